@@ -91,19 +91,36 @@ import (
 	"github.com/devtron-labs/devtron/pkg/appStore/deployment/service"
 	appStoreDeploymentGitopsTool "github.com/devtron-labs/devtron/pkg/appStore/deployment/tool/gitops"
 	"github.com/devtron-labs/devtron/pkg/appWorkflow"
+	"github.com/devtron-labs/devtron/pkg/artifactPromotion"
+	artifactPromotionRepository "github.com/devtron-labs/devtron/pkg/artifactPromotion/repository"
+	"github.com/devtron-labs/devtron/pkg/artifactRetention"
+	artifactRetentionRepository "github.com/devtron-labs/devtron/pkg/artifactRetention/repository"
 	"github.com/devtron-labs/devtron/pkg/attributes"
 	"github.com/devtron-labs/devtron/pkg/bulkAction"
+	"github.com/devtron-labs/devtron/pkg/canaryAnalysis"
+	canaryAnalysisRepository "github.com/devtron-labs/devtron/pkg/canaryAnalysis/repository"
 	"github.com/devtron-labs/devtron/pkg/chart"
 	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
+	"github.com/devtron-labs/devtron/pkg/ciBuildQueue"
+	ciBuildQueueRepository "github.com/devtron-labs/devtron/pkg/ciBuildQueue/repository"
 	"github.com/devtron-labs/devtron/pkg/commonService"
+	"github.com/devtron-labs/devtron/pkg/coreApp"
 	delete2 "github.com/devtron-labs/devtron/pkg/delete"
+	"github.com/devtron-labs/devtron/pkg/deploymentConcurrency"
+	deploymentConcurrencyRepository "github.com/devtron-labs/devtron/pkg/deploymentConcurrency/repository"
 	"github.com/devtron-labs/devtron/pkg/deploymentGroup"
+	"github.com/devtron-labs/devtron/pkg/deploymentWindow"
+	deploymentWindowRepository "github.com/devtron-labs/devtron/pkg/deploymentWindow/repository"
 	"github.com/devtron-labs/devtron/pkg/devtronResource"
 	repository9 "github.com/devtron-labs/devtron/pkg/devtronResource/repository"
 	"github.com/devtron-labs/devtron/pkg/dockerRegistry"
 	"github.com/devtron-labs/devtron/pkg/generateManifest"
 	"github.com/devtron-labs/devtron/pkg/git"
+	"github.com/devtron-labs/devtron/pkg/gitSync"
+	gitSyncRepository "github.com/devtron-labs/devtron/pkg/gitSync/repository"
 	"github.com/devtron-labs/devtron/pkg/gitops"
+	"github.com/devtron-labs/devtron/pkg/imageSigning"
+	imageSigningRepository "github.com/devtron-labs/devtron/pkg/imageSigning/repository"
 	jira2 "github.com/devtron-labs/devtron/pkg/jira"
 	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
 	repository7 "github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs/repository"
@@ -116,15 +133,25 @@ import (
 	"github.com/devtron-labs/devtron/pkg/pipeline/types"
 	"github.com/devtron-labs/devtron/pkg/plugin"
 	repository6 "github.com/devtron-labs/devtron/pkg/plugin/repository"
+	"github.com/devtron-labs/devtron/pkg/pluginTest"
+	pluginTestRepository "github.com/devtron-labs/devtron/pkg/pluginTest/repository"
 	"github.com/devtron-labs/devtron/pkg/projectManagementService/jira"
+	"github.com/devtron-labs/devtron/pkg/provenance"
+	provenanceRepository "github.com/devtron-labs/devtron/pkg/provenance/repository"
 	resourceGroup2 "github.com/devtron-labs/devtron/pkg/resourceGroup"
 	"github.com/devtron-labs/devtron/pkg/resourceQualifiers"
+	"github.com/devtron-labs/devtron/pkg/sbom"
+	sbomRepository "github.com/devtron-labs/devtron/pkg/sbom/repository"
 	"github.com/devtron-labs/devtron/pkg/security"
 	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/testAnalytics"
+	testAnalyticsRepository "github.com/devtron-labs/devtron/pkg/testAnalytics/repository"
 	util3 "github.com/devtron-labs/devtron/pkg/util"
 	"github.com/devtron-labs/devtron/pkg/variables"
 	"github.com/devtron-labs/devtron/pkg/variables/parsers"
 	repository10 "github.com/devtron-labs/devtron/pkg/variables/repository"
+	"github.com/devtron-labs/devtron/pkg/workflowLog"
+	workflowLogRepository "github.com/devtron-labs/devtron/pkg/workflowLog/repository"
 	util2 "github.com/devtron-labs/devtron/util"
 	"github.com/devtron-labs/devtron/util/argo"
 	"github.com/devtron-labs/devtron/util/rbac"
@@ -722,6 +749,8 @@ func InitializeApp() (*App, error) {
 
 		router.NewCoreAppRouterImpl,
 		wire.Bind(new(router.CoreAppRouter), new(*router.CoreAppRouterImpl)),
+		coreApp.NewCoreAppServiceImpl,
+		wire.Bind(new(coreApp.CoreAppService), new(*coreApp.CoreAppServiceImpl)),
 		restHandler.NewCoreAppRestHandlerImpl,
 		wire.Bind(new(restHandler.CoreAppRestHandler), new(*restHandler.CoreAppRestHandlerImpl)),
 
@@ -820,6 +849,8 @@ func InitializeApp() (*App, error) {
 
 		plugin.NewGlobalPluginService,
 		wire.Bind(new(plugin.GlobalPluginService), new(*plugin.GlobalPluginServiceImpl)),
+		plugin.NewPluginBundleServiceImpl,
+		wire.Bind(new(plugin.PluginBundleService), new(*plugin.PluginBundleServiceImpl)),
 
 		restHandler.NewGlobalPluginRestHandler,
 		wire.Bind(new(restHandler.GlobalPluginRestHandler), new(*restHandler.GlobalPluginRestHandlerImpl)),
@@ -972,6 +1003,173 @@ func InitializeApp() (*App, error) {
 
 		pipeline.NewPipelineConfigListenerServiceImpl,
 		wire.Bind(new(pipeline.PipelineConfigListenerService), new(*pipeline.PipelineConfigListenerServiceImpl)),
+
+		deploymentWindowRepository.NewDeploymentWindowRepositoryImpl,
+		wire.Bind(new(deploymentWindowRepository.DeploymentWindowRepository), new(*deploymentWindowRepository.DeploymentWindowRepositoryImpl)),
+		deploymentWindow.NewDeploymentWindowServiceImpl,
+		wire.Bind(new(deploymentWindow.DeploymentWindowService), new(*deploymentWindow.DeploymentWindowServiceImpl)),
+		restHandler.NewDeploymentWindowRestHandlerImpl,
+		wire.Bind(new(restHandler.DeploymentWindowRestHandler), new(*restHandler.DeploymentWindowRestHandlerImpl)),
+		router.NewDeploymentWindowRouterImpl,
+		wire.Bind(new(router.DeploymentWindowRouter), new(*router.DeploymentWindowRouterImpl)),
+		pipelineConfig.NewDeploymentApprovalRepositoryImpl,
+		wire.Bind(new(pipelineConfig.DeploymentApprovalRepository), new(*pipelineConfig.DeploymentApprovalRepositoryImpl)),
+		pipeline.NewDeploymentApprovalServiceImpl,
+		wire.Bind(new(pipeline.DeploymentApprovalService), new(*pipeline.DeploymentApprovalServiceImpl)),
+		pipeline.NewDeploymentDryRunServiceImpl,
+		wire.Bind(new(pipeline.DeploymentDryRunService), new(*pipeline.DeploymentDryRunServiceImpl)),
+		pipelineConfig.NewScheduledDeploymentRepositoryImpl,
+		wire.Bind(new(pipelineConfig.ScheduledDeploymentRepository), new(*pipelineConfig.ScheduledDeploymentRepositoryImpl)),
+		pipeline.NewScheduledDeploymentServiceImpl,
+		wire.Bind(new(pipeline.ScheduledDeploymentService), new(*pipeline.ScheduledDeploymentServiceImpl)),
+		cron.GetScheduledDeploymentCronConfig,
+		cron.NewScheduledDeploymentCronImpl,
+		wire.Bind(new(cron.ScheduledDeploymentCron), new(*cron.ScheduledDeploymentCronImpl)),
+		pipeline.NewDeploymentAutoRollbackServiceImpl,
+		wire.Bind(new(pipeline.DeploymentAutoRollbackService), new(*pipeline.DeploymentAutoRollbackServiceImpl)),
+		artifactPromotionRepository.NewArtifactPromotionPolicyRepositoryImpl,
+		wire.Bind(new(artifactPromotionRepository.ArtifactPromotionPolicyRepository), new(*artifactPromotionRepository.ArtifactPromotionPolicyRepositoryImpl)),
+		artifactPromotion.NewArtifactPromotionPolicyServiceImpl,
+		wire.Bind(new(artifactPromotion.ArtifactPromotionPolicyService), new(*artifactPromotion.ArtifactPromotionPolicyServiceImpl)),
+		restHandler.NewArtifactPromotionPolicyRestHandlerImpl,
+		wire.Bind(new(restHandler.ArtifactPromotionPolicyRestHandler), new(*restHandler.ArtifactPromotionPolicyRestHandlerImpl)),
+		router.NewArtifactPromotionPolicyRouterImpl,
+		wire.Bind(new(router.ArtifactPromotionPolicyRouter), new(*router.ArtifactPromotionPolicyRouterImpl)),
+		deploymentConcurrencyRepository.NewDeploymentConcurrencyLimitRepositoryImpl,
+		wire.Bind(new(deploymentConcurrencyRepository.DeploymentConcurrencyLimitRepository), new(*deploymentConcurrencyRepository.DeploymentConcurrencyLimitRepositoryImpl)),
+		deploymentConcurrencyRepository.NewDeploymentQueueRepositoryImpl,
+		wire.Bind(new(deploymentConcurrencyRepository.DeploymentQueueRepository), new(*deploymentConcurrencyRepository.DeploymentQueueRepositoryImpl)),
+		deploymentConcurrency.NewDeploymentConcurrencyServiceImpl,
+		wire.Bind(new(deploymentConcurrency.DeploymentConcurrencyService), new(*deploymentConcurrency.DeploymentConcurrencyServiceImpl)),
+		restHandler.NewDeploymentConcurrencyRestHandlerImpl,
+		wire.Bind(new(restHandler.DeploymentConcurrencyRestHandler), new(*restHandler.DeploymentConcurrencyRestHandlerImpl)),
+		router.NewDeploymentConcurrencyRouterImpl,
+		wire.Bind(new(router.DeploymentConcurrencyRouter), new(*router.DeploymentConcurrencyRouterImpl)),
+		cron.GetDeploymentQueueCronConfig,
+		cron.NewDeploymentQueueCronImpl,
+		wire.Bind(new(cron.DeploymentQueueCron), new(*cron.DeploymentQueueCronImpl)),
+		canaryAnalysisRepository.NewCanaryAnalysisConfigRepositoryImpl,
+		wire.Bind(new(canaryAnalysisRepository.CanaryAnalysisConfigRepository), new(*canaryAnalysisRepository.CanaryAnalysisConfigRepositoryImpl)),
+		canaryAnalysisRepository.NewCanaryAnalysisResultRepositoryImpl,
+		wire.Bind(new(canaryAnalysisRepository.CanaryAnalysisResultRepository), new(*canaryAnalysisRepository.CanaryAnalysisResultRepositoryImpl)),
+		canaryAnalysis.NewCanaryAnalysisServiceImpl,
+		wire.Bind(new(canaryAnalysis.CanaryAnalysisService), new(*canaryAnalysis.CanaryAnalysisServiceImpl)),
+		restHandler.NewCanaryAnalysisRestHandlerImpl,
+		wire.Bind(new(restHandler.CanaryAnalysisRestHandler), new(*restHandler.CanaryAnalysisRestHandlerImpl)),
+		router.NewCanaryAnalysisRouterImpl,
+		wire.Bind(new(router.CanaryAnalysisRouter), new(*router.CanaryAnalysisRouterImpl)),
+		cron.GetCanaryAnalysisCronConfig,
+		cron.NewCanaryAnalysisCronImpl,
+		wire.Bind(new(cron.CanaryAnalysisCron), new(*cron.CanaryAnalysisCronImpl)),
+		gitSyncRepository.NewGitSyncConfigRepositoryImpl,
+		wire.Bind(new(gitSyncRepository.GitSyncConfigRepository), new(*gitSyncRepository.GitSyncConfigRepositoryImpl)),
+		gitSyncRepository.NewGitSyncResultRepositoryImpl,
+		wire.Bind(new(gitSyncRepository.GitSyncResultRepository), new(*gitSyncRepository.GitSyncResultRepositoryImpl)),
+		gitSync.NewGitSyncServiceImpl,
+		wire.Bind(new(gitSync.GitSyncService), new(*gitSync.GitSyncServiceImpl)),
+		restHandler.NewGitSyncRestHandlerImpl,
+		wire.Bind(new(restHandler.GitSyncRestHandler), new(*restHandler.GitSyncRestHandlerImpl)),
+		router.NewGitSyncRouterImpl,
+		wire.Bind(new(router.GitSyncRouter), new(*router.GitSyncRouterImpl)),
+		cron.GetGitSyncCronConfig,
+		cron.NewGitSyncCronImpl,
+		wire.Bind(new(cron.GitSyncCron), new(*cron.GitSyncCronImpl)),
+		pipelineConfig.NewCiBuildMatrixRepositoryImpl,
+		wire.Bind(new(pipelineConfig.CiBuildMatrixRepository), new(*pipelineConfig.CiBuildMatrixRepositoryImpl)),
+		pipeline.NewCiBuildMatrixServiceImpl,
+		wire.Bind(new(pipeline.CiBuildMatrixService), new(*pipeline.CiBuildMatrixServiceImpl)),
+		pipelineConfig.NewCiRetryPolicyRepositoryImpl,
+		wire.Bind(new(pipelineConfig.CiRetryPolicyRepository), new(*pipelineConfig.CiRetryPolicyRepositoryImpl)),
+		pipeline.NewCiRetryPolicyServiceImpl,
+		wire.Bind(new(pipeline.CiRetryPolicyService), new(*pipeline.CiRetryPolicyServiceImpl)),
+		cron.GetCiRetryCronConfig,
+		cron.NewCiRetryCronImpl,
+		wire.Bind(new(cron.CiRetryCron), new(*cron.CiRetryCronImpl)),
+
+		ciBuildQueueRepository.NewCiBuildQuotaRepositoryImpl,
+		wire.Bind(new(ciBuildQueueRepository.CiBuildQuotaRepository), new(*ciBuildQueueRepository.CiBuildQuotaRepositoryImpl)),
+		ciBuildQueueRepository.NewCiBuildQueueRepositoryImpl,
+		wire.Bind(new(ciBuildQueueRepository.CiBuildQueueRepository), new(*ciBuildQueueRepository.CiBuildQueueRepositoryImpl)),
+		ciBuildQueue.NewCiBuildQueueServiceImpl,
+		wire.Bind(new(ciBuildQueue.CiBuildQueueService), new(*ciBuildQueue.CiBuildQueueServiceImpl)),
+		restHandler.NewCiBuildQueueRestHandlerImpl,
+		wire.Bind(new(restHandler.CiBuildQueueRestHandler), new(*restHandler.CiBuildQueueRestHandlerImpl)),
+		router.NewCiBuildQueueRouterImpl,
+		wire.Bind(new(router.CiBuildQueueRouter), new(*router.CiBuildQueueRouterImpl)),
+		cron.GetCiBuildQueueCronConfig,
+		cron.NewCiBuildQueueCronImpl,
+		wire.Bind(new(cron.CiBuildQueueCron), new(*cron.CiBuildQueueCronImpl)),
+
+		sbomRepository.NewSbomRepositoryImpl,
+		wire.Bind(new(sbomRepository.SbomRepository), new(*sbomRepository.SbomRepositoryImpl)),
+		sbom.NewSbomServiceImpl,
+		wire.Bind(new(sbom.SbomService), new(*sbom.SbomServiceImpl)),
+		restHandler.NewSbomRestHandlerImpl,
+		wire.Bind(new(restHandler.SbomRestHandler), new(*restHandler.SbomRestHandlerImpl)),
+		router.NewSbomRouterImpl,
+		wire.Bind(new(router.SbomRouter), new(*router.SbomRouterImpl)),
+
+		imageSigningRepository.NewImageSigningKeyRepositoryImpl,
+		wire.Bind(new(imageSigningRepository.ImageSigningKeyRepository), new(*imageSigningRepository.ImageSigningKeyRepositoryImpl)),
+		imageSigningRepository.NewImageSignaturePolicyRepositoryImpl,
+		wire.Bind(new(imageSigningRepository.ImageSignaturePolicyRepository), new(*imageSigningRepository.ImageSignaturePolicyRepositoryImpl)),
+		imageSigning.NewImageSigningServiceImpl,
+		wire.Bind(new(imageSigning.ImageSigningService), new(*imageSigning.ImageSigningServiceImpl)),
+		restHandler.NewImageSigningRestHandlerImpl,
+		wire.Bind(new(restHandler.ImageSigningRestHandler), new(*restHandler.ImageSigningRestHandlerImpl)),
+		router.NewImageSigningRouterImpl,
+		wire.Bind(new(router.ImageSigningRouter), new(*router.ImageSigningRouterImpl)),
+
+		provenanceRepository.NewProvenanceRepositoryImpl,
+		wire.Bind(new(provenanceRepository.ProvenanceRepository), new(*provenanceRepository.ProvenanceRepositoryImpl)),
+		provenance.NewProvenanceServiceImpl,
+		wire.Bind(new(provenance.ProvenanceService), new(*provenance.ProvenanceServiceImpl)),
+		restHandler.NewProvenanceRestHandlerImpl,
+		wire.Bind(new(restHandler.ProvenanceRestHandler), new(*restHandler.ProvenanceRestHandlerImpl)),
+		router.NewProvenanceRouterImpl,
+		wire.Bind(new(router.ProvenanceRouter), new(*router.ProvenanceRouterImpl)),
+
+		artifactRetentionRepository.NewArtifactRetentionRepositoryImpl,
+		wire.Bind(new(artifactRetentionRepository.ArtifactRetentionRepository), new(*artifactRetentionRepository.ArtifactRetentionRepositoryImpl)),
+		artifactRetention.NewArtifactRetentionServiceImpl,
+		wire.Bind(new(artifactRetention.ArtifactRetentionService), new(*artifactRetention.ArtifactRetentionServiceImpl)),
+		restHandler.NewArtifactRetentionRestHandlerImpl,
+		wire.Bind(new(restHandler.ArtifactRetentionRestHandler), new(*restHandler.ArtifactRetentionRestHandlerImpl)),
+		router.NewArtifactRetentionRouterImpl,
+		wire.Bind(new(router.ArtifactRetentionRouter), new(*router.ArtifactRetentionRouterImpl)),
+		cron.GetArtifactGcCronConfig,
+		cron.NewArtifactGcCronImpl,
+		wire.Bind(new(cron.ArtifactGcCron), new(*cron.ArtifactGcCronImpl)),
+
+		workflowLogRepository.NewWorkflowLogRepositoryImpl,
+		wire.Bind(new(workflowLogRepository.WorkflowLogRepository), new(*workflowLogRepository.WorkflowLogRepositoryImpl)),
+		workflowLog.NewWorkflowLogServiceImpl,
+		wire.Bind(new(workflowLog.WorkflowLogService), new(*workflowLog.WorkflowLogServiceImpl)),
+		restHandler.NewWorkflowLogRestHandlerImpl,
+		wire.Bind(new(restHandler.WorkflowLogRestHandler), new(*restHandler.WorkflowLogRestHandlerImpl)),
+		router.NewWorkflowLogRouterImpl,
+		wire.Bind(new(router.WorkflowLogRouter), new(*router.WorkflowLogRouterImpl)),
+		cron.GetWorkflowLogIndexCronConfig,
+		cron.NewWorkflowLogIndexCronImpl,
+		wire.Bind(new(cron.WorkflowLogIndexCron), new(*cron.WorkflowLogIndexCronImpl)),
+
+		testAnalyticsRepository.NewTestAnalyticsRepositoryImpl,
+		wire.Bind(new(testAnalyticsRepository.TestAnalyticsRepository), new(*testAnalyticsRepository.TestAnalyticsRepositoryImpl)),
+		testAnalytics.NewTestAnalyticsServiceImpl,
+		wire.Bind(new(testAnalytics.TestAnalyticsService), new(*testAnalytics.TestAnalyticsServiceImpl)),
+		restHandler.NewTestAnalyticsRestHandlerImpl,
+		wire.Bind(new(restHandler.TestAnalyticsRestHandler), new(*restHandler.TestAnalyticsRestHandlerImpl)),
+		router.NewTestAnalyticsRouterImpl,
+		wire.Bind(new(router.TestAnalyticsRouter), new(*router.TestAnalyticsRouterImpl)),
+
+		pluginTestRepository.NewPluginTestRunRepositoryImpl,
+		wire.Bind(new(pluginTestRepository.PluginTestRunRepository), new(*pluginTestRepository.PluginTestRunRepositoryImpl)),
+		pluginTest.NewPluginTestServiceImpl,
+		wire.Bind(new(pluginTest.PluginTestService), new(*pluginTest.PluginTestServiceImpl)),
+		restHandler.NewPluginTestRestHandlerImpl,
+		wire.Bind(new(restHandler.PluginTestRestHandler), new(*restHandler.PluginTestRestHandlerImpl)),
+		router.NewPluginTestRouterImpl,
+		wire.Bind(new(router.PluginTestRouter), new(*router.PluginTestRouterImpl)),
 	)
 	return &App{}, nil
 }
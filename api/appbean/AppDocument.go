@@ -0,0 +1,445 @@
+package appbean
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/yaml"
+)
+
+// AppDocument is the versioned app-as-code document of a devtron app, the spec is the same AppDetail served by the
+// app detail API so that a document exported from one instance can be applied on another one
+type AppDocument struct {
+	ApiVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Spec       *AppDetail `json:"spec"`
+}
+
+const (
+	APP_DOCUMENT_API_VERSION = "devtron.ai/v1beta1"
+	APP_DOCUMENT_KIND        = "Application"
+)
+
+type AppApplyAction string
+
+const (
+	APP_APPLY_ACTION_CREATE      AppApplyAction = "CREATE"
+	APP_APPLY_ACTION_UPDATE      AppApplyAction = "UPDATE"
+	APP_APPLY_ACTION_DELETE      AppApplyAction = "DELETE"
+	APP_APPLY_ACTION_NO_CHANGE   AppApplyAction = "NO_CHANGE"
+	APP_APPLY_ACTION_UNSUPPORTED AppApplyAction = "UNSUPPORTED"
+)
+
+type AppComponent string
+
+const (
+	APP_COMPONENT_APP                  AppComponent = "app"
+	APP_COMPONENT_LABELS               AppComponent = "labels"
+	APP_COMPONENT_GIT_MATERIAL         AppComponent = "gitMaterial"
+	APP_COMPONENT_DOCKER_CONFIG        AppComponent = "dockerConfig"
+	APP_COMPONENT_DEPLOYMENT_TEMPLATE  AppComponent = "globalDeploymentTemplate"
+	APP_COMPONENT_CONFIG_MAP           AppComponent = "globalConfigMap"
+	APP_COMPONENT_SECRET               AppComponent = "globalSecret"
+	APP_COMPONENT_WORKFLOW             AppComponent = "workflow"
+	APP_COMPONENT_CI_PIPELINE          AppComponent = "ciPipeline"
+	APP_COMPONENT_CD_PIPELINE          AppComponent = "cdPipeline"
+	APP_COMPONENT_ENVIRONMENT_OVERRIDE AppComponent = "environmentOverride"
+)
+
+// AppApplyPlanItem is the change planned for one component of the app, pipelines of an existing workflow are planned
+// as items of their own with the name of the workflow set, cd pipelines are named by their environment
+type AppApplyPlanItem struct {
+	Component AppComponent   `json:"component"`
+	Name      string         `json:"name"`
+	Workflow  string         `json:"workflow,omitempty"`
+	Action    AppApplyAction `json:"action"`
+	Message   string         `json:"message,omitempty"`
+}
+
+type AppApplyResponse struct {
+	AppId   int                 `json:"appId"`
+	AppName string              `json:"appName"`
+	DryRun  bool                `json:"dryRun"`
+	Prune   bool                `json:"prune"`
+	Applied bool                `json:"applied"`
+	Plan    []*AppApplyPlanItem `json:"plan"`
+}
+
+func NewAppDocument(appDetail *AppDetail) *AppDocument {
+	return &AppDocument{
+		ApiVersion: APP_DOCUMENT_API_VERSION,
+		Kind:       APP_DOCUMENT_KIND,
+		Spec:       appDetail,
+	}
+}
+
+// ParseAppDocument reads an app document from yaml or json and checks that its version is supported
+func ParseAppDocument(data []byte) (*AppDocument, error) {
+	document := &AppDocument{}
+	err := yaml.Unmarshal(data, document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid app document: %w", err)
+	}
+	if document.ApiVersion != APP_DOCUMENT_API_VERSION {
+		return nil, fmt.Errorf("unsupported apiVersion '%s', expected '%s'", document.ApiVersion, APP_DOCUMENT_API_VERSION)
+	}
+	if document.Kind != APP_DOCUMENT_KIND {
+		return nil, fmt.Errorf("unsupported kind '%s', expected '%s'", document.Kind, APP_DOCUMENT_KIND)
+	}
+	if document.Spec == nil || document.Spec.Metadata == nil {
+		return nil, fmt.Errorf("spec.metadata is required in app document")
+	}
+	return document, nil
+}
+
+// RedactSecretData removes the data of all non external secrets of the app, secrets without data are left unchanged
+// on apply
+func (appDetail *AppDetail) RedactSecretData() {
+	redact := func(secrets []*Secret) {
+		for _, secret := range secrets {
+			if !secret.IsExternal {
+				secret.Data = nil
+			}
+		}
+	}
+	redact(appDetail.GlobalSecrets)
+	for _, environmentOverride := range appDetail.EnvironmentOverrides {
+		if environmentOverride != nil {
+			redact(environmentOverride.Secrets)
+		}
+	}
+}
+
+// ComputeAppApplyPlan compares the desired state of an app with its current state, current is nil if the app does
+// not exist yet. Apply adds and updates components, workflows and their pipelines present in current but absent in
+// desired are deleted only with prune, other components absent in desired are left untouched.
+// Secrets exported without data are expected to be filled by FillRedactedSecretData beforehand.
+func ComputeAppApplyPlan(desired, current *AppDetail, prune bool) []*AppApplyPlanItem {
+	if current == nil {
+		return computeAppCreatePlan(desired)
+	}
+	var plan []*AppApplyPlanItem
+	addItem := func(component AppComponent, name string, action AppApplyAction, message string) {
+		plan = append(plan, &AppApplyPlanItem{Component: component, Name: name, Action: action, Message: message})
+	}
+	appName := desired.Metadata.AppName
+	if current.Metadata != nil && desired.Metadata.ProjectName != current.Metadata.ProjectName {
+		addItem(APP_COMPONENT_APP, appName, APP_APPLY_ACTION_UNSUPPORTED, fmt.Sprintf("app belongs to project '%s', moving it to another project is not supported by apply", current.Metadata.ProjectName))
+	} else {
+		addItem(APP_COMPONENT_APP, appName, APP_APPLY_ACTION_NO_CHANGE, "")
+	}
+	if current.Metadata == nil || !isLabelsEqual(desired.Metadata.Labels, current.Metadata.Labels) {
+		addItem(APP_COMPONENT_LABELS, appName, APP_APPLY_ACTION_UPDATE, "")
+	} else {
+		addItem(APP_COMPONENT_LABELS, appName, APP_APPLY_ACTION_NO_CHANGE, "")
+	}
+
+	currentGitMaterials := make(map[string]*GitMaterial)
+	for _, gitMaterial := range current.GitMaterials {
+		currentGitMaterials[gitMaterial.GitRepoUrl] = gitMaterial
+	}
+	for _, gitMaterial := range desired.GitMaterials {
+		addItem(APP_COMPONENT_GIT_MATERIAL, gitMaterial.GitRepoUrl, getApplyAction(gitMaterial, currentGitMaterials[gitMaterial.GitRepoUrl], currentGitMaterials[gitMaterial.GitRepoUrl] != nil), "")
+	}
+
+	if desired.DockerConfig != nil {
+		addItem(APP_COMPONENT_DOCKER_CONFIG, appName, getApplyAction(newComparableDockerConfig(desired.DockerConfig), newComparableDockerConfig(current.DockerConfig), current.DockerConfig != nil), "")
+	}
+
+	if desired.GlobalDeploymentTemplate != nil {
+		currentTemplate := current.GlobalDeploymentTemplate
+		if currentTemplate != nil && currentTemplate.ChartRefId != desired.GlobalDeploymentTemplate.ChartRefId {
+			addItem(APP_COMPONENT_DEPLOYMENT_TEMPLATE, appName, APP_APPLY_ACTION_UNSUPPORTED, fmt.Sprintf("chart ref %d is in use, changing the chart is not supported by apply", currentTemplate.ChartRefId))
+		} else {
+			addItem(APP_COMPONENT_DEPLOYMENT_TEMPLATE, appName, getApplyAction(desired.GlobalDeploymentTemplate, currentTemplate, currentTemplate != nil), "")
+		}
+	}
+
+	currentConfigMaps := make(map[string]*ConfigMap)
+	for _, configMap := range current.GlobalConfigMaps {
+		currentConfigMaps[configMap.Name] = configMap
+	}
+	for _, configMap := range desired.GlobalConfigMaps {
+		addItem(APP_COMPONENT_CONFIG_MAP, configMap.Name, getApplyAction(configMap, currentConfigMaps[configMap.Name], currentConfigMaps[configMap.Name] != nil), "")
+	}
+
+	currentSecrets := make(map[string]*Secret)
+	for _, secret := range current.GlobalSecrets {
+		currentSecrets[secret.Name] = secret
+	}
+	for _, secret := range desired.GlobalSecrets {
+		currentSecret := currentSecrets[secret.Name]
+		if currentSecret == nil && isSecretDataRedacted(secret) {
+			addItem(APP_COMPONENT_SECRET, secret.Name, APP_APPLY_ACTION_UNSUPPORTED, "secret data is required to create the secret")
+			continue
+		}
+		addItem(APP_COMPONENT_SECRET, secret.Name, getApplyAction(secret, currentSecret, currentSecret != nil), "")
+	}
+
+	plan = append(plan, computeWorkflowsPlan(desired.AppWorkflows, current.AppWorkflows, prune)...)
+
+	for _, envName := range getSortedEnvNames(desired.EnvironmentOverrides) {
+		currentOverride := current.EnvironmentOverrides[envName]
+		addItem(APP_COMPONENT_ENVIRONMENT_OVERRIDE, envName, getApplyAction(desired.EnvironmentOverrides[envName], currentOverride, currentOverride != nil), "")
+	}
+	return plan
+}
+
+// computeWorkflowsPlan plans new workflows as a whole and the changes in existing workflows per pipeline, a ci
+// pipeline is identified by its workflow and a cd pipeline by its workflow and environment
+func computeWorkflowsPlan(desired, current []*AppWorkflow, prune bool) []*AppApplyPlanItem {
+	var plan []*AppApplyPlanItem
+	currentWorkflows := make(map[string]*AppWorkflow)
+	for _, workflow := range current {
+		currentWorkflows[workflow.Name] = workflow
+	}
+	desiredWorkflows := make(map[string]bool)
+	for _, workflow := range desired {
+		desiredWorkflows[workflow.Name] = true
+		currentWorkflow := currentWorkflows[workflow.Name]
+		if currentWorkflow == nil {
+			plan = append(plan, &AppApplyPlanItem{Component: APP_COMPONENT_WORKFLOW, Name: workflow.Name, Action: APP_APPLY_ACTION_CREATE})
+			continue
+		}
+		pipelinesPlan := computeWorkflowPipelinesPlan(workflow, currentWorkflow, prune)
+		action := APP_APPLY_ACTION_NO_CHANGE
+		for _, item := range pipelinesPlan {
+			if item.Action != APP_APPLY_ACTION_NO_CHANGE {
+				action = APP_APPLY_ACTION_UPDATE
+				break
+			}
+		}
+		plan = append(plan, &AppApplyPlanItem{Component: APP_COMPONENT_WORKFLOW, Name: workflow.Name, Action: action})
+		plan = append(plan, pipelinesPlan...)
+	}
+	if prune {
+		for _, workflow := range current {
+			if !desiredWorkflows[workflow.Name] {
+				plan = append(plan, &AppApplyPlanItem{Component: APP_COMPONENT_WORKFLOW, Name: workflow.Name, Action: APP_APPLY_ACTION_DELETE,
+					Message: "workflow is deleted along with its ci and cd pipelines"})
+			}
+		}
+	}
+	return plan
+}
+
+func computeWorkflowPipelinesPlan(desired, current *AppWorkflow, prune bool) []*AppApplyPlanItem {
+	var plan []*AppApplyPlanItem
+	addItem := func(component AppComponent, name string, action AppApplyAction, message string) {
+		plan = append(plan, &AppApplyPlanItem{Component: component, Name: name, Workflow: desired.Name, Action: action, Message: message})
+	}
+	if desired.CiPipeline != nil && current.CiPipeline != nil {
+		desiredCi, currentCi := desired.CiPipeline, current.CiPipeline
+		switch {
+		case desiredCi.Name != currentCi.Name:
+			addItem(APP_COMPONENT_CI_PIPELINE, desiredCi.Name, APP_APPLY_ACTION_UNSUPPORTED, fmt.Sprintf("ci pipeline of the workflow is '%s', renaming it is not supported by apply", currentCi.Name))
+		case desiredCi.IsExternal != currentCi.IsExternal || desiredCi.ParentCiPipeline != currentCi.ParentCiPipeline ||
+			desiredCi.ParentAppId != currentCi.ParentAppId || desiredCi.PipelineType != currentCi.PipelineType:
+			addItem(APP_COMPONENT_CI_PIPELINE, desiredCi.Name, APP_APPLY_ACTION_UNSUPPORTED, "changing the type or the parent of a ci pipeline is not supported by apply")
+		default:
+			addItem(APP_COMPONENT_CI_PIPELINE, desiredCi.Name, getApplyAction(desiredCi, currentCi, true), "")
+		}
+	}
+
+	currentCdPipelines := make(map[string]*CdPipelineDetails)
+	for _, cdPipeline := range current.CdPipelines {
+		currentCdPipelines[cdPipeline.EnvironmentName] = cdPipeline
+	}
+	desiredEnvs := make(map[string]bool)
+	for _, cdPipeline := range desired.CdPipelines {
+		desiredEnvs[cdPipeline.EnvironmentName] = true
+		currentCdPipeline := currentCdPipelines[cdPipeline.EnvironmentName]
+		switch {
+		case currentCdPipeline == nil:
+			addItem(APP_COMPONENT_CD_PIPELINE, cdPipeline.EnvironmentName, APP_APPLY_ACTION_CREATE, "")
+		case cdPipeline.Name != currentCdPipeline.Name:
+			addItem(APP_COMPONENT_CD_PIPELINE, cdPipeline.EnvironmentName, APP_APPLY_ACTION_UNSUPPORTED,
+				fmt.Sprintf("cd pipeline of the environment is '%s', renaming it is not supported by apply", currentCdPipeline.Name))
+		default:
+			addItem(APP_COMPONENT_CD_PIPELINE, cdPipeline.EnvironmentName, getApplyAction(newComparableCdPipeline(cdPipeline), newComparableCdPipeline(currentCdPipeline), true), "")
+		}
+	}
+	if prune {
+		for _, cdPipeline := range current.CdPipelines {
+			if !desiredEnvs[cdPipeline.EnvironmentName] {
+				addItem(APP_COMPONENT_CD_PIPELINE, cdPipeline.EnvironmentName, APP_APPLY_ACTION_DELETE, fmt.Sprintf("cd pipeline '%s' is deleted", cdPipeline.Name))
+			}
+		}
+	}
+	return plan
+}
+
+// IsAppApplyPlanApplicable tells if the plan can be applied, it can not be applied if any item is unsupported
+func IsAppApplyPlanApplicable(plan []*AppApplyPlanItem) bool {
+	for _, item := range plan {
+		if item.Action == APP_APPLY_ACTION_UNSUPPORTED {
+			return false
+		}
+	}
+	return true
+}
+
+// FillRedactedSecretData sets the data of the desired secrets exported without data to the data of the current
+// secrets of the same name, so that such secrets are left unchanged on apply
+func FillRedactedSecretData(desired, current *AppDetail) {
+	if current == nil {
+		return
+	}
+	fill := func(desiredSecrets, currentSecrets []*Secret) {
+		for _, secret := range desiredSecrets {
+			if !isSecretDataRedacted(secret) {
+				continue
+			}
+			for _, currentSecret := range currentSecrets {
+				if currentSecret.Name == secret.Name {
+					secret.Data = currentSecret.Data
+					break
+				}
+			}
+		}
+	}
+	fill(desired.GlobalSecrets, current.GlobalSecrets)
+	for envName, environmentOverride := range desired.EnvironmentOverrides {
+		if environmentOverride != nil && current.EnvironmentOverrides[envName] != nil {
+			fill(environmentOverride.Secrets, current.EnvironmentOverrides[envName].Secrets)
+		}
+	}
+}
+
+func computeAppCreatePlan(desired *AppDetail) []*AppApplyPlanItem {
+	var plan []*AppApplyPlanItem
+	addItem := func(component AppComponent, name string) {
+		plan = append(plan, &AppApplyPlanItem{Component: component, Name: name, Action: APP_APPLY_ACTION_CREATE})
+	}
+	appName := desired.Metadata.AppName
+	addItem(APP_COMPONENT_APP, appName)
+	for _, gitMaterial := range desired.GitMaterials {
+		addItem(APP_COMPONENT_GIT_MATERIAL, gitMaterial.GitRepoUrl)
+	}
+	if desired.DockerConfig != nil {
+		addItem(APP_COMPONENT_DOCKER_CONFIG, appName)
+	}
+	if desired.GlobalDeploymentTemplate != nil {
+		addItem(APP_COMPONENT_DEPLOYMENT_TEMPLATE, appName)
+	}
+	for _, configMap := range desired.GlobalConfigMaps {
+		addItem(APP_COMPONENT_CONFIG_MAP, configMap.Name)
+	}
+	for _, secret := range desired.GlobalSecrets {
+		if isSecretDataRedacted(secret) {
+			plan = append(plan, &AppApplyPlanItem{Component: APP_COMPONENT_SECRET, Name: secret.Name, Action: APP_APPLY_ACTION_UNSUPPORTED, Message: "secret data is required to create the secret"})
+			continue
+		}
+		addItem(APP_COMPONENT_SECRET, secret.Name)
+	}
+	for _, workflow := range desired.AppWorkflows {
+		addItem(APP_COMPONENT_WORKFLOW, workflow.Name)
+	}
+	for _, envName := range getSortedEnvNames(desired.EnvironmentOverrides) {
+		addItem(APP_COMPONENT_ENVIRONMENT_OVERRIDE, envName)
+	}
+	return plan
+}
+
+// comparableDockerConfig is the part of docker config which does not depend on the ids of the devtron instance
+type comparableDockerConfig struct {
+	DockerRegistry      string      `json:"dockerRegistry"`
+	DockerRepository    string      `json:"dockerRepository"`
+	CheckoutPath        string      `json:"checkoutPath"`
+	UseRootBuildContext bool        `json:"useRootBuildContext"`
+	CiBuildType         interface{} `json:"ciBuildType"`
+	DockerBuildConfig   interface{} `json:"dockerBuildConfig"`
+	BuildPackConfig     interface{} `json:"buildPackConfig"`
+}
+
+func newComparableDockerConfig(dockerConfig *DockerConfig) *comparableDockerConfig {
+	if dockerConfig == nil {
+		return nil
+	}
+	result := &comparableDockerConfig{
+		DockerRegistry:   dockerConfig.DockerRegistry,
+		DockerRepository: dockerConfig.DockerRepository,
+		CheckoutPath:     dockerConfig.CheckoutPath,
+	}
+	if dockerConfig.CiBuildConfig != nil {
+		result.UseRootBuildContext = dockerConfig.CiBuildConfig.UseRootBuildContext
+		result.CiBuildType = dockerConfig.CiBuildConfig.CiBuildType
+		result.DockerBuildConfig = dockerConfig.CiBuildConfig.DockerBuildConfig
+		result.BuildPackConfig = dockerConfig.CiBuildConfig.BuildPackConfig
+	}
+	return result
+}
+
+// newComparableCdPipeline returns a copy of the cd pipeline without its deployment app type, it is only used on the
+// creation of the pipeline and is not part of the exported state
+func newComparableCdPipeline(cdPipeline *CdPipelineDetails) *CdPipelineDetails {
+	result := *cdPipeline
+	result.DeploymentAppType = ""
+	return &result
+}
+
+func getApplyAction(desired, current interface{}, exists bool) AppApplyAction {
+	if !exists {
+		return APP_APPLY_ACTION_CREATE
+	}
+	if isJsonEqual(desired, current) {
+		return APP_APPLY_ACTION_NO_CHANGE
+	}
+	return APP_APPLY_ACTION_UPDATE
+}
+
+// isJsonEqual compares the json form of both values, so that values decoded from yaml and values built from the
+// database compare equal when they carry the same content
+func isJsonEqual(a, b interface{}) bool {
+	aJson, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJson, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	var aObj, bObj interface{}
+	if json.Unmarshal(aJson, &aObj) != nil || json.Unmarshal(bJson, &bObj) != nil {
+		return false
+	}
+	aJson, _ = json.Marshal(aObj)
+	bJson, _ = json.Marshal(bObj)
+	return bytes.Equal(aJson, bJson)
+}
+
+func isLabelsEqual(desired, current []*AppLabel) bool {
+	if len(desired) != len(current) {
+		return false
+	}
+	labelKey := func(label *AppLabel) string {
+		return fmt.Sprintf("%s=%s/%t", label.Key, label.Value, label.Propagate)
+	}
+	currentLabels := make(map[string]bool, len(current))
+	for _, label := range current {
+		currentLabels[labelKey(label)] = true
+	}
+	for _, label := range desired {
+		if !currentLabels[labelKey(label)] {
+			return false
+		}
+	}
+	return true
+}
+
+func isSecretDataRedacted(secret *Secret) bool {
+	return !secret.IsExternal && secret.Data == nil
+}
+
+func getSortedEnvNames(environmentOverrides map[string]*EnvironmentOverride) []string {
+	envNames := make([]string, 0, len(environmentOverrides))
+	for envName, environmentOverride := range environmentOverrides {
+		if environmentOverride != nil {
+			envNames = append(envNames, envName)
+		}
+	}
+	sort.Strings(envNames)
+	return envNames
+}
@@ -0,0 +1,183 @@
+package appbean
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
+)
+
+const testAppDocument = `
+apiVersion: devtron.ai/v1beta1
+kind: Application
+spec:
+  metadata:
+    appName: payments
+    projectName: fintech
+    labels:
+    - key: team
+      value: core
+  gitMaterials:
+  - gitProviderUrl: github.com
+    gitRepoUrl: https://github.com/org/payments.git
+    checkoutPath: ./
+  dockerConfig:
+    dockerRegistry: ecr
+    dockerRepository: payments
+    checkoutPath: ./
+    ciBuildConfig:
+      ciBuildType: self-dockerfile-build
+      dockerBuildConfig:
+        dockerfileRelativePath: Dockerfile
+  globalDeploymentTemplate:
+    chartRefId: 10
+    template:
+      replicaCount: 2
+  globalConfigMaps:
+  - name: app-cm
+    usageType: environment
+    data:
+      LOG_LEVEL: debug
+  globalSecrets:
+  - name: app-secret
+    usageType: environment
+  workflows:
+  - name: wf-1
+    ciPipeline:
+      name: ci-1
+`
+
+func TestParseAppDocument(t *testing.T) {
+	document, err := ParseAppDocument([]byte(testAppDocument))
+	if err != nil {
+		t.Fatalf("ParseAppDocument() error = %v", err)
+	}
+	if document.Spec.Metadata.AppName != "payments" || document.Spec.GlobalDeploymentTemplate.Template["replicaCount"] != float64(2) {
+		t.Errorf("ParseAppDocument() spec = %+v", document.Spec)
+	}
+	_, err = ParseAppDocument([]byte("apiVersion: devtron.ai/v1alpha1\nkind: Application\nspec:\n  metadata:\n    appName: a\n"))
+	if err == nil {
+		t.Errorf("ParseAppDocument() expected error for unsupported apiVersion")
+	}
+	_, err = ParseAppDocument([]byte("apiVersion: devtron.ai/v1beta1\nkind: Application\n"))
+	if err == nil {
+		t.Errorf("ParseAppDocument() expected error for missing spec")
+	}
+}
+
+func TestComputeAppApplyPlan(t *testing.T) {
+	parse := func() *AppDetail {
+		document, err := ParseAppDocument([]byte(testAppDocument))
+		if err != nil {
+			t.Fatalf("ParseAppDocument() error = %v", err)
+		}
+		return document.Spec
+	}
+	getActions := func(plan []*AppApplyPlanItem) map[AppComponent]AppApplyAction {
+		actions := make(map[AppComponent]AppApplyAction)
+		for _, item := range plan {
+			actions[item.Component] = item.Action
+		}
+		return actions
+	}
+
+	t.Run("new app is created with redacted secret unsupported", func(t *testing.T) {
+		actions := getActions(ComputeAppApplyPlan(parse(), nil, false))
+		if actions[APP_COMPONENT_APP] != APP_APPLY_ACTION_CREATE || actions[APP_COMPONENT_SECRET] != APP_APPLY_ACTION_UNSUPPORTED {
+			t.Errorf("ComputeAppApplyPlan() actions = %v", actions)
+		}
+	})
+
+	t.Run("applying the exported state again changes nothing", func(t *testing.T) {
+		desired, current := parse(), parse()
+		current.GlobalSecrets[0].Data = map[string]interface{}{"PASSWORD": "c2VjcmV0"}
+		current.DockerConfig.CiBuildConfig.Id = 4
+		current.DockerConfig.CiBuildConfig.GitMaterialId = 7
+		FillRedactedSecretData(desired, current)
+		plan := ComputeAppApplyPlan(desired, current, false)
+		for _, item := range plan {
+			if item.Action != APP_APPLY_ACTION_NO_CHANGE {
+				t.Errorf("ComputeAppApplyPlan() item %s/%s action = %s, want NO_CHANGE", item.Component, item.Name, item.Action)
+			}
+		}
+		if !IsAppApplyPlanApplicable(plan) {
+			t.Errorf("IsAppApplyPlanApplicable() = false, want true")
+		}
+	})
+
+	t.Run("changes are planned as updates", func(t *testing.T) {
+		desired, current := parse(), parse()
+		desired.Metadata.Labels = append(desired.Metadata.Labels, &AppLabel{Key: "tier", Value: "1"})
+		desired.GlobalDeploymentTemplate.Template["replicaCount"] = 3
+		desired.GlobalConfigMaps = append(desired.GlobalConfigMaps, &ConfigMap{Name: "new-cm", UsageType: "environment"})
+		desired.DockerConfig.CiBuildConfig.DockerBuildConfig = &bean.DockerBuildConfig{DockerfilePath: "build/Dockerfile"}
+		desired.EnvironmentOverrides = map[string]*EnvironmentOverride{"prod": {}}
+		plan := ComputeAppApplyPlan(desired, current, false)
+		actions := getActions(plan)
+		if actions[APP_COMPONENT_LABELS] != APP_APPLY_ACTION_UPDATE || actions[APP_COMPONENT_DEPLOYMENT_TEMPLATE] != APP_APPLY_ACTION_UPDATE ||
+			actions[APP_COMPONENT_DOCKER_CONFIG] != APP_APPLY_ACTION_UPDATE || actions[APP_COMPONENT_ENVIRONMENT_OVERRIDE] != APP_APPLY_ACTION_CREATE {
+			t.Errorf("ComputeAppApplyPlan() actions = %v", actions)
+		}
+		if plan[len(plan)-1].Name != "prod" {
+			t.Errorf("ComputeAppApplyPlan() last item = %+v, want environment override prod", plan[len(plan)-1])
+		}
+	})
+
+	t.Run("chart change and ci pipeline rename are unsupported", func(t *testing.T) {
+		desired, current := parse(), parse()
+		desired.GlobalDeploymentTemplate.ChartRefId = 11
+		desired.AppWorkflows[0].CiPipeline.Name = "ci-2"
+		actions := getActions(ComputeAppApplyPlan(desired, current, false))
+		if actions[APP_COMPONENT_DEPLOYMENT_TEMPLATE] != APP_APPLY_ACTION_UNSUPPORTED || actions[APP_COMPONENT_CI_PIPELINE] != APP_APPLY_ACTION_UNSUPPORTED {
+			t.Errorf("ComputeAppApplyPlan() actions = %v", actions)
+		}
+	})
+
+	t.Run("pipeline changes of an existing workflow are planned per pipeline", func(t *testing.T) {
+		desired, current := parse(), parse()
+		current.AppWorkflows[0].CdPipelines = []*CdPipelineDetails{
+			{Name: "payments-dev", EnvironmentName: "dev", TriggerType: "AUTOMATIC"},
+			{Name: "payments-qa", EnvironmentName: "qa", TriggerType: "AUTOMATIC"},
+		}
+		desired.AppWorkflows[0].CiPipeline.IsManual = true
+		desired.AppWorkflows[0].CdPipelines = []*CdPipelineDetails{
+			{Name: "payments-dev", EnvironmentName: "dev", TriggerType: "MANUAL", DeploymentAppType: "helm"},
+			{Name: "payments-prod", EnvironmentName: "prod", TriggerType: "MANUAL"},
+		}
+		plan := ComputeAppApplyPlan(desired, current, false)
+		actions := getActions(plan)
+		if actions[APP_COMPONENT_WORKFLOW] != APP_APPLY_ACTION_UPDATE || actions[APP_COMPONENT_CI_PIPELINE] != APP_APPLY_ACTION_UPDATE {
+			t.Errorf("ComputeAppApplyPlan() actions = %v", actions)
+		}
+		cdActions := make(map[string]AppApplyAction)
+		for _, item := range plan {
+			if item.Component == APP_COMPONENT_CD_PIPELINE {
+				cdActions[item.Name] = item.Action
+			}
+		}
+		if len(cdActions) != 2 || cdActions["dev"] != APP_APPLY_ACTION_UPDATE || cdActions["prod"] != APP_APPLY_ACTION_CREATE {
+			t.Errorf("ComputeAppApplyPlan() cd pipeline actions = %v, qa must be left untouched without prune", cdActions)
+		}
+
+		plan = ComputeAppApplyPlan(desired, current, true)
+		cdActions = make(map[string]AppApplyAction)
+		for _, item := range plan {
+			if item.Component == APP_COMPONENT_CD_PIPELINE {
+				cdActions[item.Name] = item.Action
+			}
+		}
+		if cdActions["qa"] != APP_APPLY_ACTION_DELETE {
+			t.Errorf("ComputeAppApplyPlan() cd pipeline actions = %v, want qa deleted with prune", cdActions)
+		}
+	})
+
+	t.Run("workflows absent in desired are deleted only with prune", func(t *testing.T) {
+		desired, current := parse(), parse()
+		desired.AppWorkflows = nil
+		if actions := getActions(ComputeAppApplyPlan(desired, current, false)); actions[APP_COMPONENT_WORKFLOW] != "" {
+			t.Errorf("ComputeAppApplyPlan() actions = %v, want no workflow item without prune", actions)
+		}
+		if actions := getActions(ComputeAppApplyPlan(desired, current, true)); actions[APP_COMPONENT_WORKFLOW] != APP_APPLY_ACTION_DELETE {
+			t.Errorf("ComputeAppApplyPlan() actions = %v, want workflow deleted with prune", actions)
+		}
+	})
+}
@@ -66,6 +66,8 @@ type ValuesOverrideRequest struct {
 	CdWorkflowId                          int                         `json:"cdWorkflowId"`
 	PipelineOverrideId                    int                         `json:"pipelineOverrideId"` //required for async install/upgrade event;
 	DeploymentType                        models.DeploymentType       `json:"deploymentType"`     //required for async install/upgrade handling; previously if was used internally
+	DeploymentWindowOverride              bool                        `json:"deploymentWindowOverride"`
+	DeploymentWindowOverrideReason        string                      `json:"deploymentWindowOverrideReason"`
 	UserId                                int32                       `json:"-"`
 	EnvId                                 int                         `json:"-"`
 	EnvName                               string                      `json:"-"`
@@ -74,6 +76,8 @@ type ValuesOverrideRequest struct {
 	PipelineName                          string                      `json:"-"`
 	DeploymentAppType                     string                      `json:"-"`
 	Image                                 string                      `json:"-"`
+	IsAutoRollback                        bool                        `json:"-"`
+	IsDryRun                              bool                        `json:"-"`
 }
 
 type BulkCdDeployEvent struct {
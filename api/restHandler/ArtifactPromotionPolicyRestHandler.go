@@ -0,0 +1,167 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/artifactPromotion"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type ArtifactPromotionPolicyRestHandler interface {
+	CreatePolicy(w http.ResponseWriter, r *http.Request)
+	UpdatePolicy(w http.ResponseWriter, r *http.Request)
+	DeletePolicy(w http.ResponseWriter, r *http.Request)
+	GetPolicyById(w http.ResponseWriter, r *http.Request)
+	GetAllPolicies(w http.ResponseWriter, r *http.Request)
+}
+
+type ArtifactPromotionPolicyRestHandlerImpl struct {
+	logger                         *zap.SugaredLogger
+	userService                    user.UserService
+	enforcer                       casbin.Enforcer
+	validator                      *validator.Validate
+	artifactPromotionPolicyService artifactPromotion.ArtifactPromotionPolicyService
+}
+
+func NewArtifactPromotionPolicyRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcer casbin.Enforcer, validator *validator.Validate,
+	artifactPromotionPolicyService artifactPromotion.ArtifactPromotionPolicyService) *ArtifactPromotionPolicyRestHandlerImpl {
+	return &ArtifactPromotionPolicyRestHandlerImpl{
+		logger:                         logger,
+		userService:                    userService,
+		enforcer:                       enforcer,
+		validator:                      validator,
+		artifactPromotionPolicyService: artifactPromotionPolicyService,
+	}
+}
+
+func (handler *ArtifactPromotionPolicyRestHandlerImpl) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	handler.savePolicy(w, r, false)
+}
+
+func (handler *ArtifactPromotionPolicyRestHandlerImpl) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	handler.savePolicy(w, r, true)
+}
+
+func (handler *ArtifactPromotionPolicyRestHandlerImpl) savePolicy(w http.ResponseWriter, r *http.Request, isUpdate bool) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request artifactPromotion.ArtifactPromotionPolicyDto
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, savePolicy", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, savePolicy", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	handler.logger.Infow("request payload, savePolicy", "payload", request, "isUpdate", isUpdate)
+	var resp *artifactPromotion.ArtifactPromotionPolicyDto
+	if isUpdate {
+		resp, err = handler.artifactPromotionPolicyService.UpdatePolicy(&request)
+	} else {
+		resp, err = handler.artifactPromotionPolicyService.CreatePolicy(&request)
+	}
+	if err != nil {
+		handler.logger.Errorw("service err, savePolicy", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *ArtifactPromotionPolicyRestHandlerImpl) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionDelete, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	err = handler.artifactPromotionPolicyService.DeletePolicy(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeletePolicy", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (handler *ArtifactPromotionPolicyRestHandlerImpl) GetPolicyById(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.artifactPromotionPolicyService.GetPolicyById(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetPolicyById", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *ArtifactPromotionPolicyRestHandlerImpl) GetAllPolicies(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.artifactPromotionPolicyService.GetAllPolicies()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllPolicies", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
@@ -0,0 +1,218 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/artifactRetention"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type ArtifactRetentionRestHandler interface {
+	CreatePolicy(w http.ResponseWriter, r *http.Request)
+	UpdatePolicy(w http.ResponseWriter, r *http.Request)
+	DeletePolicy(w http.ResponseWriter, r *http.Request)
+	GetAllPolicies(w http.ResponseWriter, r *http.Request)
+	DryRun(w http.ResponseWriter, r *http.Request)
+	GetGcRuns(w http.ResponseWriter, r *http.Request)
+	GetGcRun(w http.ResponseWriter, r *http.Request)
+}
+
+type ArtifactRetentionRestHandlerImpl struct {
+	logger                   *zap.SugaredLogger
+	userService              user.UserService
+	enforcer                 casbin.Enforcer
+	validator                *validator.Validate
+	artifactRetentionService artifactRetention.ArtifactRetentionService
+}
+
+func NewArtifactRetentionRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcer casbin.Enforcer, validator *validator.Validate,
+	artifactRetentionService artifactRetention.ArtifactRetentionService) *ArtifactRetentionRestHandlerImpl {
+	return &ArtifactRetentionRestHandlerImpl{
+		logger:                   logger,
+		userService:              userService,
+		enforcer:                 enforcer,
+		validator:                validator,
+		artifactRetentionService: artifactRetentionService,
+	}
+}
+
+func (handler *ArtifactRetentionRestHandlerImpl) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	handler.savePolicy(w, r, false)
+}
+
+func (handler *ArtifactRetentionRestHandlerImpl) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	handler.savePolicy(w, r, true)
+}
+
+func (handler *ArtifactRetentionRestHandlerImpl) savePolicy(w http.ResponseWriter, r *http.Request, isUpdate bool) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request artifactRetention.ArtifactRetentionPolicyDto
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, savePolicy", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, savePolicy", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	handler.logger.Infow("request payload, savePolicy", "payload", request, "isUpdate", isUpdate)
+	var resp *artifactRetention.ArtifactRetentionPolicyDto
+	if isUpdate {
+		resp, err = handler.artifactRetentionService.UpdatePolicy(&request)
+	} else {
+		resp, err = handler.artifactRetentionService.CreatePolicy(&request)
+	}
+	if err != nil {
+		handler.logger.Errorw("service err, savePolicy", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *ArtifactRetentionRestHandlerImpl) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionDelete, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	err = handler.artifactRetentionService.DeletePolicy(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeletePolicy", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (handler *ArtifactRetentionRestHandlerImpl) GetAllPolicies(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.artifactRetentionService.GetAllPolicies()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllPolicies", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+// DryRun lists the artifacts the policy would delete if the garbage collector ran now
+func (handler *ArtifactRetentionRestHandlerImpl) DryRun(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.artifactRetentionService.DryRun(id)
+	if err != nil {
+		handler.logger.Errorw("service err, DryRun", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *ArtifactRetentionRestHandlerImpl) GetGcRuns(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.artifactRetentionService.GetGcRuns(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetGcRuns", "err", err, "policyId", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *ArtifactRetentionRestHandlerImpl) GetGcRun(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.artifactRetentionService.GetGcRun(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetGcRun", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
@@ -0,0 +1,235 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/ciBuildQueue"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type CiBuildQueueRestHandler interface {
+	CreateQuota(w http.ResponseWriter, r *http.Request)
+	UpdateQuota(w http.ResponseWriter, r *http.Request)
+	DeleteQuota(w http.ResponseWriter, r *http.Request)
+	GetAllQuotas(w http.ResponseWriter, r *http.Request)
+	GetQueuedBuilds(w http.ResponseWriter, r *http.Request)
+	GetPipelinePriority(w http.ResponseWriter, r *http.Request)
+	SavePipelinePriority(w http.ResponseWriter, r *http.Request)
+}
+
+type CiBuildQueueRestHandlerImpl struct {
+	logger              *zap.SugaredLogger
+	userService         user.UserService
+	enforcer            casbin.Enforcer
+	enforcerUtil        rbac.EnforcerUtil
+	validator           *validator.Validate
+	ciBuildQueueService ciBuildQueue.CiBuildQueueService
+}
+
+func NewCiBuildQueueRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcer casbin.Enforcer, enforcerUtil rbac.EnforcerUtil, validator *validator.Validate,
+	ciBuildQueueService ciBuildQueue.CiBuildQueueService) *CiBuildQueueRestHandlerImpl {
+	return &CiBuildQueueRestHandlerImpl{
+		logger:              logger,
+		userService:         userService,
+		enforcer:            enforcer,
+		enforcerUtil:        enforcerUtil,
+		validator:           validator,
+		ciBuildQueueService: ciBuildQueueService,
+	}
+}
+
+func (handler *CiBuildQueueRestHandlerImpl) CreateQuota(w http.ResponseWriter, r *http.Request) {
+	handler.saveQuota(w, r, false)
+}
+
+func (handler *CiBuildQueueRestHandlerImpl) UpdateQuota(w http.ResponseWriter, r *http.Request) {
+	handler.saveQuota(w, r, true)
+}
+
+func (handler *CiBuildQueueRestHandlerImpl) saveQuota(w http.ResponseWriter, r *http.Request, isUpdate bool) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request ciBuildQueue.CiBuildQuotaDto
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, saveQuota", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, saveQuota", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	handler.logger.Infow("request payload, saveQuota", "payload", request, "isUpdate", isUpdate)
+	var resp *ciBuildQueue.CiBuildQuotaDto
+	if isUpdate {
+		resp, err = handler.ciBuildQueueService.UpdateQuota(&request)
+	} else {
+		resp, err = handler.ciBuildQueueService.CreateQuota(&request)
+	}
+	if err != nil {
+		handler.logger.Errorw("service err, saveQuota", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *CiBuildQueueRestHandlerImpl) DeleteQuota(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionDelete, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	err = handler.ciBuildQueueService.DeleteQuota(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteQuota", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (handler *CiBuildQueueRestHandlerImpl) GetAllQuotas(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.ciBuildQueueService.GetAllQuotas()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllQuotas", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *CiBuildQueueRestHandlerImpl) GetQueuedBuilds(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	teamId := 0
+	if teamIdParam := r.URL.Query().Get("teamId"); len(teamIdParam) > 0 {
+		teamId, err = strconv.Atoi(teamIdParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.ciBuildQueueService.GetQueuedBuilds(teamId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetQueuedBuilds", "err", err, "teamId", teamId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *CiBuildQueueRestHandlerImpl) GetPipelinePriority(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciPipelineId, err := strconv.Atoi(mux.Vars(r)["ciPipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionGet); !ok {
+		return
+	}
+	resp, err := handler.ciBuildQueueService.GetPipelinePriority(ciPipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetPipelinePriority", "err", err, "ciPipelineId", ciPipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *CiBuildQueueRestHandlerImpl) SavePipelinePriority(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciPipelineId, err := strconv.Atoi(mux.Vars(r)["ciPipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var request ciBuildQueue.CiPipelineBuildPriorityDto
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, SavePipelinePriority", "err", err, "ciPipelineId", ciPipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.CiPipelineId = ciPipelineId
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, SavePipelinePriority", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionUpdate); !ok {
+		return
+	}
+	resp, err := handler.ciBuildQueueService.SavePipelinePriority(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, SavePipelinePriority", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
@@ -18,13 +18,16 @@
 package restHandler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
 	"github.com/devtron-labs/devtron/pkg/auth/user"
 	"github.com/devtron-labs/devtron/pkg/commonService"
 	"github.com/devtron-labs/devtron/pkg/gitops"
+	"github.com/devtron-labs/devtron/util/rbac"
 	"go.uber.org/zap"
 	"gopkg.in/go-playground/validator.v9"
 )
@@ -71,3 +74,20 @@ func (impl CommonRestHanlderImpl) GlobalChecklist(w http.ResponseWriter, r *http
 
 	common.WriteJsonResp(w, err, res, http.StatusOK)
 }
+
+// checkCiPipelineRbac enforces the app rbac of the ci pipeline, writing the error response if it fails
+func checkCiPipelineRbac(w http.ResponseWriter, r *http.Request, enforcerUtil rbac.EnforcerUtil, ciPipelineId int, action string) bool {
+	ok, err := enforcerUtil.CheckCiPipelineRbac(r.Header.Get("token"), ciPipelineId, action)
+	if util.IsErrNoRows(err) {
+		common.WriteJsonResp(w, err, "ci pipeline not found", http.StatusNotFound)
+		return false
+	} else if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return false
+	}
+	if !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return false
+	}
+	return true
+}
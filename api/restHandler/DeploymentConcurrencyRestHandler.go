@@ -0,0 +1,204 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/deploymentConcurrency"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type DeploymentConcurrencyRestHandler interface {
+	CreateLimit(w http.ResponseWriter, r *http.Request)
+	UpdateLimit(w http.ResponseWriter, r *http.Request)
+	DeleteLimit(w http.ResponseWriter, r *http.Request)
+	GetLimitById(w http.ResponseWriter, r *http.Request)
+	GetAllLimits(w http.ResponseWriter, r *http.Request)
+	GetQueuedDeployments(w http.ResponseWriter, r *http.Request)
+}
+
+type DeploymentConcurrencyRestHandlerImpl struct {
+	logger                       *zap.SugaredLogger
+	userService                  user.UserService
+	enforcer                     casbin.Enforcer
+	validator                    *validator.Validate
+	deploymentConcurrencyService deploymentConcurrency.DeploymentConcurrencyService
+}
+
+func NewDeploymentConcurrencyRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcer casbin.Enforcer, validator *validator.Validate,
+	deploymentConcurrencyService deploymentConcurrency.DeploymentConcurrencyService) *DeploymentConcurrencyRestHandlerImpl {
+	return &DeploymentConcurrencyRestHandlerImpl{
+		logger:                       logger,
+		userService:                  userService,
+		enforcer:                     enforcer,
+		validator:                    validator,
+		deploymentConcurrencyService: deploymentConcurrencyService,
+	}
+}
+
+func (handler *DeploymentConcurrencyRestHandlerImpl) CreateLimit(w http.ResponseWriter, r *http.Request) {
+	handler.saveLimit(w, r, false)
+}
+
+func (handler *DeploymentConcurrencyRestHandlerImpl) UpdateLimit(w http.ResponseWriter, r *http.Request) {
+	handler.saveLimit(w, r, true)
+}
+
+func (handler *DeploymentConcurrencyRestHandlerImpl) saveLimit(w http.ResponseWriter, r *http.Request, isUpdate bool) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request deploymentConcurrency.DeploymentConcurrencyLimitDto
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, saveLimit", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, saveLimit", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	handler.logger.Infow("request payload, saveLimit", "payload", request, "isUpdate", isUpdate)
+	var resp *deploymentConcurrency.DeploymentConcurrencyLimitDto
+	if isUpdate {
+		resp, err = handler.deploymentConcurrencyService.UpdateLimit(&request)
+	} else {
+		resp, err = handler.deploymentConcurrencyService.CreateLimit(&request)
+	}
+	if err != nil {
+		handler.logger.Errorw("service err, saveLimit", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *DeploymentConcurrencyRestHandlerImpl) DeleteLimit(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionDelete, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	err = handler.deploymentConcurrencyService.DeleteLimit(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteLimit", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (handler *DeploymentConcurrencyRestHandlerImpl) GetLimitById(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.deploymentConcurrencyService.GetLimitById(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetLimitById", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *DeploymentConcurrencyRestHandlerImpl) GetAllLimits(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.deploymentConcurrencyService.GetAllLimits()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllLimits", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *DeploymentConcurrencyRestHandlerImpl) GetQueuedDeployments(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	v := r.URL.Query()
+	envId, clusterId := 0, 0
+	if envIdParam := v.Get("envId"); len(envIdParam) > 0 {
+		envId, err = strconv.Atoi(envIdParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	if clusterIdParam := v.Get("clusterId"); len(clusterIdParam) > 0 {
+		clusterId, err = strconv.Atoi(clusterIdParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.deploymentConcurrencyService.GetQueuedDeployments(envId, clusterId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetQueuedDeployments", "err", err, "envId", envId, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
@@ -0,0 +1,205 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/deploymentWindow"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type DeploymentWindowRestHandler interface {
+	CreateWindow(w http.ResponseWriter, r *http.Request)
+	UpdateWindow(w http.ResponseWriter, r *http.Request)
+	DeleteWindow(w http.ResponseWriter, r *http.Request)
+	GetWindowById(w http.ResponseWriter, r *http.Request)
+	GetAllWindows(w http.ResponseWriter, r *http.Request)
+	GetDeploymentWindowState(w http.ResponseWriter, r *http.Request)
+}
+
+type DeploymentWindowRestHandlerImpl struct {
+	logger                  *zap.SugaredLogger
+	userService             user.UserService
+	enforcer                casbin.Enforcer
+	enforcerUtil            rbac.EnforcerUtil
+	validator               *validator.Validate
+	deploymentWindowService deploymentWindow.DeploymentWindowService
+}
+
+func NewDeploymentWindowRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcer casbin.Enforcer, enforcerUtil rbac.EnforcerUtil, validator *validator.Validate,
+	deploymentWindowService deploymentWindow.DeploymentWindowService) *DeploymentWindowRestHandlerImpl {
+	return &DeploymentWindowRestHandlerImpl{
+		logger:                  logger,
+		userService:             userService,
+		enforcer:                enforcer,
+		enforcerUtil:            enforcerUtil,
+		validator:               validator,
+		deploymentWindowService: deploymentWindowService,
+	}
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) CreateWindow(w http.ResponseWriter, r *http.Request) {
+	handler.saveWindow(w, r, false)
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) UpdateWindow(w http.ResponseWriter, r *http.Request) {
+	handler.saveWindow(w, r, true)
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) saveWindow(w http.ResponseWriter, r *http.Request, isUpdate bool) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request deploymentWindow.DeploymentWindowDto
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, saveWindow", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, saveWindow", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	handler.logger.Infow("request payload, saveWindow", "payload", request, "isUpdate", isUpdate)
+	var resp *deploymentWindow.DeploymentWindowDto
+	if isUpdate {
+		resp, err = handler.deploymentWindowService.UpdateWindow(&request)
+	} else {
+		resp, err = handler.deploymentWindowService.CreateWindow(&request)
+	}
+	if err != nil {
+		handler.logger.Errorw("service err, saveWindow", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) DeleteWindow(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionDelete, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	err = handler.deploymentWindowService.DeleteWindow(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteWindow", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) GetWindowById(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.deploymentWindowService.GetWindowById(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetWindowById", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *DeploymentWindowRestHandlerImpl) GetAllWindows(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.deploymentWindowService.GetAllWindows()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllWindows", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+// GetDeploymentWindowState tells whether a deployment on app and env is allowed right now, used by trigger view
+func (handler *DeploymentWindowRestHandlerImpl) GetDeploymentWindowState(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	appId, err := strconv.Atoi(vars["appId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	envId, err := strconv.Atoi(vars["envId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.deploymentWindowService.GetDeploymentWindowState(appId, envId, time.Now())
+	if err != nil {
+		handler.logger.Errorw("service err, GetDeploymentWindowState", "err", err, "appId", appId, "envId", envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
@@ -0,0 +1,252 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/gitSync"
+	"github.com/go-pg/pg"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+const defaultGitSyncResultsLimit = 20
+
+type GitSyncRestHandler interface {
+	CreateConfig(w http.ResponseWriter, r *http.Request)
+	UpdateConfig(w http.ResponseWriter, r *http.Request)
+	GetConfig(w http.ResponseWriter, r *http.Request)
+	GetAllConfigs(w http.ResponseWriter, r *http.Request)
+	DeleteConfig(w http.ResponseWriter, r *http.Request)
+	Sync(w http.ResponseWriter, r *http.Request)
+	GetLatestResult(w http.ResponseWriter, r *http.Request)
+	GetResults(w http.ResponseWriter, r *http.Request)
+}
+
+type GitSyncRestHandlerImpl struct {
+	logger         *zap.SugaredLogger
+	userService    user.UserService
+	enforcer       casbin.Enforcer
+	validator      *validator.Validate
+	gitSyncService gitSync.GitSyncService
+}
+
+func NewGitSyncRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcer casbin.Enforcer, validator *validator.Validate,
+	gitSyncService gitSync.GitSyncService) *GitSyncRestHandlerImpl {
+	return &GitSyncRestHandlerImpl{
+		logger:         logger,
+		userService:    userService,
+		enforcer:       enforcer,
+		validator:      validator,
+		gitSyncService: gitSyncService,
+	}
+}
+
+func (handler *GitSyncRestHandlerImpl) CreateConfig(w http.ResponseWriter, r *http.Request) {
+	handler.saveConfig(w, r, false)
+}
+
+func (handler *GitSyncRestHandlerImpl) UpdateConfig(w http.ResponseWriter, r *http.Request) {
+	handler.saveConfig(w, r, true)
+}
+
+func (handler *GitSyncRestHandlerImpl) saveConfig(w http.ResponseWriter, r *http.Request, isUpdate bool) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request gitSync.GitSyncConfigDto
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveGitSyncConfig", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	if isUpdate && request.Id == 0 {
+		common.WriteJsonResp(w, errors.New("id is required to update git sync config"), nil, http.StatusBadRequest)
+		return
+	} else if !isUpdate {
+		request.Id = 0
+	}
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, SaveGitSyncConfig", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	handler.logger.Infow("request payload, SaveGitSyncConfig", "payload", request)
+	var resp *gitSync.GitSyncConfigDto
+	if isUpdate {
+		resp, err = handler.gitSyncService.UpdateConfig(&request)
+	} else {
+		resp, err = handler.gitSyncService.CreateConfig(&request)
+	}
+	if err == pg.ErrNoRows {
+		common.WriteJsonResp(w, errors.New("git sync config not found"), nil, http.StatusNotFound)
+		return
+	} else if err != nil {
+		handler.logger.Errorw("service err, SaveGitSyncConfig", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *GitSyncRestHandlerImpl) GetConfig(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := handler.authoriseAndGetConfigId(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	resp, err := handler.gitSyncService.GetConfig(id)
+	if err == pg.ErrNoRows {
+		common.WriteJsonResp(w, errors.New("git sync config not found"), nil, http.StatusNotFound)
+		return
+	} else if err != nil {
+		handler.logger.Errorw("service err, GetGitSyncConfig", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *GitSyncRestHandlerImpl) GetAllConfigs(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.gitSyncService.GetAllConfigs()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllGitSyncConfigs", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *GitSyncRestHandlerImpl) DeleteConfig(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := handler.authoriseAndGetConfigId(w, r, casbin.ActionDelete)
+	if !ok {
+		return
+	}
+	err := handler.gitSyncService.DeleteConfig(id, userId)
+	if err == pg.ErrNoRows {
+		common.WriteJsonResp(w, errors.New("git sync config not found"), nil, http.StatusNotFound)
+		return
+	} else if err != nil {
+		handler.logger.Errorw("service err, DeleteGitSyncConfig", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (handler *GitSyncRestHandlerImpl) Sync(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := handler.authoriseAndGetConfigId(w, r, casbin.ActionUpdate)
+	if !ok {
+		return
+	}
+	dryRun := false
+	if dryRunParam := r.URL.Query().Get("dryRun"); len(dryRunParam) > 0 {
+		var err error
+		dryRun, err = strconv.ParseBool(dryRunParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	handler.logger.Infow("request payload, SyncGitSyncConfig", "id", id, "dryRun", dryRun)
+	resp, err := handler.gitSyncService.Sync(id, dryRun, userId)
+	if err == pg.ErrNoRows {
+		common.WriteJsonResp(w, errors.New("git sync config not found"), nil, http.StatusNotFound)
+		return
+	} else if err != nil {
+		handler.logger.Errorw("service err, SyncGitSyncConfig", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *GitSyncRestHandlerImpl) GetLatestResult(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := handler.authoriseAndGetConfigId(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	resp, err := handler.gitSyncService.GetLatestResult(id)
+	if err == pg.ErrNoRows {
+		common.WriteJsonResp(w, errors.New("no git sync result found"), nil, http.StatusNotFound)
+		return
+	} else if err != nil {
+		handler.logger.Errorw("service err, GetLatestGitSyncResult", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *GitSyncRestHandlerImpl) GetResults(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := handler.authoriseAndGetConfigId(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	limit := defaultGitSyncResultsLimit
+	if limitParam := r.URL.Query().Get("limit"); len(limitParam) > 0 {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			common.WriteJsonResp(w, errors.New("limit must be a positive number"), nil, http.StatusBadRequest)
+			return
+		}
+	}
+	resp, err := handler.gitSyncService.GetResults(id, limit)
+	if err != nil {
+		handler.logger.Errorw("service err, GetGitSyncResults", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+// authoriseAndGetConfigId writes the error response itself and returns false if the user is not logged in, is not a super admin
+// or the config id in path is invalid
+func (handler *GitSyncRestHandlerImpl) authoriseAndGetConfigId(w http.ResponseWriter, r *http.Request, action string) (int32, int, bool) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return 0, 0, false
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return 0, 0, false
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, action, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return 0, 0, false
+	}
+	return userId, id, true
+}
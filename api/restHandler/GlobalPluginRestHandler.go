@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/devtron-labs/devtron/api/restHandler/common"
 	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
@@ -25,11 +27,22 @@ type GlobalPluginRestHandler interface {
 	GetPluginDetailById(w http.ResponseWriter, r *http.Request)
 	GetDetailedPluginInfoByPluginId(w http.ResponseWriter, r *http.Request)
 	GetAllDetailedPluginInfo(w http.ResponseWriter, r *http.Request)
+
+	PublishPluginVersion(w http.ResponseWriter, r *http.Request)
+	GetPluginVersions(w http.ResponseWriter, r *http.Request)
+	DeprecatePluginVersion(w http.ResponseWriter, r *http.Request)
+	GetOutdatedPluginUsages(w http.ResponseWriter, r *http.Request)
+	UpgradePluginUsages(w http.ResponseWriter, r *http.Request)
+
+	ExportPluginBundle(w http.ResponseWriter, r *http.Request)
+	PushPluginBundle(w http.ResponseWriter, r *http.Request)
+	ImportPluginBundle(w http.ResponseWriter, r *http.Request)
+	ImportPluginBundleFromRegistry(w http.ResponseWriter, r *http.Request)
 }
 
 func NewGlobalPluginRestHandler(logger *zap.SugaredLogger, globalPluginService plugin.GlobalPluginService,
 	enforcerUtil rbac.EnforcerUtil, enforcer casbin.Enforcer, pipelineBuilder pipeline.PipelineBuilder,
-	userService user.UserService) *GlobalPluginRestHandlerImpl {
+	userService user.UserService, pluginBundleService plugin.PluginBundleService) *GlobalPluginRestHandlerImpl {
 	return &GlobalPluginRestHandlerImpl{
 		logger:              logger,
 		globalPluginService: globalPluginService,
@@ -37,6 +50,7 @@ func NewGlobalPluginRestHandler(logger *zap.SugaredLogger, globalPluginService p
 		enforcer:            enforcer,
 		pipelineBuilder:     pipelineBuilder,
 		userService:         userService,
+		pluginBundleService: pluginBundleService,
 	}
 }
 
@@ -47,6 +61,7 @@ type GlobalPluginRestHandlerImpl struct {
 	enforcer            casbin.Enforcer
 	pipelineBuilder     pipeline.PipelineBuilder
 	userService         user.UserService
+	pluginBundleService plugin.PluginBundleService
 }
 
 func (handler *GlobalPluginRestHandlerImpl) PatchPlugin(w http.ResponseWriter, r *http.Request) {
@@ -237,3 +252,304 @@ func (handler *GlobalPluginRestHandlerImpl) GetPluginDetailById(w http.ResponseW
 	}
 	common.WriteJsonResp(w, err, pluginDetail, http.StatusOK)
 }
+
+func (handler *GlobalPluginRestHandlerImpl) PublishPluginVersion(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	pluginId, err := strconv.Atoi(mux.Vars(r)["pluginId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var pluginDataDto plugin.PluginMetadataDto
+	err = json.NewDecoder(r.Body).Decode(&pluginDataDto)
+	if err != nil {
+		handler.logger.Errorw("request err, PublishPluginVersion", "pluginId", pluginId, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	pluginDataDto.Id = pluginId
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	pluginData, err := handler.globalPluginService.PublishPluginVersion(&pluginDataDto, userId)
+	if err != nil {
+		handler.logger.Errorw("error in publishing plugin version", "pluginId", pluginId, "pluginVersion", pluginDataDto.PluginVersion, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, pluginData, http.StatusOK)
+}
+
+func (handler *GlobalPluginRestHandlerImpl) GetPluginVersions(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	pluginId, err := strconv.Atoi(mux.Vars(r)["pluginId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	versions, err := handler.globalPluginService.GetPluginVersions(pluginId)
+	if err != nil {
+		handler.logger.Errorw("error in getting plugin versions", "pluginId", pluginId, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, versions, http.StatusOK)
+}
+
+func (handler *GlobalPluginRestHandlerImpl) DeprecatePluginVersion(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	pluginId, err := strconv.Atoi(mux.Vars(r)["pluginId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var request plugin.PluginDeprecationRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, DeprecatePluginVersion", "pluginId", pluginId, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.PluginId = pluginId
+	request.UserId = userId
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	err = handler.globalPluginService.DeprecatePluginVersion(&request)
+	if err != nil {
+		handler.logger.Errorw("error in deprecating plugin version", "request", request, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, request, http.StatusOK)
+}
+
+func (handler *GlobalPluginRestHandlerImpl) GetOutdatedPluginUsages(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	pluginId, err := strconv.Atoi(mux.Vars(r)["pluginId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	outdated, err := handler.globalPluginService.GetOutdatedPluginUsages(pluginId)
+	if err != nil {
+		handler.logger.Errorw("error in getting outdated plugin usages", "pluginId", pluginId, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, outdated, http.StatusOK)
+}
+
+func (handler *GlobalPluginRestHandlerImpl) UpgradePluginUsages(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	pluginId, err := strconv.Atoi(mux.Vars(r)["pluginId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var request plugin.PluginUpgradeRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, UpgradePluginUsages", "pluginId", pluginId, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.PluginId = pluginId
+	request.UserId = userId
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	response, err := handler.globalPluginService.UpgradePluginUsages(&request)
+	if err != nil {
+		handler.logger.Errorw("error in upgrading plugin usages", "request", request, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
+// maxPluginBundleSize limits the size of uploaded plugin bundles
+const maxPluginBundleSize = 10 << 20
+
+func (handler *GlobalPluginRestHandlerImpl) ExportPluginBundle(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var pluginIds []int
+	for _, id := range strings.Split(r.URL.Query().Get("pluginIds"), ",") {
+		pluginId, err := strconv.Atoi(strings.TrimSpace(id))
+		if err != nil {
+			common.WriteJsonResp(w, fmt.Errorf("invalid plugin id %q", id), nil, http.StatusBadRequest)
+			return
+		}
+		pluginIds = append(pluginIds, pluginId)
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	content, err := handler.pluginBundleService.ExportPluginBundle(pluginIds)
+	if err != nil {
+		handler.logger.Errorw("error in exporting plugin bundle", "pluginIds", pluginIds, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename="+plugin.PLUGIN_BUNDLE_FILE_NAME)
+	w.Header().Set("Content-Type", "application/x-yaml")
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	_, err = w.Write(content)
+	if err != nil {
+		handler.logger.Errorw("error in writing plugin bundle", "pluginIds", pluginIds, "err", err)
+	}
+}
+
+func (handler *GlobalPluginRestHandlerImpl) PushPluginBundle(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request plugin.PluginBundlePushRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, PushPluginBundle", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if len(request.PluginIds) == 0 || !isValidPluginBundleRegistry(request.Registry) {
+		common.WriteJsonResp(w, errors.New("pluginIds and registry with dockerRegistryId and repository are required"), nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	response, err := handler.pluginBundleService.PushPluginBundle(&request)
+	if err != nil {
+		handler.logger.Errorw("error in pushing plugin bundle", "request", request, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
+// ImportPluginBundle imports the bundle uploaded as the file bundle of a multipart form or as the request body
+func (handler *GlobalPluginRestHandlerImpl) ImportPluginBundle(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	request := &plugin.PluginBundleImportRequest{
+		DryRun:        r.URL.Query().Get("dryRun") == "true",
+		SkipConflicts: r.URL.Query().Get("skipConflicts") == "true",
+		UserId:        userId,
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxPluginBundleSize)
+	var content []byte
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("bundle")
+		if err != nil {
+			handler.logger.Errorw("request err, ImportPluginBundle", "err", err)
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		content, err = io.ReadAll(file)
+	} else {
+		content, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		handler.logger.Errorw("request err, ImportPluginBundle", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	response, err := handler.pluginBundleService.ImportPluginBundle(content, request)
+	if err != nil {
+		handler.logger.Errorw("error in importing plugin bundle", "dryRun", request.DryRun, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
+func (handler *GlobalPluginRestHandlerImpl) ImportPluginBundleFromRegistry(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request plugin.PluginBundleImportRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, ImportPluginBundleFromRegistry", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if !isValidPluginBundleRegistry(request.Registry) {
+		common.WriteJsonResp(w, errors.New("registry with dockerRegistryId and repository is required"), nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	response, err := handler.pluginBundleService.ImportPluginBundleFromRegistry(&request)
+	if err != nil {
+		handler.logger.Errorw("error in importing plugin bundle from registry", "registry", request.Registry, "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
+func isValidPluginBundleRegistry(registry *plugin.PluginBundleRegistryReference) bool {
+	return registry != nil && len(registry.DockerRegistryId) > 0 && len(registry.Repository) > 0
+}
@@ -0,0 +1,364 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/imageSigning"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/go-pg/pg"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type ImageSigningRestHandler interface {
+	CreateKey(w http.ResponseWriter, r *http.Request)
+	UpdateKey(w http.ResponseWriter, r *http.Request)
+	DeleteKey(w http.ResponseWriter, r *http.Request)
+	GetAllKeys(w http.ResponseWriter, r *http.Request)
+
+	CreatePolicy(w http.ResponseWriter, r *http.Request)
+	UpdatePolicy(w http.ResponseWriter, r *http.Request)
+	DeletePolicy(w http.ResponseWriter, r *http.Request)
+	GetAllPolicies(w http.ResponseWriter, r *http.Request)
+
+	GetCiPipelineSigningConfig(w http.ResponseWriter, r *http.Request)
+	SaveCiPipelineSigningConfig(w http.ResponseWriter, r *http.Request)
+
+	GetArtifactVerifications(w http.ResponseWriter, r *http.Request)
+}
+
+type ImageSigningRestHandlerImpl struct {
+	logger               *zap.SugaredLogger
+	userService          user.UserService
+	enforcer             casbin.Enforcer
+	enforcerUtil         rbac.EnforcerUtil
+	validator            *validator.Validate
+	ciArtifactRepository repository.CiArtifactRepository
+	imageSigningService  imageSigning.ImageSigningService
+}
+
+func NewImageSigningRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcer casbin.Enforcer, enforcerUtil rbac.EnforcerUtil, validator *validator.Validate,
+	ciArtifactRepository repository.CiArtifactRepository,
+	imageSigningService imageSigning.ImageSigningService) *ImageSigningRestHandlerImpl {
+	return &ImageSigningRestHandlerImpl{
+		logger:               logger,
+		userService:          userService,
+		enforcer:             enforcer,
+		enforcerUtil:         enforcerUtil,
+		validator:            validator,
+		ciArtifactRepository: ciArtifactRepository,
+		imageSigningService:  imageSigningService,
+	}
+}
+
+func (handler *ImageSigningRestHandlerImpl) CreateKey(w http.ResponseWriter, r *http.Request) {
+	handler.saveKey(w, r, false)
+}
+
+func (handler *ImageSigningRestHandlerImpl) UpdateKey(w http.ResponseWriter, r *http.Request) {
+	handler.saveKey(w, r, true)
+}
+
+func (handler *ImageSigningRestHandlerImpl) saveKey(w http.ResponseWriter, r *http.Request, isUpdate bool) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request imageSigning.ImageSigningKeyDto
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, saveKey", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, saveKey", "err", err, "name", request.Name)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	// the private key and its password are never logged
+	handler.logger.Infow("request payload, saveKey", "id", request.Id, "name", request.Name, "isUpdate", isUpdate)
+	var resp *imageSigning.ImageSigningKeyDto
+	if isUpdate {
+		resp, err = handler.imageSigningService.UpdateKey(&request)
+	} else {
+		resp, err = handler.imageSigningService.CreateKey(&request)
+	}
+	if err != nil {
+		handler.logger.Errorw("service err, saveKey", "err", err, "id", request.Id, "name", request.Name)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *ImageSigningRestHandlerImpl) DeleteKey(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionDelete, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	err = handler.imageSigningService.DeleteKey(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteKey", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (handler *ImageSigningRestHandlerImpl) GetAllKeys(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.imageSigningService.GetAllKeys()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllKeys", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *ImageSigningRestHandlerImpl) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	handler.savePolicy(w, r, false)
+}
+
+func (handler *ImageSigningRestHandlerImpl) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	handler.savePolicy(w, r, true)
+}
+
+func (handler *ImageSigningRestHandlerImpl) savePolicy(w http.ResponseWriter, r *http.Request, isUpdate bool) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request imageSigning.ImageSignaturePolicyDto
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, savePolicy", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, savePolicy", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	handler.logger.Infow("request payload, savePolicy", "payload", request, "isUpdate", isUpdate)
+	var resp *imageSigning.ImageSignaturePolicyDto
+	if isUpdate {
+		resp, err = handler.imageSigningService.UpdatePolicy(&request)
+	} else {
+		resp, err = handler.imageSigningService.CreatePolicy(&request)
+	}
+	if err != nil {
+		handler.logger.Errorw("service err, savePolicy", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *ImageSigningRestHandlerImpl) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionDelete, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	err = handler.imageSigningService.DeletePolicy(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeletePolicy", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (handler *ImageSigningRestHandlerImpl) GetAllPolicies(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.imageSigningService.GetAllPolicies()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllPolicies", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *ImageSigningRestHandlerImpl) GetCiPipelineSigningConfig(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciPipelineId, err := strconv.Atoi(mux.Vars(r)["ciPipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionGet); !ok {
+		return
+	}
+	resp, err := handler.imageSigningService.GetCiPipelineSigningConfig(ciPipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetCiPipelineSigningConfig", "err", err, "ciPipelineId", ciPipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *ImageSigningRestHandlerImpl) SaveCiPipelineSigningConfig(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciPipelineId, err := strconv.Atoi(mux.Vars(r)["ciPipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request imageSigning.CiPipelineSigningConfigDto
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveCiPipelineSigningConfig", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.CiPipelineId = ciPipelineId
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, SaveCiPipelineSigningConfig", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionUpdate); !ok {
+		return
+	}
+	handler.logger.Infow("request payload, SaveCiPipelineSigningConfig", "payload", request)
+	resp, err := handler.imageSigningService.SaveCiPipelineSigningConfig(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, SaveCiPipelineSigningConfig", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+// GetArtifactVerifications returns the latest signature verification of the artifact for every environment it was
+// verified for
+func (handler *ImageSigningRestHandlerImpl) GetArtifactVerifications(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciArtifactId, err := strconv.Atoi(mux.Vars(r)["artifactId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	artifact, err := handler.ciArtifactRepository.Get(ciArtifactId)
+	if err == pg.ErrNoRows {
+		err = &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "ci artifact not found", UserMessage: "ci artifact not found"}
+		common.WriteJsonResp(w, err, nil, http.StatusNotFound)
+		return
+	} else if err != nil {
+		handler.logger.Errorw("error in fetching ci artifact", "err", err, "ciArtifactId", ciArtifactId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	// RBAC enforcer applying
+	if artifact.PipelineId > 0 {
+		if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, artifact.PipelineId, casbin.ActionGet); !ok {
+			return
+		}
+	} else {
+		// artifacts not built by a ci pipeline (external ci) are visible to the global viewers only
+		token := r.Header.Get("token")
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+			common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+			return
+		}
+	}
+	//RBAC enforcer Ends
+	resp, err := handler.imageSigningService.GetArtifactVerifications(ciArtifactId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetArtifactVerifications", "err", err, "ciArtifactId", ciArtifactId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/devtron-labs/devtron/pkg/app"
 	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
@@ -33,8 +34,10 @@ import (
 
 	"github.com/devtron-labs/devtron/api/bean"
 	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/deploymentConcurrency"
 	"github.com/devtron-labs/devtron/pkg/deploymentGroup"
 	"github.com/devtron-labs/devtron/pkg/pipeline"
+	bean2 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
 	"github.com/devtron-labs/devtron/pkg/team"
 	"github.com/devtron-labs/devtron/util/argo"
 	"github.com/devtron-labs/devtron/util/rbac"
@@ -44,43 +47,61 @@ import (
 
 type PipelineTriggerRestHandler interface {
 	OverrideConfig(w http.ResponseWriter, r *http.Request)
+	DryRunCdTrigger(w http.ResponseWriter, r *http.Request)
 	ReleaseStatusUpdate(w http.ResponseWriter, r *http.Request)
 	StartStopApp(w http.ResponseWriter, r *http.Request)
 	StartStopDeploymentGroup(w http.ResponseWriter, r *http.Request)
 	GetAllLatestDeploymentConfiguration(w http.ResponseWriter, r *http.Request)
 	RotatePods(w http.ResponseWriter, r *http.Request)
+	PerformDeploymentApprovalAction(w http.ResponseWriter, r *http.Request)
+	GetDeploymentApprovalData(w http.ResponseWriter, r *http.Request)
+	ScheduleDeployment(w http.ResponseWriter, r *http.Request)
+	GetScheduledDeployments(w http.ResponseWriter, r *http.Request)
+	CancelScheduledDeployment(w http.ResponseWriter, r *http.Request)
 }
 
 type PipelineTriggerRestHandlerImpl struct {
-	appService              app.AppService
-	userAuthService         user.UserService
-	validator               *validator.Validate
-	enforcer                casbin.Enforcer
-	teamService             team.TeamService
-	logger                  *zap.SugaredLogger
-	workflowDagExecutor     pipeline.WorkflowDagExecutor
-	enforcerUtil            rbac.EnforcerUtil
-	deploymentGroupService  deploymentGroup.DeploymentGroupService
-	argoUserService         argo.ArgoUserService
-	deploymentConfigService pipeline.DeploymentConfigService
+	appService                   app.AppService
+	userAuthService              user.UserService
+	validator                    *validator.Validate
+	enforcer                     casbin.Enforcer
+	teamService                  team.TeamService
+	logger                       *zap.SugaredLogger
+	workflowDagExecutor          pipeline.WorkflowDagExecutor
+	enforcerUtil                 rbac.EnforcerUtil
+	deploymentGroupService       deploymentGroup.DeploymentGroupService
+	argoUserService              argo.ArgoUserService
+	deploymentConfigService      pipeline.DeploymentConfigService
+	deploymentApprovalService    pipeline.DeploymentApprovalService
+	deploymentDryRunService      pipeline.DeploymentDryRunService
+	scheduledDeploymentService   pipeline.ScheduledDeploymentService
+	deploymentConcurrencyService deploymentConcurrency.DeploymentConcurrencyService
 }
 
 func NewPipelineRestHandler(appService app.AppService, userAuthService user.UserService, validator *validator.Validate,
 	enforcer casbin.Enforcer, teamService team.TeamService, logger *zap.SugaredLogger, enforcerUtil rbac.EnforcerUtil,
 	workflowDagExecutor pipeline.WorkflowDagExecutor, deploymentGroupService deploymentGroup.DeploymentGroupService,
-	argoUserService argo.ArgoUserService, deploymentConfigService pipeline.DeploymentConfigService) *PipelineTriggerRestHandlerImpl {
+	argoUserService argo.ArgoUserService, deploymentConfigService pipeline.DeploymentConfigService,
+	deploymentApprovalService pipeline.DeploymentApprovalService,
+	deploymentDryRunService pipeline.DeploymentDryRunService,
+	scheduledDeploymentService pipeline.ScheduledDeploymentService,
+	deploymentConcurrencyService deploymentConcurrency.DeploymentConcurrencyService) *PipelineTriggerRestHandlerImpl {
 	pipelineHandler := &PipelineTriggerRestHandlerImpl{
-		appService:              appService,
-		userAuthService:         userAuthService,
-		validator:               validator,
-		enforcer:                enforcer,
-		teamService:             teamService,
-		logger:                  logger,
-		workflowDagExecutor:     workflowDagExecutor,
-		enforcerUtil:            enforcerUtil,
-		deploymentGroupService:  deploymentGroupService,
-		argoUserService:         argoUserService,
-		deploymentConfigService: deploymentConfigService,
+		appService:                   appService,
+		userAuthService:              userAuthService,
+		validator:                    validator,
+		enforcer:                     enforcer,
+		teamService:                  teamService,
+		logger:                       logger,
+		workflowDagExecutor:          workflowDagExecutor,
+		enforcerUtil:                 enforcerUtil,
+		deploymentGroupService:       deploymentGroupService,
+		argoUserService:              argoUserService,
+		deploymentConfigService:      deploymentConfigService,
+		deploymentApprovalService:    deploymentApprovalService,
+		deploymentDryRunService:      deploymentDryRunService,
+		scheduledDeploymentService:   scheduledDeploymentService,
+		deploymentConcurrencyService: deploymentConcurrencyService,
 	}
 	return pipelineHandler
 }
@@ -120,6 +141,17 @@ func (handler PipelineTriggerRestHandlerImpl) OverrideConfig(w http.ResponseWrit
 		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
 		return
 	}
+	if overrideRequest.DeploymentWindowOverride {
+		// only super admin can deploy outside of deployment windows, and has to justify it
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
+		}
+		if len(strings.TrimSpace(overrideRequest.DeploymentWindowOverrideReason)) == 0 {
+			common.WriteJsonResp(w, fmt.Errorf("deploymentWindowOverrideReason is required for overriding deployment window"), nil, http.StatusBadRequest)
+			return
+		}
+	}
 	//rback block ends here
 	acdToken, err := handler.argoUserService.GetLatestDevtronArgoCdUserToken()
 	if err != nil {
@@ -137,7 +169,70 @@ func (handler PipelineTriggerRestHandlerImpl) OverrideConfig(w http.ResponseWrit
 		return
 	}
 	res := map[string]interface{}{"releaseId": mergeResp}
-	common.WriteJsonResp(w, err, res, http.StatusOK)
+	if overrideRequest.CdWorkflowType == bean.CD_WORKFLOW_TYPE_DEPLOY {
+		queuePositions, err := handler.deploymentConcurrencyService.GetQueuePositions([]int{overrideRequest.WfrId})
+		if err != nil {
+			handler.logger.Errorw("error in fetching deployment queue position, OverrideConfig", "err", err, "wfrId", overrideRequest.WfrId)
+		} else if queuePosition, ok := queuePositions[overrideRequest.WfrId]; ok {
+			res["queuePosition"] = queuePosition
+		}
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler PipelineTriggerRestHandlerImpl) DryRunCdTrigger(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var overrideRequest bean.ValuesOverrideRequest
+	err = decoder.Decode(&overrideRequest)
+	if err != nil {
+		handler.logger.Errorw("request err, DryRunCdTrigger", "err", err, "payload", overrideRequest)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	overrideRequest.UserId = userId
+	handler.logger.Infow("request for DryRunCdTrigger", "payload", overrideRequest)
+	err = handler.validator.Struct(overrideRequest)
+	if err != nil {
+		handler.logger.Errorw("request err, DryRunCdTrigger", "err", err, "payload", overrideRequest)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+
+	//rbac block starts from here
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(overrideRequest.AppId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionTrigger, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	object = handler.enforcerUtil.GetAppRBACByAppIdAndPipelineId(overrideRequest.AppId, overrideRequest.PipelineId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionTrigger, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//rback block ends here
+	isSuperAdmin := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*")
+	acdToken, err := handler.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		handler.logger.Errorw("error in getting acd token", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	ctx := context.WithValue(r.Context(), "token", acdToken)
+	_, span := otel.Tracer("orchestrator").Start(ctx, "deploymentDryRunService.DryRunCdTrigger")
+	resp, err := handler.deploymentDryRunService.DryRunCdTrigger(&overrideRequest, isSuperAdmin, ctx)
+	span.End()
+	if err != nil {
+		handler.logger.Errorw("service err, DryRunCdTrigger", "err", err, "payload", overrideRequest)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
 }
 
 func (handler PipelineTriggerRestHandlerImpl) RotatePods(w http.ResponseWriter, r *http.Request) {
@@ -366,3 +461,202 @@ func (handler PipelineTriggerRestHandlerImpl) GetAllLatestDeploymentConfiguratio
 	}
 	common.WriteJsonResp(w, nil, allDeploymentconfig, http.StatusOK)
 }
+
+func (handler PipelineTriggerRestHandlerImpl) PerformDeploymentApprovalAction(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var approvalActionRequest bean2.DeploymentApprovalActionRequest
+	err = decoder.Decode(&approvalActionRequest)
+	if err != nil {
+		handler.logger.Errorw("request err, PerformDeploymentApprovalAction", "err", err, "payload", approvalActionRequest)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	approvalActionRequest.UserId = userId
+	err = handler.validator.Struct(approvalActionRequest)
+	if err != nil {
+		handler.logger.Errorw("validation err, PerformDeploymentApprovalAction", "err", err, "payload", approvalActionRequest)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	//rbac block starts from here
+	// raising and cancelling a request needs trigger access, approving needs approve action on the app and environment
+	action := casbin.ActionTrigger
+	if approvalActionRequest.ActionType == bean2.APPROVAL_APPROVE_ACTION || approvalActionRequest.ActionType == bean2.APPROVAL_REJECT_ACTION {
+		action = casbin.ActionApprove
+	}
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(approvalActionRequest.AppId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, action, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	object = handler.enforcerUtil.GetAppRBACByAppIdAndPipelineId(approvalActionRequest.AppId, approvalActionRequest.PipelineId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, action, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//rbac block ends here
+	resp, err := handler.deploymentApprovalService.PerformApprovalAction(&approvalActionRequest)
+	if err != nil {
+		handler.logger.Errorw("service err, PerformDeploymentApprovalAction", "err", err, "payload", approvalActionRequest)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler PipelineTriggerRestHandlerImpl) GetDeploymentApprovalData(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	appId, err := strconv.Atoi(vars["appId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	artifactId, err := strconv.Atoi(vars["artifactId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	resp, err := handler.deploymentApprovalService.FetchApprovalData(pipelineId, artifactId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetDeploymentApprovalData", "err", err, "pipelineId", pipelineId, "artifactId", artifactId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler PipelineTriggerRestHandlerImpl) ScheduleDeployment(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var scheduleRequest bean2.ScheduledDeploymentRequest
+	err = decoder.Decode(&scheduleRequest)
+	if err != nil {
+		handler.logger.Errorw("request err, ScheduleDeployment", "err", err, "payload", scheduleRequest)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	scheduleRequest.UserId = userId
+	err = handler.validator.Struct(scheduleRequest)
+	if err != nil {
+		handler.logger.Errorw("validation err, ScheduleDeployment", "err", err, "payload", scheduleRequest)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if !handler.isTriggerAllowed(token, scheduleRequest.AppId, scheduleRequest.PipelineId) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	resp, err := handler.scheduledDeploymentService.ScheduleDeployment(&scheduleRequest)
+	if err != nil {
+		handler.logger.Errorw("service err, ScheduleDeployment", "err", err, "payload", scheduleRequest)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler PipelineTriggerRestHandlerImpl) GetScheduledDeployments(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	appId, err := strconv.Atoi(vars["appId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	resp, err := handler.scheduledDeploymentService.GetScheduledDeployments(appId, pipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetScheduledDeployments", "err", err, "appId", appId, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler PipelineTriggerRestHandlerImpl) CancelScheduledDeployment(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	appId, err := strconv.Atoi(vars["appId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	scheduledDeployment, err := handler.scheduledDeploymentService.GetScheduledDeploymentById(id)
+	if err != nil {
+		handler.logger.Errorw("service err, CancelScheduledDeployment", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if !handler.isTriggerAllowed(token, appId, scheduledDeployment.PipelineId) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	err = handler.scheduledDeploymentService.CancelScheduledDeployment(appId, id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, CancelScheduledDeployment", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+// isTriggerAllowed checks trigger access on the app and on the environment of the cd pipeline
+func (handler PipelineTriggerRestHandlerImpl) isTriggerAllowed(token string, appId int, pipelineId int) bool {
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionTrigger, object); !ok {
+		return false
+	}
+	object = handler.enforcerUtil.GetAppRBACByAppIdAndPipelineId(appId, pipelineId)
+	return handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionTrigger, object)
+}
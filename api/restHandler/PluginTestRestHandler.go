@@ -0,0 +1,214 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	pipelineStageRepository "github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	"github.com/devtron-labs/devtron/pkg/pluginTest"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type PluginTestRestHandler interface {
+	TestPlugin(w http.ResponseWriter, r *http.Request)
+	TestStage(w http.ResponseWriter, r *http.Request)
+	GetRun(w http.ResponseWriter, r *http.Request)
+	SaveResult(w http.ResponseWriter, r *http.Request)
+}
+
+type PluginTestRestHandlerImpl struct {
+	logger               *zap.SugaredLogger
+	userService          user.UserService
+	enforcer             casbin.Enforcer
+	enforcerUtil         rbac.EnforcerUtil
+	ciPipelineRepository pipelineConfig.CiPipelineRepository
+	pipelineRepository   pipelineConfig.PipelineRepository
+	pluginTestService    pluginTest.PluginTestService
+}
+
+func NewPluginTestRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcer casbin.Enforcer, enforcerUtil rbac.EnforcerUtil,
+	ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	pluginTestService pluginTest.PluginTestService) *PluginTestRestHandlerImpl {
+	return &PluginTestRestHandlerImpl{
+		logger:               logger,
+		userService:          userService,
+		enforcer:             enforcer,
+		enforcerUtil:         enforcerUtil,
+		ciPipelineRepository: ciPipelineRepository,
+		pipelineRepository:   pipelineRepository,
+		pluginTestService:    pluginTestService,
+	}
+}
+
+func (handler *PluginTestRestHandlerImpl) TestPlugin(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request pluginTest.PluginTestRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, TestPlugin", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	resp, err := handler.pluginTestService.TestPlugin(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, TestPlugin", "err", err, "pluginId", request.PluginId)
+		common.WriteJsonResp(w, err, nil, getServiceErrStatus(err))
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *PluginTestRestHandlerImpl) TestStage(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request pluginTest.StageTestRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, TestStage", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	if ok := handler.checkStageRbac(w, r, request.PipelineId, string(request.StageType), casbin.ActionTrigger); !ok {
+		return
+	}
+	resp, err := handler.pluginTestService.TestStage(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, TestStage", "err", err, "pipelineId", request.PipelineId, "stageType", request.StageType)
+		common.WriteJsonResp(w, err, nil, getServiceErrStatus(err))
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *PluginTestRestHandlerImpl) GetRun(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	resp, err := handler.pluginTestService.GetRun(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetRun", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, getServiceErrStatus(err))
+		return
+	}
+	if resp.RunType == pluginTest.RUN_TYPE_STAGE {
+		if ok := handler.checkStageRbac(w, r, resp.PipelineId, resp.StageType, casbin.ActionGet); !ok {
+			return
+		}
+	} else if ok := handler.enforcer.Enforce(r.Header.Get("token"), casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+// SaveResult receives the step results posted by the runner of the run, the runner authenticates with the result
+// token of the run in place of a user token
+func (handler *PluginTestRestHandlerImpl) SaveResult(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	splitToken := strings.Split(r.Header.Get("Authorization"), "Bearer")
+	if len(splitToken) != 2 {
+		common.WriteJsonResp(w, errors.New("missing bearer token"), "Unauthorized req", http.StatusUnauthorized)
+		return
+	}
+	var result pluginTest.PluginTestResultRequest
+	err = json.NewDecoder(r.Body).Decode(&result)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveResult", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = handler.pluginTestService.SaveResult(id, strings.TrimSpace(splitToken[1]), &result)
+	if err != nil {
+		handler.logger.Errorw("service err, SaveResult", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, getServiceErrStatus(err))
+		return
+	}
+	common.WriteJsonResp(w, nil, nil, http.StatusOK)
+}
+
+// getServiceErrStatus returns the status of the api errors of the service, such as the 401 of an invalid result token,
+// internal server error for the other errors
+func getServiceErrStatus(err error) int {
+	if apiErr, ok := err.(*util.ApiError); ok && apiErr.HttpStatusCode != 0 {
+		return apiErr.HttpStatusCode
+	}
+	return http.StatusInternalServerError
+}
+
+// checkStageRbac enforces the app rbac of the pipeline of the stage, and the environment rbac for cd stages, writing
+// the error response if it fails
+func (handler *PluginTestRestHandlerImpl) checkStageRbac(w http.ResponseWriter, r *http.Request, pipelineId int, stageType string, action string) bool {
+	token := r.Header.Get("token")
+	switch pipelineStageRepository.PipelineStageType(stageType) {
+	case pipelineStageRepository.PIPELINE_STAGE_TYPE_PRE_CI, pipelineStageRepository.PIPELINE_STAGE_TYPE_POST_CI:
+		ciPipeline, err := handler.ciPipelineRepository.FindById(pipelineId)
+		if err != nil {
+			handler.logger.Errorw("error in fetching ci pipeline", "err", err, "ciPipelineId", pipelineId)
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return false
+		}
+		resourceName := handler.enforcerUtil.GetAppRBACNameByAppId(ciPipeline.AppId)
+		if ok := handler.enforcerUtil.CheckAppRbacForAppOrJob(token, resourceName, action); !ok {
+			common.WriteJsonResp(w, errors.New("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return false
+		}
+	case pipelineStageRepository.PIPELINE_STAGE_TYPE_PRE_CD, pipelineStageRepository.PIPELINE_STAGE_TYPE_POST_CD:
+		cdPipeline, err := handler.pipelineRepository.FindById(pipelineId)
+		if err != nil {
+			handler.logger.Errorw("error in fetching cd pipeline", "err", err, "cdPipelineId", pipelineId)
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return false
+		}
+		resourceName := handler.enforcerUtil.GetAppRBACNameByAppId(cdPipeline.AppId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, action, resourceName); !ok {
+			common.WriteJsonResp(w, errors.New("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return false
+		}
+		envObject := handler.enforcerUtil.GetEnvRBACNameByCdPipelineIdAndEnvId(pipelineId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, action, envObject); !ok {
+			common.WriteJsonResp(w, errors.New("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return false
+		}
+	default:
+		common.WriteJsonResp(w, errors.New("invalid stage type "+stageType), nil, http.StatusBadRequest)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package restHandler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/provenance"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/go-pg/pg"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type ProvenanceRestHandler interface {
+	GetProvenance(w http.ResponseWriter, r *http.Request)
+	VerifyProvenance(w http.ResponseWriter, r *http.Request)
+	GetSigningKey(w http.ResponseWriter, r *http.Request)
+}
+
+type ProvenanceRestHandlerImpl struct {
+	logger               *zap.SugaredLogger
+	userService          user.UserService
+	enforcer             casbin.Enforcer
+	enforcerUtil         rbac.EnforcerUtil
+	ciPipelineRepository pipelineConfig.CiPipelineRepository
+	ciArtifactRepository repository.CiArtifactRepository
+	provenanceService    provenance.ProvenanceService
+}
+
+func NewProvenanceRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcer casbin.Enforcer, enforcerUtil rbac.EnforcerUtil,
+	ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	ciArtifactRepository repository.CiArtifactRepository,
+	provenanceService provenance.ProvenanceService) *ProvenanceRestHandlerImpl {
+	return &ProvenanceRestHandlerImpl{
+		logger:               logger,
+		userService:          userService,
+		enforcer:             enforcer,
+		enforcerUtil:         enforcerUtil,
+		ciPipelineRepository: ciPipelineRepository,
+		ciArtifactRepository: ciArtifactRepository,
+		provenanceService:    provenanceService,
+	}
+}
+
+func (handler *ProvenanceRestHandlerImpl) GetProvenance(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciArtifactId, err := strconv.Atoi(mux.Vars(r)["artifactId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := handler.checkArtifactRbac(w, r, ciArtifactId); !ok {
+		return
+	}
+	resp, err := handler.provenanceService.GetProvenance(ciArtifactId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetProvenance", "err", err, "ciArtifactId", ciArtifactId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *ProvenanceRestHandlerImpl) VerifyProvenance(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciArtifactId, err := strconv.Atoi(mux.Vars(r)["artifactId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := handler.checkArtifactRbac(w, r, ciArtifactId); !ok {
+		return
+	}
+	resp, err := handler.provenanceService.VerifyProvenance(ciArtifactId)
+	if err != nil {
+		handler.logger.Errorw("service err, VerifyProvenance", "err", err, "ciArtifactId", ciArtifactId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+// GetSigningKey returns the public key provenance is signed with, for verifying provenance outside of devtron
+func (handler *ProvenanceRestHandlerImpl) GetSigningKey(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	resp, err := handler.provenanceService.GetSigningKey()
+	if err != nil {
+		handler.logger.Errorw("service err, GetSigningKey", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+// checkArtifactRbac enforces the app view rbac of the ci pipeline which built the artifact, writing the error
+// response if it fails
+func (handler *ProvenanceRestHandlerImpl) checkArtifactRbac(w http.ResponseWriter, r *http.Request, ciArtifactId int) bool {
+	artifact, err := handler.ciArtifactRepository.Get(ciArtifactId)
+	if err == pg.ErrNoRows {
+		err = &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "ci artifact not found", UserMessage: "ci artifact not found"}
+		common.WriteJsonResp(w, err, nil, http.StatusNotFound)
+		return false
+	} else if err != nil {
+		handler.logger.Errorw("error in fetching ci artifact", "err", err, "ciArtifactId", ciArtifactId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return false
+	}
+	token := r.Header.Get("token")
+	if artifact.PipelineId == 0 {
+		// artifacts of external ci are visible to the global viewers only
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+			common.WriteJsonResp(w, errors.New("unauthorized user"), nil, http.StatusForbidden)
+			return false
+		}
+		return true
+	}
+	ciPipeline, err := handler.ciPipelineRepository.FindById(artifact.PipelineId)
+	if err != nil {
+		handler.logger.Errorw("error in fetching ci pipeline", "err", err, "ciPipelineId", artifact.PipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return false
+	}
+	resourceName := handler.enforcerUtil.GetAppRBACNameByAppId(ciPipeline.AppId)
+	if ok := handler.enforcerUtil.CheckAppRbacForAppOrJob(token, resourceName, casbin.ActionGet); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,243 @@
+package restHandler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/sbom"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/go-pg/pg"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type SbomRestHandler interface {
+	IngestSbom(w http.ResponseWriter, r *http.Request)
+	GetSbom(w http.ResponseWriter, r *http.Request)
+	DownloadSbom(w http.ResponseWriter, r *http.Request)
+	SearchPackages(w http.ResponseWriter, r *http.Request)
+}
+
+type SbomRestHandlerImpl struct {
+	logger               *zap.SugaredLogger
+	userService          user.UserService
+	enforcer             casbin.Enforcer
+	enforcerUtil         rbac.EnforcerUtil
+	ciArtifactRepository repository.CiArtifactRepository
+	sbomService          sbom.SbomService
+}
+
+func NewSbomRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcer casbin.Enforcer, enforcerUtil rbac.EnforcerUtil,
+	ciArtifactRepository repository.CiArtifactRepository,
+	sbomService sbom.SbomService) *SbomRestHandlerImpl {
+	return &SbomRestHandlerImpl{
+		logger:               logger,
+		userService:          userService,
+		enforcer:             enforcer,
+		enforcerUtil:         enforcerUtil,
+		ciArtifactRepository: ciArtifactRepository,
+		sbomService:          sbomService,
+	}
+}
+
+// IngestSbom takes the raw SBOM document as body, the artifact is given either by ciArtifactId or by ciPipelineId,
+// image and imageDigest query params
+func (handler *SbomRestHandlerImpl) IngestSbom(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	v := r.URL.Query()
+	request := &sbom.IngestSbomRequest{
+		Image:       v.Get("image"),
+		ImageDigest: v.Get("imageDigest"),
+		UserId:      userId,
+	}
+	if ciArtifactIdParam := v.Get("ciArtifactId"); len(ciArtifactIdParam) > 0 {
+		request.CiArtifactId, err = strconv.Atoi(ciArtifactIdParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	if ciPipelineIdParam := v.Get("ciPipelineId"); len(ciPipelineIdParam) > 0 {
+		request.CiPipelineId, err = strconv.Atoi(ciPipelineIdParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	ciPipelineId := request.CiPipelineId
+	if request.CiArtifactId > 0 {
+		ciPipelineId, err = handler.getCiPipelineIdOfArtifact(request.CiArtifactId)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionUpdate); !ok {
+		return
+	}
+	maxDocumentSize := handler.sbomService.GetMaxDocumentSize()
+	request.Document, err = io.ReadAll(io.LimitReader(r.Body, maxDocumentSize+1))
+	if err != nil {
+		handler.logger.Errorw("request err, IngestSbom", "err", err, "ciArtifactId", request.CiArtifactId, "image", request.Image)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("request payload, IngestSbom", "ciArtifactId", request.CiArtifactId, "ciPipelineId", request.CiPipelineId,
+		"image", request.Image, "size", len(request.Document))
+	resp, err := handler.sbomService.IngestSbom(request)
+	if err != nil {
+		handler.logger.Errorw("service err, IngestSbom", "err", err, "ciArtifactId", request.CiArtifactId, "image", request.Image)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *SbomRestHandlerImpl) GetSbom(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciArtifactId, err := strconv.Atoi(mux.Vars(r)["artifactId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	withPackages := false
+	if packagesParam := r.URL.Query().Get("packages"); len(packagesParam) > 0 {
+		withPackages, err = strconv.ParseBool(packagesParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	if ok := handler.checkArtifactRbac(w, r, ciArtifactId); !ok {
+		return
+	}
+	resp, err := handler.sbomService.GetSbomByCiArtifactId(ciArtifactId, withPackages)
+	if err != nil {
+		handler.logger.Errorw("service err, GetSbom", "err", err, "ciArtifactId", ciArtifactId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *SbomRestHandlerImpl) DownloadSbom(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciArtifactId, err := strconv.Atoi(mux.Vars(r)["artifactId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := handler.checkArtifactRbac(w, r, ciArtifactId); !ok {
+		return
+	}
+	content, sbomDto, err := handler.sbomService.DownloadSbom(ciArtifactId)
+	if err != nil {
+		handler.logger.Errorw("service err, DownloadSbom", "err", err, "ciArtifactId", ciArtifactId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=sbom-%d-%s.json", ciArtifactId, sbomDto.Format))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	_, err = w.Write(content)
+	if err != nil {
+		handler.logger.Errorw("service err, DownloadSbom", "err", err, "ciArtifactId", ciArtifactId)
+	}
+}
+
+// SearchPackages answers queries like "which deployed artifacts contain log4j-core < 2.17", results of apps the user
+// cannot view are dropped
+func (handler *SbomRestHandlerImpl) SearchPackages(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	v := r.URL.Query()
+	request := &sbom.PackageSearchRequest{
+		Name:    v.Get("name"),
+		Version: v.Get("version"),
+	}
+	if len(request.Name) == 0 {
+		common.WriteJsonResp(w, errors.New("package name is required"), nil, http.StatusBadRequest)
+		return
+	}
+	if deployedOnlyParam := v.Get("deployedOnly"); len(deployedOnlyParam) > 0 {
+		request.DeployedOnly, err = strconv.ParseBool(deployedOnlyParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	results, err := handler.sbomService.SearchPackages(request)
+	if err != nil {
+		handler.logger.Errorw("service err, SearchPackages", "err", err, "request", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	// RBAC enforcer applying
+	var ciPipelineIds []int
+	for _, result := range results {
+		ciPipelineIds = append(ciPipelineIds, result.CiPipelineId)
+	}
+	authorizedResults := make([]*sbom.PackageSearchResultDto, 0, len(results))
+	if len(ciPipelineIds) > 0 {
+		token := r.Header.Get("token")
+		objects := handler.enforcerUtil.GetAppObjectByCiPipelineIds(ciPipelineIds)
+		var rbacObjects []string
+		for _, object := range objects {
+			rbacObjects = append(rbacObjects, object)
+		}
+		enforced := handler.enforcer.EnforceInBatch(token, casbin.ResourceApplications, casbin.ActionGet, rbacObjects)
+		for _, result := range results {
+			if object, ok := objects[result.CiPipelineId]; ok && enforced[object] {
+				authorizedResults = append(authorizedResults, result)
+			}
+		}
+	}
+	//RBAC enforcer Ends
+	common.WriteJsonResp(w, nil, authorizedResults, http.StatusOK)
+}
+
+func (handler *SbomRestHandlerImpl) getCiPipelineIdOfArtifact(ciArtifactId int) (int, error) {
+	artifact, err := handler.ciArtifactRepository.Get(ciArtifactId)
+	if err == pg.ErrNoRows {
+		return 0, &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: "ci artifact not found", UserMessage: "ci artifact not found"}
+	} else if err != nil {
+		handler.logger.Errorw("error in fetching ci artifact", "err", err, "ciArtifactId", ciArtifactId)
+		return 0, err
+	}
+	return artifact.PipelineId, nil
+}
+
+// checkArtifactRbac enforces the app view rbac of the ci pipeline which built the artifact, writing the error
+// response if it fails
+func (handler *SbomRestHandlerImpl) checkArtifactRbac(w http.ResponseWriter, r *http.Request, ciArtifactId int) bool {
+	ciPipelineId, err := handler.getCiPipelineIdOfArtifact(ciArtifactId)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return false
+	}
+	return checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionGet)
+}
@@ -0,0 +1,273 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/testAnalytics"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type TestAnalyticsRestHandler interface {
+	GetFlakyTests(w http.ResponseWriter, r *http.Request)
+	GetTopFailingTests(w http.ResponseWriter, r *http.Request)
+	GetTestTrend(w http.ResponseWriter, r *http.Request)
+	GetBuildTrend(w http.ResponseWriter, r *http.Request)
+
+	QuarantineTest(w http.ResponseWriter, r *http.Request)
+	RemoveQuarantine(w http.ResponseWriter, r *http.Request)
+	GetQuarantinedTests(w http.ResponseWriter, r *http.Request)
+}
+
+type TestAnalyticsRestHandlerImpl struct {
+	logger               *zap.SugaredLogger
+	userService          user.UserService
+	enforcerUtil         rbac.EnforcerUtil
+	validator            *validator.Validate
+	testAnalyticsService testAnalytics.TestAnalyticsService
+}
+
+func NewTestAnalyticsRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcerUtil rbac.EnforcerUtil, validator *validator.Validate,
+	testAnalyticsService testAnalytics.TestAnalyticsService) *TestAnalyticsRestHandlerImpl {
+	return &TestAnalyticsRestHandlerImpl{
+		logger:               logger,
+		userService:          userService,
+		enforcerUtil:         enforcerUtil,
+		validator:            validator,
+		testAnalyticsService: testAnalyticsService,
+	}
+}
+
+// GetFlakyTests returns the tests of the pipeline which both passed and failed on the same commits in the last days,
+// days defaults to the configured window
+func (handler *TestAnalyticsRestHandlerImpl) GetFlakyTests(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciPipelineId, err := strconv.Atoi(mux.Vars(r)["ciPipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	days, err := getIntQueryParam(r, "days")
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionGet); !ok {
+		return
+	}
+	resp, err := handler.testAnalyticsService.GetFlakyTests(ciPipelineId, days)
+	if err != nil {
+		handler.logger.Errorw("service err, GetFlakyTests", "err", err, "ciPipelineId", ciPipelineId, "days", days)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *TestAnalyticsRestHandlerImpl) GetTopFailingTests(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciPipelineId, err := strconv.Atoi(mux.Vars(r)["ciPipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	days, err := getIntQueryParam(r, "days")
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	limit, err := getIntQueryParam(r, "limit")
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionGet); !ok {
+		return
+	}
+	resp, err := handler.testAnalyticsService.GetTopFailingTests(ciPipelineId, days, limit)
+	if err != nil {
+		handler.logger.Errorw("service err, GetTopFailingTests", "err", err, "ciPipelineId", ciPipelineId, "days", days, "limit", limit)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *TestAnalyticsRestHandlerImpl) GetTestTrend(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciPipelineId, err := strconv.Atoi(mux.Vars(r)["ciPipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	testKey := r.URL.Query().Get("testKey")
+	if len(testKey) == 0 {
+		common.WriteJsonResp(w, errors.New("testKey is required"), nil, http.StatusBadRequest)
+		return
+	}
+	days, err := getIntQueryParam(r, "days")
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionGet); !ok {
+		return
+	}
+	resp, err := handler.testAnalyticsService.GetTestTrend(ciPipelineId, testKey, days)
+	if err != nil {
+		handler.logger.Errorw("service err, GetTestTrend", "err", err, "ciPipelineId", ciPipelineId, "testKey", testKey, "days", days)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *TestAnalyticsRestHandlerImpl) GetBuildTrend(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciPipelineId, err := strconv.Atoi(mux.Vars(r)["ciPipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	days, err := getIntQueryParam(r, "days")
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionGet); !ok {
+		return
+	}
+	resp, err := handler.testAnalyticsService.GetBuildTrend(ciPipelineId, days)
+	if err != nil {
+		handler.logger.Errorw("service err, GetBuildTrend", "err", err, "ciPipelineId", ciPipelineId, "days", days)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *TestAnalyticsRestHandlerImpl) QuarantineTest(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciPipelineId, err := strconv.Atoi(mux.Vars(r)["ciPipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request testAnalytics.TestQuarantineDto
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, QuarantineTest", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.CiPipelineId = ciPipelineId
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, QuarantineTest", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionUpdate); !ok {
+		return
+	}
+	handler.logger.Infow("request payload, QuarantineTest", "payload", request)
+	resp, err := handler.testAnalyticsService.QuarantineTest(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, QuarantineTest", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *TestAnalyticsRestHandlerImpl) RemoveQuarantine(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	ciPipelineId, err := strconv.Atoi(vars["ciPipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionUpdate); !ok {
+		return
+	}
+	err = handler.testAnalyticsService.RemoveQuarantine(ciPipelineId, id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, RemoveQuarantine", "err", err, "ciPipelineId", ciPipelineId, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, "Quarantine removed successfully.", http.StatusOK)
+}
+
+func (handler *TestAnalyticsRestHandlerImpl) GetQuarantinedTests(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	ciPipelineId, err := strconv.Atoi(mux.Vars(r)["ciPipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := checkCiPipelineRbac(w, r, handler.enforcerUtil, ciPipelineId, casbin.ActionGet); !ok {
+		return
+	}
+	resp, err := handler.testAnalyticsService.GetQuarantinedTests(ciPipelineId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetQuarantinedTests", "err", err, "ciPipelineId", ciPipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+// getIntQueryParam returns the int value of the query param, 0 if it is not set
+func getIntQueryParam(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if len(value) == 0 {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,194 @@
+package restHandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/workflowLog"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type WorkflowLogRestHandler interface {
+	SearchLogs(w http.ResponseWriter, r *http.Request)
+	GetSteps(w http.ResponseWriter, r *http.Request)
+	DownloadStepLogs(w http.ResponseWriter, r *http.Request)
+}
+
+type WorkflowLogRestHandlerImpl struct {
+	logger               *zap.SugaredLogger
+	userService          user.UserService
+	enforcerUtil         rbac.EnforcerUtil
+	ciPipelineRepository pipelineConfig.CiPipelineRepository
+	pipelineRepository   pipelineConfig.PipelineRepository
+	workflowLogService   workflowLog.WorkflowLogService
+}
+
+func NewWorkflowLogRestHandlerImpl(logger *zap.SugaredLogger, userService user.UserService,
+	enforcerUtil rbac.EnforcerUtil,
+	ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	workflowLogService workflowLog.WorkflowLogService) *WorkflowLogRestHandlerImpl {
+	return &WorkflowLogRestHandlerImpl{
+		logger:               logger,
+		userService:          userService,
+		enforcerUtil:         enforcerUtil,
+		ciPipelineRepository: ciPipelineRepository,
+		pipelineRepository:   pipelineRepository,
+		workflowLogService:   workflowLogService,
+	}
+}
+
+// SearchLogs searches the text given by the query param in the logs of the latest runs of the pipeline, runs
+// defaults to the configured number of runs
+func (handler *WorkflowLogRestHandlerImpl) SearchLogs(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	workflowType, pipelineId, ok := handler.getPipeline(w, r)
+	if !ok {
+		return
+	}
+	v := r.URL.Query()
+	query := v.Get("query")
+	runs := 0
+	if runsParam := v.Get("runs"); len(runsParam) > 0 {
+		runs, err = strconv.Atoi(runsParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	if ok := handler.checkPipelineRbac(w, r, workflowType, pipelineId); !ok {
+		return
+	}
+	resp, err := handler.workflowLogService.SearchLogs(workflowType, pipelineId, query, runs)
+	if err != nil {
+		handler.logger.Errorw("service err, SearchLogs", "err", err, "workflowType", workflowType, "pipelineId", pipelineId, "query", query)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *WorkflowLogRestHandlerImpl) GetSteps(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	workflowType, pipelineId, ok := handler.getPipeline(w, r)
+	if !ok {
+		return
+	}
+	workflowId, err := strconv.Atoi(mux.Vars(r)["workflowId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := handler.checkPipelineRbac(w, r, workflowType, pipelineId); !ok {
+		return
+	}
+	resp, err := handler.workflowLogService.GetSteps(workflowType, pipelineId, workflowId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetSteps", "err", err, "workflowType", workflowType, "pipelineId", pipelineId, "workflowId", workflowId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+func (handler *WorkflowLogRestHandlerImpl) DownloadStepLogs(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	workflowType, pipelineId, ok := handler.getPipeline(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	workflowId, err := strconv.Atoi(vars["workflowId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	stepIndex, err := strconv.Atoi(vars["stepIndex"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := handler.checkPipelineRbac(w, r, workflowType, pipelineId); !ok {
+		return
+	}
+	content, err := handler.workflowLogService.DownloadStepLogs(workflowType, pipelineId, workflowId, stepIndex)
+	if err != nil {
+		handler.logger.Errorw("service err, DownloadStepLogs", "err", err, "workflowType", workflowType, "workflowId", workflowId, "stepIndex", stepIndex)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%d-step-%d.log", strings.ToLower(workflowType), workflowId, stepIndex))
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	_, err = w.Write(content)
+	if err != nil {
+		handler.logger.Errorw("service err, DownloadStepLogs", "err", err, "workflowId", workflowId, "stepIndex", stepIndex)
+	}
+}
+
+// getPipeline reads the workflow type, ci or cd, and the pipeline id from the path, writing the error response if
+// they are invalid
+func (handler *WorkflowLogRestHandlerImpl) getPipeline(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	vars := mux.Vars(r)
+	workflowType := strings.ToUpper(vars["workflowType"])
+	if workflowType != workflowLog.WORKFLOW_TYPE_CI && workflowType != workflowLog.WORKFLOW_TYPE_CD {
+		common.WriteJsonResp(w, fmt.Errorf("invalid workflow type %s, must be ci or cd", vars["workflowType"]), nil, http.StatusBadRequest)
+		return "", 0, false
+	}
+	pipelineId, err := strconv.Atoi(vars["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return "", 0, false
+	}
+	return workflowType, pipelineId, true
+}
+
+// checkPipelineRbac enforces the app view rbac of the ci or cd pipeline, writing the error response if it fails
+func (handler *WorkflowLogRestHandlerImpl) checkPipelineRbac(w http.ResponseWriter, r *http.Request, workflowType string, pipelineId int) bool {
+	var appId int
+	if workflowType == workflowLog.WORKFLOW_TYPE_CI {
+		ciPipeline, err := handler.ciPipelineRepository.FindById(pipelineId)
+		if err != nil {
+			handler.logger.Errorw("error in fetching ci pipeline", "err", err, "ciPipelineId", pipelineId)
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return false
+		}
+		appId = ciPipeline.AppId
+	} else {
+		cdPipeline, err := handler.pipelineRepository.FindById(pipelineId)
+		if err != nil {
+			handler.logger.Errorw("error in fetching cd pipeline", "err", err, "cdPipelineId", pipelineId)
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return false
+		}
+		appId = cdPipeline.AppId
+	}
+	token := r.Header.Get("token")
+	resourceName := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcerUtil.CheckAppRbacForAppOrJob(token, resourceName, casbin.ActionGet); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return false
+	}
+	return true
+}
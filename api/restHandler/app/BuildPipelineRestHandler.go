@@ -62,6 +62,8 @@ type DevtronAppBuildRestHandler interface {
 	GetCiPipelineByEnvironment(w http.ResponseWriter, r *http.Request)
 	GetCiPipelineByEnvironmentMin(w http.ResponseWriter, r *http.Request)
 	GetExternalCiByEnvironment(w http.ResponseWriter, r *http.Request)
+	GetCiRetryPolicy(w http.ResponseWriter, r *http.Request)
+	SaveCiRetryPolicy(w http.ResponseWriter, r *http.Request)
 }
 
 type DevtronAppBuildMaterialRestHandler interface {
@@ -600,6 +602,12 @@ func (handler PipelineConfigRestHandlerImpl) TriggerCiPipeline(w http.ResponseWr
 		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusForbidden)
 		return
 	}
+	// overriding the build queue priority of the pipeline needs the access required to change that priority
+	if len(ciTriggerRequest.PriorityClass) > 0 && !handler.enforcerUtil.CheckAppRbacForAppOrJob(token, appObject, casbin.ActionUpdate) {
+		handler.Logger.Errorw("unauthorized user for overriding build priority", "ciPipelineId", ciTriggerRequest.PipelineId, "priorityClass", ciTriggerRequest.PriorityClass)
+		common.WriteJsonResp(w, errors.New("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
 	//checking rbac for cd cdPipelines
 	cdPipelines, err := handler.pipelineRepository.FindByCiPipelineId(ciTriggerRequest.PipelineId)
 	if err != nil {
@@ -2014,3 +2022,82 @@ func (handler PipelineConfigRestHandlerImpl) checkAppSpecificAccess(token, actio
 	}
 	return true, nil
 }
+
+func (handler PipelineConfigRestHandlerImpl) GetCiRetryPolicy(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	pipelineId, err := strconv.Atoi(mux.Vars(r)["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	ciPipeline, err := handler.ciPipelineRepository.FindById(pipelineId)
+	if err != nil {
+		handler.Logger.Errorw("service err, GetCiRetryPolicy", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	resourceName := handler.enforcerUtil.GetAppRBACNameByAppId(ciPipeline.AppId)
+	if ok := handler.enforcerUtil.CheckAppRbacForAppOrJob(token, resourceName, casbin.ActionGet); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	policy, err := handler.ciRetryPolicyService.GetPolicy(pipelineId)
+	if err != nil {
+		handler.Logger.Errorw("service err, GetCiRetryPolicy", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, policy, http.StatusOK)
+}
+
+func (handler PipelineConfigRestHandlerImpl) SaveCiRetryPolicy(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	pipelineId, err := strconv.Atoi(mux.Vars(r)["pipelineId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var policy bean1.CiRetryPolicy
+	err = json.NewDecoder(r.Body).Decode(&policy)
+	if err != nil {
+		handler.Logger.Errorw("request err, SaveCiRetryPolicy", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	policy.CiPipelineId = pipelineId
+	policy.UserId = userId
+	err = handler.validator.Struct(policy)
+	if err != nil {
+		handler.Logger.Errorw("validation err, SaveCiRetryPolicy", "err", err, "policy", policy)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	ciPipeline, err := handler.ciPipelineRepository.FindById(pipelineId)
+	if err != nil {
+		handler.Logger.Errorw("service err, SaveCiRetryPolicy", "err", err, "pipelineId", pipelineId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	resourceName := handler.enforcerUtil.GetAppRBACNameByAppId(ciPipeline.AppId)
+	if ok := handler.enforcerUtil.CheckAppRbacForAppOrJob(token, resourceName, casbin.ActionUpdate); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	resp, err := handler.ciRetryPolicyService.SavePolicy(&policy)
+	if err != nil {
+		handler.Logger.Errorw("service err, SaveCiRetryPolicy", "err", err, "policy", policy)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
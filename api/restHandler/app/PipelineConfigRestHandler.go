@@ -132,6 +132,7 @@ type PipelineConfigRestHandlerImpl struct {
 	deploymentTemplateService    generateManifest.DeploymentTemplateService
 	pipelineRestHandlerEnvConfig *PipelineRestHandlerEnvConfig
 	ciArtifactRepository         repository.CiArtifactRepository
+	ciRetryPolicyService         pipeline.CiRetryPolicyService
 }
 
 func NewPipelineRestHandlerImpl(pipelineBuilder pipeline.PipelineBuilder, Logger *zap.SugaredLogger,
@@ -156,7 +157,8 @@ func NewPipelineRestHandlerImpl(pipelineBuilder pipeline.PipelineBuilder, Logger
 	scanResultRepository security.ImageScanResultRepository, gitProviderRepo repository.GitProviderRepository,
 	argoUserService argo.ArgoUserService, ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository,
 	imageTaggingService pipeline.ImageTaggingService,
-	ciArtifactRepository repository.CiArtifactRepository) *PipelineConfigRestHandlerImpl {
+	ciArtifactRepository repository.CiArtifactRepository,
+	ciRetryPolicyService pipeline.CiRetryPolicyService) *PipelineConfigRestHandlerImpl {
 	envConfig := &PipelineRestHandlerEnvConfig{}
 	err := env.Parse(envConfig)
 	if err != nil {
@@ -194,6 +196,7 @@ func NewPipelineRestHandlerImpl(pipelineBuilder pipeline.PipelineBuilder, Logger
 		deploymentTemplateService:    deploymentTemplateService,
 		pipelineRestHandlerEnvConfig: envConfig,
 		ciArtifactRepository:         ciArtifactRepository,
+		ciRetryPolicyService:         ciRetryPolicyService,
 	}
 }
 
@@ -0,0 +1,26 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type ArtifactPromotionPolicyRouter interface {
+	InitArtifactPromotionPolicyRouter(router *mux.Router)
+}
+
+type ArtifactPromotionPolicyRouterImpl struct {
+	artifactPromotionPolicyRestHandler restHandler.ArtifactPromotionPolicyRestHandler
+}
+
+func NewArtifactPromotionPolicyRouterImpl(artifactPromotionPolicyRestHandler restHandler.ArtifactPromotionPolicyRestHandler) *ArtifactPromotionPolicyRouterImpl {
+	return &ArtifactPromotionPolicyRouterImpl{artifactPromotionPolicyRestHandler: artifactPromotionPolicyRestHandler}
+}
+
+func (router ArtifactPromotionPolicyRouterImpl) InitArtifactPromotionPolicyRouter(artifactPromotionPolicyRouter *mux.Router) {
+	artifactPromotionPolicyRouter.Path("").HandlerFunc(router.artifactPromotionPolicyRestHandler.CreatePolicy).Methods("POST")
+	artifactPromotionPolicyRouter.Path("").HandlerFunc(router.artifactPromotionPolicyRestHandler.UpdatePolicy).Methods("PUT")
+	artifactPromotionPolicyRouter.Path("/list").HandlerFunc(router.artifactPromotionPolicyRestHandler.GetAllPolicies).Methods("GET")
+	artifactPromotionPolicyRouter.Path("/{id}").HandlerFunc(router.artifactPromotionPolicyRestHandler.GetPolicyById).Methods("GET")
+	artifactPromotionPolicyRouter.Path("/{id}").HandlerFunc(router.artifactPromotionPolicyRestHandler.DeletePolicy).Methods("DELETE")
+}
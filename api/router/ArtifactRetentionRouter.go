@@ -0,0 +1,28 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type ArtifactRetentionRouter interface {
+	InitArtifactRetentionRouter(router *mux.Router)
+}
+
+type ArtifactRetentionRouterImpl struct {
+	artifactRetentionRestHandler restHandler.ArtifactRetentionRestHandler
+}
+
+func NewArtifactRetentionRouterImpl(artifactRetentionRestHandler restHandler.ArtifactRetentionRestHandler) *ArtifactRetentionRouterImpl {
+	return &ArtifactRetentionRouterImpl{artifactRetentionRestHandler: artifactRetentionRestHandler}
+}
+
+func (router ArtifactRetentionRouterImpl) InitArtifactRetentionRouter(artifactRetentionRouter *mux.Router) {
+	artifactRetentionRouter.Path("/policy").HandlerFunc(router.artifactRetentionRestHandler.CreatePolicy).Methods("POST")
+	artifactRetentionRouter.Path("/policy").HandlerFunc(router.artifactRetentionRestHandler.UpdatePolicy).Methods("PUT")
+	artifactRetentionRouter.Path("/policy/list").HandlerFunc(router.artifactRetentionRestHandler.GetAllPolicies).Methods("GET")
+	artifactRetentionRouter.Path("/policy/{id}").HandlerFunc(router.artifactRetentionRestHandler.DeletePolicy).Methods("DELETE")
+	artifactRetentionRouter.Path("/policy/{id}/dry-run").HandlerFunc(router.artifactRetentionRestHandler.DryRun).Methods("GET")
+	artifactRetentionRouter.Path("/policy/{id}/gc-run/list").HandlerFunc(router.artifactRetentionRestHandler.GetGcRuns).Methods("GET")
+	artifactRetentionRouter.Path("/gc-run/{id}").HandlerFunc(router.artifactRetentionRestHandler.GetGcRun).Methods("GET")
+}
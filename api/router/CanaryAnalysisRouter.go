@@ -0,0 +1,25 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type CanaryAnalysisRouter interface {
+	InitCanaryAnalysisRouter(router *mux.Router)
+}
+
+type CanaryAnalysisRouterImpl struct {
+	canaryAnalysisRestHandler restHandler.CanaryAnalysisRestHandler
+}
+
+func NewCanaryAnalysisRouterImpl(canaryAnalysisRestHandler restHandler.CanaryAnalysisRestHandler) *CanaryAnalysisRouterImpl {
+	return &CanaryAnalysisRouterImpl{canaryAnalysisRestHandler: canaryAnalysisRestHandler}
+}
+
+func (router CanaryAnalysisRouterImpl) InitCanaryAnalysisRouter(canaryAnalysisRouter *mux.Router) {
+	canaryAnalysisRouter.Path("/config").HandlerFunc(router.canaryAnalysisRestHandler.SaveConfig).Methods("POST")
+	canaryAnalysisRouter.Path("/config/{appId}/{pipelineId}").HandlerFunc(router.canaryAnalysisRestHandler.GetConfig).Methods("GET")
+	canaryAnalysisRouter.Path("/config/{appId}/{pipelineId}").HandlerFunc(router.canaryAnalysisRestHandler.DeleteConfig).Methods("DELETE")
+	canaryAnalysisRouter.Path("/result/{appId}/{wfrId}").HandlerFunc(router.canaryAnalysisRestHandler.GetAnalysisResults).Methods("GET")
+}
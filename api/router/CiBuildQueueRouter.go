@@ -0,0 +1,28 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type CiBuildQueueRouter interface {
+	InitCiBuildQueueRouter(router *mux.Router)
+}
+
+type CiBuildQueueRouterImpl struct {
+	ciBuildQueueRestHandler restHandler.CiBuildQueueRestHandler
+}
+
+func NewCiBuildQueueRouterImpl(ciBuildQueueRestHandler restHandler.CiBuildQueueRestHandler) *CiBuildQueueRouterImpl {
+	return &CiBuildQueueRouterImpl{ciBuildQueueRestHandler: ciBuildQueueRestHandler}
+}
+
+func (router CiBuildQueueRouterImpl) InitCiBuildQueueRouter(ciBuildQueueRouter *mux.Router) {
+	ciBuildQueueRouter.Path("/quota").HandlerFunc(router.ciBuildQueueRestHandler.CreateQuota).Methods("POST")
+	ciBuildQueueRouter.Path("/quota").HandlerFunc(router.ciBuildQueueRestHandler.UpdateQuota).Methods("PUT")
+	ciBuildQueueRouter.Path("/quota/list").HandlerFunc(router.ciBuildQueueRestHandler.GetAllQuotas).Methods("GET")
+	ciBuildQueueRouter.Path("/quota/{id}").HandlerFunc(router.ciBuildQueueRestHandler.DeleteQuota).Methods("DELETE")
+	ciBuildQueueRouter.Path("/queue").HandlerFunc(router.ciBuildQueueRestHandler.GetQueuedBuilds).Methods("GET")
+	ciBuildQueueRouter.Path("/pipeline/{ciPipelineId}/priority").HandlerFunc(router.ciBuildQueueRestHandler.GetPipelinePriority).Methods("GET")
+	ciBuildQueueRouter.Path("/pipeline/{ciPipelineId}/priority").HandlerFunc(router.ciBuildQueueRestHandler.SavePipelinePriority).Methods("PUT")
+}
@@ -38,6 +38,8 @@ func NewCoreAppRouterImpl(restHandler restHandler.CoreAppRestHandler) *CoreAppRo
 func (router CoreAppRouterImpl) initCoreAppRouter(configRouter *mux.Router) {
 	configRouter.Path("/v1beta1/application").HandlerFunc(router.restHandler.CreateApp).Methods("POST")
 	configRouter.Path("/v1beta1/application/{appId}").HandlerFunc(router.restHandler.GetAppAllDetail).Methods("GET")
+	configRouter.Path("/v1beta1/application/apply").HandlerFunc(router.restHandler.ApplyApp).Methods("POST")
+	configRouter.Path("/v1beta1/application/{appId}/export").HandlerFunc(router.restHandler.ExportApp).Methods("GET")
 	configRouter.Path("/v1beta1/application/workflow").HandlerFunc(router.restHandler.CreateAppWorkflow).Methods("POST")
 	configRouter.Path("/v1beta1/application/workflow/{appId}").HandlerFunc(router.restHandler.GetAppWorkflow).Methods("GET")
 	configRouter.Path("/v1beta1/application/workflow/{appId}/sample").HandlerFunc(router.restHandler.GetAppWorkflowAndOverridesSample).Methods("GET")
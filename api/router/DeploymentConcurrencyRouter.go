@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type DeploymentConcurrencyRouter interface {
+	InitDeploymentConcurrencyRouter(router *mux.Router)
+}
+
+type DeploymentConcurrencyRouterImpl struct {
+	deploymentConcurrencyRestHandler restHandler.DeploymentConcurrencyRestHandler
+}
+
+func NewDeploymentConcurrencyRouterImpl(deploymentConcurrencyRestHandler restHandler.DeploymentConcurrencyRestHandler) *DeploymentConcurrencyRouterImpl {
+	return &DeploymentConcurrencyRouterImpl{deploymentConcurrencyRestHandler: deploymentConcurrencyRestHandler}
+}
+
+func (router DeploymentConcurrencyRouterImpl) InitDeploymentConcurrencyRouter(deploymentConcurrencyRouter *mux.Router) {
+	deploymentConcurrencyRouter.Path("/limit").HandlerFunc(router.deploymentConcurrencyRestHandler.CreateLimit).Methods("POST")
+	deploymentConcurrencyRouter.Path("/limit").HandlerFunc(router.deploymentConcurrencyRestHandler.UpdateLimit).Methods("PUT")
+	deploymentConcurrencyRouter.Path("/limit/list").HandlerFunc(router.deploymentConcurrencyRestHandler.GetAllLimits).Methods("GET")
+	deploymentConcurrencyRouter.Path("/limit/{id}").HandlerFunc(router.deploymentConcurrencyRestHandler.GetLimitById).Methods("GET")
+	deploymentConcurrencyRouter.Path("/limit/{id}").HandlerFunc(router.deploymentConcurrencyRestHandler.DeleteLimit).Methods("DELETE")
+	deploymentConcurrencyRouter.Path("/queue").HandlerFunc(router.deploymentConcurrencyRestHandler.GetQueuedDeployments).Methods("GET")
+}
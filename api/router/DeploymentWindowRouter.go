@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type DeploymentWindowRouter interface {
+	InitDeploymentWindowRouter(router *mux.Router)
+}
+
+type DeploymentWindowRouterImpl struct {
+	deploymentWindowRestHandler restHandler.DeploymentWindowRestHandler
+}
+
+func NewDeploymentWindowRouterImpl(deploymentWindowRestHandler restHandler.DeploymentWindowRestHandler) *DeploymentWindowRouterImpl {
+	return &DeploymentWindowRouterImpl{deploymentWindowRestHandler: deploymentWindowRestHandler}
+}
+
+func (router DeploymentWindowRouterImpl) InitDeploymentWindowRouter(deploymentWindowRouter *mux.Router) {
+	deploymentWindowRouter.Path("").HandlerFunc(router.deploymentWindowRestHandler.CreateWindow).Methods("POST")
+	deploymentWindowRouter.Path("").HandlerFunc(router.deploymentWindowRestHandler.UpdateWindow).Methods("PUT")
+	deploymentWindowRouter.Path("/list").HandlerFunc(router.deploymentWindowRestHandler.GetAllWindows).Methods("GET")
+	deploymentWindowRouter.Path("/state/{appId}/{envId}").HandlerFunc(router.deploymentWindowRestHandler.GetDeploymentWindowState).Methods("GET")
+	deploymentWindowRouter.Path("/{id}").HandlerFunc(router.deploymentWindowRestHandler.GetWindowById).Methods("GET")
+	deploymentWindowRouter.Path("/{id}").HandlerFunc(router.deploymentWindowRestHandler.DeleteWindow).Methods("DELETE")
+}
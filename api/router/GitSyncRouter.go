@@ -0,0 +1,29 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type GitSyncRouter interface {
+	InitGitSyncRouter(router *mux.Router)
+}
+
+type GitSyncRouterImpl struct {
+	gitSyncRestHandler restHandler.GitSyncRestHandler
+}
+
+func NewGitSyncRouterImpl(gitSyncRestHandler restHandler.GitSyncRestHandler) *GitSyncRouterImpl {
+	return &GitSyncRouterImpl{gitSyncRestHandler: gitSyncRestHandler}
+}
+
+func (router GitSyncRouterImpl) InitGitSyncRouter(gitSyncRouter *mux.Router) {
+	gitSyncRouter.Path("/config").HandlerFunc(router.gitSyncRestHandler.CreateConfig).Methods("POST")
+	gitSyncRouter.Path("/config").HandlerFunc(router.gitSyncRestHandler.UpdateConfig).Methods("PUT")
+	gitSyncRouter.Path("/config/list").HandlerFunc(router.gitSyncRestHandler.GetAllConfigs).Methods("GET")
+	gitSyncRouter.Path("/config/{id}").HandlerFunc(router.gitSyncRestHandler.GetConfig).Methods("GET")
+	gitSyncRouter.Path("/config/{id}").HandlerFunc(router.gitSyncRestHandler.DeleteConfig).Methods("DELETE")
+	gitSyncRouter.Path("/config/{id}/sync").HandlerFunc(router.gitSyncRestHandler.Sync).Methods("POST")
+	gitSyncRouter.Path("/config/{id}/result").HandlerFunc(router.gitSyncRestHandler.GetLatestResult).Methods("GET")
+	gitSyncRouter.Path("/config/{id}/results").HandlerFunc(router.gitSyncRestHandler.GetResults).Methods("GET")
+}
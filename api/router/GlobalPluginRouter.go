@@ -30,12 +30,32 @@ func (impl *GlobalPluginRouterImpl) initGlobalPluginRouter(globalPluginRouter *m
 	globalPluginRouter.Path("/detail/{pluginId}").
 		HandlerFunc(impl.globalPluginRestHandler.GetDetailedPluginInfoByPluginId).Methods("GET")
 
+	globalPluginRouter.Path("/export").
+		HandlerFunc(impl.globalPluginRestHandler.ExportPluginBundle).Methods("GET")
+	globalPluginRouter.Path("/export/oci").
+		HandlerFunc(impl.globalPluginRestHandler.PushPluginBundle).Methods("POST")
+	globalPluginRouter.Path("/import").
+		HandlerFunc(impl.globalPluginRestHandler.ImportPluginBundle).Methods("POST")
+	globalPluginRouter.Path("/import/oci").
+		HandlerFunc(impl.globalPluginRestHandler.ImportPluginBundleFromRegistry).Methods("POST")
+
 	globalPluginRouter.Path("/list/global-variable").
 		HandlerFunc(impl.globalPluginRestHandler.GetAllGlobalVariables).Methods("GET")
 
 	globalPluginRouter.Path("/list").
 		HandlerFunc(impl.globalPluginRestHandler.ListAllPlugins).Methods("GET")
 
+	globalPluginRouter.Path("/{pluginId}/version").
+		HandlerFunc(impl.globalPluginRestHandler.PublishPluginVersion).Methods("POST")
+	globalPluginRouter.Path("/{pluginId}/versions").
+		HandlerFunc(impl.globalPluginRestHandler.GetPluginVersions).Methods("GET")
+	globalPluginRouter.Path("/{pluginId}/deprecate").
+		HandlerFunc(impl.globalPluginRestHandler.DeprecatePluginVersion).Methods("PUT")
+	globalPluginRouter.Path("/{pluginId}/outdated").
+		HandlerFunc(impl.globalPluginRestHandler.GetOutdatedPluginUsages).Methods("GET")
+	globalPluginRouter.Path("/{pluginId}/upgrade").
+		HandlerFunc(impl.globalPluginRestHandler.UpgradePluginUsages).Methods("POST")
+
 	globalPluginRouter.Path("/{pluginId}").
 		HandlerFunc(impl.globalPluginRestHandler.GetPluginDetailById).Methods("GET")
 }
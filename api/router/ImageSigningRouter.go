@@ -0,0 +1,35 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type ImageSigningRouter interface {
+	InitImageSigningRouter(router *mux.Router)
+}
+
+type ImageSigningRouterImpl struct {
+	imageSigningRestHandler restHandler.ImageSigningRestHandler
+}
+
+func NewImageSigningRouterImpl(imageSigningRestHandler restHandler.ImageSigningRestHandler) *ImageSigningRouterImpl {
+	return &ImageSigningRouterImpl{imageSigningRestHandler: imageSigningRestHandler}
+}
+
+func (router ImageSigningRouterImpl) InitImageSigningRouter(imageSigningRouter *mux.Router) {
+	imageSigningRouter.Path("/key").HandlerFunc(router.imageSigningRestHandler.CreateKey).Methods("POST")
+	imageSigningRouter.Path("/key").HandlerFunc(router.imageSigningRestHandler.UpdateKey).Methods("PUT")
+	imageSigningRouter.Path("/key/list").HandlerFunc(router.imageSigningRestHandler.GetAllKeys).Methods("GET")
+	imageSigningRouter.Path("/key/{id}").HandlerFunc(router.imageSigningRestHandler.DeleteKey).Methods("DELETE")
+
+	imageSigningRouter.Path("/policy").HandlerFunc(router.imageSigningRestHandler.CreatePolicy).Methods("POST")
+	imageSigningRouter.Path("/policy").HandlerFunc(router.imageSigningRestHandler.UpdatePolicy).Methods("PUT")
+	imageSigningRouter.Path("/policy/list").HandlerFunc(router.imageSigningRestHandler.GetAllPolicies).Methods("GET")
+	imageSigningRouter.Path("/policy/{id}").HandlerFunc(router.imageSigningRestHandler.DeletePolicy).Methods("DELETE")
+
+	imageSigningRouter.Path("/ci-pipeline/{ciPipelineId}/signing").HandlerFunc(router.imageSigningRestHandler.GetCiPipelineSigningConfig).Methods("GET")
+	imageSigningRouter.Path("/ci-pipeline/{ciPipelineId}/signing").HandlerFunc(router.imageSigningRestHandler.SaveCiPipelineSigningConfig).Methods("PUT")
+
+	imageSigningRouter.Path("/artifact/{artifactId}/verification").HandlerFunc(router.imageSigningRestHandler.GetArtifactVerifications).Methods("GET")
+}
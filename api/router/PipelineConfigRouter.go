@@ -117,6 +117,8 @@ func (router PipelineConfigRouterImpl) initPipelineConfigRouter(configRouter *mu
 	configRouter.Path("/ci-pipeline/{pipelineId}/workflow/{workflowId}/logs/old").HandlerFunc(router.restHandler.GetHistoricBuildLogs).Methods("GET")
 	configRouter.Path("/ci-pipeline/{pipelineId}/workflow/{workflowId}/logs").HandlerFunc(router.restHandler.GetBuildLogs).Methods("GET")
 	configRouter.Path("/ci-pipeline/{pipelineId}/workflows").HandlerFunc(router.restHandler.GetBuildHistory).Methods("GET")
+	configRouter.Path("/ci-pipeline/{pipelineId}/retry-policy").HandlerFunc(router.restHandler.GetCiRetryPolicy).Methods("GET")
+	configRouter.Path("/ci-pipeline/{pipelineId}/retry-policy").HandlerFunc(router.restHandler.SaveCiRetryPolicy).Methods("POST")
 	configRouter.Path("/ci-pipeline/{pipelineId}/workflow/{workflowId}").HandlerFunc(router.restHandler.CancelWorkflow).Methods("DELETE")
 	configRouter.Path("/cd-pipeline/{pipelineId}/workflowRunner/{workflowRunnerId}").HandlerFunc(router.restHandler.CancelStage).Methods("DELETE")
 
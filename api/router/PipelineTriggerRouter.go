@@ -58,6 +58,7 @@ type PipelineTriggerRouterImpl struct {
 
 func (router PipelineTriggerRouterImpl) initPipelineTriggerRouter(pipelineTriggerRouter *mux.Router) {
 	pipelineTriggerRouter.Path("/cd-pipeline/trigger").HandlerFunc(router.restHandler.OverrideConfig).Methods("POST")
+	pipelineTriggerRouter.Path("/cd-pipeline/trigger/dry-run").HandlerFunc(router.restHandler.DryRunCdTrigger).Methods("POST")
 	pipelineTriggerRouter.Path("/update-release-status").HandlerFunc(router.restHandler.ReleaseStatusUpdate).Methods("POST")
 	pipelineTriggerRouter.Path("/rotate-pods").HandlerFunc(router.restHandler.RotatePods).Methods("POST")
 	pipelineTriggerRouter.Path("/stop-start-app").HandlerFunc(router.restHandler.StartStopApp).Methods("POST")
@@ -68,6 +69,11 @@ func (router PipelineTriggerRouterImpl) initPipelineTriggerRouter(pipelineTrigge
 		Queries("name", "{name}")
 
 	pipelineTriggerRouter.Path("/deployment-configuration/latest/saved/{appId}/{pipelineId}").HandlerFunc(router.restHandler.GetAllLatestDeploymentConfiguration).Methods("GET")
+	pipelineTriggerRouter.Path("/deployment-approval").HandlerFunc(router.restHandler.PerformDeploymentApprovalAction).Methods("POST")
+	pipelineTriggerRouter.Path("/deployment-approval/{appId}/{pipelineId}/{artifactId}").HandlerFunc(router.restHandler.GetDeploymentApprovalData).Methods("GET")
+	pipelineTriggerRouter.Path("/cd-pipeline/trigger/schedule").HandlerFunc(router.restHandler.ScheduleDeployment).Methods("POST")
+	pipelineTriggerRouter.Path("/cd-pipeline/trigger/schedule/{appId}/{pipelineId}").HandlerFunc(router.restHandler.GetScheduledDeployments).Methods("GET")
+	pipelineTriggerRouter.Path("/cd-pipeline/trigger/schedule/{appId}/{id}").HandlerFunc(router.restHandler.CancelScheduledDeployment).Methods("DELETE")
 }
 
 func fetchReleaseData(r *http.Request, receive <-chan int, send chan<- int) {
@@ -0,0 +1,30 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type PluginTestRouter interface {
+	InitPluginTestRouter(router *mux.Router)
+	// InitPluginTestWebhookRouter registers the webhook the runners of the test runs post their results to
+	InitPluginTestWebhookRouter(router *mux.Router)
+}
+
+type PluginTestRouterImpl struct {
+	pluginTestRestHandler restHandler.PluginTestRestHandler
+}
+
+func NewPluginTestRouterImpl(pluginTestRestHandler restHandler.PluginTestRestHandler) *PluginTestRouterImpl {
+	return &PluginTestRouterImpl{pluginTestRestHandler: pluginTestRestHandler}
+}
+
+func (router PluginTestRouterImpl) InitPluginTestRouter(pluginTestRouter *mux.Router) {
+	pluginTestRouter.Path("/plugin").HandlerFunc(router.pluginTestRestHandler.TestPlugin).Methods("POST")
+	pluginTestRouter.Path("/stage").HandlerFunc(router.pluginTestRestHandler.TestStage).Methods("POST")
+	pluginTestRouter.Path("/run/{id}").HandlerFunc(router.pluginTestRestHandler.GetRun).Methods("GET")
+}
+
+func (router PluginTestRouterImpl) InitPluginTestWebhookRouter(pluginTestWebhookRouter *mux.Router) {
+	pluginTestWebhookRouter.Path("/{id}/result").HandlerFunc(router.pluginTestRestHandler.SaveResult).Methods("POST")
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type ProvenanceRouter interface {
+	InitProvenanceRouter(router *mux.Router)
+}
+
+type ProvenanceRouterImpl struct {
+	provenanceRestHandler restHandler.ProvenanceRestHandler
+}
+
+func NewProvenanceRouterImpl(provenanceRestHandler restHandler.ProvenanceRestHandler) *ProvenanceRouterImpl {
+	return &ProvenanceRouterImpl{provenanceRestHandler: provenanceRestHandler}
+}
+
+func (router ProvenanceRouterImpl) InitProvenanceRouter(provenanceRouter *mux.Router) {
+	provenanceRouter.Path("/artifact/{artifactId}").HandlerFunc(router.provenanceRestHandler.GetProvenance).Methods("GET")
+	provenanceRouter.Path("/artifact/{artifactId}/verify").HandlerFunc(router.provenanceRestHandler.VerifyProvenance).Methods("GET")
+	provenanceRouter.Path("/signing-key").HandlerFunc(router.provenanceRestHandler.GetSigningKey).Methods("GET")
+}
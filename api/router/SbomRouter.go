@@ -0,0 +1,25 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type SbomRouter interface {
+	InitSbomRouter(router *mux.Router)
+}
+
+type SbomRouterImpl struct {
+	sbomRestHandler restHandler.SbomRestHandler
+}
+
+func NewSbomRouterImpl(sbomRestHandler restHandler.SbomRestHandler) *SbomRouterImpl {
+	return &SbomRouterImpl{sbomRestHandler: sbomRestHandler}
+}
+
+func (router SbomRouterImpl) InitSbomRouter(sbomRouter *mux.Router) {
+	sbomRouter.Path("/ingest").HandlerFunc(router.sbomRestHandler.IngestSbom).Methods("POST")
+	sbomRouter.Path("/artifact/{artifactId}").HandlerFunc(router.sbomRestHandler.GetSbom).Methods("GET")
+	sbomRouter.Path("/artifact/{artifactId}/download").HandlerFunc(router.sbomRestHandler.DownloadSbom).Methods("GET")
+	sbomRouter.Path("/package/search").HandlerFunc(router.sbomRestHandler.SearchPackages).Methods("GET")
+}
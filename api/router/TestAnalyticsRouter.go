@@ -0,0 +1,29 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type TestAnalyticsRouter interface {
+	InitTestAnalyticsRouter(router *mux.Router)
+}
+
+type TestAnalyticsRouterImpl struct {
+	testAnalyticsRestHandler restHandler.TestAnalyticsRestHandler
+}
+
+func NewTestAnalyticsRouterImpl(testAnalyticsRestHandler restHandler.TestAnalyticsRestHandler) *TestAnalyticsRouterImpl {
+	return &TestAnalyticsRouterImpl{testAnalyticsRestHandler: testAnalyticsRestHandler}
+}
+
+func (router TestAnalyticsRouterImpl) InitTestAnalyticsRouter(testAnalyticsRouter *mux.Router) {
+	testAnalyticsRouter.Path("/ci-pipeline/{ciPipelineId}/flaky").HandlerFunc(router.testAnalyticsRestHandler.GetFlakyTests).Methods("GET")
+	testAnalyticsRouter.Path("/ci-pipeline/{ciPipelineId}/top-failing").HandlerFunc(router.testAnalyticsRestHandler.GetTopFailingTests).Methods("GET")
+	testAnalyticsRouter.Path("/ci-pipeline/{ciPipelineId}/test-trend").HandlerFunc(router.testAnalyticsRestHandler.GetTestTrend).Methods("GET")
+	testAnalyticsRouter.Path("/ci-pipeline/{ciPipelineId}/build-trend").HandlerFunc(router.testAnalyticsRestHandler.GetBuildTrend).Methods("GET")
+
+	testAnalyticsRouter.Path("/ci-pipeline/{ciPipelineId}/quarantine").HandlerFunc(router.testAnalyticsRestHandler.QuarantineTest).Methods("POST")
+	testAnalyticsRouter.Path("/ci-pipeline/{ciPipelineId}/quarantine/list").HandlerFunc(router.testAnalyticsRestHandler.GetQuarantinedTests).Methods("GET")
+	testAnalyticsRouter.Path("/ci-pipeline/{ciPipelineId}/quarantine/{id}").HandlerFunc(router.testAnalyticsRestHandler.RemoveQuarantine).Methods("DELETE")
+}
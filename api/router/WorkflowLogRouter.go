@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type WorkflowLogRouter interface {
+	InitWorkflowLogRouter(router *mux.Router)
+}
+
+type WorkflowLogRouterImpl struct {
+	workflowLogRestHandler restHandler.WorkflowLogRestHandler
+}
+
+func NewWorkflowLogRouterImpl(workflowLogRestHandler restHandler.WorkflowLogRestHandler) *WorkflowLogRouterImpl {
+	return &WorkflowLogRouterImpl{workflowLogRestHandler: workflowLogRestHandler}
+}
+
+func (router WorkflowLogRouterImpl) InitWorkflowLogRouter(workflowLogRouter *mux.Router) {
+	workflowLogRouter.Path("/{workflowType}/pipeline/{pipelineId}/search").HandlerFunc(router.workflowLogRestHandler.SearchLogs).Methods("GET")
+	workflowLogRouter.Path("/{workflowType}/pipeline/{pipelineId}/workflow/{workflowId}/steps").HandlerFunc(router.workflowLogRestHandler.GetSteps).Methods("GET")
+	workflowLogRouter.Path("/{workflowType}/pipeline/{pipelineId}/workflow/{workflowId}/step/{stepIndex}/download").HandlerFunc(router.workflowLogRestHandler.DownloadStepLogs).Methods("GET")
+}
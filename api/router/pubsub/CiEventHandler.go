@@ -28,6 +28,7 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
 	"github.com/devtron-labs/devtron/pkg/pipeline"
 	bean2 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/pkg/sbom"
 	"github.com/devtron-labs/devtron/util"
 	"go.uber.org/zap"
 	"time"
@@ -54,6 +55,7 @@ type CiEventHandlerImpl struct {
 	pubsubClient   *pubsub.PubSubClientServiceImpl
 	webhookService pipeline.WebhookService
 	ciEventConfig  *CiEventConfig
+	sbomService    sbom.SbomService
 }
 
 type ImageDetailsFromCR struct {
@@ -78,14 +80,17 @@ type CiCompleteEvent struct {
 	ImageDetailsFromCR            *ImageDetailsFromCR      `json:"imageDetailsFromCR"`
 	PluginRegistryArtifactDetails map[string][]string      `json:"PluginRegistryArtifactDetails"`
 	PluginArtifactStage           string                   `json:"pluginArtifactStage"`
+	Sbom                          json.RawMessage          `json:"sbom"` // SBOM generated by the sbom step of the build
 }
 
-func NewCiEventHandlerImpl(logger *zap.SugaredLogger, pubsubClient *pubsub.PubSubClientServiceImpl, webhookService pipeline.WebhookService, ciEventConfig *CiEventConfig) *CiEventHandlerImpl {
+func NewCiEventHandlerImpl(logger *zap.SugaredLogger, pubsubClient *pubsub.PubSubClientServiceImpl, webhookService pipeline.WebhookService, ciEventConfig *CiEventConfig,
+	sbomService sbom.SbomService) *CiEventHandlerImpl {
 	ciEventHandlerImpl := &CiEventHandlerImpl{
 		logger:         logger,
 		pubsubClient:   pubsubClient,
 		webhookService: webhookService,
 		ciEventConfig:  ciEventConfig,
+		sbomService:    sbomService,
 	}
 	err := ciEventHandlerImpl.Subscribe()
 	if err != nil {
@@ -152,6 +157,9 @@ func (impl *CiEventHandlerImpl) Subscribe() error {
 				return
 			}
 			impl.logger.Debug(resp)
+			if resp > 0 && len(ciCompleteEvent.Sbom) > 0 {
+				impl.ingestSbom(resp, ciCompleteEvent)
+			}
 		}
 	}
 	err := impl.pubsubClient.Subscribe(pubsub.CI_COMPLETE_TOPIC, callback)
@@ -162,6 +170,22 @@ func (impl *CiEventHandlerImpl) Subscribe() error {
 	return nil
 }
 
+// ingestSbom indexes the SBOM the build generated against its ci artifact, the artifact is kept if this fails
+func (impl *CiEventHandlerImpl) ingestSbom(ciArtifactId int, event CiCompleteEvent) {
+	userId := event.TriggeredBy
+	if userId == 0 {
+		userId = 1 // system triggered event
+	}
+	_, err := impl.sbomService.IngestSbom(&sbom.IngestSbomRequest{
+		CiArtifactId: ciArtifactId,
+		Document:     event.Sbom,
+		UserId:       userId,
+	})
+	if err != nil {
+		impl.logger.Errorw("error in ingesting sbom of ci artifact", "ciArtifactId", ciArtifactId, "pipelineId", event.PipelineId, "err", err)
+	}
+}
+
 func (impl *CiEventHandlerImpl) BuildCiArtifactRequest(event CiCompleteEvent) (*pipeline.CiArtifactWebhookRequest, error) {
 	var ciMaterialInfos []repository.CiMaterialInfo
 	for _, p := range event.CiProjectDetails {
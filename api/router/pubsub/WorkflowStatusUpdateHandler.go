@@ -27,6 +27,7 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
 	"github.com/devtron-labs/devtron/pkg/pipeline"
 	"github.com/devtron-labs/devtron/pkg/pipeline/executors"
+	"github.com/devtron-labs/devtron/pkg/pluginTest"
 	util "github.com/devtron-labs/devtron/util/event"
 	"go.uber.org/zap"
 )
@@ -43,10 +44,12 @@ type WorkflowStatusUpdateHandlerImpl struct {
 	eventFactory         client.EventFactory
 	eventClient          client.EventClient
 	cdWorkflowRepository pipelineConfig.CdWorkflowRepository
+	pluginTestService    pluginTest.PluginTestService
 }
 
 func NewWorkflowStatusUpdateHandlerImpl(logger *zap.SugaredLogger, pubsubClient *pubsub.PubSubClientServiceImpl, ciHandler pipeline.CiHandler, cdHandler pipeline.CdHandler,
-	eventFactory client.EventFactory, eventClient client.EventClient, cdWorkflowRepository pipelineConfig.CdWorkflowRepository) *WorkflowStatusUpdateHandlerImpl {
+	eventFactory client.EventFactory, eventClient client.EventClient, cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	pluginTestService pluginTest.PluginTestService) *WorkflowStatusUpdateHandlerImpl {
 	workflowStatusUpdateHandlerImpl := &WorkflowStatusUpdateHandlerImpl{
 		logger:               logger,
 		pubsubClient:         pubsubClient,
@@ -55,6 +58,7 @@ func NewWorkflowStatusUpdateHandlerImpl(logger *zap.SugaredLogger, pubsubClient
 		eventFactory:         eventFactory,
 		eventClient:          eventClient,
 		cdWorkflowRepository: cdWorkflowRepository,
+		pluginTestService:    pluginTestService,
 	}
 	err := workflowStatusUpdateHandlerImpl.Subscribe()
 	if err != nil {
@@ -80,6 +84,14 @@ func (impl *WorkflowStatusUpdateHandlerImpl) Subscribe() error {
 			return
 		}
 
+		// plugin test runs have no ci workflow, their status is tracked on the test run
+		if handled, err := impl.pluginTestService.UpdateWorkflowStatus(wfStatus); handled {
+			if err != nil {
+				impl.logger.Errorw("error on update plugin test run status", "err", err, "msg", string(msg.Data))
+			}
+			return
+		}
+
 		err = impl.ciHandler.CheckAndReTriggerCI(wfStatus)
 		if err != nil {
 			impl.logger.Errorw("error in checking and re triggering ci", "err", err)
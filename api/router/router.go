@@ -122,6 +122,27 @@ type MuxRouter struct {
 	rbacRoleRouter                     user.RbacRoleRouter
 	scopedVariableRouter               ScopedVariableRouter
 	ciTriggerCron                      cron.CiTriggerCron
+	deploymentWindowRouter             DeploymentWindowRouter
+	artifactPromotionPolicyRouter      ArtifactPromotionPolicyRouter
+	scheduledDeploymentCron            cron.ScheduledDeploymentCron
+	deploymentConcurrencyRouter        DeploymentConcurrencyRouter
+	deploymentQueueCron                cron.DeploymentQueueCron
+	canaryAnalysisRouter               CanaryAnalysisRouter
+	canaryAnalysisCron                 cron.CanaryAnalysisCron
+	gitSyncRouter                      GitSyncRouter
+	gitSyncCron                        cron.GitSyncCron
+	ciRetryCron                        cron.CiRetryCron
+	ciBuildQueueRouter                 CiBuildQueueRouter
+	ciBuildQueueCron                   cron.CiBuildQueueCron
+	sbomRouter                         SbomRouter
+	imageSigningRouter                 ImageSigningRouter
+	provenanceRouter                   ProvenanceRouter
+	artifactRetentionRouter            ArtifactRetentionRouter
+	artifactGcCron                     cron.ArtifactGcCron
+	workflowLogRouter                  WorkflowLogRouter
+	workflowLogIndexCron               cron.WorkflowLogIndexCron
+	testAnalyticsRouter                TestAnalyticsRouter
+	pluginTestRouter                   PluginTestRouter
 }
 
 func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, PipelineConfigRouter PipelineConfigRouter,
@@ -153,7 +174,14 @@ func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, P
 	jobRouter JobRouter, ciStatusUpdateCron cron.CiStatusUpdateCron, resourceGroupingRouter ResourceGroupingRouter,
 	rbacRoleRouter user.RbacRoleRouter,
 	scopedVariableRouter ScopedVariableRouter,
-	ciTriggerCron cron.CiTriggerCron) *MuxRouter {
+	ciTriggerCron cron.CiTriggerCron, deploymentWindowRouter DeploymentWindowRouter, artifactPromotionPolicyRouter ArtifactPromotionPolicyRouter,
+	scheduledDeploymentCron cron.ScheduledDeploymentCron, deploymentConcurrencyRouter DeploymentConcurrencyRouter,
+	deploymentQueueCron cron.DeploymentQueueCron, canaryAnalysisRouter CanaryAnalysisRouter,
+	canaryAnalysisCron cron.CanaryAnalysisCron, gitSyncRouter GitSyncRouter, gitSyncCron cron.GitSyncCron,
+	ciRetryCron cron.CiRetryCron, ciBuildQueueRouter CiBuildQueueRouter, ciBuildQueueCron cron.CiBuildQueueCron, sbomRouter SbomRouter,
+	imageSigningRouter ImageSigningRouter, provenanceRouter ProvenanceRouter, artifactRetentionRouter ArtifactRetentionRouter,
+	artifactGcCron cron.ArtifactGcCron, workflowLogRouter WorkflowLogRouter, workflowLogIndexCron cron.WorkflowLogIndexCron,
+	testAnalyticsRouter TestAnalyticsRouter, pluginTestRouter PluginTestRouter) *MuxRouter {
 	r := &MuxRouter{
 		Router:                             mux.NewRouter(),
 		HelmRouter:                         HelmRouter,
@@ -225,6 +253,27 @@ func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, P
 		rbacRoleRouter:                     rbacRoleRouter,
 		scopedVariableRouter:               scopedVariableRouter,
 		ciTriggerCron:                      ciTriggerCron,
+		deploymentWindowRouter:             deploymentWindowRouter,
+		artifactPromotionPolicyRouter:      artifactPromotionPolicyRouter,
+		scheduledDeploymentCron:            scheduledDeploymentCron,
+		deploymentConcurrencyRouter:        deploymentConcurrencyRouter,
+		deploymentQueueCron:                deploymentQueueCron,
+		canaryAnalysisRouter:               canaryAnalysisRouter,
+		canaryAnalysisCron:                 canaryAnalysisCron,
+		gitSyncRouter:                      gitSyncRouter,
+		gitSyncCron:                        gitSyncCron,
+		ciRetryCron:                        ciRetryCron,
+		ciBuildQueueRouter:                 ciBuildQueueRouter,
+		ciBuildQueueCron:                   ciBuildQueueCron,
+		sbomRouter:                         sbomRouter,
+		imageSigningRouter:                 imageSigningRouter,
+		provenanceRouter:                   provenanceRouter,
+		artifactRetentionRouter:            artifactRetentionRouter,
+		artifactGcCron:                     artifactGcCron,
+		workflowLogRouter:                  workflowLogRouter,
+		workflowLogIndexCron:               workflowLogIndexCron,
+		testAnalyticsRouter:                testAnalyticsRouter,
+		pluginTestRouter:                   pluginTestRouter,
 	}
 	return r
 }
@@ -435,4 +484,45 @@ func (r MuxRouter) Init() {
 
 	rbacRoleRouter := r.Router.PathPrefix("/orchestrator/rbac/role").Subrouter()
 	r.rbacRoleRouter.InitRbacRoleRouter(rbacRoleRouter)
+
+	deploymentWindowRouter := r.Router.PathPrefix("/orchestrator/deployment-window").Subrouter()
+	r.deploymentWindowRouter.InitDeploymentWindowRouter(deploymentWindowRouter)
+
+	artifactPromotionPolicyRouter := r.Router.PathPrefix("/orchestrator/artifact-promotion-policy").Subrouter()
+	r.artifactPromotionPolicyRouter.InitArtifactPromotionPolicyRouter(artifactPromotionPolicyRouter)
+
+	deploymentConcurrencyRouter := r.Router.PathPrefix("/orchestrator/deployment-concurrency").Subrouter()
+	r.deploymentConcurrencyRouter.InitDeploymentConcurrencyRouter(deploymentConcurrencyRouter)
+
+	canaryAnalysisRouter := r.Router.PathPrefix("/orchestrator/canary-analysis").Subrouter()
+	r.canaryAnalysisRouter.InitCanaryAnalysisRouter(canaryAnalysisRouter)
+
+	gitSyncRouter := r.Router.PathPrefix("/orchestrator/git-sync").Subrouter()
+	r.gitSyncRouter.InitGitSyncRouter(gitSyncRouter)
+
+	ciBuildQueueRouter := r.Router.PathPrefix("/orchestrator/ci-build-queue").Subrouter()
+	r.ciBuildQueueRouter.InitCiBuildQueueRouter(ciBuildQueueRouter)
+
+	sbomRouter := r.Router.PathPrefix("/orchestrator/sbom").Subrouter()
+	r.sbomRouter.InitSbomRouter(sbomRouter)
+
+	imageSigningRouter := r.Router.PathPrefix("/orchestrator/image-signing").Subrouter()
+	r.imageSigningRouter.InitImageSigningRouter(imageSigningRouter)
+
+	provenanceRouter := r.Router.PathPrefix("/orchestrator/provenance").Subrouter()
+	r.provenanceRouter.InitProvenanceRouter(provenanceRouter)
+
+	artifactRetentionRouter := r.Router.PathPrefix("/orchestrator/artifact-retention").Subrouter()
+	r.artifactRetentionRouter.InitArtifactRetentionRouter(artifactRetentionRouter)
+
+	workflowLogRouter := r.Router.PathPrefix("/orchestrator/workflow-logs").Subrouter()
+	r.workflowLogRouter.InitWorkflowLogRouter(workflowLogRouter)
+
+	testAnalyticsRouter := r.Router.PathPrefix("/orchestrator/test-analytics").Subrouter()
+	r.testAnalyticsRouter.InitTestAnalyticsRouter(testAnalyticsRouter)
+
+	pluginTestRouter := r.Router.PathPrefix("/orchestrator/plugin/test").Subrouter()
+	r.pluginTestRouter.InitPluginTestRouter(pluginTestRouter)
+	pluginTestWebhookRouter := r.Router.PathPrefix("/orchestrator/webhook/plugin-test").Subrouter()
+	r.pluginTestRouter.InitPluginTestWebhookRouter(pluginTestWebhookRouter)
 }
@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/artifactRetention"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type ArtifactGcCron interface {
+	CollectGarbage()
+}
+
+type ArtifactGcCronImpl struct {
+	logger                   *zap.SugaredLogger
+	cron                     *cron.Cron
+	artifactRetentionService artifactRetention.ArtifactRetentionService
+}
+
+func NewArtifactGcCronImpl(logger *zap.SugaredLogger, cfg *ArtifactGcCronConfig,
+	artifactRetentionService artifactRetention.ArtifactRetentionService) *ArtifactGcCronImpl {
+	cronLogger := &CronLoggerImpl{logger: logger}
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cronLogger)))
+	cron.Start()
+	impl := &ArtifactGcCronImpl{
+		logger:                   logger,
+		cron:                     cron,
+		artifactRetentionService: artifactRetentionService,
+	}
+	_, err := cron.AddFunc(cfg.ArtifactGcCron, impl.CollectGarbage)
+	if err != nil {
+		logger.Errorw("error while configure cron job for artifact garbage collection", "err", err)
+		return impl
+	}
+	return impl
+}
+
+type ArtifactGcCronConfig struct {
+	ArtifactGcCron string `env:"ARTIFACT_GC_CRON" envDefault:"0 2 * * *"`
+}
+
+func GetArtifactGcCronConfig() (*ArtifactGcCronConfig, error) {
+	cfg := &ArtifactGcCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse artifact gc cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// CollectGarbage deletes the artifacts the retention policies do not keep
+func (impl *ArtifactGcCronImpl) CollectGarbage() {
+	impl.artifactRetentionService.CollectGarbage()
+}
@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/canaryAnalysis"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type CanaryAnalysisCron interface {
+	AnalyseCanaryDeployments()
+}
+
+type CanaryAnalysisCronImpl struct {
+	logger                *zap.SugaredLogger
+	cron                  *cron.Cron
+	canaryAnalysisService canaryAnalysis.CanaryAnalysisService
+}
+
+func NewCanaryAnalysisCronImpl(logger *zap.SugaredLogger, cfg *CanaryAnalysisCronConfig,
+	canaryAnalysisService canaryAnalysis.CanaryAnalysisService) *CanaryAnalysisCronImpl {
+	cronLogger := &CronLoggerImpl{logger: logger}
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cronLogger)))
+	cron.Start()
+	impl := &CanaryAnalysisCronImpl{
+		logger:                logger,
+		cron:                  cron,
+		canaryAnalysisService: canaryAnalysisService,
+	}
+	_, err := cron.AddFunc(fmt.Sprintf("@every %ds", cfg.CanaryAnalysisCronTimeInSecs), impl.AnalyseCanaryDeployments)
+	if err != nil {
+		logger.Errorw("error while configure cron job for canary analysis", "err", err)
+		return impl
+	}
+	return impl
+}
+
+type CanaryAnalysisCronConfig struct {
+	CanaryAnalysisCronTimeInSecs int `env:"CANARY_ANALYSIS_CRON_TIME" envDefault:"30"`
+}
+
+func GetCanaryAnalysisCronConfig() (*CanaryAnalysisCronConfig, error) {
+	cfg := &CanaryAnalysisCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse canary analysis cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// AnalyseCanaryDeployments evaluates the metrics of the canary deployments paused at a step and promotes or aborts them
+func (impl *CanaryAnalysisCronImpl) AnalyseCanaryDeployments() {
+	impl.canaryAnalysisService.AnalyseCanaryDeployments()
+}
@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type CiBuildQueueCron interface {
+	ReleaseQueuedBuilds()
+}
+
+type CiBuildQueueCronImpl struct {
+	logger    *zap.SugaredLogger
+	cron      *cron.Cron
+	ciService pipeline.CiService
+}
+
+func NewCiBuildQueueCronImpl(logger *zap.SugaredLogger, cfg *CiBuildQueueCronConfig,
+	ciService pipeline.CiService) *CiBuildQueueCronImpl {
+	cronLogger := &CronLoggerImpl{logger: logger}
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cronLogger)))
+	cron.Start()
+	impl := &CiBuildQueueCronImpl{
+		logger:    logger,
+		cron:      cron,
+		ciService: ciService,
+	}
+	_, err := cron.AddFunc(fmt.Sprintf("@every %ds", cfg.CiBuildQueueCronTimeInSecs), impl.ReleaseQueuedBuilds)
+	if err != nil {
+		logger.Errorw("error while configure cron job for ci build queue", "err", err)
+		return impl
+	}
+	return impl
+}
+
+type CiBuildQueueCronConfig struct {
+	CiBuildQueueCronTimeInSecs int `env:"CI_BUILD_QUEUE_CRON_TIME" envDefault:"30"`
+}
+
+func GetCiBuildQueueCronConfig() (*CiBuildQueueCronConfig, error) {
+	cfg := &CiBuildQueueCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse ci build queue cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ReleaseQueuedBuilds submits the queued ci builds for which capacity freed up since the last run
+func (impl *CiBuildQueueCronImpl) ReleaseQueuedBuilds() {
+	impl.ciService.ReleaseQueuedBuilds()
+}
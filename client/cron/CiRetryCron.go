@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type CiRetryCron interface {
+	ExecuteDueRetries()
+}
+
+type CiRetryCronImpl struct {
+	logger               *zap.SugaredLogger
+	cron                 *cron.Cron
+	ciRetryPolicyService pipeline.CiRetryPolicyService
+}
+
+func NewCiRetryCronImpl(logger *zap.SugaredLogger, cfg *CiRetryCronConfig,
+	ciRetryPolicyService pipeline.CiRetryPolicyService) *CiRetryCronImpl {
+	cronLogger := &CronLoggerImpl{logger: logger}
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cronLogger)))
+	cron.Start()
+	impl := &CiRetryCronImpl{
+		logger:               logger,
+		cron:                 cron,
+		ciRetryPolicyService: ciRetryPolicyService,
+	}
+	_, err := cron.AddFunc(fmt.Sprintf("@every %ds", cfg.CiRetryCronTimeInSecs), impl.ExecuteDueRetries)
+	if err != nil {
+		logger.Errorw("error while configure cron job for ci retries", "err", err)
+		return impl
+	}
+	return impl
+}
+
+type CiRetryCronConfig struct {
+	CiRetryCronTimeInSecs int `env:"CI_RETRY_CRON_TIME" envDefault:"30"`
+}
+
+func GetCiRetryCronConfig() (*CiRetryCronConfig, error) {
+	cfg := &CiRetryCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse ci retry cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ExecuteDueRetries re-triggers the failed ci workflows whose retry backoff is over
+func (impl *CiRetryCronImpl) ExecuteDueRetries() {
+	impl.ciRetryPolicyService.ExecuteDueRetries()
+}
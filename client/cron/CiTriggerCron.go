@@ -67,12 +67,12 @@ func GetCiTriggerCronConfig() (*CiTriggerCronConfig, error) {
 
 // UpdateCiWorkflowStatusFailedCron this function will execute periodically
 func (impl *CiTriggerCronImpl) TriggerCiCron() {
-	plugin, err := impl.globalPluginRepository.GetPluginByName(impl.cfg.PluginName)
-	if err != nil || len(plugin) == 0 {
+	pluginIds, err := impl.globalPluginRepository.GetPluginVersionIdsByName(impl.cfg.PluginName)
+	if err != nil || len(pluginIds) == 0 {
 		return
 	}
 
-	ciPipelineIds, err := impl.pipelineStageRepository.GetAllCiPipelineIdsByPluginIdAndStageType(plugin[0].Id, string(repository.PIPELINE_STAGE_TYPE_PRE_CI))
+	ciPipelineIds, err := impl.pipelineStageRepository.GetAllCiPipelineIdsByPluginIdsAndStageType(pluginIds, string(repository.PIPELINE_STAGE_TYPE_PRE_CI))
 	if err != nil {
 		return
 	}
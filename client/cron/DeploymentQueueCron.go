@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type DeploymentQueueCron interface {
+	ReleaseQueuedDeployments()
+}
+
+type DeploymentQueueCronImpl struct {
+	logger              *zap.SugaredLogger
+	cron                *cron.Cron
+	workflowDagExecutor pipeline.WorkflowDagExecutor
+}
+
+func NewDeploymentQueueCronImpl(logger *zap.SugaredLogger, cfg *DeploymentQueueCronConfig,
+	workflowDagExecutor pipeline.WorkflowDagExecutor) *DeploymentQueueCronImpl {
+	cronLogger := &CronLoggerImpl{logger: logger}
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cronLogger)))
+	cron.Start()
+	impl := &DeploymentQueueCronImpl{
+		logger:              logger,
+		cron:                cron,
+		workflowDagExecutor: workflowDagExecutor,
+	}
+	_, err := cron.AddFunc(fmt.Sprintf("@every %ds", cfg.DeploymentQueueCronTimeInSecs), impl.ReleaseQueuedDeployments)
+	if err != nil {
+		logger.Errorw("error while configure cron job for deployment queue", "err", err)
+		return impl
+	}
+	return impl
+}
+
+type DeploymentQueueCronConfig struct {
+	DeploymentQueueCronTimeInSecs int `env:"DEPLOYMENT_QUEUE_CRON_TIME" envDefault:"30"`
+}
+
+func GetDeploymentQueueCronConfig() (*DeploymentQueueCronConfig, error) {
+	cfg := &DeploymentQueueCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse deployment queue cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ReleaseQueuedDeployments triggers the queued deployments whose environment and cluster have free capacity
+func (impl *DeploymentQueueCronImpl) ReleaseQueuedDeployments() {
+	impl.workflowDagExecutor.ReleaseQueuedDeployments()
+}
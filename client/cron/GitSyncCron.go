@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/gitSync"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type GitSyncCron interface {
+	ReconcileGitSyncConfigs()
+}
+
+type GitSyncCronImpl struct {
+	logger         *zap.SugaredLogger
+	cron           *cron.Cron
+	gitSyncService gitSync.GitSyncService
+}
+
+func NewGitSyncCronImpl(logger *zap.SugaredLogger, cfg *GitSyncCronConfig,
+	gitSyncService gitSync.GitSyncService) *GitSyncCronImpl {
+	cronLogger := &CronLoggerImpl{logger: logger}
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cronLogger)))
+	cron.Start()
+	impl := &GitSyncCronImpl{
+		logger:         logger,
+		cron:           cron,
+		gitSyncService: gitSyncService,
+	}
+	_, err := cron.AddFunc(fmt.Sprintf("@every %ds", cfg.GitSyncCronTimeInSecs), impl.ReconcileGitSyncConfigs)
+	if err != nil {
+		logger.Errorw("error while configure cron job for git sync", "err", err)
+		return impl
+	}
+	return impl
+}
+
+type GitSyncCronConfig struct {
+	GitSyncCronTimeInSecs int `env:"GIT_SYNC_CRON_TIME" envDefault:"180"`
+}
+
+func GetGitSyncCronConfig() (*GitSyncCronConfig, error) {
+	cfg := &GitSyncCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse git sync cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ReconcileGitSyncConfigs reconciles the apps of every git sync config with the head of its branch
+func (impl *GitSyncCronImpl) ReconcileGitSyncConfigs() {
+	impl.gitSyncService.ReconcileAll()
+}
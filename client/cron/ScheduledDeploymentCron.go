@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type ScheduledDeploymentCron interface {
+	ExecuteScheduledDeployments()
+}
+
+type ScheduledDeploymentCronImpl struct {
+	logger                     *zap.SugaredLogger
+	cron                       *cron.Cron
+	scheduledDeploymentService pipeline.ScheduledDeploymentService
+}
+
+func NewScheduledDeploymentCronImpl(logger *zap.SugaredLogger, cfg *ScheduledDeploymentCronConfig,
+	scheduledDeploymentService pipeline.ScheduledDeploymentService) *ScheduledDeploymentCronImpl {
+	cronLogger := &CronLoggerImpl{logger: logger}
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cronLogger)))
+	cron.Start()
+	impl := &ScheduledDeploymentCronImpl{
+		logger:                     logger,
+		cron:                       cron,
+		scheduledDeploymentService: scheduledDeploymentService,
+	}
+	_, err := cron.AddFunc(fmt.Sprintf("@every %dm", cfg.ScheduledDeploymentCronTime), impl.ExecuteScheduledDeployments)
+	if err != nil {
+		logger.Errorw("error while configure cron job for scheduled deployments", "err", err)
+		return impl
+	}
+	return impl
+}
+
+type ScheduledDeploymentCronConfig struct {
+	ScheduledDeploymentCronTime int `env:"SCHEDULED_DEPLOYMENT_CRON_TIME" envDefault:"1"`
+}
+
+func GetScheduledDeploymentCronConfig() (*ScheduledDeploymentCronConfig, error) {
+	cfg := &ScheduledDeploymentCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse scheduled deployment cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ExecuteScheduledDeployments triggers the deployments whose scheduled time has passed
+func (impl *ScheduledDeploymentCronImpl) ExecuteScheduledDeployments() {
+	impl.scheduledDeploymentService.ExecuteDueScheduledDeployments()
+}
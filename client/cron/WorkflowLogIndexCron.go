@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/pkg/workflowLog"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+type WorkflowLogIndexCron interface {
+	IndexPendingWorkflows()
+}
+
+type WorkflowLogIndexCronImpl struct {
+	logger             *zap.SugaredLogger
+	cron               *cron.Cron
+	workflowLogService workflowLog.WorkflowLogService
+}
+
+func NewWorkflowLogIndexCronImpl(logger *zap.SugaredLogger, cfg *WorkflowLogIndexCronConfig,
+	workflowLogService workflowLog.WorkflowLogService) *WorkflowLogIndexCronImpl {
+	cronLogger := &CronLoggerImpl{logger: logger}
+	cron := cron.New(
+		cron.WithChain(cron.SkipIfStillRunning(cronLogger)))
+	cron.Start()
+	impl := &WorkflowLogIndexCronImpl{
+		logger:             logger,
+		cron:               cron,
+		workflowLogService: workflowLogService,
+	}
+	_, err := cron.AddFunc(fmt.Sprintf("@every %dm", cfg.WorkflowLogIndexFrequencyInMinutes), impl.IndexPendingWorkflows)
+	if err != nil {
+		logger.Errorw("error while configure cron job for workflow log indexing", "err", err)
+		return impl
+	}
+	return impl
+}
+
+type WorkflowLogIndexCronConfig struct {
+	WorkflowLogIndexFrequencyInMinutes int `env:"WORKFLOW_LOG_INDEX_FREQUENCY_IN_MINUTES" envDefault:"5"`
+}
+
+func GetWorkflowLogIndexCronConfig() (*WorkflowLogIndexCronConfig, error) {
+	cfg := &WorkflowLogIndexCronConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		fmt.Println("failed to parse workflow log index cron config: " + err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// IndexPendingWorkflows splits the logs of the recently finished workflows into searchable steps
+func (impl *WorkflowLogIndexCronImpl) IndexPendingWorkflows() {
+	impl.workflowLogService.IndexPendingWorkflows()
+}
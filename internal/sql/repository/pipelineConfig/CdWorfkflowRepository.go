@@ -48,6 +48,10 @@ type CdWorkflowRepository interface {
 	UpdateWorkFlowRunners(wfr []*CdWorkflowRunner) error
 	FindWorkflowRunnerByCdWorkflowId(wfIds []int) ([]*CdWorkflowRunner, error)
 	FindPreviousCdWfRunnerByStatus(pipelineId int, currentWFRunnerId int, status []string) ([]*CdWorkflowRunner, error)
+	FindLastSucceededDeploymentRunnerBefore(pipelineId int, currentWFRunnerId int) (*CdWorkflowRunner, error)
+	FindLatestSucceededDeploymentRunner(pipelineId int) (*CdWorkflowRunner, error)
+	MarkAutoRollbackAttempted(wfrId int) (bool, error)
+	FindSucceededRunnersByPipelineIdAndArtifactIds(pipelineId int, artifactIds []int, runnerType bean.WorkflowType) ([]*CdWorkflowRunner, error)
 	FindConfigByPipelineId(pipelineId int) (*CdWorkflowConfig, error)
 	FindWorkflowRunnerById(wfrId int) (*CdWorkflowRunner, error)
 	FindRetriedWorkflowCountByReferenceId(wfrId int) (int, error)
@@ -102,6 +106,7 @@ const (
 	WorkflowSucceeded          = "Succeeded"
 	WorkflowTimedOut           = "TimedOut"
 	WorkflowUnableToFetchState = "UnableToFetch"
+	WorkflowSkipped            = "Skipped"
 	WorkflowTypeDeploy         = "DEPLOY"
 	WorkflowTypePre            = "PRE"
 	WorkflowTypePost           = "POST"
@@ -155,31 +160,35 @@ const (
 	FOUND_VULNERABILITY           = "Found vulnerability on image"
 )
 
+const CD_TRIGGER_TYPE_AUTO_ROLLBACK = "AUTO_ROLLBACK"
+
 type CdWorkflowRunnerWithExtraFields struct {
 	CdWorkflowRunner
 	TotalCount int
 }
 
 type CdWorkflowRunner struct {
-	tableName               struct{}             `sql:"cd_workflow_runner" pg:",discard_unknown_columns"`
-	Id                      int                  `sql:"id,pk"`
-	Name                    string               `sql:"name"`
-	WorkflowType            bean.WorkflowType    `sql:"workflow_type"` //pre,post,deploy
-	ExecutorType            WorkflowExecutorType `sql:"executor_type"` //awf, system
-	Status                  string               `sql:"status"`
-	PodStatus               string               `sql:"pod_status"`
-	Message                 string               `sql:"message"`
-	StartedOn               time.Time            `sql:"started_on"`
-	FinishedOn              time.Time            `sql:"finished_on"`
-	Namespace               string               `sql:"namespace"`
-	LogLocation             string               `sql:"log_file_path"`
-	TriggeredBy             int32                `sql:"triggered_by"`
-	CdWorkflowId            int                  `sql:"cd_workflow_id"`
-	PodName                 string               `sql:"pod_name"`
-	BlobStorageEnabled      bool                 `sql:"blob_storage_enabled,notnull"`
-	RefCdWorkflowRunnerId   int                  `sql:"ref_cd_workflow_runner_id,notnull"`
-	ImagePathReservationIds []int                `sql:"image_path_reservation_ids" pg:",array,notnull"`
-	CdWorkflow              *CdWorkflow
+	tableName                   struct{}             `sql:"cd_workflow_runner" pg:",discard_unknown_columns"`
+	Id                          int                  `sql:"id,pk"`
+	Name                        string               `sql:"name"`
+	WorkflowType                bean.WorkflowType    `sql:"workflow_type"` //pre,post,deploy
+	ExecutorType                WorkflowExecutorType `sql:"executor_type"` //awf, system
+	Status                      string               `sql:"status"`
+	PodStatus                   string               `sql:"pod_status"`
+	Message                     string               `sql:"message"`
+	StartedOn                   time.Time            `sql:"started_on"`
+	FinishedOn                  time.Time            `sql:"finished_on"`
+	Namespace                   string               `sql:"namespace"`
+	LogLocation                 string               `sql:"log_file_path"`
+	TriggeredBy                 int32                `sql:"triggered_by"`
+	CdWorkflowId                int                  `sql:"cd_workflow_id"`
+	PodName                     string               `sql:"pod_name"`
+	BlobStorageEnabled          bool                 `sql:"blob_storage_enabled,notnull"`
+	RefCdWorkflowRunnerId       int                  `sql:"ref_cd_workflow_runner_id,notnull"`
+	ImagePathReservationIds     []int                `sql:"image_path_reservation_ids" pg:",array,notnull"`
+	DeploymentApprovalRequestId int                  `sql:"deployment_approval_request_id"`
+	TriggerType                 string               `sql:"trigger_type"` //empty for regular triggers
+	CdWorkflow                  *CdWorkflow
 	sql.AuditLog
 }
 
@@ -237,6 +246,7 @@ type CdWorkflowWithArtifact struct {
 	ImageReleaseTags      []*repository2.ImageTag      `json:"imageReleaseTags"`
 	ImageComment          *repository2.ImageComment    `json:"imageComment"`
 	RefCdWorkflowRunnerId int                          `json:"referenceCdWorkflowRunnerId"`
+	TriggerType           string                       `json:"triggerType,omitempty"`
 }
 
 type TriggerWorkflowStatus struct {
@@ -254,6 +264,16 @@ type CdWorkflowStatus struct {
 	WorkflowType               string `json:"workflow_type,omitempty"`
 	WfrId                      int    `json:"wfr_id,omitempty"`
 	DeploymentAppDeleteRequest bool   `json:"deploymentAppDeleteRequest"`
+	// ScheduledDeployments are the deployments scheduled on the pipeline which are yet to be triggered
+	ScheduledDeployments []*PendingScheduledDeployment `json:"scheduledDeployments,omitempty"`
+	// QueuePosition is the position of the deployment in the deployment queue if it waits on concurrency limits
+	QueuePosition int `json:"queuePosition,omitempty"`
+}
+
+type PendingScheduledDeployment struct {
+	Id           int       `json:"id"`
+	CiArtifactId int       `json:"ciArtifactId"`
+	ScheduledOn  time.Time `json:"scheduledOn"`
 }
 
 type CiWorkflowStatus struct {
@@ -262,6 +282,7 @@ type CiWorkflowStatus struct {
 	CiStatus          string `json:"ciStatus"`
 	StorageConfigured bool   `json:"storageConfigured"`
 	CiWorkflowId      int    `json:"ciWorkflowId,omitempty"`
+	QueuePosition     int    `json:"queuePosition,omitempty"`
 }
 
 type AppDeploymentStatus struct {
@@ -292,6 +313,64 @@ func (impl *CdWorkflowRepositoryImpl) FindPreviousCdWfRunnerByStatus(pipelineId
 	return runner, err
 }
 
+func (impl *CdWorkflowRepositoryImpl) FindLastSucceededDeploymentRunnerBefore(pipelineId int, currentWFRunnerId int) (*CdWorkflowRunner, error) {
+	runner := &CdWorkflowRunner{}
+	err := impl.dbConnection.
+		Model(runner).
+		Column("cd_workflow_runner.*", "CdWorkflow", "CdWorkflow.CiArtifact").
+		Where("cd_workflow.pipeline_id = ?", pipelineId).
+		Where("cd_workflow_runner.id < ?", currentWFRunnerId).
+		Where("cd_workflow_runner.workflow_type = ?", bean.CD_WORKFLOW_TYPE_DEPLOY).
+		Where("cd_workflow_runner.status in (?)", pg.In([]string{WorkflowSucceeded, string(health.HealthStatusHealthy)})).
+		Order("cd_workflow_runner.id DESC").
+		Limit(1).
+		Select()
+	return runner, err
+}
+
+// FindLatestSucceededDeploymentRunner returns the last succeeded deployment on the pipeline, i.e. the one currently live
+func (impl *CdWorkflowRepositoryImpl) FindLatestSucceededDeploymentRunner(pipelineId int) (*CdWorkflowRunner, error) {
+	runner := &CdWorkflowRunner{}
+	err := impl.dbConnection.
+		Model(runner).
+		Column("cd_workflow_runner.*", "CdWorkflow").
+		Where("cd_workflow.pipeline_id = ?", pipelineId).
+		Where("cd_workflow_runner.workflow_type = ?", bean.CD_WORKFLOW_TYPE_DEPLOY).
+		Where("cd_workflow_runner.status in (?)", pg.In([]string{WorkflowSucceeded, string(health.HealthStatusHealthy)})).
+		Order("cd_workflow_runner.id DESC").
+		Limit(1).
+		Select()
+	return runner, err
+}
+
+// MarkAutoRollbackAttempted marks the auto rollback of the runner as attempted, it returns false if it already was, so
+// that concurrent status updates of the same runner roll it back only once
+func (impl *CdWorkflowRepositoryImpl) MarkAutoRollbackAttempted(wfrId int) (bool, error) {
+	res, err := impl.dbConnection.Exec("UPDATE cd_workflow_runner SET auto_rollback_attempted = true WHERE id = ? AND auto_rollback_attempted = false;", wfrId)
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+// FindSucceededRunnersByPipelineIdAndArtifactIds returns the succeeded runners of given type for the artifacts on the pipeline, oldest first
+func (impl *CdWorkflowRepositoryImpl) FindSucceededRunnersByPipelineIdAndArtifactIds(pipelineId int, artifactIds []int, runnerType bean.WorkflowType) ([]*CdWorkflowRunner, error) {
+	var runners []*CdWorkflowRunner
+	if len(artifactIds) == 0 {
+		return runners, nil
+	}
+	err := impl.dbConnection.
+		Model(&runners).
+		Column("cd_workflow_runner.*", "CdWorkflow").
+		Where("cd_workflow.pipeline_id = ?", pipelineId).
+		Where("cd_workflow.ci_artifact_id in (?)", pg.In(artifactIds)).
+		Where("cd_workflow_runner.workflow_type = ?", runnerType).
+		Where("cd_workflow_runner.status in (?)", pg.In([]string{WorkflowSucceeded, string(health.HealthStatusHealthy)})).
+		Order("cd_workflow_runner.id ASC").
+		Select()
+	return runners, err
+}
+
 func (impl *CdWorkflowRepositoryImpl) SaveWorkFlow(ctx context.Context, wf *CdWorkflow) error {
 	_, span := otel.Tracer("orchestrator").Start(ctx, "cdWorkflowRepository.SaveWorkFlow")
 	defer span.End()
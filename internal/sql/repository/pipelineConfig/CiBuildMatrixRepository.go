@@ -0,0 +1,121 @@
+package pipelineConfig
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// CiBuildMatrix is the manifest list built by a ci workflow out of the images of its build matrix platforms
+type CiBuildMatrix struct {
+	tableName        struct{} `sql:"ci_build_matrix" pg:",discard_unknown_columns"`
+	Id               int      `sql:"id,pk"`
+	CiWorkflowId     int      `sql:"ci_workflow_id,notnull"`
+	DockerRegistryId string   `sql:"docker_registry_id,notnull"`
+	DockerRepository string   `sql:"docker_repository,notnull"`
+	ImageTag         string   `sql:"image_tag,notnull"`
+	Image            string   `sql:"image,notnull"`
+	ImageDigest      string   `sql:"image_digest"`
+	Status           string   `sql:"status,notnull"`
+	Message          string   `sql:"message"`
+	// PendingStages is the number of pre and post ci stages yet to report their completion
+	PendingStages int `sql:"pending_stages,notnull"`
+	sql.AuditLog
+}
+
+type CiBuildMatrixPlatform struct {
+	tableName       struct{} `sql:"ci_build_matrix_platform" pg:",discard_unknown_columns"`
+	Id              int      `sql:"id,pk"`
+	CiBuildMatrixId int      `sql:"ci_build_matrix_id,notnull"`
+	Platform        string   `sql:"platform,notnull"`
+	ImageTag        string   `sql:"image_tag,notnull"`
+	ImageDigest     string   `sql:"image_digest"`
+	Status          string   `sql:"status,notnull"`
+	sql.AuditLog
+}
+
+type CiBuildMatrixRepository interface {
+	GetConnection() *pg.DB
+	Save(buildMatrix *CiBuildMatrix, platforms []*CiBuildMatrixPlatform) error
+	Update(buildMatrix *CiBuildMatrix) error
+	UpdatePlatforms(platforms []*CiBuildMatrixPlatform) error
+	FindByCiWorkflowId(ciWorkflowId int) (*CiBuildMatrix, error)
+	// FindByCiWorkflowIdForUpdate locks the build matrix of the workflow till tx ends
+	FindByCiWorkflowIdForUpdate(ciWorkflowId int, tx *pg.Tx) (*CiBuildMatrix, error)
+	FindPlatformsByBuildMatrixId(buildMatrixId int) ([]*CiBuildMatrixPlatform, error)
+	UpdatePlatform(platform *CiBuildMatrixPlatform, tx *pg.Tx) error
+}
+
+type CiBuildMatrixRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewCiBuildMatrixRepositoryImpl(dbConnection *pg.DB) *CiBuildMatrixRepositoryImpl {
+	return &CiBuildMatrixRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *CiBuildMatrixRepositoryImpl) GetConnection() *pg.DB {
+	return impl.dbConnection
+}
+
+func (impl *CiBuildMatrixRepositoryImpl) Save(buildMatrix *CiBuildMatrix, platforms []*CiBuildMatrixPlatform) error {
+	tx, err := impl.dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = tx.Insert(buildMatrix)
+	if err != nil {
+		return err
+	}
+	for _, platform := range platforms {
+		platform.CiBuildMatrixId = buildMatrix.Id
+	}
+	_, err = tx.Model(&platforms).Insert()
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (impl *CiBuildMatrixRepositoryImpl) Update(buildMatrix *CiBuildMatrix) error {
+	return impl.dbConnection.Update(buildMatrix)
+}
+
+func (impl *CiBuildMatrixRepositoryImpl) UpdatePlatforms(platforms []*CiBuildMatrixPlatform) error {
+	if len(platforms) == 0 {
+		return nil
+	}
+	_, err := impl.dbConnection.Model(&platforms).Update()
+	return err
+}
+
+func (impl *CiBuildMatrixRepositoryImpl) FindByCiWorkflowId(ciWorkflowId int) (*CiBuildMatrix, error) {
+	buildMatrix := &CiBuildMatrix{}
+	err := impl.dbConnection.Model(buildMatrix).
+		Where("ci_workflow_id = ?", ciWorkflowId).
+		Select()
+	return buildMatrix, err
+}
+
+func (impl *CiBuildMatrixRepositoryImpl) FindByCiWorkflowIdForUpdate(ciWorkflowId int, tx *pg.Tx) (*CiBuildMatrix, error) {
+	buildMatrix := &CiBuildMatrix{}
+	err := tx.Model(buildMatrix).
+		Where("ci_workflow_id = ?", ciWorkflowId).
+		For("UPDATE").
+		Select()
+	return buildMatrix, err
+}
+
+func (impl *CiBuildMatrixRepositoryImpl) FindPlatformsByBuildMatrixId(buildMatrixId int) ([]*CiBuildMatrixPlatform, error) {
+	var platforms []*CiBuildMatrixPlatform
+	err := impl.dbConnection.Model(&platforms).
+		Where("ci_build_matrix_id = ?", buildMatrixId).
+		Order("id ASC").
+		Select()
+	return platforms, err
+}
+
+func (impl *CiBuildMatrixRepositoryImpl) UpdatePlatform(platform *CiBuildMatrixPlatform, tx *pg.Tx) error {
+	return tx.Update(platform)
+}
@@ -38,7 +38,10 @@ type CiPipelineMaterial struct {
 	ScmVersion   string     `sql:"scm_version"` //gocd scm version
 	Active       bool       `sql:"active,notnull"`
 	Regex        string     `json:"regex"`
-	GitTag       string     `sql:"-"`
+	// IncludePaths and ExcludePaths are globs of files, commits changing no file they match do not trigger the pipeline
+	IncludePaths []string `sql:"include_paths"`
+	ExcludePaths []string `sql:"exclude_paths"`
+	GitTag       string   `sql:"-"`
 	CiPipeline   *CiPipeline
 	GitMaterial  *GitMaterial
 	sql.AuditLog
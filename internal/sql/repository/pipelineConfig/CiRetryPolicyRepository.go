@@ -0,0 +1,142 @@
+package pipelineConfig
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+const (
+	CiWorkflowRetryScheduled  = "SCHEDULED"
+	CiWorkflowRetryTriggering = "TRIGGERING"
+	CiWorkflowRetryTriggered  = "TRIGGERED"
+	CiWorkflowRetryFailed     = "FAILED"
+)
+
+// CiRetryPolicy decides which failed workflows of a ci pipeline are re-triggered and after how long
+type CiRetryPolicy struct {
+	tableName         struct{} `sql:"ci_retry_policy" pg:",discard_unknown_columns"`
+	Id                int      `sql:"id,pk"`
+	CiPipelineId      int      `sql:"ci_pipeline_id,notnull"`
+	MaxAttempts       int      `sql:"max_attempts,notnull"`
+	BackoffSeconds    int      `sql:"backoff_seconds,notnull"`
+	BackoffMultiplier float64  `sql:"backoff_multiplier,notnull"`
+	MaxBackoffSeconds int      `sql:"max_backoff_seconds,notnull"`
+	RetryOn           []string `sql:"retry_on"`
+	RetryOnExitCodes  []int    `sql:"retry_on_exit_codes" pg:",array"`
+	Active            bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// CiWorkflowRetry is the retry of a failed ci workflow, RefCiWorkflowId is the first attempt of the build which every
+// retry links to
+type CiWorkflowRetry struct {
+	tableName             struct{}  `sql:"ci_workflow_retry" pg:",discard_unknown_columns"`
+	Id                    int       `sql:"id,pk"`
+	CiWorkflowId          int       `sql:"ci_workflow_id,notnull"`
+	RefCiWorkflowId       int       `sql:"ref_ci_workflow_id,notnull"`
+	Attempt               int       `sql:"attempt,notnull"`
+	FailureReason         string    `sql:"failure_reason,notnull"`
+	RetryAt               time.Time `sql:"retry_at,notnull"`
+	Status                string    `sql:"status,notnull"`
+	TriggeredCiWorkflowId int       `sql:"triggered_ci_workflow_id"`
+	Message               string    `sql:"message"`
+	sql.AuditLog
+}
+
+type CiRetryPolicyRepository interface {
+	SavePolicy(policy *CiRetryPolicy) error
+	UpdatePolicy(policy *CiRetryPolicy) error
+	FindPolicyByCiPipelineId(ciPipelineId int) (*CiRetryPolicy, error)
+	// SaveRetry returns false if a retry already exists for the failed workflow
+	SaveRetry(retry *CiWorkflowRetry) (bool, error)
+	UpdateRetry(retry *CiWorkflowRetry) error
+	FindRetryByCiWorkflowId(ciWorkflowId int) (*CiWorkflowRetry, error)
+	FindRetriesByRefCiWorkflowId(refCiWorkflowId int) ([]*CiWorkflowRetry, error)
+	FindDueRetries(retryAt time.Time) ([]*CiWorkflowRetry, error)
+	// MarkRetryTriggering moves a scheduled retry to triggering, it returns false if some other instance already did
+	MarkRetryTriggering(retry *CiWorkflowRetry) (bool, error)
+}
+
+type CiRetryPolicyRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewCiRetryPolicyRepositoryImpl(dbConnection *pg.DB) *CiRetryPolicyRepositoryImpl {
+	return &CiRetryPolicyRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *CiRetryPolicyRepositoryImpl) SavePolicy(policy *CiRetryPolicy) error {
+	return impl.dbConnection.Insert(policy)
+}
+
+func (impl *CiRetryPolicyRepositoryImpl) UpdatePolicy(policy *CiRetryPolicy) error {
+	return impl.dbConnection.Update(policy)
+}
+
+func (impl *CiRetryPolicyRepositoryImpl) FindPolicyByCiPipelineId(ciPipelineId int) (*CiRetryPolicy, error) {
+	policy := &CiRetryPolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("ci_pipeline_id = ?", ciPipelineId).
+		Select()
+	return policy, err
+}
+
+func (impl *CiRetryPolicyRepositoryImpl) SaveRetry(retry *CiWorkflowRetry) (bool, error) {
+	res, err := impl.dbConnection.Model(retry).
+		OnConflict("(ci_workflow_id) DO NOTHING").
+		Insert()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+func (impl *CiRetryPolicyRepositoryImpl) UpdateRetry(retry *CiWorkflowRetry) error {
+	return impl.dbConnection.Update(retry)
+}
+
+func (impl *CiRetryPolicyRepositoryImpl) FindRetryByCiWorkflowId(ciWorkflowId int) (*CiWorkflowRetry, error) {
+	retry := &CiWorkflowRetry{}
+	err := impl.dbConnection.Model(retry).
+		Where("ci_workflow_id = ?", ciWorkflowId).
+		Select()
+	return retry, err
+}
+
+func (impl *CiRetryPolicyRepositoryImpl) FindRetriesByRefCiWorkflowId(refCiWorkflowId int) ([]*CiWorkflowRetry, error) {
+	var retries []*CiWorkflowRetry
+	err := impl.dbConnection.Model(&retries).
+		Where("ref_ci_workflow_id = ?", refCiWorkflowId).
+		Order("attempt ASC").
+		Select()
+	return retries, err
+}
+
+func (impl *CiRetryPolicyRepositoryImpl) FindDueRetries(retryAt time.Time) ([]*CiWorkflowRetry, error) {
+	var retries []*CiWorkflowRetry
+	err := impl.dbConnection.Model(&retries).
+		Where("status = ?", CiWorkflowRetryScheduled).
+		Where("retry_at <= ?", retryAt).
+		Order("retry_at ASC").
+		Select()
+	return retries, err
+}
+
+func (impl *CiRetryPolicyRepositoryImpl) MarkRetryTriggering(retry *CiWorkflowRetry) (bool, error) {
+	res, err := impl.dbConnection.Model(retry).
+		Set("status = ?", CiWorkflowRetryTriggering).
+		Set("updated_on = ?", time.Now()).
+		Where("id = ?", retry.Id).
+		Where("status = ?", CiWorkflowRetryScheduled).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	if res.RowsAffected() == 0 {
+		return false, nil
+	}
+	retry.Status = CiWorkflowRetryTriggering
+	return true, nil
+}
@@ -31,7 +31,11 @@ type CiWorkflowRepository interface {
 
 	SaveWorkFlow(wf *CiWorkflow) error
 	FindLastTriggeredWorkflow(pipelineId int) (*CiWorkflow, error)
+	// FindLastSucceededWorkflow returns the latest workflow of the pipeline which built successfully
+	FindLastSucceededWorkflow(pipelineId int) (*CiWorkflow, error)
 	UpdateWorkFlow(wf *CiWorkflow) error
+	// UpdateFailureReason updates only the failure reason of the workflow, leaving its status to the workflow updates
+	UpdateFailureReason(id int, failureReason string) error
 	FindByStatusesIn(activeStatuses []string) ([]*CiWorkflow, error)
 	FindByPipelineId(pipelineId int, offset int, size int) ([]WorkflowWithArtifact, error)
 	FindById(id int) (*CiWorkflow, error)
@@ -79,6 +83,8 @@ type CiWorkflow struct {
 	ExecutorType            WorkflowExecutorType `sql:"executor_type"` //awf, system
 	ImagePathReservationId  int                  `sql:"image_path_reservation_id"`
 	ImagePathReservationIds []int                `sql:"image_path_reservation_ids" pg:",array"`
+	PathFilterResult        *PathFilterResult    `sql:"path_filter_result"`
+	FailureReason           string               `sql:"failure_reason"`
 	CiPipeline              *CiPipeline
 }
 
@@ -118,6 +124,8 @@ type WorkflowWithArtifact struct {
 	ExecutorType            WorkflowExecutorType `json:"executor_type"` //awf, system
 	ImagePathReservationId  int                  `json:"image_path_reservation_id"`
 	ImagePathReservationIds []int                `json:"image_path_reservation_ids" pg:",array"`
+	PathFilterResult        *PathFilterResult    `json:"path_filter_result"`
+	FailureReason           string               `json:"failure_reason"`
 }
 
 type GitCommit struct {
@@ -133,6 +141,18 @@ type GitCommit struct {
 	CiConfigureSourceType  SourceType
 }
 
+// PathFilterResult is the outcome of matching the files changed since the last build up to the commit triggering a
+// workflow against the path filters of its material
+type PathFilterResult struct {
+	CiPipelineMaterialId int      `json:"ciPipelineMaterialId"`
+	Commit               string   `json:"commit"`
+	IncludePaths         []string `json:"includePaths"`
+	ExcludePaths         []string `json:"excludePaths"`
+	ChangedFileCount     int      `json:"changedFileCount"`
+	MatchedFiles         []string `json:"matchedFiles"`
+	Skipped              bool     `json:"skipped"`
+}
+
 type WebhookData struct {
 	Id              int               `json:"id"`
 	EventActionType string            `json:"eventActionType"`
@@ -178,6 +198,17 @@ func (impl *CiWorkflowRepositoryImpl) FindLastTriggeredWorkflow(pipelineId int)
 	return workflow, err
 }
 
+func (impl *CiWorkflowRepositoryImpl) FindLastSucceededWorkflow(pipelineId int) (*CiWorkflow, error) {
+	workflow := &CiWorkflow{}
+	err := impl.dbConnection.Model(workflow).
+		Where("ci_pipeline_id = ?", pipelineId).
+		Where("status = ?", WorkflowSucceeded).
+		Order("started_on DESC").
+		Limit(1).
+		Select()
+	return workflow, err
+}
+
 func (impl *CiWorkflowRepositoryImpl) FindByStatusesIn(activeStatuses []string) ([]*CiWorkflow, error) {
 	var ciWorkFlows []*CiWorkflow
 	err := impl.dbConnection.Model(&ciWorkFlows).
@@ -268,6 +299,14 @@ func (impl *CiWorkflowRepositoryImpl) UpdateWorkFlow(wf *CiWorkflow) error {
 	return err
 }
 
+func (impl *CiWorkflowRepositoryImpl) UpdateFailureReason(id int, failureReason string) error {
+	_, err := impl.dbConnection.Model((*CiWorkflow)(nil)).
+		Set("failure_reason = ?", failureReason).
+		Where("id = ?", id).
+		Update()
+	return err
+}
+
 func (impl *CiWorkflowRepositoryImpl) FindLastTriggeredWorkflowByCiIds(pipelineId []int) (ciWorkflow []*CiWorkflow, err error) {
 	err = impl.dbConnection.Model(&ciWorkflow).
 		Column("ci_workflow.*", "CiPipeline").
@@ -341,7 +380,7 @@ func (impl *CiWorkflowRepositoryImpl) FIndCiWorkflowStatusesByAppId(appId int) (
 
 	ciworkflowStatuses := make([]*CiWorkflowStatus, 0)
 
-	query := "SELECT cw1.ci_pipeline_id,cw1.status AS ci_status,cw1.blob_storage_enabled AS storage_configured " +
+	query := "SELECT cw1.ci_pipeline_id,cw1.status AS ci_status,cw1.blob_storage_enabled AS storage_configured,cw1.id AS ci_workflow_id " +
 		" FROM ci_workflow cw1 " +
 		" INNER JOIN " +
 		" (WITH cp AS (SELECT id, parent_ci_pipeline FROM ci_pipeline WHERE app_id = ? AND deleted=false ) " +
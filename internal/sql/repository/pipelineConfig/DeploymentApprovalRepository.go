@@ -0,0 +1,116 @@
+package pipelineConfig
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ApprovalUserResponse int
+
+const (
+	APPROVED ApprovalUserResponse = iota
+	REJECTED
+)
+
+type DeploymentApprovalRequest struct {
+	tableName                   struct{} `sql:"deployment_approval_request" pg:",discard_unknown_columns"`
+	Id                          int      `sql:"id,pk"`
+	PipelineId                  int      `sql:"pipeline_id"`
+	ArtifactId                  int      `sql:"ci_artifact_id"`
+	Active                      bool     `sql:"active,notnull"`
+	ArtifactDeploymentTriggered bool     `sql:"artifact_deployment_triggered,notnull"`
+	sql.AuditLog
+}
+
+type DeploymentApprovalUserData struct {
+	tableName         struct{}             `sql:"deployment_approval_user_data" pg:",discard_unknown_columns"`
+	Id                int                  `sql:"id,pk"`
+	ApprovalRequestId int                  `sql:"approval_request_id"`
+	UserId            int32                `sql:"user_id"`
+	UserResponse      ApprovalUserResponse `sql:"user_response,notnull"`
+	Comments          string               `sql:"comments"`
+	sql.AuditLog
+}
+
+type DeploymentApprovalRepository interface {
+	Save(approvalRequest *DeploymentApprovalRequest) error
+	Update(approvalRequest *DeploymentApprovalRequest) error
+	FindById(id int) (*DeploymentApprovalRequest, error)
+	FindByIds(ids []int) ([]*DeploymentApprovalRequest, error)
+	FindActiveByPipelineIdAndArtifactId(pipelineId int, artifactId int) (*DeploymentApprovalRequest, error)
+	SaveUserData(userData *DeploymentApprovalUserData) error
+	UpdateUserData(userData *DeploymentApprovalUserData) error
+	FindUserDataByRequestIds(approvalRequestIds []int) ([]*DeploymentApprovalUserData, error)
+}
+
+type DeploymentApprovalRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewDeploymentApprovalRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *DeploymentApprovalRepositoryImpl {
+	return &DeploymentApprovalRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) Save(approvalRequest *DeploymentApprovalRequest) error {
+	return impl.dbConnection.Insert(approvalRequest)
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) Update(approvalRequest *DeploymentApprovalRequest) error {
+	return impl.dbConnection.Update(approvalRequest)
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) FindById(id int) (*DeploymentApprovalRequest, error) {
+	approvalRequest := &DeploymentApprovalRequest{}
+	err := impl.dbConnection.Model(approvalRequest).
+		Where("id = ?", id).
+		Select()
+	return approvalRequest, err
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) FindByIds(ids []int) ([]*DeploymentApprovalRequest, error) {
+	var approvalRequests []*DeploymentApprovalRequest
+	if len(ids) == 0 {
+		return approvalRequests, nil
+	}
+	err := impl.dbConnection.Model(&approvalRequests).
+		Where("id in (?)", pg.In(ids)).
+		Select()
+	return approvalRequests, err
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) FindActiveByPipelineIdAndArtifactId(pipelineId int, artifactId int) (*DeploymentApprovalRequest, error) {
+	approvalRequest := &DeploymentApprovalRequest{}
+	err := impl.dbConnection.Model(approvalRequest).
+		Where("pipeline_id = ?", pipelineId).
+		Where("ci_artifact_id = ?", artifactId).
+		Where("active = ?", true).
+		Order("id DESC").
+		Limit(1).
+		Select()
+	return approvalRequest, err
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) SaveUserData(userData *DeploymentApprovalUserData) error {
+	return impl.dbConnection.Insert(userData)
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) UpdateUserData(userData *DeploymentApprovalUserData) error {
+	return impl.dbConnection.Update(userData)
+}
+
+func (impl *DeploymentApprovalRepositoryImpl) FindUserDataByRequestIds(approvalRequestIds []int) ([]*DeploymentApprovalUserData, error) {
+	var userData []*DeploymentApprovalUserData
+	if len(approvalRequestIds) == 0 {
+		return userData, nil
+	}
+	err := impl.dbConnection.Model(&userData).
+		Where("approval_request_id in (?)", pg.In(approvalRequestIds)).
+		Order("id ASC").
+		Select()
+	return userData, err
+}
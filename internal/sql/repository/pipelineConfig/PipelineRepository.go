@@ -18,6 +18,7 @@
 package pipelineConfig
 
 import (
+	"encoding/json"
 	"github.com/devtron-labs/common-lib/utils/k8s/health"
 	"github.com/devtron-labs/devtron/api/bean"
 	"github.com/devtron-labs/devtron/internal/sql/models"
@@ -61,10 +62,49 @@ type Pipeline struct {
 	DeploymentAppType             string      `sql:"deployment_app_type,notnull"` //helm, acd
 	DeploymentAppName             string      `sql:"deployment_app_name"`
 	DeploymentAppDeleteRequest    bool        `sql:"deployment_app_delete_request,notnull"`
+	UserApprovalConfig            string      `sql:"user_approval_config"`
+	AutoRollbackConfig            string      `sql:"auto_rollback_config"`
 	Environment                   repository.Environment
 	sql.AuditLog
 }
 
+// UserApprovalConfig is stored as json in pipeline.user_approval_config, deployments need RequiredCount approvals when set
+type UserApprovalConfig struct {
+	RequiredCount   int `json:"requiredCount" validate:"number,min=1,max=6"`
+	ExpiryInMinutes int `json:"expiryInMinutes" validate:"number,min=0"`
+}
+
+func (pipeline *Pipeline) GetUserApprovalConfig() (*UserApprovalConfig, error) {
+	if len(pipeline.UserApprovalConfig) == 0 {
+		return nil, nil
+	}
+	approvalConfig := &UserApprovalConfig{}
+	err := json.Unmarshal([]byte(pipeline.UserApprovalConfig), approvalConfig)
+	if err != nil {
+		return nil, err
+	}
+	return approvalConfig, nil
+}
+
+// AutoRollbackConfig is stored as json in pipeline.auto_rollback_config, when enabled a deployment which fails or degrades
+// within ObservationWindowInMinutes of being triggered is rolled back to the last healthy release
+type AutoRollbackConfig struct {
+	Enabled                    bool `json:"enabled"`
+	ObservationWindowInMinutes int  `json:"observationWindowInMinutes" validate:"number,min=0,max=1440"`
+}
+
+func (pipeline *Pipeline) GetAutoRollbackConfig() (*AutoRollbackConfig, error) {
+	if len(pipeline.AutoRollbackConfig) == 0 {
+		return nil, nil
+	}
+	autoRollbackConfig := &AutoRollbackConfig{}
+	err := json.Unmarshal([]byte(pipeline.AutoRollbackConfig), autoRollbackConfig)
+	if err != nil {
+		return nil, err
+	}
+	return autoRollbackConfig, nil
+}
+
 type PipelineRepository interface {
 	Save(pipeline []*Pipeline, tx *pg.Tx) error
 	Update(pipeline *Pipeline, tx *pg.Tx) error
@@ -12,25 +12,36 @@ type TimelineStatus = string
 var TimelineStatusDescription string
 
 const (
-	TIMELINE_STATUS_DEPLOYMENT_INITIATED   TimelineStatus = "DEPLOYMENT_INITIATED"
-	TIMELINE_STATUS_GIT_COMMIT             TimelineStatus = "GIT_COMMIT"
-	TIMELINE_STATUS_GIT_COMMIT_FAILED      TimelineStatus = "GIT_COMMIT_FAILED"
-	TIMELINE_STATUS_ARGOCD_SYNC_INITIATED  TimelineStatus = "ARGOCD_SYNC_INITIATED"
-	TIMELINE_STATUS_ARGOCD_SYNC_COMPLETED  TimelineStatus = "ARGOCD_SYNC_COMPLETED"
-	TIMELINE_STATUS_KUBECTL_APPLY_STARTED  TimelineStatus = "KUBECTL_APPLY_STARTED"
-	TIMELINE_STATUS_KUBECTL_APPLY_SYNCED   TimelineStatus = "KUBECTL_APPLY_SYNCED"
-	TIMELINE_STATUS_APP_HEALTHY            TimelineStatus = "HEALTHY"
-	TIMELINE_STATUS_DEPLOYMENT_FAILED      TimelineStatus = "FAILED"
-	TIMELINE_STATUS_FETCH_TIMED_OUT        TimelineStatus = "TIMED_OUT"
-	TIMELINE_STATUS_UNABLE_TO_FETCH_STATUS TimelineStatus = "UNABLE_TO_FETCH_STATUS"
-	TIMELINE_STATUS_DEPLOYMENT_SUPERSEDED  TimelineStatus = "DEPLOYMENT_SUPERSEDED"
-	TIMELINE_STATUS_MANIFEST_GENERATED     TimelineStatus = "MANIFEST_GENERATED"
+	TIMELINE_STATUS_DEPLOYMENT_INITIATED                TimelineStatus = "DEPLOYMENT_INITIATED"
+	TIMELINE_STATUS_GIT_COMMIT                          TimelineStatus = "GIT_COMMIT"
+	TIMELINE_STATUS_GIT_COMMIT_FAILED                   TimelineStatus = "GIT_COMMIT_FAILED"
+	TIMELINE_STATUS_ARGOCD_SYNC_INITIATED               TimelineStatus = "ARGOCD_SYNC_INITIATED"
+	TIMELINE_STATUS_ARGOCD_SYNC_COMPLETED               TimelineStatus = "ARGOCD_SYNC_COMPLETED"
+	TIMELINE_STATUS_KUBECTL_APPLY_STARTED               TimelineStatus = "KUBECTL_APPLY_STARTED"
+	TIMELINE_STATUS_KUBECTL_APPLY_SYNCED                TimelineStatus = "KUBECTL_APPLY_SYNCED"
+	TIMELINE_STATUS_APP_HEALTHY                         TimelineStatus = "HEALTHY"
+	TIMELINE_STATUS_DEPLOYMENT_FAILED                   TimelineStatus = "FAILED"
+	TIMELINE_STATUS_FETCH_TIMED_OUT                     TimelineStatus = "TIMED_OUT"
+	TIMELINE_STATUS_UNABLE_TO_FETCH_STATUS              TimelineStatus = "UNABLE_TO_FETCH_STATUS"
+	TIMELINE_STATUS_DEPLOYMENT_SUPERSEDED               TimelineStatus = "DEPLOYMENT_SUPERSEDED"
+	TIMELINE_STATUS_MANIFEST_GENERATED                  TimelineStatus = "MANIFEST_GENERATED"
+	TIMELINE_STATUS_DEPLOYMENT_BLOCKED                  TimelineStatus = "DEPLOYMENT_BLOCKED"
+	TIMELINE_STATUS_WINDOW_OVERRIDDEN                   TimelineStatus = "DEPLOYMENT_WINDOW_OVERRIDDEN"
+	TIMELINE_STATUS_AUTO_ROLLBACK_TRIGGERED             TimelineStatus = "AUTO_ROLLBACK_TRIGGERED"
+	TIMELINE_STATUS_AUTO_ROLLBACK_FAILED                TimelineStatus = "AUTO_ROLLBACK_FAILED"
+	TIMELINE_STATUS_DEPLOYMENT_QUEUED                   TimelineStatus = "DEPLOYMENT_QUEUED"
+	TIMELINE_STATUS_DEPLOYMENT_DEQUEUED                 TimelineStatus = "DEPLOYMENT_DEQUEUED"
+	TIMELINE_STATUS_CANARY_ANALYSIS_PASSED              TimelineStatus = "CANARY_ANALYSIS_PASSED"
+	TIMELINE_STATUS_CANARY_ANALYSIS_FAILED              TimelineStatus = "CANARY_ANALYSIS_FAILED"
+	TIMELINE_STATUS_IMAGE_SIGNATURE_VERIFIED            TimelineStatus = "IMAGE_SIGNATURE_VERIFIED"
+	TIMELINE_STATUS_IMAGE_SIGNATURE_VERIFICATION_FAILED TimelineStatus = "IMAGE_SIGNATURE_VERIFICATION_FAILED"
 )
 
 const (
 	TIMELINE_DESCRIPTION_DEPLOYMENT_INITIATED string = "Deployment initiated successfully."
 	TIMELINE_DESCRIPTION_VULNERABLE_IMAGE     string = "Deployment failed: Vulnerability policy violated."
 	TIMELINE_DESCRIPTION_MANIFEST_GENERATED   string = "HELM_PACKAGE_GENERATED"
+	TIMELINE_DESCRIPTION_DEPLOYMENT_DEQUEUED  string = "Deployment released from queue."
 )
 
 type PipelineStatusTimelineRepository interface {
@@ -204,7 +215,7 @@ func (impl *PipelineStatusTimelineRepositoryImpl) FetchLatestTimelineByWfrId(wfr
 }
 
 func (impl *PipelineStatusTimelineRepositoryImpl) CheckIfTerminalStatusTimelinePresentByWfrId(wfrId int) (bool, error) {
-	terminalStatus := []string{string(TIMELINE_STATUS_APP_HEALTHY), string(TIMELINE_STATUS_DEPLOYMENT_FAILED), string(TIMELINE_STATUS_GIT_COMMIT_FAILED), string(TIMELINE_STATUS_DEPLOYMENT_SUPERSEDED)}
+	terminalStatus := []string{string(TIMELINE_STATUS_APP_HEALTHY), string(TIMELINE_STATUS_DEPLOYMENT_FAILED), string(TIMELINE_STATUS_GIT_COMMIT_FAILED), string(TIMELINE_STATUS_DEPLOYMENT_SUPERSEDED), string(TIMELINE_STATUS_DEPLOYMENT_BLOCKED)}
 	timeline := &PipelineStatusTimeline{}
 	exists, err := impl.dbConnection.Model(timeline).
 		Where("cd_workflow_runner_id = ?", wfrId).
@@ -0,0 +1,136 @@
+package pipelineConfig
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ScheduledDeploymentStatus string
+
+const (
+	SCHEDULED_DEPLOYMENT_STATUS_SCHEDULED   ScheduledDeploymentStatus = "SCHEDULED"
+	SCHEDULED_DEPLOYMENT_STATUS_IN_PROGRESS ScheduledDeploymentStatus = "IN_PROGRESS"
+	SCHEDULED_DEPLOYMENT_STATUS_TRIGGERED   ScheduledDeploymentStatus = "TRIGGERED"
+	SCHEDULED_DEPLOYMENT_STATUS_FAILED      ScheduledDeploymentStatus = "FAILED"
+	SCHEDULED_DEPLOYMENT_STATUS_CANCELLED   ScheduledDeploymentStatus = "CANCELLED"
+)
+
+type ScheduledDeployment struct {
+	tableName              struct{}                  `sql:"scheduled_deployment" pg:",discard_unknown_columns"`
+	Id                     int                       `sql:"id,pk"`
+	PipelineId             int                       `sql:"pipeline_id,notnull"`
+	CiArtifactId           int                       `sql:"ci_artifact_id,notnull"`
+	ScheduledOn            time.Time                 `sql:"scheduled_on,notnull"`
+	InNextDeploymentWindow bool                      `sql:"in_next_deployment_window,notnull"`
+	Status                 ScheduledDeploymentStatus `sql:"status,notnull"`
+	CdWorkflowRunnerId     int                       `sql:"cd_workflow_runner_id"`
+	Message                string                    `sql:"message"`
+	ExecutedOn             time.Time                 `sql:"executed_on"`
+	sql.AuditLog
+}
+
+type ScheduledDeploymentRepository interface {
+	Save(scheduledDeployment *ScheduledDeployment) error
+	Update(scheduledDeployment *ScheduledDeployment) error
+	FindById(id int) (*ScheduledDeployment, error)
+	FindByPipelineId(pipelineId int, limit int) ([]*ScheduledDeployment, error)
+	FindPendingByPipelineIds(pipelineIds []int) ([]*ScheduledDeployment, error)
+	FindDueForExecution(at time.Time) ([]*ScheduledDeployment, error)
+	// MarkInProgress moves a scheduled deployment from SCHEDULED to IN_PROGRESS, it returns false if the deployment was
+	// already picked up (by another instance) or cancelled in the meantime
+	MarkInProgress(id int) (bool, error)
+	// MarkStaleInProgressFailed fails the deployments which are in progress since before updatedBefore, it returns the
+	// number of deployments failed
+	MarkStaleInProgressFailed(updatedBefore time.Time, message string) (int, error)
+}
+
+type ScheduledDeploymentRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewScheduledDeploymentRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *ScheduledDeploymentRepositoryImpl {
+	return &ScheduledDeploymentRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl *ScheduledDeploymentRepositoryImpl) Save(scheduledDeployment *ScheduledDeployment) error {
+	return impl.dbConnection.Insert(scheduledDeployment)
+}
+
+func (impl *ScheduledDeploymentRepositoryImpl) Update(scheduledDeployment *ScheduledDeployment) error {
+	return impl.dbConnection.Update(scheduledDeployment)
+}
+
+func (impl *ScheduledDeploymentRepositoryImpl) FindById(id int) (*ScheduledDeployment, error) {
+	scheduledDeployment := &ScheduledDeployment{}
+	err := impl.dbConnection.Model(scheduledDeployment).
+		Where("id = ?", id).
+		Select()
+	return scheduledDeployment, err
+}
+
+func (impl *ScheduledDeploymentRepositoryImpl) FindByPipelineId(pipelineId int, limit int) ([]*ScheduledDeployment, error) {
+	var scheduledDeployments []*ScheduledDeployment
+	err := impl.dbConnection.Model(&scheduledDeployments).
+		Where("pipeline_id = ?", pipelineId).
+		Order("scheduled_on DESC").
+		Limit(limit).
+		Select()
+	return scheduledDeployments, err
+}
+
+func (impl *ScheduledDeploymentRepositoryImpl) FindPendingByPipelineIds(pipelineIds []int) ([]*ScheduledDeployment, error) {
+	var scheduledDeployments []*ScheduledDeployment
+	if len(pipelineIds) == 0 {
+		return scheduledDeployments, nil
+	}
+	err := impl.dbConnection.Model(&scheduledDeployments).
+		Where("pipeline_id in (?)", pg.In(pipelineIds)).
+		Where("status = ?", SCHEDULED_DEPLOYMENT_STATUS_SCHEDULED).
+		Order("scheduled_on ASC").
+		Select()
+	return scheduledDeployments, err
+}
+
+func (impl *ScheduledDeploymentRepositoryImpl) FindDueForExecution(at time.Time) ([]*ScheduledDeployment, error) {
+	var scheduledDeployments []*ScheduledDeployment
+	err := impl.dbConnection.Model(&scheduledDeployments).
+		Where("status = ?", SCHEDULED_DEPLOYMENT_STATUS_SCHEDULED).
+		Where("scheduled_on <= ?", at).
+		Order("scheduled_on ASC").
+		Select()
+	return scheduledDeployments, err
+}
+
+func (impl *ScheduledDeploymentRepositoryImpl) MarkInProgress(id int) (bool, error) {
+	res, err := impl.dbConnection.Model(&ScheduledDeployment{}).
+		Set("status = ?", SCHEDULED_DEPLOYMENT_STATUS_IN_PROGRESS).
+		Set("updated_on = ?", time.Now()).
+		Where("id = ?", id).
+		Where("status = ?", SCHEDULED_DEPLOYMENT_STATUS_SCHEDULED).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl *ScheduledDeploymentRepositoryImpl) MarkStaleInProgressFailed(updatedBefore time.Time, message string) (int, error) {
+	res, err := impl.dbConnection.Model(&ScheduledDeployment{}).
+		Set("status = ?", SCHEDULED_DEPLOYMENT_STATUS_FAILED).
+		Set("message = ?", message).
+		Set("updated_on = ?", time.Now()).
+		Where("status = ?", SCHEDULED_DEPLOYMENT_STATUS_IN_PROGRESS).
+		Where("updated_on < ?", updatedBefore).
+		Update()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
@@ -417,6 +417,96 @@ func (_m *CdWorkflowRepository) FindPreviousCdWfRunnerByStatus(pipelineId int, c
 	return r0, r1
 }
 
+// FindLastSucceededDeploymentRunnerBefore provides a mock function with given fields: pipelineId, currentWFRunnerId
+func (_m *CdWorkflowRepository) FindLastSucceededDeploymentRunnerBefore(pipelineId int, currentWFRunnerId int) (*pipelineConfig.CdWorkflowRunner, error) {
+	ret := _m.Called(pipelineId, currentWFRunnerId)
+
+	var r0 *pipelineConfig.CdWorkflowRunner
+	if rf, ok := ret.Get(0).(func(int, int) *pipelineConfig.CdWorkflowRunner); ok {
+		r0 = rf(pipelineId, currentWFRunnerId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pipelineConfig.CdWorkflowRunner)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(pipelineId, currentWFRunnerId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindLatestSucceededDeploymentRunner provides a mock function with given fields: pipelineId
+func (_m *CdWorkflowRepository) FindLatestSucceededDeploymentRunner(pipelineId int) (*pipelineConfig.CdWorkflowRunner, error) {
+	ret := _m.Called(pipelineId)
+
+	var r0 *pipelineConfig.CdWorkflowRunner
+	if rf, ok := ret.Get(0).(func(int) *pipelineConfig.CdWorkflowRunner); ok {
+		r0 = rf(pipelineId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pipelineConfig.CdWorkflowRunner)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(pipelineId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkAutoRollbackAttempted provides a mock function with given fields: wfrId
+func (_m *CdWorkflowRepository) MarkAutoRollbackAttempted(wfrId int) (bool, error) {
+	ret := _m.Called(wfrId)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(int) bool); ok {
+		r0 = rf(wfrId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(wfrId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSucceededRunnersByPipelineIdAndArtifactIds provides a mock function with given fields: pipelineId, artifactIds, runnerType
+func (_m *CdWorkflowRepository) FindSucceededRunnersByPipelineIdAndArtifactIds(pipelineId int, artifactIds []int, runnerType bean.WorkflowType) ([]*pipelineConfig.CdWorkflowRunner, error) {
+	ret := _m.Called(pipelineId, artifactIds, runnerType)
+
+	var r0 []*pipelineConfig.CdWorkflowRunner
+	if rf, ok := ret.Get(0).(func(int, []int, bean.WorkflowType) []*pipelineConfig.CdWorkflowRunner); ok {
+		r0 = rf(pipelineId, artifactIds, runnerType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*pipelineConfig.CdWorkflowRunner)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, []int, bean.WorkflowType) error); ok {
+		r1 = rf(pipelineId, artifactIds, runnerType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindWorkflowRunnerByCdWorkflowId provides a mock function with given fields: wfIds
 func (_m *CdWorkflowRepository) FindWorkflowRunnerByCdWorkflowId(wfIds []int) ([]*pipelineConfig.CdWorkflowRunner, error) {
 	ret := _m.Called(wfIds)
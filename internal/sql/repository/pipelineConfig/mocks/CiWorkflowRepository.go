@@ -208,6 +208,32 @@ func (_m *CiWorkflowRepository) FindConfigByPipelineId(pipelineId int) (*pipelin
 	return r0, r1
 }
 
+// FindLastSucceededWorkflow provides a mock function with given fields: pipelineId
+func (_m *CiWorkflowRepository) FindLastSucceededWorkflow(pipelineId int) (*pipelineConfig.CiWorkflow, error) {
+	ret := _m.Called(pipelineId)
+
+	var r0 *pipelineConfig.CiWorkflow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) (*pipelineConfig.CiWorkflow, error)); ok {
+		return rf(pipelineId)
+	}
+	if rf, ok := ret.Get(0).(func(int) *pipelineConfig.CiWorkflow); ok {
+		r0 = rf(pipelineId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pipelineConfig.CiWorkflow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(pipelineId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindLastTriggeredWorkflow provides a mock function with given fields: pipelineId
 func (_m *CiWorkflowRepository) FindLastTriggeredWorkflow(pipelineId int) (*pipelineConfig.CiWorkflow, error) {
 	ret := _m.Called(pipelineId)
@@ -340,6 +366,20 @@ func (_m *CiWorkflowRepository) SaveWorkFlowConfig(config *pipelineConfig.CiWork
 	return r0
 }
 
+// UpdateFailureReason provides a mock function with given fields: id, failureReason
+func (_m *CiWorkflowRepository) UpdateFailureReason(id int, failureReason string) error {
+	ret := _m.Called(id, failureReason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, string) error); ok {
+		r0 = rf(id, failureReason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateWorkFlow provides a mock function with given fields: wf
 func (_m *CiWorkflowRepository) UpdateWorkFlow(wf *pipelineConfig.CiWorkflow) error {
 	ret := _m.Called(wf)
@@ -17,6 +17,10 @@
 
 package util
 
+import "sort"
+
+// TopoSort returns the vertices of the graph ordered so that every vertex comes before its children, vertices free to
+// come at the same point are ordered from the smallest one so that the order does not depend on map iteration
 func TopoSort(graph map[int][]int) []int {
 	var sorted []int
 	inDegree := map[int]int{}
@@ -32,7 +36,7 @@ func TopoSort(graph map[int][]int) []int {
 		}
 	}
 
-	// 02. Collect all vertices with indegree==0 onto a stack;
+	// 02. Collect all vertices with indegree==0 onto a stack, sorted from the largest so that the smallest is popped first;
 	var stack []int
 	for rule, value := range inDegree {
 		if value == 0 {
@@ -40,6 +44,7 @@ func TopoSort(graph map[int][]int) []int {
 			inDegree[rule] = -1
 		}
 	}
+	sort.Sort(sort.Reverse(sort.IntSlice(stack)))
 
 	// 03. While zero-degree-stack is not empty:
 	for len(stack) > 0 {
@@ -55,6 +60,7 @@ func TopoSort(graph map[int][]int) []int {
 				inDegree[child] = -1
 			}
 		}
+		sort.Sort(sort.Reverse(sort.IntSlice(stack)))
 
 		// 03.03. Append to the sorted list.
 		sorted = append(sorted, node)
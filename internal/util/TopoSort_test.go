@@ -36,6 +36,17 @@ func TestSort(t *testing.T) {
 			},
 			want: []int{1, 3, 2},
 		},
+		{name: "ties broken by the smallest vertex",
+			args: map[int][]int{
+				4: {5},
+				3: {5},
+				1: {2},
+				2: {},
+				5: {},
+				6: {},
+			},
+			want: []int{1, 2, 3, 4, 5, 6},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
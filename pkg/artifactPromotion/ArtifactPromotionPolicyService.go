@@ -0,0 +1,352 @@
+package artifactPromotion
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/artifactPromotion/repository"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ArtifactPromotionPolicyService interface {
+	CreatePolicy(request *ArtifactPromotionPolicyDto) (*ArtifactPromotionPolicyDto, error)
+	UpdatePolicy(request *ArtifactPromotionPolicyDto) (*ArtifactPromotionPolicyDto, error)
+	DeletePolicy(id int, userId int32) error
+	GetPolicyById(id int) (*ArtifactPromotionPolicyDto, error)
+	GetAllPolicies() ([]*ArtifactPromotionPolicyDto, error)
+	// EvaluatePromotionPolicies evaluates the policies of the environment of pipeline for every artifact, artifacts are
+	// absent from the result if no policy is configured for the environment
+	EvaluatePromotionPolicies(pipeline *pipelineConfig.Pipeline, artifactIds []int, at time.Time) (map[int]*PromotionPolicyEvaluation, error)
+	// ValidatePromotionForDeployment returns a forbidden ApiError if artifact is not allowed to be deployed on pipeline
+	ValidatePromotionForDeployment(pipeline *pipelineConfig.Pipeline, artifactId int) error
+}
+
+type ArtifactPromotionPolicyServiceImpl struct {
+	logger                            *zap.SugaredLogger
+	artifactPromotionPolicyRepository repository.ArtifactPromotionPolicyRepository
+	pipelineRepository                pipelineConfig.PipelineRepository
+	cdWorkflowRepository              pipelineConfig.CdWorkflowRepository
+	environmentRepository             repository2.EnvironmentRepository
+}
+
+func NewArtifactPromotionPolicyServiceImpl(logger *zap.SugaredLogger,
+	artifactPromotionPolicyRepository repository.ArtifactPromotionPolicyRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	environmentRepository repository2.EnvironmentRepository) *ArtifactPromotionPolicyServiceImpl {
+	return &ArtifactPromotionPolicyServiceImpl{
+		logger:                            logger,
+		artifactPromotionPolicyRepository: artifactPromotionPolicyRepository,
+		pipelineRepository:                pipelineRepository,
+		cdWorkflowRepository:              cdWorkflowRepository,
+		environmentRepository:             environmentRepository,
+	}
+}
+
+// sourceDeploymentState is what is known about an artifact on the source environment of a policy
+type sourceDeploymentState struct {
+	isPipelineFound bool
+	// deployedOn is the time of the latest successful deployment of the artifact, zero if it was never deployed successfully
+	deployedOn time.Time
+	// isReplaced is true when another artifact has been deployed successfully on the source environment after the
+	// artifact, its soak time is void since it no longer runs there
+	isReplaced     bool
+	isPostCdPassed bool
+}
+
+func (impl *ArtifactPromotionPolicyServiceImpl) CreatePolicy(request *ArtifactPromotionPolicyDto) (*ArtifactPromotionPolicyDto, error) {
+	err := impl.validatePolicy(request)
+	if err != nil {
+		impl.logger.Errorw("invalid artifact promotion policy", "request", request, "err", err)
+		return nil, err
+	}
+	policy := &repository.ArtifactPromotionPolicy{Active: true}
+	adaptDtoToModel(request, policy)
+	policy.AuditLog = sql.NewDefaultAuditLog(request.UserId)
+	err = impl.artifactPromotionPolicyRepository.Save(policy)
+	if err != nil {
+		impl.logger.Errorw("error in saving artifact promotion policy", "policy", policy, "err", err)
+		return nil, err
+	}
+	request.Id = policy.Id
+	return request, nil
+}
+
+func (impl *ArtifactPromotionPolicyServiceImpl) UpdatePolicy(request *ArtifactPromotionPolicyDto) (*ArtifactPromotionPolicyDto, error) {
+	err := impl.validatePolicy(request)
+	if err != nil {
+		impl.logger.Errorw("invalid artifact promotion policy", "request", request, "err", err)
+		return nil, err
+	}
+	policy, err := impl.artifactPromotionPolicyRepository.FindById(request.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact promotion policy", "id", request.Id, "err", err)
+		return nil, err
+	}
+	adaptDtoToModel(request, policy)
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = request.UserId
+	err = impl.artifactPromotionPolicyRepository.Update(policy)
+	if err != nil {
+		impl.logger.Errorw("error in updating artifact promotion policy", "policy", policy, "err", err)
+		return nil, err
+	}
+	return request, nil
+}
+
+func (impl *ArtifactPromotionPolicyServiceImpl) DeletePolicy(id int, userId int32) error {
+	policy, err := impl.artifactPromotionPolicyRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact promotion policy", "id", id, "err", err)
+		return err
+	}
+	policy.Active = false
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = userId
+	err = impl.artifactPromotionPolicyRepository.Update(policy)
+	if err != nil {
+		impl.logger.Errorw("error in deleting artifact promotion policy", "id", id, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *ArtifactPromotionPolicyServiceImpl) GetPolicyById(id int) (*ArtifactPromotionPolicyDto, error) {
+	policy, err := impl.artifactPromotionPolicyRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact promotion policy", "id", id, "err", err)
+		return nil, err
+	}
+	return adaptModelToDto(policy), nil
+}
+
+func (impl *ArtifactPromotionPolicyServiceImpl) GetAllPolicies() ([]*ArtifactPromotionPolicyDto, error) {
+	policies, err := impl.artifactPromotionPolicyRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact promotion policies", "err", err)
+		return nil, err
+	}
+	result := make([]*ArtifactPromotionPolicyDto, 0, len(policies))
+	for _, policy := range policies {
+		result = append(result, adaptModelToDto(policy))
+	}
+	return result, nil
+}
+
+func (impl *ArtifactPromotionPolicyServiceImpl) EvaluatePromotionPolicies(pipeline *pipelineConfig.Pipeline, artifactIds []int, at time.Time) (map[int]*PromotionPolicyEvaluation, error) {
+	result := make(map[int]*PromotionPolicyEvaluation)
+	if len(artifactIds) == 0 {
+		return result, nil
+	}
+	policies, err := impl.artifactPromotionPolicyRepository.FindActiveByTargetEnvironmentId(pipeline.EnvironmentId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching artifact promotion policies", "envId", pipeline.EnvironmentId, "err", err)
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return result, nil
+	}
+	ruleResults := make(map[int][]*PromotionRuleResult, len(artifactIds))
+	for _, policy := range policies {
+		sourceEnvName, states, err := impl.getSourceDeploymentStates(pipeline.AppId, policy.SourceEnvironmentId, artifactIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, artifactId := range artifactIds {
+			ruleResults[artifactId] = append(ruleResults[artifactId], evaluatePolicy(policy, sourceEnvName, states[artifactId], at)...)
+		}
+	}
+	for _, artifactId := range artifactIds {
+		result[artifactId] = buildEvaluation(ruleResults[artifactId])
+	}
+	return result, nil
+}
+
+func (impl *ArtifactPromotionPolicyServiceImpl) ValidatePromotionForDeployment(pipeline *pipelineConfig.Pipeline, artifactId int) error {
+	evaluations, err := impl.EvaluatePromotionPolicies(pipeline, []int{artifactId}, time.Now())
+	if err != nil {
+		return err
+	}
+	if evaluation, ok := evaluations[artifactId]; ok && !evaluation.IsAllowed {
+		impl.logger.Infow("artifact not allowed to be promoted", "pipelineId", pipeline.Id, "artifactId", artifactId, "reason", evaluation.Reason)
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusForbidden,
+			InternalMessage: evaluation.Reason,
+			UserMessage:     evaluation.Reason,
+		}
+	}
+	return nil
+}
+
+// getSourceDeploymentStates finds the deployment state of the artifacts on the pipeline of the app in the source environment
+func (impl *ArtifactPromotionPolicyServiceImpl) getSourceDeploymentStates(appId int, sourceEnvId int, artifactIds []int) (string, map[int]sourceDeploymentState, error) {
+	states := make(map[int]sourceDeploymentState, len(artifactIds))
+	sourceEnv, err := impl.environmentRepository.FindById(sourceEnvId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching source environment", "envId", sourceEnvId, "err", err)
+		return "", nil, err
+	}
+	pipelines, err := impl.pipelineRepository.FindActiveByAppIdAndEnvironmentId(appId, sourceEnvId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching source pipeline", "appId", appId, "envId", sourceEnvId, "err", err)
+		return "", nil, err
+	}
+	if len(pipelines) == 0 {
+		return sourceEnv.Name, states, nil
+	}
+	sourcePipelineId := pipelines[0].Id
+	deployRunners, err := impl.cdWorkflowRepository.FindSucceededRunnersByPipelineIdAndArtifactIds(sourcePipelineId, artifactIds, bean.CD_WORKFLOW_TYPE_DEPLOY)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching succeeded deployments on source pipeline", "pipelineId", sourcePipelineId, "err", err)
+		return "", nil, err
+	}
+	postRunners, err := impl.cdWorkflowRepository.FindSucceededRunnersByPipelineIdAndArtifactIds(sourcePipelineId, artifactIds, bean.CD_WORKFLOW_TYPE_POST)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching succeeded post-cd runs on source pipeline", "pipelineId", sourcePipelineId, "err", err)
+		return "", nil, err
+	}
+	for _, artifactId := range artifactIds {
+		states[artifactId] = sourceDeploymentState{isPipelineFound: true}
+	}
+	liveRunner, err := impl.cdWorkflowRepository.FindLatestSucceededDeploymentRunner(sourcePipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching latest succeeded deployment on source pipeline", "pipelineId", sourcePipelineId, "err", err)
+		return "", nil, err
+	}
+	liveArtifactId := 0
+	if err == nil && liveRunner.CdWorkflow != nil {
+		liveArtifactId = liveRunner.CdWorkflow.CiArtifactId
+	}
+	for _, runner := range deployRunners {
+		// runners are ordered oldest first, so the soak restarts on every redeployment of the artifact
+		state := states[runner.CdWorkflow.CiArtifactId]
+		state.deployedOn = runner.FinishedOn
+		if state.deployedOn.IsZero() {
+			state.deployedOn = runner.UpdatedOn
+		}
+		state.isReplaced = liveArtifactId > 0 && liveArtifactId != runner.CdWorkflow.CiArtifactId
+		states[runner.CdWorkflow.CiArtifactId] = state
+	}
+	for _, runner := range postRunners {
+		state := states[runner.CdWorkflow.CiArtifactId]
+		state.isPostCdPassed = true
+		states[runner.CdWorkflow.CiArtifactId] = state
+	}
+	return sourceEnv.Name, states, nil
+}
+
+// evaluatePolicy evaluates every rule of the policy for an artifact with the given state on the source environment.
+// Soak time is counted from the latest successful deployment of the artifact on the source environment and is reset
+// once another artifact is deployed there.
+func evaluatePolicy(policy *repository.ArtifactPromotionPolicy, sourceEnvName string, state sourceDeploymentState, at time.Time) []*PromotionRuleResult {
+	newResult := func(rule PromotionRule, passed bool, message string) *PromotionRuleResult {
+		return &PromotionRuleResult{
+			PolicyId:              policy.Id,
+			PolicyName:            policy.Name,
+			SourceEnvironmentId:   policy.SourceEnvironmentId,
+			SourceEnvironmentName: sourceEnvName,
+			Rule:                  rule,
+			Passed:                passed,
+			Message:               message,
+		}
+	}
+	isDeployed := !state.deployedOn.IsZero()
+	var results []*PromotionRuleResult
+	if !state.isPipelineFound {
+		results = append(results, newResult(PROMOTION_RULE_DEPLOYED_ON_SOURCE, false, fmt.Sprintf("no deployment pipeline found on %s", sourceEnvName)))
+	} else if !isDeployed {
+		results = append(results, newResult(PROMOTION_RULE_DEPLOYED_ON_SOURCE, false, fmt.Sprintf("not deployed successfully on %s", sourceEnvName)))
+	} else {
+		results = append(results, newResult(PROMOTION_RULE_DEPLOYED_ON_SOURCE, true, fmt.Sprintf("deployed successfully on %s", sourceEnvName)))
+	}
+	if policy.MinSoakTimeInMinutes > 0 {
+		minSoakTime := time.Duration(policy.MinSoakTimeInMinutes) * time.Minute
+		if !isDeployed {
+			results = append(results, newResult(PROMOTION_RULE_SOAK_TIME, false, fmt.Sprintf("requires %s on %s", minSoakTime, sourceEnvName)))
+		} else if state.isReplaced {
+			results = append(results, newResult(PROMOTION_RULE_SOAK_TIME, false, fmt.Sprintf("requires %s on %s, soak reset as %s was redeployed with another artifact", minSoakTime, sourceEnvName, sourceEnvName)))
+		} else if soakTime := at.Sub(state.deployedOn); soakTime < minSoakTime {
+			results = append(results, newResult(PROMOTION_RULE_SOAK_TIME, false, fmt.Sprintf("requires %s on %s, remaining %s", minSoakTime, sourceEnvName, (minSoakTime-soakTime).Round(time.Minute))))
+		} else {
+			results = append(results, newResult(PROMOTION_RULE_SOAK_TIME, true, fmt.Sprintf("soaked for %s on %s", minSoakTime, sourceEnvName)))
+		}
+	}
+	if policy.RequirePostCdSuccess {
+		if state.isPostCdPassed {
+			results = append(results, newResult(PROMOTION_RULE_POST_CD_SUCCESS, true, fmt.Sprintf("post-cd passed on %s", sourceEnvName)))
+		} else {
+			results = append(results, newResult(PROMOTION_RULE_POST_CD_SUCCESS, false, fmt.Sprintf("post-cd has not passed on %s", sourceEnvName)))
+		}
+	}
+	return results
+}
+
+func buildEvaluation(ruleResults []*PromotionRuleResult) *PromotionPolicyEvaluation {
+	evaluation := &PromotionPolicyEvaluation{IsAllowed: true, RuleResults: ruleResults}
+	var failures []string
+	for _, ruleResult := range ruleResults {
+		if !ruleResult.Passed {
+			failures = append(failures, fmt.Sprintf("%s: %s", ruleResult.PolicyName, ruleResult.Message))
+		}
+	}
+	if len(failures) > 0 {
+		evaluation.IsAllowed = false
+		evaluation.Reason = fmt.Sprintf("Artifact not allowed by promotion policy, %s", strings.Join(failures, "; "))
+	}
+	return evaluation
+}
+
+func (impl *ArtifactPromotionPolicyServiceImpl) validatePolicy(request *ArtifactPromotionPolicyDto) error {
+	var validationErr string
+	if request.TargetEnvironmentId == request.SourceEnvironmentId {
+		validationErr = "source and target environment must be different"
+	} else {
+		for _, envId := range []int{request.TargetEnvironmentId, request.SourceEnvironmentId} {
+			_, err := impl.environmentRepository.FindById(envId)
+			if err == pg.ErrNoRows {
+				validationErr = fmt.Sprintf("environment %d not found", envId)
+				break
+			} else if err != nil {
+				impl.logger.Errorw("error in fetching environment", "envId", envId, "err", err)
+				return err
+			}
+		}
+	}
+	if len(validationErr) > 0 {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: validationErr,
+			UserMessage:     validationErr,
+		}
+	}
+	return nil
+}
+
+func adaptDtoToModel(request *ArtifactPromotionPolicyDto, policy *repository.ArtifactPromotionPolicy) {
+	policy.Name = request.Name
+	policy.Description = request.Description
+	policy.TargetEnvironmentId = request.TargetEnvironmentId
+	policy.SourceEnvironmentId = request.SourceEnvironmentId
+	policy.MinSoakTimeInMinutes = request.MinSoakTimeInMinutes
+	policy.RequirePostCdSuccess = request.RequirePostCdSuccess
+}
+
+func adaptModelToDto(policy *repository.ArtifactPromotionPolicy) *ArtifactPromotionPolicyDto {
+	return &ArtifactPromotionPolicyDto{
+		Id:                   policy.Id,
+		Name:                 policy.Name,
+		Description:          policy.Description,
+		TargetEnvironmentId:  policy.TargetEnvironmentId,
+		SourceEnvironmentId:  policy.SourceEnvironmentId,
+		MinSoakTimeInMinutes: policy.MinSoakTimeInMinutes,
+		RequirePostCdSuccess: policy.RequirePostCdSuccess,
+	}
+}
@@ -0,0 +1,47 @@
+package artifactPromotion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/artifactPromotion/repository"
+)
+
+func TestEvaluatePolicy(t *testing.T) {
+	now := time.Date(2023, time.November, 15, 12, 0, 0, 0, time.UTC)
+	policy := &repository.ArtifactPromotionPolicy{Id: 1, Name: "staging to prod", SourceEnvironmentId: 2, MinSoakTimeInMinutes: 120, RequirePostCdSuccess: true}
+	tests := []struct {
+		name      string
+		state     sourceDeploymentState
+		wantRules int
+		isAllowed bool
+	}{
+		{name: "no pipeline on source environment", state: sourceDeploymentState{}, wantRules: 3, isAllowed: false},
+		{name: "never deployed on source environment", state: sourceDeploymentState{isPipelineFound: true}, wantRules: 3, isAllowed: false},
+		{name: "soak time not elapsed", state: sourceDeploymentState{isPipelineFound: true, deployedOn: now.Add(-time.Hour), isPostCdPassed: true}, wantRules: 3, isAllowed: false},
+		{name: "soak reset by redeployment of source", state: sourceDeploymentState{isPipelineFound: true, deployedOn: now.Add(-3 * time.Hour), isReplaced: true, isPostCdPassed: true}, wantRules: 3, isAllowed: false},
+		{name: "post cd not passed", state: sourceDeploymentState{isPipelineFound: true, deployedOn: now.Add(-3 * time.Hour)}, wantRules: 3, isAllowed: false},
+		{name: "all rules passed", state: sourceDeploymentState{isPipelineFound: true, deployedOn: now.Add(-3 * time.Hour), isPostCdPassed: true}, wantRules: 3, isAllowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := evaluatePolicy(policy, "staging", tt.state, now)
+			if len(results) != tt.wantRules {
+				t.Errorf("evaluatePolicy() returned %d rules, want %d", len(results), tt.wantRules)
+			}
+			evaluation := buildEvaluation(results)
+			if evaluation.IsAllowed != tt.isAllowed {
+				t.Errorf("buildEvaluation() isAllowed = %v, want %v, reason %s", evaluation.IsAllowed, tt.isAllowed, evaluation.Reason)
+			}
+		})
+	}
+}
+
+func TestEvaluatePolicyWithoutOptionalRules(t *testing.T) {
+	now := time.Date(2023, time.November, 15, 12, 0, 0, 0, time.UTC)
+	policy := &repository.ArtifactPromotionPolicy{Id: 1, Name: "qa to staging", SourceEnvironmentId: 2}
+	results := evaluatePolicy(policy, "qa", sourceDeploymentState{isPipelineFound: true, deployedOn: now}, now)
+	if len(results) != 1 || results[0].Rule != PROMOTION_RULE_DEPLOYED_ON_SOURCE || !results[0].Passed {
+		t.Errorf("evaluatePolicy() = %+v, want only a passed %s rule", results, PROMOTION_RULE_DEPLOYED_ON_SOURCE)
+	}
+}
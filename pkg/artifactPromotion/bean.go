@@ -0,0 +1,40 @@
+package artifactPromotion
+
+type ArtifactPromotionPolicyDto struct {
+	Id                   int    `json:"id"`
+	Name                 string `json:"name" validate:"required,max=250"`
+	Description          string `json:"description"`
+	TargetEnvironmentId  int    `json:"targetEnvironmentId" validate:"number,min=1"`
+	SourceEnvironmentId  int    `json:"sourceEnvironmentId" validate:"number,min=1"`
+	MinSoakTimeInMinutes int    `json:"minSoakTimeInMinutes" validate:"number,min=0"`
+	RequirePostCdSuccess bool   `json:"requirePostCdSuccess"`
+	UserId               int32  `json:"-"`
+}
+
+type PromotionRule string
+
+const (
+	// PROMOTION_RULE_DEPLOYED_ON_SOURCE requires the artifact to be deployed successfully on the source environment
+	PROMOTION_RULE_DEPLOYED_ON_SOURCE PromotionRule = "DEPLOYED_ON_SOURCE"
+	// PROMOTION_RULE_SOAK_TIME requires the artifact to have spent the minimum soak time on the source environment
+	PROMOTION_RULE_SOAK_TIME PromotionRule = "SOAK_TIME"
+	// PROMOTION_RULE_POST_CD_SUCCESS requires the post-cd stage of the source environment to have passed for the artifact
+	PROMOTION_RULE_POST_CD_SUCCESS PromotionRule = "POST_CD_SUCCESS"
+)
+
+type PromotionRuleResult struct {
+	PolicyId              int           `json:"policyId"`
+	PolicyName            string        `json:"policyName"`
+	SourceEnvironmentId   int           `json:"sourceEnvironmentId"`
+	SourceEnvironmentName string        `json:"sourceEnvironmentName"`
+	Rule                  PromotionRule `json:"rule"`
+	Passed                bool          `json:"passed"`
+	Message               string        `json:"message"`
+}
+
+// PromotionPolicyEvaluation is the outcome of evaluating all the promotion policies of a target environment for an artifact
+type PromotionPolicyEvaluation struct {
+	IsAllowed   bool                   `json:"isAllowed"`
+	Reason      string                 `json:"reason,omitempty"`
+	RuleResults []*PromotionRuleResult `json:"ruleResults"`
+}
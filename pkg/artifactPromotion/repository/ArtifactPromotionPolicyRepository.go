@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// ArtifactPromotionPolicy allows an artifact to be deployed on the target environment only after it has been
+// deployed successfully on the source environment of the same app
+type ArtifactPromotionPolicy struct {
+	tableName            struct{} `sql:"artifact_promotion_policy" pg:",discard_unknown_columns"`
+	Id                   int      `sql:"id,pk"`
+	Name                 string   `sql:"name,notnull"`
+	Description          string   `sql:"description"`
+	TargetEnvironmentId  int      `sql:"target_environment_id,notnull"`
+	SourceEnvironmentId  int      `sql:"source_environment_id,notnull"`
+	MinSoakTimeInMinutes int      `sql:"min_soak_time_in_minutes,notnull"`
+	RequirePostCdSuccess bool     `sql:"require_post_cd_success,notnull"`
+	Active               bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type ArtifactPromotionPolicyRepository interface {
+	Save(policy *ArtifactPromotionPolicy) error
+	Update(policy *ArtifactPromotionPolicy) error
+	FindById(id int) (*ArtifactPromotionPolicy, error)
+	FindAllActive() ([]*ArtifactPromotionPolicy, error)
+	FindActiveByTargetEnvironmentId(envId int) ([]*ArtifactPromotionPolicy, error)
+}
+
+type ArtifactPromotionPolicyRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewArtifactPromotionPolicyRepositoryImpl(dbConnection *pg.DB) *ArtifactPromotionPolicyRepositoryImpl {
+	return &ArtifactPromotionPolicyRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *ArtifactPromotionPolicyRepositoryImpl) Save(policy *ArtifactPromotionPolicy) error {
+	return impl.dbConnection.Insert(policy)
+}
+
+func (impl *ArtifactPromotionPolicyRepositoryImpl) Update(policy *ArtifactPromotionPolicy) error {
+	return impl.dbConnection.Update(policy)
+}
+
+func (impl *ArtifactPromotionPolicyRepositoryImpl) FindById(id int) (*ArtifactPromotionPolicy, error) {
+	policy := &ArtifactPromotionPolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return policy, err
+}
+
+func (impl *ArtifactPromotionPolicyRepositoryImpl) FindAllActive() ([]*ArtifactPromotionPolicy, error) {
+	var policies []*ArtifactPromotionPolicy
+	err := impl.dbConnection.Model(&policies).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return policies, err
+}
+
+func (impl *ArtifactPromotionPolicyRepositoryImpl) FindActiveByTargetEnvironmentId(envId int) ([]*ArtifactPromotionPolicy, error) {
+	var policies []*ArtifactPromotionPolicy
+	err := impl.dbConnection.Model(&policies).
+		Where("target_environment_id = ?", envId).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return policies, err
+}
@@ -0,0 +1,688 @@
+package artifactRetention
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env"
+	dockerRegistryRepository "github.com/devtron-labs/devtron/internal/sql/repository/dockerRegistry"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	artifactRetentionRepository "github.com/devtron-labs/devtron/pkg/artifactRetention/repository"
+	"github.com/devtron-labs/devtron/pkg/dockerRegistry"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/pipeline/executors"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/registry"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ArtifactRetentionConfig struct {
+	// MaxArtifactsPerRun bounds the artifacts a run collects for a policy, the rest are collected by the next runs
+	MaxArtifactsPerRun int `env:"ARTIFACT_GC_MAX_ARTIFACTS_PER_RUN" envDefault:"500"`
+	GcRunHistoryLimit  int `env:"ARTIFACT_GC_RUN_HISTORY_LIMIT" envDefault:"50"`
+}
+
+type ArtifactRetentionService interface {
+	CreatePolicy(request *ArtifactRetentionPolicyDto) (*ArtifactRetentionPolicyDto, error)
+	UpdatePolicy(request *ArtifactRetentionPolicyDto) (*ArtifactRetentionPolicyDto, error)
+	DeletePolicy(id int, userId int32) error
+	GetAllPolicies() ([]*ArtifactRetentionPolicyDto, error)
+	// DryRun lists the artifacts the policy would collect, nothing is deleted or recorded
+	DryRun(policyId int) (*DryRunResponse, error)
+	// CollectGarbage runs every active policy, policies in dry run mode only record what they would have deleted
+	CollectGarbage()
+	GetGcRuns(policyId int) ([]*GcRunDto, error)
+	GetGcRun(id int) (*GcRunDto, error)
+}
+
+type ArtifactRetentionServiceImpl struct {
+	logger                        *zap.SugaredLogger
+	artifactRetentionRepository   artifactRetentionRepository.ArtifactRetentionRepository
+	ciPipelineRepository          pipelineConfig.CiPipelineRepository
+	dockerArtifactStoreRepository dockerRegistryRepository.DockerArtifactStoreRepository
+	imageTaggingService           pipeline.ImageTaggingService
+	blobStorageConfigService      pipeline.BlobStorageConfigService
+	config                        *ArtifactRetentionConfig
+}
+
+func NewArtifactRetentionServiceImpl(logger *zap.SugaredLogger,
+	artifactRetentionRepository artifactRetentionRepository.ArtifactRetentionRepository,
+	ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	dockerArtifactStoreRepository dockerRegistryRepository.DockerArtifactStoreRepository,
+	imageTaggingService pipeline.ImageTaggingService,
+	blobStorageConfigService pipeline.BlobStorageConfigService) *ArtifactRetentionServiceImpl {
+	cfg := &ArtifactRetentionConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Infow("error occurred while parsing ArtifactRetentionConfig, so setting artifact retention config to default values", "err", err)
+		cfg.MaxArtifactsPerRun = 500
+		cfg.GcRunHistoryLimit = 50
+	}
+	return &ArtifactRetentionServiceImpl{
+		logger:                        logger,
+		artifactRetentionRepository:   artifactRetentionRepository,
+		ciPipelineRepository:          ciPipelineRepository,
+		dockerArtifactStoreRepository: dockerArtifactStoreRepository,
+		imageTaggingService:           imageTaggingService,
+		blobStorageConfigService:      blobStorageConfigService,
+		config:                        cfg,
+	}
+}
+
+const insecureRegistryConnection = "insecure"
+
+// undeployedStatuses are the statuses of deployments which did not roll out the artifact
+var undeployedStatuses = []string{pipelineConfig.WorkflowFailed, pipelineConfig.WorkflowAborted, executors.WorkflowCancel}
+
+func (impl *ArtifactRetentionServiceImpl) CreatePolicy(request *ArtifactRetentionPolicyDto) (*ArtifactRetentionPolicyDto, error) {
+	err := impl.validatePolicy(request)
+	if err != nil {
+		return nil, err
+	}
+	policy := &artifactRetentionRepository.ArtifactRetentionPolicy{
+		Active:   true,
+		AuditLog: sql.NewDefaultAuditLog(request.UserId),
+	}
+	updatePolicy(policy, request)
+	err = impl.artifactRetentionRepository.SavePolicy(policy)
+	if err != nil {
+		impl.logger.Errorw("error in saving artifact retention policy", "policy", policy, "err", err)
+		return nil, err
+	}
+	return adaptPolicy(policy), nil
+}
+
+func (impl *ArtifactRetentionServiceImpl) UpdatePolicy(request *ArtifactRetentionPolicyDto) (*ArtifactRetentionPolicyDto, error) {
+	policy, err := impl.artifactRetentionRepository.FindPolicyById(request.Id)
+	if err == pg.ErrNoRows {
+		return nil, notFound(fmt.Sprintf("artifact retention policy %d not found", request.Id))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching artifact retention policy", "id", request.Id, "err", err)
+		return nil, err
+	}
+	err = impl.validatePolicy(request)
+	if err != nil {
+		return nil, err
+	}
+	updatePolicy(policy, request)
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = request.UserId
+	err = impl.artifactRetentionRepository.UpdatePolicy(policy)
+	if err != nil {
+		impl.logger.Errorw("error in updating artifact retention policy", "id", policy.Id, "err", err)
+		return nil, err
+	}
+	return adaptPolicy(policy), nil
+}
+
+func (impl *ArtifactRetentionServiceImpl) DeletePolicy(id int, userId int32) error {
+	policy, err := impl.artifactRetentionRepository.FindPolicyById(id)
+	if err == pg.ErrNoRows {
+		return notFound(fmt.Sprintf("artifact retention policy %d not found", id))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching artifact retention policy", "id", id, "err", err)
+		return err
+	}
+	policy.Active = false
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = userId
+	err = impl.artifactRetentionRepository.UpdatePolicy(policy)
+	if err != nil {
+		impl.logger.Errorw("error in deleting artifact retention policy", "id", id, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *ArtifactRetentionServiceImpl) GetAllPolicies() ([]*ArtifactRetentionPolicyDto, error) {
+	policies, err := impl.artifactRetentionRepository.FindAllActivePolicies()
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact retention policies", "err", err)
+		return nil, err
+	}
+	result := make([]*ArtifactRetentionPolicyDto, 0, len(policies))
+	for _, policy := range policies {
+		result = append(result, adaptPolicy(policy))
+	}
+	return result, nil
+}
+
+func (impl *ArtifactRetentionServiceImpl) DryRun(policyId int) (*DryRunResponse, error) {
+	policy, err := impl.artifactRetentionRepository.FindPolicyById(policyId)
+	if err == pg.ErrNoRows {
+		return nil, notFound(fmt.Sprintf("artifact retention policy %d not found", policyId))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching artifact retention policy", "id", policyId, "err", err)
+		return nil, err
+	}
+	scanned, candidates, err := impl.findGcCandidates(policy)
+	if err != nil {
+		return nil, err
+	}
+	return &DryRunResponse{PolicyId: policy.Id, ArtifactsScanned: scanned, Candidates: candidates}, nil
+}
+
+func (impl *ArtifactRetentionServiceImpl) CollectGarbage() {
+	policies, err := impl.artifactRetentionRepository.FindAllActivePolicies()
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact retention policies", "err", err)
+		return
+	}
+	for _, policy := range policies {
+		impl.runPolicy(policy)
+	}
+}
+
+func (impl *ArtifactRetentionServiceImpl) GetGcRuns(policyId int) ([]*GcRunDto, error) {
+	runs, err := impl.artifactRetentionRepository.FindGcRunsByPolicyId(policyId, impl.config.GcRunHistoryLimit)
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact gc runs", "policyId", policyId, "err", err)
+		return nil, err
+	}
+	result := make([]*GcRunDto, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, adaptGcRun(run))
+	}
+	return result, nil
+}
+
+func (impl *ArtifactRetentionServiceImpl) GetGcRun(id int) (*GcRunDto, error) {
+	run, err := impl.artifactRetentionRepository.FindGcRunById(id)
+	if err == pg.ErrNoRows {
+		return nil, notFound(fmt.Sprintf("artifact gc run %d not found", id))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching artifact gc run", "id", id, "err", err)
+		return nil, err
+	}
+	records, err := impl.artifactRetentionRepository.FindGcRecordsByGcRunId(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact gc records", "gcRunId", id, "err", err)
+		return nil, err
+	}
+	result := adaptGcRun(run)
+	result.Records = make([]*GcRecordDto, 0, len(records))
+	for _, record := range records {
+		result.Records = append(result.Records, &GcRecordDto{
+			CiArtifactId: record.CiArtifactId,
+			CiPipelineId: record.CiPipelineId,
+			Image:        record.Image,
+			ImageDigest:  record.ImageDigest,
+			RowDeleted:   record.RowDeleted,
+			ImageDeleted: record.ImageDeleted,
+			LogsDeleted:  record.LogsDeleted,
+			DryRun:       record.DryRun,
+			Failed:       record.Failed,
+			Message:      record.Message,
+			CreatedOn:    record.CreatedOn,
+		})
+	}
+	return result, nil
+}
+
+// runPolicy collects the artifacts the policy does not keep and records the run, failures of an artifact are recorded
+// and do not stop the run
+func (impl *ArtifactRetentionServiceImpl) runPolicy(policy *artifactRetentionRepository.ArtifactRetentionPolicy) {
+	run := &artifactRetentionRepository.ArtifactGcRun{
+		PolicyId:  policy.Id,
+		DryRun:    policy.DryRun,
+		Status:    GC_RUN_STATUS_RUNNING,
+		StartedOn: time.Now(),
+		AuditLog:  sql.NewDefaultAuditLog(1),
+	}
+	err := impl.artifactRetentionRepository.SaveGcRun(run)
+	if err != nil {
+		impl.logger.Errorw("error in saving artifact gc run", "policyId", policy.Id, "err", err)
+		return
+	}
+	scanned, candidates, err := impl.findGcCandidates(policy)
+	run.ArtifactsScanned = scanned
+	if err != nil {
+		run.Status = GC_RUN_STATUS_FAILED
+		run.Message = err.Error()
+		impl.finishGcRun(run)
+		return
+	}
+	if len(candidates) > impl.config.MaxArtifactsPerRun {
+		// the oldest artifacts are collected first
+		candidates = candidates[len(candidates)-impl.config.MaxArtifactsPerRun:]
+	}
+	registryClients := make(map[string]*registryClient)
+	failed := 0
+	for _, candidate := range candidates {
+		record := impl.collect(candidate, policy.DryRun, registryClients)
+		record.GcRunId = run.Id
+		err = impl.saveGcRecord(record, candidate.CiArtifactId)
+		if err != nil {
+			record.Failed = true
+		}
+		if record.Failed {
+			failed++
+			continue
+		}
+		if record.RowDeleted {
+			run.ArtifactsDeleted++
+		}
+		if record.ImageDeleted {
+			run.ImagesDeleted++
+		}
+		if record.LogsDeleted {
+			run.LogsDeleted++
+		}
+	}
+	run.Status = GC_RUN_STATUS_SUCCEEDED
+	if failed > 0 {
+		run.Status = GC_RUN_STATUS_PARTIAL
+		run.Message = fmt.Sprintf("failed to collect %d of %d artifacts", failed, len(candidates))
+	}
+	impl.finishGcRun(run)
+}
+
+func (impl *ArtifactRetentionServiceImpl) finishGcRun(run *artifactRetentionRepository.ArtifactGcRun) {
+	run.FinishedOn = time.Now()
+	run.UpdatedOn = time.Now()
+	err := impl.artifactRetentionRepository.UpdateGcRun(run)
+	if err != nil {
+		impl.logger.Errorw("error in updating artifact gc run", "id", run.Id, "err", err)
+	}
+}
+
+// collect deletes the image and the logs of the candidate and then its row, the row is kept if anything before it
+// fails so that the next run collects the artifact again
+func (impl *ArtifactRetentionServiceImpl) collect(candidate *GcCandidateDto, dryRun bool, registryClients map[string]*registryClient) *artifactRetentionRepository.ArtifactGcRecord {
+	record := &artifactRetentionRepository.ArtifactGcRecord{
+		CiArtifactId: candidate.CiArtifactId,
+		CiPipelineId: candidate.CiPipelineId,
+		Image:        candidate.Image,
+		ImageDigest:  candidate.ImageDigest,
+		DryRun:       dryRun,
+		CreatedOn:    time.Now(),
+	}
+	if dryRun {
+		record.RowDeleted, record.ImageDeleted, record.LogsDeleted = candidate.DeleteRow, candidate.DeleteImage, candidate.DeleteLogs
+		return record
+	}
+	if candidate.DeleteImage {
+		err := impl.deleteImage(candidate, registryClients)
+		if err != nil {
+			impl.logger.Errorw("error in deleting image of artifact", "ciArtifactId", candidate.CiArtifactId, "image", candidate.Image, "err", err)
+			record.Failed = true
+			record.Message = fmt.Sprintf("error in deleting image: %s", err.Error())
+			return record
+		}
+		record.ImageDeleted = true
+	}
+	if candidate.DeleteLogs {
+		for _, blobKey := range candidate.blobKeys {
+			err := impl.blobStorageConfigService.DeleteObject(blobKey)
+			if err != nil {
+				record.Failed = true
+				record.Message = fmt.Sprintf("error in deleting %s from blob storage: %s", blobKey, err.Error())
+				return record
+			}
+		}
+		record.LogsDeleted = true
+	}
+	record.RowDeleted = candidate.DeleteRow
+	return record
+}
+
+// saveGcRecord saves the record, deleting the artifact row in the same transaction if the record says so
+func (impl *ArtifactRetentionServiceImpl) saveGcRecord(record *artifactRetentionRepository.ArtifactGcRecord, ciArtifactId int) error {
+	dbConnection := impl.artifactRetentionRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	if record.RowDeleted && !record.DryRun {
+		err = impl.artifactRetentionRepository.DeleteArtifact(ciArtifactId, tx)
+		if err != nil {
+			impl.logger.Errorw("error in deleting ci artifact", "ciArtifactId", ciArtifactId, "err", err)
+			return impl.saveFailedGcRecord(record, err)
+		}
+	}
+	err = impl.artifactRetentionRepository.SaveGcRecord(record, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving artifact gc record", "ciArtifactId", ciArtifactId, "err", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveFailedGcRecord records that the artifact row could not be deleted, its image and logs may already be deleted
+func (impl *ArtifactRetentionServiceImpl) saveFailedGcRecord(record *artifactRetentionRepository.ArtifactGcRecord, cause error) error {
+	record.RowDeleted = false
+	record.Failed = true
+	record.Message = fmt.Sprintf("error in deleting artifact: %s", cause.Error())
+	tx, err := impl.artifactRetentionRepository.GetConnection().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = impl.artifactRetentionRepository.SaveGcRecord(record, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving artifact gc record", "ciArtifactId", record.CiArtifactId, "err", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// findGcCandidates returns the number of artifacts the policy applies to and those it does not keep, oldest last
+func (impl *ArtifactRetentionServiceImpl) findGcCandidates(policy *artifactRetentionRepository.ArtifactRetentionPolicy) (int, []*GcCandidateDto, error) {
+	ciPipelineIds, err := impl.getCiPipelineIds(policy)
+	if err != nil {
+		return 0, nil, err
+	}
+	scanned := 0
+	var candidates []*GcCandidateDto
+	taggedArtifactIdsByApp := make(map[int]map[int]bool)
+	now := time.Now()
+	for _, ciPipelineId := range ciPipelineIds {
+		infos, err := impl.artifactRetentionRepository.FindRetentionInfoByCiPipelineId(ciPipelineId, undeployedStatuses)
+		if err != nil {
+			impl.logger.Errorw("error in fetching artifact retention info", "ciPipelineId", ciPipelineId, "err", err)
+			return 0, nil, err
+		}
+		if len(policy.DockerRegistryId) > 0 {
+			infos = filterByDockerRegistry(infos, policy.DockerRegistryId)
+		}
+		if len(infos) == 0 {
+			continue
+		}
+		scanned += len(infos)
+		var taggedArtifactIds map[int]bool
+		if policy.KeepTaggedReleases {
+			taggedArtifactIds, err = impl.getTaggedArtifactIds(infos[0].AppId, taggedArtifactIdsByApp)
+			if err != nil {
+				return 0, nil, err
+			}
+		}
+		candidates = append(candidates, selectGcCandidates(policy, infos, taggedArtifactIds, now)...)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].CiArtifactId > candidates[j].CiArtifactId
+	})
+	return scanned, candidates, nil
+}
+
+// getCiPipelineIds returns the ci pipelines the policy applies to, pipelines with a policy of their own are skipped by
+// the policy of their registry
+func (impl *ArtifactRetentionServiceImpl) getCiPipelineIds(policy *artifactRetentionRepository.ArtifactRetentionPolicy) ([]int, error) {
+	if policy.CiPipelineId > 0 {
+		return []int{policy.CiPipelineId}, nil
+	}
+	ciPipelineIds, err := impl.artifactRetentionRepository.FindCiPipelineIdsByDockerRegistryId(policy.DockerRegistryId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci pipelines of docker registry", "dockerRegistryId", policy.DockerRegistryId, "err", err)
+		return nil, err
+	}
+	policies, err := impl.artifactRetentionRepository.FindAllActivePolicies()
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact retention policies", "err", err)
+		return nil, err
+	}
+	ownPolicy := make(map[int]bool)
+	for _, p := range policies {
+		if p.CiPipelineId > 0 {
+			ownPolicy[p.CiPipelineId] = true
+		}
+	}
+	var result []int
+	for _, ciPipelineId := range ciPipelineIds {
+		if !ownPolicy[ciPipelineId] {
+			result = append(result, ciPipelineId)
+		}
+	}
+	return result, nil
+}
+
+func (impl *ArtifactRetentionServiceImpl) getTaggedArtifactIds(appId int, taggedArtifactIdsByApp map[int]map[int]bool) (map[int]bool, error) {
+	if taggedArtifactIds, ok := taggedArtifactIdsByApp[appId]; ok {
+		return taggedArtifactIds, nil
+	}
+	tagsByArtifactId, err := impl.imageTaggingService.GetTagsDataMapByAppId(appId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching release tags of app", "appId", appId, "err", err)
+		return nil, err
+	}
+	taggedArtifactIds := make(map[int]bool)
+	for artifactId, tags := range tagsByArtifactId {
+		for _, tag := range tags {
+			if !tag.Deleted {
+				taggedArtifactIds[artifactId] = true
+				break
+			}
+		}
+	}
+	taggedArtifactIdsByApp[appId] = taggedArtifactIds
+	return taggedArtifactIds, nil
+}
+
+// registryClient deletes images from a docker registry, ecr is reached through its own api
+type registryClient struct {
+	store  *dockerRegistryRepository.DockerArtifactStore
+	client *registry.Client
+}
+
+func (impl *ArtifactRetentionServiceImpl) deleteImage(candidate *GcCandidateDto, registryClients map[string]*registryClient) error {
+	client, ok := registryClients[candidate.dockerRegistryId]
+	if !ok {
+		var err error
+		client, err = impl.getRegistryClient(candidate.dockerRegistryId)
+		if err != nil {
+			return err
+		}
+		registryClients[candidate.dockerRegistryId] = client
+	}
+	repository := registry.GetRepositoryOfImage(candidate.Image)
+	if client.store.RegistryType == dockerRegistryRepository.REGISTRYTYPE_ECR {
+		return dockerRegistry.DeleteEcrImage(client.store.AWSRegion, client.store.AWSAccessKeyId, client.store.AWSSecretAccessKey,
+			repository, candidate.ImageDigest, getImageTag(candidate.Image))
+	}
+	reference := candidate.ImageDigest
+	if len(reference) == 0 {
+		reference = getImageTag(candidate.Image)
+	}
+	err := client.client.DeleteManifest(repository, reference)
+	if errors.Is(err, registry.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (impl *ArtifactRetentionServiceImpl) getRegistryClient(dockerRegistryId string) (*registryClient, error) {
+	store, err := impl.dockerArtifactStoreRepository.FindOne(dockerRegistryId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching docker registry", "dockerRegistryId", dockerRegistryId, "err", err)
+		return nil, err
+	}
+	if store.RegistryType == dockerRegistryRepository.REGISTRYTYPE_ECR {
+		return &registryClient{store: store}, nil
+	}
+	client, err := registry.NewClient(&registry.Credential{
+		RegistryURL: store.RegistryURL,
+		Username:    store.Username,
+		Password:    store.Password,
+		Insecure:    store.Connection == insecureRegistryConnection,
+		Cert:        store.Cert,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &registryClient{store: store, client: client}, nil
+}
+
+func (impl *ArtifactRetentionServiceImpl) validatePolicy(request *ArtifactRetentionPolicyDto) error {
+	if (request.CiPipelineId > 0) == (len(request.DockerRegistryId) > 0) {
+		return badRequest("a retention policy applies to either a ci pipeline or a docker registry")
+	}
+	if request.CiPipelineId > 0 {
+		_, err := impl.ciPipelineRepository.FindById(request.CiPipelineId)
+		if err == pg.ErrNoRows {
+			return badRequest(fmt.Sprintf("ci pipeline %d not found", request.CiPipelineId))
+		} else if err != nil {
+			impl.logger.Errorw("error in fetching ci pipeline", "ciPipelineId", request.CiPipelineId, "err", err)
+			return err
+		}
+		existing, err := impl.artifactRetentionRepository.FindActivePolicyByCiPipelineId(request.CiPipelineId)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching artifact retention policy", "ciPipelineId", request.CiPipelineId, "err", err)
+			return err
+		}
+		if err == nil && existing.Id != request.Id {
+			return badRequest("a retention policy already exists for the ci pipeline")
+		}
+		return nil
+	}
+	store, err := impl.dockerArtifactStoreRepository.FindOne(request.DockerRegistryId)
+	if err == pg.ErrNoRows {
+		return badRequest(fmt.Sprintf("docker registry %s not found", request.DockerRegistryId))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching docker registry", "dockerRegistryId", request.DockerRegistryId, "err", err)
+		return err
+	}
+	if request.DeleteRegistryImages && store.RegistryType == dockerRegistryRepository.REGISTRYTYPE_ECR && len(store.AWSAccessKeyId) == 0 {
+		return badRequest(fmt.Sprintf("images cannot be deleted from docker registry %s as it has no access key", request.DockerRegistryId))
+	}
+	existing, err := impl.artifactRetentionRepository.FindActivePolicyByDockerRegistryId(request.DockerRegistryId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching artifact retention policy", "dockerRegistryId", request.DockerRegistryId, "err", err)
+		return err
+	}
+	if err == nil && existing.Id != request.Id {
+		return badRequest("a retention policy already exists for the docker registry")
+	}
+	return nil
+}
+
+// selectGcCandidates returns the artifacts of a ci pipeline the policy does not keep, infos are ordered latest first.
+// Artifacts with nothing left to delete are not candidates.
+func selectGcCandidates(policy *artifactRetentionRepository.ArtifactRetentionPolicy, infos []*artifactRetentionRepository.ArtifactRetentionInfo,
+	taggedArtifactIds map[int]bool, now time.Time) []*GcCandidateDto {
+	deployedAfter := now.AddDate(0, 0, -policy.KeepDeployedWithinDays)
+	var candidates []*GcCandidateDto
+	for i, info := range infos {
+		if i < policy.KeepLast || info.CurrentlyDeployed || info.AlreadyCollected {
+			continue
+		}
+		if policy.KeepDeployedWithinDays > 0 && !info.LastDeployedOn.IsZero() && info.LastDeployedOn.After(deployedAfter) {
+			continue
+		}
+		if policy.KeepTaggedReleases && taggedArtifactIds[info.CiArtifactId] {
+			continue
+		}
+		candidate := &GcCandidateDto{
+			CiArtifactId:     info.CiArtifactId,
+			CiPipelineId:     info.CiPipelineId,
+			Image:            info.Image,
+			ImageDigest:      info.ImageDigest,
+			CreatedOn:        info.CreatedOn,
+			DeleteRow:        !info.Referenced,
+			dockerRegistryId: info.DockerRegistryId,
+		}
+		// images of artifacts referenced by deployments are never deleted so that they can still be rolled back to, and
+		// images pushed by another artifact too, as by a rebuild of the same commit, are left to the last of them
+		candidate.DeleteImage = policy.DeleteRegistryImages && !info.Referenced && !info.ImageShared && len(info.Image) > 0 && len(info.DockerRegistryId) > 0
+		if policy.DeleteBlobStorageLogs {
+			for _, location := range []string{info.LogLocation, info.ArtifactLocation} {
+				if blobKey := getBlobKey(location); len(blobKey) > 0 {
+					candidate.blobKeys = append(candidate.blobKeys, blobKey)
+				}
+			}
+			candidate.DeleteLogs = len(candidate.blobKeys) > 0
+		}
+		if !candidate.DeleteRow && !candidate.DeleteImage && !candidate.DeleteLogs {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+func filterByDockerRegistry(infos []*artifactRetentionRepository.ArtifactRetentionInfo, dockerRegistryId string) []*artifactRetentionRepository.ArtifactRetentionInfo {
+	var result []*artifactRetentionRepository.ArtifactRetentionInfo
+	for _, info := range infos {
+		if info.DockerRegistryId == dockerRegistryId {
+			result = append(result, info)
+		}
+	}
+	return result
+}
+
+// getBlobKey returns the key of a location in the build logs bucket, locations are either keys or s3://bucket/key
+func getBlobKey(location string) string {
+	if strings.HasPrefix(location, "s3://") {
+		_, key, _ := strings.Cut(strings.TrimPrefix(location, "s3://"), "/")
+		return key
+	}
+	return strings.TrimPrefix(location, "/")
+}
+
+func getImageTag(image string) string {
+	if index := strings.LastIndex(image, ":"); index > strings.LastIndex(image, "/") {
+		return image[index+1:]
+	}
+	return ""
+}
+
+func updatePolicy(policy *artifactRetentionRepository.ArtifactRetentionPolicy, request *ArtifactRetentionPolicyDto) {
+	policy.Name = request.Name
+	policy.CiPipelineId = request.CiPipelineId
+	policy.DockerRegistryId = request.DockerRegistryId
+	policy.KeepLast = request.KeepLast
+	policy.KeepDeployedWithinDays = request.KeepDeployedWithinDays
+	policy.KeepTaggedReleases = request.KeepTaggedReleases
+	policy.DeleteRegistryImages = request.DeleteRegistryImages
+	policy.DeleteBlobStorageLogs = request.DeleteBlobStorageLogs
+	policy.DryRun = request.DryRun
+}
+
+func adaptPolicy(policy *artifactRetentionRepository.ArtifactRetentionPolicy) *ArtifactRetentionPolicyDto {
+	return &ArtifactRetentionPolicyDto{
+		Id:                     policy.Id,
+		Name:                   policy.Name,
+		CiPipelineId:           policy.CiPipelineId,
+		DockerRegistryId:       policy.DockerRegistryId,
+		KeepLast:               policy.KeepLast,
+		KeepDeployedWithinDays: policy.KeepDeployedWithinDays,
+		KeepTaggedReleases:     policy.KeepTaggedReleases,
+		DeleteRegistryImages:   policy.DeleteRegistryImages,
+		DeleteBlobStorageLogs:  policy.DeleteBlobStorageLogs,
+		DryRun:                 policy.DryRun,
+	}
+}
+
+func adaptGcRun(run *artifactRetentionRepository.ArtifactGcRun) *GcRunDto {
+	result := &GcRunDto{
+		Id:               run.Id,
+		PolicyId:         run.PolicyId,
+		DryRun:           run.DryRun,
+		Status:           run.Status,
+		Message:          run.Message,
+		ArtifactsScanned: run.ArtifactsScanned,
+		ArtifactsDeleted: run.ArtifactsDeleted,
+		ImagesDeleted:    run.ImagesDeleted,
+		LogsDeleted:      run.LogsDeleted,
+		StartedOn:        run.StartedOn,
+	}
+	if !run.FinishedOn.IsZero() {
+		finishedOn := run.FinishedOn
+		result.FinishedOn = &finishedOn
+	}
+	return result
+}
+
+func badRequest(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: message, UserMessage: message}
+}
+
+func notFound(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: message, UserMessage: message}
+}
@@ -0,0 +1,65 @@
+package artifactRetention
+
+import (
+	"testing"
+	"time"
+
+	artifactRetentionRepository "github.com/devtron-labs/devtron/pkg/artifactRetention/repository"
+)
+
+func TestSelectGcCandidates(t *testing.T) {
+	now := time.Now()
+	policy := &artifactRetentionRepository.ArtifactRetentionPolicy{
+		KeepLast:               2,
+		KeepDeployedWithinDays: 30,
+		KeepTaggedReleases:     true,
+		DeleteRegistryImages:   true,
+		DeleteBlobStorageLogs:  true,
+	}
+	infos := []*artifactRetentionRepository.ArtifactRetentionInfo{
+		{CiArtifactId: 10, Image: "registry.example.com/app:10", DockerRegistryId: "reg"},
+		{CiArtifactId: 9, Image: "registry.example.com/app:9", DockerRegistryId: "reg"},
+		{CiArtifactId: 8, Image: "registry.example.com/app:8", DockerRegistryId: "reg", CurrentlyDeployed: true, Referenced: true},
+		{CiArtifactId: 7, Image: "registry.example.com/app:7", DockerRegistryId: "reg", LastDeployedOn: now.AddDate(0, 0, -3), Referenced: true},
+		{CiArtifactId: 6, Image: "registry.example.com/app:6", DockerRegistryId: "reg", Referenced: true},
+		{CiArtifactId: 5, Image: "registry.example.com/app:5", DockerRegistryId: "reg", LastDeployedOn: now.AddDate(0, 0, -60), Referenced: true,
+			LogLocation: "ci-logs/app-5/main.log", ArtifactLocation: "s3://devtron-ci-log/ci-artifacts/5/5.zip"},
+		{CiArtifactId: 4, Image: "registry.example.com/app:4", DockerRegistryId: "reg", ImageShared: true},
+		{CiArtifactId: 31, Image: "registry.example.com/app:31", DockerRegistryId: "reg"},
+		{CiArtifactId: 3, Image: "registry.example.com/app:3", DockerRegistryId: "reg", AlreadyCollected: true, Referenced: true},
+		{CiArtifactId: 2, Image: "registry.example.com/app:2", DockerRegistryId: "reg", ImageShared: true, Referenced: true},
+	}
+	candidates := selectGcCandidates(policy, infos, map[int]bool{6: true}, now)
+	if len(candidates) != 3 {
+		t.Fatalf("selectGcCandidates() = %d candidates, want 3", len(candidates))
+	}
+	if c := candidates[0]; c.CiArtifactId != 5 || c.DeleteRow || c.DeleteImage || !c.DeleteLogs ||
+		len(c.blobKeys) != 2 || c.blobKeys[1] != "ci-artifacts/5/5.zip" {
+		t.Errorf("selectGcCandidates() deployed long ago = %+v", c)
+	}
+	if c := candidates[1]; c.CiArtifactId != 4 || !c.DeleteRow || c.DeleteImage || c.DeleteLogs {
+		t.Errorf("selectGcCandidates() shared image = %+v", c)
+	}
+	if c := candidates[2]; c.CiArtifactId != 31 || !c.DeleteRow || !c.DeleteImage || c.DeleteLogs {
+		t.Errorf("selectGcCandidates() unreferenced = %+v", c)
+	}
+
+	policy.KeepTaggedReleases = false
+	policy.DeleteRegistryImages = false
+	candidates = selectGcCandidates(policy, infos, map[int]bool{6: true}, now)
+	if len(candidates) != 3 || candidates[0].CiArtifactId != 5 || candidates[2].DeleteImage || candidates[1].CiArtifactId != 4 {
+		t.Errorf("selectGcCandidates() without deleting images = %+v", candidates)
+	}
+}
+
+func TestGetBlobKey(t *testing.T) {
+	for location, want := range map[string]string{
+		"s3://devtron-ci-log/ci-artifacts/5/5.zip": "ci-artifacts/5/5.zip",
+		"ci-logs/app-5/main.log":                   "ci-logs/app-5/main.log",
+		"":                                         "",
+	} {
+		if got := getBlobKey(location); got != want {
+			t.Errorf("getBlobKey(%q) = %q, want %q", location, got, want)
+		}
+	}
+}
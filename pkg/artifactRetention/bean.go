@@ -0,0 +1,81 @@
+package artifactRetention
+
+import "time"
+
+const (
+	GC_RUN_STATUS_RUNNING   = "Running"
+	GC_RUN_STATUS_SUCCEEDED = "Succeeded"
+	// GC_RUN_STATUS_PARTIAL is the status of runs which failed to collect some of the artifacts, those are collected
+	// again by the next run
+	GC_RUN_STATUS_PARTIAL = "PartiallySucceeded"
+	GC_RUN_STATUS_FAILED  = "Failed"
+)
+
+// ArtifactRetentionPolicyDto applies to either one ci pipeline or every ci pipeline pushing to the docker registry.
+// Artifacts among the last KeepLast built, deployed in the last KeepDeployedWithinDays days, currently deployed or,
+// with KeepTaggedReleases, carrying a release tag are kept, the others are garbage collected.
+type ArtifactRetentionPolicyDto struct {
+	Id                     int    `json:"id"`
+	Name                   string `json:"name" validate:"required,max=250"`
+	CiPipelineId           int    `json:"ciPipelineId" validate:"number,min=0"`
+	DockerRegistryId       string `json:"dockerRegistryId"`
+	KeepLast               int    `json:"keepLast" validate:"number,min=1"`
+	KeepDeployedWithinDays int    `json:"keepDeployedWithinDays" validate:"number,min=0"`
+	KeepTaggedReleases     bool   `json:"keepTaggedReleases"`
+	DeleteRegistryImages   bool   `json:"deleteRegistryImages"`
+	DeleteBlobStorageLogs  bool   `json:"deleteBlobStorageLogs"`
+	// DryRun makes the garbage collector only record what it would have deleted
+	DryRun bool  `json:"dryRun"`
+	UserId int32 `json:"-"`
+}
+
+// GcCandidateDto is an artifact the policy does not keep, the row and image of artifacts still referenced by
+// deployments, approvals or tags are kept, as they can still be rolled back to, and only their logs are deleted
+type GcCandidateDto struct {
+	CiArtifactId int       `json:"ciArtifactId"`
+	CiPipelineId int       `json:"ciPipelineId"`
+	Image        string    `json:"image"`
+	ImageDigest  string    `json:"imageDigest"`
+	CreatedOn    time.Time `json:"createdOn"`
+	DeleteRow    bool      `json:"deleteRow"`
+	DeleteImage  bool      `json:"deleteImage"`
+	DeleteLogs   bool      `json:"deleteLogs"`
+
+	dockerRegistryId string
+	blobKeys         []string
+}
+
+type DryRunResponse struct {
+	PolicyId         int               `json:"policyId"`
+	ArtifactsScanned int               `json:"artifactsScanned"`
+	Candidates       []*GcCandidateDto `json:"candidates"`
+}
+
+type GcRunDto struct {
+	Id               int            `json:"id"`
+	PolicyId         int            `json:"policyId"`
+	DryRun           bool           `json:"dryRun"`
+	Status           string         `json:"status"`
+	Message          string         `json:"message"`
+	ArtifactsScanned int            `json:"artifactsScanned"`
+	ArtifactsDeleted int            `json:"artifactsDeleted"`
+	ImagesDeleted    int            `json:"imagesDeleted"`
+	LogsDeleted      int            `json:"logsDeleted"`
+	StartedOn        time.Time      `json:"startedOn"`
+	FinishedOn       *time.Time     `json:"finishedOn,omitempty"`
+	Records          []*GcRecordDto `json:"records,omitempty"`
+}
+
+type GcRecordDto struct {
+	CiArtifactId int       `json:"ciArtifactId"`
+	CiPipelineId int       `json:"ciPipelineId"`
+	Image        string    `json:"image"`
+	ImageDigest  string    `json:"imageDigest"`
+	RowDeleted   bool      `json:"rowDeleted"`
+	ImageDeleted bool      `json:"imageDeleted"`
+	LogsDeleted  bool      `json:"logsDeleted"`
+	DryRun       bool      `json:"dryRun"`
+	Failed       bool      `json:"failed"`
+	Message      string    `json:"message"`
+	CreatedOn    time.Time `json:"createdOn"`
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// ArtifactRetentionPolicy decides which artifacts of a ci pipeline, or of every ci pipeline pushing to a container
+// registry, are kept, a policy of the ci pipeline takes precedence over the one of its registry
+type ArtifactRetentionPolicy struct {
+	tableName              struct{} `sql:"artifact_retention_policy" pg:",discard_unknown_columns"`
+	Id                     int      `sql:"id,pk"`
+	Name                   string   `sql:"name,notnull"`
+	CiPipelineId           int      `sql:"ci_pipeline_id"`
+	DockerRegistryId       string   `sql:"docker_registry_id"`
+	KeepLast               int      `sql:"keep_last,notnull"`
+	KeepDeployedWithinDays int      `sql:"keep_deployed_within_days,notnull"`
+	KeepTaggedReleases     bool     `sql:"keep_tagged_releases,notnull"`
+	DeleteRegistryImages   bool     `sql:"delete_registry_images,notnull"`
+	DeleteBlobStorageLogs  bool     `sql:"delete_blob_storage_logs,notnull"`
+	DryRun                 bool     `sql:"dry_run,notnull"`
+	Active                 bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// ArtifactGcRun is a run of the garbage collector for a policy
+type ArtifactGcRun struct {
+	tableName        struct{}  `sql:"artifact_gc_run" pg:",discard_unknown_columns"`
+	Id               int       `sql:"id,pk"`
+	PolicyId         int       `sql:"policy_id,notnull"`
+	DryRun           bool      `sql:"dry_run,notnull"`
+	Status           string    `sql:"status,notnull"`
+	Message          string    `sql:"message"`
+	ArtifactsScanned int       `sql:"artifacts_scanned,notnull"`
+	ArtifactsDeleted int       `sql:"artifacts_deleted,notnull"`
+	ImagesDeleted    int       `sql:"images_deleted,notnull"`
+	LogsDeleted      int       `sql:"logs_deleted,notnull"`
+	StartedOn        time.Time `sql:"started_on,notnull"`
+	FinishedOn       time.Time `sql:"finished_on"`
+	sql.AuditLog
+}
+
+// ArtifactGcRecord is an artifact collected by a run, artifacts which are still referenced keep their row and image and
+// only lose their logs. Artifacts of failed records are kept and collected again by the next run
+type ArtifactGcRecord struct {
+	tableName    struct{}  `sql:"artifact_gc_record" pg:",discard_unknown_columns"`
+	Id           int       `sql:"id,pk"`
+	GcRunId      int       `sql:"gc_run_id,notnull"`
+	CiArtifactId int       `sql:"ci_artifact_id,notnull"`
+	CiPipelineId int       `sql:"ci_pipeline_id,notnull"`
+	Image        string    `sql:"image"`
+	ImageDigest  string    `sql:"image_digest"`
+	RowDeleted   bool      `sql:"row_deleted,notnull"`
+	ImageDeleted bool      `sql:"image_deleted,notnull"`
+	LogsDeleted  bool      `sql:"logs_deleted,notnull"`
+	DryRun       bool      `sql:"dry_run,notnull"`
+	Failed       bool      `sql:"failed,notnull"`
+	Message      string    `sql:"message"`
+	CreatedOn    time.Time `sql:"created_on,notnull"`
+}
+
+// ArtifactRetentionInfo is what retention of an artifact is decided on
+type ArtifactRetentionInfo struct {
+	CiArtifactId      int       `sql:"ci_artifact_id"`
+	CiPipelineId      int       `sql:"ci_pipeline_id"`
+	AppId             int       `sql:"app_id"`
+	Image             string    `sql:"image"`
+	ImageDigest       string    `sql:"image_digest"`
+	DockerRegistryId  string    `sql:"docker_registry_id"`
+	LogLocation       string    `sql:"log_location"`
+	ArtifactLocation  string    `sql:"artifact_location"`
+	CreatedOn         time.Time `sql:"created_on"`
+	LastDeployedOn    time.Time `sql:"last_deployed_on"`
+	CurrentlyDeployed bool      `sql:"currently_deployed"`
+	Referenced        bool      `sql:"referenced"`
+	ImageShared       bool      `sql:"image_shared"`
+	AlreadyCollected  bool      `sql:"already_collected"`
+}
+
+type ArtifactRetentionRepository interface {
+	GetConnection() *pg.DB
+	SavePolicy(policy *ArtifactRetentionPolicy) error
+	UpdatePolicy(policy *ArtifactRetentionPolicy) error
+	FindPolicyById(id int) (*ArtifactRetentionPolicy, error)
+	FindAllActivePolicies() ([]*ArtifactRetentionPolicy, error)
+	FindActivePolicyByCiPipelineId(ciPipelineId int) (*ArtifactRetentionPolicy, error)
+	FindActivePolicyByDockerRegistryId(dockerRegistryId string) (*ArtifactRetentionPolicy, error)
+	// FindCiPipelineIdsByDockerRegistryId returns the active ci pipelines pushing to the registry, either through their
+	// override or through the ci template of their app
+	FindCiPipelineIdsByDockerRegistryId(dockerRegistryId string) ([]int, error)
+	// FindRetentionInfoByCiPipelineId returns the artifacts built by the ci pipeline, latest first
+	FindRetentionInfoByCiPipelineId(ciPipelineId int, deployExcludedStatuses []string) ([]*ArtifactRetentionInfo, error)
+	// DeleteArtifact deletes the artifact row along with the rows which only describe it
+	DeleteArtifact(ciArtifactId int, tx *pg.Tx) error
+
+	SaveGcRun(run *ArtifactGcRun) error
+	UpdateGcRun(run *ArtifactGcRun) error
+	FindGcRunById(id int) (*ArtifactGcRun, error)
+	FindGcRunsByPolicyId(policyId int, limit int) ([]*ArtifactGcRun, error)
+	SaveGcRecord(record *ArtifactGcRecord, tx *pg.Tx) error
+	FindGcRecordsByGcRunId(gcRunId int) ([]*ArtifactGcRecord, error)
+}
+
+type ArtifactRetentionRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewArtifactRetentionRepositoryImpl(dbConnection *pg.DB) *ArtifactRetentionRepositoryImpl {
+	return &ArtifactRetentionRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) GetConnection() *pg.DB {
+	return impl.dbConnection
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) SavePolicy(policy *ArtifactRetentionPolicy) error {
+	return impl.dbConnection.Insert(policy)
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) UpdatePolicy(policy *ArtifactRetentionPolicy) error {
+	return impl.dbConnection.Update(policy)
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) FindPolicyById(id int) (*ArtifactRetentionPolicy, error) {
+	policy := &ArtifactRetentionPolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return policy, err
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) FindAllActivePolicies() ([]*ArtifactRetentionPolicy, error) {
+	var policies []*ArtifactRetentionPolicy
+	err := impl.dbConnection.Model(&policies).
+		Where("active = ?", true).
+		Order("id").
+		Select()
+	return policies, err
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) FindActivePolicyByCiPipelineId(ciPipelineId int) (*ArtifactRetentionPolicy, error) {
+	policy := &ArtifactRetentionPolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("ci_pipeline_id = ?", ciPipelineId).
+		Where("active = ?", true).
+		Select()
+	return policy, err
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) FindActivePolicyByDockerRegistryId(dockerRegistryId string) (*ArtifactRetentionPolicy, error) {
+	policy := &ArtifactRetentionPolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("docker_registry_id = ?", dockerRegistryId).
+		Where("active = ?", true).
+		Select()
+	return policy, err
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) FindCiPipelineIdsByDockerRegistryId(dockerRegistryId string) ([]int, error) {
+	var ciPipelineIds []int
+	query := "SELECT p.id FROM ci_pipeline p" +
+		" LEFT JOIN ci_template ct ON ct.app_id = p.app_id AND ct.active = true" +
+		" LEFT JOIN ci_template_override cto ON cto.ci_pipeline_id = p.id AND cto.active = true AND p.is_docker_config_overridden = true" +
+		" WHERE p.deleted = false AND COALESCE(cto.docker_registry_id, ct.docker_registry_id) = ?" +
+		" ORDER BY p.id;"
+	_, err := impl.dbConnection.Query(&ciPipelineIds, query, dockerRegistryId)
+	return ciPipelineIds, err
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) FindRetentionInfoByCiPipelineId(ciPipelineId int, deployExcludedStatuses []string) ([]*ArtifactRetentionInfo, error) {
+	var infos []*ArtifactRetentionInfo
+	query := "SELECT a.id AS ci_artifact_id, a.pipeline_id AS ci_pipeline_id, p.app_id, a.image, a.image_digest, a.created_on," +
+		" CASE WHEN a.credentials_source_type = 'global_container_registry' THEN a.credentials_source_value" +
+		" ELSE COALESCE(cto.docker_registry_id, ct.docker_registry_id) END AS docker_registry_id," +
+		" wf.log_file_path AS log_location, wf.ci_artifact_location AS artifact_location," +
+		" (SELECT max(cwr.started_on) FROM cd_workflow cw INNER JOIN cd_workflow_runner cwr ON cwr.cd_workflow_id = cw.id" +
+		" WHERE cw.ci_artifact_id = a.id AND cwr.workflow_type = 'DEPLOY' AND cwr.status NOT IN (?)) AS last_deployed_on," +
+		" a.id IN (SELECT DISTINCT ON (cw.pipeline_id) cw.ci_artifact_id FROM cd_workflow_runner cwr" +
+		" INNER JOIN cd_workflow cw ON cw.id = cwr.cd_workflow_id" +
+		" INNER JOIN pipeline cp ON cp.id = cw.pipeline_id AND cp.deleted = false" +
+		" WHERE cp.ci_pipeline_id = a.pipeline_id AND cwr.workflow_type = 'DEPLOY' AND cwr.status NOT IN (?)" +
+		" ORDER BY cw.pipeline_id, cwr.id DESC) AS currently_deployed," +
+		" (EXISTS (SELECT 1 FROM cd_workflow cw WHERE cw.ci_artifact_id = a.id)" +
+		" OR EXISTS (SELECT 1 FROM pipeline_config_override pco WHERE pco.ci_artifact_id = a.id)" +
+		" OR EXISTS (SELECT 1 FROM ci_artifact child WHERE child.parent_ci_artifact = a.id)" +
+		" OR EXISTS (SELECT 1 FROM deployment_approval_request dar WHERE dar.ci_artifact_id = a.id)" +
+		" OR EXISTS (SELECT 1 FROM scheduled_deployment sd WHERE sd.ci_artifact_id = a.id)" +
+		" OR EXISTS (SELECT 1 FROM release_tags rt WHERE rt.artifact_id = a.id)" +
+		" OR EXISTS (SELECT 1 FROM image_comments ic WHERE ic.artifact_id = a.id)" +
+		" OR EXISTS (SELECT 1 FROM image_tagging_audit ita WHERE ita.artifact_id = a.id)) AS referenced," +
+		" EXISTS (SELECT 1 FROM ci_artifact other WHERE other.image = a.image AND other.id <> a.id) AS image_shared," +
+		" EXISTS (SELECT 1 FROM artifact_gc_record r WHERE r.ci_artifact_id = a.id AND r.dry_run = false AND r.failed = false) AS already_collected" +
+		" FROM ci_artifact a" +
+		" INNER JOIN ci_pipeline p ON p.id = a.pipeline_id" +
+		" LEFT JOIN ci_workflow wf ON wf.id = a.ci_workflow_id" +
+		" LEFT JOIN ci_template ct ON ct.app_id = p.app_id AND ct.active = true" +
+		" LEFT JOIN ci_template_override cto ON cto.ci_pipeline_id = p.id AND cto.active = true AND p.is_docker_config_overridden = true" +
+		" WHERE a.pipeline_id = ?" +
+		" ORDER BY a.id DESC;"
+	_, err := impl.dbConnection.Query(&infos, query, pg.In(deployExcludedStatuses), pg.In(deployExcludedStatuses), ciPipelineId)
+	return infos, err
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) DeleteArtifact(ciArtifactId int, tx *pg.Tx) error {
+	_, err := tx.Exec("DELETE FROM image_signature_verification WHERE ci_artifact_id = ?;", ciArtifactId)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM ci_build_provenance WHERE ci_artifact_id = ?;", ciArtifactId)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM ci_artifact WHERE id = ?;", ciArtifactId)
+	return err
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) SaveGcRun(run *ArtifactGcRun) error {
+	return impl.dbConnection.Insert(run)
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) UpdateGcRun(run *ArtifactGcRun) error {
+	return impl.dbConnection.Update(run)
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) FindGcRunById(id int) (*ArtifactGcRun, error) {
+	run := &ArtifactGcRun{}
+	err := impl.dbConnection.Model(run).
+		Where("id = ?", id).
+		Select()
+	return run, err
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) FindGcRunsByPolicyId(policyId int, limit int) ([]*ArtifactGcRun, error) {
+	var runs []*ArtifactGcRun
+	err := impl.dbConnection.Model(&runs).
+		Where("policy_id = ?", policyId).
+		Order("id DESC").
+		Limit(limit).
+		Select()
+	return runs, err
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) SaveGcRecord(record *ArtifactGcRecord, tx *pg.Tx) error {
+	return tx.Insert(record)
+}
+
+func (impl *ArtifactRetentionRepositoryImpl) FindGcRecordsByGcRunId(gcRunId int) ([]*ArtifactGcRecord, error) {
+	var records []*ArtifactGcRecord
+	err := impl.dbConnection.Model(&records).
+		Where("gc_run_id = ?", gcRunId).
+		Order("id").
+		Select()
+	return records, err
+}
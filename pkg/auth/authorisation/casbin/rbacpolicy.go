@@ -54,6 +54,7 @@ const (
 	ActionTrigger   = "trigger"
 	ActionNotify    = "notify"
 	ActionExec      = "exec"
+	ActionApprove   = "approve"
 
 	ClusterResourceRegex         = "%s/%s"    // {cluster}/{namespace}
 	ClusterObjectRegex           = "%s/%s/%s" // {groupName}/{kindName}/{objectName}
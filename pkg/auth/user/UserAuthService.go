@@ -398,6 +398,7 @@ func WhitelistChecker(url string) bool {
 		"/orchestrator/auth/login",
 		"/dashboard",
 		"/orchestrator/webhook/git",
+		"/orchestrator/webhook/plugin-test/",
 	}
 	for _, a := range prefixUrls {
 		if strings.Contains(url, a) {
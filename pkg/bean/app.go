@@ -25,6 +25,7 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/helper"
 	repository2 "github.com/devtron-labs/devtron/internal/sql/repository/imageTagging"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/artifactPromotion"
 	"github.com/devtron-labs/devtron/pkg/chartRepo/repository"
 	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
 	"github.com/devtron-labs/devtron/pkg/pipeline/repository"
@@ -91,6 +92,10 @@ type CiMaterial struct {
 	Id              int               `json:"id,omitempty"`
 	GitMaterialName string            `json:"gitMaterialName"`
 	IsRegex         bool              `json:"isRegex"`
+	// IncludePaths and ExcludePaths are globs of files relative to the repository root, ** matching any number of
+	// directories. Automatic triggers are skipped for commits changing no included file which is not excluded
+	IncludePaths []string `json:"includePaths,omitempty"`
+	ExcludePaths []string `json:"excludePaths,omitempty"`
 }
 
 type CiPipeline struct {
@@ -328,6 +333,8 @@ type CiTriggerRequest struct {
 	EnvironmentId       int                  `json:"environmentId"`
 	PipelineType        string               `json:"pipelineType"`
 	CiArtifactLastFetch time.Time            `json:"ciArtifactLastFetch"`
+	// PriorityClass overrides the priority class of the ci pipeline for this build in the build queue
+	PriorityClass string `json:"priorityClass,omitempty"`
 }
 
 type CiTrigger struct {
@@ -590,6 +597,8 @@ type CDPipelineConfigObject struct {
 	SwitchFromCiPipelineId        int                                    `json:"switchFromCiPipelineId"`
 	CDPipelineAddType             CDPipelineAddType                      `json:"addType"`
 	ChildPipelineId               int                                    `json:"childPipelineId"`
+	UserApprovalConf              *pipelineConfig.UserApprovalConfig     `json:"userApprovalConf,omitempty"`
+	AutoRollbackConf              *pipelineConfig.AutoRollbackConfig     `json:"autoRollbackConf,omitempty"`
 }
 
 type CDPipelineAddType string
@@ -776,6 +785,9 @@ type CiArtifactBean struct {
 	CiPipelineId                  int                       `json:"-"`
 	CredentialsSourceType         string                    `json:"-"`
 	CredentialsSourceValue        string                    `json:"-"`
+
+	// PromotionPolicyResult is set for deploy stage of environments having artifact promotion policies
+	PromotionPolicyResult *artifactPromotion.PromotionPolicyEvaluation `json:"promotionPolicyResult,omitempty"`
 }
 
 type CiArtifactResponse struct {
@@ -0,0 +1,651 @@
+package canaryAnalysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/common-lib/utils/k8s"
+	"github.com/devtron-labs/common-lib/utils/k8s/commonBean"
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/sql/repository/chartConfig"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/app/status"
+	"github.com/devtron-labs/devtron/pkg/canaryAnalysis/repository"
+	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	k8s2 "github.com/devtron-labs/devtron/pkg/k8s"
+	history "github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
+	"github.com/devtron-labs/devtron/pkg/prometheus"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	util2 "github.com/devtron-labs/devtron/util"
+	"github.com/go-pg/pg"
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+type CanaryAnalysisService interface {
+	// SaveConfig creates the canary analysis config of a pipeline or replaces the existing one
+	SaveConfig(request *CanaryAnalysisConfigDto) (*CanaryAnalysisConfigDto, error)
+	GetConfig(appId, pipelineId int) (*CanaryAnalysisConfigDto, error)
+	DeleteConfig(appId, pipelineId int, userId int32) error
+	GetAnalysisResults(appId, cdWorkflowRunnerId int) ([]*CanaryStepAnalysisDto, error)
+	// AnalyseCanaryDeployments evaluates the metrics of every canary deployment paused at a step, the rollout is
+	// promoted to the next step if all the metrics pass and aborted otherwise
+	AnalyseCanaryDeployments()
+}
+
+type CanaryAnalysisServiceConfig struct {
+	// MaxAnalysisDurationInMins bounds the age of the deployments considered for analysis
+	MaxAnalysisDurationInMins int `env:"CANARY_ANALYSIS_MAX_DURATION_MINS" envDefault:"720"`
+}
+
+type CanaryAnalysisServiceImpl struct {
+	logger                            *zap.SugaredLogger
+	config                            *CanaryAnalysisServiceConfig
+	canaryAnalysisConfigRepository    repository.CanaryAnalysisConfigRepository
+	canaryAnalysisResultRepository    repository.CanaryAnalysisResultRepository
+	pipelineRepository                pipelineConfig.PipelineRepository
+	cdWorkflowRepository              pipelineConfig.CdWorkflowRepository
+	pipelineOverrideRepository        chartConfig.PipelineOverrideRepository
+	pipelineStrategyHistoryRepository history.PipelineStrategyHistoryRepository
+	environmentRepository             repository2.EnvironmentRepository
+	k8sCommonService                  k8s2.K8sCommonService
+	k8sUtil                           *k8s.K8sUtil
+	pipelineStatusTimelineService     status.PipelineStatusTimelineService
+}
+
+func NewCanaryAnalysisServiceImpl(logger *zap.SugaredLogger,
+	canaryAnalysisConfigRepository repository.CanaryAnalysisConfigRepository,
+	canaryAnalysisResultRepository repository.CanaryAnalysisResultRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	pipelineOverrideRepository chartConfig.PipelineOverrideRepository,
+	pipelineStrategyHistoryRepository history.PipelineStrategyHistoryRepository,
+	environmentRepository repository2.EnvironmentRepository,
+	k8sCommonService k8s2.K8sCommonService,
+	k8sUtil *k8s.K8sUtil,
+	pipelineStatusTimelineService status.PipelineStatusTimelineService) *CanaryAnalysisServiceImpl {
+	config := &CanaryAnalysisServiceConfig{}
+	err := env.Parse(config)
+	if err != nil {
+		logger.Infow("error in parsing canary analysis config", "err", err)
+	}
+	return &CanaryAnalysisServiceImpl{
+		logger:                            logger,
+		config:                            config,
+		canaryAnalysisConfigRepository:    canaryAnalysisConfigRepository,
+		canaryAnalysisResultRepository:    canaryAnalysisResultRepository,
+		pipelineRepository:                pipelineRepository,
+		cdWorkflowRepository:              cdWorkflowRepository,
+		pipelineOverrideRepository:        pipelineOverrideRepository,
+		pipelineStrategyHistoryRepository: pipelineStrategyHistoryRepository,
+		environmentRepository:             environmentRepository,
+		k8sCommonService:                  k8sCommonService,
+		k8sUtil:                           k8sUtil,
+		pipelineStatusTimelineService:     pipelineStatusTimelineService,
+	}
+}
+
+// systemUserId is the user recorded on everything done by the analysis worker
+const systemUserId int32 = 1
+
+const rolloutVersion = "v1alpha1"
+
+const rolloutPauseReasonCanaryStep = "CanaryPauseStep"
+
+// terminal statuses of a deployment after which its canary is not analysed anymore
+var analysisSkippedStatuses = []string{pipelineConfig.WorkflowAborted, pipelineConfig.WorkflowFailed, pipelineConfig.WorkflowTimedOut, pipelineConfig.WorkflowInQueue, "Degraded"}
+
+func (impl *CanaryAnalysisServiceImpl) SaveConfig(request *CanaryAnalysisConfigDto) (*CanaryAnalysisConfigDto, error) {
+	_, err := impl.getPipelineOfApp(request.AppId, request.PipelineId)
+	if err != nil {
+		return nil, err
+	}
+	err = validateMetrics(request.Metrics)
+	if err != nil {
+		impl.logger.Errorw("invalid canary analysis metrics", "request", request, "err", err)
+		return nil, err
+	}
+	config, err := impl.canaryAnalysisConfigRepository.FindActiveByPipelineId(request.PipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching canary analysis config", "pipelineId", request.PipelineId, "err", err)
+		return nil, err
+	}
+	isNewConfig := err == pg.ErrNoRows
+	dbConnection := impl.canaryAnalysisConfigRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	if isNewConfig {
+		config = &repository.CanaryAnalysisConfig{
+			PipelineId:           request.PipelineId,
+			LookbackWindowInSecs: request.LookbackWindowInSecs,
+			Active:               true,
+			AuditLog:             sql.NewDefaultAuditLog(request.UserId),
+		}
+		err = impl.canaryAnalysisConfigRepository.SaveConfig(config, tx)
+	} else {
+		config.LookbackWindowInSecs = request.LookbackWindowInSecs
+		config.UpdatedOn = time.Now()
+		config.UpdatedBy = request.UserId
+		err = impl.canaryAnalysisConfigRepository.UpdateConfig(config, tx)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving canary analysis config", "config", config, "err", err)
+		return nil, err
+	}
+	err = impl.canaryAnalysisConfigRepository.DeactivateMetricsByConfigId(config.Id, request.UserId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deactivating canary analysis metrics", "configId", config.Id, "err", err)
+		return nil, err
+	}
+	metrics := make([]*repository.CanaryAnalysisMetric, 0, len(request.Metrics))
+	for _, metric := range request.Metrics {
+		metrics = append(metrics, &repository.CanaryAnalysisMetric{
+			CanaryAnalysisConfigId: config.Id,
+			Name:                   metric.Name,
+			Query:                  metric.Query,
+			Condition:              metric.Condition,
+			Threshold:              metric.Threshold,
+			Active:                 true,
+			AuditLog:               sql.NewDefaultAuditLog(request.UserId),
+		})
+	}
+	err = impl.canaryAnalysisConfigRepository.SaveMetrics(metrics, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving canary analysis metrics", "configId", config.Id, "err", err)
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	request.Id = config.Id
+	return request, nil
+}
+
+func (impl *CanaryAnalysisServiceImpl) GetConfig(appId, pipelineId int) (*CanaryAnalysisConfigDto, error) {
+	_, err := impl.getPipelineOfApp(appId, pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	config, err := impl.canaryAnalysisConfigRepository.FindActiveByPipelineId(pipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching canary analysis config", "pipelineId", pipelineId, "err", err)
+		return nil, err
+	}
+	metrics, err := impl.canaryAnalysisConfigRepository.FindActiveMetricsByConfigId(config.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching canary analysis metrics", "configId", config.Id, "err", err)
+		return nil, err
+	}
+	result := &CanaryAnalysisConfigDto{
+		Id:                   config.Id,
+		AppId:                appId,
+		PipelineId:           config.PipelineId,
+		LookbackWindowInSecs: config.LookbackWindowInSecs,
+		Metrics:              make([]*CanaryMetricDto, 0, len(metrics)),
+	}
+	for _, metric := range metrics {
+		result.Metrics = append(result.Metrics, &CanaryMetricDto{
+			Name:      metric.Name,
+			Query:     metric.Query,
+			Condition: metric.Condition,
+			Threshold: metric.Threshold,
+		})
+	}
+	return result, nil
+}
+
+func (impl *CanaryAnalysisServiceImpl) DeleteConfig(appId, pipelineId int, userId int32) error {
+	_, err := impl.getPipelineOfApp(appId, pipelineId)
+	if err != nil {
+		return err
+	}
+	config, err := impl.canaryAnalysisConfigRepository.FindActiveByPipelineId(pipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching canary analysis config", "pipelineId", pipelineId, "err", err)
+		return err
+	}
+	dbConnection := impl.canaryAnalysisConfigRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	config.Active = false
+	config.UpdatedOn = time.Now()
+	config.UpdatedBy = userId
+	err = impl.canaryAnalysisConfigRepository.UpdateConfig(config, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deleting canary analysis config", "pipelineId", pipelineId, "err", err)
+		return err
+	}
+	err = impl.canaryAnalysisConfigRepository.DeactivateMetricsByConfigId(config.Id, userId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deleting canary analysis metrics", "configId", config.Id, "err", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (impl *CanaryAnalysisServiceImpl) GetAnalysisResults(appId, cdWorkflowRunnerId int) ([]*CanaryStepAnalysisDto, error) {
+	runner, err := impl.cdWorkflowRepository.FindWorkflowRunnerById(cdWorkflowRunnerId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cd workflow runner", "wfrId", cdWorkflowRunnerId, "err", err)
+		return nil, err
+	}
+	if runner.CdWorkflow == nil || runner.CdWorkflow.Pipeline == nil || runner.CdWorkflow.Pipeline.AppId != appId {
+		return nil, &util.ApiError{
+			HttpStatusCode:  http.StatusNotFound,
+			InternalMessage: "deployment not found in app",
+			UserMessage:     fmt.Sprintf("deployment %d not found in app %d", cdWorkflowRunnerId, appId),
+		}
+	}
+	results, err := impl.canaryAnalysisResultRepository.FindByCdWorkflowRunnerId(cdWorkflowRunnerId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching canary analysis results", "wfrId", cdWorkflowRunnerId, "err", err)
+		return nil, err
+	}
+	return groupResultsByStep(results), nil
+}
+
+func (impl *CanaryAnalysisServiceImpl) getPipelineOfApp(appId, pipelineId int) (*pipelineConfig.Pipeline, error) {
+	pipeline, err := impl.pipelineRepository.FindById(pipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching pipeline", "pipelineId", pipelineId, "err", err)
+		return nil, err
+	}
+	if err == pg.ErrNoRows || pipeline.Deleted || pipeline.AppId != appId {
+		return nil, &util.ApiError{
+			HttpStatusCode:  http.StatusNotFound,
+			InternalMessage: "pipeline not found in app",
+			UserMessage:     fmt.Sprintf("pipeline %d not found in app %d", pipelineId, appId),
+		}
+	}
+	return pipeline, nil
+}
+
+func (impl *CanaryAnalysisServiceImpl) AnalyseCanaryDeployments() {
+	configs, err := impl.canaryAnalysisConfigRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching canary analysis configs", "err", err)
+		return
+	}
+	for _, config := range configs {
+		err = impl.analyseCanaryDeployment(config)
+		if err != nil {
+			impl.logger.Errorw("error in analysing canary deployment", "pipelineId", config.PipelineId, "err", err)
+		}
+	}
+}
+
+func (impl *CanaryAnalysisServiceImpl) analyseCanaryDeployment(config *repository.CanaryAnalysisConfig) error {
+	pipeline, err := impl.pipelineRepository.FindById(config.PipelineId)
+	if err != nil || pipeline.Deleted {
+		return err
+	}
+	runner, err := impl.cdWorkflowRepository.FindLastStatusByPipelineIdAndRunnerType(pipeline.Id, bean.CD_WORKFLOW_TYPE_DEPLOY)
+	if err == pg.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	maxDuration := time.Duration(impl.config.MaxAnalysisDurationInMins) * time.Minute
+	if util2.ContainsString(analysisSkippedStatuses, runner.Status) || time.Since(runner.StartedOn) > maxDuration {
+		return nil
+	}
+	strategyHistory, err := impl.pipelineStrategyHistoryRepository.GetHistoryByPipelineIdAndWfrId(pipeline.Id, runner.Id)
+	if err == pg.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if strategyHistory.Strategy != chartRepoRepository.DEPLOYMENT_STRATEGY_CANARY {
+		return nil
+	}
+	pipelineOverride, err := impl.pipelineOverrideRepository.FindLatestByCdWorkflowId(runner.CdWorkflowId)
+	if err != nil {
+		return err
+	}
+	environment, err := impl.environmentRepository.FindById(pipeline.EnvironmentId)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	restConfig, err, _ := impl.k8sCommonService.GetRestConfigByClusterId(ctx, environment.ClusterId)
+	if err != nil {
+		return err
+	}
+	rollout, err := impl.getRollout(ctx, restConfig, environment.Namespace, pipeline.DeploymentAppName)
+	if err != nil || rollout == nil {
+		return err
+	}
+	if rollout.releaseVersion != strconv.Itoa(pipelineOverride.PipelineReleaseCounter) || !rollout.isPausedAtCanaryStep() {
+		return nil
+	}
+	metrics, err := impl.canaryAnalysisConfigRepository.FindActiveMetricsByConfigId(config.Id)
+	if err != nil {
+		return err
+	}
+	if len(metrics) == 0 {
+		// the rollout is never promoted without a metric backing the decision
+		impl.logger.Warnw("skipping canary analysis, no metrics configured", "pipelineId", pipeline.Id, "configId", config.Id)
+		return nil
+	}
+	// analysing the step, recording its results and acting on the rollout is serialized per rollout, so that the step
+	// is analysed only once when several instances poll the same deployment
+	tx, err := impl.canaryAnalysisResultRepository.StartTx()
+	if err != nil {
+		return err
+	}
+	defer impl.canaryAnalysisResultRepository.RollbackTx(tx)
+	err = impl.canaryAnalysisResultRepository.LockAnalysis(runner.Id, tx)
+	if err != nil {
+		return err
+	}
+	isAnalysed, err := impl.canaryAnalysisResultRepository.ExistsByCdWorkflowRunnerIdAndStepIndex(runner.Id, rollout.currentStepIndex)
+	if err != nil || isAnalysed {
+		return err
+	}
+	placeholders := map[string]string{
+		QUERY_PLACEHOLDER_NAMESPACE: environment.Namespace,
+		QUERY_PLACEHOLDER_RELEASE:   pipeline.DeploymentAppName,
+		QUERY_PLACEHOLDER_WINDOW:    fmt.Sprintf("%ds", config.LookbackWindowInSecs),
+	}
+	var prometheusUrl string
+	if environment.Cluster != nil {
+		prometheusUrl = environment.Cluster.PrometheusEndpoint
+	}
+	results := impl.evaluateMetrics(ctx, environment.Name, prometheusUrl, metrics, placeholders, rollout)
+	for _, result := range results {
+		result.CdWorkflowRunnerId = runner.Id
+		result.StepIndex = rollout.currentStepIndex
+	}
+	err = impl.canaryAnalysisResultRepository.SaveResults(results, tx)
+	if err != nil {
+		return err
+	}
+	failedMetrics := getFailedMetricNames(results)
+	if len(failedMetrics) == 0 {
+		err = impl.patchRolloutStatus(ctx, restConfig, environment.Namespace, rollout.name, `{"status":{"pauseConditions":null}}`)
+		if err != nil {
+			return err
+		}
+		err = impl.canaryAnalysisResultRepository.CommitTx(tx)
+		if err != nil {
+			return err
+		}
+		impl.saveTimeline(runner.Id, pipelineConfig.TIMELINE_STATUS_CANARY_ANALYSIS_PASSED,
+			fmt.Sprintf("Canary analysis passed at step %d, rollout promoted.", rollout.currentStepIndex))
+		return nil
+	}
+	err = impl.patchRolloutStatus(ctx, restConfig, environment.Namespace, rollout.name, `{"status":{"abort":true}}`)
+	if err != nil {
+		return err
+	}
+	err = impl.canaryAnalysisResultRepository.CommitTx(tx)
+	if err != nil {
+		return err
+	}
+	statusDetail := fmt.Sprintf("Canary analysis failed at step %d for metrics %s, rollout aborted.", rollout.currentStepIndex, strings.Join(failedMetrics, ", "))
+	impl.saveTimeline(runner.Id, pipelineConfig.TIMELINE_STATUS_CANARY_ANALYSIS_FAILED, statusDetail)
+	runner.Status = pipelineConfig.WorkflowFailed
+	runner.Message = statusDetail
+	runner.FinishedOn = time.Now()
+	runner.UpdatedOn = time.Now()
+	runner.UpdatedBy = systemUserId
+	return impl.cdWorkflowRepository.UpdateWorkFlowRunner(&runner)
+}
+
+func (impl *CanaryAnalysisServiceImpl) evaluateMetrics(ctx context.Context, envName, prometheusUrl string,
+	metrics []*repository.CanaryAnalysisMetric, placeholders map[string]string, rollout *rolloutState) []*repository.CanaryAnalysisResult {
+	results := make([]*repository.CanaryAnalysisResult, 0, len(metrics))
+	for _, metric := range metrics {
+		result := &repository.CanaryAnalysisResult{
+			MetricName: metric.Name,
+			Condition:  metric.Condition,
+			Threshold:  metric.Threshold,
+			AuditLog:   sql.NewDefaultAuditLog(systemUserId),
+		}
+		result.Query = renderQuery(metric.Query, placeholders, rollout.currentPodHash)
+		results = append(results, result)
+		if len(prometheusUrl) == 0 {
+			result.Message = "prometheus endpoint is not configured for the cluster"
+			continue
+		}
+		var err error
+		result.Value, err = impl.queryValue(ctx, envName, prometheusUrl, result.Query)
+		if err == nil && metric.Condition == METRIC_CONDITION_BASELINE {
+			result.BaselineValue, err = impl.queryValue(ctx, envName, prometheusUrl, renderQuery(metric.Query, placeholders, rollout.stablePodHash))
+		}
+		if err != nil {
+			impl.logger.Errorw("error in querying canary metric", "metric", metric.Name, "query", result.Query, "err", err)
+			result.Message = err.Error()
+			continue
+		}
+		result.Passed, result.Message = evaluateCondition(metric.Condition, metric.Threshold, result.Value, result.BaselineValue)
+	}
+	return results
+}
+
+func (impl *CanaryAnalysisServiceImpl) queryValue(ctx context.Context, envName, prometheusUrl, query string) (*float64, error) {
+	prometheusAPI, err := prometheus.ContextByEnv(envName, prometheusUrl)
+	if err != nil {
+		return nil, err
+	}
+	value, _, err := prometheusAPI.Query(ctx, query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return extractSampleValue(value)
+}
+
+func (impl *CanaryAnalysisServiceImpl) getRollout(ctx context.Context, restConfig *rest.Config, namespace, releaseName string) (*rolloutState, error) {
+	resourceIf, _, err := impl.k8sUtil.GetResourceIf(restConfig, rolloutGroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	rollouts, err := resourceIf.Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: "release=" + releaseName})
+	if err != nil {
+		return nil, err
+	}
+	if len(rollouts.Items) == 0 {
+		return nil, nil
+	}
+	return newRolloutState(rollouts.Items[0].Object), nil
+}
+
+func (impl *CanaryAnalysisServiceImpl) patchRolloutStatus(ctx context.Context, restConfig *rest.Config, namespace, name, patch string) error {
+	resourceIf, _, err := impl.k8sUtil.GetResourceIf(restConfig, rolloutGroupVersionKind())
+	if err != nil {
+		return err
+	}
+	// rollouts are promoted and aborted through the status sub resource, the same way the argo rollouts cli does
+	_, err = resourceIf.Namespace(namespace).Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{}, "status")
+	return err
+}
+
+func (impl *CanaryAnalysisServiceImpl) saveTimeline(cdWorkflowRunnerId int, timelineStatus pipelineConfig.TimelineStatus, statusDetail string) {
+	timeline := impl.pipelineStatusTimelineService.GetTimelineDbObjectByTimelineStatusAndTimelineDescription(cdWorkflowRunnerId, 0, timelineStatus, statusDetail, systemUserId, time.Now())
+	err := impl.pipelineStatusTimelineService.SaveTimeline(timeline, nil, false)
+	if err != nil {
+		impl.logger.Errorw("error in saving canary analysis timeline", "wfrId", cdWorkflowRunnerId, "status", timelineStatus, "err", err)
+	}
+}
+
+func rolloutGroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: commonBean.K8sClusterResourceRolloutGroup, Version: rolloutVersion, Kind: commonBean.K8sClusterResourceRolloutKind}
+}
+
+// rolloutState is the part of an argo rollout relevant for the analysis
+type rolloutState struct {
+	name             string
+	releaseVersion   string
+	currentStepIndex int
+	currentPodHash   string
+	stablePodHash    string
+	pauseReasons     []string
+	isAborted        bool
+}
+
+func newRolloutState(object map[string]interface{}) *rolloutState {
+	rollout := &rolloutState{}
+	rollout.name, _, _ = unstructured.NestedString(object, "metadata", "name")
+	rollout.releaseVersion, _, _ = unstructured.NestedString(object, "metadata", "labels", "releaseVersion")
+	currentStepIndex, _, _ := unstructured.NestedInt64(object, "status", "currentStepIndex")
+	rollout.currentStepIndex = int(currentStepIndex)
+	rollout.currentPodHash, _, _ = unstructured.NestedString(object, "status", "currentPodHash")
+	rollout.stablePodHash, _, _ = unstructured.NestedString(object, "status", "stableRS")
+	rollout.isAborted, _, _ = unstructured.NestedBool(object, "status", "abort")
+	pauseConditions, _, _ := unstructured.NestedSlice(object, "status", "pauseConditions")
+	for _, pauseCondition := range pauseConditions {
+		if condition, ok := pauseCondition.(map[string]interface{}); ok {
+			if reason, ok := condition["reason"].(string); ok {
+				rollout.pauseReasons = append(rollout.pauseReasons, reason)
+			}
+		}
+	}
+	return rollout
+}
+
+func (rollout *rolloutState) isPausedAtCanaryStep() bool {
+	return !rollout.isAborted && len(rollout.currentPodHash) > 0 && util2.ContainsString(rollout.pauseReasons, rolloutPauseReasonCanaryStep)
+}
+
+func renderQuery(query string, placeholders map[string]string, podHash string) string {
+	replacements := []string{QUERY_PLACEHOLDER_POD_HASH, podHash}
+	for placeholder, value := range placeholders {
+		replacements = append(replacements, placeholder, value)
+	}
+	return strings.NewReplacer(replacements...).Replace(query)
+}
+
+// extractSampleValue returns the value of a query which evaluates to a scalar or a single sample, nil if there is no data
+func extractSampleValue(value model.Value) (*float64, error) {
+	var sample float64
+	switch v := value.(type) {
+	case *model.Scalar:
+		sample = float64(v.Value)
+	case model.Vector:
+		if len(v) == 0 {
+			return nil, nil
+		} else if len(v) > 1 {
+			return nil, fmt.Errorf("query returned %d series, expected a single series", len(v))
+		}
+		sample = float64(v[0].Value)
+	default:
+		return nil, fmt.Errorf("unsupported query result type %s", value.Type())
+	}
+	if math.IsNaN(sample) {
+		return nil, nil
+	}
+	return &sample, nil
+}
+
+// evaluateCondition checks value against the threshold of the condition, missing data always fails the analysis
+func evaluateCondition(condition MetricCondition, threshold float64, value *float64, baselineValue *float64) (bool, string) {
+	if value == nil {
+		return false, "no data returned by query"
+	}
+	switch condition {
+	case METRIC_CONDITION_LESS_THAN:
+		if *value < threshold {
+			return true, ""
+		}
+		return false, fmt.Sprintf("value %g is not less than %g", *value, threshold)
+	case METRIC_CONDITION_GREATER_THAN:
+		if *value > threshold {
+			return true, ""
+		}
+		return false, fmt.Sprintf("value %g is not greater than %g", *value, threshold)
+	case METRIC_CONDITION_BASELINE:
+		if baselineValue == nil {
+			return false, "no data returned by query for baseline"
+		}
+		maxValue := *baselineValue * (1 + threshold/100)
+		if *value <= maxValue {
+			return true, ""
+		}
+		return false, fmt.Sprintf("value %g exceeds baseline %g by more than %g%%", *value, *baselineValue, threshold)
+	}
+	return false, fmt.Sprintf("unknown condition %s", condition)
+}
+
+func getFailedMetricNames(results []*repository.CanaryAnalysisResult) []string {
+	var failedMetrics []string
+	for _, result := range results {
+		if !result.Passed {
+			failedMetrics = append(failedMetrics, result.MetricName)
+		}
+	}
+	return failedMetrics
+}
+
+func groupResultsByStep(results []*repository.CanaryAnalysisResult) []*CanaryStepAnalysisDto {
+	steps := make([]*CanaryStepAnalysisDto, 0)
+	var step *CanaryStepAnalysisDto
+	for _, result := range results {
+		if step == nil || step.StepIndex != result.StepIndex {
+			step = &CanaryStepAnalysisDto{StepIndex: result.StepIndex, Passed: true, EvaluatedOn: result.CreatedOn}
+			steps = append(steps, step)
+		}
+		step.Passed = step.Passed && result.Passed
+		step.Metrics = append(step.Metrics, &CanaryMetricResultDto{
+			MetricName:    result.MetricName,
+			Query:         result.Query,
+			Condition:     result.Condition,
+			Threshold:     result.Threshold,
+			Value:         result.Value,
+			BaselineValue: result.BaselineValue,
+			Passed:        result.Passed,
+			Message:       result.Message,
+		})
+	}
+	return steps
+}
+
+func validateMetrics(metrics []*CanaryMetricDto) error {
+	if len(metrics) == 0 {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: "no metric configured",
+			UserMessage:     "at least one metric is required for canary analysis",
+		}
+	}
+	names := make(map[string]bool, len(metrics))
+	for _, metric := range metrics {
+		if names[metric.Name] {
+			return &util.ApiError{
+				HttpStatusCode:  http.StatusBadRequest,
+				InternalMessage: "duplicate metric name",
+				UserMessage:     fmt.Sprintf("metric %s is configured more than once", metric.Name),
+			}
+		}
+		names[metric.Name] = true
+		if metric.Condition == METRIC_CONDITION_BASELINE && !strings.Contains(metric.Query, QUERY_PLACEHOLDER_POD_HASH) {
+			return &util.ApiError{
+				HttpStatusCode:  http.StatusBadRequest,
+				InternalMessage: "baseline metric query without pod hash",
+				UserMessage:     fmt.Sprintf("query of baseline metric %s must use %s to tell canary and stable pods apart", metric.Name, QUERY_PLACEHOLDER_POD_HASH),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package canaryAnalysis
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name          string
+		condition     MetricCondition
+		threshold     float64
+		value         *float64
+		baselineValue *float64
+		wantPassed    bool
+	}{
+		{name: "less than passes", condition: METRIC_CONDITION_LESS_THAN, threshold: 0.05, value: value(0.01), wantPassed: true},
+		{name: "less than fails on equal", condition: METRIC_CONDITION_LESS_THAN, threshold: 0.05, value: value(0.05), wantPassed: false},
+		{name: "greater than passes", condition: METRIC_CONDITION_GREATER_THAN, threshold: 0.99, value: value(0.999), wantPassed: true},
+		{name: "no data fails", condition: METRIC_CONDITION_LESS_THAN, threshold: 0.05, value: nil, wantPassed: false},
+		{name: "baseline within deviation", condition: METRIC_CONDITION_BASELINE, threshold: 10, value: value(105), baselineValue: value(100), wantPassed: true},
+		{name: "baseline beyond deviation", condition: METRIC_CONDITION_BASELINE, threshold: 10, value: value(111), baselineValue: value(100), wantPassed: false},
+		{name: "baseline without data", condition: METRIC_CONDITION_BASELINE, threshold: 10, value: value(100), wantPassed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passed, message := evaluateCondition(tt.condition, tt.threshold, tt.value, tt.baselineValue)
+			if passed != tt.wantPassed {
+				t.Errorf("evaluateCondition() = %v (%s), want %v", passed, message, tt.wantPassed)
+			}
+		})
+	}
+}
+
+func TestExtractSampleValue(t *testing.T) {
+	got, err := extractSampleValue(model.Vector{&model.Sample{Value: 0.5}})
+	if err != nil || got == nil || *got != 0.5 {
+		t.Errorf("extractSampleValue() single sample = %v, %v", got, err)
+	}
+	got, err = extractSampleValue(model.Vector{})
+	if err != nil || got != nil {
+		t.Errorf("extractSampleValue() empty vector = %v, %v", got, err)
+	}
+	_, err = extractSampleValue(model.Vector{&model.Sample{Value: 1}, &model.Sample{Value: 2}})
+	if err == nil {
+		t.Errorf("extractSampleValue() expected error for multiple series")
+	}
+}
+
+func TestRolloutState(t *testing.T) {
+	object := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":   "app-prod",
+			"labels": map[string]interface{}{"releaseVersion": "4"},
+		},
+		"status": map[string]interface{}{
+			"currentStepIndex": int64(2),
+			"currentPodHash":   "abc",
+			"stableRS":         "xyz",
+			"pauseConditions":  []interface{}{map[string]interface{}{"reason": "CanaryPauseStep"}},
+		},
+	}
+	rollout := newRolloutState(object)
+	if rollout.name != "app-prod" || rollout.releaseVersion != "4" || rollout.currentStepIndex != 2 || rollout.stablePodHash != "xyz" {
+		t.Errorf("newRolloutState() = %+v", rollout)
+	}
+	if !rollout.isPausedAtCanaryStep() {
+		t.Errorf("isPausedAtCanaryStep() = false, want true")
+	}
+	query := renderQuery(`sum(rate(errors{namespace="{{namespace}}",rollouts_pod_template_hash="{{podHash}}"}[{{window}}]))`,
+		map[string]string{QUERY_PLACEHOLDER_NAMESPACE: "prod", QUERY_PLACEHOLDER_WINDOW: "300s"}, rollout.stablePodHash)
+	want := `sum(rate(errors{namespace="prod",rollouts_pod_template_hash="xyz"}[300s]))`
+	if query != want {
+		t.Errorf("renderQuery() = %s, want %s", query, want)
+	}
+}
+
+func TestValidateMetrics(t *testing.T) {
+	errorRate := &CanaryMetricDto{Name: "error-rate", Query: "rate(errors[{{window}}])", Condition: METRIC_CONDITION_LESS_THAN, Threshold: 0.05}
+	tests := []struct {
+		name    string
+		metrics []*CanaryMetricDto
+		wantErr bool
+	}{
+		{name: "no metrics", metrics: nil, wantErr: true},
+		{name: "single metric", metrics: []*CanaryMetricDto{errorRate}},
+		{name: "duplicate metric", metrics: []*CanaryMetricDto{errorRate, errorRate}, wantErr: true},
+		{name: "baseline without pod hash", metrics: []*CanaryMetricDto{{Name: "latency", Query: "latency", Condition: METRIC_CONDITION_BASELINE, Threshold: 10}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateMetrics(tt.metrics); (err != nil) != tt.wantErr {
+				t.Errorf("validateMetrics() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
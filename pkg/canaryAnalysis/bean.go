@@ -0,0 +1,62 @@
+package canaryAnalysis
+
+import "time"
+
+type MetricCondition = string
+
+const (
+	// METRIC_CONDITION_LESS_THAN passes when the value of the canary is less than the threshold
+	METRIC_CONDITION_LESS_THAN MetricCondition = "LESS_THAN"
+	// METRIC_CONDITION_GREATER_THAN passes when the value of the canary is greater than the threshold
+	METRIC_CONDITION_GREATER_THAN MetricCondition = "GREATER_THAN"
+	// METRIC_CONDITION_BASELINE passes when the value of the canary exceeds the value of the stable pods by at most
+	// threshold percent
+	METRIC_CONDITION_BASELINE MetricCondition = "BASELINE"
+)
+
+// placeholders which can be used in the query templates of metrics
+const (
+	QUERY_PLACEHOLDER_NAMESPACE = "{{namespace}}"
+	QUERY_PLACEHOLDER_RELEASE   = "{{release}}"
+	// QUERY_PLACEHOLDER_POD_HASH is the pod template hash of the canary, it is replaced by the hash of the stable
+	// pods while querying the baseline
+	QUERY_PLACEHOLDER_POD_HASH = "{{podHash}}"
+	QUERY_PLACEHOLDER_WINDOW   = "{{window}}"
+)
+
+// CanaryAnalysisConfigDto configures the metrics analysed at every canary step of a pipeline. Analysis runs while the
+// rollout is paused at a step, so the canary steps should pause indefinitely for the analysis to gate the promotion.
+type CanaryAnalysisConfigDto struct {
+	Id                   int                `json:"id"`
+	AppId                int                `json:"appId" validate:"number,min=1"`
+	PipelineId           int                `json:"pipelineId" validate:"number,min=1"`
+	LookbackWindowInSecs int                `json:"lookbackWindowInSecs" validate:"number,min=1"`
+	Metrics              []*CanaryMetricDto `json:"metrics" validate:"required,min=1,dive"`
+	UserId               int32              `json:"-"`
+}
+
+type CanaryMetricDto struct {
+	Name      string          `json:"name" validate:"required,max=250"`
+	Query     string          `json:"query" validate:"required"`
+	Condition MetricCondition `json:"condition" validate:"oneof=LESS_THAN GREATER_THAN BASELINE"`
+	Threshold float64         `json:"threshold"`
+}
+
+// CanaryStepAnalysisDto is the analysis of all the metrics at one canary step of a deployment
+type CanaryStepAnalysisDto struct {
+	StepIndex   int                      `json:"stepIndex"`
+	Passed      bool                     `json:"passed"`
+	EvaluatedOn time.Time                `json:"evaluatedOn"`
+	Metrics     []*CanaryMetricResultDto `json:"metrics"`
+}
+
+type CanaryMetricResultDto struct {
+	MetricName    string          `json:"metricName"`
+	Query         string          `json:"query"`
+	Condition     MetricCondition `json:"condition"`
+	Threshold     float64         `json:"threshold"`
+	Value         *float64        `json:"value"`
+	BaselineValue *float64        `json:"baselineValue,omitempty"`
+	Passed        bool            `json:"passed"`
+	Message       string          `json:"message,omitempty"`
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// CanaryAnalysisConfig enables metric analysis on the canary steps of a cd pipeline deployed with the canary strategy
+type CanaryAnalysisConfig struct {
+	tableName            struct{} `sql:"canary_analysis_config" pg:",discard_unknown_columns"`
+	Id                   int      `sql:"id,pk"`
+	PipelineId           int      `sql:"pipeline_id,notnull"`
+	LookbackWindowInSecs int      `sql:"lookback_window_in_secs,notnull"`
+	Active               bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// CanaryAnalysisMetric is a PromQL query template evaluated at every canary step along with the condition its value must satisfy
+type CanaryAnalysisMetric struct {
+	tableName              struct{} `sql:"canary_analysis_metric" pg:",discard_unknown_columns"`
+	Id                     int      `sql:"id,pk"`
+	CanaryAnalysisConfigId int      `sql:"canary_analysis_config_id,notnull"`
+	Name                   string   `sql:"name,notnull"`
+	Query                  string   `sql:"query,notnull"`
+	Condition              string   `sql:"condition,notnull"`
+	Threshold              float64  `sql:"threshold,notnull"`
+	Active                 bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type CanaryAnalysisConfigRepository interface {
+	GetConnection() *pg.DB
+	SaveConfig(config *CanaryAnalysisConfig, tx *pg.Tx) error
+	UpdateConfig(config *CanaryAnalysisConfig, tx *pg.Tx) error
+	FindActiveByPipelineId(pipelineId int) (*CanaryAnalysisConfig, error)
+	FindAllActive() ([]*CanaryAnalysisConfig, error)
+	SaveMetrics(metrics []*CanaryAnalysisMetric, tx *pg.Tx) error
+	DeactivateMetricsByConfigId(configId int, userId int32, tx *pg.Tx) error
+	FindActiveMetricsByConfigId(configId int) ([]*CanaryAnalysisMetric, error)
+}
+
+type CanaryAnalysisConfigRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewCanaryAnalysisConfigRepositoryImpl(dbConnection *pg.DB) *CanaryAnalysisConfigRepositoryImpl {
+	return &CanaryAnalysisConfigRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *CanaryAnalysisConfigRepositoryImpl) GetConnection() *pg.DB {
+	return impl.dbConnection
+}
+
+func (impl *CanaryAnalysisConfigRepositoryImpl) SaveConfig(config *CanaryAnalysisConfig, tx *pg.Tx) error {
+	return tx.Insert(config)
+}
+
+func (impl *CanaryAnalysisConfigRepositoryImpl) UpdateConfig(config *CanaryAnalysisConfig, tx *pg.Tx) error {
+	return tx.Update(config)
+}
+
+func (impl *CanaryAnalysisConfigRepositoryImpl) FindActiveByPipelineId(pipelineId int) (*CanaryAnalysisConfig, error) {
+	config := &CanaryAnalysisConfig{}
+	err := impl.dbConnection.Model(config).
+		Where("pipeline_id = ?", pipelineId).
+		Where("active = ?", true).
+		Select()
+	return config, err
+}
+
+func (impl *CanaryAnalysisConfigRepositoryImpl) FindAllActive() ([]*CanaryAnalysisConfig, error) {
+	var configs []*CanaryAnalysisConfig
+	err := impl.dbConnection.Model(&configs).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return configs, err
+}
+
+func (impl *CanaryAnalysisConfigRepositoryImpl) SaveMetrics(metrics []*CanaryAnalysisMetric, tx *pg.Tx) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&metrics).Insert()
+	return err
+}
+
+func (impl *CanaryAnalysisConfigRepositoryImpl) DeactivateMetricsByConfigId(configId int, userId int32, tx *pg.Tx) error {
+	_, err := tx.Model(&CanaryAnalysisMetric{}).
+		Set("active = ?", false).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", userId).
+		Where("canary_analysis_config_id = ?", configId).
+		Where("active = ?", true).
+		Update()
+	return err
+}
+
+func (impl *CanaryAnalysisConfigRepositoryImpl) FindActiveMetricsByConfigId(configId int) ([]*CanaryAnalysisMetric, error) {
+	var metrics []*CanaryAnalysisMetric
+	err := impl.dbConnection.Model(&metrics).
+		Where("canary_analysis_config_id = ?", configId).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return metrics, err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// CanaryAnalysisResult is the outcome of evaluating one metric at one canary step of a deployment
+type CanaryAnalysisResult struct {
+	tableName          struct{} `sql:"canary_analysis_result" pg:",discard_unknown_columns"`
+	Id                 int      `sql:"id,pk"`
+	CdWorkflowRunnerId int      `sql:"cd_workflow_runner_id,notnull"`
+	StepIndex          int      `sql:"step_index,notnull"`
+	MetricName         string   `sql:"metric_name,notnull"`
+	Query              string   `sql:"query,notnull"`
+	Condition          string   `sql:"condition,notnull"`
+	Threshold          float64  `sql:"threshold,notnull"`
+	Value              *float64 `sql:"value"`
+	BaselineValue      *float64 `sql:"baseline_value"`
+	Passed             bool     `sql:"passed,notnull"`
+	Message            string   `sql:"message"`
+	sql.AuditLog
+}
+
+// canaryAnalysisLockKey is the postgres advisory lock class key serializing the analysis of a canary step, it is paired
+// with the id of the deploy runner so that the rollouts of different deployments are analysed independently
+const canaryAnalysisLockKey = 20240801
+
+type CanaryAnalysisResultRepository interface {
+	sql.TransactionWrapper
+	// LockAnalysis takes the advisory lock of the rollout of the deploy runner for the duration of tx
+	LockAnalysis(cdWorkflowRunnerId int, tx *pg.Tx) error
+	SaveResults(results []*CanaryAnalysisResult, tx *pg.Tx) error
+	FindByCdWorkflowRunnerId(cdWorkflowRunnerId int) ([]*CanaryAnalysisResult, error)
+	ExistsByCdWorkflowRunnerIdAndStepIndex(cdWorkflowRunnerId, stepIndex int) (bool, error)
+}
+
+type CanaryAnalysisResultRepositoryImpl struct {
+	*sql.TransactionUtilImpl
+	dbConnection *pg.DB
+}
+
+func NewCanaryAnalysisResultRepositoryImpl(dbConnection *pg.DB) *CanaryAnalysisResultRepositoryImpl {
+	return &CanaryAnalysisResultRepositoryImpl{
+		TransactionUtilImpl: sql.NewTransactionUtilImpl(dbConnection),
+		dbConnection:        dbConnection,
+	}
+}
+
+func (impl *CanaryAnalysisResultRepositoryImpl) LockAnalysis(cdWorkflowRunnerId int, tx *pg.Tx) error {
+	_, err := tx.Exec("SELECT pg_advisory_xact_lock(?, ?);", canaryAnalysisLockKey, cdWorkflowRunnerId)
+	return err
+}
+
+func (impl *CanaryAnalysisResultRepositoryImpl) SaveResults(results []*CanaryAnalysisResult, tx *pg.Tx) error {
+	if len(results) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&results).Insert()
+	return err
+}
+
+func (impl *CanaryAnalysisResultRepositoryImpl) FindByCdWorkflowRunnerId(cdWorkflowRunnerId int) ([]*CanaryAnalysisResult, error) {
+	var results []*CanaryAnalysisResult
+	err := impl.dbConnection.Model(&results).
+		Where("cd_workflow_runner_id = ?", cdWorkflowRunnerId).
+		Order("step_index ASC").
+		Order("id ASC").
+		Select()
+	return results, err
+}
+
+func (impl *CanaryAnalysisResultRepositoryImpl) ExistsByCdWorkflowRunnerIdAndStepIndex(cdWorkflowRunnerId, stepIndex int) (bool, error) {
+	return impl.dbConnection.Model(&CanaryAnalysisResult{}).
+		Where("cd_workflow_runner_id = ?", cdWorkflowRunnerId).
+		Where("step_index = ?", stepIndex).
+		Exists()
+}
@@ -0,0 +1,553 @@
+package ciBuildQueue
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/ciBuildQueue/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/team"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type CiBuildQueueConfig struct {
+	// MaxConcurrentBuilds caps the ci builds running at the same time across all projects, 0 for no cap
+	MaxConcurrentBuilds int `env:"CI_BUILD_QUEUE_MAX_CONCURRENT_BUILDS" envDefault:"0"`
+	// RunningTimeoutMins is the time after which a build still not in a terminal state is no longer counted as running,
+	// so that a build stuck in running does not hold the queue of its project forever
+	RunningTimeoutMins int `env:"CI_BUILD_QUEUE_RUNNING_TIMEOUT" envDefault:"120"`
+}
+
+type CiBuildQueueService interface {
+	CreateQuota(request *CiBuildQuotaDto) (*CiBuildQuotaDto, error)
+	UpdateQuota(request *CiBuildQuotaDto) (*CiBuildQuotaDto, error)
+	DeleteQuota(id int, userId int32) error
+	GetAllQuotas() ([]*CiBuildQuotaDto, error)
+	SavePipelinePriority(request *CiPipelineBuildPriorityDto) (*CiPipelineBuildPriorityDto, error)
+	// GetPipelinePriority returns the priority class builds of the ci pipeline are queued with by default
+	GetPipelinePriority(ciPipelineId int) (*CiPipelineBuildPriorityDto, error)
+	// QueueBuildIfQuotaReached adds the build to the queue if the build quota of its project or the global build limit
+	// is reached, or builds ahead of it are already waiting. It returns the position of the build in the queue, 0 if the
+	// build can be submitted right away.
+	QueueBuildIfQuotaReached(request *QueueBuildRequest) (int, error)
+	// GetQueuedBuilds returns the builds waiting in queue with their positions, filtered on project if non-zero
+	GetQueuedBuilds(teamId int) ([]*QueuedBuildDto, error)
+	// GetQueuePositions returns the queue position of the given ci workflows, workflows not waiting in queue are absent
+	GetQueuePositions(ciWorkflowIds []int) (map[int]int, error)
+	// ReleaseBuilds takes out of the queue, in the order they are to be submitted, the queued builds for which capacity
+	// is available now. The ci workflows of the returned builds are moved to starting, their workflow requests are to be
+	// built from the trigger inputs and submitted by the caller.
+	ReleaseBuilds() ([]*repository.CiBuildQueue, error)
+	// DropQueuedBuild removes the build of the ci workflow from the queue, it returns false if the build was not
+	// waiting in queue
+	DropQueuedBuild(ciWorkflowId int, message string) (bool, error)
+}
+
+type CiBuildQueueServiceImpl struct {
+	logger                 *zap.SugaredLogger
+	ciBuildQuotaRepository repository.CiBuildQuotaRepository
+	ciBuildQueueRepository repository.CiBuildQueueRepository
+	teamRepository         team.TeamRepository
+	config                 *CiBuildQueueConfig
+}
+
+func NewCiBuildQueueServiceImpl(logger *zap.SugaredLogger,
+	ciBuildQuotaRepository repository.CiBuildQuotaRepository,
+	ciBuildQueueRepository repository.CiBuildQueueRepository,
+	teamRepository team.TeamRepository) *CiBuildQueueServiceImpl {
+	cfg := &CiBuildQueueConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Infow("error occurred while parsing CiBuildQueueConfig, so setting build queue config to default values", "err", err)
+		cfg.MaxConcurrentBuilds = 0
+		cfg.RunningTimeoutMins = 120
+	}
+	return &CiBuildQueueServiceImpl{
+		logger:                 logger,
+		ciBuildQuotaRepository: ciBuildQuotaRepository,
+		ciBuildQueueRepository: ciBuildQueueRepository,
+		teamRepository:         teamRepository,
+		config:                 cfg,
+	}
+}
+
+// runningStatuses are the ci workflow statuses for which the build is still running on the build cluster
+var runningStatuses = []string{pipelineConfig.WorkflowStarting, string(v1alpha1.NodePending), string(v1alpha1.NodeRunning)}
+
+func (impl *CiBuildQueueServiceImpl) CreateQuota(request *CiBuildQuotaDto) (*CiBuildQuotaDto, error) {
+	err := impl.validateQuota(request)
+	if err != nil {
+		impl.logger.Errorw("invalid ci build quota", "request", request, "err", err)
+		return nil, err
+	}
+	quota := &repository.CiBuildQuota{
+		TeamId:              request.TeamId,
+		MaxConcurrentBuilds: request.MaxConcurrentBuilds,
+		Active:              true,
+		AuditLog:            sql.NewDefaultAuditLog(request.UserId),
+	}
+	err = impl.ciBuildQuotaRepository.Save(quota)
+	if err != nil {
+		impl.logger.Errorw("error in saving ci build quota", "quota", quota, "err", err)
+		return nil, err
+	}
+	request.Id = quota.Id
+	return request, nil
+}
+
+func (impl *CiBuildQueueServiceImpl) UpdateQuota(request *CiBuildQuotaDto) (*CiBuildQuotaDto, error) {
+	err := impl.validateQuota(request)
+	if err != nil {
+		impl.logger.Errorw("invalid ci build quota", "request", request, "err", err)
+		return nil, err
+	}
+	quota, err := impl.ciBuildQuotaRepository.FindById(request.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci build quota", "id", request.Id, "err", err)
+		return nil, err
+	}
+	quota.TeamId = request.TeamId
+	quota.MaxConcurrentBuilds = request.MaxConcurrentBuilds
+	quota.UpdatedOn = time.Now()
+	quota.UpdatedBy = request.UserId
+	err = impl.ciBuildQuotaRepository.Update(quota)
+	if err != nil {
+		impl.logger.Errorw("error in updating ci build quota", "quota", quota, "err", err)
+		return nil, err
+	}
+	return request, nil
+}
+
+func (impl *CiBuildQueueServiceImpl) DeleteQuota(id int, userId int32) error {
+	quota, err := impl.ciBuildQuotaRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci build quota", "id", id, "err", err)
+		return err
+	}
+	quota.Active = false
+	quota.UpdatedOn = time.Now()
+	quota.UpdatedBy = userId
+	err = impl.ciBuildQuotaRepository.Update(quota)
+	if err != nil {
+		impl.logger.Errorw("error in deleting ci build quota", "id", id, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *CiBuildQueueServiceImpl) GetAllQuotas() ([]*CiBuildQuotaDto, error) {
+	quotas, err := impl.ciBuildQuotaRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci build quotas", "err", err)
+		return nil, err
+	}
+	result := make([]*CiBuildQuotaDto, 0, len(quotas))
+	for _, quota := range quotas {
+		result = append(result, &CiBuildQuotaDto{
+			Id:                  quota.Id,
+			TeamId:              quota.TeamId,
+			MaxConcurrentBuilds: quota.MaxConcurrentBuilds,
+		})
+	}
+	return result, nil
+}
+
+func (impl *CiBuildQueueServiceImpl) SavePipelinePriority(request *CiPipelineBuildPriorityDto) (*CiPipelineBuildPriorityDto, error) {
+	if !IsValidPriorityClass(request.PriorityClass) {
+		validationErr := fmt.Sprintf("invalid priority class %q", request.PriorityClass)
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: validationErr, UserMessage: validationErr}
+	}
+	priority, err := impl.ciBuildQuotaRepository.FindPipelinePriorityByCiPipelineId(request.CiPipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching ci pipeline build priority", "ciPipelineId", request.CiPipelineId, "err", err)
+		return nil, err
+	}
+	if err == pg.ErrNoRows {
+		priority = &repository.CiPipelineBuildPriority{
+			CiPipelineId:  request.CiPipelineId,
+			PriorityClass: request.PriorityClass,
+			AuditLog:      sql.NewDefaultAuditLog(request.UserId),
+		}
+		err = impl.ciBuildQuotaRepository.SavePipelinePriority(priority)
+	} else {
+		priority.PriorityClass = request.PriorityClass
+		priority.UpdatedOn = time.Now()
+		priority.UpdatedBy = request.UserId
+		err = impl.ciBuildQuotaRepository.UpdatePipelinePriority(priority)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving ci pipeline build priority", "ciPipelineId", request.CiPipelineId, "err", err)
+		return nil, err
+	}
+	return request, nil
+}
+
+func (impl *CiBuildQueueServiceImpl) GetPipelinePriority(ciPipelineId int) (*CiPipelineBuildPriorityDto, error) {
+	result := &CiPipelineBuildPriorityDto{CiPipelineId: ciPipelineId, PriorityClass: CI_BUILD_PRIORITY_NORMAL}
+	priority, err := impl.ciBuildQuotaRepository.FindPipelinePriorityByCiPipelineId(ciPipelineId)
+	if err == pg.ErrNoRows {
+		return result, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching ci pipeline build priority", "ciPipelineId", ciPipelineId, "err", err)
+		return nil, err
+	}
+	result.PriorityClass = priority.PriorityClass
+	return result, nil
+}
+
+func (impl *CiBuildQueueServiceImpl) QueueBuildIfQuotaReached(request *QueueBuildRequest) (int, error) {
+	limits, err := impl.getBuildLimits()
+	if err != nil {
+		return 0, err
+	}
+	if !limits.isLimited(request.TeamId) {
+		return 0, nil
+	}
+	priorityClass := request.PriorityClass
+	if len(priorityClass) == 0 {
+		pipelinePriority, err := impl.GetPipelinePriority(request.CiPipelineId)
+		if err != nil {
+			return 0, err
+		}
+		priorityClass = pipelinePriority.PriorityClass
+	} else if !IsValidPriorityClass(priorityClass) {
+		validationErr := fmt.Sprintf("invalid priority class %q", priorityClass)
+		return 0, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: validationErr, UserMessage: validationErr}
+	}
+	triggerInputs, err := json.Marshal(request.TriggerInputs)
+	if err != nil {
+		impl.logger.Errorw("error in marshalling trigger inputs", "ciWorkflowId", request.CiWorkflowId, "err", err)
+		return 0, err
+	}
+	tx, err := impl.ciBuildQueueRepository.StartTx()
+	if err != nil {
+		impl.logger.Errorw("error in starting transaction", "err", err)
+		return 0, err
+	}
+	defer impl.ciBuildQueueRepository.RollbackTx(tx)
+	// running builds are counted and the build queued under the admission lock so that concurrent triggers do not
+	// all see the same free capacity
+	err = impl.ciBuildQueueRepository.LockAdmission(tx)
+	if err != nil {
+		impl.logger.Errorw("error in taking ci build admission lock", "ciWorkflowId", request.CiWorkflowId, "err", err)
+		return 0, err
+	}
+	queued, err := impl.ciBuildQueueRepository.FindAllQueued()
+	if err != nil {
+		impl.logger.Errorw("error in fetching queued ci builds", "err", err)
+		return 0, err
+	}
+	running, err := impl.getRunningBuilds()
+	if err != nil {
+		return 0, err
+	}
+	// the workflow of this build is already saved and counted as running
+	running.add(request.TeamId, -1)
+	item := &repository.CiBuildQueue{
+		CiWorkflowId:  request.CiWorkflowId,
+		CiPipelineId:  request.CiPipelineId,
+		TeamId:        request.TeamId,
+		PriorityClass: priorityClass,
+		Status:        repository.CI_BUILD_QUEUE_STATUS_QUEUED,
+		TriggerInputs: string(triggerInputs),
+		TriggeredBy:   request.TriggeredBy,
+	}
+	queued = append(queued, item)
+	for _, releasable := range selectBuildsToRelease(queued, limits, running) {
+		if releasable == item {
+			return 0, nil
+		}
+	}
+	item.AuditLog = sql.NewDefaultAuditLog(request.TriggeredBy)
+	err = impl.ciBuildQueueRepository.Save(item, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving ci build in queue", "ciWorkflowId", request.CiWorkflowId, "err", err)
+		return 0, err
+	}
+	err = impl.ciBuildQueueRepository.CommitTx(tx)
+	if err != nil {
+		impl.logger.Errorw("error in committing transaction", "ciWorkflowId", request.CiWorkflowId, "err", err)
+		return 0, err
+	}
+	impl.logger.Infow("ci build queued as build quota is reached", "ciPipelineId", request.CiPipelineId, "ciWorkflowId", request.CiWorkflowId, "teamId", request.TeamId)
+	return computeQueuePositions(queued, limits)[item.CiWorkflowId], nil
+}
+
+func (impl *CiBuildQueueServiceImpl) GetQueuedBuilds(teamId int) ([]*QueuedBuildDto, error) {
+	queued, positions, err := impl.getQueuePositions()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*QueuedBuildDto, 0)
+	for _, item := range queued {
+		if teamId > 0 && item.TeamId != teamId {
+			continue
+		}
+		result = append(result, &QueuedBuildDto{
+			Id:            item.Id,
+			CiWorkflowId:  item.CiWorkflowId,
+			CiPipelineId:  item.CiPipelineId,
+			TeamId:        item.TeamId,
+			PriorityClass: item.PriorityClass,
+			QueuePosition: positions[item.CiWorkflowId],
+			QueuedOn:      item.CreatedOn,
+			TriggeredBy:   item.TriggeredBy,
+		})
+	}
+	return result, nil
+}
+
+func (impl *CiBuildQueueServiceImpl) GetQueuePositions(ciWorkflowIds []int) (map[int]int, error) {
+	result := make(map[int]int)
+	if len(ciWorkflowIds) == 0 {
+		return result, nil
+	}
+	_, positions, err := impl.getQueuePositions()
+	if err != nil {
+		return nil, err
+	}
+	for _, ciWorkflowId := range ciWorkflowIds {
+		if position, ok := positions[ciWorkflowId]; ok {
+			result[ciWorkflowId] = position
+		}
+	}
+	return result, nil
+}
+
+func (impl *CiBuildQueueServiceImpl) ReleaseBuilds() ([]*repository.CiBuildQueue, error) {
+	tx, err := impl.ciBuildQueueRepository.StartTx()
+	if err != nil {
+		impl.logger.Errorw("error in starting transaction", "err", err)
+		return nil, err
+	}
+	defer impl.ciBuildQueueRepository.RollbackTx(tx)
+	err = impl.ciBuildQueueRepository.LockAdmission(tx)
+	if err != nil {
+		impl.logger.Errorw("error in taking ci build admission lock", "err", err)
+		return nil, err
+	}
+	queued, err := impl.ciBuildQueueRepository.FindAllQueued()
+	if err != nil {
+		impl.logger.Errorw("error in fetching queued ci builds", "err", err)
+		return nil, err
+	}
+	if len(queued) == 0 {
+		return nil, nil
+	}
+	limits, err := impl.getBuildLimits()
+	if err != nil {
+		return nil, err
+	}
+	running, err := impl.getRunningBuilds()
+	if err != nil {
+		return nil, err
+	}
+	var released []*repository.CiBuildQueue
+	for _, item := range selectBuildsToRelease(queued, limits, running) {
+		ok, err := impl.ciBuildQueueRepository.Release(item, tx)
+		if err != nil {
+			impl.logger.Errorw("error in releasing ci build from queue", "ciWorkflowId", item.CiWorkflowId, "err", err)
+			return nil, err
+		} else if ok {
+			released = append(released, item)
+		}
+	}
+	err = impl.ciBuildQueueRepository.CommitTx(tx)
+	if err != nil {
+		impl.logger.Errorw("error in committing transaction", "err", err)
+		return nil, err
+	}
+	return released, nil
+}
+
+func (impl *CiBuildQueueServiceImpl) DropQueuedBuild(ciWorkflowId int, message string) (bool, error) {
+	item, err := impl.ciBuildQueueRepository.FindByCiWorkflowId(ciWorkflowId)
+	if err == pg.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching queued ci build", "ciWorkflowId", ciWorkflowId, "err", err)
+		return false, err
+	}
+	return impl.ciBuildQueueRepository.MarkDropped(item.Id, message)
+}
+
+// getQueuePositions returns the queued builds in FIFO order along with their positions keyed by ci workflow id
+func (impl *CiBuildQueueServiceImpl) getQueuePositions() ([]*repository.CiBuildQueue, map[int]int, error) {
+	queued, err := impl.ciBuildQueueRepository.FindAllQueued()
+	if err != nil {
+		impl.logger.Errorw("error in fetching queued ci builds", "err", err)
+		return nil, nil, err
+	}
+	if len(queued) == 0 {
+		return queued, make(map[int]int), nil
+	}
+	limits, err := impl.getBuildLimits()
+	if err != nil {
+		return nil, nil, err
+	}
+	return queued, computeQueuePositions(queued, limits), nil
+}
+
+func (impl *CiBuildQueueServiceImpl) getBuildLimits() (*buildLimits, error) {
+	quotas, err := impl.ciBuildQuotaRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci build quotas", "err", err)
+		return nil, err
+	}
+	return newBuildLimits(impl.config.MaxConcurrentBuilds, quotas), nil
+}
+
+func (impl *CiBuildQueueServiceImpl) getRunningBuilds() (*runningBuilds, error) {
+	startedAfter := time.Now().Add(-time.Duration(impl.config.RunningTimeoutMins) * time.Minute)
+	counts, err := impl.ciBuildQueueRepository.GetRunningBuildCounts(runningStatuses, startedAfter)
+	if err != nil {
+		impl.logger.Errorw("error in fetching running ci build counts", "err", err)
+		return nil, err
+	}
+	running := &runningBuilds{byTeam: make(map[int]int)}
+	for _, count := range counts {
+		running.add(count.TeamId, count.Count)
+	}
+	return running, nil
+}
+
+func (impl *CiBuildQueueServiceImpl) validateQuota(request *CiBuildQuotaDto) error {
+	var validationErr string
+	_, err := impl.teamRepository.FindOne(request.TeamId)
+	if err == pg.ErrNoRows {
+		validationErr = fmt.Sprintf("project %d not found", request.TeamId)
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching team", "teamId", request.TeamId, "err", err)
+		return err
+	}
+	if len(validationErr) == 0 {
+		quotas, err := impl.ciBuildQuotaRepository.FindAllActive()
+		if err != nil {
+			impl.logger.Errorw("error in fetching ci build quotas", "err", err)
+			return err
+		}
+		for _, quota := range quotas {
+			if quota.Id != request.Id && quota.TeamId == request.TeamId {
+				validationErr = fmt.Sprintf("build quota %d already exists for the project", quota.Id)
+				break
+			}
+		}
+	}
+	if len(validationErr) > 0 {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: validationErr,
+			UserMessage:     validationErr,
+		}
+	}
+	return nil
+}
+
+// buildLimits are the maximum concurrent builds allowed across all projects, 0 for no cap, and per project
+type buildLimits struct {
+	maxConcurrentBuilds int
+	byTeam              map[int]int
+}
+
+func newBuildLimits(maxConcurrentBuilds int, quotas []*repository.CiBuildQuota) *buildLimits {
+	result := &buildLimits{maxConcurrentBuilds: maxConcurrentBuilds, byTeam: make(map[int]int)}
+	for _, quota := range quotas {
+		result.byTeam[quota.TeamId] = quota.MaxConcurrentBuilds
+	}
+	return result
+}
+
+func (limits *buildLimits) isLimited(teamId int) bool {
+	_, isTeamLimited := limits.byTeam[teamId]
+	return limits.maxConcurrentBuilds > 0 || isTeamLimited
+}
+
+func (limits *buildLimits) hasCapacity(teamId int, running *runningBuilds) bool {
+	if limits.maxConcurrentBuilds > 0 && running.total >= limits.maxConcurrentBuilds {
+		return false
+	}
+	teamLimit, isTeamLimited := limits.byTeam[teamId]
+	return !isTeamLimited || running.byTeam[teamId] < teamLimit
+}
+
+// sharesLimitedScope tells if the two builds compete for the same capacity
+func (limits *buildLimits) sharesLimitedScope(item, other *repository.CiBuildQueue) bool {
+	if limits.maxConcurrentBuilds > 0 {
+		return true
+	}
+	_, isTeamLimited := limits.byTeam[item.TeamId]
+	return isTeamLimited && item.TeamId == other.TeamId
+}
+
+type runningBuilds struct {
+	total  int
+	byTeam map[int]int
+}
+
+func (running *runningBuilds) add(teamId, count int) {
+	running.total += count
+	running.byTeam[teamId] += count
+}
+
+// selectBuildsToRelease picks the queued builds for which both the quota of their project and the global limit have
+// capacity. Builds of a higher priority class are picked first, among builds of the same class the project with the
+// fewest running builds goes first so that a mass rebuild of one project does not starve the others, FIFO otherwise.
+func selectBuildsToRelease(queued []*repository.CiBuildQueue, limits *buildLimits, running *runningBuilds) []*repository.CiBuildQueue {
+	var releasable []*repository.CiBuildQueue
+	picked := make([]bool, len(queued))
+	for {
+		next := -1
+		for i, item := range queued {
+			if picked[i] || !limits.hasCapacity(item.TeamId, running) {
+				continue
+			}
+			if next == -1 || isAheadInQueue(item, queued[next], running) {
+				next = i
+			}
+		}
+		if next == -1 {
+			return releasable
+		}
+		picked[next] = true
+		running.add(queued[next].TeamId, 1)
+		releasable = append(releasable, queued[next])
+	}
+}
+
+func isAheadInQueue(item, other *repository.CiBuildQueue, running *runningBuilds) bool {
+	itemWeight, otherWeight := priorityClassWeights[item.PriorityClass], priorityClassWeights[other.PriorityClass]
+	if itemWeight != otherWeight {
+		return itemWeight > otherWeight
+	}
+	return running.byTeam[item.TeamId] < running.byTeam[other.TeamId]
+}
+
+// computeQueuePositions returns the 1-based queue position of every queued build keyed by its ci workflow id, the
+// position counts the builds competing for the same capacity which are ahead by priority class or, within the same
+// class, by FIFO order
+func computeQueuePositions(queued []*repository.CiBuildQueue, limits *buildLimits) map[int]int {
+	ordered := make([]*repository.CiBuildQueue, len(queued))
+	copy(ordered, queued)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return priorityClassWeights[ordered[i].PriorityClass] > priorityClassWeights[ordered[j].PriorityClass]
+	})
+	positions := make(map[int]int, len(ordered))
+	for i, item := range ordered {
+		position := 1
+		for _, earlier := range ordered[:i] {
+			if limits.sharesLimitedScope(item, earlier) {
+				position++
+			}
+		}
+		positions[item.CiWorkflowId] = position
+	}
+	return positions
+}
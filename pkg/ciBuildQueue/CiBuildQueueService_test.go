@@ -0,0 +1,66 @@
+package ciBuildQueue
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/devtron-labs/devtron/pkg/ciBuildQueue/repository"
+)
+
+func TestSelectBuildsToRelease(t *testing.T) {
+	quotas := []*repository.CiBuildQuota{
+		{TeamId: 1, MaxConcurrentBuilds: 1},
+		{TeamId: 2, MaxConcurrentBuilds: 2},
+	}
+	queued := []*repository.CiBuildQueue{
+		{Id: 1, TeamId: 1, PriorityClass: CI_BUILD_PRIORITY_NORMAL},
+		{Id: 2, TeamId: 1, PriorityClass: CI_BUILD_PRIORITY_HOTFIX},
+		{Id: 3, TeamId: 2, PriorityClass: CI_BUILD_PRIORITY_NIGHTLY},
+		{Id: 4, TeamId: 2, PriorityClass: CI_BUILD_PRIORITY_NORMAL},
+		{Id: 5, TeamId: 3, PriorityClass: CI_BUILD_PRIORITY_NORMAL},
+	}
+	tests := []struct {
+		name                string
+		maxConcurrentBuilds int
+		running             map[int]int
+		wantIds             []int
+	}{
+		{name: "team quotas only", running: map[int]int{2: 1}, wantIds: []int{2, 5, 4}},
+		{name: "global limit", maxConcurrentBuilds: 3, running: map[int]int{2: 1}, wantIds: []int{2, 5}},
+		{name: "team quota full", running: map[int]int{1: 1, 2: 2}, wantIds: []int{5}},
+		{name: "global limit full", maxConcurrentBuilds: 2, running: map[int]int{3: 2}, wantIds: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := newBuildLimits(tt.maxConcurrentBuilds, quotas)
+			running := &runningBuilds{byTeam: make(map[int]int)}
+			for teamId, count := range tt.running {
+				running.add(teamId, count)
+			}
+			var gotIds []int
+			for _, item := range selectBuildsToRelease(queued, limits, running) {
+				gotIds = append(gotIds, item.Id)
+			}
+			if !reflect.DeepEqual(gotIds, tt.wantIds) {
+				t.Errorf("selectBuildsToRelease() = %v, want %v", gotIds, tt.wantIds)
+			}
+		})
+	}
+}
+
+func TestComputeQueuePositions(t *testing.T) {
+	quotas := []*repository.CiBuildQuota{{TeamId: 1, MaxConcurrentBuilds: 1}}
+	queued := []*repository.CiBuildQueue{
+		{CiWorkflowId: 11, TeamId: 1, PriorityClass: CI_BUILD_PRIORITY_NORMAL},
+		{CiWorkflowId: 12, TeamId: 2, PriorityClass: CI_BUILD_PRIORITY_NORMAL},
+		{CiWorkflowId: 13, TeamId: 1, PriorityClass: CI_BUILD_PRIORITY_HOTFIX},
+	}
+	want := map[int]int{13: 1, 11: 2, 12: 1}
+	if got := computeQueuePositions(queued, newBuildLimits(0, quotas)); !reflect.DeepEqual(got, want) {
+		t.Errorf("computeQueuePositions() = %v, want %v", got, want)
+	}
+	want = map[int]int{13: 1, 11: 2, 12: 3}
+	if got := computeQueuePositions(queued, newBuildLimits(5, quotas)); !reflect.DeepEqual(got, want) {
+		t.Errorf("computeQueuePositions() with global limit = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,67 @@
+package ciBuildQueue
+
+import "time"
+
+const (
+	CI_BUILD_PRIORITY_HOTFIX  = "HOTFIX"
+	CI_BUILD_PRIORITY_HIGH    = "HIGH"
+	CI_BUILD_PRIORITY_NORMAL  = "NORMAL"
+	CI_BUILD_PRIORITY_NIGHTLY = "NIGHTLY"
+)
+
+// priorityClassWeights orders the priority classes, builds of a heavier class are released from the queue first
+var priorityClassWeights = map[string]int{
+	CI_BUILD_PRIORITY_HOTFIX:  3,
+	CI_BUILD_PRIORITY_HIGH:    2,
+	CI_BUILD_PRIORITY_NORMAL:  1,
+	CI_BUILD_PRIORITY_NIGHTLY: 0,
+}
+
+func IsValidPriorityClass(priorityClass string) bool {
+	_, ok := priorityClassWeights[priorityClass]
+	return ok
+}
+
+type CiBuildQuotaDto struct {
+	Id                  int   `json:"id"`
+	TeamId              int   `json:"teamId" validate:"number,min=1"`
+	MaxConcurrentBuilds int   `json:"maxConcurrentBuilds" validate:"number,min=1"`
+	UserId              int32 `json:"-"`
+}
+
+type CiPipelineBuildPriorityDto struct {
+	CiPipelineId  int    `json:"ciPipelineId"`
+	PriorityClass string `json:"priorityClass" validate:"required"`
+	UserId        int32  `json:"-"`
+}
+
+// QueueBuildRequest is a ci build about to be submitted, TriggerInputs are kept while the build waits in queue
+type QueueBuildRequest struct {
+	CiWorkflowId  int
+	CiPipelineId  int
+	TeamId        int
+	PriorityClass string
+	TriggeredBy   int32
+	TriggerInputs *QueuedBuildTriggerInputs
+}
+
+// QueuedBuildTriggerInputs are the inputs of the trigger of a queued build which are not recorded on its ci workflow,
+// the workflow request is built from them once the build is released so that no credentials are kept in the queue
+type QueuedBuildTriggerInputs struct {
+	CiPipelineMaterialIds     []int             `json:"ciPipelineMaterialIds,omitempty"`
+	InvalidateCache           bool              `json:"invalidateCache"`
+	ExtraEnvironmentVariables map[string]string `json:"extraEnvironmentVariables,omitempty"`
+	PipelineType              string            `json:"pipelineType,omitempty"`
+	CiArtifactLastFetch       time.Time         `json:"ciArtifactLastFetch"`
+}
+
+type QueuedBuildDto struct {
+	Id            int       `json:"id"`
+	CiWorkflowId  int       `json:"ciWorkflowId"`
+	CiPipelineId  int       `json:"ciPipelineId"`
+	TeamId        int       `json:"teamId"`
+	PriorityClass string    `json:"priorityClass"`
+	QueuePosition int       `json:"queuePosition"`
+	QueuedOn      time.Time `json:"queuedOn"`
+	TriggeredBy   int32     `json:"triggeredBy"`
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+type CiBuildQueueStatus string
+
+const (
+	CI_BUILD_QUEUE_STATUS_QUEUED  CiBuildQueueStatus = "QUEUED"
+	CI_BUILD_QUEUE_STATUS_DROPPED CiBuildQueueStatus = "DROPPED"
+)
+
+// CiBuildQueue is a ci build held back by the build quota of its project or the global build limit. Only the trigger
+// inputs not recorded on the ci workflow are kept, the workflow request is built from them once the build is released
+// from the queue and the row is deleted.
+type CiBuildQueue struct {
+	tableName     struct{}           `sql:"ci_build_queue" pg:",discard_unknown_columns"`
+	Id            int                `sql:"id,pk"`
+	CiWorkflowId  int                `sql:"ci_workflow_id,notnull"`
+	CiPipelineId  int                `sql:"ci_pipeline_id,notnull"`
+	TeamId        int                `sql:"team_id,notnull"`
+	PriorityClass string             `sql:"priority_class,notnull"`
+	Status        CiBuildQueueStatus `sql:"status,notnull"`
+	TriggerInputs string             `sql:"trigger_inputs,notnull"`
+	TriggeredBy   int32              `sql:"triggered_by,notnull"`
+	Message       string             `sql:"message"`
+	sql.AuditLog
+}
+
+// RunningBuildCount is the number of ci builds running for the apps of a project
+type RunningBuildCount struct {
+	TeamId int `sql:"team_id"`
+	Count  int `sql:"count"`
+}
+
+// ciBuildAdmissionLockKey is the postgres advisory lock key serializing the admission of ci builds against the build
+// quotas, the global build limit spans all projects so a single key is taken for all of them
+const ciBuildAdmissionLockKey = 20240601
+
+type CiBuildQueueRepository interface {
+	sql.TransactionWrapper
+	// LockAdmission takes the transaction scoped advisory lock held while counting running builds and queueing or
+	// releasing builds, the lock is released on commit or rollback of tx
+	LockAdmission(tx *pg.Tx) error
+	Save(item *CiBuildQueue, tx *pg.Tx) error
+	FindAllQueued() ([]*CiBuildQueue, error)
+	FindByCiWorkflowId(ciWorkflowId int) (*CiBuildQueue, error)
+	// Release deletes the queued build and moves its ci workflow from the queue to starting so that it counts as
+	// running right away, it returns false if the build was dropped in the meantime
+	Release(item *CiBuildQueue, tx *pg.Tx) (bool, error)
+	// MarkDropped moves a queued build to DROPPED, it returns false if the build was not waiting in queue
+	MarkDropped(id int, message string) (bool, error)
+	// GetRunningBuildCounts counts, per project, the ci workflows in one of the given statuses started after
+	// startedAfter
+	GetRunningBuildCounts(statuses []string, startedAfter time.Time) ([]*RunningBuildCount, error)
+}
+
+type CiBuildQueueRepositoryImpl struct {
+	*sql.TransactionUtilImpl
+	dbConnection *pg.DB
+}
+
+func NewCiBuildQueueRepositoryImpl(dbConnection *pg.DB) *CiBuildQueueRepositoryImpl {
+	return &CiBuildQueueRepositoryImpl{
+		TransactionUtilImpl: sql.NewTransactionUtilImpl(dbConnection),
+		dbConnection:        dbConnection,
+	}
+}
+
+func (impl *CiBuildQueueRepositoryImpl) LockAdmission(tx *pg.Tx) error {
+	_, err := tx.Exec("SELECT pg_advisory_xact_lock(?);", ciBuildAdmissionLockKey)
+	return err
+}
+
+func (impl *CiBuildQueueRepositoryImpl) Save(item *CiBuildQueue, tx *pg.Tx) error {
+	return tx.Insert(item)
+}
+
+func (impl *CiBuildQueueRepositoryImpl) FindAllQueued() ([]*CiBuildQueue, error) {
+	var items []*CiBuildQueue
+	err := impl.dbConnection.Model(&items).
+		Where("status = ?", CI_BUILD_QUEUE_STATUS_QUEUED).
+		Order("id ASC").
+		Select()
+	return items, err
+}
+
+func (impl *CiBuildQueueRepositoryImpl) FindByCiWorkflowId(ciWorkflowId int) (*CiBuildQueue, error) {
+	item := &CiBuildQueue{}
+	err := impl.dbConnection.Model(item).
+		Where("ci_workflow_id = ?", ciWorkflowId).
+		Select()
+	return item, err
+}
+
+func (impl *CiBuildQueueRepositoryImpl) Release(item *CiBuildQueue, tx *pg.Tx) (bool, error) {
+	res, err := tx.Model(&CiBuildQueue{}).
+		Where("id = ?", item.Id).
+		Where("status = ?", CI_BUILD_QUEUE_STATUS_QUEUED).
+		Delete()
+	if err != nil || res.RowsAffected() != 1 {
+		return false, err
+	}
+	_, err = tx.Model(&pipelineConfig.CiWorkflow{}).
+		Set("status = ?", pipelineConfig.WorkflowStarting).
+		Set("message = ?", "").
+		Set("started_on = ?", time.Now()).
+		Where("id = ?", item.CiWorkflowId).
+		Where("status = ?", pipelineConfig.WorkflowInQueue).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (impl *CiBuildQueueRepositoryImpl) MarkDropped(id int, message string) (bool, error) {
+	res, err := impl.dbConnection.Model(&CiBuildQueue{}).
+		Set("status = ?", CI_BUILD_QUEUE_STATUS_DROPPED).
+		Set("message = ?", message).
+		Set("updated_on = ?", time.Now()).
+		Where("id = ?", id).
+		Where("status = ?", CI_BUILD_QUEUE_STATUS_QUEUED).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl *CiBuildQueueRepositoryImpl) GetRunningBuildCounts(statuses []string, startedAfter time.Time) ([]*RunningBuildCount, error) {
+	var counts []*RunningBuildCount
+	query := "SELECT app.team_id, count(wf.id) AS count FROM ci_workflow wf" +
+		" INNER JOIN ci_pipeline cp ON cp.id = wf.ci_pipeline_id" +
+		" INNER JOIN app ON app.id = cp.app_id" +
+		" WHERE wf.status IN (?) AND wf.started_on > ?" +
+		" GROUP BY app.team_id;"
+	_, err := impl.dbConnection.Query(&counts, query, pg.In(statuses), startedAfter)
+	return counts, err
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// CiBuildQuota caps the number of ci builds running at the same time for the apps of a project (team)
+type CiBuildQuota struct {
+	tableName           struct{} `sql:"ci_build_quota" pg:",discard_unknown_columns"`
+	Id                  int      `sql:"id,pk"`
+	TeamId              int      `sql:"team_id,notnull"`
+	MaxConcurrentBuilds int      `sql:"max_concurrent_builds,notnull"`
+	Active              bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// CiPipelineBuildPriority is the priority class the builds of a ci pipeline are queued with unless given at trigger
+type CiPipelineBuildPriority struct {
+	tableName     struct{} `sql:"ci_pipeline_build_priority" pg:",discard_unknown_columns"`
+	Id            int      `sql:"id,pk"`
+	CiPipelineId  int      `sql:"ci_pipeline_id,notnull"`
+	PriorityClass string   `sql:"priority_class,notnull"`
+	sql.AuditLog
+}
+
+type CiBuildQuotaRepository interface {
+	Save(quota *CiBuildQuota) error
+	Update(quota *CiBuildQuota) error
+	FindById(id int) (*CiBuildQuota, error)
+	FindAllActive() ([]*CiBuildQuota, error)
+	SavePipelinePriority(priority *CiPipelineBuildPriority) error
+	UpdatePipelinePriority(priority *CiPipelineBuildPriority) error
+	FindPipelinePriorityByCiPipelineId(ciPipelineId int) (*CiPipelineBuildPriority, error)
+}
+
+type CiBuildQuotaRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewCiBuildQuotaRepositoryImpl(dbConnection *pg.DB) *CiBuildQuotaRepositoryImpl {
+	return &CiBuildQuotaRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *CiBuildQuotaRepositoryImpl) Save(quota *CiBuildQuota) error {
+	return impl.dbConnection.Insert(quota)
+}
+
+func (impl *CiBuildQuotaRepositoryImpl) Update(quota *CiBuildQuota) error {
+	return impl.dbConnection.Update(quota)
+}
+
+func (impl *CiBuildQuotaRepositoryImpl) FindById(id int) (*CiBuildQuota, error) {
+	quota := &CiBuildQuota{}
+	err := impl.dbConnection.Model(quota).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return quota, err
+}
+
+func (impl *CiBuildQuotaRepositoryImpl) FindAllActive() ([]*CiBuildQuota, error) {
+	var quotas []*CiBuildQuota
+	err := impl.dbConnection.Model(&quotas).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return quotas, err
+}
+
+func (impl *CiBuildQuotaRepositoryImpl) SavePipelinePriority(priority *CiPipelineBuildPriority) error {
+	return impl.dbConnection.Insert(priority)
+}
+
+func (impl *CiBuildQuotaRepositoryImpl) UpdatePipelinePriority(priority *CiPipelineBuildPriority) error {
+	return impl.dbConnection.Update(priority)
+}
+
+func (impl *CiBuildQuotaRepositoryImpl) FindPipelinePriorityByCiPipelineId(ciPipelineId int) (*CiPipelineBuildPriority, error) {
+	priority := &CiPipelineBuildPriority{}
+	err := impl.dbConnection.Model(priority).
+		Where("ci_pipeline_id = ?", ciPipelineId).
+		Select()
+	return priority, err
+}
@@ -0,0 +1,530 @@
+package deploymentConcurrency
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/deploymentConcurrency/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type DeploymentConcurrencyConfig struct {
+	// InFlightTimeoutMins is the time after which a deployment still not in a terminal state is no longer counted as
+	// in flight, so that a deployment stuck in progressing does not hold the queue of its environment forever
+	InFlightTimeoutMins int `env:"DEPLOYMENT_CONCURRENCY_IN_FLIGHT_TIMEOUT" envDefault:"60"`
+}
+
+type DeploymentConcurrencyService interface {
+	CreateLimit(request *DeploymentConcurrencyLimitDto) (*DeploymentConcurrencyLimitDto, error)
+	UpdateLimit(request *DeploymentConcurrencyLimitDto) (*DeploymentConcurrencyLimitDto, error)
+	DeleteLimit(id int, userId int32) error
+	GetLimitById(id int) (*DeploymentConcurrencyLimitDto, error)
+	GetAllLimits() ([]*DeploymentConcurrencyLimitDto, error)
+	// QueueDeploymentIfLimitReached adds the deploy runner of overrideRequest to the deployment queue if the concurrency
+	// limits of its environment or cluster are reached or earlier deployments are already waiting there. It returns the
+	// position of the deployment in the queue, 0 if the deployment can go ahead right away.
+	QueueDeploymentIfLimitReached(overrideRequest *bean.ValuesOverrideRequest, triggeredBy int32) (int, error)
+	// GetQueuedDeployments returns the deployments waiting in queue with their positions, filtered on environment
+	// and cluster if non-zero
+	GetQueuedDeployments(envId, clusterId int) ([]*QueuedDeploymentDto, error)
+	// GetQueuePositions returns the queue position of the given deploy runners, runners not waiting in queue are absent
+	GetQueuePositions(cdWorkflowRunnerIds []int) (map[int]int, error)
+	// GetQueuePositionsByPipelineIds returns the queue position of the latest queued deployment of the given pipelines,
+	// pipelines with no deployment waiting in queue are absent
+	GetQueuePositionsByPipelineIds(pipelineIds []int) (map[int]int, error)
+	// ReleaseDeployments marks released and returns, in FIFO order, the queued deployments for which capacity is
+	// available now
+	ReleaseDeployments() ([]*repository.DeploymentQueue, error)
+	MarkDropped(id int, message string) error
+}
+
+type DeploymentConcurrencyServiceImpl struct {
+	logger                               *zap.SugaredLogger
+	deploymentConcurrencyLimitRepository repository.DeploymentConcurrencyLimitRepository
+	deploymentQueueRepository            repository.DeploymentQueueRepository
+	environmentRepository                repository2.EnvironmentRepository
+	clusterRepository                    repository2.ClusterRepository
+	config                               *DeploymentConcurrencyConfig
+}
+
+func NewDeploymentConcurrencyServiceImpl(logger *zap.SugaredLogger,
+	deploymentConcurrencyLimitRepository repository.DeploymentConcurrencyLimitRepository,
+	deploymentQueueRepository repository.DeploymentQueueRepository,
+	environmentRepository repository2.EnvironmentRepository,
+	clusterRepository repository2.ClusterRepository) *DeploymentConcurrencyServiceImpl {
+	cfg := &DeploymentConcurrencyConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Infow("error occurred while parsing DeploymentConcurrencyConfig, so setting in flight timeout to default value", "err", err)
+		cfg.InFlightTimeoutMins = 60
+	}
+	return &DeploymentConcurrencyServiceImpl{
+		logger:                               logger,
+		deploymentConcurrencyLimitRepository: deploymentConcurrencyLimitRepository,
+		deploymentQueueRepository:            deploymentQueueRepository,
+		environmentRepository:                environmentRepository,
+		clusterRepository:                    clusterRepository,
+		config:                               cfg,
+	}
+}
+
+// inFlightStatuses are the deploy runner statuses for which the deployment is still being rolled out
+var inFlightStatuses = []string{pipelineConfig.WorkflowStarting, pipelineConfig.WorkflowInQueue, pipelineConfig.WorkflowInitiated, pipelineConfig.WorkflowInProgress}
+
+func (impl *DeploymentConcurrencyServiceImpl) CreateLimit(request *DeploymentConcurrencyLimitDto) (*DeploymentConcurrencyLimitDto, error) {
+	err := impl.validateLimit(request)
+	if err != nil {
+		impl.logger.Errorw("invalid deployment concurrency limit", "request", request, "err", err)
+		return nil, err
+	}
+	limit := &repository.DeploymentConcurrencyLimit{Active: true}
+	adaptDtoToModel(request, limit)
+	limit.AuditLog = sql.NewDefaultAuditLog(request.UserId)
+	err = impl.deploymentConcurrencyLimitRepository.Save(limit)
+	if err != nil {
+		impl.logger.Errorw("error in saving deployment concurrency limit", "limit", limit, "err", err)
+		return nil, err
+	}
+	request.Id = limit.Id
+	return request, nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) UpdateLimit(request *DeploymentConcurrencyLimitDto) (*DeploymentConcurrencyLimitDto, error) {
+	err := impl.validateLimit(request)
+	if err != nil {
+		impl.logger.Errorw("invalid deployment concurrency limit", "request", request, "err", err)
+		return nil, err
+	}
+	limit, err := impl.deploymentConcurrencyLimitRepository.FindById(request.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching deployment concurrency limit", "id", request.Id, "err", err)
+		return nil, err
+	}
+	adaptDtoToModel(request, limit)
+	limit.UpdatedOn = time.Now()
+	limit.UpdatedBy = request.UserId
+	err = impl.deploymentConcurrencyLimitRepository.Update(limit)
+	if err != nil {
+		impl.logger.Errorw("error in updating deployment concurrency limit", "limit", limit, "err", err)
+		return nil, err
+	}
+	return request, nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) DeleteLimit(id int, userId int32) error {
+	limit, err := impl.deploymentConcurrencyLimitRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching deployment concurrency limit", "id", id, "err", err)
+		return err
+	}
+	limit.Active = false
+	limit.UpdatedOn = time.Now()
+	limit.UpdatedBy = userId
+	err = impl.deploymentConcurrencyLimitRepository.Update(limit)
+	if err != nil {
+		impl.logger.Errorw("error in deleting deployment concurrency limit", "id", id, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) GetLimitById(id int) (*DeploymentConcurrencyLimitDto, error) {
+	limit, err := impl.deploymentConcurrencyLimitRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching deployment concurrency limit", "id", id, "err", err)
+		return nil, err
+	}
+	return adaptModelToDto(limit), nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) GetAllLimits() ([]*DeploymentConcurrencyLimitDto, error) {
+	limits, err := impl.deploymentConcurrencyLimitRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching deployment concurrency limits", "err", err)
+		return nil, err
+	}
+	result := make([]*DeploymentConcurrencyLimitDto, 0, len(limits))
+	for _, limit := range limits {
+		result = append(result, adaptModelToDto(limit))
+	}
+	return result, nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) QueueDeploymentIfLimitReached(overrideRequest *bean.ValuesOverrideRequest, triggeredBy int32) (int, error) {
+	limits, err := impl.getConcurrencyLimits()
+	if err != nil {
+		return 0, err
+	}
+	if !limits.isLimited(overrideRequest.EnvId, overrideRequest.ClusterId) {
+		return 0, nil
+	}
+	// counting the in flight deployments and queueing this one is serialized with other triggers and releases,
+	// otherwise concurrent triggers all see the same counts and go ahead beyond the limit
+	tx, err := impl.deploymentQueueRepository.StartTx()
+	if err != nil {
+		impl.logger.Errorw("error in starting transaction", "err", err)
+		return 0, err
+	}
+	defer impl.deploymentQueueRepository.RollbackTx(tx)
+	err = impl.deploymentQueueRepository.LockAdmission(tx)
+	if err != nil {
+		impl.logger.Errorw("error in taking deployment admission lock", "wfrId", overrideRequest.WfrId, "err", err)
+		return 0, err
+	}
+	queued, err := impl.deploymentQueueRepository.FindAllQueued()
+	if err != nil {
+		impl.logger.Errorw("error in fetching queued deployments", "err", err)
+		return 0, err
+	}
+	inFlight, err := impl.getInFlightDeployments()
+	if err != nil {
+		return 0, err
+	}
+	// the runner of this deployment is already created and counted as in flight
+	inFlight.add(overrideRequest.EnvId, overrideRequest.ClusterId, -1)
+	item := &repository.DeploymentQueue{
+		CdWorkflowRunnerId: overrideRequest.WfrId,
+		PipelineId:         overrideRequest.PipelineId,
+		EnvironmentId:      overrideRequest.EnvId,
+		ClusterId:          overrideRequest.ClusterId,
+		Status:             repository.DEPLOYMENT_QUEUE_STATUS_QUEUED,
+		TriggeredBy:        triggeredBy,
+	}
+	queued = append(queued, item)
+	for _, releasable := range selectDeploymentsToRelease(queued, limits, inFlight) {
+		if releasable == item {
+			return 0, nil
+		}
+	}
+	request, err := json.Marshal(overrideRequest)
+	if err != nil {
+		impl.logger.Errorw("error in marshalling values override request", "wfrId", overrideRequest.WfrId, "err", err)
+		return 0, err
+	}
+	item.ValuesOverrideRequest = string(request)
+	item.AuditLog = sql.NewDefaultAuditLog(triggeredBy)
+	err = impl.deploymentQueueRepository.Save(item, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving deployment in queue", "wfrId", overrideRequest.WfrId, "err", err)
+		return 0, err
+	}
+	err = impl.deploymentQueueRepository.CommitTx(tx)
+	if err != nil {
+		impl.logger.Errorw("error in committing transaction", "wfrId", overrideRequest.WfrId, "err", err)
+		return 0, err
+	}
+	impl.logger.Infow("deployment queued as concurrency limit is reached", "pipelineId", overrideRequest.PipelineId, "wfrId", overrideRequest.WfrId)
+	return computeQueuePositions(queued, limits)[item.CdWorkflowRunnerId], nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) GetQueuedDeployments(envId, clusterId int) ([]*QueuedDeploymentDto, error) {
+	queued, positions, err := impl.getQueuePositions()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*QueuedDeploymentDto, 0)
+	for _, item := range queued {
+		if (envId > 0 && item.EnvironmentId != envId) || (clusterId > 0 && item.ClusterId != clusterId) {
+			continue
+		}
+		result = append(result, &QueuedDeploymentDto{
+			Id:                 item.Id,
+			CdWorkflowRunnerId: item.CdWorkflowRunnerId,
+			PipelineId:         item.PipelineId,
+			EnvironmentId:      item.EnvironmentId,
+			ClusterId:          item.ClusterId,
+			QueuePosition:      positions[item.CdWorkflowRunnerId],
+			QueuedOn:           item.CreatedOn,
+			TriggeredBy:        item.TriggeredBy,
+		})
+	}
+	return result, nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) GetQueuePositions(cdWorkflowRunnerIds []int) (map[int]int, error) {
+	result := make(map[int]int)
+	if len(cdWorkflowRunnerIds) == 0 {
+		return result, nil
+	}
+	_, positions, err := impl.getQueuePositions()
+	if err != nil {
+		return nil, err
+	}
+	for _, wfrId := range cdWorkflowRunnerIds {
+		if position, ok := positions[wfrId]; ok {
+			result[wfrId] = position
+		}
+	}
+	return result, nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) GetQueuePositionsByPipelineIds(pipelineIds []int) (map[int]int, error) {
+	result := make(map[int]int)
+	if len(pipelineIds) == 0 {
+		return result, nil
+	}
+	queued, positions, err := impl.getQueuePositions()
+	if err != nil {
+		return nil, err
+	}
+	isRequested := make(map[int]bool, len(pipelineIds))
+	for _, pipelineId := range pipelineIds {
+		isRequested[pipelineId] = true
+	}
+	// queue is in FIFO order, so the latest deployment of a pipeline overwrites the earlier ones
+	for _, item := range queued {
+		if isRequested[item.PipelineId] {
+			result[item.PipelineId] = positions[item.CdWorkflowRunnerId]
+		}
+	}
+	return result, nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) ReleaseDeployments() ([]*repository.DeploymentQueue, error) {
+	tx, err := impl.deploymentQueueRepository.StartTx()
+	if err != nil {
+		impl.logger.Errorw("error in starting transaction", "err", err)
+		return nil, err
+	}
+	defer impl.deploymentQueueRepository.RollbackTx(tx)
+	err = impl.deploymentQueueRepository.LockAdmission(tx)
+	if err != nil {
+		impl.logger.Errorw("error in taking deployment admission lock", "err", err)
+		return nil, err
+	}
+	queued, err := impl.deploymentQueueRepository.FindAllQueued()
+	if err != nil {
+		impl.logger.Errorw("error in fetching queued deployments", "err", err)
+		return nil, err
+	}
+	if len(queued) == 0 {
+		return nil, nil
+	}
+	limits, err := impl.getConcurrencyLimits()
+	if err != nil {
+		return nil, err
+	}
+	inFlight, err := impl.getInFlightDeployments()
+	if err != nil {
+		return nil, err
+	}
+	// released deployments count as in flight from the commit on, so they are marked while the lock is held
+	var released []*repository.DeploymentQueue
+	for _, item := range selectDeploymentsToRelease(queued, limits, inFlight) {
+		isReleased, err := impl.deploymentQueueRepository.MarkReleased(item.Id, tx)
+		if err != nil {
+			impl.logger.Errorw("error in marking queued deployment released", "id", item.Id, "err", err)
+			return nil, err
+		} else if !isReleased {
+			// dropped in the meantime
+			continue
+		}
+		released = append(released, item)
+	}
+	err = impl.deploymentQueueRepository.CommitTx(tx)
+	if err != nil {
+		impl.logger.Errorw("error in committing transaction", "err", err)
+		return nil, err
+	}
+	return released, nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) MarkDropped(id int, message string) error {
+	return impl.deploymentQueueRepository.MarkDropped(id, message)
+}
+
+// getQueuePositions returns the queued deployments in FIFO order along with their positions keyed by runner id
+func (impl *DeploymentConcurrencyServiceImpl) getQueuePositions() ([]*repository.DeploymentQueue, map[int]int, error) {
+	queued, err := impl.deploymentQueueRepository.FindAllQueued()
+	if err != nil {
+		impl.logger.Errorw("error in fetching queued deployments", "err", err)
+		return nil, nil, err
+	}
+	if len(queued) == 0 {
+		return queued, make(map[int]int), nil
+	}
+	limits, err := impl.getConcurrencyLimits()
+	if err != nil {
+		return nil, nil, err
+	}
+	return queued, computeQueuePositions(queued, limits), nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) getConcurrencyLimits() (*concurrencyLimits, error) {
+	limits, err := impl.deploymentConcurrencyLimitRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching deployment concurrency limits", "err", err)
+		return nil, err
+	}
+	return newConcurrencyLimits(limits), nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) getInFlightDeployments() (*inFlightDeployments, error) {
+	startedAfter := time.Now().Add(-time.Duration(impl.config.InFlightTimeoutMins) * time.Minute)
+	counts, err := impl.deploymentQueueRepository.GetInFlightDeploymentCounts(inFlightStatuses, startedAfter)
+	if err != nil {
+		impl.logger.Errorw("error in fetching in flight deployment counts", "err", err)
+		return nil, err
+	}
+	inFlight := &inFlightDeployments{byEnvironment: make(map[int]int), byCluster: make(map[int]int)}
+	for _, count := range counts {
+		inFlight.add(count.EnvironmentId, count.ClusterId, count.Count)
+	}
+	return inFlight, nil
+}
+
+func (impl *DeploymentConcurrencyServiceImpl) validateLimit(request *DeploymentConcurrencyLimitDto) error {
+	var validationErr string
+	if (request.EnvironmentId > 0) == (request.ClusterId > 0) {
+		validationErr = "exactly one of environment and cluster must be set for the limit"
+	} else if request.EnvironmentId > 0 {
+		_, err := impl.environmentRepository.FindById(request.EnvironmentId)
+		if err == pg.ErrNoRows {
+			validationErr = fmt.Sprintf("environment %d not found", request.EnvironmentId)
+		} else if err != nil {
+			impl.logger.Errorw("error in fetching environment", "envId", request.EnvironmentId, "err", err)
+			return err
+		}
+	} else {
+		_, err := impl.clusterRepository.FindById(request.ClusterId)
+		if err == pg.ErrNoRows {
+			validationErr = fmt.Sprintf("cluster %d not found", request.ClusterId)
+		} else if err != nil {
+			impl.logger.Errorw("error in fetching cluster", "clusterId", request.ClusterId, "err", err)
+			return err
+		}
+	}
+	if len(validationErr) == 0 {
+		limits, err := impl.deploymentConcurrencyLimitRepository.FindAllActive()
+		if err != nil {
+			impl.logger.Errorw("error in fetching deployment concurrency limits", "err", err)
+			return err
+		}
+		for _, limit := range limits {
+			if limit.Id != request.Id && limit.EnvironmentId == request.EnvironmentId && limit.ClusterId == request.ClusterId {
+				validationErr = fmt.Sprintf("concurrency limit %d already exists for the same scope", limit.Id)
+				break
+			}
+		}
+	}
+	if len(validationErr) > 0 {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: validationErr,
+			UserMessage:     validationErr,
+		}
+	}
+	return nil
+}
+
+// concurrencyLimits are the maximum concurrent deployments allowed per environment and per cluster
+type concurrencyLimits struct {
+	byEnvironment map[int]int
+	byCluster     map[int]int
+}
+
+func newConcurrencyLimits(limits []*repository.DeploymentConcurrencyLimit) *concurrencyLimits {
+	result := &concurrencyLimits{byEnvironment: make(map[int]int), byCluster: make(map[int]int)}
+	for _, limit := range limits {
+		if limit.EnvironmentId > 0 {
+			result.byEnvironment[limit.EnvironmentId] = limit.MaxConcurrentDeployments
+		} else {
+			result.byCluster[limit.ClusterId] = limit.MaxConcurrentDeployments
+		}
+	}
+	return result
+}
+
+func (limits *concurrencyLimits) isLimited(envId, clusterId int) bool {
+	_, isEnvLimited := limits.byEnvironment[envId]
+	_, isClusterLimited := limits.byCluster[clusterId]
+	return isEnvLimited || isClusterLimited
+}
+
+// sharesLimitedScope tells if the two deployments compete for the same limited environment or cluster
+func (limits *concurrencyLimits) sharesLimitedScope(item, other *repository.DeploymentQueue) bool {
+	if _, ok := limits.byEnvironment[item.EnvironmentId]; ok && item.EnvironmentId == other.EnvironmentId {
+		return true
+	}
+	if _, ok := limits.byCluster[item.ClusterId]; ok && item.ClusterId == other.ClusterId {
+		return true
+	}
+	return false
+}
+
+type inFlightDeployments struct {
+	byEnvironment map[int]int
+	byCluster     map[int]int
+}
+
+func (inFlight *inFlightDeployments) add(envId, clusterId, count int) {
+	inFlight.byEnvironment[envId] += count
+	inFlight.byCluster[clusterId] += count
+}
+
+// selectDeploymentsToRelease walks the queue in FIFO order and picks the deployments for which the limits of both their
+// environment and cluster have capacity. Once a deployment waits on an environment or cluster, the later deployments of
+// that environment or cluster wait behind it.
+func selectDeploymentsToRelease(queued []*repository.DeploymentQueue, limits *concurrencyLimits, inFlight *inFlightDeployments) []*repository.DeploymentQueue {
+	var releasable []*repository.DeploymentQueue
+	waitingEnvs := make(map[int]bool)
+	waitingClusters := make(map[int]bool)
+	for _, item := range queued {
+		envLimit, isEnvLimited := limits.byEnvironment[item.EnvironmentId]
+		clusterLimit, isClusterLimited := limits.byCluster[item.ClusterId]
+		isEnvFull := isEnvLimited && (waitingEnvs[item.EnvironmentId] || inFlight.byEnvironment[item.EnvironmentId] >= envLimit)
+		isClusterFull := isClusterLimited && (waitingClusters[item.ClusterId] || inFlight.byCluster[item.ClusterId] >= clusterLimit)
+		if isEnvFull || isClusterFull {
+			if isEnvFull {
+				waitingEnvs[item.EnvironmentId] = true
+			}
+			if isClusterFull {
+				waitingClusters[item.ClusterId] = true
+			}
+			continue
+		}
+		inFlight.add(item.EnvironmentId, item.ClusterId, 1)
+		releasable = append(releasable, item)
+	}
+	return releasable
+}
+
+// computeQueuePositions returns the 1-based queue position of every queued deployment keyed by its runner id, the
+// position counts only the earlier deployments competing for the same limited environment or cluster
+func computeQueuePositions(queued []*repository.DeploymentQueue, limits *concurrencyLimits) map[int]int {
+	positions := make(map[int]int, len(queued))
+	for i, item := range queued {
+		position := 1
+		for _, earlier := range queued[:i] {
+			if limits.sharesLimitedScope(item, earlier) {
+				position++
+			}
+		}
+		positions[item.CdWorkflowRunnerId] = position
+	}
+	return positions
+}
+
+func adaptDtoToModel(request *DeploymentConcurrencyLimitDto, limit *repository.DeploymentConcurrencyLimit) {
+	limit.EnvironmentId = request.EnvironmentId
+	limit.ClusterId = request.ClusterId
+	limit.MaxConcurrentDeployments = request.MaxConcurrentDeployments
+}
+
+func adaptModelToDto(limit *repository.DeploymentConcurrencyLimit) *DeploymentConcurrencyLimitDto {
+	return &DeploymentConcurrencyLimitDto{
+		Id:                       limit.Id,
+		EnvironmentId:            limit.EnvironmentId,
+		ClusterId:                limit.ClusterId,
+		MaxConcurrentDeployments: limit.MaxConcurrentDeployments,
+	}
+}
@@ -0,0 +1,57 @@
+package deploymentConcurrency
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/devtron-labs/devtron/pkg/deploymentConcurrency/repository"
+)
+
+func TestSelectDeploymentsToRelease(t *testing.T) {
+	limits := newConcurrencyLimits([]*repository.DeploymentConcurrencyLimit{
+		{EnvironmentId: 1, MaxConcurrentDeployments: 1},
+		{ClusterId: 10, MaxConcurrentDeployments: 2},
+	})
+	queued := []*repository.DeploymentQueue{
+		{Id: 1, EnvironmentId: 1, ClusterId: 10},
+		{Id: 2, EnvironmentId: 1, ClusterId: 10},
+		{Id: 3, EnvironmentId: 2, ClusterId: 10},
+		{Id: 4, EnvironmentId: 3, ClusterId: 10},
+		{Id: 5, EnvironmentId: 4, ClusterId: 20},
+	}
+	tests := []struct {
+		name    string
+		envs    map[int]int
+		cluster map[int]int
+		wantIds []int
+	}{
+		{name: "nothing in flight", envs: map[int]int{}, cluster: map[int]int{}, wantIds: []int{1, 3, 5}},
+		{name: "environment full", envs: map[int]int{1: 1}, cluster: map[int]int{10: 1}, wantIds: []int{3, 5}},
+		{name: "cluster full", envs: map[int]int{2: 2}, cluster: map[int]int{10: 2}, wantIds: []int{5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inFlight := &inFlightDeployments{byEnvironment: tt.envs, byCluster: tt.cluster}
+			var gotIds []int
+			for _, item := range selectDeploymentsToRelease(queued, limits, inFlight) {
+				gotIds = append(gotIds, item.Id)
+			}
+			if !reflect.DeepEqual(gotIds, tt.wantIds) {
+				t.Errorf("selectDeploymentsToRelease() = %v, want %v", gotIds, tt.wantIds)
+			}
+		})
+	}
+}
+
+func TestComputeQueuePositions(t *testing.T) {
+	limits := newConcurrencyLimits([]*repository.DeploymentConcurrencyLimit{{EnvironmentId: 1, MaxConcurrentDeployments: 1}})
+	queued := []*repository.DeploymentQueue{
+		{CdWorkflowRunnerId: 11, EnvironmentId: 1, ClusterId: 10},
+		{CdWorkflowRunnerId: 12, EnvironmentId: 2, ClusterId: 10},
+		{CdWorkflowRunnerId: 13, EnvironmentId: 1, ClusterId: 10},
+	}
+	want := map[int]int{11: 1, 12: 1, 13: 2}
+	if got := computeQueuePositions(queued, limits); !reflect.DeepEqual(got, want) {
+		t.Errorf("computeQueuePositions() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,22 @@
+package deploymentConcurrency
+
+import "time"
+
+type DeploymentConcurrencyLimitDto struct {
+	Id                       int   `json:"id"`
+	EnvironmentId            int   `json:"environmentId,omitempty" validate:"number,min=0"`
+	ClusterId                int   `json:"clusterId,omitempty" validate:"number,min=0"`
+	MaxConcurrentDeployments int   `json:"maxConcurrentDeployments" validate:"number,min=1"`
+	UserId                   int32 `json:"-"`
+}
+
+type QueuedDeploymentDto struct {
+	Id                 int       `json:"id"`
+	CdWorkflowRunnerId int       `json:"cdWorkflowRunnerId"`
+	PipelineId         int       `json:"pipelineId"`
+	EnvironmentId      int       `json:"environmentId"`
+	ClusterId          int       `json:"clusterId"`
+	QueuePosition      int       `json:"queuePosition"`
+	QueuedOn           time.Time `json:"queuedOn"`
+	TriggeredBy        int32     `json:"triggeredBy"`
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// DeploymentConcurrencyLimit caps the number of deployments in flight at the same time on an environment or on all the
+// environments of a cluster, exactly one of EnvironmentId and ClusterId is set
+type DeploymentConcurrencyLimit struct {
+	tableName                struct{} `sql:"deployment_concurrency_limit" pg:",discard_unknown_columns"`
+	Id                       int      `sql:"id,pk"`
+	EnvironmentId            int      `sql:"environment_id"`
+	ClusterId                int      `sql:"cluster_id"`
+	MaxConcurrentDeployments int      `sql:"max_concurrent_deployments,notnull"`
+	Active                   bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type DeploymentConcurrencyLimitRepository interface {
+	Save(limit *DeploymentConcurrencyLimit) error
+	Update(limit *DeploymentConcurrencyLimit) error
+	FindById(id int) (*DeploymentConcurrencyLimit, error)
+	FindAllActive() ([]*DeploymentConcurrencyLimit, error)
+}
+
+type DeploymentConcurrencyLimitRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewDeploymentConcurrencyLimitRepositoryImpl(dbConnection *pg.DB) *DeploymentConcurrencyLimitRepositoryImpl {
+	return &DeploymentConcurrencyLimitRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *DeploymentConcurrencyLimitRepositoryImpl) Save(limit *DeploymentConcurrencyLimit) error {
+	return impl.dbConnection.Insert(limit)
+}
+
+func (impl *DeploymentConcurrencyLimitRepositoryImpl) Update(limit *DeploymentConcurrencyLimit) error {
+	return impl.dbConnection.Update(limit)
+}
+
+func (impl *DeploymentConcurrencyLimitRepositoryImpl) FindById(id int) (*DeploymentConcurrencyLimit, error) {
+	limit := &DeploymentConcurrencyLimit{}
+	err := impl.dbConnection.Model(limit).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return limit, err
+}
+
+func (impl *DeploymentConcurrencyLimitRepositoryImpl) FindAllActive() ([]*DeploymentConcurrencyLimit, error) {
+	var limits []*DeploymentConcurrencyLimit
+	err := impl.dbConnection.Model(&limits).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return limits, err
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+type DeploymentQueueStatus string
+
+const (
+	DEPLOYMENT_QUEUE_STATUS_QUEUED   DeploymentQueueStatus = "QUEUED"
+	DEPLOYMENT_QUEUE_STATUS_RELEASED DeploymentQueueStatus = "RELEASED"
+	DEPLOYMENT_QUEUE_STATUS_DROPPED  DeploymentQueueStatus = "DROPPED"
+)
+
+// DeploymentQueue is a deployment held back by the concurrency limits of its environment or cluster, the serialized
+// values override request is replayed once the deployment is released from the queue
+type DeploymentQueue struct {
+	tableName             struct{}              `sql:"deployment_queue" pg:",discard_unknown_columns"`
+	Id                    int                   `sql:"id,pk"`
+	CdWorkflowRunnerId    int                   `sql:"cd_workflow_runner_id,notnull"`
+	PipelineId            int                   `sql:"pipeline_id,notnull"`
+	EnvironmentId         int                   `sql:"environment_id,notnull"`
+	ClusterId             int                   `sql:"cluster_id,notnull"`
+	Status                DeploymentQueueStatus `sql:"status,notnull"`
+	ValuesOverrideRequest string                `sql:"values_override_request,notnull"`
+	TriggeredBy           int32                 `sql:"triggered_by,notnull"`
+	Message               string                `sql:"message"`
+	ReleasedOn            time.Time             `sql:"released_on"`
+	sql.AuditLog
+}
+
+// InFlightDeploymentCount is the number of deployments in flight on an environment
+type InFlightDeploymentCount struct {
+	EnvironmentId int `sql:"environment_id"`
+	ClusterId     int `sql:"cluster_id"`
+	Count         int `sql:"count"`
+}
+
+// deploymentAdmissionLockKey is the postgres advisory lock key serializing the admission of deployments against the
+// concurrency limits, limits span both environments and clusters so a single key is taken for all of them
+const deploymentAdmissionLockKey = 20240701
+
+type DeploymentQueueRepository interface {
+	sql.TransactionWrapper
+	// LockAdmission takes the transaction scoped advisory lock held while counting in flight deployments and queueing
+	// or releasing deployments, the lock is released on commit or rollback of tx
+	LockAdmission(tx *pg.Tx) error
+	Save(item *DeploymentQueue, tx *pg.Tx) error
+	FindAllQueued() ([]*DeploymentQueue, error)
+	FindByCdWorkflowRunnerId(cdWorkflowRunnerId int) (*DeploymentQueue, error)
+	// MarkReleased moves a queued deployment to RELEASED, it returns false if the deployment was already released (by
+	// another instance) or dropped in the meantime
+	MarkReleased(id int, tx *pg.Tx) (bool, error)
+	MarkDropped(id int, message string) error
+	// GetInFlightDeploymentCounts counts, per environment, the deploy runners in one of the given statuses started after
+	// startedAfter, runners waiting in the deployment queue are not counted
+	GetInFlightDeploymentCounts(statuses []string, startedAfter time.Time) ([]*InFlightDeploymentCount, error)
+}
+
+type DeploymentQueueRepositoryImpl struct {
+	*sql.TransactionUtilImpl
+	dbConnection *pg.DB
+}
+
+func NewDeploymentQueueRepositoryImpl(dbConnection *pg.DB) *DeploymentQueueRepositoryImpl {
+	return &DeploymentQueueRepositoryImpl{
+		TransactionUtilImpl: sql.NewTransactionUtilImpl(dbConnection),
+		dbConnection:        dbConnection,
+	}
+}
+
+func (impl *DeploymentQueueRepositoryImpl) LockAdmission(tx *pg.Tx) error {
+	_, err := tx.Exec("SELECT pg_advisory_xact_lock(?);", deploymentAdmissionLockKey)
+	return err
+}
+
+func (impl *DeploymentQueueRepositoryImpl) Save(item *DeploymentQueue, tx *pg.Tx) error {
+	return tx.Insert(item)
+}
+
+func (impl *DeploymentQueueRepositoryImpl) FindAllQueued() ([]*DeploymentQueue, error) {
+	var items []*DeploymentQueue
+	err := impl.dbConnection.Model(&items).
+		Where("status = ?", DEPLOYMENT_QUEUE_STATUS_QUEUED).
+		Order("id ASC").
+		Select()
+	return items, err
+}
+
+func (impl *DeploymentQueueRepositoryImpl) FindByCdWorkflowRunnerId(cdWorkflowRunnerId int) (*DeploymentQueue, error) {
+	item := &DeploymentQueue{}
+	err := impl.dbConnection.Model(item).
+		Where("cd_workflow_runner_id = ?", cdWorkflowRunnerId).
+		Select()
+	return item, err
+}
+
+func (impl *DeploymentQueueRepositoryImpl) MarkReleased(id int, tx *pg.Tx) (bool, error) {
+	res, err := tx.Model(&DeploymentQueue{}).
+		Set("status = ?", DEPLOYMENT_QUEUE_STATUS_RELEASED).
+		Set("released_on = ?", time.Now()).
+		Set("updated_on = ?", time.Now()).
+		Where("id = ?", id).
+		Where("status = ?", DEPLOYMENT_QUEUE_STATUS_QUEUED).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (impl *DeploymentQueueRepositoryImpl) MarkDropped(id int, message string) error {
+	_, err := impl.dbConnection.Model(&DeploymentQueue{}).
+		Set("status = ?", DEPLOYMENT_QUEUE_STATUS_DROPPED).
+		Set("message = ?", message).
+		Set("updated_on = ?", time.Now()).
+		Where("id = ?", id).
+		Update()
+	return err
+}
+
+func (impl *DeploymentQueueRepositoryImpl) GetInFlightDeploymentCounts(statuses []string, startedAfter time.Time) ([]*InFlightDeploymentCount, error) {
+	var counts []*InFlightDeploymentCount
+	query := "SELECT p.environment_id, env.cluster_id, count(wfr.id) AS count FROM cd_workflow_runner wfr" +
+		" INNER JOIN cd_workflow wf ON wf.id = wfr.cd_workflow_id" +
+		" INNER JOIN pipeline p ON p.id = wf.pipeline_id" +
+		" INNER JOIN environment env ON env.id = p.environment_id" +
+		" WHERE wfr.workflow_type = ? AND wfr.status IN (?) AND wfr.started_on > ? AND p.deleted = false" +
+		" AND wfr.id NOT IN (SELECT cd_workflow_runner_id FROM deployment_queue WHERE status = ?)" +
+		" GROUP BY p.environment_id, env.cluster_id;"
+	_, err := impl.dbConnection.Query(&counts, query, bean.CD_WORKFLOW_TYPE_DEPLOY, pg.In(statuses), startedAfter, DEPLOYMENT_QUEUE_STATUS_QUEUED)
+	return counts, err
+}
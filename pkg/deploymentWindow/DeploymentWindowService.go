@@ -0,0 +1,387 @@
+package deploymentWindow
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/deploymentWindow/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	// how far ahead the next allowed deployment time is searched for
+	nextAllowedTimeSearchHorizon = 30 * 24 * time.Hour
+	// cap on occurrences of a recurring window considered while searching the next allowed time
+	maxWindowOccurrencesInSearch = 1000
+)
+
+type DeploymentWindowService interface {
+	CreateWindow(request *DeploymentWindowDto) (*DeploymentWindowDto, error)
+	UpdateWindow(request *DeploymentWindowDto) (*DeploymentWindowDto, error)
+	DeleteWindow(id int, userId int32) error
+	GetWindowById(id int) (*DeploymentWindowDto, error)
+	GetAllWindows() ([]*DeploymentWindowDto, error)
+	GetDeploymentWindowState(appId int, envId int, at time.Time) (*DeploymentWindowState, error)
+	// GetNextAllowedTime returns the earliest time, not before from, at which windows allow deployment on the env of
+	// the app, nil is returned if deployment stays blocked for the whole search horizon
+	GetNextAllowedTime(appId int, envId int, from time.Time) (*time.Time, error)
+	SaveOverrideAudit(request *DeploymentWindowOverrideRequest) error
+}
+
+type DeploymentWindowServiceImpl struct {
+	logger                     *zap.SugaredLogger
+	deploymentWindowRepository repository.DeploymentWindowRepository
+	appRepository              app.AppRepository
+}
+
+func NewDeploymentWindowServiceImpl(logger *zap.SugaredLogger, deploymentWindowRepository repository.DeploymentWindowRepository,
+	appRepository app.AppRepository) *DeploymentWindowServiceImpl {
+	return &DeploymentWindowServiceImpl{
+		logger:                     logger,
+		deploymentWindowRepository: deploymentWindowRepository,
+		appRepository:              appRepository,
+	}
+}
+
+func (impl *DeploymentWindowServiceImpl) CreateWindow(request *DeploymentWindowDto) (*DeploymentWindowDto, error) {
+	err := validateWindow(request)
+	if err != nil {
+		impl.logger.Errorw("invalid deployment window", "request", request, "err", err)
+		return nil, err
+	}
+	window := &repository.DeploymentWindow{Active: true}
+	adaptDtoToModel(request, window)
+	window.AuditLog = sql.NewDefaultAuditLog(request.UserId)
+	err = impl.deploymentWindowRepository.Save(window)
+	if err != nil {
+		impl.logger.Errorw("error in saving deployment window", "window", window, "err", err)
+		return nil, err
+	}
+	request.Id = window.Id
+	return request, nil
+}
+
+func (impl *DeploymentWindowServiceImpl) UpdateWindow(request *DeploymentWindowDto) (*DeploymentWindowDto, error) {
+	err := validateWindow(request)
+	if err != nil {
+		impl.logger.Errorw("invalid deployment window", "request", request, "err", err)
+		return nil, err
+	}
+	window, err := impl.deploymentWindowRepository.FindById(request.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching deployment window", "id", request.Id, "err", err)
+		return nil, err
+	}
+	adaptDtoToModel(request, window)
+	window.UpdatedOn = time.Now()
+	window.UpdatedBy = request.UserId
+	err = impl.deploymentWindowRepository.Update(window)
+	if err != nil {
+		impl.logger.Errorw("error in updating deployment window", "window", window, "err", err)
+		return nil, err
+	}
+	return request, nil
+}
+
+func (impl *DeploymentWindowServiceImpl) DeleteWindow(id int, userId int32) error {
+	window, err := impl.deploymentWindowRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching deployment window", "id", id, "err", err)
+		return err
+	}
+	window.Active = false
+	window.UpdatedOn = time.Now()
+	window.UpdatedBy = userId
+	err = impl.deploymentWindowRepository.Update(window)
+	if err != nil {
+		impl.logger.Errorw("error in deleting deployment window", "id", id, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *DeploymentWindowServiceImpl) GetWindowById(id int) (*DeploymentWindowDto, error) {
+	window, err := impl.deploymentWindowRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching deployment window", "id", id, "err", err)
+		return nil, err
+	}
+	return adaptModelToDto(window), nil
+}
+
+func (impl *DeploymentWindowServiceImpl) GetAllWindows() ([]*DeploymentWindowDto, error) {
+	windows, err := impl.deploymentWindowRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching deployment windows", "err", err)
+		return nil, err
+	}
+	result := make([]*DeploymentWindowDto, 0, len(windows))
+	for _, window := range windows {
+		result = append(result, adaptModelToDto(window))
+	}
+	return result, nil
+}
+
+func (impl *DeploymentWindowServiceImpl) GetDeploymentWindowState(appId int, envId int, at time.Time) (*DeploymentWindowState, error) {
+	app, err := impl.appRepository.FindById(appId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching app", "appId", appId, "err", err)
+		return nil, err
+	}
+	windows, err := impl.deploymentWindowRepository.FindActiveByEnvOrTeam(envId, app.TeamId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching deployment windows", "envId", envId, "teamId", app.TeamId, "err", err)
+		return nil, err
+	}
+	return evaluateWindows(windows, at, impl.logger), nil
+}
+
+func (impl *DeploymentWindowServiceImpl) GetNextAllowedTime(appId int, envId int, from time.Time) (*time.Time, error) {
+	app, err := impl.appRepository.FindById(appId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching app", "appId", appId, "err", err)
+		return nil, err
+	}
+	windows, err := impl.deploymentWindowRepository.FindActiveByEnvOrTeam(envId, app.TeamId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching deployment windows", "envId", envId, "teamId", app.TeamId, "err", err)
+		return nil, err
+	}
+	return findNextAllowedTime(windows, from, from.Add(nextAllowedTimeSearchHorizon), impl.logger), nil
+}
+
+func (impl *DeploymentWindowServiceImpl) SaveOverrideAudit(request *DeploymentWindowOverrideRequest) error {
+	if len(strings.TrimSpace(request.Justification)) == 0 {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: "justification is mandatory for overriding deployment window",
+			UserMessage:     "justification is mandatory for overriding deployment window",
+		}
+	}
+	audit := &repository.DeploymentWindowOverrideAudit{
+		PipelineId:         request.PipelineId,
+		CiArtifactId:       request.CiArtifactId,
+		CdWorkflowRunnerId: request.CdWorkflowRunnerId,
+		Justification:      request.Justification,
+		AuditLog:           sql.NewDefaultAuditLog(request.UserId),
+	}
+	if request.State != nil {
+		audit.WindowIds = request.State.GetBlockingWindowIds()
+	}
+	err := impl.deploymentWindowRepository.SaveOverrideAudit(audit)
+	if err != nil {
+		impl.logger.Errorw("error in saving deployment window override audit", "audit", audit, "err", err)
+		return err
+	}
+	return nil
+}
+
+// evaluateWindows decides whether a deployment is allowed at the given time. An active blackout window always blocks,
+// and when maintenance windows are configured for the scope deployment is allowed only while one of them is active.
+func evaluateWindows(windows []*repository.DeploymentWindow, at time.Time, logger *zap.SugaredLogger) *DeploymentWindowState {
+	state := &DeploymentWindowState{IsAllowed: true}
+	var activeBlackouts, maintenanceWindows []*DeploymentWindowDto
+	isInMaintenanceWindow := false
+	for _, window := range windows {
+		isActive, err := isWindowActiveAt(window, at)
+		if err != nil {
+			// invalid windows are rejected on save, this can only happen if db is edited by hand
+			logger.Errorw("error in evaluating deployment window, skipping", "windowId", window.Id, "err", err)
+			continue
+		}
+		switch window.WindowType {
+		case repository.WINDOW_TYPE_BLACKOUT:
+			if isActive {
+				activeBlackouts = append(activeBlackouts, adaptModelToDto(window))
+			}
+		case repository.WINDOW_TYPE_MAINTENANCE:
+			maintenanceWindows = append(maintenanceWindows, adaptModelToDto(window))
+			isInMaintenanceWindow = isInMaintenanceWindow || isActive
+		}
+	}
+	if len(activeBlackouts) > 0 {
+		state.IsAllowed = false
+		state.BlockingWindows = activeBlackouts
+		state.Reason = fmt.Sprintf("Deployment blocked: blackout window %s is active", getWindowNames(activeBlackouts))
+	} else if len(maintenanceWindows) > 0 && !isInMaintenanceWindow {
+		state.IsAllowed = false
+		state.BlockingWindows = maintenanceWindows
+		state.Reason = fmt.Sprintf("Deployment blocked: outside of deployment window %s", getWindowNames(maintenanceWindows))
+	}
+	return state
+}
+
+// findNextAllowedTime evaluates the windows at every point in [from, until] where the outcome can change, i.e. from
+// itself and the start and end of each window occurrence, and returns the first point at which deployment is allowed
+func findNextAllowedTime(windows []*repository.DeploymentWindow, from time.Time, until time.Time, logger *zap.SugaredLogger) *time.Time {
+	candidates := []time.Time{from}
+	for _, window := range windows {
+		boundaries, err := getWindowBoundaries(window, from, until)
+		if err != nil {
+			logger.Errorw("error in evaluating deployment window, skipping", "windowId", window.Id, "err", err)
+			continue
+		}
+		candidates = append(candidates, boundaries...)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Before(candidates[j])
+	})
+	for _, candidate := range candidates {
+		if candidate.Before(from) || candidate.After(until) {
+			continue
+		}
+		if evaluateWindows(windows, candidate, logger).IsAllowed {
+			nextAllowedTime := candidate
+			return &nextAllowedTime
+		}
+	}
+	return nil
+}
+
+// getWindowBoundaries returns the times in [from, until] at which the window becomes active or inactive
+func getWindowBoundaries(window *repository.DeploymentWindow, from time.Time, until time.Time) ([]time.Time, error) {
+	var boundaries []time.Time
+	if !window.StartTime.IsZero() {
+		boundaries = append(boundaries, window.StartTime)
+	}
+	if !window.EndTime.IsZero() {
+		boundaries = append(boundaries, window.EndTime)
+	}
+	if len(window.CronExpression) == 0 {
+		return boundaries, nil
+	}
+	location, err := getLocation(window.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := cron.ParseStandard(window.CronExpression)
+	if err != nil {
+		return nil, err
+	}
+	duration := time.Duration(window.DurationMinutes) * time.Minute
+	// starting a duration early picks the occurrence which may be active at from
+	occurrence := schedule.Next(from.In(location).Add(-duration))
+	for i := 0; i < maxWindowOccurrencesInSearch && !occurrence.IsZero() && !occurrence.After(until); i++ {
+		boundaries = append(boundaries, occurrence, occurrence.Add(duration))
+		occurrence = schedule.Next(occurrence)
+	}
+	return boundaries, nil
+}
+
+func isWindowActiveAt(window *repository.DeploymentWindow, at time.Time) (bool, error) {
+	if !window.StartTime.IsZero() && at.Before(window.StartTime) {
+		return false, nil
+	}
+	if !window.EndTime.IsZero() && !at.Before(window.EndTime) {
+		return false, nil
+	}
+	if len(window.CronExpression) == 0 {
+		// one-off window, bounded only by start and end time
+		return true, nil
+	}
+	location, err := getLocation(window.Timezone)
+	if err != nil {
+		return false, err
+	}
+	schedule, err := cron.ParseStandard(window.CronExpression)
+	if err != nil {
+		return false, err
+	}
+	duration := time.Duration(window.DurationMinutes) * time.Minute
+	// the window is active if an occurrence started within the last `duration`
+	lastOccurrence := schedule.Next(at.In(location).Add(-duration))
+	return !lastOccurrence.After(at), nil
+}
+
+func validateWindow(request *DeploymentWindowDto) error {
+	var validationErr string
+	if request.EnvironmentId == 0 && request.TeamId == 0 {
+		validationErr = "either environmentId or teamId is required"
+	} else if _, err := getLocation(request.Timezone); err != nil {
+		validationErr = fmt.Sprintf("invalid timezone %s", request.Timezone)
+	} else if len(request.CronExpression) > 0 {
+		if _, err := cron.ParseStandard(request.CronExpression); err != nil {
+			validationErr = fmt.Sprintf("invalid cron expression %s: %s", request.CronExpression, err.Error())
+		} else if request.DurationMinutes <= 0 {
+			validationErr = "durationMinutes must be positive for recurring windows"
+		}
+	} else if request.StartTime == nil || request.EndTime == nil {
+		validationErr = "startTime and endTime are required for one-off windows"
+	}
+	if len(validationErr) == 0 && request.StartTime != nil && request.EndTime != nil && !request.EndTime.After(*request.StartTime) {
+		validationErr = "endTime must be after startTime"
+	}
+	if len(validationErr) > 0 {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: validationErr,
+			UserMessage:     validationErr,
+		}
+	}
+	return nil
+}
+
+func getLocation(timezone string) (*time.Location, error) {
+	if len(timezone) == 0 {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(timezone)
+}
+
+func getWindowNames(windows []*DeploymentWindowDto) string {
+	names := make([]string, 0, len(windows))
+	for _, window := range windows {
+		names = append(names, window.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func adaptDtoToModel(request *DeploymentWindowDto, window *repository.DeploymentWindow) {
+	window.Name = request.Name
+	window.Description = request.Description
+	window.WindowType = request.WindowType
+	window.EnvironmentId = request.EnvironmentId
+	window.TeamId = request.TeamId
+	window.CronExpression = request.CronExpression
+	window.DurationMinutes = request.DurationMinutes
+	window.Timezone = request.Timezone
+	window.StartTime = time.Time{}
+	window.EndTime = time.Time{}
+	if request.StartTime != nil {
+		window.StartTime = *request.StartTime
+	}
+	if request.EndTime != nil {
+		window.EndTime = *request.EndTime
+	}
+}
+
+func adaptModelToDto(window *repository.DeploymentWindow) *DeploymentWindowDto {
+	dto := &DeploymentWindowDto{
+		Id:              window.Id,
+		Name:            window.Name,
+		Description:     window.Description,
+		WindowType:      window.WindowType,
+		EnvironmentId:   window.EnvironmentId,
+		TeamId:          window.TeamId,
+		CronExpression:  window.CronExpression,
+		DurationMinutes: window.DurationMinutes,
+		Timezone:        window.Timezone,
+	}
+	if !window.StartTime.IsZero() {
+		startTime := window.StartTime
+		dto.StartTime = &startTime
+	}
+	if !window.EndTime.IsZero() {
+		endTime := window.EndTime
+		dto.EndTime = &endTime
+	}
+	return dto
+}
@@ -0,0 +1,140 @@
+package deploymentWindow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/deploymentWindow/repository"
+	"go.uber.org/zap"
+)
+
+func TestIsWindowActiveAt(t *testing.T) {
+	at := time.Date(2023, time.November, 15, 22, 30, 0, 0, time.UTC) // wednesday
+	tests := []struct {
+		name   string
+		window *repository.DeploymentWindow
+		want   bool
+	}{
+		{name: "one-off window containing time", window: &repository.DeploymentWindow{
+			StartTime: at.Add(-time.Hour), EndTime: at.Add(time.Hour),
+		}, want: true},
+		{name: "one-off window in the past", window: &repository.DeploymentWindow{
+			StartTime: at.Add(-2 * time.Hour), EndTime: at.Add(-time.Hour),
+		}, want: false},
+		{name: "one-off window end is exclusive", window: &repository.DeploymentWindow{
+			StartTime: at.Add(-time.Hour), EndTime: at,
+		}, want: false},
+		{name: "nightly window started within duration", window: &repository.DeploymentWindow{
+			CronExpression: "0 22 * * *", DurationMinutes: 60,
+		}, want: true},
+		{name: "nightly window already over", window: &repository.DeploymentWindow{
+			CronExpression: "0 20 * * *", DurationMinutes: 60,
+		}, want: false},
+		{name: "weekend window on a weekday", window: &repository.DeploymentWindow{
+			CronExpression: "0 0 * * 6", DurationMinutes: 48 * 60,
+		}, want: false},
+		{name: "recurring window honours timezone", window: &repository.DeploymentWindow{
+			CronExpression: "0 4 * * *", DurationMinutes: 60, Timezone: "Asia/Kolkata",
+		}, want: true},
+		{name: "recurring window before its validity", window: &repository.DeploymentWindow{
+			CronExpression: "0 22 * * *", DurationMinutes: 60, StartTime: at.Add(24 * time.Hour),
+		}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isWindowActiveAt(tt.window, at)
+			if err != nil {
+				t.Errorf("isWindowActiveAt() unexpected error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("isWindowActiveAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateWindows(t *testing.T) {
+	at := time.Date(2023, time.November, 15, 22, 30, 0, 0, time.UTC)
+	logger := zap.NewNop().Sugar()
+	activeBlackout := &repository.DeploymentWindow{Id: 1, Name: "freeze", WindowType: repository.WINDOW_TYPE_BLACKOUT,
+		StartTime: at.Add(-time.Hour), EndTime: at.Add(time.Hour)}
+	inactiveBlackout := &repository.DeploymentWindow{Id: 2, Name: "old-freeze", WindowType: repository.WINDOW_TYPE_BLACKOUT,
+		StartTime: at.Add(-2 * time.Hour), EndTime: at.Add(-time.Hour)}
+	activeMaintenance := &repository.DeploymentWindow{Id: 3, Name: "nightly", WindowType: repository.WINDOW_TYPE_MAINTENANCE,
+		CronExpression: "0 22 * * *", DurationMinutes: 60}
+	inactiveMaintenance := &repository.DeploymentWindow{Id: 4, Name: "morning", WindowType: repository.WINDOW_TYPE_MAINTENANCE,
+		CronExpression: "0 9 * * *", DurationMinutes: 60}
+	tests := []struct {
+		name        string
+		windows     []*repository.DeploymentWindow
+		wantAllowed bool
+		wantBlocked []int
+	}{
+		{name: "no windows", windows: nil, wantAllowed: true},
+		{name: "inactive blackout", windows: []*repository.DeploymentWindow{inactiveBlackout}, wantAllowed: true},
+		{name: "active blackout", windows: []*repository.DeploymentWindow{activeBlackout, inactiveBlackout}, wantAllowed: false, wantBlocked: []int{1}},
+		{name: "blackout wins over maintenance", windows: []*repository.DeploymentWindow{activeMaintenance, activeBlackout}, wantAllowed: false, wantBlocked: []int{1}},
+		{name: "inside one of the maintenance windows", windows: []*repository.DeploymentWindow{inactiveMaintenance, activeMaintenance}, wantAllowed: true},
+		{name: "outside maintenance windows", windows: []*repository.DeploymentWindow{inactiveMaintenance}, wantAllowed: false, wantBlocked: []int{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := evaluateWindows(tt.windows, at, logger)
+			if state.IsAllowed != tt.wantAllowed {
+				t.Errorf("evaluateWindows() allowed = %v, want %v", state.IsAllowed, tt.wantAllowed)
+			}
+			blockedIds := state.GetBlockingWindowIds()
+			if len(blockedIds) != len(tt.wantBlocked) {
+				t.Errorf("evaluateWindows() blocking windows = %v, want %v", blockedIds, tt.wantBlocked)
+				return
+			}
+			for i := range blockedIds {
+				if blockedIds[i] != tt.wantBlocked[i] {
+					t.Errorf("evaluateWindows() blocking windows = %v, want %v", blockedIds, tt.wantBlocked)
+				}
+			}
+			if !state.IsAllowed && len(state.Reason) == 0 {
+				t.Errorf("evaluateWindows() expected a reason for blocked deployment")
+			}
+		})
+	}
+}
+
+func TestFindNextAllowedTime(t *testing.T) {
+	from := time.Date(2023, time.November, 15, 12, 0, 0, 0, time.UTC) // wednesday
+	until := from.Add(7 * 24 * time.Hour)
+	logger := zap.NewNop().Sugar()
+	tests := []struct {
+		name    string
+		windows []*repository.DeploymentWindow
+		want    *time.Time
+	}{
+		{name: "no windows", want: &from},
+		{name: "after active blackout ends", windows: []*repository.DeploymentWindow{
+			{WindowType: repository.WINDOW_TYPE_BLACKOUT, StartTime: from.Add(-time.Hour), EndTime: from.Add(3 * time.Hour)},
+		}, want: timePtr(from.Add(3 * time.Hour))},
+		{name: "start of next nightly maintenance window", windows: []*repository.DeploymentWindow{
+			{WindowType: repository.WINDOW_TYPE_MAINTENANCE, CronExpression: "0 2 * * *", DurationMinutes: 120},
+		}, want: timePtr(time.Date(2023, time.November, 16, 2, 0, 0, 0, time.UTC))},
+		{name: "maintenance window occurrence blocked by blackout", windows: []*repository.DeploymentWindow{
+			{WindowType: repository.WINDOW_TYPE_MAINTENANCE, CronExpression: "0 2 * * *", DurationMinutes: 120},
+			{WindowType: repository.WINDOW_TYPE_BLACKOUT, StartTime: from, EndTime: time.Date(2023, time.November, 16, 3, 0, 0, 0, time.UTC)},
+		}, want: timePtr(time.Date(2023, time.November, 16, 3, 0, 0, 0, time.UTC))},
+		{name: "blocked for the whole horizon", windows: []*repository.DeploymentWindow{
+			{WindowType: repository.WINDOW_TYPE_BLACKOUT, StartTime: from.Add(-time.Hour), EndTime: until.Add(time.Hour)},
+		}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findNextAllowedTime(tt.windows, from, until, logger)
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("findNextAllowedTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
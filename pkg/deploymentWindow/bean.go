@@ -0,0 +1,46 @@
+package deploymentWindow
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/deploymentWindow/repository"
+)
+
+type DeploymentWindowDto struct {
+	Id              int                   `json:"id"`
+	Name            string                `json:"name" validate:"required,max=250"`
+	Description     string                `json:"description"`
+	WindowType      repository.WindowType `json:"windowType" validate:"oneof=MAINTENANCE BLACKOUT"`
+	EnvironmentId   int                   `json:"environmentId"`
+	TeamId          int                   `json:"teamId"`
+	CronExpression  string                `json:"cronExpression"`
+	DurationMinutes int                   `json:"durationMinutes"`
+	StartTime       *time.Time            `json:"startTime,omitempty"`
+	EndTime         *time.Time            `json:"endTime,omitempty"`
+	Timezone        string                `json:"timezone"`
+	UserId          int32                 `json:"-"`
+}
+
+// DeploymentWindowState is the outcome of evaluating all the windows applicable on a cd pipeline at a point in time
+type DeploymentWindowState struct {
+	IsAllowed       bool                   `json:"isAllowed"`
+	Reason          string                 `json:"reason,omitempty"`
+	BlockingWindows []*DeploymentWindowDto `json:"blockingWindows,omitempty"`
+}
+
+func (state *DeploymentWindowState) GetBlockingWindowIds() []int {
+	ids := make([]int, 0, len(state.BlockingWindows))
+	for _, window := range state.BlockingWindows {
+		ids = append(ids, window.Id)
+	}
+	return ids
+}
+
+type DeploymentWindowOverrideRequest struct {
+	PipelineId         int
+	CiArtifactId       int
+	CdWorkflowRunnerId int
+	Justification      string
+	State              *DeploymentWindowState
+	UserId             int32
+}
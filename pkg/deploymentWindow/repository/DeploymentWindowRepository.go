@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+type WindowType string
+
+const (
+	// WINDOW_TYPE_MAINTENANCE windows are the only slots in which deployments are allowed for the scope, if any is configured
+	WINDOW_TYPE_MAINTENANCE WindowType = "MAINTENANCE"
+	// WINDOW_TYPE_BLACKOUT windows block deployments for the scope while they are active (change freeze)
+	WINDOW_TYPE_BLACKOUT WindowType = "BLACKOUT"
+)
+
+type DeploymentWindow struct {
+	tableName       struct{}   `sql:"deployment_window" pg:",discard_unknown_columns"`
+	Id              int        `sql:"id,pk"`
+	Name            string     `sql:"name,notnull"`
+	Description     string     `sql:"description"`
+	WindowType      WindowType `sql:"window_type,notnull"`
+	EnvironmentId   int        `sql:"environment_id"`
+	TeamId          int        `sql:"team_id"`
+	CronExpression  string     `sql:"cron_expression"`
+	DurationMinutes int        `sql:"duration_minutes"`
+	StartTime       time.Time  `sql:"start_time"`
+	EndTime         time.Time  `sql:"end_time"`
+	Timezone        string     `sql:"timezone"`
+	Active          bool       `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type DeploymentWindowOverrideAudit struct {
+	tableName          struct{} `sql:"deployment_window_override_audit" pg:",discard_unknown_columns"`
+	Id                 int      `sql:"id,pk"`
+	PipelineId         int      `sql:"pipeline_id,notnull"`
+	CiArtifactId       int      `sql:"ci_artifact_id,notnull"`
+	CdWorkflowRunnerId int      `sql:"cd_workflow_runner_id"`
+	WindowIds          []int    `sql:"window_ids" pg:",array"`
+	Justification      string   `sql:"justification,notnull"`
+	sql.AuditLog
+}
+
+type DeploymentWindowRepository interface {
+	Save(window *DeploymentWindow) error
+	Update(window *DeploymentWindow) error
+	FindById(id int) (*DeploymentWindow, error)
+	FindAllActive() ([]*DeploymentWindow, error)
+	FindActiveByEnvOrTeam(envId int, teamId int) ([]*DeploymentWindow, error)
+	SaveOverrideAudit(audit *DeploymentWindowOverrideAudit) error
+	FindOverrideAuditsByPipelineId(pipelineId int) ([]*DeploymentWindowOverrideAudit, error)
+}
+
+type DeploymentWindowRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewDeploymentWindowRepositoryImpl(dbConnection *pg.DB) *DeploymentWindowRepositoryImpl {
+	return &DeploymentWindowRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *DeploymentWindowRepositoryImpl) Save(window *DeploymentWindow) error {
+	return impl.dbConnection.Insert(window)
+}
+
+func (impl *DeploymentWindowRepositoryImpl) Update(window *DeploymentWindow) error {
+	return impl.dbConnection.Update(window)
+}
+
+func (impl *DeploymentWindowRepositoryImpl) FindById(id int) (*DeploymentWindow, error) {
+	window := &DeploymentWindow{}
+	err := impl.dbConnection.Model(window).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return window, err
+}
+
+func (impl *DeploymentWindowRepositoryImpl) FindAllActive() ([]*DeploymentWindow, error) {
+	var windows []*DeploymentWindow
+	err := impl.dbConnection.Model(&windows).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return windows, err
+}
+
+// FindActiveByEnvOrTeam returns every active window which applies to an app of the given team deployed on the given
+// environment, i.e. windows scoped to the env, to the team, or to both the env and the team
+func (impl *DeploymentWindowRepositoryImpl) FindActiveByEnvOrTeam(envId int, teamId int) ([]*DeploymentWindow, error) {
+	var windows []*DeploymentWindow
+	err := impl.dbConnection.Model(&windows).
+		Where("active = ?", true).
+		Where("environment_id = ? OR environment_id IS NULL", envId).
+		Where("team_id = ? OR team_id IS NULL", teamId).
+		Where("NOT (environment_id IS NULL AND team_id IS NULL)").
+		Select()
+	return windows, err
+}
+
+func (impl *DeploymentWindowRepositoryImpl) SaveOverrideAudit(audit *DeploymentWindowOverrideAudit) error {
+	return impl.dbConnection.Insert(audit)
+}
+
+func (impl *DeploymentWindowRepositoryImpl) FindOverrideAuditsByPipelineId(pipelineId int) ([]*DeploymentWindowOverrideAudit, error) {
+	var audits []*DeploymentWindowOverrideAudit
+	err := impl.dbConnection.Model(&audits).
+		Where("pipeline_id = ?", pipelineId).
+		Order("id DESC").
+		Select()
+	return audits, err
+}
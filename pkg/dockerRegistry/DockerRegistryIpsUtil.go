@@ -173,3 +173,37 @@ func CreateCredentialForEcr(awsRegion, awsAccessKey, awsSecretKey string) (strin
 
 	return username, pwd, nil
 }
+
+// DeleteEcrImage deletes the image of the digest, or of the tag when the digest is not known, from the ecr repository,
+// ecr does not serve the registry api for deleting manifests
+func DeleteEcrImage(awsRegion, awsAccessKey, awsSecretKey, repository, digest, tag string) error {
+	creds := credentials.NewStaticCredentials(awsAccessKey, awsSecretKey, "")
+	sess, err := session.NewSession(&aws.Config{
+		Region:      &awsRegion,
+		Credentials: creds,
+	})
+	if err != nil {
+		return err
+	}
+	imageId := &ecr.ImageIdentifier{}
+	if len(digest) > 0 {
+		imageId.ImageDigest = aws.String(digest)
+	} else {
+		imageId.ImageTag = aws.String(tag)
+	}
+	svc := ecr.New(sess)
+	output, err := svc.BatchDeleteImage(&ecr.BatchDeleteImageInput{
+		RepositoryName: aws.String(repository),
+		ImageIds:       []*ecr.ImageIdentifier{imageId},
+	})
+	if err != nil {
+		return err
+	}
+	for _, failure := range output.Failures {
+		if aws.StringValue(failure.FailureCode) == ecr.ImageFailureCodeImageNotFound {
+			continue
+		}
+		return fmt.Errorf("error in deleting image from ecr repository %s, %s: %s", repository, aws.StringValue(failure.FailureCode), aws.StringValue(failure.FailureReason))
+	}
+	return nil
+}
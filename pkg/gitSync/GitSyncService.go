@@ -0,0 +1,618 @@
+package gitSync
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caarlos0/env"
+	appBean "github.com/devtron-labs/devtron/api/appbean"
+	"github.com/devtron-labs/devtron/client/gitSensor"
+	repository3 "github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/coreApp"
+	"github.com/devtron-labs/devtron/pkg/gitSync/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type GitSyncServiceConfig struct {
+	// GitSyncCheckoutDir is the directory under which the repositories of git sync configs are checked out
+	GitSyncCheckoutDir string `env:"GIT_SYNC_CHECKOUT_DIR" envDefault:"/tmp/git-sync"`
+}
+
+type GitSyncService interface {
+	CreateConfig(request *GitSyncConfigDto) (*GitSyncConfigDto, error)
+	UpdateConfig(request *GitSyncConfigDto) (*GitSyncConfigDto, error)
+	DeleteConfig(id int, userId int32) error
+	GetConfig(id int) (*GitSyncConfigDto, error)
+	GetAllConfigs() ([]*GitSyncConfigDto, error)
+	// Sync reconciles the config with the head of its branch now, changes are applied unless dryRun
+	Sync(configId int, dryRun bool, userId int32) (*GitSyncResultDto, error)
+	GetLatestResult(configId int) (*GitSyncResultDto, error)
+	// GetResults returns the latest reconciliations of the config without their resource statuses
+	GetResults(configId int, limit int) ([]*GitSyncResultDto, error)
+	// ReconcileAll reconciles all configs, changes are applied only for the configs with auto sync
+	ReconcileAll()
+}
+
+type GitSyncServiceImpl struct {
+	logger                       *zap.SugaredLogger
+	gitSyncConfigRepository      repository.GitSyncConfigRepository
+	gitSyncResultRepository      repository.GitSyncResultRepository
+	ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository
+	materialRepository           pipelineConfig.MaterialRepository
+	gitSensorClient              gitSensor.Client
+	gitCliUtil                   *util.GitCliUtil
+	coreAppService               coreApp.CoreAppService
+	enforcer                     casbin.Enforcer
+	userService                  user.UserService
+	config                       *GitSyncServiceConfig
+	// syncLocks keeps one reconciliation of a config at a time, keyed by config id
+	syncLocks sync.Map
+}
+
+func NewGitSyncServiceImpl(logger *zap.SugaredLogger,
+	gitSyncConfigRepository repository.GitSyncConfigRepository,
+	gitSyncResultRepository repository.GitSyncResultRepository,
+	ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository,
+	materialRepository pipelineConfig.MaterialRepository,
+	gitSensorClient gitSensor.Client, gitCliUtil *util.GitCliUtil,
+	coreAppService coreApp.CoreAppService, enforcer casbin.Enforcer, userService user.UserService) *GitSyncServiceImpl {
+	cfg := &GitSyncServiceConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Infow("error occurred while parsing GitSyncServiceConfig, so setting checkout dir to default value", "err", err)
+		cfg.GitSyncCheckoutDir = "/tmp/git-sync"
+	}
+	return &GitSyncServiceImpl{
+		logger:                       logger,
+		gitSyncConfigRepository:      gitSyncConfigRepository,
+		gitSyncResultRepository:      gitSyncResultRepository,
+		ciPipelineMaterialRepository: ciPipelineMaterialRepository,
+		materialRepository:           materialRepository,
+		gitSensorClient:              gitSensorClient,
+		gitCliUtil:                   gitCliUtil,
+		coreAppService:               coreAppService,
+		enforcer:                     enforcer,
+		userService:                  userService,
+		config:                       cfg,
+	}
+}
+
+func (impl *GitSyncServiceImpl) CreateConfig(request *GitSyncConfigDto) (*GitSyncConfigDto, error) {
+	err := impl.validateConfig(request)
+	if err != nil {
+		return nil, err
+	}
+	config := &repository.GitSyncConfig{
+		Name:                 request.Name,
+		CiPipelineMaterialId: request.CiPipelineMaterialId,
+		Path:                 request.Path,
+		AutoSync:             request.AutoSync,
+		Prune:                request.Prune,
+		Active:               true,
+		AuditLog:             sql.NewDefaultAuditLog(request.UserId),
+	}
+	err = impl.gitSyncConfigRepository.Save(config)
+	if err != nil {
+		impl.logger.Errorw("error in saving git sync config", "config", config, "err", err)
+		return nil, err
+	}
+	return impl.adaptConfigToDto(config)
+}
+
+func (impl *GitSyncServiceImpl) UpdateConfig(request *GitSyncConfigDto) (*GitSyncConfigDto, error) {
+	config, err := impl.gitSyncConfigRepository.FindById(request.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching git sync config", "id", request.Id, "err", err)
+		return nil, err
+	}
+	err = impl.validateConfig(request)
+	if err != nil {
+		return nil, err
+	}
+	config.Name = request.Name
+	config.CiPipelineMaterialId = request.CiPipelineMaterialId
+	config.Path = request.Path
+	config.AutoSync = request.AutoSync
+	config.Prune = request.Prune
+	config.UpdatedOn = time.Now()
+	config.UpdatedBy = request.UserId
+	err = impl.gitSyncConfigRepository.Update(config)
+	if err != nil {
+		impl.logger.Errorw("error in updating git sync config", "config", config, "err", err)
+		return nil, err
+	}
+	return impl.adaptConfigToDto(config)
+}
+
+func (impl *GitSyncServiceImpl) DeleteConfig(id int, userId int32) error {
+	config, err := impl.gitSyncConfigRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching git sync config", "id", id, "err", err)
+		return err
+	}
+	config.Active = false
+	config.UpdatedOn = time.Now()
+	config.UpdatedBy = userId
+	err = impl.gitSyncConfigRepository.Update(config)
+	if err != nil {
+		impl.logger.Errorw("error in deleting git sync config", "id", id, "err", err)
+		return err
+	}
+	err = os.RemoveAll(impl.getCheckoutDir(id))
+	if err != nil {
+		impl.logger.Warnw("error in removing checkout of deleted git sync config", "id", id, "err", err)
+	}
+	return nil
+}
+
+func (impl *GitSyncServiceImpl) GetConfig(id int) (*GitSyncConfigDto, error) {
+	config, err := impl.gitSyncConfigRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching git sync config", "id", id, "err", err)
+		return nil, err
+	}
+	return impl.adaptConfigToDto(config)
+}
+
+func (impl *GitSyncServiceImpl) GetAllConfigs() ([]*GitSyncConfigDto, error) {
+	configs, err := impl.gitSyncConfigRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching git sync configs", "err", err)
+		return nil, err
+	}
+	result := make([]*GitSyncConfigDto, 0, len(configs))
+	for _, config := range configs {
+		dto, err := impl.adaptConfigToDto(config)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dto)
+	}
+	return result, nil
+}
+
+func (impl *GitSyncServiceImpl) Sync(configId int, dryRun bool, userId int32) (*GitSyncResultDto, error) {
+	config, err := impl.gitSyncConfigRepository.FindById(configId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching git sync config", "id", configId, "err", err)
+		return nil, err
+	}
+	result, statuses, err := impl.reconcile(config, dryRun, userId)
+	if err != nil {
+		return nil, err
+	}
+	return adaptResultToDto(result, statuses), nil
+}
+
+func (impl *GitSyncServiceImpl) GetLatestResult(configId int) (*GitSyncResultDto, error) {
+	result, err := impl.gitSyncResultRepository.FindLatestByConfigId(configId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching latest git sync result", "configId", configId, "err", err)
+		return nil, err
+	}
+	statuses, err := impl.gitSyncResultRepository.FindResourceStatusesByResultId(result.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching git sync resource statuses", "resultId", result.Id, "err", err)
+		return nil, err
+	}
+	return adaptResultToDto(result, statuses), nil
+}
+
+func (impl *GitSyncServiceImpl) GetResults(configId int, limit int) ([]*GitSyncResultDto, error) {
+	results, err := impl.gitSyncResultRepository.FindByConfigId(configId, limit)
+	if err != nil {
+		impl.logger.Errorw("error in fetching git sync results", "configId", configId, "err", err)
+		return nil, err
+	}
+	resultDtos := make([]*GitSyncResultDto, 0, len(results))
+	for _, result := range results {
+		resultDtos = append(resultDtos, adaptResultToDto(result, nil))
+	}
+	return resultDtos, nil
+}
+
+func (impl *GitSyncServiceImpl) ReconcileAll() {
+	configs, err := impl.gitSyncConfigRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching git sync configs", "err", err)
+		return
+	}
+	for _, config := range configs {
+		// changes are applied as the user who last saved the config
+		_, _, err = impl.reconcile(config, !config.AutoSync, config.UpdatedBy)
+		if err != nil {
+			impl.logger.Errorw("error in reconciling git sync config", "configId", config.Id, "err", err)
+		}
+	}
+}
+
+// reconcile checks out the head of the branch of the config and applies every app document found under its path,
+// the outcome is saved per component of every document
+func (impl *GitSyncServiceImpl) reconcile(config *repository.GitSyncConfig, dryRun bool, userId int32) (*repository.GitSyncResult, []*repository.GitSyncResourceStatus, error) {
+	lock, _ := impl.syncLocks.LoadOrStore(config.Id, &sync.Mutex{})
+	if !lock.(*sync.Mutex).TryLock() {
+		return nil, nil, &util.ApiError{
+			HttpStatusCode:  http.StatusConflict,
+			InternalMessage: "git sync is already running for the config",
+			UserMessage:     fmt.Sprintf("git sync is already running for config %s", config.Name),
+		}
+	}
+	defer lock.(*sync.Mutex).Unlock()
+
+	result := &repository.GitSyncResult{
+		GitSyncConfigId: config.Id,
+		Status:          repository.GIT_SYNC_STATUS_RUNNING,
+		DryRun:          dryRun,
+		StartedOn:       time.Now(),
+		AuditLog:        sql.NewDefaultAuditLog(userId),
+	}
+	err := impl.gitSyncResultRepository.SaveResult(result)
+	if err != nil {
+		impl.logger.Errorw("error in saving git sync result", "configId", config.Id, "err", err)
+		return nil, nil, err
+	}
+	statuses, err := impl.reconcileCommit(config, result, dryRun, userId)
+	result.FinishedOn = time.Now()
+	result.UpdatedOn = time.Now()
+	if err != nil {
+		result.Status = repository.GIT_SYNC_STATUS_FAILED
+		result.Message = err.Error()
+	} else {
+		lastApplied, err := impl.gitSyncResultRepository.FindLatestAppliedByConfigId(config.Id)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching last applied git sync result", "configId", config.Id, "err", err)
+			return nil, nil, err
+		}
+		if err == nil {
+			result.DriftDetected = isDriftDetected(lastApplied, result.CommitHash, statuses)
+		}
+		result.Status, result.Message = summariseResourceStatuses(statuses)
+	}
+	for _, status := range statuses {
+		status.GitSyncResultId = result.Id
+		status.AuditLog = sql.NewDefaultAuditLog(userId)
+	}
+
+	dbConnection := impl.gitSyncResultRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = impl.gitSyncResultRepository.UpdateResult(result, tx)
+	if err != nil {
+		impl.logger.Errorw("error in updating git sync result", "resultId", result.Id, "err", err)
+		return nil, nil, err
+	}
+	err = impl.gitSyncResultRepository.SaveResourceStatuses(statuses, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving git sync resource statuses", "resultId", result.Id, "err", err)
+		return nil, nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.DriftDetected {
+		impl.logger.Infow("drift detected between git and devtron", "configId", config.Id, "commit", result.CommitHash)
+	}
+	return result, statuses, nil
+}
+
+// reconcileCommit applies the app documents of the head commit, pipelines of existing workflows are updated as in the
+// documents and the ones removed from the documents are deleted if the config prunes
+func (impl *GitSyncServiceImpl) reconcileCommit(config *repository.GitSyncConfig, result *repository.GitSyncResult, dryRun bool, userId int32) ([]*repository.GitSyncResourceStatus, error) {
+	ciPipelineMaterial, err := impl.ciPipelineMaterialRepository.GetById(config.CiPipelineMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci pipeline material of git sync config", "configId", config.Id, "err", err)
+		return nil, err
+	}
+	gitMaterial, err := impl.materialRepository.FindById(ciPipelineMaterial.GitMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching git material of git sync config", "configId", config.Id, "err", err)
+		return nil, err
+	}
+	heads, err := impl.gitSensorClient.GetHeadForPipelineMaterials(context.Background(), &gitSensor.HeadRequest{MaterialIds: []int{ciPipelineMaterial.Id}})
+	if err != nil {
+		impl.logger.Errorw("error in fetching head from git sensor", "ciPipelineMaterialId", ciPipelineMaterial.Id, "err", err)
+		return nil, err
+	}
+	if len(heads) == 0 || len(heads[0].GitCommit.Commit) == 0 {
+		return nil, fmt.Errorf("no commit found by git sensor for branch %s of %s", ciPipelineMaterial.Value, gitMaterial.Url)
+	}
+	result.CommitHash = heads[0].GitCommit.Commit
+
+	checkoutDir := impl.getCheckoutDir(config.Id)
+	err = impl.checkoutCommit(checkoutDir, gitMaterial, result.CommitHash)
+	if err != nil {
+		return nil, err
+	}
+	files, err := findAppDocumentFiles(checkoutDir, config.Path)
+	if err != nil {
+		impl.logger.Errorw("error in finding app documents", "configId", config.Id, "path", config.Path, "err", err)
+		return nil, err
+	}
+
+	// documents are applied with the permissions of the owner of the config, that is the user who last saved it
+	ownerEmailId, err := impl.userService.GetEmailById(config.UpdatedBy)
+	if err != nil {
+		impl.logger.Errorw("error in fetching owner of git sync config", "configId", config.Id, "userId", config.UpdatedBy, "err", err)
+		return nil, err
+	}
+	authorizer := coreApp.NewEmailAuthorizer(impl.enforcer, ownerEmailId)
+
+	var statuses []*repository.GitSyncResourceStatus
+	appFiles := make(map[string]string)
+	for _, file := range files {
+		filePath, _ := filepath.Rel(checkoutDir, file)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			statuses = append(statuses, newDocumentFailedStatus(filePath, "", err))
+			continue
+		}
+		document, err := appBean.ParseAppDocument(data)
+		if err != nil {
+			statuses = append(statuses, newDocumentFailedStatus(filePath, "", err))
+			continue
+		}
+		appName := document.Spec.Metadata.AppName
+		if appFile, ok := appFiles[appName]; ok {
+			statuses = append(statuses, newDocumentFailedStatus(filePath, appName, fmt.Errorf("app is already defined in %s", appFile)))
+			continue
+		}
+		appFiles[appName] = filePath
+		resp, applyErr, _ := impl.coreAppService.ApplyAppDocument(context.Background(), document.Spec, userId, authorizer, dryRun, config.Prune)
+		if applyErr != nil {
+			impl.logger.Errorw("error in applying app document", "configId", config.Id, "filePath", filePath, "err", applyErr)
+		}
+		if resp == nil {
+			statuses = append(statuses, newDocumentFailedStatus(filePath, appName, applyErr))
+			continue
+		}
+		statuses = append(statuses, getResourceStatuses(filePath, resp, applyErr)...)
+	}
+	return statuses, nil
+}
+
+// checkoutCommit fetches the repository of the git material in checkoutDir, initialising it on first use, and checks
+// out the commit
+func (impl *GitSyncServiceImpl) checkoutCommit(checkoutDir string, gitMaterial *pipelineConfig.GitMaterial, commitHash string) error {
+	userName, password, err := getGitCredentials(gitMaterial.GitProvider)
+	if err != nil {
+		return err
+	}
+	if _, err = os.Stat(filepath.Join(checkoutDir, ".git")); os.IsNotExist(err) {
+		err = impl.gitCliUtil.Init(checkoutDir, gitMaterial.Url, false)
+		if err != nil {
+			impl.logger.Errorw("error in initialising git sync checkout", "url", gitMaterial.Url, "err", err)
+			return err
+		}
+	}
+	_, errMsg, err := impl.gitCliUtil.Fetch(checkoutDir, userName, password)
+	if err != nil {
+		impl.logger.Errorw("error in fetching git sync repository", "url", gitMaterial.Url, "errMsg", errMsg, "err", err)
+		return fmt.Errorf("error in fetching %s: %s", gitMaterial.Url, errMsg)
+	}
+	_, errMsg, err = impl.gitCliUtil.Checkout(checkoutDir, commitHash)
+	if err != nil {
+		impl.logger.Errorw("error in checking out git sync commit", "url", gitMaterial.Url, "commit", commitHash, "errMsg", errMsg, "err", err)
+		return fmt.Errorf("error in checking out %s of %s: %s", commitHash, gitMaterial.Url, errMsg)
+	}
+	return nil
+}
+
+func (impl *GitSyncServiceImpl) getCheckoutDir(configId int) string {
+	return filepath.Join(impl.config.GitSyncCheckoutDir, strconv.Itoa(configId))
+}
+
+func (impl *GitSyncServiceImpl) validateConfig(request *GitSyncConfigDto) error {
+	var validationErr string
+	existing, err := impl.gitSyncConfigRepository.FindActiveByName(request.Name)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching git sync config by name", "name", request.Name, "err", err)
+		return err
+	}
+	if err == nil && existing.Id != request.Id {
+		validationErr = fmt.Sprintf("git sync config %s already exists", request.Name)
+	} else if strings.Contains(filepath.Clean("/"+request.Path), "..") {
+		validationErr = "path must be inside the repository"
+	} else {
+		ciPipelineMaterial, err := impl.ciPipelineMaterialRepository.GetById(request.CiPipelineMaterialId)
+		if err == pg.ErrNoRows {
+			validationErr = fmt.Sprintf("ci pipeline material %d not found", request.CiPipelineMaterialId)
+		} else if err != nil {
+			impl.logger.Errorw("error in fetching ci pipeline material", "id", request.CiPipelineMaterialId, "err", err)
+			return err
+		} else if ciPipelineMaterial.Type != pipelineConfig.SOURCE_TYPE_BRANCH_FIXED {
+			validationErr = "ci pipeline material must track a fixed branch"
+		}
+	}
+	if len(validationErr) > 0 {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: validationErr,
+			UserMessage:     validationErr,
+		}
+	}
+	return nil
+}
+
+func (impl *GitSyncServiceImpl) adaptConfigToDto(config *repository.GitSyncConfig) (*GitSyncConfigDto, error) {
+	dto := &GitSyncConfigDto{
+		Id:                   config.Id,
+		Name:                 config.Name,
+		CiPipelineMaterialId: config.CiPipelineMaterialId,
+		Path:                 config.Path,
+		AutoSync:             config.AutoSync,
+		Prune:                config.Prune,
+	}
+	ciPipelineMaterial, err := impl.ciPipelineMaterialRepository.GetById(config.CiPipelineMaterialId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching ci pipeline material", "id", config.CiPipelineMaterialId, "err", err)
+		return nil, err
+	}
+	if err == nil {
+		dto.Branch = ciPipelineMaterial.Value
+		if ciPipelineMaterial.GitMaterial != nil {
+			dto.GitRepoUrl = ciPipelineMaterial.GitMaterial.Url
+		}
+	}
+	return dto, nil
+}
+
+func getGitCredentials(gitProvider *repository3.GitProvider) (userName string, password string, err error) {
+	if gitProvider == nil {
+		return "", "", fmt.Errorf("git provider not found")
+	}
+	switch gitProvider.AuthMode {
+	case repository3.AUTH_MODE_USERNAME_PASSWORD:
+		return gitProvider.UserName, gitProvider.Password, nil
+	case repository3.AUTH_MODE_ACCESS_TOKEN:
+		return gitProvider.UserName, gitProvider.AccessToken, nil
+	case repository3.AUTH_MODE_ANONYMOUS:
+		return "", "", nil
+	default:
+		return "", "", fmt.Errorf("auth mode %s of git provider %s is not supported by git sync", gitProvider.AuthMode, gitProvider.Name)
+	}
+}
+
+// findAppDocumentFiles returns the yaml files under path of the checkout in lexical order
+func findAppDocumentFiles(checkoutDir string, path string) ([]string, error) {
+	root := filepath.Join(checkoutDir, filepath.Clean("/"+path))
+	var files []string
+	err := filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		extension := strings.ToLower(filepath.Ext(file))
+		if extension == ".yaml" || extension == ".yml" {
+			files = append(files, file)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// getResourceStatuses maps the plan of an app document to the status of each component, changes not applied are
+// out of sync and are failed if applying them failed
+func getResourceStatuses(filePath string, resp *appBean.AppApplyResponse, applyErr error) []*repository.GitSyncResourceStatus {
+	var statuses []*repository.GitSyncResourceStatus
+	isApplyFailed := applyErr != nil && appBean.IsAppApplyPlanApplicable(resp.Plan)
+	for _, item := range resp.Plan {
+		status := &repository.GitSyncResourceStatus{
+			FilePath:  filePath,
+			AppName:   resp.AppName,
+			Component: string(item.Component),
+			Name:      item.Name,
+			Message:   item.Message,
+		}
+		if len(item.Workflow) > 0 {
+			// pipelines are named within their workflow
+			status.Name = fmt.Sprintf("%s/%s", item.Workflow, item.Name)
+		}
+		switch {
+		case item.Action == appBean.APP_APPLY_ACTION_NO_CHANGE:
+			status.Status = repository.GIT_SYNC_RESOURCE_SYNCED
+		case item.Action == appBean.APP_APPLY_ACTION_UNSUPPORTED:
+			status.Status = repository.GIT_SYNC_RESOURCE_UNSUPPORTED
+		case resp.Applied:
+			status.Status = repository.GIT_SYNC_RESOURCE_APPLIED
+		case isApplyFailed:
+			status.Status = repository.GIT_SYNC_RESOURCE_FAILED
+			status.Message = applyErr.Error()
+		default:
+			status.Status = repository.GIT_SYNC_RESOURCE_OUT_OF_SYNC
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func newDocumentFailedStatus(filePath string, appName string, err error) *repository.GitSyncResourceStatus {
+	return &repository.GitSyncResourceStatus{
+		FilePath:  filePath,
+		AppName:   appName,
+		Component: GIT_SYNC_COMPONENT_DOCUMENT,
+		Status:    repository.GIT_SYNC_RESOURCE_FAILED,
+		Message:   err.Error(),
+	}
+}
+
+// isDriftDetected tells if devtron was changed outside git, that is the commit is the one last applied successfully and
+// still some components differ from it. Dry runs are not a baseline as their changes were never applied.
+func isDriftDetected(lastApplied *repository.GitSyncResult, commitHash string, statuses []*repository.GitSyncResourceStatus) bool {
+	if lastApplied == nil || lastApplied.DryRun || lastApplied.CommitHash != commitHash {
+		return false
+	}
+	for _, status := range statuses {
+		if status.Component == GIT_SYNC_COMPONENT_DOCUMENT {
+			continue
+		}
+		if status.Status != repository.GIT_SYNC_RESOURCE_SYNCED {
+			return true
+		}
+	}
+	return false
+}
+
+func summariseResourceStatuses(statuses []*repository.GitSyncResourceStatus) (repository.GitSyncStatus, string) {
+	counts := make(map[repository.GitSyncResourceStatusType]int)
+	for _, status := range statuses {
+		counts[status.Status]++
+	}
+	message := fmt.Sprintf("%d synced, %d applied, %d out of sync, %d unsupported, %d failed",
+		counts[repository.GIT_SYNC_RESOURCE_SYNCED], counts[repository.GIT_SYNC_RESOURCE_APPLIED], counts[repository.GIT_SYNC_RESOURCE_OUT_OF_SYNC],
+		counts[repository.GIT_SYNC_RESOURCE_UNSUPPORTED], counts[repository.GIT_SYNC_RESOURCE_FAILED])
+	if counts[repository.GIT_SYNC_RESOURCE_FAILED] > 0 {
+		return repository.GIT_SYNC_STATUS_FAILED, message
+	}
+	return repository.GIT_SYNC_STATUS_SUCCEEDED, message
+}
+
+func adaptResultToDto(result *repository.GitSyncResult, statuses []*repository.GitSyncResourceStatus) *GitSyncResultDto {
+	dto := &GitSyncResultDto{
+		Id:              result.Id,
+		GitSyncConfigId: result.GitSyncConfigId,
+		CommitHash:      result.CommitHash,
+		Status:          result.Status,
+		DryRun:          result.DryRun,
+		DriftDetected:   result.DriftDetected,
+		Message:         result.Message,
+		StartedOn:       result.StartedOn,
+		FinishedOn:      result.FinishedOn,
+	}
+	for _, status := range statuses {
+		dto.Resources = append(dto.Resources, &GitSyncResourceStatusDto{
+			FilePath:  status.FilePath,
+			AppName:   status.AppName,
+			Component: status.Component,
+			Name:      status.Name,
+			Status:    status.Status,
+			Message:   status.Message,
+		})
+	}
+	return dto
+}
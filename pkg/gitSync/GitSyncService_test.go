@@ -0,0 +1,116 @@
+package gitSync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	appBean "github.com/devtron-labs/devtron/api/appbean"
+	repository3 "github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/pkg/gitSync/repository"
+)
+
+func TestGetResourceStatuses(t *testing.T) {
+	plan := []*appBean.AppApplyPlanItem{
+		{Component: appBean.APP_COMPONENT_LABELS, Action: appBean.APP_APPLY_ACTION_NO_CHANGE},
+		{Component: appBean.APP_COMPONENT_DEPLOYMENT_TEMPLATE, Action: appBean.APP_APPLY_ACTION_UPDATE},
+		{Component: appBean.APP_COMPONENT_CD_PIPELINE, Name: "dev", Workflow: "wf-1", Action: appBean.APP_APPLY_ACTION_DELETE},
+		{Component: appBean.APP_COMPONENT_CI_PIPELINE, Name: "ci-1", Workflow: "wf-1", Action: appBean.APP_APPLY_ACTION_UNSUPPORTED},
+	}
+	applicablePlan := plan[:3]
+	tests := []struct {
+		name     string
+		resp     *appBean.AppApplyResponse
+		applyErr error
+		want     []repository.GitSyncResourceStatusType
+	}{
+		{name: "applied", resp: &appBean.AppApplyResponse{Applied: true, Plan: applicablePlan},
+			want: []repository.GitSyncResourceStatusType{repository.GIT_SYNC_RESOURCE_SYNCED, repository.GIT_SYNC_RESOURCE_APPLIED, repository.GIT_SYNC_RESOURCE_APPLIED}},
+		{name: "dry run", resp: &appBean.AppApplyResponse{DryRun: true, Plan: applicablePlan},
+			want: []repository.GitSyncResourceStatusType{repository.GIT_SYNC_RESOURCE_SYNCED, repository.GIT_SYNC_RESOURCE_OUT_OF_SYNC, repository.GIT_SYNC_RESOURCE_OUT_OF_SYNC}},
+		{name: "apply failed", resp: &appBean.AppApplyResponse{Plan: applicablePlan}, applyErr: fmt.Errorf("boom"),
+			want: []repository.GitSyncResourceStatusType{repository.GIT_SYNC_RESOURCE_SYNCED, repository.GIT_SYNC_RESOURCE_FAILED, repository.GIT_SYNC_RESOURCE_FAILED}},
+		{name: "unsupported change blocks apply", resp: &appBean.AppApplyResponse{Plan: plan}, applyErr: fmt.Errorf("unsupported"),
+			want: []repository.GitSyncResourceStatusType{repository.GIT_SYNC_RESOURCE_SYNCED, repository.GIT_SYNC_RESOURCE_OUT_OF_SYNC, repository.GIT_SYNC_RESOURCE_OUT_OF_SYNC, repository.GIT_SYNC_RESOURCE_UNSUPPORTED}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []repository.GitSyncResourceStatusType
+			for _, status := range getResourceStatuses("apps/payments.yaml", tt.resp, tt.applyErr) {
+				got = append(got, status.Status)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getResourceStatuses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	statuses := getResourceStatuses("apps/payments.yaml", &appBean.AppApplyResponse{Applied: true, Plan: applicablePlan}, nil)
+	if statuses[2].Name != "wf-1/dev" {
+		t.Errorf("getResourceStatuses() name = %s, want the cd pipeline named within its workflow", statuses[2].Name)
+	}
+}
+
+func TestIsDriftDetected(t *testing.T) {
+	outOfSync := []*repository.GitSyncResourceStatus{
+		{Component: string(appBean.APP_COMPONENT_LABELS), Status: repository.GIT_SYNC_RESOURCE_SYNCED},
+		{Component: string(appBean.APP_COMPONENT_CONFIG_MAP), Status: repository.GIT_SYNC_RESOURCE_OUT_OF_SYNC},
+	}
+	documentFailed := []*repository.GitSyncResourceStatus{
+		{Component: GIT_SYNC_COMPONENT_DOCUMENT, Status: repository.GIT_SYNC_RESOURCE_FAILED},
+	}
+	previous := &repository.GitSyncResult{CommitHash: "abc"}
+	if !isDriftDetected(previous, "abc", outOfSync) {
+		t.Errorf("isDriftDetected() = false for changes on reconciled commit")
+	}
+	if isDriftDetected(previous, "def", outOfSync) {
+		t.Errorf("isDriftDetected() = true for changes of a new commit")
+	}
+	if isDriftDetected(&repository.GitSyncResult{CommitHash: "abc", DryRun: true}, "abc", outOfSync) {
+		t.Errorf("isDriftDetected() = true against a dry run")
+	}
+	if isDriftDetected(nil, "abc", outOfSync) {
+		t.Errorf("isDriftDetected() = true without previous reconciliation")
+	}
+	if isDriftDetected(previous, "abc", documentFailed) {
+		t.Errorf("isDriftDetected() = true for invalid document")
+	}
+}
+
+func TestGetGitCredentials(t *testing.T) {
+	userName, password, err := getGitCredentials(&repository3.GitProvider{AuthMode: repository3.AUTH_MODE_ACCESS_TOKEN, UserName: "bot", AccessToken: "token"})
+	if err != nil || userName != "bot" || password != "token" {
+		t.Errorf("getGitCredentials() access token = %s, %s, %v", userName, password, err)
+	}
+	_, _, err = getGitCredentials(&repository3.GitProvider{AuthMode: repository3.AUTH_MODE_SSH})
+	if err == nil {
+		t.Errorf("getGitCredentials() expected error for ssh auth mode")
+	}
+}
+
+func TestFindAppDocumentFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"apps/b.yaml", "apps/a.yml", "apps/README.md", "apps/nested/c.YAML", "other/d.yaml", ".git/e.yaml"} {
+		err := os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, file), []byte{}, 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := findAppDocumentFiles(dir, "apps")
+	if err != nil {
+		t.Fatalf("findAppDocumentFiles() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "apps/a.yml"), filepath.Join(dir, "apps/b.yaml"), filepath.Join(dir, "apps/nested/c.YAML")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("findAppDocumentFiles() = %v, want %v", files, want)
+	}
+	files, err = findAppDocumentFiles(dir, "../")
+	if err != nil || len(files) != 4 {
+		t.Errorf("findAppDocumentFiles() root = %v, %v", files, err)
+	}
+}
@@ -0,0 +1,51 @@
+package gitSync
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/gitSync/repository"
+)
+
+// GitSyncConfigDto configures a git repository holding app documents to be reconciled with devtron. The repository
+// and branch are the ones of the given ci pipeline material, so the repository is tracked by git sensor like any
+// other source of devtron.
+type GitSyncConfigDto struct {
+	Id                   int    `json:"id"`
+	Name                 string `json:"name" validate:"required,max=250"`
+	CiPipelineMaterialId int    `json:"ciPipelineMaterialId" validate:"number,min=1"`
+	// Path is the directory in the repository holding the app documents, all yaml files under it are read
+	Path string `json:"path"`
+	// AutoSync applies the changes found on every reconciliation, drift is only reported if not set
+	AutoSync bool `json:"autoSync"`
+	// Prune deletes the workflows and cd pipelines of an app which are no longer in its document, they are left
+	// untouched if not set
+	Prune      bool   `json:"prune"`
+	GitRepoUrl string `json:"gitRepoUrl,omitempty"`
+	Branch     string `json:"branch,omitempty"`
+	UserId     int32  `json:"-"`
+}
+
+type GitSyncResultDto struct {
+	Id              int                         `json:"id"`
+	GitSyncConfigId int                         `json:"gitSyncConfigId"`
+	CommitHash      string                      `json:"commitHash"`
+	Status          repository.GitSyncStatus    `json:"status"`
+	DryRun          bool                        `json:"dryRun"`
+	DriftDetected   bool                        `json:"driftDetected"`
+	Message         string                      `json:"message,omitempty"`
+	StartedOn       time.Time                   `json:"startedOn"`
+	FinishedOn      time.Time                   `json:"finishedOn,omitempty"`
+	Resources       []*GitSyncResourceStatusDto `json:"resources,omitempty"`
+}
+
+type GitSyncResourceStatusDto struct {
+	FilePath  string                               `json:"filePath"`
+	AppName   string                               `json:"appName,omitempty"`
+	Component string                               `json:"component"`
+	Name      string                               `json:"name,omitempty"`
+	Status    repository.GitSyncResourceStatusType `json:"status"`
+	Message   string                               `json:"message,omitempty"`
+}
+
+// GIT_SYNC_COMPONENT_DOCUMENT is the component of the statuses of app documents which could not be read
+const GIT_SYNC_COMPONENT_DOCUMENT = "document"
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// GitSyncConfig is a git repository, tracked through a ci pipeline material, whose app documents are reconciled
+// with the apps in devtron
+type GitSyncConfig struct {
+	tableName            struct{} `sql:"git_sync_config" pg:",discard_unknown_columns"`
+	Id                   int      `sql:"id,pk"`
+	Name                 string   `sql:"name,notnull"`
+	CiPipelineMaterialId int      `sql:"ci_pipeline_material_id,notnull"`
+	Path                 string   `sql:"path"`
+	AutoSync             bool     `sql:"auto_sync,notnull"`
+	Prune                bool     `sql:"prune,notnull"`
+	Active               bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type GitSyncConfigRepository interface {
+	Save(config *GitSyncConfig) error
+	Update(config *GitSyncConfig) error
+	FindById(id int) (*GitSyncConfig, error)
+	FindActiveByName(name string) (*GitSyncConfig, error)
+	FindAllActive() ([]*GitSyncConfig, error)
+}
+
+type GitSyncConfigRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewGitSyncConfigRepositoryImpl(dbConnection *pg.DB) *GitSyncConfigRepositoryImpl {
+	return &GitSyncConfigRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *GitSyncConfigRepositoryImpl) Save(config *GitSyncConfig) error {
+	return impl.dbConnection.Insert(config)
+}
+
+func (impl *GitSyncConfigRepositoryImpl) Update(config *GitSyncConfig) error {
+	return impl.dbConnection.Update(config)
+}
+
+func (impl *GitSyncConfigRepositoryImpl) FindById(id int) (*GitSyncConfig, error) {
+	config := &GitSyncConfig{}
+	err := impl.dbConnection.Model(config).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return config, err
+}
+
+func (impl *GitSyncConfigRepositoryImpl) FindActiveByName(name string) (*GitSyncConfig, error) {
+	config := &GitSyncConfig{}
+	err := impl.dbConnection.Model(config).
+		Where("name = ?", name).
+		Where("active = ?", true).
+		Select()
+	return config, err
+}
+
+func (impl *GitSyncConfigRepositoryImpl) FindAllActive() ([]*GitSyncConfig, error) {
+	var configs []*GitSyncConfig
+	err := impl.dbConnection.Model(&configs).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return configs, err
+}
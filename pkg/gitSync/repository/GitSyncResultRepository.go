@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+type GitSyncStatus string
+
+const (
+	GIT_SYNC_STATUS_RUNNING   GitSyncStatus = "Running"
+	GIT_SYNC_STATUS_SUCCEEDED GitSyncStatus = "Succeeded"
+	GIT_SYNC_STATUS_FAILED    GitSyncStatus = "Failed"
+)
+
+type GitSyncResourceStatusType string
+
+const (
+	// GIT_SYNC_RESOURCE_SYNCED means the resource in devtron matches the app document
+	GIT_SYNC_RESOURCE_SYNCED GitSyncResourceStatusType = "Synced"
+	// GIT_SYNC_RESOURCE_OUT_OF_SYNC means the resource differs from the app document and was not applied
+	GIT_SYNC_RESOURCE_OUT_OF_SYNC GitSyncResourceStatusType = "OutOfSync"
+	GIT_SYNC_RESOURCE_APPLIED     GitSyncResourceStatusType = "Applied"
+	GIT_SYNC_RESOURCE_UNSUPPORTED GitSyncResourceStatusType = "Unsupported"
+	GIT_SYNC_RESOURCE_FAILED      GitSyncResourceStatusType = "Failed"
+)
+
+// GitSyncResult is one reconciliation of a git sync config at a commit
+type GitSyncResult struct {
+	tableName       struct{}      `sql:"git_sync_result" pg:",discard_unknown_columns"`
+	Id              int           `sql:"id,pk"`
+	GitSyncConfigId int           `sql:"git_sync_config_id,notnull"`
+	CommitHash      string        `sql:"commit_hash"`
+	Status          GitSyncStatus `sql:"status,notnull"`
+	DryRun          bool          `sql:"dry_run,notnull"`
+	DriftDetected   bool          `sql:"drift_detected,notnull"`
+	Message         string        `sql:"message"`
+	StartedOn       time.Time     `sql:"started_on,notnull"`
+	FinishedOn      time.Time     `sql:"finished_on"`
+	sql.AuditLog
+}
+
+// GitSyncResourceStatus is the status of one component of an app document in a reconciliation
+type GitSyncResourceStatus struct {
+	tableName       struct{}                  `sql:"git_sync_resource_status" pg:",discard_unknown_columns"`
+	Id              int                       `sql:"id,pk"`
+	GitSyncResultId int                       `sql:"git_sync_result_id,notnull"`
+	FilePath        string                    `sql:"file_path,notnull"`
+	AppName         string                    `sql:"app_name"`
+	Component       string                    `sql:"component,notnull"`
+	Name            string                    `sql:"name"`
+	Status          GitSyncResourceStatusType `sql:"status,notnull"`
+	Message         string                    `sql:"message"`
+	sql.AuditLog
+}
+
+type GitSyncResultRepository interface {
+	GetConnection() *pg.DB
+	SaveResult(result *GitSyncResult) error
+	UpdateResult(result *GitSyncResult, tx *pg.Tx) error
+	SaveResourceStatuses(statuses []*GitSyncResourceStatus, tx *pg.Tx) error
+	FindById(id int) (*GitSyncResult, error)
+	FindLatestByConfigId(configId int) (*GitSyncResult, error)
+	// FindLatestAppliedByConfigId returns the latest successful reconciliation which applied its changes, dry runs are
+	// skipped
+	FindLatestAppliedByConfigId(configId int) (*GitSyncResult, error)
+	FindByConfigId(configId int, limit int) ([]*GitSyncResult, error)
+	FindResourceStatusesByResultId(resultId int) ([]*GitSyncResourceStatus, error)
+}
+
+type GitSyncResultRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewGitSyncResultRepositoryImpl(dbConnection *pg.DB) *GitSyncResultRepositoryImpl {
+	return &GitSyncResultRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *GitSyncResultRepositoryImpl) GetConnection() *pg.DB {
+	return impl.dbConnection
+}
+
+func (impl *GitSyncResultRepositoryImpl) SaveResult(result *GitSyncResult) error {
+	return impl.dbConnection.Insert(result)
+}
+
+func (impl *GitSyncResultRepositoryImpl) UpdateResult(result *GitSyncResult, tx *pg.Tx) error {
+	return tx.Update(result)
+}
+
+func (impl *GitSyncResultRepositoryImpl) SaveResourceStatuses(statuses []*GitSyncResourceStatus, tx *pg.Tx) error {
+	if len(statuses) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&statuses).Insert()
+	return err
+}
+
+func (impl *GitSyncResultRepositoryImpl) FindById(id int) (*GitSyncResult, error) {
+	result := &GitSyncResult{}
+	err := impl.dbConnection.Model(result).
+		Where("id = ?", id).
+		Select()
+	return result, err
+}
+
+func (impl *GitSyncResultRepositoryImpl) FindLatestByConfigId(configId int) (*GitSyncResult, error) {
+	result := &GitSyncResult{}
+	err := impl.dbConnection.Model(result).
+		Where("git_sync_config_id = ?", configId).
+		Order("id DESC").
+		Limit(1).
+		Select()
+	return result, err
+}
+
+func (impl *GitSyncResultRepositoryImpl) FindLatestAppliedByConfigId(configId int) (*GitSyncResult, error) {
+	result := &GitSyncResult{}
+	err := impl.dbConnection.Model(result).
+		Where("git_sync_config_id = ?", configId).
+		Where("status = ?", GIT_SYNC_STATUS_SUCCEEDED).
+		Where("dry_run = ?", false).
+		Order("id DESC").
+		Limit(1).
+		Select()
+	return result, err
+}
+
+func (impl *GitSyncResultRepositoryImpl) FindByConfigId(configId int, limit int) ([]*GitSyncResult, error) {
+	var results []*GitSyncResult
+	err := impl.dbConnection.Model(&results).
+		Where("git_sync_config_id = ?", configId).
+		Order("id DESC").
+		Limit(limit).
+		Select()
+	return results, err
+}
+
+func (impl *GitSyncResultRepositoryImpl) FindResourceStatusesByResultId(resultId int) ([]*GitSyncResourceStatus, error) {
+	var statuses []*GitSyncResourceStatus
+	err := impl.dbConnection.Model(&statuses).
+		Where("git_sync_result_id = ?", resultId).
+		Order("id ASC").
+		Select()
+	return statuses, err
+}
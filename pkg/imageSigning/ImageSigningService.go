@@ -0,0 +1,685 @@
+package imageSigning
+
+import (
+	"crypto"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/common-lib/utils/k8s"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	dockerRegistryRepository "github.com/devtron-labs/devtron/internal/sql/repository/dockerRegistry"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/dockerRegistry"
+	imageSigningRepository "github.com/devtron-labs/devtron/pkg/imageSigning/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	util2 "github.com/devtron-labs/devtron/util"
+	"github.com/devtron-labs/devtron/util/registry"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	insecureRegistryConnection = "insecure"
+	// privateKeySecretNamePrefix prefixes the id of the key in the name of the kubernetes secret its private key is
+	// kept in
+	privateKeySecretNamePrefix = "devtron-image-signing-key-"
+	privateKeySecretDataKey    = "cosign.key"
+)
+
+type ImageSigningService interface {
+	CreateKey(request *ImageSigningKeyDto) (*ImageSigningKeyDto, error)
+	UpdateKey(request *ImageSigningKeyDto) (*ImageSigningKeyDto, error)
+	DeleteKey(id int, userId int32) error
+	GetAllKeys() ([]*ImageSigningKeyDto, error)
+
+	CreatePolicy(request *ImageSignaturePolicyDto) (*ImageSignaturePolicyDto, error)
+	UpdatePolicy(request *ImageSignaturePolicyDto) (*ImageSignaturePolicyDto, error)
+	DeletePolicy(id int, userId int32) error
+	GetAllPolicies() ([]*ImageSignaturePolicyDto, error)
+
+	SaveCiPipelineSigningConfig(request *CiPipelineSigningConfigDto) (*CiPipelineSigningConfigDto, error)
+	GetCiPipelineSigningConfig(ciPipelineId int) (*CiPipelineSigningConfigDto, error)
+	// GetSigningSecrets returns the private key and password, keyed by the variable names the cosign signer plugin
+	// step reads them from, the image of the ci pipeline is signed with. Nothing is returned if the ci pipeline does
+	// not sign its images.
+	GetSigningSecrets(ciPipelineId int) (map[string]string, error)
+
+	// VerifyArtifactForDeployment verifies the signature of the artifact against the signature policy of the
+	// environment of pipeline and saves the result, nil is returned if no policy is configured for the environment
+	VerifyArtifactForDeployment(artifact *repository.CiArtifact, pipeline *pipelineConfig.Pipeline, userId int32) (*SignatureVerificationResult, error)
+	GetArtifactVerifications(ciArtifactId int) ([]*SignatureVerificationResult, error)
+}
+
+type ImageSigningServiceImpl struct {
+	logger                         *zap.SugaredLogger
+	imageSigningKeyRepository      imageSigningRepository.ImageSigningKeyRepository
+	imageSignaturePolicyRepository imageSigningRepository.ImageSignaturePolicyRepository
+	ciPipelineRepository           pipelineConfig.CiPipelineRepository
+	dockerArtifactStoreRepository  dockerRegistryRepository.DockerArtifactStoreRepository
+	environmentRepository          repository2.EnvironmentRepository
+	k8sUtil                        *k8s.K8sUtil
+	devtronSecretConfig            *util2.DevtronSecretConfig
+}
+
+func NewImageSigningServiceImpl(logger *zap.SugaredLogger,
+	imageSigningKeyRepository imageSigningRepository.ImageSigningKeyRepository,
+	imageSignaturePolicyRepository imageSigningRepository.ImageSignaturePolicyRepository,
+	ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	dockerArtifactStoreRepository dockerRegistryRepository.DockerArtifactStoreRepository,
+	environmentRepository repository2.EnvironmentRepository,
+	k8sUtil *k8s.K8sUtil,
+	devtronSecretConfig *util2.DevtronSecretConfig) *ImageSigningServiceImpl {
+	return &ImageSigningServiceImpl{
+		logger:                         logger,
+		imageSigningKeyRepository:      imageSigningKeyRepository,
+		imageSignaturePolicyRepository: imageSignaturePolicyRepository,
+		ciPipelineRepository:           ciPipelineRepository,
+		dockerArtifactStoreRepository:  dockerArtifactStoreRepository,
+		environmentRepository:          environmentRepository,
+		k8sUtil:                        k8sUtil,
+		devtronSecretConfig:            devtronSecretConfig,
+	}
+}
+
+// trustedKey is a key of a signature policy whose public key is parsed
+type trustedKey struct {
+	id        int
+	name      string
+	publicKey crypto.PublicKey
+}
+
+func (impl *ImageSigningServiceImpl) CreateKey(request *ImageSigningKeyDto) (*ImageSigningKeyDto, error) {
+	err := impl.validateKey(request)
+	if err != nil {
+		return nil, err
+	}
+	key := &imageSigningRepository.ImageSigningKey{
+		Name:        request.Name,
+		Description: request.Description,
+		PublicKey:   strings.TrimSpace(request.PublicKey),
+		Password:    request.Password,
+		Active:      true,
+		AuditLog:    sql.NewDefaultAuditLog(request.UserId),
+	}
+	err = impl.imageSigningKeyRepository.Save(key)
+	if err != nil {
+		impl.logger.Errorw("error in saving image signing key", "name", key.Name, "err", err)
+		return nil, err
+	}
+	if len(request.PrivateKey) > 0 {
+		// the secret is named after the id of the key, the key is saved first and dropped if its secret cannot be saved
+		err = impl.savePrivateKey(key, request.PrivateKey)
+		if err == nil {
+			err = impl.imageSigningKeyRepository.Update(key)
+		}
+		if err != nil {
+			impl.logger.Errorw("error in saving private key of image signing key", "id", key.Id, "err", err)
+			key.Active = false
+			if updateErr := impl.imageSigningKeyRepository.Update(key); updateErr != nil {
+				impl.logger.Errorw("error in dropping image signing key", "id", key.Id, "err", updateErr)
+			}
+			return nil, err
+		}
+	}
+	return adaptKey(key), nil
+}
+
+// UpdateKey updates the key, the private key and its password are kept as they are if not given
+func (impl *ImageSigningServiceImpl) UpdateKey(request *ImageSigningKeyDto) (*ImageSigningKeyDto, error) {
+	err := impl.validateKey(request)
+	if err != nil {
+		return nil, err
+	}
+	key, err := impl.imageSigningKeyRepository.FindById(request.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signing key", "id", request.Id, "err", err)
+		return nil, err
+	}
+	key.Name = request.Name
+	key.Description = request.Description
+	key.PublicKey = strings.TrimSpace(request.PublicKey)
+	if len(request.PrivateKey) > 0 {
+		err = impl.savePrivateKey(key, request.PrivateKey)
+		if err != nil {
+			impl.logger.Errorw("error in saving private key of image signing key", "id", key.Id, "err", err)
+			return nil, err
+		}
+		key.Password = request.Password
+	}
+	key.UpdatedOn = time.Now()
+	key.UpdatedBy = request.UserId
+	err = impl.imageSigningKeyRepository.Update(key)
+	if err != nil {
+		impl.logger.Errorw("error in updating image signing key", "id", key.Id, "err", err)
+		return nil, err
+	}
+	return adaptKey(key), nil
+}
+
+func (impl *ImageSigningServiceImpl) DeleteKey(id int, userId int32) error {
+	key, err := impl.imageSigningKeyRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signing key", "id", id, "err", err)
+		return err
+	}
+	ciPipelineIds, err := impl.imageSigningKeyRepository.FindCiPipelineIdsBySigningKeyId(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci pipelines signing with key", "id", id, "err", err)
+		return err
+	}
+	if len(ciPipelineIds) > 0 {
+		return badRequest(fmt.Sprintf("key %s is used to sign the images of %d ci pipelines", key.Name, len(ciPipelineIds)))
+	}
+	policies, err := impl.imageSignaturePolicyRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signature policies", "err", err)
+		return err
+	}
+	for _, policy := range policies {
+		for _, keyId := range policy.TrustedKeyIds {
+			if keyId == id {
+				return badRequest(fmt.Sprintf("key %s is trusted by the signature policy of environment %d", key.Name, policy.EnvironmentId))
+			}
+		}
+	}
+	key.Active = false
+	key.UpdatedOn = time.Now()
+	key.UpdatedBy = userId
+	err = impl.imageSigningKeyRepository.Update(key)
+	if err != nil {
+		impl.logger.Errorw("error in deleting image signing key", "id", id, "err", err)
+		return err
+	}
+	if len(key.PrivateKeySecretName) > 0 {
+		err = impl.deletePrivateKey(key)
+		if err != nil {
+			impl.logger.Errorw("error in deleting private key of image signing key", "id", id, "err", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (impl *ImageSigningServiceImpl) GetAllKeys() ([]*ImageSigningKeyDto, error) {
+	keys, err := impl.imageSigningKeyRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signing keys", "err", err)
+		return nil, err
+	}
+	result := make([]*ImageSigningKeyDto, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, adaptKey(key))
+	}
+	return result, nil
+}
+
+func (impl *ImageSigningServiceImpl) CreatePolicy(request *ImageSignaturePolicyDto) (*ImageSignaturePolicyDto, error) {
+	err := impl.validatePolicy(request)
+	if err != nil {
+		return nil, err
+	}
+	policy := &imageSigningRepository.ImageSignaturePolicy{
+		EnvironmentId: request.EnvironmentId,
+		TrustedKeyIds: request.TrustedKeyIds,
+		Active:        true,
+		AuditLog:      sql.NewDefaultAuditLog(request.UserId),
+	}
+	err = impl.imageSignaturePolicyRepository.Save(policy)
+	if err != nil {
+		impl.logger.Errorw("error in saving image signature policy", "policy", policy, "err", err)
+		return nil, err
+	}
+	request.Id = policy.Id
+	return request, nil
+}
+
+func (impl *ImageSigningServiceImpl) UpdatePolicy(request *ImageSignaturePolicyDto) (*ImageSignaturePolicyDto, error) {
+	err := impl.validatePolicy(request)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := impl.imageSignaturePolicyRepository.FindById(request.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signature policy", "id", request.Id, "err", err)
+		return nil, err
+	}
+	policy.EnvironmentId = request.EnvironmentId
+	policy.TrustedKeyIds = request.TrustedKeyIds
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = request.UserId
+	err = impl.imageSignaturePolicyRepository.Update(policy)
+	if err != nil {
+		impl.logger.Errorw("error in updating image signature policy", "policy", policy, "err", err)
+		return nil, err
+	}
+	return request, nil
+}
+
+func (impl *ImageSigningServiceImpl) DeletePolicy(id int, userId int32) error {
+	policy, err := impl.imageSignaturePolicyRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signature policy", "id", id, "err", err)
+		return err
+	}
+	policy.Active = false
+	policy.UpdatedOn = time.Now()
+	policy.UpdatedBy = userId
+	err = impl.imageSignaturePolicyRepository.Update(policy)
+	if err != nil {
+		impl.logger.Errorw("error in deleting image signature policy", "id", id, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *ImageSigningServiceImpl) GetAllPolicies() ([]*ImageSignaturePolicyDto, error) {
+	policies, err := impl.imageSignaturePolicyRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signature policies", "err", err)
+		return nil, err
+	}
+	result := make([]*ImageSignaturePolicyDto, 0, len(policies))
+	for _, policy := range policies {
+		dto := &ImageSignaturePolicyDto{
+			Id:            policy.Id,
+			EnvironmentId: policy.EnvironmentId,
+			TrustedKeyIds: policy.TrustedKeyIds,
+		}
+		env, err := impl.environmentRepository.FindById(policy.EnvironmentId)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching environment", "envId", policy.EnvironmentId, "err", err)
+			return nil, err
+		} else if err == nil {
+			dto.EnvironmentName = env.Name
+		}
+		result = append(result, dto)
+	}
+	return result, nil
+}
+
+func (impl *ImageSigningServiceImpl) SaveCiPipelineSigningConfig(request *CiPipelineSigningConfigDto) (*CiPipelineSigningConfigDto, error) {
+	if request.SigningKeyId > 0 {
+		key, err := impl.imageSigningKeyRepository.FindById(request.SigningKeyId)
+		if err == pg.ErrNoRows {
+			return nil, badRequest(fmt.Sprintf("signing key %d not found", request.SigningKeyId))
+		} else if err != nil {
+			impl.logger.Errorw("error in fetching image signing key", "id", request.SigningKeyId, "err", err)
+			return nil, err
+		}
+		if len(key.PrivateKeySecretName) == 0 {
+			return nil, badRequest(fmt.Sprintf("key %s has no private key to sign images with", key.Name))
+		}
+	}
+	config, err := impl.imageSigningKeyRepository.FindCiPipelineSigningConfig(request.CiPipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching ci pipeline signing config", "ciPipelineId", request.CiPipelineId, "err", err)
+		return nil, err
+	}
+	if err == pg.ErrNoRows {
+		if request.SigningKeyId == 0 {
+			return request, nil
+		}
+		config = &imageSigningRepository.CiPipelineSigningConfig{
+			CiPipelineId: request.CiPipelineId,
+			SigningKeyId: request.SigningKeyId,
+			Active:       true,
+			AuditLog:     sql.NewDefaultAuditLog(request.UserId),
+		}
+		err = impl.imageSigningKeyRepository.SaveCiPipelineSigningConfig(config)
+	} else {
+		config.SigningKeyId = request.SigningKeyId
+		config.Active = request.SigningKeyId > 0
+		config.UpdatedOn = time.Now()
+		config.UpdatedBy = request.UserId
+		err = impl.imageSigningKeyRepository.UpdateCiPipelineSigningConfig(config)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving ci pipeline signing config", "ciPipelineId", request.CiPipelineId, "err", err)
+		return nil, err
+	}
+	return request, nil
+}
+
+func (impl *ImageSigningServiceImpl) GetCiPipelineSigningConfig(ciPipelineId int) (*CiPipelineSigningConfigDto, error) {
+	config, err := impl.imageSigningKeyRepository.FindCiPipelineSigningConfig(ciPipelineId)
+	if err == pg.ErrNoRows {
+		return &CiPipelineSigningConfigDto{CiPipelineId: ciPipelineId}, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching ci pipeline signing config", "ciPipelineId", ciPipelineId, "err", err)
+		return nil, err
+	}
+	return &CiPipelineSigningConfigDto{CiPipelineId: ciPipelineId, SigningKeyId: config.SigningKeyId}, nil
+}
+
+func (impl *ImageSigningServiceImpl) GetSigningSecrets(ciPipelineId int) (map[string]string, error) {
+	config, err := impl.imageSigningKeyRepository.FindCiPipelineSigningConfig(ciPipelineId)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching ci pipeline signing config", "ciPipelineId", ciPipelineId, "err", err)
+		return nil, err
+	}
+	key, err := impl.imageSigningKeyRepository.FindById(config.SigningKeyId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signing key", "id", config.SigningKeyId, "err", err)
+		return nil, err
+	}
+	if len(key.PrivateKeySecretName) == 0 {
+		return nil, badRequest(fmt.Sprintf("key %s has no private key to sign images with", key.Name))
+	}
+	privateKey, err := impl.getPrivateKey(key)
+	if err != nil {
+		impl.logger.Errorw("error in fetching private key of image signing key", "id", key.Id, "err", err)
+		return nil, err
+	}
+	return map[string]string{
+		COSIGN_PRIVATE_KEY_ENV: privateKey,
+		COSIGN_PASSWORD_ENV:    key.Password,
+	}, nil
+}
+
+// savePrivateKey creates or updates the kubernetes secret of the devtron namespace the private key of key is kept in
+func (impl *ImageSigningServiceImpl) savePrivateKey(key *imageSigningRepository.ImageSigningKey, privateKey string) error {
+	client, err := impl.k8sUtil.GetClientForInCluster()
+	if err != nil {
+		return err
+	}
+	namespace := impl.devtronSecretConfig.DevtronDexSecretNamespace
+	secretName := privateKeySecretNamePrefix + strconv.Itoa(key.Id)
+	data := map[string][]byte{privateKeySecretDataKey: []byte(strings.TrimSpace(privateKey))}
+	secret, err := impl.k8sUtil.GetSecret(namespace, secretName, client)
+	if apierrors.IsNotFound(err) {
+		_, err = impl.k8sUtil.CreateSecret(namespace, data, secretName, "", client, nil, nil)
+	} else if err == nil {
+		secret.Data = data
+		_, err = impl.k8sUtil.UpdateSecret(namespace, secret, client)
+	}
+	if err != nil {
+		return err
+	}
+	key.PrivateKeySecretName = secretName
+	return nil
+}
+
+func (impl *ImageSigningServiceImpl) getPrivateKey(key *imageSigningRepository.ImageSigningKey) (string, error) {
+	client, err := impl.k8sUtil.GetClientForInCluster()
+	if err != nil {
+		return "", err
+	}
+	secret, err := impl.k8sUtil.GetSecret(impl.devtronSecretConfig.DevtronDexSecretNamespace, key.PrivateKeySecretName, client)
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Data[privateKeySecretDataKey]), nil
+}
+
+func (impl *ImageSigningServiceImpl) deletePrivateKey(key *imageSigningRepository.ImageSigningKey) error {
+	client, err := impl.k8sUtil.GetClientForInCluster()
+	if err != nil {
+		return err
+	}
+	err = impl.k8sUtil.DeleteSecret(impl.devtronSecretConfig.DevtronDexSecretNamespace, key.PrivateKeySecretName, client)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (impl *ImageSigningServiceImpl) VerifyArtifactForDeployment(artifact *repository.CiArtifact, pipeline *pipelineConfig.Pipeline, userId int32) (*SignatureVerificationResult, error) {
+	policy, err := impl.imageSignaturePolicyRepository.FindActiveByEnvironmentId(pipeline.EnvironmentId)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching image signature policy", "envId", pipeline.EnvironmentId, "err", err)
+		return nil, err
+	}
+	verification := &imageSigningRepository.ImageSignatureVerification{
+		CiArtifactId:  artifact.Id,
+		ImageDigest:   artifact.ImageDigest,
+		EnvironmentId: pipeline.EnvironmentId,
+		PolicyId:      policy.Id,
+		VerifiedOn:    time.Now(),
+		AuditLog:      sql.NewDefaultAuditLog(userId),
+	}
+	signingKey, message, err := impl.verifyArtifact(artifact, policy)
+	if err != nil {
+		return nil, err
+	}
+	if signingKey != nil {
+		verification.Verified = true
+		verification.SigningKeyId = signingKey.id
+		message = fmt.Sprintf("image is signed by trusted key %s", signingKey.name)
+	}
+	verification.Message = util.GetTruncatedMessage(message, 250)
+	err = impl.imageSignaturePolicyRepository.SaveVerification(verification)
+	if err != nil {
+		impl.logger.Errorw("error in saving image signature verification", "ciArtifactId", artifact.Id, "envId", pipeline.EnvironmentId, "err", err)
+		return nil, err
+	}
+	impl.logger.Infow("image signature verified", "ciArtifactId", artifact.Id, "envId", pipeline.EnvironmentId, "verified", verification.Verified, "message", message)
+	result := adaptVerification(verification)
+	if signingKey != nil {
+		result.SigningKeyName = signingKey.name
+	}
+	return result, nil
+}
+
+func (impl *ImageSigningServiceImpl) GetArtifactVerifications(ciArtifactId int) ([]*SignatureVerificationResult, error) {
+	verifications, err := impl.imageSignaturePolicyRepository.FindLatestVerificationsByCiArtifactId(ciArtifactId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signature verifications", "ciArtifactId", ciArtifactId, "err", err)
+		return nil, err
+	}
+	keyNames := make(map[int]string)
+	var keyIds []int
+	for _, verification := range verifications {
+		if verification.SigningKeyId > 0 {
+			keyIds = append(keyIds, verification.SigningKeyId)
+		}
+	}
+	keys, err := impl.imageSigningKeyRepository.FindByIds(keyIds)
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signing keys", "ids", keyIds, "err", err)
+		return nil, err
+	}
+	for _, key := range keys {
+		keyNames[key.Id] = key.Name
+	}
+	result := make([]*SignatureVerificationResult, 0, len(verifications))
+	for _, verification := range verifications {
+		dto := adaptVerification(verification)
+		dto.SigningKeyName = keyNames[verification.SigningKeyId]
+		env, err := impl.environmentRepository.FindById(verification.EnvironmentId)
+		if err == nil {
+			dto.EnvironmentName = env.Name
+		}
+		result = append(result, dto)
+	}
+	return result, nil
+}
+
+// verifyArtifact returns the trusted key which signed the artifact, or the reason why no trusted signature was found.
+// Registries which cannot be reached fail the verification, only errors of devtron itself are returned.
+func (impl *ImageSigningServiceImpl) verifyArtifact(artifact *repository.CiArtifact, policy *imageSigningRepository.ImageSignaturePolicy) (*trustedKey, string, error) {
+	keys, err := impl.imageSigningKeyRepository.FindByIds(policy.TrustedKeyIds)
+	if err != nil {
+		impl.logger.Errorw("error in fetching trusted keys", "policyId", policy.Id, "err", err)
+		return nil, "", err
+	}
+	var trustedKeys []*trustedKey
+	for _, key := range keys {
+		publicKey, err := registry.ParsePublicKey(key.PublicKey)
+		if err != nil {
+			impl.logger.Errorw("invalid public key of trusted key", "keyId", key.Id, "err", err)
+			continue
+		}
+		trustedKeys = append(trustedKeys, &trustedKey{id: key.Id, name: key.Name, publicKey: publicKey})
+	}
+	if len(trustedKeys) == 0 {
+		return nil, "no valid trusted key is configured in the signature policy of the environment", nil
+	}
+	if len(artifact.ImageDigest) == 0 {
+		return nil, "image digest is not known, signature cannot be verified", nil
+	}
+	registryClient, err := impl.getRegistryClient(artifact)
+	if err != nil {
+		return nil, "", err
+	}
+	if registryClient == nil {
+		return nil, "container registry of the image is not known, signature cannot be verified", nil
+	}
+	signatures, err := registryClient.GetCosignSignatures(registry.GetRepositoryOfImage(artifact.Image), artifact.ImageDigest)
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signatures", "image", artifact.Image, "err", err)
+		return nil, fmt.Sprintf("error in fetching signatures of image: %s", err.Error()), nil
+	}
+	signingKey, message := verifyImageSignatures(signatures, trustedKeys, artifact.ImageDigest)
+	return signingKey, message, nil
+}
+
+// getRegistryClient returns a client of the registry the artifact is pushed to, nil if the registry is not known
+func (impl *ImageSigningServiceImpl) getRegistryClient(artifact *repository.CiArtifact) (*registry.Client, error) {
+	var dockerRegistryId string
+	if artifact.CredentialsSourceType == repository.GLOBAL_CONTAINER_REGISTRY {
+		dockerRegistryId = artifact.CredentialSourceValue
+	} else if artifact.PipelineId > 0 {
+		ciPipeline, err := impl.ciPipelineRepository.FindByIdIncludingInActive(artifact.PipelineId)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching ci pipeline", "ciPipelineId", artifact.PipelineId, "err", err)
+			return nil, err
+		}
+		if err == nil && ciPipeline.CiTemplate != nil && ciPipeline.CiTemplate.DockerRegistryId != nil {
+			dockerRegistryId = *ciPipeline.CiTemplate.DockerRegistryId
+		}
+	}
+	if len(dockerRegistryId) == 0 {
+		return nil, nil
+	}
+	dockerArtifactStore, err := impl.dockerArtifactStoreRepository.FindOne(dockerRegistryId)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching docker registry", "dockerRegistryId", dockerRegistryId, "err", err)
+		return nil, err
+	}
+	credential := &registry.Credential{
+		RegistryURL: dockerArtifactStore.RegistryURL,
+		Username:    dockerArtifactStore.Username,
+		Password:    dockerArtifactStore.Password,
+		Insecure:    dockerArtifactStore.Connection == insecureRegistryConnection,
+		Cert:        dockerArtifactStore.Cert,
+	}
+	if dockerArtifactStore.RegistryType == dockerRegistryRepository.REGISTRYTYPE_ECR {
+		credential.Username, credential.Password, err = dockerRegistry.CreateCredentialForEcr(dockerArtifactStore.AWSRegion, dockerArtifactStore.AWSAccessKeyId, dockerArtifactStore.AWSSecretAccessKey)
+		if err != nil {
+			impl.logger.Errorw("error in creating ecr credential", "dockerRegistryId", dockerRegistryId, "err", err)
+			return nil, err
+		}
+	}
+	return registry.NewClient(credential)
+}
+
+// verifyImageSignatures returns the first trusted key which made one of the signatures of the image of digest, or the
+// reason why none did
+func verifyImageSignatures(signatures []*registry.CosignSignature, trustedKeys []*trustedKey, digest string) (*trustedKey, string) {
+	if len(signatures) == 0 {
+		return nil, "image is not signed"
+	}
+	for _, key := range trustedKeys {
+		for _, signature := range signatures {
+			if registry.VerifyCosignSignature(key.publicKey, signature, digest) == nil {
+				return key, ""
+			}
+		}
+	}
+	return nil, fmt.Sprintf("none of the %d signatures of the image is made by a trusted key", len(signatures))
+}
+
+func (impl *ImageSigningServiceImpl) validateKey(request *ImageSigningKeyDto) error {
+	_, err := registry.ParsePublicKey(request.PublicKey)
+	if err != nil {
+		return badRequest(err.Error())
+	}
+	if len(request.PrivateKey) > 0 {
+		block, _ := pem.Decode([]byte(strings.TrimSpace(request.PrivateKey)))
+		if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			return badRequest("private key is not a PEM encoded cosign private key")
+		}
+	}
+	existing, err := impl.imageSigningKeyRepository.FindByName(request.Name)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching image signing key", "name", request.Name, "err", err)
+		return err
+	}
+	if err == nil && existing.Id != request.Id {
+		return badRequest(fmt.Sprintf("key with name %s already exists", request.Name))
+	}
+	return nil
+}
+
+func (impl *ImageSigningServiceImpl) validatePolicy(request *ImageSignaturePolicyDto) error {
+	_, err := impl.environmentRepository.FindById(request.EnvironmentId)
+	if err == pg.ErrNoRows {
+		return badRequest(fmt.Sprintf("environment %d not found", request.EnvironmentId))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching environment", "envId", request.EnvironmentId, "err", err)
+		return err
+	}
+	existing, err := impl.imageSignaturePolicyRepository.FindActiveByEnvironmentId(request.EnvironmentId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching image signature policy", "envId", request.EnvironmentId, "err", err)
+		return err
+	}
+	if err == nil && existing.Id != request.Id {
+		return badRequest("a signature policy already exists for the environment")
+	}
+	keys, err := impl.imageSigningKeyRepository.FindByIds(request.TrustedKeyIds)
+	if err != nil {
+		impl.logger.Errorw("error in fetching image signing keys", "ids", request.TrustedKeyIds, "err", err)
+		return err
+	}
+	found := make(map[int]bool)
+	for _, key := range keys {
+		found[key.Id] = true
+	}
+	for _, keyId := range request.TrustedKeyIds {
+		if !found[keyId] {
+			return badRequest("trusted key " + strconv.Itoa(keyId) + " not found")
+		}
+	}
+	return nil
+}
+
+func adaptKey(key *imageSigningRepository.ImageSigningKey) *ImageSigningKeyDto {
+	return &ImageSigningKeyDto{
+		Id:            key.Id,
+		Name:          key.Name,
+		Description:   key.Description,
+		PublicKey:     key.PublicKey,
+		HasPrivateKey: len(key.PrivateKeySecretName) > 0,
+	}
+}
+
+func adaptVerification(verification *imageSigningRepository.ImageSignatureVerification) *SignatureVerificationResult {
+	return &SignatureVerificationResult{
+		Id:            verification.Id,
+		CiArtifactId:  verification.CiArtifactId,
+		ImageDigest:   verification.ImageDigest,
+		EnvironmentId: verification.EnvironmentId,
+		Verified:      verification.Verified,
+		SigningKeyId:  verification.SigningKeyId,
+		Message:       verification.Message,
+		VerifiedOn:    verification.VerifiedOn,
+	}
+}
+
+func badRequest(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: message, UserMessage: message}
+}
@@ -0,0 +1,53 @@
+package imageSigning
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/devtron-labs/devtron/util/registry"
+)
+
+const imageDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, digest string) *registry.CosignSignature {
+	payload := []byte(fmt.Sprintf(`{"critical":{"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"}}`, digest))
+	hash := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &registry.CosignSignature{Payload: payload, Signature: base64.StdEncoding.EncodeToString(signature)}
+}
+
+func TestVerifyImageSignatures(t *testing.T) {
+	teamKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	releaseKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	untrustedKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	trustedKeys := []*trustedKey{
+		{id: 1, name: "team", publicKey: &teamKey.PublicKey},
+		{id: 2, name: "release", publicKey: &releaseKey.PublicKey},
+	}
+
+	key, message := verifyImageSignatures(nil, trustedKeys, imageDigest)
+	if key != nil || message != "image is not signed" {
+		t.Errorf("verifyImageSignatures() unsigned = %v, %s", key, message)
+	}
+	key, _ = verifyImageSignatures([]*registry.CosignSignature{sign(t, untrustedKey, imageDigest), sign(t, releaseKey, imageDigest)}, trustedKeys, imageDigest)
+	if key == nil || key.name != "release" {
+		t.Errorf("verifyImageSignatures() signed by trusted key = %v", key)
+	}
+	key, message = verifyImageSignatures([]*registry.CosignSignature{sign(t, untrustedKey, imageDigest)}, trustedKeys, imageDigest)
+	if key != nil || len(message) == 0 {
+		t.Errorf("verifyImageSignatures() signed by untrusted key = %v, %s", key, message)
+	}
+	// a valid signature of another image of the repository does not sign this one
+	key, _ = verifyImageSignatures([]*registry.CosignSignature{sign(t, teamKey, "sha256:2222")}, trustedKeys, imageDigest)
+	if key != nil {
+		t.Errorf("verifyImageSignatures() signature of another image = %v", key)
+	}
+}
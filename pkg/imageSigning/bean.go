@@ -0,0 +1,53 @@
+package imageSigning
+
+import "time"
+
+const (
+	// COSIGN_PRIVATE_KEY_ENV and COSIGN_PASSWORD_ENV are the input variables of the cosign signer plugin step of
+	// pipelines signing their images, the step signs the pushed image with cosign sign --key env://COSIGN_PRIVATE_KEY
+	COSIGN_PRIVATE_KEY_ENV = "COSIGN_PRIVATE_KEY"
+	COSIGN_PASSWORD_ENV    = "COSIGN_PASSWORD"
+)
+
+// ImageSigningKeyDto is a cosign key pair, PrivateKey and Password are write only and are never returned. Keys
+// without private key can only be used to verify images signed outside of devtron.
+type ImageSigningKeyDto struct {
+	Id            int    `json:"id"`
+	Name          string `json:"name" validate:"required,max=250"`
+	Description   string `json:"description"`
+	PublicKey     string `json:"publicKey" validate:"required"`
+	PrivateKey    string `json:"privateKey,omitempty"`
+	Password      string `json:"password,omitempty"`
+	HasPrivateKey bool   `json:"hasPrivateKey"`
+	UserId        int32  `json:"-"`
+}
+
+// ImageSignaturePolicyDto allows only images signed by one of the trusted keys to be deployed on the environment
+type ImageSignaturePolicyDto struct {
+	Id              int    `json:"id"`
+	EnvironmentId   int    `json:"environmentId" validate:"number,min=1"`
+	EnvironmentName string `json:"environmentName"`
+	TrustedKeyIds   []int  `json:"trustedKeyIds" validate:"min=1"`
+	UserId          int32  `json:"-"`
+}
+
+// CiPipelineSigningConfigDto is the key images built by the ci pipeline are signed with, signing is disabled if
+// SigningKeyId is 0
+type CiPipelineSigningConfigDto struct {
+	CiPipelineId int   `json:"ciPipelineId"`
+	SigningKeyId int   `json:"signingKeyId" validate:"number,min=0"`
+	UserId       int32 `json:"-"`
+}
+
+type SignatureVerificationResult struct {
+	Id              int       `json:"id"`
+	CiArtifactId    int       `json:"ciArtifactId"`
+	ImageDigest     string    `json:"imageDigest"`
+	EnvironmentId   int       `json:"environmentId"`
+	EnvironmentName string    `json:"environmentName,omitempty"`
+	Verified        bool      `json:"verified"`
+	SigningKeyId    int       `json:"signingKeyId,omitempty"`
+	SigningKeyName  string    `json:"signingKeyName,omitempty"`
+	Message         string    `json:"message"`
+	VerifiedOn      time.Time `json:"verifiedOn"`
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// ImageSignaturePolicy allows only images signed by one of the trusted keys to be deployed on the environment
+type ImageSignaturePolicy struct {
+	tableName     struct{} `sql:"image_signature_policy" pg:",discard_unknown_columns"`
+	Id            int      `sql:"id,pk"`
+	EnvironmentId int      `sql:"environment_id,notnull"`
+	TrustedKeyIds []int    `sql:"trusted_key_ids" pg:",array"`
+	Active        bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// ImageSignatureVerification is the outcome of verifying the signature of an artifact against the policy of an
+// environment, a row is saved for every verification done at deployment
+type ImageSignatureVerification struct {
+	tableName     struct{}  `sql:"image_signature_verification" pg:",discard_unknown_columns"`
+	Id            int       `sql:"id,pk"`
+	CiArtifactId  int       `sql:"ci_artifact_id,notnull"`
+	ImageDigest   string    `sql:"image_digest"`
+	EnvironmentId int       `sql:"environment_id,notnull"`
+	PolicyId      int       `sql:"policy_id,notnull"`
+	Verified      bool      `sql:"verified,notnull"`
+	SigningKeyId  int       `sql:"signing_key_id"`
+	Message       string    `sql:"message"`
+	VerifiedOn    time.Time `sql:"verified_on,notnull"`
+	sql.AuditLog
+}
+
+type ImageSignaturePolicyRepository interface {
+	Save(policy *ImageSignaturePolicy) error
+	Update(policy *ImageSignaturePolicy) error
+	FindById(id int) (*ImageSignaturePolicy, error)
+	FindAllActive() ([]*ImageSignaturePolicy, error)
+	FindActiveByEnvironmentId(envId int) (*ImageSignaturePolicy, error)
+
+	SaveVerification(verification *ImageSignatureVerification) error
+	// FindLatestVerificationsByCiArtifactId returns the latest verification of the artifact for every environment
+	FindLatestVerificationsByCiArtifactId(ciArtifactId int) ([]*ImageSignatureVerification, error)
+}
+
+type ImageSignaturePolicyRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewImageSignaturePolicyRepositoryImpl(dbConnection *pg.DB) *ImageSignaturePolicyRepositoryImpl {
+	return &ImageSignaturePolicyRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *ImageSignaturePolicyRepositoryImpl) Save(policy *ImageSignaturePolicy) error {
+	return impl.dbConnection.Insert(policy)
+}
+
+func (impl *ImageSignaturePolicyRepositoryImpl) Update(policy *ImageSignaturePolicy) error {
+	return impl.dbConnection.Update(policy)
+}
+
+func (impl *ImageSignaturePolicyRepositoryImpl) FindById(id int) (*ImageSignaturePolicy, error) {
+	policy := &ImageSignaturePolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return policy, err
+}
+
+func (impl *ImageSignaturePolicyRepositoryImpl) FindAllActive() ([]*ImageSignaturePolicy, error) {
+	var policies []*ImageSignaturePolicy
+	err := impl.dbConnection.Model(&policies).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return policies, err
+}
+
+func (impl *ImageSignaturePolicyRepositoryImpl) FindActiveByEnvironmentId(envId int) (*ImageSignaturePolicy, error) {
+	policy := &ImageSignaturePolicy{}
+	err := impl.dbConnection.Model(policy).
+		Where("environment_id = ?", envId).
+		Where("active = ?", true).
+		Select()
+	return policy, err
+}
+
+func (impl *ImageSignaturePolicyRepositoryImpl) SaveVerification(verification *ImageSignatureVerification) error {
+	return impl.dbConnection.Insert(verification)
+}
+
+func (impl *ImageSignaturePolicyRepositoryImpl) FindLatestVerificationsByCiArtifactId(ciArtifactId int) ([]*ImageSignatureVerification, error) {
+	var verifications []*ImageSignatureVerification
+	query := "SELECT DISTINCT ON (environment_id) * FROM image_signature_verification" +
+		" WHERE ci_artifact_id = ? ORDER BY environment_id, id DESC;"
+	_, err := impl.dbConnection.Query(&verifications, query, ciArtifactId)
+	return verifications, err
+}
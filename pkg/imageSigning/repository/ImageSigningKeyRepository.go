@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// ImageSigningKey is a cosign key pair, the private key is encrypted by cosign with Password. The private key is not
+// kept next to its password but in the kubernetes secret PrivateKeySecretName of the devtron namespace.
+type ImageSigningKey struct {
+	tableName            struct{} `sql:"image_signing_key" pg:",discard_unknown_columns"`
+	Id                   int      `sql:"id,pk"`
+	Name                 string   `sql:"name,notnull"`
+	Description          string   `sql:"description"`
+	PublicKey            string   `sql:"public_key,notnull"`
+	PrivateKeySecretName string   `sql:"private_key_secret_name"`
+	Password             string   `sql:"password"`
+	Active               bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// CiPipelineSigningConfig is the key the images built by the ci pipeline are signed with
+type CiPipelineSigningConfig struct {
+	tableName    struct{} `sql:"ci_pipeline_signing_config" pg:",discard_unknown_columns"`
+	Id           int      `sql:"id,pk"`
+	CiPipelineId int      `sql:"ci_pipeline_id,notnull"`
+	SigningKeyId int      `sql:"signing_key_id,notnull"`
+	Active       bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type ImageSigningKeyRepository interface {
+	Save(key *ImageSigningKey) error
+	Update(key *ImageSigningKey) error
+	FindById(id int) (*ImageSigningKey, error)
+	FindByIds(ids []int) ([]*ImageSigningKey, error)
+	FindByName(name string) (*ImageSigningKey, error)
+	FindAllActive() ([]*ImageSigningKey, error)
+
+	SaveCiPipelineSigningConfig(config *CiPipelineSigningConfig) error
+	UpdateCiPipelineSigningConfig(config *CiPipelineSigningConfig) error
+	FindCiPipelineSigningConfig(ciPipelineId int) (*CiPipelineSigningConfig, error)
+	// FindCiPipelineIdsBySigningKeyId returns the ci pipelines signing their images with the key
+	FindCiPipelineIdsBySigningKeyId(signingKeyId int) ([]int, error)
+}
+
+type ImageSigningKeyRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewImageSigningKeyRepositoryImpl(dbConnection *pg.DB) *ImageSigningKeyRepositoryImpl {
+	return &ImageSigningKeyRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *ImageSigningKeyRepositoryImpl) Save(key *ImageSigningKey) error {
+	return impl.dbConnection.Insert(key)
+}
+
+func (impl *ImageSigningKeyRepositoryImpl) Update(key *ImageSigningKey) error {
+	return impl.dbConnection.Update(key)
+}
+
+func (impl *ImageSigningKeyRepositoryImpl) FindById(id int) (*ImageSigningKey, error) {
+	key := &ImageSigningKey{}
+	err := impl.dbConnection.Model(key).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return key, err
+}
+
+func (impl *ImageSigningKeyRepositoryImpl) FindByIds(ids []int) ([]*ImageSigningKey, error) {
+	var keys []*ImageSigningKey
+	if len(ids) == 0 {
+		return keys, nil
+	}
+	err := impl.dbConnection.Model(&keys).
+		Where("id IN (?)", pg.In(ids)).
+		Where("active = ?", true).
+		Select()
+	return keys, err
+}
+
+func (impl *ImageSigningKeyRepositoryImpl) FindByName(name string) (*ImageSigningKey, error) {
+	key := &ImageSigningKey{}
+	err := impl.dbConnection.Model(key).
+		Where("name = ?", name).
+		Where("active = ?", true).
+		Select()
+	return key, err
+}
+
+func (impl *ImageSigningKeyRepositoryImpl) FindAllActive() ([]*ImageSigningKey, error) {
+	var keys []*ImageSigningKey
+	err := impl.dbConnection.Model(&keys).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return keys, err
+}
+
+func (impl *ImageSigningKeyRepositoryImpl) SaveCiPipelineSigningConfig(config *CiPipelineSigningConfig) error {
+	return impl.dbConnection.Insert(config)
+}
+
+func (impl *ImageSigningKeyRepositoryImpl) UpdateCiPipelineSigningConfig(config *CiPipelineSigningConfig) error {
+	return impl.dbConnection.Update(config)
+}
+
+func (impl *ImageSigningKeyRepositoryImpl) FindCiPipelineSigningConfig(ciPipelineId int) (*CiPipelineSigningConfig, error) {
+	config := &CiPipelineSigningConfig{}
+	err := impl.dbConnection.Model(config).
+		Where("ci_pipeline_id = ?", ciPipelineId).
+		Where("active = ?", true).
+		Select()
+	return config, err
+}
+
+func (impl *ImageSigningKeyRepositoryImpl) FindCiPipelineIdsBySigningKeyId(signingKeyId int) ([]int, error) {
+	var ciPipelineIds []int
+	err := impl.dbConnection.Model((*CiPipelineSigningConfig)(nil)).
+		Column("ci_pipeline_id").
+		Where("signing_key_id = ?", signingKeyId).
+		Where("active = ?", true).
+		Select(&ciPipelineIds)
+	return ciPipelineIds, err
+}
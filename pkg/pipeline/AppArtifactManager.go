@@ -20,12 +20,14 @@ package pipeline
 import (
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/devtron-labs/devtron/api/bean"
 	"github.com/devtron-labs/devtron/client/argocdServer/application"
 	"github.com/devtron-labs/devtron/internal/sql/repository"
 	dockerArtifactStoreRegistry "github.com/devtron-labs/devtron/internal/sql/repository/dockerRegistry"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/artifactPromotion"
 	"github.com/devtron-labs/devtron/pkg/auth/user"
 	bean2 "github.com/devtron-labs/devtron/pkg/bean"
 	repository2 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
@@ -63,6 +65,7 @@ type AppArtifactManagerImpl struct {
 	dockerArtifactRegistry  dockerArtifactStoreRegistry.DockerArtifactStoreRepository
 	CiPipelineRepository    pipelineConfig.CiPipelineRepository
 	ciTemplateService       CiTemplateService
+	promotionPolicyService  artifactPromotion.ArtifactPromotionPolicyService
 }
 
 func NewAppArtifactManagerImpl(
@@ -76,7 +79,8 @@ func NewAppArtifactManagerImpl(
 	cdPipelineConfigService CdPipelineConfigService,
 	dockerArtifactRegistry dockerArtifactStoreRegistry.DockerArtifactStoreRepository,
 	CiPipelineRepository pipelineConfig.CiPipelineRepository,
-	ciTemplateService CiTemplateService) *AppArtifactManagerImpl {
+	ciTemplateService CiTemplateService,
+	promotionPolicyService artifactPromotion.ArtifactPromotionPolicyService) *AppArtifactManagerImpl {
 	cdConfig, err := types.GetCdConfig()
 	if err != nil {
 		return nil
@@ -94,6 +98,7 @@ func NewAppArtifactManagerImpl(
 		dockerArtifactRegistry:  dockerArtifactRegistry,
 		CiPipelineRepository:    CiPipelineRepository,
 		ciTemplateService:       ciTemplateService,
+		promotionPolicyService:  promotionPolicyService,
 	}
 }
 
@@ -555,6 +560,11 @@ func (impl *AppArtifactManagerImpl) RetrieveArtifactsByCDPipeline(pipeline *pipe
 		ciArtifacts[i].CiConfigureSourceType = ciWorkflow.GitTriggers[ciWorkflow.CiPipelineId].CiConfigureSourceType
 		ciArtifacts[i].CiConfigureSourceValue = ciWorkflow.GitTriggers[ciWorkflow.CiPipelineId].CiConfigureSourceValue
 	}
+	err = impl.setPromotionPolicyResultInArtifacts(ciArtifacts, pipeline, stage)
+	if err != nil {
+		impl.logger.Errorw("error in evaluating promotion policies for artifacts", "pipelineId", pipeline.Id, "err", err)
+		return ciArtifactsResponse, err
+	}
 
 	ciArtifactsResponse.CdPipelineId = pipeline.Id
 	ciArtifactsResponse.LatestWfArtifactId = latestWfArtifactId
@@ -642,6 +652,11 @@ func (impl *AppArtifactManagerImpl) RetrieveArtifactsByCDPipelineV2(pipeline *pi
 			impl.logger.Errorw("error in setting additional data in fetched artifacts", "pipelineId", pipeline.Id, "err", err)
 			return ciArtifactsResponse, err
 		}
+		err = impl.setPromotionPolicyResultInArtifacts(ciArtifacts, pipeline, stage)
+		if err != nil {
+			impl.logger.Errorw("error in evaluating promotion policies for artifacts", "pipelineId", pipeline.Id, "err", err)
+			return ciArtifactsResponse, err
+		}
 	}
 
 	ciArtifactsResponse.CdPipelineId = pipeline.Id
@@ -717,6 +732,25 @@ func (impl *AppArtifactManagerImpl) setAdditionalDataInArtifacts(ciArtifacts []b
 
 }
 
+// setPromotionPolicyResultInArtifacts sets the result of promotion policies of the pipeline's environment, policies apply only on deploy stage
+func (impl *AppArtifactManagerImpl) setPromotionPolicyResultInArtifacts(ciArtifacts []bean2.CiArtifactBean, pipeline *pipelineConfig.Pipeline, stage bean.WorkflowType) error {
+	if stage != bean.CD_WORKFLOW_TYPE_DEPLOY || len(ciArtifacts) == 0 {
+		return nil
+	}
+	artifactIds := make([]int, 0, len(ciArtifacts))
+	for _, artifact := range ciArtifacts {
+		artifactIds = append(artifactIds, artifact.Id)
+	}
+	evaluations, err := impl.promotionPolicyService.EvaluatePromotionPolicies(pipeline, artifactIds, time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range ciArtifacts {
+		ciArtifacts[i].PromotionPolicyResult = evaluations[ciArtifacts[i].Id]
+	}
+	return nil
+}
+
 func (impl *AppArtifactManagerImpl) setGitTriggerData(ciArtifacts []bean2.CiArtifactBean) ([]bean2.CiArtifactBean, error) {
 	directArtifactIndexes, directWorkflowIds, artifactsWithParentIndexes, parentArtifactIds := make([]int, 0), make([]int, 0), make([]int, 0), make([]int, 0)
 	for i, artifact := range ciArtifacts {
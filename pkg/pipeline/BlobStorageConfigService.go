@@ -9,11 +9,23 @@ import (
 	"github.com/devtron-labs/devtron/pkg/pipeline/types"
 	"go.uber.org/zap"
 	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type BlobStorageConfigService interface {
 	FetchCmAndSecretBlobConfigFromExternalCluster(clusterConfig *k8s.ClusterConfig, namespace string) (*bean2.CmBlobStorageConfig, *bean2.SecretBlobStorageConfig, error)
+	IsBlobStorageConfigured() bool
+	// PutObject uploads the content to the key in the default build logs bucket of the configured blob storage
+	PutObject(key string, content []byte) error
+	// GetObject downloads the content of the key from the default build logs bucket of the configured blob storage
+	GetObject(key string) ([]byte, error)
+	// DeleteObject deletes the key from the default build logs bucket of the configured blob storage, only s3
+	// compatible blob storage supports it
+	DeleteObject(key string) error
 }
 type BlobStorageConfigServiceImpl struct {
 	Logger     *zap.SugaredLogger
@@ -97,3 +109,94 @@ func updateRequestWithExtClusterCmAndSecret(request *blob_storage.BlobStorageReq
 
 	return request
 }
+
+func (impl *BlobStorageConfigServiceImpl) IsBlobStorageConfigured() bool {
+	return impl.ciCdConfig.BlobStorageEnabled
+}
+
+func (impl *BlobStorageConfigServiceImpl) PutObject(key string, content []byte) error {
+	if !impl.IsBlobStorageConfigured() {
+		return fmt.Errorf("blob storage is not configured")
+	}
+	sourceFile := impl.getTempFilePath(key)
+	err := os.WriteFile(sourceFile, content, 0644)
+	if err != nil {
+		impl.Logger.Errorw("error in writing file for blob storage upload", "file", sourceFile, "err", err)
+		return err
+	}
+	defer os.Remove(sourceFile)
+	request := impl.buildBlobStorageRequest(sourceFile, key)
+	err = blob_storage.NewBlobStorageServiceImpl(impl.Logger).PutWithCommand(request)
+	if err != nil {
+		impl.Logger.Errorw("error in uploading object to blob storage", "key", key, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *BlobStorageConfigServiceImpl) GetObject(key string) ([]byte, error) {
+	if !impl.IsBlobStorageConfigured() {
+		return nil, fmt.Errorf("blob storage is not configured")
+	}
+	destinationFile := impl.getTempFilePath(key)
+	defer os.Remove(destinationFile)
+	request := impl.buildBlobStorageRequest(key, strings.TrimPrefix(destinationFile, "/"))
+	_, _, err := blob_storage.NewBlobStorageServiceImpl(impl.Logger).Get(request)
+	if err != nil {
+		impl.Logger.Errorw("error in downloading object from blob storage", "key", key, "err", err)
+		return nil, err
+	}
+	return os.ReadFile(destinationFile)
+}
+
+func (impl *BlobStorageConfigServiceImpl) DeleteObject(key string) error {
+	if !impl.IsBlobStorageConfigured() {
+		return fmt.Errorf("blob storage is not configured")
+	}
+	request := impl.buildBlobStorageRequest("", key)
+	if request.StorageType != blob_storage.BLOB_STORAGE_S3 {
+		return fmt.Errorf("deleting objects from blob storage %s is not supported", request.StorageType)
+	}
+	err := blob_storage.NewBlobStorageServiceImpl(impl.Logger).DeleteObjectForS3(request)
+	if err != nil {
+		impl.Logger.Errorw("error in deleting object from blob storage", "key", key, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *BlobStorageConfigServiceImpl) getTempFilePath(key string) string {
+	return filepath.Join(impl.ciCdConfig.BaseLogLocationPath, fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(key)))
+}
+
+func (impl *BlobStorageConfigServiceImpl) buildBlobStorageRequest(sourceKey, destinationKey string) *blob_storage.BlobStorageRequest {
+	storageType := impl.ciCdConfig.CloudProvider
+	if storageType == types.BLOB_STORAGE_MINIO {
+		// minio is s3 compatible and is reached through its endpoint
+		storageType = blob_storage.BLOB_STORAGE_S3
+	}
+	return &blob_storage.BlobStorageRequest{
+		StorageType:    storageType,
+		SourceKey:      sourceKey,
+		DestinationKey: destinationKey,
+		AwsS3BaseConfig: &blob_storage.AwsS3BaseConfig{
+			AccessKey:         impl.ciCdConfig.BlobStorageS3AccessKey,
+			Passkey:           impl.ciCdConfig.BlobStorageS3SecretKey,
+			EndpointUrl:       impl.ciCdConfig.BlobStorageS3Endpoint,
+			IsInSecure:        impl.ciCdConfig.BlobStorageS3EndpointInsecure,
+			BucketName:        impl.ciCdConfig.CiDefaultBuildLogsBucket,
+			Region:            impl.ciCdConfig.CiDefaultCdLogsBucketRegion,
+			VersioningEnabled: impl.ciCdConfig.BlobStorageS3BucketVersioned,
+		},
+		AzureBlobBaseConfig: &blob_storage.AzureBlobBaseConfig{
+			Enabled:           impl.ciCdConfig.CloudProvider == types.BLOB_STORAGE_AZURE,
+			AccountName:       impl.ciCdConfig.AzureAccountName,
+			BlobContainerName: impl.ciCdConfig.AzureBlobContainerCiLog,
+			AccountKey:        impl.ciCdConfig.AzureAccountKey,
+		},
+		GcpBlobBaseConfig: &blob_storage.GcpBlobBaseConfig{
+			BucketName:             impl.ciCdConfig.CiDefaultBuildLogsBucket,
+			CredentialFileJsonData: impl.ciCdConfig.BlobStorageGcpCredentialJson,
+		},
+	}
+}
@@ -630,6 +630,8 @@ func (impl *CiPipelineConfigServiceImpl) GetCiPipeline(appId int) (ciConfig *bea
 				ScmVersion:      material.ScmVersion,
 				IsRegex:         material.Regex != "",
 				Source:          &bean.SourceTypeConfig{Type: material.Type, Value: material.Value, Regex: material.Regex},
+				IncludePaths:    material.IncludePaths,
+				ExcludePaths:    material.ExcludePaths,
 			}
 			ciPipeline.CiMaterial = append(ciPipeline.CiMaterial, ciMaterial)
 		}
@@ -769,6 +771,8 @@ func (impl *CiPipelineConfigServiceImpl) GetCiPipelineById(pipelineId int) (ciPi
 			ScmVersion:      material.ScmVersion,
 			IsRegex:         material.Regex != "",
 			Source:          &bean.SourceTypeConfig{Type: material.Type, Value: material.Value, Regex: material.Regex},
+			IncludePaths:    material.IncludePaths,
+			ExcludePaths:    material.ExcludePaths,
 		}
 		ciPipeline.CiMaterial = append(ciPipeline.CiMaterial, ciMaterial)
 	}
@@ -866,6 +870,8 @@ func (impl *CiPipelineConfigServiceImpl) GetTriggerViewCiPipeline(appId int) (*b
 				ScmVersion:      material.ScmVersion,
 				IsRegex:         material.Regex != "",
 				Source:          &bean.SourceTypeConfig{Type: material.Type, Value: material.Value, Regex: material.Regex},
+				IncludePaths:    material.IncludePaths,
+				ExcludePaths:    material.ExcludePaths,
 			}
 			ciPipeline.CiMaterial = append(ciPipeline.CiMaterial, ciMaterial)
 		}
@@ -1500,6 +1506,8 @@ func (impl *CiPipelineConfigServiceImpl) PatchRegexCiPipeline(request *bean.CiRe
 			Active:        true,
 			GitMaterialId: materialDbObject.GitMaterialId,
 			Regex:         materialDbObject.Regex,
+			IncludePaths:  materialDbObject.IncludePaths,
+			ExcludePaths:  materialDbObject.ExcludePaths,
 			AuditLog:      sql.AuditLog{UpdatedBy: request.UserId, UpdatedOn: time.Now(), CreatedOn: time.Now(), CreatedBy: request.UserId},
 		}
 		materials = append(materials, pipelineMaterial)
@@ -1711,6 +1719,8 @@ func (impl *CiPipelineConfigServiceImpl) GetCiPipelineByEnvironment(request reso
 					ScmVersion:      material.ScmVersion,
 					IsRegex:         material.Regex != "",
 					Source:          &bean.SourceTypeConfig{Type: material.Type, Value: material.Value, Regex: material.Regex},
+					IncludePaths:    material.IncludePaths,
+					ExcludePaths:    material.ExcludePaths,
 				}
 				ciPipeline.CiMaterial = append(ciPipeline.CiMaterial, ciMaterial)
 			}
@@ -50,6 +50,7 @@ import (
 	"github.com/devtron-labs/devtron/pkg/auth/user"
 	"github.com/devtron-labs/devtron/pkg/cluster"
 	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/deploymentConcurrency"
 	bean2 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
 	"github.com/devtron-labs/devtron/pkg/pipeline/executors"
 	"github.com/devtron-labs/devtron/pkg/pipeline/types"
@@ -131,9 +132,13 @@ type CdHandlerImpl struct {
 	argocdClientWrapperService             argocdServer.ArgoClientWrapperService
 	AppConfig                              *app.AppServiceConfig
 	acdConfig                              *argocdServer.ACDConfig
+	deploymentApprovalService              DeploymentApprovalService
+	deploymentAutoRollbackService          DeploymentAutoRollbackService
+	scheduledDeploymentRepository          pipelineConfig.ScheduledDeploymentRepository
+	deploymentConcurrencyService           deploymentConcurrency.DeploymentConcurrencyService
 }
 
-func NewCdHandlerImpl(Logger *zap.SugaredLogger, userService user.UserService, cdWorkflowRepository pipelineConfig.CdWorkflowRepository, ciLogService CiLogService, ciArtifactRepository repository.CiArtifactRepository, ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository, pipelineRepository pipelineConfig.PipelineRepository, envRepository repository2.EnvironmentRepository, ciWorkflowRepository pipelineConfig.CiWorkflowRepository, helmAppService client.HelmAppService, pipelineOverrideRepository chartConfig.PipelineOverrideRepository, workflowDagExecutor WorkflowDagExecutor, appListingService app.AppListingService, appListingRepository repository.AppListingRepository, pipelineStatusTimelineRepository pipelineConfig.PipelineStatusTimelineRepository, application application.ServiceClient, argoUserService argo.ArgoUserService, deploymentEventHandler app.DeploymentEventHandler, eventClient client2.EventClient, pipelineStatusTimelineResourcesService status.PipelineStatusTimelineResourcesService, pipelineStatusSyncDetailService status.PipelineStatusSyncDetailService, pipelineStatusTimelineService status.PipelineStatusTimelineService, appService app.AppService, appStatusService app_status.AppStatusService, enforcerUtil rbac.EnforcerUtil, installedAppRepository repository3.InstalledAppRepository, installedAppVersionHistoryRepository repository3.InstalledAppVersionHistoryRepository, appRepository app2.AppRepository, resourceGroupService resourceGroup2.ResourceGroupService, imageTaggingService ImageTaggingService, k8sUtil *k8s.K8sUtil, workflowService WorkflowService, clusterService cluster.ClusterService, blobConfigStorageService BlobStorageConfigService, customTagService CustomTagService, argocdClientWrapperService argocdServer.ArgoClientWrapperService, AppConfig *app.AppServiceConfig, acdConfig *argocdServer.ACDConfig) *CdHandlerImpl {
+func NewCdHandlerImpl(Logger *zap.SugaredLogger, userService user.UserService, cdWorkflowRepository pipelineConfig.CdWorkflowRepository, ciLogService CiLogService, ciArtifactRepository repository.CiArtifactRepository, ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository, pipelineRepository pipelineConfig.PipelineRepository, envRepository repository2.EnvironmentRepository, ciWorkflowRepository pipelineConfig.CiWorkflowRepository, helmAppService client.HelmAppService, pipelineOverrideRepository chartConfig.PipelineOverrideRepository, workflowDagExecutor WorkflowDagExecutor, appListingService app.AppListingService, appListingRepository repository.AppListingRepository, pipelineStatusTimelineRepository pipelineConfig.PipelineStatusTimelineRepository, application application.ServiceClient, argoUserService argo.ArgoUserService, deploymentEventHandler app.DeploymentEventHandler, eventClient client2.EventClient, pipelineStatusTimelineResourcesService status.PipelineStatusTimelineResourcesService, pipelineStatusSyncDetailService status.PipelineStatusSyncDetailService, pipelineStatusTimelineService status.PipelineStatusTimelineService, appService app.AppService, appStatusService app_status.AppStatusService, enforcerUtil rbac.EnforcerUtil, installedAppRepository repository3.InstalledAppRepository, installedAppVersionHistoryRepository repository3.InstalledAppVersionHistoryRepository, appRepository app2.AppRepository, resourceGroupService resourceGroup2.ResourceGroupService, imageTaggingService ImageTaggingService, k8sUtil *k8s.K8sUtil, workflowService WorkflowService, clusterService cluster.ClusterService, blobConfigStorageService BlobStorageConfigService, customTagService CustomTagService, argocdClientWrapperService argocdServer.ArgoClientWrapperService, AppConfig *app.AppServiceConfig, acdConfig *argocdServer.ACDConfig, deploymentApprovalService DeploymentApprovalService, deploymentAutoRollbackService DeploymentAutoRollbackService, scheduledDeploymentRepository pipelineConfig.ScheduledDeploymentRepository, deploymentConcurrencyService deploymentConcurrency.DeploymentConcurrencyService) *CdHandlerImpl {
 	cdh := &CdHandlerImpl{
 		Logger:                                 Logger,
 		userService:                            userService,
@@ -173,6 +178,10 @@ func NewCdHandlerImpl(Logger *zap.SugaredLogger, userService user.UserService, c
 		argocdClientWrapperService:             argocdClientWrapperService,
 		AppConfig:                              AppConfig,
 		acdConfig:                              acdConfig,
+		deploymentApprovalService:              deploymentApprovalService,
+		deploymentAutoRollbackService:          deploymentAutoRollbackService,
+		scheduledDeploymentRepository:          scheduledDeploymentRepository,
+		deploymentConcurrencyService:           deploymentConcurrencyService,
 	}
 	config, err := types.GetCdConfig()
 	if err != nil {
@@ -426,6 +435,8 @@ func (impl *CdHandlerImpl) UpdatePipelineTimelineAndStatusByLiveApplicationFetch
 				impl.Logger.Errorw("error in handling deployment success event", "pipelineOverride", pipelineOverride, "err", err)
 				return err, isTimelineUpdated
 			}
+		} else {
+			impl.triggerAutoRollbackIfDegraded(cdWfr.Id)
 		}
 	} else {
 		isAppStore := true
@@ -540,6 +551,19 @@ func (impl *CdHandlerImpl) UpdatePipelineTimelineAndStatusByLiveApplicationFetch
 	return nil, isTimelineUpdated
 }
 
+// triggerAutoRollbackIfDegraded rolls back the deployment of wfrId if its status was updated to a degraded state
+func (impl *CdHandlerImpl) triggerAutoRollbackIfDegraded(wfrId int) {
+	wfr, err := impl.cdWorkflowRepository.FindWorkflowRunnerById(wfrId)
+	if err != nil {
+		impl.Logger.Errorw("error in fetching cd workflow runner", "wfrId", wfrId, "err", err)
+		return
+	}
+	err = impl.deploymentAutoRollbackService.TriggerAutoRollbackIfEligible(wfr)
+	if err != nil {
+		impl.Logger.Errorw("error in triggering auto rollback for degraded deployment", "wfrId", wfrId, "err", err)
+	}
+}
+
 func (impl *CdHandlerImpl) CheckHelmAppStatusPeriodicallyAndUpdateInDb(helmPipelineStatusCheckEligibleTime int, getPipelineDeployedWithinHours int) error {
 	wfrList, err := impl.cdWorkflowRepository.GetLatestTriggersOfHelmPipelinesStuckInNonTerminalStatuses(getPipelineDeployedWithinHours)
 	if err != nil {
@@ -577,6 +601,12 @@ func (impl *CdHandlerImpl) CheckHelmAppStatusPeriodicallyAndUpdateInDb(helmPipel
 		if slices.Contains(pipelineConfig.WfrTerminalStatusList, wfr.Status) {
 			impl.workflowDagExecutor.UpdateTriggerCDMetricsOnFinish(wfr)
 		}
+		if wfr.Status == pipelineConfig.WorkflowFailed {
+			err = impl.deploymentAutoRollbackService.TriggerAutoRollbackIfEligible(wfr)
+			if err != nil {
+				impl.Logger.Errorw("error in triggering auto rollback for failed helm deployment", "wfrId", wfr.Id, "err", err)
+			}
+		}
 
 		impl.Logger.Infow("updated workflow runner status for helm app", "wfr", wfr)
 		if wfr.Status == pipelineConfig.WorkflowSucceeded {
@@ -1106,6 +1136,15 @@ func (impl *CdHandlerImpl) FetchCdWorkflowDetails(appId int, environmentId int,
 		PodName:              workflowR.PodName,
 		ArtifactId:           workflow.CiArtifactId,
 		CiPipelineId:         ciWf.CiPipelineId,
+		TriggerType:          workflowR.TriggerType,
+	}
+	if workflowR.DeploymentApprovalRequestId > 0 {
+		deploymentApprovalData, err := impl.deploymentApprovalService.FetchApprovalDataById(workflowR.DeploymentApprovalRequestId)
+		if err != nil {
+			impl.Logger.Errorw("error in fetching deployment approval data", "wfrId", workflowR.Id, "approvalRequestId", workflowR.DeploymentApprovalRequestId, "err", err)
+			return types.WorkflowResponse{}, err
+		}
+		workflowResponse.DeploymentApprovalData = deploymentApprovalData
 	}
 	return workflowResponse, nil
 
@@ -1217,6 +1256,7 @@ func (impl *CdHandlerImpl) converterWFR(wfr pipelineConfig.CdWorkflowRunner) pip
 		workflow.CiArtifactId = wfr.CdWorkflow.CiArtifactId
 		workflow.BlobStorageEnabled = wfr.BlobStorageEnabled
 		workflow.RefCdWorkflowRunnerId = wfr.RefCdWorkflowRunnerId
+		workflow.TriggerType = wfr.TriggerType
 	}
 	return workflow
 }
@@ -1377,9 +1417,52 @@ func (impl *CdHandlerImpl) FetchAppWorkflowStatusForTriggerView(appId int) ([]*p
 		}
 	}
 
+	err = impl.setScheduledDeployments(cdWorkflowStatus, pipelineIds)
+	if err != nil {
+		return cdWorkflowStatus, err
+	}
+	err = impl.setDeploymentQueuePositions(cdWorkflowStatus)
 	return cdWorkflowStatus, err
 }
 
+func (impl *CdHandlerImpl) setScheduledDeployments(cdWorkflowStatus []*pipelineConfig.CdWorkflowStatus, pipelineIds []int) error {
+	scheduledDeployments, err := impl.scheduledDeploymentRepository.FindPendingByPipelineIds(pipelineIds)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.Logger.Errorw("error in fetching pending scheduled deployments", "pipelineIds", pipelineIds, "err", err)
+		return err
+	}
+	pipelineIdToScheduledDeployments := make(map[int][]*pipelineConfig.PendingScheduledDeployment)
+	for _, scheduledDeployment := range scheduledDeployments {
+		pipelineIdToScheduledDeployments[scheduledDeployment.PipelineId] = append(pipelineIdToScheduledDeployments[scheduledDeployment.PipelineId], &pipelineConfig.PendingScheduledDeployment{
+			Id:           scheduledDeployment.Id,
+			CiArtifactId: scheduledDeployment.CiArtifactId,
+			ScheduledOn:  scheduledDeployment.ScheduledOn,
+		})
+	}
+	for _, item := range cdWorkflowStatus {
+		item.ScheduledDeployments = pipelineIdToScheduledDeployments[item.PipelineId]
+	}
+	return nil
+}
+
+func (impl *CdHandlerImpl) setDeploymentQueuePositions(cdWorkflowStatus []*pipelineConfig.CdWorkflowStatus) error {
+	var queuedPipelineIds []int
+	for _, item := range cdWorkflowStatus {
+		if item.DeployStatus == pipelineConfig.WorkflowInQueue {
+			queuedPipelineIds = append(queuedPipelineIds, item.PipelineId)
+		}
+	}
+	queuePositions, err := impl.deploymentConcurrencyService.GetQueuePositionsByPipelineIds(queuedPipelineIds)
+	if err != nil {
+		impl.Logger.Errorw("error in fetching deployment queue positions", "pipelineIds", queuedPipelineIds, "err", err)
+		return err
+	}
+	for _, item := range cdWorkflowStatus {
+		item.QueuePosition = queuePositions[item.PipelineId]
+	}
+	return nil
+}
+
 func (impl *CdHandlerImpl) FetchAppWorkflowStatusForTriggerViewForEnvironment(request resourceGroup2.ResourceGroupingRequest, token string) ([]*pipelineConfig.CdWorkflowStatus, error) {
 	cdWorkflowStatus := make([]*pipelineConfig.CdWorkflowStatus, 0)
 	var pipelines []*pipelineConfig.Pipeline
@@ -1528,6 +1611,11 @@ func (impl *CdHandlerImpl) FetchAppWorkflowStatusForTriggerViewForEnvironment(re
 		}
 	}
 
+	err = impl.setScheduledDeployments(cdWorkflowStatus, pipelineIds)
+	if err != nil {
+		return cdWorkflowStatus, err
+	}
+	err = impl.setDeploymentQueuePositions(cdWorkflowStatus)
 	return cdWorkflowStatus, err
 }
 
@@ -3,8 +3,10 @@ package pipeline
 import (
 	"errors"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
 	"go.uber.org/zap"
+	"net/http"
 	"time"
 )
 
@@ -28,6 +30,9 @@ func NewCiBuildConfigServiceImpl(logger *zap.SugaredLogger, ciBuildConfigReposit
 }
 
 func (impl *CiBuildConfigServiceImpl) Save(templateId int, overrideTemplateId int, ciBuildConfigBean *bean.CiBuildConfigBean, userId int32) error {
+	if err := validateBuildMatrix(ciBuildConfigBean); err != nil {
+		return err
+	}
 	ciBuildConfigEntity, err := bean.ConvertBuildConfigBeanToDbEntity(templateId, overrideTemplateId, ciBuildConfigBean, userId)
 	if err != nil {
 		impl.Logger.Errorw("error occurred while converting build config to db entity", "templateId", templateId,
@@ -50,6 +55,9 @@ func (impl *CiBuildConfigServiceImpl) UpdateOrSave(templateId int, overrideTempl
 		impl.Logger.Warnw("not updating build config as object is empty", "ciBuildConfig", ciBuildConfig)
 		return nil, nil
 	}
+	if err := validateBuildMatrix(ciBuildConfig); err != nil {
+		return nil, err
+	}
 	ciBuildConfigEntity, err := bean.ConvertBuildConfigBeanToDbEntity(templateId, overrideTemplateId, ciBuildConfig, userId)
 	if err != nil {
 		impl.Logger.Errorw("error occurred while converting build config to db entity", "templateId", templateId,
@@ -86,3 +94,15 @@ func (impl *CiBuildConfigServiceImpl) GetCountByBuildType() map[bean.CiBuildType
 	}
 	return result
 }
+
+func validateBuildMatrix(ciBuildConfig *bean.CiBuildConfigBean) error {
+	err := ciBuildConfig.ValidateBuildMatrix()
+	if err != nil {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: err.Error(),
+			UserMessage:     err.Error(),
+		}
+	}
+	return nil
+}
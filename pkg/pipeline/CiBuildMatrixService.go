@@ -0,0 +1,340 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	dockerRegistryRepository "github.com/devtron-labs/devtron/internal/sql/repository/dockerRegistry"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/dockerRegistry"
+	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/pkg/pipeline/types"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/registry"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const insecureRegistryConnection = "insecure"
+
+type CiBuildMatrixService interface {
+	// SaveBuildMatrix records the images of the platforms the workflow of the request is going to build
+	SaveBuildMatrix(workflowRequest *types.WorkflowRequest) error
+	// HandleImageBuilt records the image of a platform built by the workflow, or the completion of its pre or post ci
+	// stage reported without image. Once the images of all the platforms are built their manifest list is pushed, and
+	// once the stages are complete as well the request is pointed to it. It returns false while images or stages are
+	// pending and true for workflows without build matrix
+	HandleImageBuilt(ciWorkflowId int, request *CiArtifactWebhookRequest) (bool, error)
+	GetBuildMatrixStatus(ciWorkflowId int) (*bean.BuildMatrixStatus, error)
+	// MarkBuildMatrixFailed fails the build matrix of a failed workflow along with its platforms not built yet
+	MarkBuildMatrixFailed(ciWorkflowId int, message string) error
+}
+
+type CiBuildMatrixServiceImpl struct {
+	logger                        *zap.SugaredLogger
+	ciBuildMatrixRepository       pipelineConfig.CiBuildMatrixRepository
+	ciWorkflowRepository          pipelineConfig.CiWorkflowRepository
+	dockerArtifactStoreRepository dockerRegistryRepository.DockerArtifactStoreRepository
+}
+
+func NewCiBuildMatrixServiceImpl(logger *zap.SugaredLogger, ciBuildMatrixRepository pipelineConfig.CiBuildMatrixRepository,
+	ciWorkflowRepository pipelineConfig.CiWorkflowRepository,
+	dockerArtifactStoreRepository dockerRegistryRepository.DockerArtifactStoreRepository) *CiBuildMatrixServiceImpl {
+	return &CiBuildMatrixServiceImpl{
+		logger:                        logger,
+		ciBuildMatrixRepository:       ciBuildMatrixRepository,
+		ciWorkflowRepository:          ciWorkflowRepository,
+		dockerArtifactStoreRepository: dockerArtifactStoreRepository,
+	}
+}
+
+func (impl *CiBuildMatrixServiceImpl) SaveBuildMatrix(workflowRequest *types.WorkflowRequest) error {
+	buildMatrix := workflowRequest.CiBuildConfig.GetBuildMatrix()
+	if buildMatrix == nil {
+		return nil
+	}
+	ciBuildMatrix := &pipelineConfig.CiBuildMatrix{
+		CiWorkflowId:     workflowRequest.WorkflowId,
+		DockerRegistryId: workflowRequest.DockerRegistryId,
+		DockerRepository: workflowRequest.DockerRepository,
+		ImageTag:         workflowRequest.DockerImageTag,
+		Image:            fmt.Sprintf(bean.ImagePathPattern, workflowRequest.DockerRegistryURL, workflowRequest.DockerRepository, workflowRequest.DockerImageTag),
+		Status:           string(bean.BUILD_MATRIX_STATUS_RUNNING),
+		AuditLog:         sql.NewDefaultAuditLog(workflowRequest.TriggeredBy),
+	}
+	if workflowRequest.HasPreCiStage() {
+		ciBuildMatrix.PendingStages++
+	}
+	if workflowRequest.HasPostCiStage() {
+		ciBuildMatrix.PendingStages++
+	}
+	var platforms []*pipelineConfig.CiBuildMatrixPlatform
+	for _, platform := range buildMatrix.Platforms {
+		platforms = append(platforms, &pipelineConfig.CiBuildMatrixPlatform{
+			Platform: platform.Platform,
+			ImageTag: bean.GetBuildMatrixImageTag(workflowRequest.DockerImageTag, platform.Platform),
+			Status:   string(bean.BUILD_MATRIX_STATUS_RUNNING),
+			AuditLog: sql.NewDefaultAuditLog(workflowRequest.TriggeredBy),
+		})
+	}
+	err := impl.ciBuildMatrixRepository.Save(ciBuildMatrix, platforms)
+	if err != nil {
+		impl.logger.Errorw("error in saving ci build matrix", "ciWorkflowId", workflowRequest.WorkflowId, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *CiBuildMatrixServiceImpl) HandleImageBuilt(ciWorkflowId int, request *CiArtifactWebhookRequest) (bool, error) {
+	dbConnection := impl.ciBuildMatrixRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return false, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	// the images of the platforms are reported concurrently, the lock lets only the last of them push the manifest list
+	ciBuildMatrix, err := impl.ciBuildMatrixRepository.FindByCiWorkflowIdForUpdate(ciWorkflowId, tx)
+	if err == pg.ErrNoRows {
+		return true, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching ci build matrix", "ciWorkflowId", ciWorkflowId, "err", err)
+		return false, err
+	}
+	if len(request.Image) == 0 {
+		// the pre and post ci stages skip the build, the order of their reports and the ones of the platforms is not
+		// relied upon as they are delivered asynchronously
+		return impl.handleStageCompleted(ciBuildMatrix, request, tx)
+	}
+	if ciBuildMatrix.Status != string(bean.BUILD_MATRIX_STATUS_RUNNING) {
+		impl.logger.Infow("ignoring image of finished build matrix", "ciWorkflowId", ciWorkflowId, "image", request.Image)
+		return false, nil
+	}
+	platforms, err := impl.ciBuildMatrixRepository.FindPlatformsByBuildMatrixId(ciBuildMatrix.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci build matrix platforms", "ciBuildMatrixId", ciBuildMatrix.Id, "err", err)
+		return false, err
+	}
+	builtPlatform := findBuildMatrixPlatformOfImage(platforms, request.Image)
+	if builtPlatform == nil {
+		return false, fmt.Errorf("image %s is not built by the build matrix of workflow %d", request.Image, ciWorkflowId)
+	}
+	builtPlatform.ImageDigest = request.ImageDigest
+	builtPlatform.Status = string(bean.BUILD_MATRIX_STATUS_SUCCEEDED)
+	builtPlatform.UpdatedOn = time.Now()
+	builtPlatform.UpdatedBy = request.UserId
+	err = impl.ciBuildMatrixRepository.UpdatePlatform(builtPlatform, tx)
+	if err != nil {
+		impl.logger.Errorw("error in updating ci build matrix platform", "platform", builtPlatform, "err", err)
+		return false, err
+	}
+	for _, platform := range platforms {
+		if platform.Status != string(bean.BUILD_MATRIX_STATUS_SUCCEEDED) {
+			return false, tx.Commit()
+		}
+	}
+
+	manifestList, err := impl.createManifestList(ciBuildMatrix, platforms)
+	ciBuildMatrix.UpdatedOn = time.Now()
+	ciBuildMatrix.UpdatedBy = request.UserId
+	if err != nil {
+		impl.logger.Errorw("error in creating manifest list of build matrix", "ciWorkflowId", ciWorkflowId, "err", err)
+		ciBuildMatrix.Status = string(bean.BUILD_MATRIX_STATUS_FAILED)
+		ciBuildMatrix.Message = err.Error()
+		if updateErr := tx.Update(ciBuildMatrix); updateErr == nil {
+			_ = tx.Commit()
+		}
+		impl.markWorkflowFailed(ciWorkflowId, fmt.Sprintf("error in creating multi-arch manifest list: %s", err.Error()))
+		return false, err
+	}
+	ciBuildMatrix.Status = string(bean.BUILD_MATRIX_STATUS_SUCCEEDED)
+	ciBuildMatrix.ImageDigest = manifestList.Digest
+	err = tx.Update(ciBuildMatrix)
+	if err != nil {
+		impl.logger.Errorw("error in updating ci build matrix", "ciBuildMatrixId", ciBuildMatrix.Id, "err", err)
+		return false, err
+	}
+	for _, platform := range platforms {
+		// the digest in registry is of the platform manifest even when the image was pushed as an index
+		if digest, ok := manifestList.PlatformDigests[platform.Platform]; ok {
+			platform.ImageDigest = digest
+			err = impl.ciBuildMatrixRepository.UpdatePlatform(platform, tx)
+			if err != nil {
+				impl.logger.Errorw("error in updating ci build matrix platform", "platform", platform, "err", err)
+				return false, err
+			}
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	if ciBuildMatrix.PendingStages > 0 {
+		// the artifact is saved once the post ci stage completes
+		return false, nil
+	}
+	request.Image = ciBuildMatrix.Image
+	request.ImageDigest = manifestList.Digest
+	return true, nil
+}
+
+// handleStageCompleted records the completion of the pre or post ci stage of the build matrix, the build is complete
+// when it is the last one pending and the manifest list of the platforms is already pushed
+func (impl *CiBuildMatrixServiceImpl) handleStageCompleted(ciBuildMatrix *pipelineConfig.CiBuildMatrix, request *CiArtifactWebhookRequest, tx *pg.Tx) (bool, error) {
+	if ciBuildMatrix.Status == string(bean.BUILD_MATRIX_STATUS_FAILED) || ciBuildMatrix.PendingStages == 0 {
+		impl.logger.Infow("ignoring stage completion of build matrix", "ciWorkflowId", ciBuildMatrix.CiWorkflowId, "status", ciBuildMatrix.Status)
+		return false, nil
+	}
+	ciBuildMatrix.PendingStages--
+	ciBuildMatrix.UpdatedOn = time.Now()
+	ciBuildMatrix.UpdatedBy = request.UserId
+	err := tx.Update(ciBuildMatrix)
+	if err != nil {
+		impl.logger.Errorw("error in updating ci build matrix", "ciBuildMatrixId", ciBuildMatrix.Id, "err", err)
+		return false, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	if ciBuildMatrix.PendingStages > 0 || ciBuildMatrix.Status != string(bean.BUILD_MATRIX_STATUS_SUCCEEDED) {
+		return false, nil
+	}
+	request.Image = ciBuildMatrix.Image
+	request.ImageDigest = ciBuildMatrix.ImageDigest
+	return true, nil
+}
+
+func (impl *CiBuildMatrixServiceImpl) GetBuildMatrixStatus(ciWorkflowId int) (*bean.BuildMatrixStatus, error) {
+	ciBuildMatrix, err := impl.ciBuildMatrixRepository.FindByCiWorkflowId(ciWorkflowId)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching ci build matrix", "ciWorkflowId", ciWorkflowId, "err", err)
+		return nil, err
+	}
+	platforms, err := impl.ciBuildMatrixRepository.FindPlatformsByBuildMatrixId(ciBuildMatrix.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci build matrix platforms", "ciBuildMatrixId", ciBuildMatrix.Id, "err", err)
+		return nil, err
+	}
+	imagePrefix := strings.TrimSuffix(ciBuildMatrix.Image, ciBuildMatrix.ImageTag)
+	status := &bean.BuildMatrixStatus{
+		Image:       ciBuildMatrix.Image,
+		ImageDigest: ciBuildMatrix.ImageDigest,
+		Status:      bean.BuildMatrixStatusType(ciBuildMatrix.Status),
+		Message:     ciBuildMatrix.Message,
+	}
+	for _, platform := range platforms {
+		status.Platforms = append(status.Platforms, &bean.BuildMatrixPlatformStatus{
+			Platform:    platform.Platform,
+			Image:       imagePrefix + platform.ImageTag,
+			ImageDigest: platform.ImageDigest,
+			Status:      bean.BuildMatrixStatusType(platform.Status),
+		})
+	}
+	return status, nil
+}
+
+func (impl *CiBuildMatrixServiceImpl) MarkBuildMatrixFailed(ciWorkflowId int, message string) error {
+	dbConnection := impl.ciBuildMatrixRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	ciBuildMatrix, err := impl.ciBuildMatrixRepository.FindByCiWorkflowIdForUpdate(ciWorkflowId, tx)
+	if err == pg.ErrNoRows {
+		return nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching ci build matrix", "ciWorkflowId", ciWorkflowId, "err", err)
+		return err
+	}
+	platforms, err := impl.ciBuildMatrixRepository.FindPlatformsByBuildMatrixId(ciBuildMatrix.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci build matrix platforms", "ciBuildMatrixId", ciBuildMatrix.Id, "err", err)
+		return err
+	}
+	if ciBuildMatrix.Status != string(bean.BUILD_MATRIX_STATUS_FAILED) {
+		ciBuildMatrix.Status = string(bean.BUILD_MATRIX_STATUS_FAILED)
+		ciBuildMatrix.Message = message
+		ciBuildMatrix.UpdatedOn = time.Now()
+		err = tx.Update(ciBuildMatrix)
+		if err != nil {
+			impl.logger.Errorw("error in updating ci build matrix", "ciBuildMatrixId", ciBuildMatrix.Id, "err", err)
+			return err
+		}
+	}
+	for _, platform := range platforms {
+		if platform.Status != string(bean.BUILD_MATRIX_STATUS_RUNNING) {
+			continue
+		}
+		platform.Status = string(bean.BUILD_MATRIX_STATUS_FAILED)
+		platform.UpdatedOn = time.Now()
+		err = impl.ciBuildMatrixRepository.UpdatePlatform(platform, tx)
+		if err != nil {
+			impl.logger.Errorw("error in updating ci build matrix platform", "platform", platform, "err", err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (impl *CiBuildMatrixServiceImpl) createManifestList(ciBuildMatrix *pipelineConfig.CiBuildMatrix, platforms []*pipelineConfig.CiBuildMatrixPlatform) (*registry.ManifestList, error) {
+	dockerArtifactStore, err := impl.dockerArtifactStoreRepository.FindOne(ciBuildMatrix.DockerRegistryId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching docker registry", "dockerRegistryId", ciBuildMatrix.DockerRegistryId, "err", err)
+		return nil, err
+	}
+	credential := &registry.Credential{
+		RegistryURL: dockerArtifactStore.RegistryURL,
+		Username:    dockerArtifactStore.Username,
+		Password:    dockerArtifactStore.Password,
+		Insecure:    dockerArtifactStore.Connection == insecureRegistryConnection,
+		Cert:        dockerArtifactStore.Cert,
+	}
+	if dockerArtifactStore.RegistryType == dockerRegistryRepository.REGISTRYTYPE_ECR {
+		credential.Username, credential.Password, err = dockerRegistry.CreateCredentialForEcr(dockerArtifactStore.AWSRegion, dockerArtifactStore.AWSAccessKeyId, dockerArtifactStore.AWSSecretAccessKey)
+		if err != nil {
+			impl.logger.Errorw("error in creating ecr credential", "dockerRegistryId", ciBuildMatrix.DockerRegistryId, "err", err)
+			return nil, err
+		}
+	}
+	registryClient, err := registry.NewClient(credential)
+	if err != nil {
+		return nil, err
+	}
+	var images []*registry.PlatformImage
+	for _, platform := range platforms {
+		images = append(images, &registry.PlatformImage{Platform: platform.Platform, Tag: platform.ImageTag})
+	}
+	return registryClient.CreateManifestList(ciBuildMatrix.DockerRepository, ciBuildMatrix.ImageTag, images)
+}
+
+func (impl *CiBuildMatrixServiceImpl) markWorkflowFailed(ciWorkflowId int, message string) {
+	ciWorkflow, err := impl.ciWorkflowRepository.FindById(ciWorkflowId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci workflow", "ciWorkflowId", ciWorkflowId, "err", err)
+		return
+	}
+	ciWorkflow.Status = pipelineConfig.WorkflowFailed
+	ciWorkflow.Message = message
+	err = impl.ciWorkflowRepository.UpdateWorkFlow(ciWorkflow)
+	if err != nil {
+		impl.logger.Errorw("error in marking ci workflow failed", "ciWorkflowId", ciWorkflowId, "err", err)
+	}
+}
+
+// findBuildMatrixPlatformOfImage returns the platform whose image tag is the tag of image
+func findBuildMatrixPlatformOfImage(platforms []*pipelineConfig.CiBuildMatrixPlatform, image string) *pipelineConfig.CiBuildMatrixPlatform {
+	image, _, _ = strings.Cut(image, "@")
+	tag := image[strings.LastIndex(image, ":")+1:]
+	for _, platform := range platforms {
+		if platform.ImageTag == tag {
+			return platform
+		}
+	}
+	return nil
+}
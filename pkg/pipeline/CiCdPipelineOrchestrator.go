@@ -26,6 +26,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"path"
 	"regexp"
 	"strconv"
@@ -334,6 +335,10 @@ func (impl CiCdPipelineOrchestratorImpl) saveUpdatedMaterial(materialsUpdate []*
 	return nil
 }
 func (impl CiCdPipelineOrchestratorImpl) PatchMaterialValue(createRequest *bean.CiPipeline, userId int32, oldPipeline *pipelineConfig.CiPipeline) (*bean.CiPipeline, error) {
+	err := validateCiMaterialPathFilters(createRequest.CiMaterial)
+	if err != nil {
+		return nil, err
+	}
 	argByte, err := json.Marshal(createRequest.DockerArgs)
 	if err != nil {
 		impl.logger.Error(err)
@@ -473,6 +478,8 @@ func (impl CiCdPipelineOrchestratorImpl) PatchMaterialValue(createRequest *bean.
 			Active:        createRequest.Active,
 			Regex:         material.Source.Regex,
 			GitMaterialId: material.GitMaterialId,
+			IncludePaths:  material.IncludePaths,
+			ExcludePaths:  material.ExcludePaths,
 			AuditLog:      sql.AuditLog{UpdatedBy: userId, UpdatedOn: time.Now()},
 		}
 		if material.Source.Type == pipelineConfig.SOURCE_TYPE_BRANCH_FIXED {
@@ -648,6 +655,8 @@ func (impl CiCdPipelineOrchestratorImpl) PatchMaterialValue(createRequest *bean.
 					Type:          parentMaterial.Source.Type,
 					GitMaterialId: parentMaterial.GitMaterialId,
 					CiPipelineId:  ciPipelineMaterial.CiPipelineId,
+					IncludePaths:  parentMaterial.IncludePaths,
+					ExcludePaths:  parentMaterial.ExcludePaths,
 				}
 				linkedMaterials = append(linkedMaterials, pipelineMaterial)
 			} else {
@@ -793,6 +802,12 @@ func (impl CiCdPipelineOrchestratorImpl) deleteCiEnvMapping(tx *pg.Tx, ciPipelin
 	return nil
 }
 func (impl CiCdPipelineOrchestratorImpl) CreateCiConf(createRequest *bean.CiConfigRequest, templateId int) (*bean.CiConfigRequest, error) {
+	for _, ciPipeline := range createRequest.CiPipelines {
+		err := validateCiMaterialPathFilters(ciPipeline.CiMaterial)
+		if err != nil {
+			return nil, err
+		}
+	}
 	//save pipeline in db start
 	for _, ciPipeline := range createRequest.CiPipelines {
 		argByte, err := json.Marshal(ciPipeline.DockerArgs)
@@ -896,6 +911,8 @@ func (impl CiCdPipelineOrchestratorImpl) CreateCiConf(createRequest *bean.CiConf
 				CiPipelineId:  ciPipelineObject.Id,
 				Active:        true,
 				Regex:         r.Source.Regex,
+				IncludePaths:  r.IncludePaths,
+				ExcludePaths:  r.ExcludePaths,
 				AuditLog:      sql.AuditLog{UpdatedBy: createRequest.UserId, CreatedBy: createRequest.UserId, UpdatedOn: time.Now(), CreatedOn: time.Now()},
 			}
 			if material.Regex == "" && r.Source.Type == pipelineConfig.SOURCE_TYPE_BRANCH_REGEX {
@@ -1559,6 +1576,17 @@ func (impl CiCdPipelineOrchestratorImpl) CreateCDPipelines(pipelineRequest *bean
 		return 0, err
 	}
 
+	userApprovalConfig, err := getUserApprovalConfigJson(pipelineRequest.UserApprovalConf)
+	if err != nil {
+		impl.logger.Errorw("error in marshalling user approval config", "userApprovalConf", pipelineRequest.UserApprovalConf, "err", err)
+		return 0, err
+	}
+	autoRollbackConfig, err := getAutoRollbackConfigJson(pipelineRequest.AutoRollbackConf)
+	if err != nil {
+		impl.logger.Errorw("error in marshalling auto rollback config", "autoRollbackConf", pipelineRequest.AutoRollbackConf, "err", err)
+		return 0, err
+	}
+
 	env, err := impl.envRepository.FindById(pipelineRequest.EnvironmentId)
 	if err != nil {
 		impl.logger.Errorw("error in getting environment by id", "err", err)
@@ -1582,6 +1610,8 @@ func (impl CiCdPipelineOrchestratorImpl) CreateCDPipelines(pipelineRequest *bean
 		DeploymentAppCreated:          false,
 		DeploymentAppType:             pipelineRequest.DeploymentAppType,
 		DeploymentAppName:             fmt.Sprintf("%s-%s", appName, env.Name),
+		UserApprovalConfig:            userApprovalConfig,
+		AutoRollbackConfig:            autoRollbackConfig,
 		AuditLog:                      sql.AuditLog{UpdatedBy: userId, CreatedBy: userId, UpdatedOn: time.Now(), CreatedOn: time.Now()},
 	}
 	err = impl.pipelineRepository.Save([]*pipelineConfig.Pipeline{pipeline}, tx)
@@ -1647,6 +1677,17 @@ func (impl CiCdPipelineOrchestratorImpl) UpdateCDPipeline(pipelineRequest *bean.
 		return pipeline, err
 	}
 
+	userApprovalConfig, err := getUserApprovalConfigJson(pipelineRequest.UserApprovalConf)
+	if err != nil {
+		impl.logger.Errorw("error in marshalling user approval config", "userApprovalConf", pipelineRequest.UserApprovalConf, "err", err)
+		return pipeline, err
+	}
+	autoRollbackConfig, err := getAutoRollbackConfigJson(pipelineRequest.AutoRollbackConf)
+	if err != nil {
+		impl.logger.Errorw("error in marshalling auto rollback config", "autoRollbackConf", pipelineRequest.AutoRollbackConf, "err", err)
+		return pipeline, err
+	}
+
 	pipeline.TriggerType = pipelineRequest.TriggerType
 	pipeline.PreTriggerType = preTriggerType
 	pipeline.PostTriggerType = postTriggerType
@@ -1656,6 +1697,8 @@ func (impl CiCdPipelineOrchestratorImpl) UpdateCDPipeline(pipelineRequest *bean.
 	pipeline.PostStageConfigMapSecretNames = string(postStageConfigMapSecretNames)
 	pipeline.RunPreStageInEnv = pipelineRequest.RunPreStageInEnv
 	pipeline.RunPostStageInEnv = pipelineRequest.RunPostStageInEnv
+	pipeline.UserApprovalConfig = userApprovalConfig
+	pipeline.AutoRollbackConfig = autoRollbackConfig
 	pipeline.UpdatedBy = userId
 	pipeline.UpdatedOn = time.Now()
 	err = impl.pipelineRepository.Update(pipeline, tx)
@@ -2077,3 +2120,42 @@ func (impl CiCdPipelineOrchestratorImpl) AddPipelineToTemplate(createRequest *be
 	}
 	return createRequest, err
 }
+
+func getUserApprovalConfigJson(userApprovalConf *pipelineConfig.UserApprovalConfig) (string, error) {
+	if userApprovalConf == nil {
+		return "", nil
+	}
+	userApprovalConfig, err := json.Marshal(userApprovalConf)
+	if err != nil {
+		return "", err
+	}
+	return string(userApprovalConfig), nil
+}
+
+func getAutoRollbackConfigJson(autoRollbackConf *pipelineConfig.AutoRollbackConfig) (string, error) {
+	if autoRollbackConf == nil {
+		return "", nil
+	}
+	autoRollbackConfig, err := json.Marshal(autoRollbackConf)
+	if err != nil {
+		return "", err
+	}
+	return string(autoRollbackConfig), nil
+}
+
+func validateCiMaterialPathFilters(ciMaterials []*bean.CiMaterial) error {
+	for _, ciMaterial := range ciMaterials {
+		err := bean2.ValidatePathFilters(ciMaterial.IncludePaths)
+		if err == nil {
+			err = bean2.ValidatePathFilters(ciMaterial.ExcludePaths)
+		}
+		if err != nil {
+			return &util.ApiError{
+				HttpStatusCode:  http.StatusBadRequest,
+				InternalMessage: err.Error(),
+				UserMessage:     err.Error(),
+			}
+		}
+	}
+	return nil
+}
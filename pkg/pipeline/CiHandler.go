@@ -39,6 +39,7 @@ import (
 	"github.com/devtron-labs/devtron/client/gitSensor"
 	"github.com/devtron-labs/devtron/internal/sql/repository/appWorkflow"
 	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/ciBuildQueue"
 	"github.com/devtron-labs/devtron/pkg/cluster"
 	repository3 "github.com/devtron-labs/devtron/pkg/cluster/repository"
 	k8s2 "github.com/devtron-labs/devtron/pkg/k8s"
@@ -46,6 +47,7 @@ import (
 	"github.com/devtron-labs/devtron/pkg/pipeline/executors"
 	"github.com/devtron-labs/devtron/pkg/pipeline/types"
 	resourceGroup "github.com/devtron-labs/devtron/pkg/resourceGroup"
+	"github.com/devtron-labs/devtron/pkg/testAnalytics"
 	"github.com/devtron-labs/devtron/util/rbac"
 	errors2 "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/rest"
@@ -84,7 +86,8 @@ type CiHandler interface {
 	FetchCiStatusForTriggerViewV1(appId int) ([]*pipelineConfig.CiWorkflowStatus, error)
 	RefreshMaterialByCiPipelineMaterialId(gitMaterialId int) (refreshRes *gitSensor.RefreshGitMaterialResponse, err error)
 	FetchMaterialInfoByArtifactId(ciArtifactId int, envId int) (*types.GitTriggerInfoResponse, error)
-	WriteToCreateTestSuites(pipelineId int, buildId int, triggeredBy int)
+	WriteToCreateTestSuites(pipelineId int, buildId int, triggeredBy int) (failuresQuarantined bool)
+	IngestTestReports(pipelineId int, buildId int)
 	UpdateCiWorkflowStatusFailure(timeoutForFailureCiBuild int) error
 	FetchCiStatusForTriggerViewForEnvironment(request resourceGroup.ResourceGroupingRequest, token string) ([]*pipelineConfig.CiWorkflowStatus, error)
 }
@@ -116,13 +119,18 @@ type CiHandlerImpl struct {
 	clusterService               cluster.ClusterService
 	blobConfigStorageService     BlobStorageConfigService
 	envService                   cluster.EnvironmentService
+	ciBuildMatrixService         CiBuildMatrixService
+	ciRetryPolicyService         CiRetryPolicyService
+	ciBuildQueueService          ciBuildQueue.CiBuildQueueService
+	testAnalyticsService         testAnalytics.TestAnalyticsService
 }
 
 func NewCiHandlerImpl(Logger *zap.SugaredLogger, ciService CiService, ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository, gitSensorClient gitSensor.Client, ciWorkflowRepository pipelineConfig.CiWorkflowRepository, workflowService WorkflowService,
 	ciLogService CiLogService, ciArtifactRepository repository.CiArtifactRepository, userService user.UserService, eventClient client.EventClient, eventFactory client.EventFactory, ciPipelineRepository pipelineConfig.CiPipelineRepository,
 	appListingRepository repository.AppListingRepository, K8sUtil *k8s.K8sUtil, cdPipelineRepository pipelineConfig.PipelineRepository, enforcerUtil rbac.EnforcerUtil, resourceGroupService resourceGroup.ResourceGroupService, envRepository repository3.EnvironmentRepository,
 	imageTaggingService ImageTaggingService, k8sCommonService k8s2.K8sCommonService, clusterService cluster.ClusterService, blobConfigStorageService BlobStorageConfigService, appWorkflowRepository appWorkflow.AppWorkflowRepository, customTagService CustomTagService,
-	envService cluster.EnvironmentService) *CiHandlerImpl {
+	envService cluster.EnvironmentService, ciBuildMatrixService CiBuildMatrixService, ciRetryPolicyService CiRetryPolicyService,
+	ciBuildQueueService ciBuildQueue.CiBuildQueueService, testAnalyticsService testAnalytics.TestAnalyticsService) *CiHandlerImpl {
 	cih := &CiHandlerImpl{
 		Logger:                       Logger,
 		ciService:                    ciService,
@@ -149,6 +157,10 @@ func NewCiHandlerImpl(Logger *zap.SugaredLogger, ciService CiService, ciPipeline
 		clusterService:               clusterService,
 		blobConfigStorageService:     blobConfigStorageService,
 		envService:                   envService,
+		ciBuildMatrixService:         ciBuildMatrixService,
+		ciRetryPolicyService:         ciRetryPolicyService,
+		ciBuildQueueService:          ciBuildQueueService,
+		testAnalyticsService:         testAnalyticsService,
 	}
 	config, err := types.GetCiConfig()
 	if err != nil {
@@ -180,6 +192,12 @@ func (impl *CiHandlerImpl) CheckAndReTriggerCI(workflowStatus v1alpha1.WorkflowS
 		return err
 	}
 
+	// retry policy of the pipeline, if any, takes over from the retries configured for all pipelines
+	handled, err := impl.ciRetryPolicyService.HandleWorkflowFailure(ciWorkFlow, status, message)
+	if err != nil || handled {
+		return err
+	}
+
 	if !executors.CheckIfReTriggerRequired(status, message, ciWorkFlow.Status) {
 		impl.Logger.Debugw("not re-triggering ci", "status", status, "message", message, "ciWorkflowStatus", ciWorkFlow.Status)
 		return nil
@@ -253,6 +271,7 @@ func (impl *CiHandlerImpl) HandleCIManual(ciTriggerRequest bean.CiTriggerRequest
 		EnvironmentId:             ciTriggerRequest.EnvironmentId,
 		PipelineType:              ciTriggerRequest.PipelineType,
 		CiArtifactLastFetch:       createdOn,
+		PriorityClass:             ciTriggerRequest.PriorityClass,
 	}
 	id, err := impl.ciService.TriggerCiPipeline(trigger)
 
@@ -289,12 +308,31 @@ func (impl *CiHandlerImpl) HandleCIWebhook(gitCiTriggerRequest bean.GitCiTrigger
 		return 0, err
 	}
 
+	var triggerMaterial *pipelineConfig.CiPipelineMaterial
+	for _, ciMaterial := range ciMaterials {
+		if ciMaterial.Id == gitCiTriggerRequest.CiPipelineMaterial.Id {
+			triggerMaterial = ciMaterial
+		}
+	}
+	var pathFilterResult *pipelineConfig.PathFilterResult
+	if triggerMaterial != nil && (len(triggerMaterial.IncludePaths) > 0 || len(triggerMaterial.ExcludePaths) > 0) {
+		// a push can carry several commits, so the files changed since the last build are filtered and not only the
+		// ones of the head commit
+		commit := gitCiTriggerRequest.CiPipelineMaterial.GitCommit
+		commit.Changes = impl.getChangedFilesSinceLastBuild(ciPipeline.Id, triggerMaterial, commit.Commit)
+		pathFilterResult = bean3.GetPathFilterResult(triggerMaterial, commit)
+	}
+	if pathFilterResult != nil && pathFilterResult.Skipped {
+		return impl.saveSkippedWorkflow(ciPipeline, commitHashes, gitCiTriggerRequest.TriggeredBy, pathFilterResult)
+	}
+
 	trigger := types.Trigger{
 		PipelineId:                ciPipeline.Id,
 		CommitHashes:              commitHashes,
 		CiMaterials:               ciMaterials,
 		TriggeredBy:               gitCiTriggerRequest.TriggeredBy,
 		ExtraEnvironmentVariables: gitCiTriggerRequest.ExtraEnvironmentVariables,
+		PathFilterResult:          pathFilterResult,
 	}
 	id, err := impl.ciService.TriggerCiPipeline(trigger)
 	if err != nil {
@@ -303,6 +341,64 @@ func (impl *CiHandlerImpl) HandleCIWebhook(gitCiTriggerRequest bean.GitCiTrigger
 	return id, nil
 }
 
+// getChangedFilesSinceLastBuild returns the files changed on the material since the commit of the last successful build
+// of the pipeline up to headCommit, nil if they are not known in which case the commit is built
+func (impl *CiHandlerImpl) getChangedFilesSinceLastBuild(ciPipelineId int, material *pipelineConfig.CiPipelineMaterial, headCommit string) []string {
+	lastBuilt, err := impl.ciWorkflowRepository.FindLastSucceededWorkflow(ciPipelineId)
+	if err != nil {
+		if err != pg.ErrNoRows {
+			impl.Logger.Errorw("error in fetching last successful ci workflow", "ciPipelineId", ciPipelineId, "err", err)
+		}
+		return nil
+	}
+	lastBuiltCommit := lastBuilt.GitTriggers[material.Id].Commit
+	if len(lastBuiltCommit) == 0 {
+		return nil
+	}
+	changesResp, err := impl.gitSensorClient.FetchChanges(context.Background(), &gitSensor.FetchScmChangesRequest{
+		PipelineMaterialId: material.Id,
+		ShowAll:            true,
+	})
+	if err != nil {
+		impl.Logger.Errorw("error in fetching changes from git sensor", "ciPipelineMaterialId", material.Id, "err", err)
+		return nil
+	}
+	commits := make([]pipelineConfig.GitCommit, 0, len(changesResp.Commits))
+	for _, commit := range changesResp.Commits {
+		commits = append(commits, pipelineConfig.GitCommit{Commit: commit.Commit, Changes: commit.Changes})
+	}
+	changedFiles, ok := bean3.GetChangedFilesSince(commits, headCommit, lastBuiltCommit)
+	if !ok {
+		impl.Logger.Infow("files changed since last build not known, skipping path filters", "ciPipelineMaterialId", material.Id, "headCommit", headCommit, "lastBuiltCommit", lastBuiltCommit)
+		return nil
+	}
+	return changedFiles
+}
+
+// saveSkippedWorkflow records a workflow which is not run as the commit triggering it changes no file matching the path
+// filters of its material, so that users can see why the commit did not build
+func (impl *CiHandlerImpl) saveSkippedWorkflow(ciPipeline *pipelineConfig.CiPipeline, commitHashes map[int]pipelineConfig.GitCommit,
+	triggeredBy int32, pathFilterResult *pipelineConfig.PathFilterResult) (int, error) {
+	ciWorkflow := &pipelineConfig.CiWorkflow{
+		Name:             ciPipeline.Name + "-" + strconv.Itoa(ciPipeline.Id),
+		Status:           pipelineConfig.WorkflowSkipped,
+		Message:          fmt.Sprintf("build skipped as none of the %d files changed since the last build up to commit %s match the path filters", pathFilterResult.ChangedFileCount, pathFilterResult.Commit),
+		StartedOn:        time.Now(),
+		FinishedOn:       time.Now(),
+		CiPipelineId:     ciPipeline.Id,
+		GitTriggers:      commitHashes,
+		TriggeredBy:      triggeredBy,
+		PathFilterResult: pathFilterResult,
+	}
+	err := impl.ciWorkflowRepository.SaveWorkFlow(ciWorkflow)
+	if err != nil {
+		impl.Logger.Errorw("error in saving skipped workflow", "ciPipelineId", ciPipeline.Id, "err", err)
+		return 0, err
+	}
+	impl.Logger.Infow("skipped ci trigger by path filters", "ciPipelineId", ciPipeline.Id, "commit", pathFilterResult.Commit, "ciWorkflowId", ciWorkflow.Id)
+	return 0, nil
+}
+
 func (impl *CiHandlerImpl) validateBuildSequence(gitCiTriggerRequest bean.GitCiTriggerRequest, pipelineId int) (bool, error) {
 	isValid := true
 	lastTriggeredBuild, err := impl.ciWorkflowRepository.FindLastTriggeredWorkflow(pipelineId)
@@ -554,6 +650,8 @@ func (impl *CiHandlerImpl) GetBuildHistory(pipelineId int, appId int, offset int
 			EnvironmentName:     w.EnvironmentName,
 			ReferenceWorkflowId: w.RefCiWorkflowId,
 			PodName:             w.PodName,
+			PathFilterResult:    w.PathFilterResult,
+			FailureReason:       w.FailureReason,
 		}
 		if w.Message == bean3.ImageTagUnavailableMessage {
 			customTag, err := impl.customTagService.GetCustomTagByEntityKeyAndValue(bean3.EntityTypeCiPipelineId, strconv.Itoa(w.CiPipelineId))
@@ -589,6 +687,9 @@ func (impl *CiHandlerImpl) CancelBuild(workflowId int, forceAbort bool) (int, er
 		impl.Logger.Errorw("err", "err", err)
 		return 0, err
 	}
+	if workflow.Status == pipelineConfig.WorkflowInQueue {
+		return impl.cancelQueuedBuild(workflow)
+	}
 	if !(string(v1alpha1.NodePending) == workflow.Status || string(v1alpha1.NodeRunning) == workflow.Status) {
 		if forceAbort {
 			return impl.cancelBuildAfterStartWorkflowStage(workflow)
@@ -646,6 +747,27 @@ func (impl *CiHandlerImpl) CancelBuild(workflowId int, forceAbort bool) (int, er
 	return workflow.Id, nil
 }
 
+// cancelQueuedBuild drops the build from the build queue, there is no workflow to terminate as it is not submitted yet
+func (impl *CiHandlerImpl) cancelQueuedBuild(workflow *pipelineConfig.CiWorkflow) (int, error) {
+	dropped, err := impl.ciBuildQueueService.DropQueuedBuild(workflow.Id, "cancelled by user")
+	if err != nil {
+		impl.Logger.Errorw("error in dropping build from queue", "ciWorkflowId", workflow.Id, "err", err)
+		return 0, err
+	}
+	if !dropped {
+		return 0, &util.ApiError{Code: "200", HttpStatusCode: 400, UserMessage: "cannot cancel build, build already released from queue"}
+	}
+	workflow.Status = executors.WorkflowCancel
+	workflow.Message = "build cancelled while waiting in queue"
+	workflow.FinishedOn = time.Now()
+	err = impl.ciWorkflowRepository.UpdateWorkFlow(workflow)
+	if err != nil {
+		impl.Logger.Errorw("error in updating workflow status", "err", err)
+		return 0, err
+	}
+	return workflow.Id, nil
+}
+
 func (impl *CiHandlerImpl) cancelBuildAfterStartWorkflowStage(workflow *pipelineConfig.CiWorkflow) (int, error) {
 	workflow.Status = executors.WorkflowCancel
 	workflow.PodStatus = string(bean.Failed)
@@ -731,6 +853,16 @@ func (impl *CiHandlerImpl) FetchWorkflowDetails(appId int, pipelineId int, build
 		}
 		environmentName = env.Name
 	}
+	buildMatrix, err := impl.ciBuildMatrixService.GetBuildMatrixStatus(workflow.Id)
+	if err != nil {
+		impl.Logger.Errorw("error in fetching build matrix status", "ciWorkflowId", workflow.Id, "err", err)
+		return types.WorkflowResponse{}, err
+	}
+	retryAttempts, err := impl.ciRetryPolicyService.GetRetryAttempts(workflow)
+	if err != nil {
+		impl.Logger.Errorw("error in fetching retry attempts", "ciWorkflowId", workflow.Id, "err", err)
+		return types.WorkflowResponse{}, err
+	}
 	workflowResponse := types.WorkflowResponse{
 		Id:                 workflow.Id,
 		Name:               workflow.Name,
@@ -754,6 +886,10 @@ func (impl *CiHandlerImpl) FetchWorkflowDetails(appId int, pipelineId int, build
 		EnvironmentName:    environmentName,
 		PipelineType:       workflow.CiPipeline.PipelineType,
 		PodName:            workflow.PodName,
+		BuildMatrix:        buildMatrix,
+		PathFilterResult:   workflow.PathFilterResult,
+		FailureReason:      workflow.FailureReason,
+		RetryAttempts:      retryAttempts,
 	}
 	return workflowResponse, nil
 }
@@ -1181,6 +1317,10 @@ func (impl *CiHandlerImpl) UpdateWorkflow(workflowStatus v1alpha1.WorkflowStatus
 		//savedWorkflow.LogLocation = logLocation // removed because we are saving log location at trigger
 		savedWorkflow.CiArtifactLocation = ciArtifactLocation
 		savedWorkflow.PodName = podName
+		if string(v1alpha1.NodeError) == savedWorkflow.Status || string(v1alpha1.NodeFailed) == savedWorkflow.Status {
+			failureReason, _ := bean3.ClassifyCiWorkflowFailure(bean3.CiFailureReason(savedWorkflow.FailureReason), message)
+			savedWorkflow.FailureReason = string(failureReason)
+		}
 		impl.Logger.Debugw("updating workflow ", "workflow", savedWorkflow)
 		err = impl.ciWorkflowRepository.UpdateWorkFlow(savedWorkflow)
 		if err != nil {
@@ -1189,19 +1329,40 @@ func (impl *CiHandlerImpl) UpdateWorkflow(workflowStatus v1alpha1.WorkflowStatus
 		}
 		if string(v1alpha1.NodeError) == savedWorkflow.Status || string(v1alpha1.NodeFailed) == savedWorkflow.Status {
 			impl.Logger.Warnw("ci failed for workflow: ", "wfId", savedWorkflow.Id)
-
-			if extractErrorCode(savedWorkflow.Message) != CiStageFailErrorCode {
+			failuresQuarantined := impl.WriteToCreateTestSuites(savedWorkflow.CiPipelineId, workflowId, int(savedWorkflow.TriggeredBy))
+			if failuresQuarantined && extractErrorCode(savedWorkflow.Message) == CiStageFailErrorCode {
+				// the build failed only because of quarantined tests, so it is not treated as failed
+				impl.markFailuresQuarantined(savedWorkflow)
+			} else if extractErrorCode(savedWorkflow.Message) != CiStageFailErrorCode {
 				go impl.WriteCIFailEvent(savedWorkflow, ciWorkflowConfig.CiImage)
 			} else {
 				impl.Logger.Infof("Step failed notification received for wfID %d with message %s", savedWorkflow.Id, savedWorkflow.Message)
 			}
-
-			impl.WriteToCreateTestSuites(savedWorkflow.CiPipelineId, workflowId, int(savedWorkflow.TriggeredBy))
+		} else if string(v1alpha1.NodeSucceeded) == savedWorkflow.Status && savedWorkflow.BlobStorageEnabled {
+			go impl.IngestTestReports(savedWorkflow.CiPipelineId, workflowId)
+		}
+		if string(v1alpha1.NodeError) == savedWorkflow.Status || string(v1alpha1.NodeFailed) == savedWorkflow.Status || executors.WorkflowCancel == savedWorkflow.Status {
+			err = impl.ciBuildMatrixService.MarkBuildMatrixFailed(savedWorkflow.Id, savedWorkflow.Message)
+			if err != nil {
+				impl.Logger.Errorw("error in marking build matrix failed", "ciWorkflowId", savedWorkflow.Id, "err", err)
+			}
 		}
 	}
 	return savedWorkflow.Id, nil
 }
 
+// markFailuresQuarantined marks the workflow succeeded when all of its failed tests are quarantined
+func (impl *CiHandlerImpl) markFailuresQuarantined(savedWorkflow *pipelineConfig.CiWorkflow) {
+	impl.Logger.Infow("all failed tests of the workflow are quarantined, marking it succeeded", "wfId", savedWorkflow.Id)
+	savedWorkflow.Status = string(v1alpha1.NodeSucceeded)
+	savedWorkflow.Message = "failed tests are quarantined"
+	savedWorkflow.FailureReason = ""
+	err := impl.ciWorkflowRepository.UpdateWorkFlow(savedWorkflow)
+	if err != nil {
+		impl.Logger.Errorw("error in marking workflow with quarantined failures succeeded", "wfId", savedWorkflow.Id, "err", err)
+	}
+}
+
 func extractErrorCode(msg string) int {
 	re := regexp.MustCompile(`\d+`)
 	matches := re.FindAllString(msg, -1)
@@ -1433,8 +1594,11 @@ func (impl *CiHandlerImpl) FetchCiStatusForTriggerViewV1(appId int) ([]*pipeline
 		impl.Logger.Errorw("err in fetching ciWorkflowStatuses from ciWorkflowRepository", "appId", appId, "err", err)
 		return ciWorkflowStatuses, err
 	}
-
-	return ciWorkflowStatuses, err
+	err = impl.setQueuePositions(ciWorkflowStatuses)
+	if err != nil {
+		return ciWorkflowStatuses, err
+	}
+	return ciWorkflowStatuses, nil
 }
 
 func (impl *CiHandlerImpl) FetchCiStatusForTriggerView(appId int) ([]*pipelineConfig.CiWorkflowStatus, error) {
@@ -1462,14 +1626,41 @@ func (impl *CiHandlerImpl) FetchCiStatusForTriggerView(appId int) ([]*pipelineCo
 		if workflow.Id > 0 {
 			ciWorkflowStatus.CiPipelineName = workflow.CiPipeline.Name
 			ciWorkflowStatus.CiStatus = workflow.Status
+			ciWorkflowStatus.CiWorkflowId = workflow.Id
 		} else {
 			ciWorkflowStatus.CiStatus = "Not Triggered"
 		}
 		ciWorkflowStatuses = append(ciWorkflowStatuses, ciWorkflowStatus)
 	}
+	err = impl.setQueuePositions(ciWorkflowStatuses)
+	if err != nil {
+		return ciWorkflowStatuses, err
+	}
 	return ciWorkflowStatuses, nil
 }
 
+// setQueuePositions sets the build queue position of the statuses of builds waiting in queue
+func (impl *CiHandlerImpl) setQueuePositions(ciWorkflowStatuses []*pipelineConfig.CiWorkflowStatus) error {
+	var queuedCiWorkflowIds []int
+	for _, ciWorkflowStatus := range ciWorkflowStatuses {
+		if ciWorkflowStatus.CiStatus == pipelineConfig.WorkflowInQueue {
+			queuedCiWorkflowIds = append(queuedCiWorkflowIds, ciWorkflowStatus.CiWorkflowId)
+		}
+	}
+	if len(queuedCiWorkflowIds) == 0 {
+		return nil
+	}
+	queuePositions, err := impl.ciBuildQueueService.GetQueuePositions(queuedCiWorkflowIds)
+	if err != nil {
+		impl.Logger.Errorw("error in fetching build queue positions", "ciWorkflowIds", queuedCiWorkflowIds, "err", err)
+		return err
+	}
+	for _, ciWorkflowStatus := range ciWorkflowStatuses {
+		ciWorkflowStatus.QueuePosition = queuePositions[ciWorkflowStatus.CiWorkflowId]
+	}
+	return nil
+}
+
 func (impl *CiHandlerImpl) FetchMaterialInfoByArtifactId(ciArtifactId int, envId int) (*types.GitTriggerInfoResponse, error) {
 
 	ciArtifact, err := impl.ciArtifactRepository.Get(ciArtifactId)
@@ -1584,48 +1775,93 @@ func (impl *CiHandlerImpl) FetchMaterialInfoByArtifactId(ciArtifactId int, envId
 	return gitTriggerInfoResponse, nil
 }
 
-func (impl *CiHandlerImpl) WriteToCreateTestSuites(pipelineId int, buildId int, triggeredBy int) {
-	testReportFile, err := impl.DownloadCiWorkflowArtifacts(pipelineId, buildId)
-	if err != nil {
-		impl.Logger.Errorw("WriteTestSuite, error in fetching report file from s3", "err", err, "pipelineId", pipelineId, "buildId", buildId)
-		return
+// WriteToCreateTestSuites sends the test suites of a failed build and ingests them for test analytics, it returns
+// true if all of the failed tests are quarantined
+func (impl *CiHandlerImpl) WriteToCreateTestSuites(pipelineId int, buildId int, triggeredBy int) (failuresQuarantined bool) {
+	reports, found := impl.readTestReports(pipelineId, buildId)
+	if !found {
+		return false
 	}
-	if testReportFile == nil {
-		return
-	}
-	read, err := zip.OpenReader(testReportFile.Name())
-	if err != nil {
-		impl.Logger.Errorw("WriteTestSuite, error while open reader", "name", testReportFile.Name())
-		return
-	}
-	defer read.Close()
+	failuresQuarantined = impl.ingestTestReports(pipelineId, buildId, reports)
 	const CreatedBy = "created_by"
 	const TriggerId = "trigger_id"
 	const CiPipelineId = "ci_pipeline_id"
 	const XML = "xml"
 	payload := make(map[string]interface{})
-	var reports []string
 	payload[CreatedBy] = triggeredBy
 	payload[TriggerId] = buildId
 	payload[CiPipelineId] = pipelineId
 	payload[XML] = reports
-	for _, file := range read.File {
-		if payload, err = impl.listFiles(file, payload); err != nil {
-			impl.Logger.Errorw("WriteTestSuite, failed to read from zip", "file", file.Name, "error", err)
-			return
-		}
-	}
 	b, err := json.Marshal(payload)
 	if err != nil {
 		impl.Logger.Errorw("WriteTestSuite, payload marshal error", "error", err)
-		return
+		return failuresQuarantined
 	}
 	impl.Logger.Debugw("WriteTestSuite, sending to create", "TriggerId", buildId)
 	_, err = impl.eventClient.SendTestSuite(b)
 	if err != nil {
 		impl.Logger.Errorw("WriteTestSuite, error while making test suit post request", "err", err)
+	}
+	return failuresQuarantined
+}
+
+// IngestTestReports stores the test results of a succeeded build for test analytics, failed builds are ingested
+// along with writing their test suites
+func (impl *CiHandlerImpl) IngestTestReports(pipelineId int, buildId int) {
+	if !impl.testAnalyticsService.IngestsSucceededBuilds() {
 		return
 	}
+	reports, found := impl.readTestReports(pipelineId, buildId)
+	if !found {
+		return
+	}
+	impl.ingestTestReports(pipelineId, buildId, reports)
+}
+
+func (impl *CiHandlerImpl) ingestTestReports(pipelineId int, buildId int, reports []string) (failuresQuarantined bool) {
+	if len(reports) == 0 {
+		return false
+	}
+	failuresQuarantined, err := impl.testAnalyticsService.IngestTestReports(&testAnalytics.IngestTestReportsRequest{
+		CiPipelineId: pipelineId,
+		CiWorkflowId: buildId,
+		Reports:      reports,
+	})
+	if err != nil {
+		impl.Logger.Errorw("error in ingesting test reports", "pipelineId", pipelineId, "buildId", buildId, "err", err)
+		return false
+	}
+	return failuresQuarantined
+}
+
+// readTestReports returns the xml reports found in the artifacts of the build, found is false if the artifacts could
+// not be read
+func (impl *CiHandlerImpl) readTestReports(pipelineId int, buildId int) (reports []string, found bool) {
+	testReportFile, err := impl.DownloadCiWorkflowArtifacts(pipelineId, buildId)
+	if err != nil {
+		impl.Logger.Errorw("WriteTestSuite, error in fetching report file from s3", "err", err, "pipelineId", pipelineId, "buildId", buildId)
+		return nil, false
+	}
+	if testReportFile == nil {
+		return nil, false
+	}
+	defer os.Remove(testReportFile.Name())
+	defer testReportFile.Close()
+	read, err := zip.OpenReader(testReportFile.Name())
+	if err != nil {
+		impl.Logger.Errorw("WriteTestSuite, error while open reader", "name", testReportFile.Name())
+		return nil, false
+	}
+	defer read.Close()
+	payload := make(map[string]interface{})
+	for _, file := range read.File {
+		if payload, err = impl.listFiles(file, payload); err != nil {
+			impl.Logger.Errorw("WriteTestSuite, failed to read from zip", "file", file.Name, "error", err)
+			return nil, false
+		}
+	}
+	reports, _ = payload["xml"].([]string)
+	return reports, true
 }
 
 func (impl *CiHandlerImpl) listFiles(file *zip.File, payload map[string]interface{}) (map[string]interface{}, error) {
@@ -1746,6 +1982,8 @@ func (impl *CiHandlerImpl) UpdateCiWorkflowStatusFailure(timeoutForFailureCiBuil
 			} else {
 				ciWorkflow.Message = "marked failed by job"
 			}
+			failureReason, _ := bean3.ClassifyCiWorkflowFailure(bean3.CiFailureReason(ciWorkflow.FailureReason), ciWorkflow.Message)
+			ciWorkflow.FailureReason = string(failureReason)
 			err := impl.ciWorkflowRepository.UpdateWorkFlow(ciWorkflow)
 			if err != nil {
 				impl.Logger.Errorw("unable to update ci workflow, its eligible to mark failed", "err", err)
@@ -1761,6 +1999,13 @@ func (impl *CiHandlerImpl) UpdateCiWorkflowStatusFailure(timeoutForFailureCiBuil
 }
 
 func (impl *CiHandlerImpl) handlePodDeleted(ciWorkflow *pipelineConfig.CiWorkflow) {
+	handled, err := impl.ciRetryPolicyService.HandleWorkflowFailure(ciWorkflow, ciWorkflow.Status, ciWorkflow.Message)
+	if err != nil {
+		impl.Logger.Errorw("error in handling ci workflow failure by retry policy", "ciWorkflowId", ciWorkflow.Id, "err", err)
+	}
+	if handled {
+		return
+	}
 	if !impl.config.WorkflowRetriesEnabled() {
 		impl.Logger.Debug("ci workflow retry feature disabled")
 		return
@@ -1875,5 +2120,9 @@ func (impl *CiHandlerImpl) FetchCiStatusForTriggerViewForEnvironment(request res
 			ciWorkflowStatuses = append(ciWorkflowStatuses, ciWorkflowStatus)
 		}
 	}
+	err = impl.setQueuePositions(ciWorkflowStatuses)
+	if err != nil {
+		return ciWorkflowStatuses, err
+	}
 	return ciWorkflowStatuses, nil
 }
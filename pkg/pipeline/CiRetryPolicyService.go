@@ -0,0 +1,276 @@
+package pipeline
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/pkg/pipeline/executors"
+	"github.com/devtron-labs/devtron/pkg/pipeline/types"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type CiRetryPolicyService interface {
+	SavePolicy(policy *bean.CiRetryPolicy) (*bean.CiRetryPolicy, error)
+	// GetPolicy returns nil if the ci pipeline has no retry policy
+	GetPolicy(ciPipelineId int) (*bean.CiRetryPolicy, error)
+	// HandleWorkflowFailure schedules the retry of a workflow failed with the message if the retry policy of its pipeline
+	// allows it. It returns false if the pipeline has no active retry policy, the caller then falls back to the
+	// retries configured for all pipelines
+	HandleWorkflowFailure(ciWorkflow *pipelineConfig.CiWorkflow, status string, message string) (bool, error)
+	// ExecuteDueRetries triggers the scheduled retries whose backoff is over
+	ExecuteDueRetries()
+	// GetRetryAttempts returns every attempt of the build the workflow is part of, nil if it was never retried
+	GetRetryAttempts(ciWorkflow *pipelineConfig.CiWorkflow) ([]*bean.CiRetryAttempt, error)
+}
+
+type CiRetryPolicyServiceImpl struct {
+	logger                       *zap.SugaredLogger
+	ciRetryPolicyRepository      pipelineConfig.CiRetryPolicyRepository
+	ciWorkflowRepository         pipelineConfig.CiWorkflowRepository
+	ciPipelineRepository         pipelineConfig.CiPipelineRepository
+	ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository
+	ciService                    CiService
+}
+
+func NewCiRetryPolicyServiceImpl(logger *zap.SugaredLogger, ciRetryPolicyRepository pipelineConfig.CiRetryPolicyRepository,
+	ciWorkflowRepository pipelineConfig.CiWorkflowRepository, ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository, ciService CiService) *CiRetryPolicyServiceImpl {
+	return &CiRetryPolicyServiceImpl{
+		logger:                       logger,
+		ciRetryPolicyRepository:      ciRetryPolicyRepository,
+		ciWorkflowRepository:         ciWorkflowRepository,
+		ciPipelineRepository:         ciPipelineRepository,
+		ciPipelineMaterialRepository: ciPipelineMaterialRepository,
+		ciService:                    ciService,
+	}
+}
+
+func (impl *CiRetryPolicyServiceImpl) SavePolicy(policy *bean.CiRetryPolicy) (*bean.CiRetryPolicy, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: err.Error()}
+	}
+	if _, err := impl.ciPipelineRepository.FindById(policy.CiPipelineId); err != nil {
+		impl.logger.Errorw("error in finding ci pipeline", "ciPipelineId", policy.CiPipelineId, "err", err)
+		return nil, err
+	}
+	retryPolicy, err := impl.ciRetryPolicyRepository.FindPolicyByCiPipelineId(policy.CiPipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in finding ci retry policy", "ciPipelineId", policy.CiPipelineId, "err", err)
+		return nil, err
+	}
+	exists := err == nil
+	if !exists {
+		retryPolicy = &pipelineConfig.CiRetryPolicy{CiPipelineId: policy.CiPipelineId, AuditLog: sql.NewDefaultAuditLog(policy.UserId)}
+	}
+	retryPolicy.MaxAttempts = policy.MaxAttempts
+	retryPolicy.BackoffSeconds = policy.BackoffSeconds
+	retryPolicy.BackoffMultiplier = policy.BackoffMultiplier
+	retryPolicy.MaxBackoffSeconds = policy.MaxBackoffSeconds
+	retryPolicy.RetryOn = make([]string, 0, len(policy.RetryOn))
+	for _, reason := range policy.RetryOn {
+		retryPolicy.RetryOn = append(retryPolicy.RetryOn, string(reason))
+	}
+	retryPolicy.RetryOnExitCodes = policy.RetryOnExitCodes
+	retryPolicy.Active = policy.Active
+	retryPolicy.UpdatedOn = time.Now()
+	retryPolicy.UpdatedBy = policy.UserId
+	if exists {
+		err = impl.ciRetryPolicyRepository.UpdatePolicy(retryPolicy)
+	} else {
+		err = impl.ciRetryPolicyRepository.SavePolicy(retryPolicy)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving ci retry policy", "ciPipelineId", policy.CiPipelineId, "err", err)
+		return nil, err
+	}
+	return impl.getPolicyBean(retryPolicy), nil
+}
+
+func (impl *CiRetryPolicyServiceImpl) GetPolicy(ciPipelineId int) (*bean.CiRetryPolicy, error) {
+	retryPolicy, err := impl.ciRetryPolicyRepository.FindPolicyByCiPipelineId(ciPipelineId)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in finding ci retry policy", "ciPipelineId", ciPipelineId, "err", err)
+		return nil, err
+	}
+	return impl.getPolicyBean(retryPolicy), nil
+}
+
+func (impl *CiRetryPolicyServiceImpl) getPolicyBean(retryPolicy *pipelineConfig.CiRetryPolicy) *bean.CiRetryPolicy {
+	policy := &bean.CiRetryPolicy{
+		CiPipelineId:      retryPolicy.CiPipelineId,
+		MaxAttempts:       retryPolicy.MaxAttempts,
+		BackoffSeconds:    retryPolicy.BackoffSeconds,
+		BackoffMultiplier: retryPolicy.BackoffMultiplier,
+		MaxBackoffSeconds: retryPolicy.MaxBackoffSeconds,
+		RetryOnExitCodes:  retryPolicy.RetryOnExitCodes,
+		Active:            retryPolicy.Active,
+	}
+	for _, reason := range retryPolicy.RetryOn {
+		policy.RetryOn = append(policy.RetryOn, bean.CiFailureReason(reason))
+	}
+	return policy
+}
+
+func (impl *CiRetryPolicyServiceImpl) HandleWorkflowFailure(ciWorkflow *pipelineConfig.CiWorkflow, status string, message string) (bool, error) {
+	policy, err := impl.GetPolicy(ciWorkflow.CiPipelineId)
+	if err != nil {
+		return false, err
+	}
+	if policy == nil || !policy.Active {
+		return false, nil
+	}
+	if (status != string(v1alpha1.NodeError) && status != string(v1alpha1.NodeFailed)) || ciWorkflow.Status == executors.WorkflowCancel {
+		return true, nil
+	}
+	refCiWorkflowId := ciWorkflow.Id
+	if ciWorkflow.ReferenceCiWorkflowId != 0 {
+		refCiWorkflowId = ciWorkflow.ReferenceCiWorkflowId
+	}
+	retryCount, err := impl.ciWorkflowRepository.FindRetriedWorkflowCountByReferenceId(refCiWorkflowId)
+	if err != nil {
+		impl.logger.Errorw("error in finding retry count of ci workflow", "refCiWorkflowId", refCiWorkflowId, "err", err)
+		return true, err
+	}
+	reason, exitCode := bean.ClassifyCiWorkflowFailure(bean.CiFailureReason(ciWorkflow.FailureReason), message)
+	attempt := retryCount + 2
+	if attempt > policy.MaxAttempts {
+		impl.logger.Infow("maximum attempts exhausted for ci workflow", "ciWorkflowId", ciWorkflow.Id, "refCiWorkflowId", refCiWorkflowId, "maxAttempts", policy.MaxAttempts)
+		return true, nil
+	}
+	if !policy.ShouldRetry(reason, exitCode) {
+		impl.logger.Debugw("ci workflow failure not retried by policy", "ciWorkflowId", ciWorkflow.Id, "reason", reason, "exitCode", exitCode)
+		return true, nil
+	}
+	retry := &pipelineConfig.CiWorkflowRetry{
+		CiWorkflowId:    ciWorkflow.Id,
+		RefCiWorkflowId: refCiWorkflowId,
+		Attempt:         attempt,
+		FailureReason:   string(reason),
+		RetryAt:         time.Now().Add(policy.GetBackoff(attempt - 1)),
+		Status:          pipelineConfig.CiWorkflowRetryScheduled,
+		AuditLog:        sql.NewDefaultAuditLog(1),
+	}
+	saved, err := impl.ciRetryPolicyRepository.SaveRetry(retry)
+	if err != nil {
+		impl.logger.Errorw("error in saving ci workflow retry", "ciWorkflowId", ciWorkflow.Id, "err", err)
+		return true, err
+	}
+	if !saved {
+		// status of the workflow was already handled
+		return true, nil
+	}
+	impl.logger.Infow("scheduled retry of ci workflow", "ciWorkflowId", ciWorkflow.Id, "refCiWorkflowId", refCiWorkflowId, "attempt", attempt, "reason", reason, "retryAt", retry.RetryAt)
+	if !retry.RetryAt.After(time.Now()) {
+		impl.triggerRetry(retry)
+	}
+	return true, nil
+}
+
+func (impl *CiRetryPolicyServiceImpl) ExecuteDueRetries() {
+	retries, err := impl.ciRetryPolicyRepository.FindDueRetries(time.Now())
+	if err != nil {
+		impl.logger.Errorw("error in finding due ci workflow retries", "err", err)
+		return
+	}
+	for _, retry := range retries {
+		impl.triggerRetry(retry)
+	}
+}
+
+func (impl *CiRetryPolicyServiceImpl) triggerRetry(retry *pipelineConfig.CiWorkflowRetry) {
+	marked, err := impl.ciRetryPolicyRepository.MarkRetryTriggering(retry)
+	if err != nil || !marked {
+		if err != nil {
+			impl.logger.Errorw("error in marking ci workflow retry triggering", "retryId", retry.Id, "err", err)
+		}
+		return
+	}
+	triggeredCiWorkflowId, err := impl.triggerRetryWorkflow(retry)
+	retry.Status = pipelineConfig.CiWorkflowRetryTriggered
+	retry.TriggeredCiWorkflowId = triggeredCiWorkflowId
+	if err != nil {
+		retry.Status = pipelineConfig.CiWorkflowRetryFailed
+		retry.Message = err.Error()
+	}
+	retry.UpdatedOn = time.Now()
+	if err = impl.ciRetryPolicyRepository.UpdateRetry(retry); err != nil {
+		impl.logger.Errorw("error in updating ci workflow retry", "retryId", retry.Id, "err", err)
+	}
+}
+
+func (impl *CiRetryPolicyServiceImpl) triggerRetryWorkflow(retry *pipelineConfig.CiWorkflowRetry) (int, error) {
+	refCiWorkflow, err := impl.ciWorkflowRepository.FindById(retry.RefCiWorkflowId)
+	if err != nil {
+		impl.logger.Errorw("error in finding ref ci workflow", "refCiWorkflowId", retry.RefCiWorkflowId, "err", err)
+		return 0, err
+	}
+	ciPipelineMaterialIds := make([]int, 0, len(refCiWorkflow.GitTriggers))
+	for id := range refCiWorkflow.GitTriggers {
+		ciPipelineMaterialIds = append(ciPipelineMaterialIds, id)
+	}
+	ciMaterials, err := impl.ciPipelineMaterialRepository.GetByIdsIncludeDeleted(ciPipelineMaterialIds)
+	if err != nil {
+		impl.logger.Errorw("error in getting ci pipeline materials", "ciPipelineMaterialIds", ciPipelineMaterialIds, "err", err)
+		return 0, err
+	}
+	trigger := types.Trigger{}
+	trigger.BuildTriggerObject(refCiWorkflow, ciMaterials, 1, true, nil, "")
+	trigger.PathFilterResult = refCiWorkflow.PathFilterResult
+	ciWorkflowId, err := impl.ciService.TriggerCiPipeline(trigger)
+	if err != nil {
+		impl.logger.Errorw("error in re-triggering ci workflow", "refCiWorkflowId", refCiWorkflow.Id, "attempt", retry.Attempt, "err", err)
+		return 0, err
+	}
+	impl.logger.Infow("re-triggered ci workflow", "refCiWorkflowId", refCiWorkflow.Id, "attempt", retry.Attempt, "ciWorkflowId", ciWorkflowId)
+	return ciWorkflowId, nil
+}
+
+func (impl *CiRetryPolicyServiceImpl) GetRetryAttempts(ciWorkflow *pipelineConfig.CiWorkflow) ([]*bean.CiRetryAttempt, error) {
+	refCiWorkflowId := ciWorkflow.Id
+	if ciWorkflow.ReferenceCiWorkflowId != 0 {
+		refCiWorkflowId = ciWorkflow.ReferenceCiWorkflowId
+	}
+	retries, err := impl.ciRetryPolicyRepository.FindRetriesByRefCiWorkflowId(refCiWorkflowId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in finding ci workflow retries", "refCiWorkflowId", refCiWorkflowId, "err", err)
+		return nil, err
+	}
+	if len(retries) == 0 {
+		return nil, nil
+	}
+	retryByCiWorkflowId := make(map[int]*pipelineConfig.CiWorkflowRetry, len(retries))
+	for _, retry := range retries {
+		retryByCiWorkflowId[retry.CiWorkflowId] = retry
+	}
+	attempts := []*bean.CiRetryAttempt{{Attempt: 1, CiWorkflowId: refCiWorkflowId}}
+	for _, retry := range retries {
+		// retries yet to be triggered show up as the retry status of the failed attempt
+		if retry.TriggeredCiWorkflowId != 0 {
+			attempts = append(attempts, &bean.CiRetryAttempt{Attempt: retry.Attempt, CiWorkflowId: retry.TriggeredCiWorkflowId})
+		}
+	}
+	for _, attempt := range attempts {
+		attemptWorkflow, err := impl.ciWorkflowRepository.FindById(attempt.CiWorkflowId)
+		if err != nil {
+			impl.logger.Errorw("error in finding ci workflow", "ciWorkflowId", attempt.CiWorkflowId, "err", err)
+			return nil, err
+		}
+		attempt.Status = attemptWorkflow.Status
+		attempt.FailureReason = bean.CiFailureReason(attemptWorkflow.FailureReason)
+		if retry, ok := retryByCiWorkflowId[attempt.CiWorkflowId]; ok {
+			retryAt := retry.RetryAt
+			attempt.RetryStatus = retry.Status
+			attempt.RetryAt = &retryAt
+			attempt.Message = retry.Message
+		}
+	}
+	return attempts, nil
+}
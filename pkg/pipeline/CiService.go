@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -32,18 +33,24 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/helper"
 	"github.com/devtron-labs/devtron/pkg/app"
 	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/ciBuildQueue"
+	ciBuildQueueRepository "github.com/devtron-labs/devtron/pkg/ciBuildQueue/repository"
 	repository1 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/imageSigning"
 	bean2 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
 	"github.com/devtron-labs/devtron/pkg/pipeline/history"
 	"github.com/devtron-labs/devtron/pkg/pipeline/repository"
 	"github.com/devtron-labs/devtron/pkg/pipeline/types"
 	"github.com/devtron-labs/devtron/pkg/plugin"
 	repository2 "github.com/devtron-labs/devtron/pkg/plugin/repository"
+	"github.com/devtron-labs/devtron/pkg/provenance"
 	"github.com/devtron-labs/devtron/pkg/resourceQualifiers"
+	"github.com/devtron-labs/devtron/pkg/testAnalytics"
 	"github.com/devtron-labs/devtron/pkg/variables"
 	repository4 "github.com/devtron-labs/devtron/pkg/variables/repository"
 	util3 "github.com/devtron-labs/devtron/util"
 	"github.com/go-pg/pg"
+	"golang.org/x/exp/slices"
 
 	"github.com/devtron-labs/common-lib/blob-storage"
 	client "github.com/devtron-labs/devtron/client/events"
@@ -57,9 +64,22 @@ import (
 
 type CiService interface {
 	TriggerCiPipeline(trigger types.Trigger) (int, error)
+	// ReleaseQueuedBuilds submits the builds waiting in the build queue for which capacity is available
+	ReleaseQueuedBuilds()
 	GetCiMaterials(pipelineId int, ciMaterials []*pipelineConfig.CiPipelineMaterial) ([]*pipelineConfig.CiPipelineMaterial, error)
 }
 
+const (
+	sbomStepName     = "Generate SBOM"
+	sbomStepFilePath = "/devtroncd/sbom/sbom.json"
+	// sbomStepScript scans the image pushed to the registry, with the docker config the runner logged in to it with
+	sbomStepScript = `#!/bin/sh
+set -e
+mkdir -p "$(dirname "$SBOM_FILE_PATH")"
+echo -e "\n======== Generating $SBOM_FORMAT SBOM of $DEST ========"
+docker run --rm -e DOCKER_CONFIG=/docker-config -v "${DOCKER_CONFIG:-$HOME/.docker}":/docker-config:ro "$SBOM_TOOL_IMAGE" "registry:$DEST" -o "$SBOM_FORMAT" > "$SBOM_FILE_PATH"`
+)
+
 type CiServiceImpl struct {
 	Logger                        *zap.SugaredLogger
 	workflowService               WorkflowService
@@ -82,6 +102,11 @@ type CiServiceImpl struct {
 	scopedVariableManager         variables.ScopedVariableManager
 	pluginInputVariableParser     PluginInputVariableParser
 	globalPluginService           plugin.GlobalPluginService
+	ciBuildMatrixService          CiBuildMatrixService
+	ciBuildQueueService           ciBuildQueue.CiBuildQueueService
+	imageSigningService           imageSigning.ImageSigningService
+	provenanceService             provenance.ProvenanceService
+	testAnalyticsService          testAnalytics.TestAnalyticsService
 }
 
 func NewCiServiceImpl(Logger *zap.SugaredLogger, workflowService WorkflowService,
@@ -96,6 +121,11 @@ func NewCiServiceImpl(Logger *zap.SugaredLogger, workflowService WorkflowService
 	customTagService CustomTagService,
 	pluginInputVariableParser PluginInputVariableParser,
 	globalPluginService plugin.GlobalPluginService,
+	ciBuildMatrixService CiBuildMatrixService,
+	ciBuildQueueService ciBuildQueue.CiBuildQueueService,
+	imageSigningService imageSigning.ImageSigningService,
+	provenanceService provenance.ProvenanceService,
+	testAnalyticsService testAnalytics.TestAnalyticsService,
 ) *CiServiceImpl {
 	cis := &CiServiceImpl{
 		Logger:                        Logger,
@@ -117,6 +147,11 @@ func NewCiServiceImpl(Logger *zap.SugaredLogger, workflowService WorkflowService
 		customTagService:              customTagService,
 		pluginInputVariableParser:     pluginInputVariableParser,
 		globalPluginService:           globalPluginService,
+		ciBuildMatrixService:          ciBuildMatrixService,
+		ciBuildQueueService:           ciBuildQueueService,
+		imageSigningService:           imageSigningService,
+		provenanceService:             provenanceService,
+		testAnalyticsService:          testAnalyticsService,
 	}
 	config, err := types.GetCiConfig()
 	if err != nil {
@@ -140,12 +175,82 @@ func (impl *CiServiceImpl) GetCiMaterials(pipelineId int, ciMaterials []*pipelin
 	}
 }
 
+// ciTriggerData is what the workflow request of a ci trigger is built from
+type ciTriggerData struct {
+	pipeline          *pipelineConfig.CiPipeline
+	ciMaterials       []*pipelineConfig.CiPipelineMaterial
+	ciPipelineScripts []*pipelineConfig.CiPipelineScript
+	ciWorkflowConfig  *pipelineConfig.CiWorkflowConfig
+	scope             resourceQualifiers.Scope
+	env               *repository1.Environment
+	isJob             bool
+	preCiSteps        []*bean2.StepObject
+	postCiSteps       []*bean2.StepObject
+	refPluginsData    []*bean2.RefPluginObject
+	variableSnapshot  map[string]string
+}
+
 func (impl *CiServiceImpl) TriggerCiPipeline(trigger types.Trigger) (int, error) {
 	impl.Logger.Debug("ci pipeline manual trigger")
-	ciMaterials, err := impl.GetCiMaterials(trigger.PipelineId, trigger.CiMaterials)
+	triggerData, err := impl.getCiTriggerData(trigger)
 	if err != nil {
 		return 0, err
 	}
+	pipeline := triggerData.pipeline
+	savedCiWf, err := impl.saveNewWorkflow(pipeline, triggerData.ciWorkflowConfig, trigger.CommitHashes, trigger.TriggeredBy, trigger.EnvironmentId, triggerData.isJob, trigger.ReferenceCiWorkflowId, trigger.PathFilterResult)
+	if err != nil {
+		impl.Logger.Errorw("could not save new workflow", "err", err)
+		return 0, err
+	}
+
+	queuePosition, err := impl.ciBuildQueueService.QueueBuildIfQuotaReached(&ciBuildQueue.QueueBuildRequest{
+		CiWorkflowId:  savedCiWf.Id,
+		CiPipelineId:  pipeline.Id,
+		TeamId:        pipeline.App.TeamId,
+		PriorityClass: trigger.PriorityClass,
+		TriggeredBy:   trigger.TriggeredBy,
+		TriggerInputs: getQueuedBuildTriggerInputs(trigger),
+	})
+	if err != nil {
+		impl.Logger.Errorw("error in checking build quota", "ciPipelineId", pipeline.Id, "err", err)
+		return 0, err
+	}
+	if queuePosition > 0 {
+		// the workflow request is built once the build is released from the queue
+		savedCiWf.Status = pipelineConfig.WorkflowInQueue
+		savedCiWf.Message = fmt.Sprintf("waiting in build queue at position %d", queuePosition)
+		err = impl.ciWorkflowRepository.UpdateWorkFlow(savedCiWf)
+		if err != nil {
+			impl.Logger.Errorw("error in updating queued workflow", "ciWorkflowId", savedCiWf.Id, "err", err)
+			return 0, err
+		}
+		impl.Logger.Debugw("ci build queued", "pipeline", trigger.PipelineId, "queuePosition", queuePosition)
+	} else {
+		err = impl.submitCiWorkflow(trigger, triggerData, savedCiWf)
+		if err != nil {
+			return 0, err
+		}
+		impl.Logger.Debugw("ci triggered", " pipeline ", trigger.PipelineId)
+	}
+
+	var variableSnapshotHistories = util3.GetBeansPtr(
+		repository4.GetSnapshotBean(savedCiWf.Id, repository4.HistoryReferenceTypeCIWORKFLOW, triggerData.variableSnapshot))
+	if len(variableSnapshotHistories) > 0 {
+		err = impl.scopedVariableManager.SaveVariableHistoriesForTrigger(variableSnapshotHistories, trigger.TriggeredBy)
+		if err != nil {
+			impl.Logger.Errorf("Not able to save variable snapshot for CI trigger %s", err)
+		}
+	}
+
+	middleware.CiTriggerCounter.WithLabelValues(pipeline.App.AppName, pipeline.Name).Inc()
+	return savedCiWf.Id, err
+}
+
+func (impl *CiServiceImpl) getCiTriggerData(trigger types.Trigger) (*ciTriggerData, error) {
+	ciMaterials, err := impl.GetCiMaterials(trigger.PipelineId, trigger.CiMaterials)
+	if err != nil {
+		return nil, err
+	}
 	if trigger.PipelineType == bean2.CI_JOB && len(ciMaterials) != 0 {
 		ciMaterials = []*pipelineConfig.CiPipelineMaterial{ciMaterials[0]}
 		ciMaterials[0].GitMaterial = nil
@@ -153,7 +258,7 @@ func (impl *CiServiceImpl) TriggerCiPipeline(trigger types.Trigger) (int, error)
 	}
 	ciPipelineScripts, err := impl.ciPipelineRepository.FindCiScriptsByCiPipelineId(trigger.PipelineId)
 	if err != nil && !util.IsErrNoRows(err) {
-		return 0, err
+		return nil, err
 	}
 
 	var pipeline *pipelineConfig.CiPipeline
@@ -165,7 +270,7 @@ func (impl *CiServiceImpl) TriggerCiPipeline(trigger types.Trigger) (int, error)
 	ciWorkflowConfig, err := impl.ciWorkflowRepository.FindConfigByPipelineId(trigger.PipelineId)
 	if err != nil && !util.IsErrNoRows(err) {
 		impl.Logger.Errorw("could not fetch ci config", "pipeline", trigger.PipelineId)
-		return 0, err
+		return nil, err
 	}
 
 	scope := resourceQualifiers.Scope{
@@ -173,7 +278,7 @@ func (impl *CiServiceImpl) TriggerCiPipeline(trigger types.Trigger) (int, error)
 	}
 	env, isJob, err := impl.getEnvironmentForJob(pipeline, trigger)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	if isJob && env != nil {
 		ciWorkflowConfig.Namespace = env.Namespace
@@ -196,36 +301,43 @@ func (impl *CiServiceImpl) TriggerCiPipeline(trigger types.Trigger) (int, error)
 	prePostAndRefPluginResponse, err := impl.pipelineStageService.BuildPrePostAndRefPluginStepsDataForWfRequest(pipeline.Id, bean2.CiStage, scope)
 	if err != nil {
 		impl.Logger.Errorw("error in getting pre steps data for wf request", "err", err, "ciPipelineId", pipeline.Id)
-		return 0, err
+		return nil, err
 	}
-	preCiSteps := prePostAndRefPluginResponse.PreStageSteps
-	postCiSteps := prePostAndRefPluginResponse.PostStageSteps
-	refPluginsData := prePostAndRefPluginResponse.RefPluginData
-	variableSnapshot := prePostAndRefPluginResponse.VariableSnapshot
-
-	if len(preCiSteps) == 0 && isJob {
-		return 0, &util.ApiError{
+	if len(prePostAndRefPluginResponse.PreStageSteps) == 0 && isJob {
+		return nil, &util.ApiError{
 			UserMessage: "No tasks are configured in this job pipeline",
 		}
 	}
-	savedCiWf, err := impl.saveNewWorkflow(pipeline, ciWorkflowConfig, trigger.CommitHashes, trigger.TriggeredBy, trigger.EnvironmentId, isJob, trigger.ReferenceCiWorkflowId)
-	if err != nil {
-		impl.Logger.Errorw("could not save new workflow", "err", err)
-		return 0, err
-	}
+	return &ciTriggerData{
+		pipeline:          pipeline,
+		ciMaterials:       ciMaterials,
+		ciPipelineScripts: ciPipelineScripts,
+		ciWorkflowConfig:  ciWorkflowConfig,
+		scope:             scope,
+		env:               env,
+		isJob:             isJob,
+		preCiSteps:        prePostAndRefPluginResponse.PreStageSteps,
+		postCiSteps:       prePostAndRefPluginResponse.PostStageSteps,
+		refPluginsData:    prePostAndRefPluginResponse.RefPluginData,
+		variableSnapshot:  prePostAndRefPluginResponse.VariableSnapshot,
+	}, nil
+}
 
-	workflowRequest, err := impl.buildWfRequestForCiPipeline(pipeline, trigger, ciMaterials, savedCiWf, ciWorkflowConfig, ciPipelineScripts, preCiSteps, postCiSteps, refPluginsData, isJob)
+// submitCiWorkflow builds the workflow request of the saved ci workflow and submits it to the build cluster
+func (impl *CiServiceImpl) submitCiWorkflow(trigger types.Trigger, triggerData *ciTriggerData, savedCiWf *pipelineConfig.CiWorkflow) error {
+	pipeline := triggerData.pipeline
+	workflowRequest, err := impl.buildWfRequestForCiPipeline(pipeline, trigger, triggerData.ciMaterials, savedCiWf, triggerData.ciWorkflowConfig, triggerData.ciPipelineScripts, triggerData.preCiSteps, triggerData.postCiSteps, triggerData.refPluginsData, triggerData.isJob)
 	if err != nil {
 		impl.Logger.Errorw("make workflow req", "err", err)
-		return 0, err
+		return err
 	}
-	workflowRequest.Scope = scope
+	workflowRequest.Scope = triggerData.scope
 
 	if impl.config != nil && impl.config.BuildxK8sDriverOptions != "" {
 		err = impl.setBuildxK8sDriverData(workflowRequest)
 		if err != nil {
 			impl.Logger.Errorw("error in setBuildxK8sDriverData", "BUILDX_K8S_DRIVER_OPTIONS", impl.config.BuildxK8sDriverOptions, "err", err)
-			return 0, err
+			return err
 		}
 	}
 
@@ -235,35 +347,158 @@ func (impl *CiServiceImpl) TriggerCiPipeline(trigger types.Trigger) (int, error)
 
 	appLabels, err := impl.appCrudOperationService.GetLabelsByAppId(pipeline.AppId)
 	if err != nil {
-		return 0, err
+		return err
 	}
 	workflowRequest.AppId = pipeline.AppId
 	workflowRequest.AppLabels = appLabels
-	workflowRequest.Env = env
-	if isJob {
+	workflowRequest.Env = triggerData.env
+	if triggerData.isJob {
 		workflowRequest.Type = bean2.JOB_WORKFLOW_PIPELINE_TYPE
 	} else {
 		workflowRequest.Type = bean2.CI_WORKFLOW_PIPELINE_TYPE
+		err = impl.saveBuildMatrix(workflowRequest)
+		if err != nil {
+			return err
+		}
+		impl.saveBuildDefinition(workflowRequest)
 	}
 	err = impl.executeCiPipeline(workflowRequest)
 	if err != nil {
 		impl.Logger.Errorw("workflow error", "err", err)
-		return 0, err
+		return err
 	}
-	impl.Logger.Debugw("ci triggered", " pipeline ", trigger.PipelineId)
+	go impl.WriteCITriggerEvent(trigger, pipeline, workflowRequest)
+	return nil
+}
 
-	var variableSnapshotHistories = util3.GetBeansPtr(
-		repository4.GetSnapshotBean(savedCiWf.Id, repository4.HistoryReferenceTypeCIWORKFLOW, variableSnapshot))
-	if len(variableSnapshotHistories) > 0 {
-		err = impl.scopedVariableManager.SaveVariableHistoriesForTrigger(variableSnapshotHistories, trigger.TriggeredBy)
+func getQueuedBuildTriggerInputs(trigger types.Trigger) *ciBuildQueue.QueuedBuildTriggerInputs {
+	triggerInputs := &ciBuildQueue.QueuedBuildTriggerInputs{
+		InvalidateCache:           trigger.InvalidateCache,
+		ExtraEnvironmentVariables: trigger.ExtraEnvironmentVariables,
+		PipelineType:              trigger.PipelineType,
+		CiArtifactLastFetch:       trigger.CiArtifactLastFetch,
+	}
+	for _, ciMaterial := range trigger.CiMaterials {
+		triggerInputs.CiPipelineMaterialIds = append(triggerInputs.CiPipelineMaterialIds, ciMaterial.Id)
+	}
+	return triggerInputs
+}
+
+func (impl *CiServiceImpl) ReleaseQueuedBuilds() {
+	builds, err := impl.ciBuildQueueService.ReleaseBuilds()
+	if err != nil {
+		impl.Logger.Errorw("error in releasing builds from queue", "err", err)
+		return
+	}
+	for _, build := range builds {
+		err = impl.submitQueuedBuild(build)
 		if err != nil {
-			impl.Logger.Errorf("Not able to save variable snapshot for CI trigger %s", err)
+			impl.Logger.Errorw("error in submitting build released from queue", "ciWorkflowId", build.CiWorkflowId, "err", err)
 		}
 	}
+}
 
-	middleware.CiTriggerCounter.WithLabelValues(pipeline.App.AppName, pipeline.Name).Inc()
-	go impl.WriteCITriggerEvent(trigger, pipeline, workflowRequest)
-	return savedCiWf.Id, err
+// submitQueuedBuild rebuilds the trigger of a build released from the queue from its ci workflow and the kept trigger
+// inputs, and submits it
+func (impl *CiServiceImpl) submitQueuedBuild(build *ciBuildQueueRepository.CiBuildQueue) error {
+	savedCiWf, err := impl.ciWorkflowRepository.FindById(build.CiWorkflowId)
+	if err != nil {
+		impl.Logger.Errorw("error in fetching queued workflow", "ciWorkflowId", build.CiWorkflowId, "err", err)
+		return err
+	}
+	if savedCiWf.Status != pipelineConfig.WorkflowStarting {
+		impl.Logger.Infow("skipping queued build as workflow is not starting anymore", "ciWorkflowId", build.CiWorkflowId, "status", savedCiWf.Status)
+		return nil
+	}
+	trigger, err := impl.buildQueuedBuildTrigger(build, savedCiWf)
+	if err == nil {
+		var triggerData *ciTriggerData
+		triggerData, err = impl.getCiTriggerData(trigger)
+		if err == nil {
+			err = impl.submitCiWorkflow(trigger, triggerData, savedCiWf)
+		}
+	}
+	if err != nil {
+		savedCiWf.Status = pipelineConfig.WorkflowFailed
+		savedCiWf.Message = err.Error()
+		savedCiWf.FinishedOn = time.Now()
+		if updateErr := impl.ciWorkflowRepository.UpdateWorkFlow(savedCiWf); updateErr != nil {
+			impl.Logger.Errorw("error in marking workflow failed", "ciWorkflowId", build.CiWorkflowId, "err", updateErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (impl *CiServiceImpl) buildQueuedBuildTrigger(build *ciBuildQueueRepository.CiBuildQueue, savedCiWf *pipelineConfig.CiWorkflow) (types.Trigger, error) {
+	triggerInputs := &ciBuildQueue.QueuedBuildTriggerInputs{}
+	err := json.Unmarshal([]byte(build.TriggerInputs), triggerInputs)
+	if err != nil {
+		impl.Logger.Errorw("error in unmarshalling trigger inputs of queued build", "ciWorkflowId", build.CiWorkflowId, "err", err)
+		return types.Trigger{}, err
+	}
+	var ciMaterials []*pipelineConfig.CiPipelineMaterial
+	if len(triggerInputs.CiPipelineMaterialIds) > 0 {
+		ciMaterials, err = impl.ciPipelineMaterialRepository.GetByIdsIncludeDeleted(triggerInputs.CiPipelineMaterialIds)
+		if err != nil {
+			impl.Logger.Errorw("error in fetching ci pipeline materials of queued build", "ciWorkflowId", build.CiWorkflowId, "err", err)
+			return types.Trigger{}, err
+		}
+	}
+	return types.Trigger{
+		PipelineId:                savedCiWf.CiPipelineId,
+		CommitHashes:              savedCiWf.GitTriggers,
+		CiMaterials:               ciMaterials,
+		TriggeredBy:               savedCiWf.TriggeredBy,
+		InvalidateCache:           triggerInputs.InvalidateCache,
+		ExtraEnvironmentVariables: triggerInputs.ExtraEnvironmentVariables,
+		EnvironmentId:             savedCiWf.EnvironmentId,
+		PipelineType:              triggerInputs.PipelineType,
+		CiArtifactLastFetch:       triggerInputs.CiArtifactLastFetch,
+		ReferenceCiWorkflowId:     savedCiWf.ReferenceCiWorkflowId,
+		PathFilterResult:          savedCiWf.PathFilterResult,
+		PriorityClass:             build.PriorityClass,
+	}, nil
+}
+
+func (impl *CiServiceImpl) saveBuildMatrix(workflowRequest *types.WorkflowRequest) error {
+	if workflowRequest.CiBuildConfig.GetBuildMatrix() == nil {
+		return nil
+	}
+	if workflowRequest.WorkflowExecutor != pipelineConfig.WORKFLOW_EXECUTOR_TYPE_AWF {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusPreconditionFailed,
+			InternalMessage: "build matrix is supported only with argo workflow executor",
+			UserMessage:     "build matrix is supported only with argo workflow executor",
+		}
+	}
+	err := impl.ciBuildMatrixService.SaveBuildMatrix(workflowRequest)
+	if err != nil {
+		impl.Logger.Errorw("error in saving build matrix", "ciWorkflowId", workflowRequest.WorkflowId, "err", err)
+		return err
+	}
+	return nil
+}
+
+// saveBuildDefinition records what the workflow builds from for the provenance of its image, the build is not failed
+// if it cannot be recorded
+func (impl *CiServiceImpl) saveBuildDefinition(workflowRequest *types.WorkflowRequest) {
+	err := impl.provenanceService.SaveBuildDefinition(&provenance.BuildDefinitionRequest{
+		CiWorkflowId:  workflowRequest.WorkflowId,
+		CiPipelineId:  workflowRequest.PipelineId,
+		PipelineName:  workflowRequest.PipelineName,
+		AppName:       workflowRequest.AppName,
+		BuilderImage:  workflowRequest.CiImage,
+		TriggeredBy:   workflowRequest.TriggerByAuthor,
+		Materials:     workflowRequest.CiProjectDetails,
+		CiBuildConfig: workflowRequest.CiBuildConfig,
+		PreCiSteps:    workflowRequest.PreCiSteps,
+		PostCiSteps:   workflowRequest.PostCiSteps,
+		UserId:        workflowRequest.TriggeredBy,
+	})
+	if err != nil {
+		impl.Logger.Errorw("error in saving build definition for provenance", "ciWorkflowId", workflowRequest.WorkflowId, "err", err)
+	}
 }
 
 func (impl *CiServiceImpl) setBuildxK8sDriverData(workflowRequest *types.WorkflowRequest) error {
@@ -332,7 +567,8 @@ func (impl *CiServiceImpl) BuildPayload(trigger types.Trigger, pipeline *pipelin
 }
 
 func (impl *CiServiceImpl) saveNewWorkflow(pipeline *pipelineConfig.CiPipeline, wfConfig *pipelineConfig.CiWorkflowConfig,
-	commitHashes map[int]pipelineConfig.GitCommit, userId int32, EnvironmentId int, isJob bool, refCiWorkflowId int) (wf *pipelineConfig.CiWorkflow, error error) {
+	commitHashes map[int]pipelineConfig.GitCommit, userId int32, EnvironmentId int, isJob bool, refCiWorkflowId int,
+	pathFilterResult *pipelineConfig.PathFilterResult) (wf *pipelineConfig.CiWorkflow, error error) {
 
 	ciWorkflow := &pipelineConfig.CiWorkflow{
 		Name:                  pipeline.Name + "-" + strconv.Itoa(pipeline.Id),
@@ -347,6 +583,7 @@ func (impl *CiServiceImpl) saveNewWorkflow(pipeline *pipelineConfig.CiPipeline,
 		TriggeredBy:           userId,
 		ReferenceCiWorkflowId: refCiWorkflowId,
 		ExecutorType:          impl.config.GetWorkflowExecutorType(),
+		PathFilterResult:      pathFilterResult,
 	}
 	if isJob {
 		ciWorkflow.Namespace = wfConfig.Namespace
@@ -576,6 +813,26 @@ func (impl *CiServiceImpl) buildWfRequestForCiPipeline(pipeline *pipelineConfig.
 
 		savedWf.ImagePathReservationIds = append(savedWf.ImagePathReservationIds, imageReservationIds...)
 	}
+	if !isJob {
+		err = impl.addSigningSecretsToSignerStep(pipeline.Id, postCiSteps)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sbomFilePath := ""
+	if !isJob && impl.config.SbomGenerationEnabled {
+		postCiSteps = append(postCiSteps, impl.buildSbomStep(postCiSteps))
+		sbomFilePath = sbomStepFilePath
+	}
+	extraEnvironmentVariables := trigger.ExtraEnvironmentVariables
+	// the quarantined tests of the pipeline are passed to the test steps so that their failures can be ignored
+	quarantineEnvVariables, err := impl.testAnalyticsService.GetQuarantineEnvVariables(pipeline.Id)
+	if err != nil {
+		// the build is triggered without quarantine instead of failing the trigger
+		impl.Logger.Errorw("error in getting quarantined tests env variables, continuing without them", "ciPipelineId", pipeline.Id, "err", err)
+	} else {
+		extraEnvironmentVariables = mergeEnvVariables(extraEnvironmentVariables, quarantineEnvVariables)
+	}
 	//mergedArgs := string(merged)
 	oldArgs := ciTemplate.Args
 	ciBuildConfigBean, err = bean2.OverrideCiBuildConfig(dockerfilePath, oldArgs, ciLevelArgs, ciTemplate.DockerBuildOptions, ciTemplate.TargetPlatform, ciBuildConfigBean)
@@ -647,7 +904,7 @@ func (impl *CiServiceImpl) buildWfRequestForCiPipeline(pipeline *pipelineConfig.
 		IgnoreDockerCachePush:       impl.config.IgnoreDockerCacheForCI,
 		IgnoreDockerCachePull:       impl.config.IgnoreDockerCacheForCI,
 		CacheInvalidate:             trigger.InvalidateCache,
-		ExtraEnvironmentVariables:   trigger.ExtraEnvironmentVariables,
+		ExtraEnvironmentVariables:   extraEnvironmentVariables,
 		EnableBuildContext:          impl.config.EnableBuildContext,
 		OrchestratorHost:            impl.config.OrchestratorHost,
 		OrchestratorToken:           impl.config.OrchestratorToken,
@@ -659,6 +916,7 @@ func (impl *CiServiceImpl) buildWfRequestForCiPipeline(pipeline *pipelineConfig.
 		RegistryDestinationImageMap: registryDestinationImageMap,
 		RegistryCredentialMap:       registryCredentialMap,
 		PluginArtifactStage:         pluginArtifactStage,
+		SbomFilePath:                sbomFilePath,
 	}
 
 	if dockerRegistry != nil {
@@ -681,7 +939,8 @@ func (impl *CiServiceImpl) buildWfRequestForCiPipeline(pipeline *pipelineConfig.
 	if ciWorkflowConfig.LogsBucket == "" {
 		ciWorkflowConfig.LogsBucket = impl.config.GetDefaultBuildLogsBucket()
 	}
-	if len(registryDestinationImageMap) > 0 {
+	if len(registryDestinationImageMap) > 0 || len(sbomFilePath) > 0 {
+		// the sbom is generated from the image in the registry
 		workflowRequest.PushImageBeforePostCI = true
 	}
 	switch workflowRequest.CloudProvider {
@@ -746,19 +1005,19 @@ func (impl *CiServiceImpl) GetWorkflowRequestVariablesForCopyContainerImagePlugi
 	var registryCredentialMap map[string]plugin.RegistryCredentials
 	var pluginArtifactStage string
 	var imagePathReservationIds []int
-	copyContainerImagePluginId, err := impl.globalPluginService.GetRefPluginIdByRefPluginName(COPY_CONTAINER_IMAGE)
+	copyContainerImagePluginIds, err := impl.globalPluginService.GetRefPluginIdsByRefPluginName(COPY_CONTAINER_IMAGE)
 	if err != nil && err != pg.ErrNoRows {
 		impl.Logger.Errorw("error in getting copyContainerImage plugin id", "err", err)
 		return registryDestinationImageMap, registryCredentialMap, pluginArtifactStage, imagePathReservationIds, err
 	}
 	for _, step := range preCiSteps {
-		if copyContainerImagePluginId != 0 && step.RefPluginId == copyContainerImagePluginId {
+		if slices.Contains(copyContainerImagePluginIds, step.RefPluginId) {
 			// for copyContainerImage plugin parse destination images and save its data in image path reservation table
 			return nil, nil, pluginArtifactStage, nil, errors.New("copyContainerImage plugin not allowed in pre-ci step, please remove it and try again")
 		}
 	}
 	for _, step := range postCiSteps {
-		if copyContainerImagePluginId != 0 && step.RefPluginId == copyContainerImagePluginId {
+		if slices.Contains(copyContainerImagePluginIds, step.RefPluginId) {
 			// for copyContainerImage plugin parse destination images and save its data in image path reservation table
 			registryDestinationImageMap, registryCredentialMap, err = impl.pluginInputVariableParser.HandleCopyContainerImagePluginInputVariables(step.InputVars, customTag, buildImagePath, buildImagedockerRegistryId)
 			if err != nil {
@@ -783,6 +1042,72 @@ func (impl *CiServiceImpl) GetWorkflowRequestVariablesForCopyContainerImagePlugi
 	return registryDestinationImageMap, registryCredentialMap, pluginArtifactStage, imagePathReservationIds, nil
 }
 
+// addSigningSecretsToSignerStep passes the cosign key of the pipeline to its signer plugin step only, as input variables
+// of that step, so that the other steps of the build cannot read it
+func (impl *CiServiceImpl) addSigningSecretsToSignerStep(ciPipelineId int, postCiSteps []*bean2.StepObject) error {
+	signingSecrets, err := impl.imageSigningService.GetSigningSecrets(ciPipelineId)
+	if err != nil {
+		impl.Logger.Errorw("error in getting image signing secrets", "ciPipelineId", ciPipelineId, "err", err)
+		return err
+	}
+	if len(signingSecrets) == 0 {
+		return nil
+	}
+	signerPluginIds, err := impl.globalPluginService.GetRefPluginIdsByRefPluginName(COSIGN_SIGNER)
+	if err != nil && err != pg.ErrNoRows {
+		impl.Logger.Errorw("error in getting cosign signer plugin id", "err", err)
+		return err
+	}
+	if !bean2.AddStepSecrets(postCiSteps, signerPluginIds, signingSecrets) {
+		impl.Logger.Warnw("ci pipeline signs its images but has no signer plugin post build step", "ciPipelineId", ciPipelineId)
+	}
+	return nil
+}
+
+// buildSbomStep builds the post build step generating the SBOM of the pushed image, the runner sends the generated
+// SBOM to the orchestrator in the ci complete event so that one is indexed for every ci artifact
+func (impl *CiServiceImpl) buildSbomStep(postCiSteps []*bean2.StepObject) *bean2.StepObject {
+	index := 1
+	for _, step := range postCiSteps {
+		if step.Index >= index {
+			index = step.Index + 1
+		}
+	}
+	return &bean2.StepObject{
+		Name:         sbomStepName,
+		Index:        index,
+		Script:       sbomStepScript,
+		StepType:     string(repository.PIPELINE_STEP_TYPE_INLINE),
+		ExecutorType: string(repository2.SCRIPT_TYPE_SHELL),
+		InputVars: []*bean2.VariableObject{
+			{
+				Name:                  "DEST",
+				Format:                "STRING",
+				VariableType:          bean2.VARIABLE_TYPE_REF_GLOBAL,
+				ReferenceVariableName: "DEST",
+			},
+			{
+				Name:         "SBOM_FORMAT",
+				Format:       "STRING",
+				VariableType: bean2.VARIABLE_TYPE_VALUE,
+				Value:        impl.config.SbomFormat,
+			},
+			{
+				Name:         "SBOM_TOOL_IMAGE",
+				Format:       "STRING",
+				VariableType: bean2.VARIABLE_TYPE_VALUE,
+				Value:        impl.config.SbomToolImage,
+			},
+			{
+				Name:         "SBOM_FILE_PATH",
+				Format:       "STRING",
+				VariableType: bean2.VARIABLE_TYPE_VALUE,
+				Value:        sbomStepFilePath,
+			},
+		},
+	}
+}
+
 func (impl *CiServiceImpl) ReserveImagesGeneratedAtPlugin(customTagId int, registryImageMap map[string][]string) ([]int, error) {
 	var imagePathReservationIds []int
 	for _, images := range registryImageMap {
@@ -913,3 +1238,19 @@ func _getTruncatedImageTag(imageTag string) string {
 		return imageTag[:_truncatedLength]
 	}
 }
+
+// mergeEnvVariables returns the env variables with the additional ones added, the env variables are copied rather than
+// modified as they may be shared with the trigger
+func mergeEnvVariables(envVariables map[string]string, additionalEnvVariables map[string]string) map[string]string {
+	if len(additionalEnvVariables) == 0 {
+		return envVariables
+	}
+	merged := make(map[string]string, len(envVariables)+len(additionalEnvVariables))
+	for key, value := range envVariables {
+		merged[key] = value
+	}
+	for key, value := range additionalEnvVariables {
+		merged[key] = value
+	}
+	return merged
+}
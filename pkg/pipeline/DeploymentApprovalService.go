@@ -0,0 +1,307 @@
+package pipeline
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	bean2 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type DeploymentApprovalService interface {
+	PerformApprovalAction(request *bean2.DeploymentApprovalActionRequest) (*bean2.DeploymentApprovalData, error)
+	FetchApprovalData(pipelineId int, artifactId int) (*bean2.DeploymentApprovalData, error)
+	FetchApprovalDataById(approvalRequestId int) (*bean2.DeploymentApprovalData, error)
+	// ValidateApprovalForDeployment returns the approval request to be consumed by the deployment of artifact on pipeline,
+	// returns 0 when approval is not configured for the pipeline and error when enough valid approvals are not present
+	ValidateApprovalForDeployment(pipeline *pipelineConfig.Pipeline, artifactId int) (int, error)
+	MarkApprovalRequestConsumed(approvalRequestId int, userId int32) error
+}
+
+type DeploymentApprovalServiceImpl struct {
+	logger                       *zap.SugaredLogger
+	deploymentApprovalRepository pipelineConfig.DeploymentApprovalRepository
+	pipelineRepository           pipelineConfig.PipelineRepository
+	ciArtifactRepository         repository.CiArtifactRepository
+	userService                  user.UserService
+}
+
+func NewDeploymentApprovalServiceImpl(logger *zap.SugaredLogger,
+	deploymentApprovalRepository pipelineConfig.DeploymentApprovalRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	ciArtifactRepository repository.CiArtifactRepository,
+	userService user.UserService) *DeploymentApprovalServiceImpl {
+	return &DeploymentApprovalServiceImpl{
+		logger:                       logger,
+		deploymentApprovalRepository: deploymentApprovalRepository,
+		pipelineRepository:           pipelineRepository,
+		ciArtifactRepository:         ciArtifactRepository,
+		userService:                  userService,
+	}
+}
+
+func (impl *DeploymentApprovalServiceImpl) PerformApprovalAction(request *bean2.DeploymentApprovalActionRequest) (*bean2.DeploymentApprovalData, error) {
+	pipeline, err := impl.pipelineRepository.FindById(request.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching pipeline", "pipelineId", request.PipelineId, "err", err)
+		return nil, err
+	}
+	approvalConfig, err := pipeline.GetUserApprovalConfig()
+	if err != nil {
+		impl.logger.Errorw("error in parsing user approval config", "pipelineId", pipeline.Id, "err", err)
+		return nil, err
+	}
+	if approvalConfig == nil {
+		return nil, newApprovalApiError(http.StatusBadRequest, "deployment approval is not configured for this pipeline")
+	}
+	var approvalRequest *pipelineConfig.DeploymentApprovalRequest
+	if request.ActionType == bean2.APPROVAL_REQUEST_ACTION {
+		approvalRequest, err = impl.raiseApprovalRequest(request)
+	} else {
+		approvalRequest, err = impl.deploymentApprovalRepository.FindActiveByPipelineIdAndArtifactId(request.PipelineId, request.ArtifactId)
+		if err == pg.ErrNoRows {
+			return nil, newApprovalApiError(http.StatusNotFound, "no active approval request found for this artifact")
+		} else if err != nil {
+			impl.logger.Errorw("error in fetching approval request", "pipelineId", request.PipelineId, "artifactId", request.ArtifactId, "err", err)
+			return nil, err
+		}
+		switch request.ActionType {
+		case bean2.APPROVAL_APPROVE_ACTION, bean2.APPROVAL_REJECT_ACTION:
+			err = impl.saveUserResponse(approvalRequest, approvalConfig, request)
+		case bean2.APPROVAL_CANCEL_ACTION:
+			err = impl.cancelApprovalRequest(approvalRequest, request.UserId)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return impl.FetchApprovalDataById(approvalRequest.Id)
+}
+
+func (impl *DeploymentApprovalServiceImpl) raiseApprovalRequest(request *bean2.DeploymentApprovalActionRequest) (*pipelineConfig.DeploymentApprovalRequest, error) {
+	approvalRequest, err := impl.deploymentApprovalRepository.FindActiveByPipelineIdAndArtifactId(request.PipelineId, request.ArtifactId)
+	if err == nil {
+		// request already raised for this artifact
+		return approvalRequest, nil
+	} else if err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching approval request", "pipelineId", request.PipelineId, "artifactId", request.ArtifactId, "err", err)
+		return nil, err
+	}
+	approvalRequest = &pipelineConfig.DeploymentApprovalRequest{
+		PipelineId: request.PipelineId,
+		ArtifactId: request.ArtifactId,
+		Active:     true,
+		AuditLog:   sql.NewDefaultAuditLog(request.UserId),
+	}
+	err = impl.deploymentApprovalRepository.Save(approvalRequest)
+	if err != nil {
+		impl.logger.Errorw("error in saving approval request", "approvalRequest", approvalRequest, "err", err)
+		return nil, err
+	}
+	return approvalRequest, nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) saveUserResponse(approvalRequest *pipelineConfig.DeploymentApprovalRequest,
+	approvalConfig *pipelineConfig.UserApprovalConfig, request *bean2.DeploymentApprovalActionRequest) error {
+	if request.ActionType == bean2.APPROVAL_APPROVE_ACTION {
+		artifact, err := impl.ciArtifactRepository.Get(approvalRequest.ArtifactId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching artifact", "artifactId", approvalRequest.ArtifactId, "err", err)
+			return err
+		}
+		if artifact.CreatedBy == request.UserId {
+			return newApprovalApiError(http.StatusForbidden, "image builder cannot approve the deployment of own image")
+		}
+	}
+	userResponse := pipelineConfig.APPROVED
+	if request.ActionType == bean2.APPROVAL_REJECT_ACTION {
+		userResponse = pipelineConfig.REJECTED
+	}
+	userDataList, err := impl.deploymentApprovalRepository.FindUserDataByRequestIds([]int{approvalRequest.Id})
+	if err != nil {
+		impl.logger.Errorw("error in fetching approval user data", "approvalRequestId", approvalRequest.Id, "err", err)
+		return err
+	}
+	if bean2.IsApprovalRejected(userDataList) {
+		return newApprovalApiError(http.StatusBadRequest, "approval request has been rejected, no further responses are accepted")
+	}
+	now := time.Now()
+	for _, userData := range userDataList {
+		if userData.UserId != request.UserId {
+			continue
+		}
+		if !bean2.IsApprovalExpired(userData, approvalConfig.ExpiryInMinutes, now) {
+			return newApprovalApiError(http.StatusBadRequest, "user has already responded to this approval request")
+		}
+		// response expired, user can respond again
+		userData.UserResponse = userResponse
+		userData.Comments = request.Comments
+		userData.UpdatedOn = now
+		userData.UpdatedBy = request.UserId
+		err = impl.deploymentApprovalRepository.UpdateUserData(userData)
+		if err != nil {
+			impl.logger.Errorw("error in updating approval user data", "userData", userData, "err", err)
+		}
+		return err
+	}
+	userData := &pipelineConfig.DeploymentApprovalUserData{
+		ApprovalRequestId: approvalRequest.Id,
+		UserId:            request.UserId,
+		UserResponse:      userResponse,
+		Comments:          request.Comments,
+		AuditLog:          sql.NewDefaultAuditLog(request.UserId),
+	}
+	err = impl.deploymentApprovalRepository.SaveUserData(userData)
+	if err != nil {
+		impl.logger.Errorw("error in saving approval user data", "userData", userData, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) cancelApprovalRequest(approvalRequest *pipelineConfig.DeploymentApprovalRequest, userId int32) error {
+	if approvalRequest.CreatedBy != userId {
+		return newApprovalApiError(http.StatusForbidden, "only the requester can cancel the approval request")
+	}
+	userDataList, err := impl.deploymentApprovalRepository.FindUserDataByRequestIds([]int{approvalRequest.Id})
+	if err != nil {
+		impl.logger.Errorw("error in fetching approval user data", "approvalRequestId", approvalRequest.Id, "err", err)
+		return err
+	}
+	if bean2.IsApprovalRejected(userDataList) {
+		// a rejected request stays active so that a new request cannot be raised for the same artifact
+		return newApprovalApiError(http.StatusBadRequest, "rejected approval request cannot be cancelled")
+	}
+	approvalRequest.Active = false
+	approvalRequest.UpdatedOn = time.Now()
+	approvalRequest.UpdatedBy = userId
+	err = impl.deploymentApprovalRepository.Update(approvalRequest)
+	if err != nil {
+		impl.logger.Errorw("error in cancelling approval request", "approvalRequestId", approvalRequest.Id, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) FetchApprovalData(pipelineId int, artifactId int) (*bean2.DeploymentApprovalData, error) {
+	approvalRequest, err := impl.deploymentApprovalRepository.FindActiveByPipelineIdAndArtifactId(pipelineId, artifactId)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching approval request", "pipelineId", pipelineId, "artifactId", artifactId, "err", err)
+		return nil, err
+	}
+	return impl.getApprovalData(approvalRequest)
+}
+
+func (impl *DeploymentApprovalServiceImpl) FetchApprovalDataById(approvalRequestId int) (*bean2.DeploymentApprovalData, error) {
+	approvalRequest, err := impl.deploymentApprovalRepository.FindById(approvalRequestId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching approval request", "approvalRequestId", approvalRequestId, "err", err)
+		return nil, err
+	}
+	return impl.getApprovalData(approvalRequest)
+}
+
+func (impl *DeploymentApprovalServiceImpl) getApprovalData(approvalRequest *pipelineConfig.DeploymentApprovalRequest) (*bean2.DeploymentApprovalData, error) {
+	pipeline, err := impl.pipelineRepository.FindById(approvalRequest.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching pipeline", "pipelineId", approvalRequest.PipelineId, "err", err)
+		return nil, err
+	}
+	approvalConfig, err := pipeline.GetUserApprovalConfig()
+	if err != nil {
+		impl.logger.Errorw("error in parsing user approval config", "pipelineId", pipeline.Id, "err", err)
+		return nil, err
+	}
+	if approvalConfig == nil {
+		// approval was removed from pipeline after the request was raised
+		approvalConfig = &pipelineConfig.UserApprovalConfig{}
+	}
+	userDataList, err := impl.deploymentApprovalRepository.FindUserDataByRequestIds([]int{approvalRequest.Id})
+	if err != nil {
+		impl.logger.Errorw("error in fetching approval user data", "approvalRequestId", approvalRequest.Id, "err", err)
+		return nil, err
+	}
+	userIds := []int32{approvalRequest.CreatedBy}
+	for _, userData := range userDataList {
+		userIds = append(userIds, userData.UserId)
+	}
+	users, err := impl.userService.GetByIds(userIds)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching users", "userIds", userIds, "err", err)
+		return nil, err
+	}
+	userEmails := make(map[int32]string)
+	for _, userInfo := range users {
+		userEmails[userInfo.Id] = userInfo.EmailId
+	}
+	return bean2.BuildApprovalData(approvalRequest, approvalConfig, userDataList, userEmails, time.Now()), nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) ValidateApprovalForDeployment(pipeline *pipelineConfig.Pipeline, artifactId int) (int, error) {
+	approvalConfig, err := pipeline.GetUserApprovalConfig()
+	if err != nil {
+		impl.logger.Errorw("error in parsing user approval config", "pipelineId", pipeline.Id, "err", err)
+		return 0, err
+	}
+	if approvalConfig == nil {
+		return 0, nil
+	}
+	approvalRequest, err := impl.deploymentApprovalRepository.FindActiveByPipelineIdAndArtifactId(pipeline.Id, artifactId)
+	if err == pg.ErrNoRows {
+		return 0, newApprovalApiError(http.StatusForbidden,
+			fmt.Sprintf("deployment approval required: %d approval(s) needed for this image", approvalConfig.RequiredCount))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching approval request", "pipelineId", pipeline.Id, "artifactId", artifactId, "err", err)
+		return 0, err
+	}
+	userDataList, err := impl.deploymentApprovalRepository.FindUserDataByRequestIds([]int{approvalRequest.Id})
+	if err != nil {
+		impl.logger.Errorw("error in fetching approval user data", "approvalRequestId", approvalRequest.Id, "err", err)
+		return 0, err
+	}
+	if bean2.IsApprovalRejected(userDataList) {
+		return 0, newApprovalApiError(http.StatusForbidden, "deployment approval rejected for this image")
+	}
+	approvedCount := bean2.CountValidApprovals(userDataList, approvalConfig.ExpiryInMinutes, time.Now())
+	if approvedCount < approvalConfig.RequiredCount {
+		return 0, newApprovalApiError(http.StatusForbidden,
+			fmt.Sprintf("deployment approval pending: %d of %d approval(s) received for this image", approvedCount, approvalConfig.RequiredCount))
+	}
+	return approvalRequest.Id, nil
+}
+
+func (impl *DeploymentApprovalServiceImpl) MarkApprovalRequestConsumed(approvalRequestId int, userId int32) error {
+	approvalRequest, err := impl.deploymentApprovalRepository.FindById(approvalRequestId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching approval request", "approvalRequestId", approvalRequestId, "err", err)
+		return err
+	}
+	// approvals are valid for a single deployment of the artifact
+	approvalRequest.Active = false
+	approvalRequest.ArtifactDeploymentTriggered = true
+	approvalRequest.UpdatedOn = time.Now()
+	approvalRequest.UpdatedBy = userId
+	err = impl.deploymentApprovalRepository.Update(approvalRequest)
+	if err != nil {
+		impl.logger.Errorw("error in marking approval request consumed", "approvalRequestId", approvalRequestId, "err", err)
+		return err
+	}
+	return nil
+}
+
+func newApprovalApiError(httpStatusCode int, message string) *util.ApiError {
+	return &util.ApiError{
+		HttpStatusCode:  httpStatusCode,
+		InternalMessage: message,
+		UserMessage:     message,
+	}
+}
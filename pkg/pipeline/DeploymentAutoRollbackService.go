@@ -0,0 +1,169 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devtron-labs/devtron/api/bean"
+	client "github.com/devtron-labs/devtron/client/events"
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/internal/sql/repository/chartConfig"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/app/status"
+	bean2 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/util/argo"
+	util2 "github.com/devtron-labs/devtron/util/event"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type DeploymentAutoRollbackService interface {
+	// TriggerAutoRollbackIfEligible redeploys the last healthy release of the pipeline of wfr if wfr has degraded
+	// within the observation window configured for the pipeline, it is a no-op for pipelines without auto rollback enabled
+	TriggerAutoRollbackIfEligible(wfr *pipelineConfig.CdWorkflowRunner) error
+}
+
+type DeploymentAutoRollbackServiceImpl struct {
+	logger                        *zap.SugaredLogger
+	pipelineRepository            pipelineConfig.PipelineRepository
+	cdWorkflowRepository          pipelineConfig.CdWorkflowRepository
+	pipelineOverrideRepository    chartConfig.PipelineOverrideRepository
+	pipelineStatusTimelineService status.PipelineStatusTimelineService
+	workflowDagExecutor           WorkflowDagExecutor
+	argoUserService               argo.ArgoUserService
+	eventFactory                  client.EventFactory
+	eventClient                   client.EventClient
+}
+
+func NewDeploymentAutoRollbackServiceImpl(logger *zap.SugaredLogger,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	pipelineOverrideRepository chartConfig.PipelineOverrideRepository,
+	pipelineStatusTimelineService status.PipelineStatusTimelineService,
+	workflowDagExecutor WorkflowDagExecutor,
+	argoUserService argo.ArgoUserService,
+	eventFactory client.EventFactory,
+	eventClient client.EventClient) *DeploymentAutoRollbackServiceImpl {
+	return &DeploymentAutoRollbackServiceImpl{
+		logger:                        logger,
+		pipelineRepository:            pipelineRepository,
+		cdWorkflowRepository:          cdWorkflowRepository,
+		pipelineOverrideRepository:    pipelineOverrideRepository,
+		pipelineStatusTimelineService: pipelineStatusTimelineService,
+		workflowDagExecutor:           workflowDagExecutor,
+		argoUserService:               argoUserService,
+		eventFactory:                  eventFactory,
+		eventClient:                   eventClient,
+	}
+}
+
+func (impl *DeploymentAutoRollbackServiceImpl) TriggerAutoRollbackIfEligible(wfr *pipelineConfig.CdWorkflowRunner) error {
+	if wfr.CdWorkflow == nil {
+		return nil
+	}
+	pipeline, err := impl.pipelineRepository.FindById(wfr.CdWorkflow.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cd pipeline for auto rollback", "pipelineId", wfr.CdWorkflow.PipelineId, "err", err)
+		return err
+	}
+	autoRollbackConfig, err := pipeline.GetAutoRollbackConfig()
+	if err != nil {
+		impl.logger.Errorw("error in parsing auto rollback config", "pipelineId", pipeline.Id, "err", err)
+		return err
+	}
+	if !bean2.IsEligibleForAutoRollback(wfr, autoRollbackConfig, time.Now()) {
+		return nil
+	}
+	latestWfr, err := impl.cdWorkflowRepository.FindLastStatusByPipelineIdAndRunnerType(pipeline.Id, bean.CD_WORKFLOW_TYPE_DEPLOY)
+	if err != nil {
+		impl.logger.Errorw("error in fetching latest deployment runner", "pipelineId", pipeline.Id, "err", err)
+		return err
+	}
+	if latestWfr.Id != wfr.Id {
+		// a newer deployment is already in place, it supersedes the rollback
+		impl.logger.Infow("skipping auto rollback, newer deployment found for pipeline", "pipelineId", pipeline.Id, "wfrId", wfr.Id, "latestWfrId", latestWfr.Id)
+		return nil
+	}
+	// status of a degraded runner keeps being polled, the rollback is attempted only on the first poll which finds it degraded
+	isFirstAttempt, err := impl.cdWorkflowRepository.MarkAutoRollbackAttempted(wfr.Id)
+	if err != nil {
+		impl.logger.Errorw("error in marking auto rollback attempted", "wfrId", wfr.Id, "err", err)
+		return err
+	}
+	if !isFirstAttempt {
+		return nil
+	}
+	healthyWfr, err := impl.cdWorkflowRepository.FindLastSucceededDeploymentRunnerBefore(pipeline.Id, wfr.Id)
+	if err == pg.ErrNoRows {
+		impl.saveAutoRollbackTimeline(wfr.Id, pipelineConfig.TIMELINE_STATUS_AUTO_ROLLBACK_FAILED, "Auto rollback skipped: no healthy deployment found to roll back to.")
+		return nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching last healthy deployment runner", "pipelineId", pipeline.Id, "wfrId", wfr.Id, "err", err)
+		return err
+	}
+	pipelineOverride, err := impl.pipelineOverrideRepository.FindLatestByCdWorkflowId(healthyWfr.CdWorkflowId)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in fetching pipeline override of healthy deployment", "cdWorkflowId", healthyWfr.CdWorkflowId, "err", err)
+		return err
+	}
+	if pipelineOverride == nil || pipelineOverride.Id == 0 {
+		impl.saveAutoRollbackTimeline(wfr.Id, pipelineConfig.TIMELINE_STATUS_AUTO_ROLLBACK_FAILED, "Auto rollback skipped: release of the last healthy deployment not found.")
+		return nil
+	}
+	ctx := context.Background()
+	if util.IsAcdApp(pipeline.DeploymentAppType) {
+		acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+		if err != nil {
+			impl.logger.Errorw("error in getting acd token", "err", err)
+			return err
+		}
+		ctx = context.WithValue(ctx, "token", acdToken)
+	}
+	overrideRequest := &bean.ValuesOverrideRequest{
+		PipelineId:                            pipeline.Id,
+		AppId:                                 pipeline.AppId,
+		CiArtifactId:                          pipelineOverride.CiArtifactId,
+		CdWorkflowType:                        bean.CD_WORKFLOW_TYPE_DEPLOY,
+		DeploymentType:                        models.DEPLOYMENTTYPE_ROLLBACK,
+		DeploymentWithConfig:                  bean.DEPLOYMENT_CONFIG_TYPE_SPECIFIC_TRIGGER,
+		WfrIdForDeploymentWithSpecificTrigger: healthyWfr.Id,
+		UserId:                                DEVTRON_SYSTEM_USER_ID,
+		IsAutoRollback:                        true,
+	}
+	reason := bean2.GetAutoRollbackReason(wfr)
+	impl.logger.Infow("triggering auto rollback for degraded deployment", "pipelineId", pipeline.Id, "wfrId", wfr.Id, "rollbackToWfrId", healthyWfr.Id, "reason", reason)
+	_, err = impl.workflowDagExecutor.ManualCdTrigger(overrideRequest, ctx)
+	if err != nil {
+		impl.logger.Errorw("error in triggering auto rollback", "pipelineId", pipeline.Id, "wfrId", wfr.Id, "err", err)
+		impl.saveAutoRollbackTimeline(wfr.Id, pipelineConfig.TIMELINE_STATUS_AUTO_ROLLBACK_FAILED, fmt.Sprintf("Auto rollback failed: %s", util.GetGRPCErrorDetailedMessage(err)))
+		return err
+	}
+	impl.saveAutoRollbackTimeline(wfr.Id, pipelineConfig.TIMELINE_STATUS_AUTO_ROLLBACK_TRIGGERED, fmt.Sprintf("Deployment %s, rolled back automatically to the release of deployment %d.", reason, healthyWfr.Id))
+	go impl.writeAutoRollbackEvent(pipeline, overrideRequest.WfrId, reason)
+	return nil
+}
+
+func (impl *DeploymentAutoRollbackServiceImpl) saveAutoRollbackTimeline(wfrId int, timelineStatus pipelineConfig.TimelineStatus, statusDetail string) {
+	timeline := impl.pipelineStatusTimelineService.GetTimelineDbObjectByTimelineStatusAndTimelineDescription(wfrId, 0, timelineStatus, statusDetail, DEVTRON_SYSTEM_USER_ID, time.Now())
+	err := impl.pipelineStatusTimelineService.SaveTimeline(timeline, nil, false)
+	if err != nil {
+		impl.logger.Errorw("error in saving auto rollback timeline", "wfrId", wfrId, "timeline", timeline, "err", err)
+	}
+}
+
+func (impl *DeploymentAutoRollbackServiceImpl) writeAutoRollbackEvent(pipeline *pipelineConfig.Pipeline, rollbackWfrId int, reason string) {
+	rollbackWfr, err := impl.cdWorkflowRepository.FindWorkflowRunnerById(rollbackWfrId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching auto rollback runner", "wfrId", rollbackWfrId, "err", err)
+		return
+	}
+	event := impl.eventFactory.Build(util2.AutoRollback, &pipeline.Id, pipeline.AppId, &pipeline.EnvironmentId, util2.CD)
+	event.Payload = &client.Payload{FailureReason: reason}
+	event = impl.eventFactory.BuildExtraCDData(event, rollbackWfr, 0, bean.CD_WORKFLOW_TYPE_DEPLOY)
+	_, evtErr := impl.eventClient.WriteNotificationEvent(event)
+	if evtErr != nil {
+		impl.logger.Errorw("error in writing auto rollback event", "event", event, "err", evtErr)
+	}
+}
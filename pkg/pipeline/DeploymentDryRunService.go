@@ -0,0 +1,172 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	util5 "github.com/devtron-labs/common-lib/utils/k8s"
+	yamlUtil "github.com/devtron-labs/common-lib/utils/yaml"
+	"github.com/devtron-labs/devtron/api/bean"
+	client2 "github.com/devtron-labs/devtron/api/helm-app"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/k8s"
+	bean3 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"go.uber.org/zap"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type DeploymentDryRunService interface {
+	// DryRunCdTrigger renders the release which a deploy trigger of overrideRequest would apply and diffs every rendered
+	// resource against its live object in the cluster, nothing is saved or pushed to GitOps. Secret data is masked
+	// unless the user is super admin
+	DryRunCdTrigger(overrideRequest *bean.ValuesOverrideRequest, isSuperAdmin bool, ctx context.Context) (*bean3.DeploymentDryRunResponse, error)
+}
+
+type DeploymentDryRunServiceImpl struct {
+	logger               *zap.SugaredLogger
+	workflowDagExecutor  WorkflowDagExecutor
+	chartTemplateService util.ChartTemplateService
+	helmAppService       client2.HelmAppService
+	helmAppClient        client2.HelmAppClient
+	k8sCommonService     k8s.K8sCommonService
+}
+
+func NewDeploymentDryRunServiceImpl(logger *zap.SugaredLogger,
+	workflowDagExecutor WorkflowDagExecutor,
+	chartTemplateService util.ChartTemplateService,
+	helmAppService client2.HelmAppService,
+	helmAppClient client2.HelmAppClient,
+	k8sCommonService k8s.K8sCommonService) *DeploymentDryRunServiceImpl {
+	return &DeploymentDryRunServiceImpl{
+		logger:               logger,
+		workflowDagExecutor:  workflowDagExecutor,
+		chartTemplateService: chartTemplateService,
+		helmAppService:       helmAppService,
+		helmAppClient:        helmAppClient,
+		k8sCommonService:     k8sCommonService,
+	}
+}
+
+func (impl *DeploymentDryRunServiceImpl) DryRunCdTrigger(overrideRequest *bean.ValuesOverrideRequest, isSuperAdmin bool, ctx context.Context) (*bean3.DeploymentDryRunResponse, error) {
+	valuesOverrideResponse, builtChartPath, err := impl.workflowDagExecutor.BuildManifestForDryRun(overrideRequest, ctx)
+	if err != nil {
+		impl.logger.Errorw("error in building manifest for dry run", "pipelineId", overrideRequest.PipelineId, "ciArtifactId", overrideRequest.CiArtifactId, "err", err)
+		return nil, err
+	}
+	defer impl.chartTemplateService.CleanDir(builtChartPath)
+	envOverride := valuesOverrideResponse.EnvOverride
+	clusterId := envOverride.Environment.ClusterId
+	releaseName := valuesOverrideResponse.Pipeline.DeploymentAppName
+	manifest, err := impl.templateChart(ctx, builtChartPath, valuesOverrideResponse.MergedValues, clusterId, releaseName, envOverride.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	renderedObjects, err := yamlUtil.SplitYAMLs([]byte(manifest))
+	if err != nil {
+		impl.logger.Errorw("error in splitting rendered manifest", "pipelineId", overrideRequest.PipelineId, "err", err)
+		return nil, err
+	}
+	resources, err := impl.diffWithLiveObjects(ctx, clusterId, envOverride.Namespace, renderedObjects, isSuperAdmin)
+	if err != nil {
+		return nil, err
+	}
+	return &bean3.DeploymentDryRunResponse{
+		AppId:        overrideRequest.AppId,
+		PipelineId:   overrideRequest.PipelineId,
+		CiArtifactId: overrideRequest.CiArtifactId,
+		Image:        valuesOverrideResponse.Artifact.Image,
+		ReleaseName:  releaseName,
+		Namespace:    envOverride.Namespace,
+		Resources:    resources,
+	}, nil
+}
+
+func (impl *DeploymentDryRunServiceImpl) templateChart(ctx context.Context, builtChartPath string, valuesYaml string, clusterId int, releaseName string, namespace string) (string, error) {
+	chart, err := impl.chartTemplateService.LoadChartFromDir(builtChartPath)
+	if err != nil {
+		impl.logger.Errorw("error in LoadChartFromDir", "builtChartPath", builtChartPath, "err", err)
+		return "", err
+	}
+	outputChartPathDir := fmt.Sprintf("%s-%v", builtChartPath, strconv.FormatInt(time.Now().UnixNano(), 16))
+	err = os.Mkdir(outputChartPathDir, 0755)
+	if err != nil {
+		impl.logger.Errorw("error in creating temp outputChartPathDir", "outputChartPathDir", outputChartPathDir, "err", err)
+		return "", err
+	}
+	defer impl.chartTemplateService.CleanDir(outputChartPathDir)
+	chartBytes, err := impl.chartTemplateService.CreateZipFileForChart(chart, outputChartPathDir)
+	if err != nil {
+		impl.logger.Errorw("error in CreateZipFileForChart", "builtChartPath", builtChartPath, "err", err)
+		return "", err
+	}
+	clusterConfig, err := impl.helmAppService.GetClusterConf(clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cluster detail", "clusterId", clusterId, "err", err)
+		return "", err
+	}
+	k8sServerVersion, err := impl.k8sCommonService.GetK8sServerVersion(clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting k8s server version", "clusterId", clusterId, "err", err)
+		return "", err
+	}
+	installReleaseRequest := &client2.InstallReleaseRequest{
+		ChartName:    chart.Metadata.Name,
+		ChartVersion: chart.Metadata.Version,
+		ValuesYaml:   valuesYaml,
+		K8SVersion:   k8sServerVersion.String(),
+		ReleaseIdentifier: &client2.ReleaseIdentifier{
+			ReleaseName:      releaseName,
+			ReleaseNamespace: namespace,
+			ClusterConfig:    clusterConfig,
+		},
+		ChartContent: &client2.ChartContent{
+			Content: chartBytes,
+		},
+	}
+	templateChartResponse, err := impl.helmAppClient.TemplateChart(ctx, installReleaseRequest)
+	if err != nil {
+		impl.logger.Errorw("error in templating chart for dry run", "releaseName", releaseName, "err", err)
+		return "", err
+	}
+	return templateChartResponse.GeneratedManifest, nil
+}
+
+func (impl *DeploymentDryRunServiceImpl) diffWithLiveObjects(ctx context.Context, clusterId int, namespace string, renderedObjects []unstructured.Unstructured, isSuperAdmin bool) ([]*bean3.DryRunResourceDiff, error) {
+	requests := make([]k8s.ResourceRequestBean, 0, len(renderedObjects))
+	for i := range renderedObjects {
+		if len(renderedObjects[i].GetNamespace()) == 0 {
+			renderedObjects[i].SetNamespace(namespace)
+		}
+		requests = append(requests, k8s.ResourceRequestBean{
+			ClusterId: clusterId,
+			K8sRequest: &util5.K8sRequestBean{
+				ResourceIdentifier: util5.ResourceIdentifier{
+					Name:             renderedObjects[i].GetName(),
+					Namespace:        renderedObjects[i].GetNamespace(),
+					GroupVersionKind: renderedObjects[i].GroupVersionKind(),
+				},
+			},
+		})
+	}
+	liveObjects, err := impl.k8sCommonService.GetManifestsByBatch(ctx, requests)
+	if err != nil {
+		impl.logger.Errorw("error in fetching live manifests for dry run", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	resources := make([]*bean3.DryRunResourceDiff, 0, len(renderedObjects))
+	for i, rendered := range renderedObjects {
+		var live map[string]interface{}
+		var liveErr error
+		if liveObjects[i].Err == nil && liveObjects[i].ManifestResponse != nil {
+			live = liveObjects[i].ManifestResponse.Manifest.Object
+		} else if liveObjects[i].Err != nil && !k8sErrors.IsNotFound(liveObjects[i].Err) {
+			liveErr = liveObjects[i].Err
+		}
+		resources = append(resources, bean3.BuildDryRunResourceDiff(rendered, live, liveErr, isSuperAdmin))
+	}
+	return resources, nil
+}
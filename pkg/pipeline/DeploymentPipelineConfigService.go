@@ -279,6 +279,16 @@ func (impl *CdPipelineConfigServiceImpl) GetCdPipelineById(pipelineId int) (cdPi
 			return nil, err
 		}
 	}
+	userApprovalConf, err := dbPipeline.GetUserApprovalConfig()
+	if err != nil {
+		impl.logger.Errorw("error in parsing user approval config", "pipelineId", pipelineId, "err", err)
+		return nil, err
+	}
+	autoRollbackConf, err := dbPipeline.GetAutoRollbackConfig()
+	if err != nil {
+		impl.logger.Errorw("error in parsing auto rollback config", "pipelineId", pipelineId, "err", err)
+		return nil, err
+	}
 	appWorkflowMapping, err := impl.appWorkflowRepository.FindWFCDMappingByCDPipelineId(pipelineId)
 	if err != nil {
 		return nil, err
@@ -338,6 +348,8 @@ func (impl *CdPipelineConfigServiceImpl) GetCdPipelineById(pipelineId int) (cdPi
 		CustomTagStage:                &customTagStage,
 		EnableCustomTag:               customTagEnabled,
 		AppId:                         dbPipeline.AppId,
+		UserApprovalConf:              userApprovalConf,
+		AutoRollbackConf:              autoRollbackConf,
 	}
 	var preDeployStage *bean3.PipelineStageDto
 	var postDeployStage *bean3.PipelineStageDto
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
 	"github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	"github.com/devtron-labs/devtron/pkg/plugin"
 	repository2 "github.com/devtron-labs/devtron/pkg/plugin/repository"
 	"github.com/devtron-labs/devtron/pkg/resourceQualifiers"
 	"github.com/devtron-labs/devtron/pkg/sql"
@@ -13,6 +15,8 @@ import (
 	repository3 "github.com/devtron-labs/devtron/pkg/variables/repository"
 	"github.com/go-pg/pg"
 	"go.uber.org/zap"
+	"net/http"
+	"strings"
 	"time"
 )
 
@@ -22,6 +26,10 @@ type PipelineStageService interface {
 	UpdatePipelineStage(stageReq *bean.PipelineStageDto, stageType repository.PipelineStageType, pipelineId int, userId int32) error
 	DeletePipelineStage(stageReq *bean.PipelineStageDto, userId int32, tx *pg.Tx) error
 	BuildPrePostAndRefPluginStepsDataForWfRequest(pipelineId int, stageType string, scope resourceQualifiers.Scope) (*bean.PrePostAndRefPluginStepsResponse, error)
+	// BuildStageStepsDataForWfRequest returns the steps of one pre/post stage of the ci or cd pipeline, with their scoped
+	// variables left unresolved, and the plugins they refer to
+	BuildStageStepsDataForWfRequest(pipelineId int, stageType repository.PipelineStageType) ([]*bean.StepObject, []*bean.RefPluginObject, error)
+	BuildRefPluginStepDataForWfRequest(refPluginIds []int) ([]*bean.RefPluginObject, error)
 	GetCiPipelineStageDataDeepCopy(ciPipelineId int) (preCiStage *bean.PipelineStageDto, postCiStage *bean.PipelineStageDto, err error)
 	GetCdPipelineStageDataDeepCopy(cdPipelineId int) (*bean.PipelineStageDto, *bean.PipelineStageDto, error)
 	GetCdPipelineStageDataDeepCopyForPipelineIds(cdPipelineIds []int) (map[int][]*bean.PipelineStageDto, error)
@@ -210,6 +218,7 @@ func (impl *PipelineStageServiceImpl) BuildPipelineStageDataDeepCopy(pipelineSta
 			OutputDirectoryPath:      step.OutputDirectoryPath,
 			StepType:                 step.StepType,
 			TriggerIfParentStageFail: step.TriggerIfParentStageFail,
+			DependsOn:                step.DependsOnStepIndexes,
 		}
 		if step.StepType == repository.PIPELINE_STEP_TYPE_INLINE {
 			inlineStepDetail, err := impl.BuildInlineStepDataDeepCopy(step)
@@ -298,7 +307,8 @@ func (impl *PipelineStageServiceImpl) BuildInlineStepDataDeepCopy(step *reposito
 
 func (impl *PipelineStageServiceImpl) BuildRefPluginStepDataDeepCopy(step *repository.PipelineStageStep) (*bean.RefPluginStepDetailDto, error) {
 	refPluginStepDetail := &bean.RefPluginStepDetailDto{
-		PluginId: step.RefPluginId,
+		PluginId:      step.RefPluginId,
+		PluginVersion: step.RefPluginVersionConstraint,
 	}
 	inputVariablesDto, outputVariablesDto, conditionsDto, err := impl.BuildVariableAndConditionDataByStepIdDeepCopy(step.Id)
 	if err != nil {
@@ -418,6 +428,7 @@ func (impl *PipelineStageServiceImpl) BuildCiStageData(ciStage *repository.Pipel
 			OutputDirectoryPath:      step.OutputDirectoryPath,
 			StepType:                 step.StepType,
 			TriggerIfParentStageFail: step.TriggerIfParentStageFail,
+			DependsOn:                step.DependsOnStepIndexes,
 		}
 		if step.StepType == repository.PIPELINE_STEP_TYPE_INLINE {
 			inlineStepDetail, err := impl.BuildInlineStepData(step)
@@ -506,7 +517,8 @@ func (impl *PipelineStageServiceImpl) BuildInlineStepData(step *repository.Pipel
 
 func (impl *PipelineStageServiceImpl) BuildRefPluginStepData(step *repository.PipelineStageStep) (*bean.RefPluginStepDetailDto, error) {
 	refPluginStepDetail := &bean.RefPluginStepDetailDto{
-		PluginId: step.RefPluginId,
+		PluginId:      step.RefPluginId,
+		PluginVersion: step.RefPluginVersionConstraint,
 	}
 	inputVariablesDto, outputVariablesDto, conditionsDto, err := impl.BuildVariableAndConditionDataByStepId(step.Id)
 	if err != nil {
@@ -578,6 +590,10 @@ func (impl *PipelineStageServiceImpl) BuildVariableAndConditionDataByStepId(step
 
 // CreatePipelineStage and related methods starts
 func (impl *PipelineStageServiceImpl) CreatePipelineStage(stageReq *bean.PipelineStageDto, stageType repository.PipelineStageType, pipelineId int, userId int32) error {
+	err := impl.validatePipelineStage(stageReq, stageType)
+	if err != nil {
+		return err
+	}
 	dbConnection := impl.pipelineRepository.GetConnection()
 	tx, err := dbConnection.Begin()
 	if err != nil {
@@ -615,7 +631,7 @@ func (impl *PipelineStageServiceImpl) CreatePipelineStage(stageReq *bean.Pipelin
 		indexNameString[step.Index] = step.Name
 	}
 	//creating stage steps and all related data
-	err = impl.CreateStageSteps(stageReq.Steps, stage.Id, userId, indexNameString, tx)
+	err = impl.CreateStageSteps(stageReq.Steps, stage.Id, userId, indexNameString, hasStepDependencies(stageReq.Steps), tx)
 	if err != nil {
 		impl.logger.Errorw("error in creating stage steps for ci stage", "err", err, "stageId", stage.Id)
 		return err
@@ -634,17 +650,10 @@ func (impl *PipelineStageServiceImpl) CreatePipelineStage(stageReq *bean.Pipelin
 	return nil
 }
 
-func (impl *PipelineStageServiceImpl) CreateStageSteps(steps []*bean.PipelineStageStepDto, stageId int, userId int32, indexNameString map[int]string, tx *pg.Tx) error {
+func (impl *PipelineStageServiceImpl) CreateStageSteps(steps []*bean.PipelineStageStepDto, stageId int, userId int32, indexNameString map[int]string, isDag bool, tx *pg.Tx) error {
 	for _, step := range steps {
 		//setting dependentStep detail
-		var dependentOnStep string
-		if step.Index > 1 {
-			//since starting index is independent of any step we will be setting dependent detail for further indexes
-			name, ok := indexNameString[step.Index-1]
-			if ok {
-				dependentOnStep = name
-			}
-		}
+		dependentOnStep := getDependentOnStep(step, isDag, indexNameString)
 		var stepId int
 		var inputVariables []*bean.StepVariableDto
 		var outputVariables []*bean.StepVariableDto
@@ -658,15 +667,16 @@ func (impl *PipelineStageServiceImpl) CreateStageSteps(steps []*bean.PipelineSta
 				return err
 			}
 			inlineStep := &repository.PipelineStageStep{
-				PipelineStageId:     stageId,
-				Name:                step.Name,
-				Description:         step.Description,
-				Index:               step.Index,
-				StepType:            step.StepType,
-				ScriptId:            scriptEntryId,
-				OutputDirectoryPath: step.OutputDirectoryPath,
-				DependentOnStep:     dependentOnStep,
-				Deleted:             false,
+				PipelineStageId:      stageId,
+				Name:                 step.Name,
+				Description:          step.Description,
+				Index:                step.Index,
+				StepType:             step.StepType,
+				ScriptId:             scriptEntryId,
+				OutputDirectoryPath:  step.OutputDirectoryPath,
+				DependentOnStep:      dependentOnStep,
+				DependsOnStepIndexes: step.DependsOn,
+				Deleted:              false,
 				AuditLog: sql.AuditLog{
 					CreatedOn: time.Now(),
 					CreatedBy: userId,
@@ -686,16 +696,23 @@ func (impl *PipelineStageServiceImpl) CreateStageSteps(steps []*bean.PipelineSta
 			conditionDetails = inlineStepDetail.ConditionDetails
 		} else if step.StepType == repository.PIPELINE_STEP_TYPE_REF_PLUGIN {
 			refPluginStepDetail := step.RefPluginStepDetail
+			refPluginId, err := impl.resolveRefPluginId(refPluginStepDetail.PluginId, refPluginStepDetail.PluginVersion)
+			if err != nil {
+				impl.logger.Errorw("error in resolving plugin version of step", "err", err, "pluginId", refPluginStepDetail.PluginId, "pluginVersion", refPluginStepDetail.PluginVersion)
+				return err
+			}
 			refPluginStep := &repository.PipelineStageStep{
-				PipelineStageId:     stageId,
-				Name:                step.Name,
-				Description:         step.Description,
-				Index:               step.Index,
-				StepType:            step.StepType,
-				RefPluginId:         refPluginStepDetail.PluginId,
-				OutputDirectoryPath: step.OutputDirectoryPath,
-				DependentOnStep:     dependentOnStep,
-				Deleted:             false,
+				PipelineStageId:            stageId,
+				Name:                       step.Name,
+				Description:                step.Description,
+				Index:                      step.Index,
+				StepType:                   step.StepType,
+				RefPluginId:                refPluginId,
+				RefPluginVersionConstraint: refPluginStepDetail.PluginVersion,
+				OutputDirectoryPath:        step.OutputDirectoryPath,
+				DependentOnStep:            dependentOnStep,
+				DependsOnStepIndexes:       step.DependsOn,
+				Deleted:                    false,
 				AuditLog: sql.AuditLog{
 					CreatedOn: time.Now(),
 					CreatedBy: userId,
@@ -704,7 +721,7 @@ func (impl *PipelineStageServiceImpl) CreateStageSteps(steps []*bean.PipelineSta
 				},
 				TriggerIfParentStageFail: step.TriggerIfParentStageFail,
 			}
-			refPluginStep, err := impl.pipelineStageRepository.CreatePipelineStageStep(refPluginStep, tx)
+			refPluginStep, err = impl.pipelineStageRepository.CreatePipelineStageStep(refPluginStep, tx)
 			if err != nil {
 				impl.logger.Errorw("error in creating ref plugin step", "err", err, "step", refPluginStep)
 				return err
@@ -965,6 +982,10 @@ func (impl *PipelineStageServiceImpl) UpdatePipelineStage(stageReq *bean.Pipelin
 		}
 	} else {
 		//stageId found, to handle as an update request
+		err = impl.validatePipelineStage(stageReq, stageType)
+		if err != nil {
+			return err
+		}
 		stageReq.Id = stageOld.Id
 		stageUpdateReq := stageOld
 		stageUpdateReq.Name = stageReq.Name
@@ -1097,7 +1118,7 @@ func (impl *PipelineStageServiceImpl) FilterAndActOnStepsInPipelineStageUpdateRe
 			}
 		}
 		//creating new steps
-		err = impl.CreateStageSteps(stepsToBeCreated, stageReq.Id, userId, indexNameString, nil)
+		err = impl.CreateStageSteps(stepsToBeCreated, stageReq.Id, userId, indexNameString, hasStepDependencies(stageReq.Steps), nil)
 		if err != nil {
 			impl.logger.Errorw("error in creating stage steps for ci stage", "err", err, "stageId", stageReq.Id)
 			return err
@@ -1105,7 +1126,7 @@ func (impl *PipelineStageServiceImpl) FilterAndActOnStepsInPipelineStageUpdateRe
 	}
 	if len(stepsToBeUpdated) > 0 {
 		//updating steps
-		err = impl.UpdateStageSteps(stepsToBeUpdated, userId, stageReq.Id, indexNameString)
+		err = impl.UpdateStageSteps(stepsToBeUpdated, userId, stageReq.Id, indexNameString, hasStepDependencies(stageReq.Steps))
 		if err != nil {
 			impl.logger.Errorw("error in updating stage steps for ci stage", "err", err)
 			return err
@@ -1114,17 +1135,10 @@ func (impl *PipelineStageServiceImpl) FilterAndActOnStepsInPipelineStageUpdateRe
 	return nil
 }
 
-func (impl *PipelineStageServiceImpl) UpdateStageSteps(steps []*bean.PipelineStageStepDto, userId int32, stageId int, indexNameString map[int]string) error {
+func (impl *PipelineStageServiceImpl) UpdateStageSteps(steps []*bean.PipelineStageStepDto, userId int32, stageId int, indexNameString map[int]string, isDag bool) error {
 	for _, step := range steps {
 		//setting dependentStep detail
-		var dependentOnStep string
-		if step.Index > 1 {
-			//since starting index is independent of any step we will be setting dependent detail for further indexes
-			name, ok := indexNameString[step.Index-1]
-			if ok {
-				dependentOnStep = name
-			}
-		}
+		dependentOnStep := getDependentOnStep(step, isDag, indexNameString)
 		//getting saved step from db
 		savedStep, err := impl.pipelineStageRepository.GetStepById(step.Id)
 		if err != nil {
@@ -1132,15 +1146,16 @@ func (impl *PipelineStageServiceImpl) UpdateStageSteps(steps []*bean.PipelineSta
 			return err
 		}
 		stepUpdateReq := &repository.PipelineStageStep{
-			Id:                  step.Id,
-			PipelineStageId:     stageId,
-			Name:                step.Name,
-			Description:         step.Description,
-			Index:               step.Index,
-			StepType:            step.StepType,
-			OutputDirectoryPath: step.OutputDirectoryPath,
-			DependentOnStep:     dependentOnStep,
-			Deleted:             false,
+			Id:                   step.Id,
+			PipelineStageId:      stageId,
+			Name:                 step.Name,
+			Description:          step.Description,
+			Index:                step.Index,
+			StepType:             step.StepType,
+			OutputDirectoryPath:  step.OutputDirectoryPath,
+			DependentOnStep:      dependentOnStep,
+			DependsOnStepIndexes: step.DependsOn,
+			Deleted:              false,
 			AuditLog: sql.AuditLog{
 				CreatedOn: savedStep.CreatedOn,
 				CreatedBy: savedStep.CreatedBy,
@@ -1172,7 +1187,12 @@ func (impl *PipelineStageServiceImpl) UpdateStageSteps(steps []*bean.PipelineSta
 				}
 			}
 			//updating ref plugin id in step update req
-			stepUpdateReq.RefPluginId = step.RefPluginStepDetail.PluginId
+			stepUpdateReq.RefPluginId, err = impl.resolveRefPluginId(step.RefPluginStepDetail.PluginId, step.RefPluginStepDetail.PluginVersion)
+			if err != nil {
+				impl.logger.Errorw("error in resolving plugin version of step", "err", err, "pluginId", step.RefPluginStepDetail.PluginId, "pluginVersion", step.RefPluginStepDetail.PluginVersion)
+				return err
+			}
+			stepUpdateReq.RefPluginVersionConstraint = step.RefPluginStepDetail.PluginVersion
 			inputVariables = step.RefPluginStepDetail.InputVariables
 			outputVariables = step.RefPluginStepDetail.OutputVariables
 			conditionDetails = step.RefPluginStepDetail.ConditionDetails
@@ -1625,6 +1645,27 @@ func (impl *PipelineStageServiceImpl) DeletePipelineStage(stageReq *bean.Pipelin
 
 // BuildPrePostAndRefPluginStepsDataForWfRequest and related methods starts
 func (impl *PipelineStageServiceImpl) BuildPrePostAndRefPluginStepsDataForWfRequest(pipelineId int, stageType string, scope resourceQualifiers.Scope) (*bean.PrePostAndRefPluginStepsResponse, error) {
+	unresolvedResponse, pipelineStageIds, err := impl.buildUnresolvedPrePostAndRefPluginStepsData(pipelineId, stageType)
+	if err != nil {
+		return nil, err
+	}
+	resolvedResponse, err := impl.fetchScopedVariablesAndResolveTemplate(unresolvedResponse, pipelineStageIds, scope)
+	if err != nil {
+		impl.logger.Errorw("error in resolving stage request", "err", err, "pipelineStageIds", pipelineStageIds)
+		return resolvedResponse, err
+	}
+	//values referring scoped variables are checked only now, once resolved
+	err = impl.validateResolvedPluginStepInputs(resolvedResponse.PreStageSteps, resolvedResponse.PostStageSteps)
+	if err != nil {
+		impl.logger.Errorw("invalid plugin inputs in stage steps", "err", err, "pipelineId", pipelineId, "stageType", stageType)
+		return nil, err
+	}
+	return resolvedResponse, nil
+}
+
+// buildUnresolvedPrePostAndRefPluginStepsData returns the steps of the stages of the pipeline before their scoped
+// variables are resolved, along with the ids of the stages
+func (impl *PipelineStageServiceImpl) buildUnresolvedPrePostAndRefPluginStepsData(pipelineId int, stageType string) (*bean.PrePostAndRefPluginStepsResponse, []int, error) {
 	//get all stages By pipelineId (it can be ciPipelineId or cdPipelineId)
 	var pipelineStages []*repository.PipelineStage
 	var err error
@@ -1639,7 +1680,7 @@ func (impl *PipelineStageServiceImpl) BuildPrePostAndRefPluginStepsDataForWfRequ
 	}
 	if err != nil && err != pg.ErrNoRows {
 		impl.logger.Errorw("error in getting all ci stages by pipelineId", "err", err, "pipelineId", pipelineId, "stageType", stageType)
-		return nil, err
+		return nil, nil, err
 	}
 	var preCiSteps []*bean.StepObject
 	var postCiSteps []*bean.StepObject
@@ -1656,25 +1697,25 @@ func (impl *PipelineStageServiceImpl) BuildPrePostAndRefPluginStepsDataForWfRequ
 			preCiSteps = steps
 			if err != nil {
 				impl.logger.Errorw("error in getting pre ci steps data for wf request", "err", err, "ciStage", pipelineStage)
-				return nil, err
+				return nil, nil, err
 			}
 		case repository.PIPELINE_STAGE_TYPE_POST_CI:
 			postCiSteps = steps
 			if err != nil {
 				impl.logger.Errorw("error in getting post ci steps data for wf request", "err", err, "ciStage", pipelineStage)
-				return nil, err
+				return nil, nil, err
 			}
 		case repository.PIPELINE_STAGE_TYPE_PRE_CD:
 			preCdSteps = steps
 			if err != nil {
 				impl.logger.Errorw("error in getting post cd steps data for wf request", "err", err, "cdStage", pipelineStage)
-				return nil, err
+				return nil, nil, err
 			}
 		case repository.PIPELINE_STAGE_TYPE_POST_CD:
 			postCdSteps = steps
 			if err != nil {
 				impl.logger.Errorw("error in getting post cd steps data for wf request", "err", err, "cdStage", pipelineStage)
-				return nil, err
+				return nil, nil, err
 			}
 		}
 		refPluginIds = append(refPluginIds, refIds...)
@@ -1684,7 +1725,7 @@ func (impl *PipelineStageServiceImpl) BuildPrePostAndRefPluginStepsDataForWfRequ
 		refPluginsData, err = impl.BuildRefPluginStepDataForWfRequest(refPluginIds)
 		if err != nil {
 			impl.logger.Errorw("error in building ref plugin step data", "err", err, "refPluginIds", refPluginIds)
-			return nil, err
+			return nil, nil, err
 		}
 	}
 	unresolvedResponse := &bean.PrePostAndRefPluginStepsResponse{RefPluginData: refPluginsData}
@@ -1697,12 +1738,130 @@ func (impl *PipelineStageServiceImpl) BuildPrePostAndRefPluginStepsDataForWfRequ
 		unresolvedResponse.PostStageSteps = postCdSteps
 	}
 
-	resolvedResponse, err := impl.fetchScopedVariablesAndResolveTemplate(unresolvedResponse, pipelineStageIds, scope)
+	return unresolvedResponse, pipelineStageIds, nil
+}
+
+func (impl *PipelineStageServiceImpl) BuildStageStepsDataForWfRequest(pipelineId int, stageType repository.PipelineStageType) ([]*bean.StepObject, []*bean.RefPluginObject, error) {
+	var wfStageType string
+	switch stageType {
+	case repository.PIPELINE_STAGE_TYPE_PRE_CI, repository.PIPELINE_STAGE_TYPE_POST_CI:
+		wfStageType = bean.CiStage
+	case repository.PIPELINE_STAGE_TYPE_PRE_CD, repository.PIPELINE_STAGE_TYPE_POST_CD:
+		wfStageType = preCdStage
+		if stageType == repository.PIPELINE_STAGE_TYPE_POST_CD {
+			wfStageType = postCdStage
+		}
+		_, err := impl.pipelineStageRepository.GetCdStageByCdPipelineIdAndStageType(pipelineId, stageType)
+		if err == pg.ErrNoRows {
+			return nil, nil, nil
+		} else if err != nil {
+			impl.logger.Errorw("error in getting cd stage", "err", err, "cdPipelineId", pipelineId, "stageType", stageType)
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, errors.New("invalid stage type " + string(stageType))
+	}
+	response, _, err := impl.buildUnresolvedPrePostAndRefPluginStepsData(pipelineId, wfStageType)
 	if err != nil {
-		impl.logger.Errorw("error in resolving stage request", "err", err, "pipelineStageIds", pipelineStageIds)
-		return resolvedResponse, err
+		return nil, nil, err
 	}
-	return resolvedResponse, nil
+	if stageType == repository.PIPELINE_STAGE_TYPE_PRE_CI || stageType == repository.PIPELINE_STAGE_TYPE_PRE_CD {
+		return response.PreStageSteps, response.RefPluginData, nil
+	}
+	return response.PostStageSteps, response.RefPluginData, nil
+}
+
+// validatePipelineStage checks the dependencies between the steps of the stage and the inputs of the steps using
+// plugins before the stage is saved
+func (impl *PipelineStageServiceImpl) validatePipelineStage(stageReq *bean.PipelineStageDto, stageType repository.PipelineStageType) error {
+	err := validateStepDependencies(stageReq.Steps, stageType)
+	if err != nil {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: err.Error()}
+	}
+	return impl.validatePluginStepInputs(stageReq.Steps)
+}
+
+// validatePluginStepInputs checks the input values of the steps using plugins against the types of the inputs declared
+// by the plugins, all the invalid values are returned as field level errors
+func (impl *PipelineStageServiceImpl) validatePluginStepInputs(steps []*bean.PipelineStageStepDto) error {
+	var inputErrors []*plugin.PluginInputError
+	for _, step := range steps {
+		if step.StepType != repository.PIPELINE_STEP_TYPE_REF_PLUGIN || step.RefPluginStepDetail == nil {
+			continue
+		}
+		refPluginId, err := impl.resolveRefPluginId(step.RefPluginStepDetail.PluginId, step.RefPluginStepDetail.PluginVersion)
+		if err != nil {
+			return err
+		}
+		pluginInputs, err := impl.globalPluginRepository.GetExposedVariablesByPluginIdAndVariableType(refPluginId, repository2.PLUGIN_VARIABLE_TYPE_INPUT)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting plugin input variables", "err", err, "pluginId", refPluginId)
+			return err
+		}
+		values := make(map[string]*bean.StepVariableDto, len(step.RefPluginStepDetail.InputVariables))
+		for _, variable := range step.RefPluginStepDetail.InputVariables {
+			values[variable.Name] = variable
+		}
+		for _, input := range pluginInputs {
+			var value string
+			if variable, ok := values[input.Name]; ok {
+				if variable.ValueType != repository.PIPELINE_STAGE_STEP_VARIABLE_VALUE_TYPE_NEW {
+					continue
+				}
+				value = variable.Value
+			}
+			if err = plugin.ValidatePluginInputValue(input, value); err != nil {
+				inputErrors = append(inputErrors, &plugin.PluginInputError{StepName: step.Name, VariableName: input.Name, Message: err.Error()})
+			}
+		}
+	}
+	return getPluginInputValidationError(inputErrors)
+}
+
+// validateResolvedPluginStepInputs checks the input values of the steps using plugins at trigger, after scoped
+// variables are resolved
+func (impl *PipelineStageServiceImpl) validateResolvedPluginStepInputs(stageSteps ...[]*bean.StepObject) error {
+	var inputErrors []*plugin.PluginInputError
+	for _, steps := range stageSteps {
+		for _, step := range steps {
+			if step.StepType != string(repository.PIPELINE_STEP_TYPE_REF_PLUGIN) {
+				continue
+			}
+			pluginInputs, err := impl.globalPluginRepository.GetExposedVariablesByPluginIdAndVariableType(step.RefPluginId, repository2.PLUGIN_VARIABLE_TYPE_INPUT)
+			if err != nil && err != pg.ErrNoRows {
+				impl.logger.Errorw("error in getting plugin input variables", "err", err, "pluginId", step.RefPluginId)
+				return err
+			}
+			values := make(map[string]*bean.VariableObject, len(step.InputVars))
+			for _, variable := range step.InputVars {
+				values[variable.Name] = variable
+			}
+			for _, input := range pluginInputs {
+				var value string
+				if variable, ok := values[input.Name]; ok {
+					if variable.VariableType != bean.VARIABLE_TYPE_VALUE {
+						continue
+					}
+					value = variable.Value
+				}
+				if err = plugin.ValidateResolvedPluginInputValue(input, value); err != nil {
+					inputErrors = append(inputErrors, &plugin.PluginInputError{StepName: step.Name, VariableName: input.Name, Message: err.Error()})
+				}
+			}
+		}
+	}
+	return getPluginInputValidationError(inputErrors)
+}
+
+func getPluginInputValidationError(inputErrors []*plugin.PluginInputError) error {
+	if len(inputErrors) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(inputErrors))
+	for _, inputError := range inputErrors {
+		messages = append(messages, inputError.Error())
+	}
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: strings.Join(messages, ", "), UserMessage: inputErrors}
 }
 
 func getPipelineStageFromStageType(stageType string) repository.PipelineStageType {
@@ -1735,7 +1894,11 @@ func (impl *PipelineStageServiceImpl) BuildPipelineStageDataForWfRequest(pipelin
 		}
 		stepsData = append(stepsData, stepData)
 	}
-
+	stepsData, err = orderStepsByDependencies(stepsData)
+	if err != nil {
+		impl.logger.Errorw("invalid step dependencies in pipeline stage", "err", err, "stageId", pipelineStage.Id)
+		return nil, nil, err
+	}
 	return stepsData, refPluginIds, nil
 }
 
@@ -1816,6 +1979,7 @@ func (impl *PipelineStageServiceImpl) BuildPipelineStepDataForWfRequest(step *re
 		StepType:                 string(step.StepType),
 		ArtifactPaths:            step.OutputDirectoryPath,
 		TriggerIfParentStageFail: step.TriggerIfParentStageFail,
+		DependsOn:                step.DependsOnStepIndexes,
 	}
 	if step.StepType == repository.PIPELINE_STEP_TYPE_INLINE {
 		//get script and mapping data
@@ -1868,7 +2032,13 @@ func (impl *PipelineStageServiceImpl) BuildPipelineStepDataForWfRequest(step *re
 		}
 	} else if step.StepType == repository.PIPELINE_STEP_TYPE_REF_PLUGIN {
 		stepData.ExecutorType = "PLUGIN" //added only to avoid un-marshaling issues at ci-runner side, will not be used
-		stepData.RefPluginId = step.RefPluginId
+		//steps with a version constraint run the newest version of the plugin matching it at trigger time
+		refPluginId, err := impl.resolveRefPluginId(step.RefPluginId, step.RefPluginVersionConstraint)
+		if err != nil {
+			impl.logger.Errorw("error in resolving plugin version of step", "err", err, "stepId", step.Id, "pluginVersion", step.RefPluginVersionConstraint)
+			return nil, err
+		}
+		stepData.RefPluginId = refPluginId
 	}
 	inputVars, outputVars, triggerSkipConditions, successFailureConditions, err := impl.BuildVariableAndConditionDataForWfRequest(step.Id)
 	if err != nil {
@@ -1882,6 +2052,29 @@ func (impl *PipelineStageServiceImpl) BuildPipelineStepDataForWfRequest(step *re
 	return stepData, nil
 }
 
+// resolveRefPluginId returns the version of the plugin matching the version constraint, the plugin itself if there is
+// no constraint
+func (impl *PipelineStageServiceImpl) resolveRefPluginId(pluginId int, versionConstraint string) (int, error) {
+	if len(versionConstraint) == 0 {
+		return pluginId, nil
+	}
+	pluginMetadata, err := impl.globalPluginRepository.GetMetaDataByPluginId(pluginId)
+	if err != nil {
+		impl.logger.Errorw("error in getting plugin metadata", "err", err, "pluginId", pluginId)
+		return 0, err
+	}
+	versions, err := impl.globalPluginRepository.GetPluginVersionsByParentId(pluginMetadata.GetParentId())
+	if err != nil {
+		impl.logger.Errorw("error in getting plugin versions", "err", err, "pluginId", pluginId)
+		return 0, err
+	}
+	resolved, err := plugin.ResolvePluginVersion(versions, versionConstraint)
+	if err != nil {
+		return 0, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: err.Error()}
+	}
+	return resolved.Id, nil
+}
+
 func (impl *PipelineStageServiceImpl) BuildVariableAndConditionDataForWfRequest(stepId int) ([]*bean.VariableObject, []*bean.VariableObject, []*bean.ConditionObject, []*bean.ConditionObject, error) {
 	var inputVariables []*bean.VariableObject
 	var outputVariables []*bean.VariableObject
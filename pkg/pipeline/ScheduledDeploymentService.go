@@ -0,0 +1,347 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/appWorkflow"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
+	"github.com/devtron-labs/devtron/pkg/auth/user"
+	"github.com/devtron-labs/devtron/pkg/deploymentWindow"
+	bean2 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/argo"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const (
+	// number of past scheduled deployments returned along with the pending ones of a pipeline
+	scheduledDeploymentHistoryLimit = 20
+	// a scheduled deployment still in progress after this long was interrupted, the instance executing it went down
+	scheduledDeploymentStaleTimeout = 30 * time.Minute
+)
+
+type ScheduledDeploymentService interface {
+	ScheduleDeployment(request *bean2.ScheduledDeploymentRequest) (*bean2.ScheduledDeploymentDto, error)
+	GetScheduledDeploymentById(id int) (*bean2.ScheduledDeploymentDto, error)
+	GetScheduledDeployments(appId int, pipelineId int) ([]*bean2.ScheduledDeploymentDto, error)
+	CancelScheduledDeployment(appId int, id int, userId int32) error
+	// ExecuteDueScheduledDeployments triggers all the scheduled deployments which are due. RBAC of the user who scheduled
+	// the deployment and vulnerability policy are validated again before triggering
+	ExecuteDueScheduledDeployments()
+}
+
+type ScheduledDeploymentServiceImpl struct {
+	logger                        *zap.SugaredLogger
+	scheduledDeploymentRepository pipelineConfig.ScheduledDeploymentRepository
+	pipelineRepository            pipelineConfig.PipelineRepository
+	ciArtifactRepository          repository.CiArtifactRepository
+	appWorkflowRepository         appWorkflow.AppWorkflowRepository
+	workflowDagExecutor           WorkflowDagExecutor
+	deploymentWindowService       deploymentWindow.DeploymentWindowService
+	userService                   user.UserService
+	enforcer                      casbin.Enforcer
+	enforcerUtil                  rbac.EnforcerUtil
+	argoUserService               argo.ArgoUserService
+}
+
+func NewScheduledDeploymentServiceImpl(logger *zap.SugaredLogger,
+	scheduledDeploymentRepository pipelineConfig.ScheduledDeploymentRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	ciArtifactRepository repository.CiArtifactRepository,
+	appWorkflowRepository appWorkflow.AppWorkflowRepository,
+	workflowDagExecutor WorkflowDagExecutor,
+	deploymentWindowService deploymentWindow.DeploymentWindowService,
+	userService user.UserService,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	argoUserService argo.ArgoUserService) *ScheduledDeploymentServiceImpl {
+	return &ScheduledDeploymentServiceImpl{
+		logger:                        logger,
+		scheduledDeploymentRepository: scheduledDeploymentRepository,
+		pipelineRepository:            pipelineRepository,
+		ciArtifactRepository:          ciArtifactRepository,
+		appWorkflowRepository:         appWorkflowRepository,
+		workflowDagExecutor:           workflowDagExecutor,
+		deploymentWindowService:       deploymentWindowService,
+		userService:                   userService,
+		enforcer:                      enforcer,
+		enforcerUtil:                  enforcerUtil,
+		argoUserService:               argoUserService,
+	}
+}
+
+func (impl *ScheduledDeploymentServiceImpl) ScheduleDeployment(request *bean2.ScheduledDeploymentRequest) (*bean2.ScheduledDeploymentDto, error) {
+	pipeline, err := impl.getPipelineOfApp(request.AppId, request.PipelineId)
+	if err != nil {
+		return nil, err
+	}
+	artifact, err := impl.ciArtifactRepository.Get(request.CiArtifactId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact", "ciArtifactId", request.CiArtifactId, "err", err)
+		return nil, err
+	}
+	err = impl.validateArtifactSource(pipeline, artifact)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if request.InNextDeploymentWindow {
+		nextAllowedTime, err := impl.deploymentWindowService.GetNextAllowedTime(pipeline.AppId, pipeline.EnvironmentId, now)
+		if err != nil {
+			impl.logger.Errorw("error in finding next deployment window", "pipelineId", pipeline.Id, "err", err)
+			return nil, err
+		}
+		if nextAllowedTime == nil {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "no deployment window found for the pipeline in the next 30 days", UserMessage: "no deployment window found for the pipeline in the next 30 days"}
+		}
+		request.ScheduledOn = nextAllowedTime
+	}
+	err = bean2.ValidateScheduleRequest(request, now)
+	if err != nil {
+		impl.logger.Errorw("invalid schedule deployment request", "request", request, "err", err)
+		return nil, err
+	}
+	scheduledDeployment := &pipelineConfig.ScheduledDeployment{
+		PipelineId:             request.PipelineId,
+		CiArtifactId:           request.CiArtifactId,
+		ScheduledOn:            *request.ScheduledOn,
+		InNextDeploymentWindow: request.InNextDeploymentWindow,
+		Status:                 pipelineConfig.SCHEDULED_DEPLOYMENT_STATUS_SCHEDULED,
+		AuditLog:               sql.NewDefaultAuditLog(request.UserId),
+	}
+	err = impl.scheduledDeploymentRepository.Save(scheduledDeployment)
+	if err != nil {
+		impl.logger.Errorw("error in saving scheduled deployment", "scheduledDeployment", scheduledDeployment, "err", err)
+		return nil, err
+	}
+	return impl.adaptScheduledDeployments([]*pipelineConfig.ScheduledDeployment{scheduledDeployment})[0], nil
+}
+
+func (impl *ScheduledDeploymentServiceImpl) GetScheduledDeploymentById(id int) (*bean2.ScheduledDeploymentDto, error) {
+	scheduledDeployment, err := impl.scheduledDeploymentRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching scheduled deployment", "id", id, "err", err)
+		return nil, err
+	}
+	return impl.adaptScheduledDeployments([]*pipelineConfig.ScheduledDeployment{scheduledDeployment})[0], nil
+}
+
+func (impl *ScheduledDeploymentServiceImpl) GetScheduledDeployments(appId int, pipelineId int) ([]*bean2.ScheduledDeploymentDto, error) {
+	_, err := impl.getPipelineOfApp(appId, pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	scheduledDeployments, err := impl.scheduledDeploymentRepository.FindByPipelineId(pipelineId, scheduledDeploymentHistoryLimit)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching scheduled deployments", "pipelineId", pipelineId, "err", err)
+		return nil, err
+	}
+	return impl.adaptScheduledDeployments(scheduledDeployments), nil
+}
+
+func (impl *ScheduledDeploymentServiceImpl) CancelScheduledDeployment(appId int, id int, userId int32) error {
+	scheduledDeployment, err := impl.scheduledDeploymentRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching scheduled deployment", "id", id, "err", err)
+		return err
+	}
+	_, err = impl.getPipelineOfApp(appId, scheduledDeployment.PipelineId)
+	if err != nil {
+		return err
+	}
+	if scheduledDeployment.Status != pipelineConfig.SCHEDULED_DEPLOYMENT_STATUS_SCHEDULED {
+		errMsg := fmt.Sprintf("scheduled deployment is already %s, it cannot be cancelled", scheduledDeployment.Status)
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: errMsg, UserMessage: errMsg}
+	}
+	scheduledDeployment.Status = pipelineConfig.SCHEDULED_DEPLOYMENT_STATUS_CANCELLED
+	scheduledDeployment.UpdatedOn = time.Now()
+	scheduledDeployment.UpdatedBy = userId
+	err = impl.scheduledDeploymentRepository.Update(scheduledDeployment)
+	if err != nil {
+		impl.logger.Errorw("error in cancelling scheduled deployment", "id", id, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *ScheduledDeploymentServiceImpl) ExecuteDueScheduledDeployments() {
+	impl.failStaleScheduledDeployments()
+	scheduledDeployments, err := impl.scheduledDeploymentRepository.FindDueForExecution(time.Now())
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching due scheduled deployments", "err", err)
+		return
+	}
+	for _, scheduledDeployment := range scheduledDeployments {
+		claimed, err := impl.scheduledDeploymentRepository.MarkInProgress(scheduledDeployment.Id)
+		if err != nil {
+			impl.logger.Errorw("error in marking scheduled deployment in progress", "id", scheduledDeployment.Id, "err", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		wfrId, err := impl.executeScheduledDeployment(scheduledDeployment)
+		scheduledDeployment.Status = pipelineConfig.SCHEDULED_DEPLOYMENT_STATUS_TRIGGERED
+		scheduledDeployment.CdWorkflowRunnerId = wfrId
+		if err != nil {
+			impl.logger.Errorw("error in executing scheduled deployment", "id", scheduledDeployment.Id, "pipelineId", scheduledDeployment.PipelineId, "err", err)
+			scheduledDeployment.Status = pipelineConfig.SCHEDULED_DEPLOYMENT_STATUS_FAILED
+			scheduledDeployment.Message = util.GetGRPCErrorDetailedMessage(err)
+		}
+		scheduledDeployment.ExecutedOn = time.Now()
+		scheduledDeployment.UpdatedOn = time.Now()
+		scheduledDeployment.UpdatedBy = DEVTRON_SYSTEM_USER_ID
+		err = impl.scheduledDeploymentRepository.Update(scheduledDeployment)
+		if err != nil {
+			impl.logger.Errorw("error in updating scheduled deployment", "scheduledDeployment", scheduledDeployment, "err", err)
+		}
+	}
+}
+
+// failStaleScheduledDeployments fails the deployments left in progress by an instance which went down while executing
+// them. They are not executed again, the deployment may have been triggered before the instance went down
+func (impl *ScheduledDeploymentServiceImpl) failStaleScheduledDeployments() {
+	staleCount, err := impl.scheduledDeploymentRepository.MarkStaleInProgressFailed(time.Now().Add(-scheduledDeploymentStaleTimeout),
+		"execution of the scheduled deployment was interrupted, check the deployment history of the pipeline and schedule it again if needed")
+	if err != nil {
+		impl.logger.Errorw("error in failing stale scheduled deployments", "err", err)
+		return
+	}
+	if staleCount > 0 {
+		impl.logger.Warnw("failed scheduled deployments left in progress", "count", staleCount)
+	}
+}
+
+// executeScheduledDeployment triggers the deployment on behalf of the user who scheduled it and returns the runner
+// created for the deployment, if any
+func (impl *ScheduledDeploymentServiceImpl) executeScheduledDeployment(scheduledDeployment *pipelineConfig.ScheduledDeployment) (int, error) {
+	pipeline, err := impl.pipelineRepository.FindById(scheduledDeployment.PipelineId)
+	if err != nil {
+		if util.IsErrNoRows(err) {
+			return 0, fmt.Errorf("cd pipeline has been deleted")
+		}
+		return 0, err
+	}
+	scheduledBy, err := impl.userService.GetById(scheduledDeployment.CreatedBy)
+	if err != nil {
+		if util.IsErrNoRows(err) {
+			return 0, fmt.Errorf("user who scheduled the deployment is no longer active")
+		}
+		return 0, err
+	}
+	if !impl.isTriggerAllowed(scheduledBy.EmailId, pipeline) {
+		return 0, fmt.Errorf("user %s is no longer authorised to deploy on this pipeline", scheduledBy.EmailId)
+	}
+	ctx := context.Background()
+	artifact, err := impl.ciArtifactRepository.Get(scheduledDeployment.CiArtifactId)
+	if err != nil {
+		return 0, err
+	}
+	isVulnerable, err := impl.workflowDagExecutor.GetArtifactVulnerabilityStatus(artifact, pipeline, ctx)
+	if err != nil {
+		return 0, err
+	}
+	if isVulnerable {
+		return 0, fmt.Errorf("found vulnerability for image digest %s", artifact.ImageDigest)
+	}
+	if util.IsAcdApp(pipeline.DeploymentAppType) {
+		acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+		if err != nil {
+			impl.logger.Errorw("error in getting acd token", "err", err)
+			return 0, err
+		}
+		ctx = context.WithValue(ctx, "token", acdToken)
+	}
+	overrideRequest := &bean.ValuesOverrideRequest{
+		PipelineId:           pipeline.Id,
+		AppId:                pipeline.AppId,
+		CiArtifactId:         scheduledDeployment.CiArtifactId,
+		CdWorkflowType:       bean.CD_WORKFLOW_TYPE_DEPLOY,
+		DeploymentType:       models.DEPLOYMENTTYPE_DEPLOY,
+		DeploymentWithConfig: bean.DEPLOYMENT_CONFIG_TYPE_LAST_SAVED,
+		UserId:               scheduledDeployment.CreatedBy,
+	}
+	impl.logger.Infow("triggering scheduled deployment", "id", scheduledDeployment.Id, "pipelineId", pipeline.Id, "ciArtifactId", scheduledDeployment.CiArtifactId)
+	_, err = impl.workflowDagExecutor.ManualCdTrigger(overrideRequest, ctx)
+	return overrideRequest.WfrId, err
+}
+
+func (impl *ScheduledDeploymentServiceImpl) getPipelineOfApp(appId int, pipelineId int) (*pipelineConfig.Pipeline, error) {
+	pipeline, err := impl.pipelineRepository.FindById(pipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching pipeline", "pipelineId", pipelineId, "err", err)
+		return nil, err
+	}
+	if pipeline.AppId != appId {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: "pipeline does not belong to app", UserMessage: "pipeline does not belong to app"}
+	}
+	return pipeline, nil
+}
+
+// validateArtifactSource checks that the artifact was built by the ci pipeline, or received by the external ci, which the
+// workflow of the cd pipeline is sourced from
+func (impl *ScheduledDeploymentServiceImpl) validateArtifactSource(pipeline *pipelineConfig.Pipeline, artifact *repository.CiArtifact) error {
+	externalCiPipelineId := 0
+	if pipeline.CiPipelineId == 0 {
+		cdMapping, err := impl.appWorkflowRepository.FindWFCDMappingByCDPipelineId(pipeline.Id)
+		if err != nil {
+			impl.logger.Errorw("error in fetching workflow mapping of cd pipeline", "pipelineId", pipeline.Id, "err", err)
+			return err
+		}
+		mappings, err := impl.appWorkflowRepository.FindWFAllMappingByWorkflowId(cdMapping.AppWorkflowId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching workflow mappings", "appWorkflowId", cdMapping.AppWorkflowId, "err", err)
+			return err
+		}
+		for _, mapping := range mappings {
+			if mapping.Type == appWorkflow.WEBHOOK {
+				externalCiPipelineId = mapping.ComponentId
+			}
+		}
+	}
+	if !bean2.IsArtifactOfCiSource(artifact, pipeline.CiPipelineId, externalCiPipelineId) {
+		errMsg := "artifact does not belong to the ci source of the pipeline"
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: errMsg, UserMessage: errMsg}
+	}
+	return nil
+}
+
+func (impl *ScheduledDeploymentServiceImpl) isTriggerAllowed(emailId string, pipeline *pipelineConfig.Pipeline) bool {
+	appObject := impl.enforcerUtil.GetAppRBACNameByAppId(pipeline.AppId)
+	if !impl.enforcer.EnforceByEmail(emailId, casbin.ResourceApplications, casbin.ActionTrigger, appObject) {
+		return false
+	}
+	envObject := impl.enforcerUtil.GetAppRBACByAppIdAndPipelineId(pipeline.AppId, pipeline.Id)
+	return impl.enforcer.EnforceByEmail(emailId, casbin.ResourceEnvironment, casbin.ActionTrigger, envObject)
+}
+
+func (impl *ScheduledDeploymentServiceImpl) adaptScheduledDeployments(scheduledDeployments []*pipelineConfig.ScheduledDeployment) []*bean2.ScheduledDeploymentDto {
+	userIds := make([]int32, 0, len(scheduledDeployments))
+	for _, scheduledDeployment := range scheduledDeployments {
+		userIds = append(userIds, scheduledDeployment.CreatedBy)
+	}
+	userEmails := make(map[int32]string)
+	users, err := impl.userService.GetByIds(userIds)
+	if err != nil && err != pg.ErrNoRows {
+		// emails are only informative here, not failing the request for them
+		impl.logger.Errorw("error in fetching users", "userIds", userIds, "err", err)
+	}
+	for _, userInfo := range users {
+		userEmails[userInfo.Id] = userInfo.EmailId
+	}
+	result := make([]*bean2.ScheduledDeploymentDto, 0, len(scheduledDeployments))
+	for _, scheduledDeployment := range scheduledDeployments {
+		result = append(result, bean2.AdaptScheduledDeployment(scheduledDeployment, userEmails[scheduledDeployment.CreatedBy]))
+	}
+	return result
+}
@@ -2,7 +2,9 @@ package pipeline
 
 import (
 	"errors"
+	"fmt"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
 	bean2 "github.com/devtron-labs/devtron/pkg/bean"
 	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
 	repository2 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
@@ -10,6 +12,7 @@ import (
 	"github.com/devtron-labs/devtron/pkg/plugin"
 	"github.com/devtron-labs/devtron/pkg/plugin/repository"
 	"gopkg.in/yaml.v2"
+	"sort"
 	"strings"
 )
 
@@ -286,3 +289,163 @@ func ToTaskYaml(yamlFile []byte) (*types.TaskYaml, error) {
 	err := yaml.Unmarshal(yamlFile, taskYaml)
 	return taskYaml, err
 }
+
+// getDependentOnStep returns the names of the steps the step depends on. Steps of stages where no step declares
+// dependencies depend on the previous step, steps declaring none in the other stages depend on no step.
+func getDependentOnStep(step *bean.PipelineStageStepDto, isDag bool, indexNameString map[int]string) string {
+	if isDag {
+		names := make([]string, 0, len(step.DependsOn))
+		for _, index := range step.DependsOn {
+			names = append(names, indexNameString[index])
+		}
+		return strings.Join(names, ",")
+	}
+	//since starting index is independent of any step we will be setting dependent detail for further indexes
+	if step.Index > 1 {
+		return indexNameString[step.Index-1]
+	}
+	return ""
+}
+
+// hasStepDependencies returns whether a step of the stage declares dependencies, steps of other stages run in index order
+func hasStepDependencies(steps []*bean.PipelineStageStepDto) bool {
+	for _, step := range steps {
+		if len(step.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateStepDependencies checks the dependencies declared by the steps of a stage form a DAG, and that inputs using
+// the outputs of other steps of the stage use steps which run before them
+func validateStepDependencies(steps []*bean.PipelineStageStepDto, stageType repository2.PipelineStageType) error {
+	if !hasStepDependencies(steps) {
+		return nil
+	}
+	dependsOn := make(map[int][]int, len(steps))
+	for _, step := range steps {
+		if _, ok := dependsOn[step.Index]; ok {
+			return fmt.Errorf("more than one step has the index %d", step.Index)
+		}
+		dependsOn[step.Index] = step.DependsOn
+	}
+	if _, err := sortStepIndexesByDependencies(dependsOn); err != nil {
+		return err
+	}
+	for _, step := range steps {
+		var inputVariables []*bean.StepVariableDto
+		if step.InlineStepDetail != nil {
+			inputVariables = step.InlineStepDetail.InputVariables
+		} else if step.RefPluginStepDetail != nil {
+			inputVariables = step.RefPluginStepDetail.InputVariables
+		}
+		for _, variable := range inputVariables {
+			if variable.ValueType != repository2.PIPELINE_STAGE_STEP_VARIABLE_VALUE_TYPE_PREVIOUS ||
+				(len(variable.ReferenceVariableStage) > 0 && variable.ReferenceVariableStage != stageType) {
+				continue
+			}
+			if !isDependentOnStep(dependsOn, step.Index, variable.PreviousStepIndex, make(map[int]bool)) {
+				return fmt.Errorf("step %s uses output %s of step %d in input %s but does not depend on it",
+					step.Name, variable.ReferenceVariableName, variable.PreviousStepIndex, variable.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// isDependentOnStep returns whether the step of index runs after the step of dependency, directly or through other steps
+func isDependentOnStep(dependsOn map[int][]int, index int, dependency int, visited map[int]bool) bool {
+	visited[index] = true
+	for _, parent := range dependsOn[index] {
+		if parent == dependency || (!visited[parent] && isDependentOnStep(dependsOn, parent, dependency, visited)) {
+			return true
+		}
+	}
+	return false
+}
+
+// orderStepsByDependencies sets the steps each step of a stage runs after and groups the steps so that the steps of a
+// group depend only on steps of the groups before it, the runner runs the steps of a group concurrently. Steps are
+// ordered by their group and then their index. Steps of stages where no step declares dependencies run in index order,
+// each in its own group.
+func orderStepsByDependencies(steps []*bean.StepObject) ([]*bean.StepObject, error) {
+	isDag := false
+	for _, step := range steps {
+		isDag = isDag || len(step.DependsOn) > 0
+	}
+	if !isDag {
+		for i, step := range steps {
+			step.ParallelGroup = i
+			if i > 0 {
+				step.DependsOn = []int{steps[i-1].Index}
+			}
+		}
+		return steps, nil
+	}
+	dependsOn := make(map[int][]int, len(steps))
+	stepsByIndex := make(map[int]*bean.StepObject, len(steps))
+	for _, step := range steps {
+		dependsOn[step.Index] = step.DependsOn
+		stepsByIndex[step.Index] = step
+	}
+	sorted, err := sortStepIndexesByDependencies(dependsOn)
+	if err != nil {
+		return nil, err
+	}
+	orderedSteps := make([]*bean.StepObject, 0, len(sorted))
+	for _, index := range sorted {
+		step := stepsByIndex[index]
+		// the dependencies come before the step in the sorted order, so their groups are already set
+		step.ParallelGroup = 0
+		for _, dependency := range step.DependsOn {
+			if group := stepsByIndex[dependency].ParallelGroup + 1; group > step.ParallelGroup {
+				step.ParallelGroup = group
+			}
+		}
+		orderedSteps = append(orderedSteps, step)
+	}
+	sort.SliceStable(orderedSteps, func(i, j int) bool {
+		if orderedSteps[i].ParallelGroup != orderedSteps[j].ParallelGroup {
+			return orderedSteps[i].ParallelGroup < orderedSteps[j].ParallelGroup
+		}
+		return orderedSteps[i].Index < orderedSteps[j].Index
+	})
+	return orderedSteps, nil
+}
+
+// sortStepIndexesByDependencies returns the indexes of the steps of a stage in an order where every step comes after
+// the steps it depends on, dependsOn has the dependencies of every step of the stage
+func sortStepIndexesByDependencies(dependsOn map[int][]int) ([]int, error) {
+	graph := make(map[int][]int, len(dependsOn))
+	for index := range dependsOn {
+		graph[index] = nil
+	}
+	for index, dependencies := range dependsOn {
+		for _, dependency := range dependencies {
+			if dependency == index {
+				return nil, fmt.Errorf("step %d depends on itself", index)
+			}
+			if _, ok := dependsOn[dependency]; !ok {
+				return nil, fmt.Errorf("step %d depends on step %d which is not in the stage", index, dependency)
+			}
+			graph[dependency] = append(graph[dependency], index)
+		}
+	}
+	sorted := util.TopoSort(graph)
+	if len(sorted) < len(graph) {
+		isSorted := make(map[int]bool, len(sorted))
+		for _, index := range sorted {
+			isSorted[index] = true
+		}
+		var cycle []int
+		for index := range graph {
+			if !isSorted[index] {
+				cycle = append(cycle, index)
+			}
+		}
+		sort.Ints(cycle)
+		return nil, fmt.Errorf("dependencies of steps %v form a cycle", cycle)
+	}
+	return sorted, nil
+}
@@ -3,6 +3,7 @@ package pipeline
 import (
 	"github.com/devtron-labs/devtron/pkg/bean"
 	bean2 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/pkg/pipeline/repository"
 	"reflect"
 	"strings"
 	"testing"
@@ -268,3 +269,72 @@ func TestStageServiceUtil_CreatePreAndPostStageResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateStepDependencies(t *testing.T) {
+	usesLint := &bean2.InlineStepDetailDto{InputVariables: []*bean2.StepVariableDto{
+		{Name: "REPORT", ValueType: repository.PIPELINE_STAGE_STEP_VARIABLE_VALUE_TYPE_PREVIOUS, PreviousStepIndex: 1, ReferenceVariableName: "LINT_REPORT"},
+	}}
+	tests := []struct {
+		name    string
+		steps   []*bean2.PipelineStageStepDto
+		wantErr bool
+	}{
+		{"sequential", []*bean2.PipelineStageStepDto{{Index: 1}, {Index: 2, InlineStepDetail: usesLint}}, false},
+		{"parallel", []*bean2.PipelineStageStepDto{{Index: 1}, {Index: 2}, {Index: 3, DependsOn: []int{1, 2}, InlineStepDetail: usesLint}}, false},
+		{"transitive output", []*bean2.PipelineStageStepDto{{Index: 1}, {Index: 2, DependsOn: []int{1}}, {Index: 3, DependsOn: []int{2}, InlineStepDetail: usesLint}}, false},
+		{"output of parallel step", []*bean2.PipelineStageStepDto{{Index: 1}, {Index: 2}, {Index: 3, DependsOn: []int{2}, InlineStepDetail: usesLint}}, true},
+		{"cycle", []*bean2.PipelineStageStepDto{{Index: 1, DependsOn: []int{3}}, {Index: 2, DependsOn: []int{1}}, {Index: 3, DependsOn: []int{2}}}, true},
+		{"self", []*bean2.PipelineStageStepDto{{Index: 1, DependsOn: []int{1}}}, true},
+		{"missing step", []*bean2.PipelineStageStepDto{{Index: 1, DependsOn: []int{4}}}, true},
+		{"duplicate index", []*bean2.PipelineStageStepDto{{Index: 1}, {Index: 1, DependsOn: []int{1}}}, true},
+	}
+	for _, tt := range tests {
+		if err := validateStepDependencies(tt.steps, repository.PIPELINE_STAGE_TYPE_PRE_CI); (err != nil) != tt.wantErr {
+			t.Errorf("validateStepDependencies() of %s err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGetDependentOnStep(t *testing.T) {
+	names := map[int]string{1: "lint", 2: "test", 3: "scan"}
+	tests := []struct {
+		name  string
+		step  *bean2.PipelineStageStepDto
+		isDag bool
+		want  string
+	}{
+		{"first sequential step", &bean2.PipelineStageStepDto{Index: 1}, false, ""},
+		{"sequential step", &bean2.PipelineStageStepDto{Index: 3}, false, "test"},
+		{"dag root", &bean2.PipelineStageStepDto{Index: 2}, true, ""},
+		{"dag step", &bean2.PipelineStageStepDto{Index: 3, DependsOn: []int{1, 2}}, true, "lint,test"},
+	}
+	for _, tt := range tests {
+		if got := getDependentOnStep(tt.step, tt.isDag, names); got != tt.want {
+			t.Errorf("getDependentOnStep() of %s = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOrderStepsByDependencies(t *testing.T) {
+	sequential, err := orderStepsByDependencies([]*bean2.StepObject{{Index: 1}, {Index: 2}, {Index: 3}})
+	if err != nil || sequential[0].DependsOn != nil || !reflect.DeepEqual(sequential[2].DependsOn, []int{2}) || sequential[2].ParallelGroup != 2 {
+		t.Errorf("orderStepsByDependencies() of sequential steps = %v, err %v", sequential, err)
+	}
+	steps, err := orderStepsByDependencies([]*bean2.StepObject{
+		{Index: 1, DependsOn: []int{3, 4}}, {Index: 2}, {Index: 3, DependsOn: []int{2}}, {Index: 4},
+	})
+	if err != nil || len(steps) != 4 {
+		t.Fatalf("orderStepsByDependencies() = %v, err %v", steps, err)
+	}
+	var order, groups []int
+	for _, step := range steps {
+		order = append(order, step.Index)
+		groups = append(groups, step.ParallelGroup)
+	}
+	if !reflect.DeepEqual(order, []int{2, 4, 3, 1}) || !reflect.DeepEqual(groups, []int{0, 0, 1, 2}) {
+		t.Errorf("orderStepsByDependencies() order = %v groups = %v, want [2 4 3 1] groups [0 0 1 2]", order, groups)
+	}
+	if _, err = orderStepsByDependencies([]*bean2.StepObject{{Index: 1, DependsOn: []int{2}}, {Index: 2, DependsOn: []int{1}}}); err == nil {
+		t.Errorf("orderStepsByDependencies() of cyclic steps did not fail")
+	}
+}
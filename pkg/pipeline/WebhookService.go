@@ -34,6 +34,7 @@ import (
 	repository2 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
 	types2 "github.com/devtron-labs/devtron/pkg/pipeline/types"
 	repository3 "github.com/devtron-labs/devtron/pkg/plugin/repository"
+	"github.com/devtron-labs/devtron/pkg/provenance"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/devtron-labs/devtron/util/event"
 	"github.com/go-pg/pg"
@@ -80,6 +81,8 @@ type WebhookServiceImpl struct {
 	pipelineStageRepository repository2.PipelineStageRepository
 	globalPluginRepository  repository3.GlobalPluginRepository
 	customTagService        CustomTagService
+	ciBuildMatrixService    CiBuildMatrixService
+	provenanceService       provenance.ProvenanceService
 }
 
 func NewWebhookServiceImpl(
@@ -92,7 +95,9 @@ func NewWebhookServiceImpl(
 	workflowDagExecutor WorkflowDagExecutor, ciHandler CiHandler,
 	pipelineStageRepository repository2.PipelineStageRepository,
 	globalPluginRepository repository3.GlobalPluginRepository,
-	customTagService CustomTagService) *WebhookServiceImpl {
+	customTagService CustomTagService,
+	ciBuildMatrixService CiBuildMatrixService,
+	provenanceService provenance.ProvenanceService) *WebhookServiceImpl {
 	webhookHandler := &WebhookServiceImpl{
 		ciArtifactRepository:    ciArtifactRepository,
 		logger:                  logger,
@@ -106,6 +111,8 @@ func NewWebhookServiceImpl(
 		pipelineStageRepository: pipelineStageRepository,
 		globalPluginRepository:  globalPluginRepository,
 		customTagService:        customTagService,
+		ciBuildMatrixService:    ciBuildMatrixService,
+		provenanceService:       provenanceService,
 	}
 	config, err := types2.GetCiConfig()
 	if err != nil {
@@ -151,6 +158,16 @@ func (impl WebhookServiceImpl) HandleCiStepFailedEvent(ciPipelineId int, request
 		return err
 	}
 
+	// the reason reported by the ci runner tells the failed step apart, which the message of the failed pod does not
+	if failureReason, _ := bean.ClassifyCiFailure(request.FailureReason); failureReason != bean.CI_FAILURE_UNKNOWN {
+		err = impl.ciWorkflowRepository.UpdateFailureReason(savedWorkflow.Id, string(failureReason))
+		if err != nil {
+			impl.logger.Errorw("error in updating failure reason of ci workflow", "ciWorkflowId", savedWorkflow.Id, "failureReason", failureReason, "err", err)
+			return err
+		}
+		savedWorkflow.FailureReason = string(failureReason)
+	}
+
 	pipeline, err := impl.ciPipelineRepository.FindByCiAndAppDetailsById(ciPipelineId)
 	if err != nil {
 		impl.logger.Errorw("unable to find pipeline", "ID", ciPipelineId, "err", err)
@@ -182,6 +199,15 @@ func (impl WebhookServiceImpl) HandleCiSuccessEvent(ciPipelineId int, request *C
 		if savedWorkflow.Status == executors.WorkflowCancel {
 			return 0, err
 		}
+		// with a build matrix, the artifact is saved once the images of all the platforms are built
+		isBuildComplete, err := impl.ciBuildMatrixService.HandleImageBuilt(savedWorkflow.Id, request)
+		if err != nil {
+			impl.logger.Errorw("error in handling image built by build matrix", "ciWorkflowId", savedWorkflow.Id, "err", err)
+			return 0, err
+		}
+		if !isBuildComplete {
+			return 0, nil
+		}
 		savedWorkflow.Status = string(v1alpha1.NodeSucceeded)
 		impl.logger.Debugw("updating workflow ", "savedWorkflow", savedWorkflow)
 		err = impl.ciWorkflowRepository.UpdateWorkFlow(savedWorkflow)
@@ -234,14 +260,14 @@ func (impl WebhookServiceImpl) HandleCiSuccessEvent(ciPipelineId int, request *C
 		IsArtifactUploaded: request.IsArtifactUploaded,
 		AuditLog:           sql.AuditLog{CreatedBy: request.UserId, UpdatedBy: request.UserId, CreatedOn: createdOn, UpdatedOn: updatedOn},
 	}
-	plugin, err := impl.globalPluginRepository.GetPluginByName(bean.VULNERABILITY_SCANNING_PLUGIN)
-	if err != nil || len(plugin) == 0 {
+	scanPluginIds, err := impl.globalPluginRepository.GetPluginVersionIdsByName(bean.VULNERABILITY_SCANNING_PLUGIN)
+	if err != nil || len(scanPluginIds) == 0 {
 		impl.logger.Errorw("error in getting image scanning plugin", "err", err)
 		return 0, err
 	}
-	isScanPluginConfigured, err := impl.pipelineStageRepository.CheckPluginExistsInCiPipeline(pipeline.Id, string(repository2.PIPELINE_STAGE_TYPE_POST_CI), plugin[0].Id)
+	isScanPluginConfigured, err := impl.pipelineStageRepository.CheckPluginExistsInCiPipeline(pipeline.Id, string(repository2.PIPELINE_STAGE_TYPE_POST_CI), scanPluginIds)
 	if err != nil {
-		impl.logger.Errorw("error in getting ci pipeline plugin", "err", err, "pipelineId", pipeline.Id, "pluginId", plugin[0].Id)
+		impl.logger.Errorw("error in getting ci pipeline plugin", "err", err, "pipelineId", pipeline.Id, "pluginIds", scanPluginIds)
 		return 0, err
 	}
 	if pipeline.ScanEnabled || isScanPluginConfigured {
@@ -252,6 +278,10 @@ func (impl WebhookServiceImpl) HandleCiSuccessEvent(ciPipelineId int, request *C
 		impl.logger.Errorw("error in saving material", "err", err)
 		return 0, err
 	}
+	// the artifact is kept even if its provenance could not be generated
+	if err = impl.provenanceService.GenerateProvenance(buildArtifact); err != nil {
+		impl.logger.Errorw("error in generating provenance of artifact", "ciArtifactId", buildArtifact.Id, "err", err)
+	}
 
 	var pluginArtifacts []*repository.CiArtifact
 	for registry, artifacts := range request.PluginRegistryArtifactDetails {
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	errors3 "errors"
 	"fmt"
+	"net/http"
 	"path"
 	"strconv"
 	"strings"
@@ -44,10 +45,15 @@ import (
 	app2 "github.com/devtron-labs/devtron/internal/sql/repository/app"
 	bean4 "github.com/devtron-labs/devtron/pkg/app/bean"
 	"github.com/devtron-labs/devtron/pkg/app/status"
+	"github.com/devtron-labs/devtron/pkg/artifactPromotion"
 	"github.com/devtron-labs/devtron/pkg/auth/authorisation/casbin"
 	"github.com/devtron-labs/devtron/pkg/auth/user"
 	"github.com/devtron-labs/devtron/pkg/chartRepo/repository"
+	"github.com/devtron-labs/devtron/pkg/deploymentConcurrency"
+	repository6 "github.com/devtron-labs/devtron/pkg/deploymentConcurrency/repository"
+	"github.com/devtron-labs/devtron/pkg/deploymentWindow"
 	"github.com/devtron-labs/devtron/pkg/dockerRegistry"
+	"github.com/devtron-labs/devtron/pkg/imageSigning"
 	"github.com/devtron-labs/devtron/pkg/k8s"
 	bean3 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
 	repository4 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
@@ -92,6 +98,7 @@ import (
 	util2 "github.com/devtron-labs/devtron/util/event"
 	"github.com/devtron-labs/devtron/util/rbac"
 	"github.com/go-pg/pg"
+	slices2 "golang.org/x/exp/slices"
 	"go.uber.org/zap"
 )
 
@@ -106,6 +113,7 @@ type WorkflowDagExecutor interface {
 	TriggerPreStage(ctx context.Context, cdWf *pipelineConfig.CdWorkflow, artifact *repository.CiArtifact, pipeline *pipelineConfig.Pipeline, triggeredBy int32, refCdWorkflowRunnerId int) error
 	TriggerDeployment(cdWf *pipelineConfig.CdWorkflow, artifact *repository.CiArtifact, pipeline *pipelineConfig.Pipeline, triggeredBy int32) error
 	ManualCdTrigger(overrideRequest *bean.ValuesOverrideRequest, ctx context.Context) (int, error)
+	GetArtifactVulnerabilityStatus(artifact *repository.CiArtifact, cdPipeline *pipelineConfig.Pipeline, ctx context.Context) (bool, error)
 	TriggerBulkDeploymentAsync(requests []*BulkTriggerRequest, UserId int32) (interface{}, error)
 	StopStartApp(stopRequest *StopAppRequest, ctx context.Context) (int, error)
 	TriggerBulkHibernateAsync(request StopDeploymentGroupRequest, ctx context.Context) (interface{}, error)
@@ -115,6 +123,12 @@ type WorkflowDagExecutor interface {
 	OnDeleteCdPipelineEvent(pipelineId int, triggeredBy int32)
 	MarkPipelineStatusTimelineFailed(runner *pipelineConfig.CdWorkflowRunner, releaseErr error) error
 	UpdateTriggerCDMetricsOnFinish(runner *pipelineConfig.CdWorkflowRunner)
+	// BuildManifestForDryRun builds the merged values and chart a deploy trigger with overrideRequest would use, without
+	// saving pipeline override or any other trigger data
+	BuildManifestForDryRun(overrideRequest *bean.ValuesOverrideRequest, ctx context.Context) (*app.ValuesOverrideResponse, string, error)
+	// ReleaseQueuedDeployments triggers the deployments waiting in the deployment queue for which the concurrency
+	// limits of their environment and cluster have capacity now
+	ReleaseQueuedDeployments()
 }
 
 type WorkflowDagExecutorImpl struct {
@@ -199,6 +213,11 @@ type WorkflowDagExecutorImpl struct {
 	pipelineConfigListenerService       PipelineConfigListenerService
 	customTagService                    CustomTagService
 	ACDConfig                           *argocdServer.ACDConfig
+	deploymentWindowService             deploymentWindow.DeploymentWindowService
+	deploymentApprovalService           DeploymentApprovalService
+	artifactPromotionPolicyService      artifactPromotion.ArtifactPromotionPolicyService
+	deploymentConcurrencyService        deploymentConcurrency.DeploymentConcurrencyService
+	imageSigningService                 imageSigning.ImageSigningService
 }
 
 const kedaAutoscaling = "kedaAutoscaling"
@@ -307,6 +326,11 @@ func NewWorkflowDagExecutorImpl(Logger *zap.SugaredLogger, pipelineRepository pi
 	pipelineConfigListenerService PipelineConfigListenerService,
 	customTagService CustomTagService,
 	ACDConfig *argocdServer.ACDConfig,
+	deploymentWindowService deploymentWindow.DeploymentWindowService,
+	deploymentApprovalService DeploymentApprovalService,
+	artifactPromotionPolicyService artifactPromotion.ArtifactPromotionPolicyService,
+	deploymentConcurrencyService deploymentConcurrency.DeploymentConcurrencyService,
+	imageSigningService imageSigning.ImageSigningService,
 ) *WorkflowDagExecutorImpl {
 	wde := &WorkflowDagExecutorImpl{logger: Logger,
 		pipelineRepository:            pipelineRepository,
@@ -384,6 +408,11 @@ func NewWorkflowDagExecutorImpl(Logger *zap.SugaredLogger, pipelineRepository pi
 		pipelineConfigListenerService:       pipelineConfigListenerService,
 		customTagService:                    customTagService,
 		ACDConfig:                           ACDConfig,
+		deploymentWindowService:             deploymentWindowService,
+		deploymentApprovalService:           deploymentApprovalService,
+		artifactPromotionPolicyService:      artifactPromotionPolicyService,
+		deploymentConcurrencyService:        deploymentConcurrencyService,
+		imageSigningService:                 imageSigningService,
 	}
 	config, err := types.GetCdConfig()
 	if err != nil {
@@ -860,6 +889,10 @@ func (impl *WorkflowDagExecutorImpl) triggerIfAutoStageCdPipeline(cdWf *pipeline
 	if len(pipeline.PreStageConfig) > 0 || (preStage != nil && !deleted) {
 		// pre stage exists
 		if pipeline.PreTriggerType == pipelineConfig.TRIGGER_TYPE_AUTOMATIC {
+			isBlocked, err := impl.blockPreStageOutsideDeploymentWindow(cdWf, artifact, pipeline, triggeredBy)
+			if err != nil || isBlocked {
+				return err
+			}
 			impl.logger.Debugw("trigger pre stage for pipeline", "artifactId", artifact.Id, "pipelineId", pipeline.Id)
 			err = impl.TriggerPreStage(context.Background(), cdWf, artifact, pipeline, artifact.UpdatedBy, 0) //TODO handle error here
 			return err
@@ -873,6 +906,33 @@ func (impl *WorkflowDagExecutorImpl) triggerIfAutoStageCdPipeline(cdWf *pipeline
 	return nil
 }
 
+// blockPreStageOutsideDeploymentWindow records the pre stage of an auto triggered deployment as blocked if deployment
+// windows do not allow deploying the pipeline now, neither the pre stage nor the deployment runs then
+func (impl *WorkflowDagExecutorImpl) blockPreStageOutsideDeploymentWindow(cdWf *pipelineConfig.CdWorkflow, artifact *repository.CiArtifact, pipeline *pipelineConfig.Pipeline, triggeredBy int32) (bool, error) {
+	triggeredAt := time.Now()
+	windowState, err := impl.deploymentWindowService.GetDeploymentWindowState(pipeline.AppId, pipeline.EnvironmentId, triggeredAt)
+	if err != nil {
+		impl.logger.Errorw("error in evaluating deployment windows for pre stage", "pipelineId", pipeline.Id, "err", err)
+		return false, err
+	}
+	if windowState.IsAllowed {
+		return false, nil
+	}
+	if cdWf == nil {
+		cdWf = &pipelineConfig.CdWorkflow{
+			CiArtifactId: artifact.Id,
+			PipelineId:   pipeline.Id,
+			AuditLog:     sql.AuditLog{CreatedOn: triggeredAt, CreatedBy: 1, UpdatedOn: triggeredAt, UpdatedBy: 1},
+		}
+		err = impl.cdWorkflowRepository.SaveWorkFlow(context.Background(), cdWf)
+		if err != nil {
+			return false, err
+		}
+	}
+	impl.logger.Infow("pre stage blocked by deployment window", "pipelineId", pipeline.Id, "artifactId", artifact.Id, "reason", windowState.Reason)
+	return true, impl.markDeploymentBlockedByWindow(cdWf.Id, bean.CD_WORKFLOW_TYPE_PRE, pipeline, windowState, pipelineConfig.WORKFLOW_EXECUTOR_TYPE_SYSTEM, triggeredBy, triggeredAt)
+}
+
 func (impl *WorkflowDagExecutorImpl) getPipelineStage(pipelineId int, stageType repository4.PipelineStageType) (*repository4.PipelineStage, error) {
 	stage, err := impl.pipelineStageService.GetCdStageByCdPipelineIdAndStageType(pipelineId, stageType)
 	if err != nil && err != pg.ErrNoRows {
@@ -1108,14 +1168,14 @@ func (impl *WorkflowDagExecutorImpl) TriggerPreStage(ctx context.Context, cdWf *
 }
 
 func (impl *WorkflowDagExecutorImpl) SetCopyContainerImagePluginDataInWorkflowRequest(cdStageWorkflowRequest *types.WorkflowRequest, pipelineId int, pipelineStage string, artifact *repository.CiArtifact) ([]int, error) {
-	copyContainerImagePluginId, err := impl.globalPluginService.GetRefPluginIdByRefPluginName(COPY_CONTAINER_IMAGE)
+	copyContainerImagePluginIds, err := impl.globalPluginService.GetRefPluginIdsByRefPluginName(COPY_CONTAINER_IMAGE)
 	var imagePathReservationIds []int
 	if err != nil && err != pg.ErrNoRows {
 		impl.logger.Errorw("error in getting copyContainerImage plugin id", "err", err)
 		return imagePathReservationIds, err
 	}
 	for _, step := range cdStageWorkflowRequest.PrePostDeploySteps {
-		if copyContainerImagePluginId != 0 && step.RefPluginId == copyContainerImagePluginId {
+		if slices2.Contains(copyContainerImagePluginIds, step.RefPluginId) {
 			var pipelineStageEntityType int
 			if pipelineStage == types.PRE {
 				pipelineStageEntityType = bean3.EntityTypePreCD
@@ -1969,16 +2029,39 @@ func (impl *WorkflowDagExecutorImpl) TriggerDeployment(cdWf *pipelineConfig.CdWo
 		}
 	}
 
+	// auto triggers cannot override deployment windows, blocked deployment is recorded and skipped
+	windowState, err := impl.deploymentWindowService.GetDeploymentWindowState(pipeline.AppId, pipeline.EnvironmentId, triggeredAt)
+	if err != nil {
+		impl.logger.Errorw("error in evaluating deployment windows, TriggerDeployment", "pipelineId", pipeline.Id, "err", err)
+		return err
+	}
+	if !windowState.IsAllowed {
+		impl.logger.Infow("deployment blocked by deployment window, TriggerDeployment", "pipelineId", pipeline.Id, "artifactId", artifact.Id, "reason", windowState.Reason)
+		return impl.markDeploymentBlockedByWindow(cdWf.Id, bean.CD_WORKFLOW_TYPE_DEPLOY, pipeline, windowState, pipelineConfig.WORKFLOW_EXECUTOR_TYPE_SYSTEM, triggeredBy, triggeredAt)
+	}
+
+	approvalRequestId, approvalErr := impl.deploymentApprovalService.ValidateApprovalForDeployment(pipeline, artifact.Id)
+	if _, isApiError := approvalErr.(*util.ApiError); approvalErr != nil && !isApiError {
+		impl.logger.Errorw("error in validating deployment approval, TriggerDeployment", "pipelineId", pipeline.Id, "artifactId", artifact.Id, "err", approvalErr)
+		return approvalErr
+	}
+	promotionErr := impl.artifactPromotionPolicyService.ValidatePromotionForDeployment(pipeline, artifact.Id)
+	if _, isApiError := promotionErr.(*util.ApiError); promotionErr != nil && !isApiError {
+		impl.logger.Errorw("error in validating artifact promotion policies, TriggerDeployment", "pipelineId", pipeline.Id, "artifactId", artifact.Id, "err", promotionErr)
+		return promotionErr
+	}
+
 	runner := &pipelineConfig.CdWorkflowRunner{
-		Name:         pipeline.Name,
-		WorkflowType: bean.CD_WORKFLOW_TYPE_DEPLOY,
-		ExecutorType: pipelineConfig.WORKFLOW_EXECUTOR_TYPE_SYSTEM,
-		Status:       pipelineConfig.WorkflowInitiated, //deployment Initiated for auto trigger
-		TriggeredBy:  1,
-		StartedOn:    triggeredAt,
-		Namespace:    impl.config.GetDefaultNamespace(),
-		CdWorkflowId: cdWf.Id,
-		AuditLog:     sql.AuditLog{CreatedOn: triggeredAt, CreatedBy: triggeredBy, UpdatedOn: triggeredAt, UpdatedBy: triggeredBy},
+		Name:                        pipeline.Name,
+		WorkflowType:                bean.CD_WORKFLOW_TYPE_DEPLOY,
+		ExecutorType:                pipelineConfig.WORKFLOW_EXECUTOR_TYPE_SYSTEM,
+		Status:                      pipelineConfig.WorkflowInitiated, //deployment Initiated for auto trigger
+		TriggeredBy:                 1,
+		StartedOn:                   triggeredAt,
+		Namespace:                   impl.config.GetDefaultNamespace(),
+		CdWorkflowId:                cdWf.Id,
+		DeploymentApprovalRequestId: approvalRequestId,
+		AuditLog:                    sql.AuditLog{CreatedOn: triggeredAt, CreatedBy: triggeredBy, UpdatedOn: triggeredAt, UpdatedBy: triggeredBy},
 	}
 	savedWfr, err := impl.cdWorkflowRepository.SaveWorkFlowRunner(runner)
 	if err != nil {
@@ -2005,6 +2088,20 @@ func (impl *WorkflowDagExecutorImpl) TriggerDeployment(cdWf *pipelineConfig.CdWo
 	if err != nil {
 		impl.logger.Errorw("error in creating timeline status for deployment initiation", "err", err, "timeline", timeline)
 	}
+	if approvalErr != nil {
+		// pipeline needs approval and image is not approved yet, auto trigger is marked failed with the reason
+		if err = impl.MarkCurrentDeploymentFailed(runner, approvalErr, triggeredBy); err != nil {
+			impl.logger.Errorw("error while updating current runner status to failed, TriggerDeployment", "wfrId", runner.Id, "err", err)
+		}
+		return nil
+	}
+	if promotionErr != nil {
+		// artifact has not met the promotion policies of the environment yet, auto trigger is marked failed with the reason
+		if err = impl.MarkCurrentDeploymentFailed(runner, promotionErr, triggeredBy); err != nil {
+			impl.logger.Errorw("error while updating current runner status to failed, TriggerDeployment", "wfrId", runner.Id, "err", err)
+		}
+		return nil
+	}
 	//checking vulnerability for deploying image
 	isVulnerable := false
 	if len(artifact.ImageDigest) > 0 {
@@ -2037,6 +2134,37 @@ func (impl *WorkflowDagExecutorImpl) TriggerDeployment(cdWf *pipelineConfig.CdWo
 		}
 		return nil
 	}
+	signatureErr := impl.verifyImageSignature(runner, artifact, pipeline, triggeredBy)
+	if _, isApiError := signatureErr.(*util.ApiError); signatureErr != nil && !isApiError {
+		impl.logger.Errorw("error in verifying image signature, TriggerDeployment", "pipelineId", pipeline.Id, "artifactId", artifact.Id, "err", signatureErr)
+		return signatureErr
+	} else if signatureErr != nil {
+		// image is not signed by a key trusted on the environment, auto trigger is marked failed with the reason
+		return nil
+	}
+	cdPipeline, err := impl.pipelineRepository.FindById(pipeline.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching pipeline by pipelineId, TriggerDeployment", "pipelineId", pipeline.Id, "err", err)
+		return err
+	}
+	isQueued, err := impl.queueDeploymentIfConcurrencyLimitReached(impl.buildAutoTriggerOverrideRequest(cdPipeline, artifact, cdWf.Id, savedWfr.Id), runner, triggeredBy)
+	if err != nil {
+		if err1 := impl.MarkCurrentDeploymentFailed(runner, err, triggeredBy); err1 != nil {
+			impl.logger.Errorw("error while updating current runner status to failed, TriggerDeployment", "wfrId", runner.Id, "err", err1)
+		}
+		return err
+	}
+	if isQueued {
+		// approval is consumed once the deployment is released from the queue
+		return nil
+	}
+	if approvalRequestId > 0 {
+		err = impl.deploymentApprovalService.MarkApprovalRequestConsumed(approvalRequestId, triggeredBy)
+		if err != nil {
+			impl.logger.Errorw("error in consuming deployment approval, TriggerDeployment", "approvalRequestId", approvalRequestId, "err", err)
+			return err
+		}
+	}
 
 	releaseErr := impl.TriggerCD(artifact, cdWf.Id, savedWfr.Id, pipeline, triggeredAt)
 	//skip updatePreviousDeploymentStatus if Async Install is enabled; handled inside SubscribeDevtronAsyncHelmInstallRequest
@@ -2050,6 +2178,270 @@ func (impl *WorkflowDagExecutorImpl) TriggerDeployment(cdWf *pipelineConfig.CdWo
 	return nil
 }
 
+// markDeploymentBlockedByWindow records a deployment rejected by deployment windows as a failed runner, so that the
+// blocked attempt and its reason are visible in deployment history and timeline
+func (impl *WorkflowDagExecutorImpl) markDeploymentBlockedByWindow(cdWorkflowId int, workflowType bean.WorkflowType, pipeline *pipelineConfig.Pipeline,
+	windowState *deploymentWindow.DeploymentWindowState, executorType pipelineConfig.WorkflowExecutorType, triggeredBy int32, triggeredAt time.Time) error {
+	runner := &pipelineConfig.CdWorkflowRunner{
+		Name:         pipeline.Name,
+		WorkflowType: workflowType,
+		ExecutorType: executorType,
+		Status:       pipelineConfig.WorkflowFailed,
+		Message:      windowState.Reason,
+		TriggeredBy:  triggeredBy,
+		StartedOn:    triggeredAt,
+		FinishedOn:   triggeredAt,
+		Namespace:    impl.config.GetDefaultNamespace(),
+		CdWorkflowId: cdWorkflowId,
+		AuditLog:     sql.AuditLog{CreatedOn: triggeredAt, CreatedBy: triggeredBy, UpdatedOn: triggeredAt, UpdatedBy: triggeredBy},
+	}
+	_, err := impl.cdWorkflowRepository.SaveWorkFlowRunner(runner)
+	if err != nil {
+		impl.logger.Errorw("error in saving blocked cd workflow runner", "cdWorkflowId", cdWorkflowId, "err", err)
+		return err
+	}
+	timeline := impl.pipelineStatusTimelineService.GetTimelineDbObjectByTimelineStatusAndTimelineDescription(runner.Id, 0, pipelineConfig.TIMELINE_STATUS_DEPLOYMENT_BLOCKED, windowState.Reason, triggeredBy, time.Now())
+	err = impl.pipelineStatusTimelineService.SaveTimeline(timeline, nil, false)
+	if err != nil {
+		impl.logger.Errorw("error in creating timeline status for blocked deployment", "err", err, "timeline", timeline)
+		return err
+	}
+	return nil
+}
+
+// verifyImageSignature enforces the image signature policy of the environment of pipeline, the outcome is recorded in
+// the timeline of runner and the deployment is marked failed with a forbidden ApiError if the image is not signed by
+// a trusted key
+func (impl *WorkflowDagExecutorImpl) verifyImageSignature(runner *pipelineConfig.CdWorkflowRunner, artifact *repository.CiArtifact, pipeline *pipelineConfig.Pipeline, triggeredBy int32) error {
+	result, err := impl.imageSigningService.VerifyArtifactForDeployment(artifact, pipeline, triggeredBy)
+	if err != nil {
+		if err1 := impl.MarkCurrentDeploymentFailed(runner, err, triggeredBy); err1 != nil {
+			impl.logger.Errorw("error while updating current runner status to failed", "wfrId", runner.Id, "err", err1)
+		}
+		return err
+	}
+	if result == nil {
+		// no signature policy on the environment
+		return nil
+	}
+	timelineStatus := pipelineConfig.TIMELINE_STATUS_IMAGE_SIGNATURE_VERIFIED
+	if !result.Verified {
+		timelineStatus = pipelineConfig.TIMELINE_STATUS_IMAGE_SIGNATURE_VERIFICATION_FAILED
+	}
+	timeline := impl.pipelineStatusTimelineService.GetTimelineDbObjectByTimelineStatusAndTimelineDescription(runner.Id, 0, timelineStatus,
+		util.GetTruncatedMessage(fmt.Sprintf("Image signature verification: %s", result.Message), 255), triggeredBy, time.Now())
+	err = impl.pipelineStatusTimelineService.SaveTimeline(timeline, nil, false)
+	if err != nil {
+		impl.logger.Errorw("error in creating timeline status for image signature verification", "err", err, "timeline", timeline)
+	}
+	if result.Verified {
+		return nil
+	}
+	message := fmt.Sprintf("image signature verification failed: %s", result.Message)
+	if err = impl.MarkCurrentDeploymentFailed(runner, errors.New(message), triggeredBy); err != nil {
+		impl.logger.Errorw("error while updating current runner status to failed", "wfrId", runner.Id, "err", err)
+	}
+	return &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: message, UserMessage: message}
+}
+
+// auditDeploymentWindowOverride saves the justification given by super admin for deploying outside of allowed windows
+func (impl *WorkflowDagExecutorImpl) auditDeploymentWindowOverride(overrideRequest *bean.ValuesOverrideRequest, windowState *deploymentWindow.DeploymentWindowState) error {
+	err := impl.deploymentWindowService.SaveOverrideAudit(&deploymentWindow.DeploymentWindowOverrideRequest{
+		PipelineId:         overrideRequest.PipelineId,
+		CiArtifactId:       overrideRequest.CiArtifactId,
+		CdWorkflowRunnerId: overrideRequest.WfrId,
+		Justification:      overrideRequest.DeploymentWindowOverrideReason,
+		State:              windowState,
+		UserId:             overrideRequest.UserId,
+	})
+	if err != nil {
+		impl.logger.Errorw("error in saving deployment window override audit", "pipelineId", overrideRequest.PipelineId, "wfrId", overrideRequest.WfrId, "err", err)
+		return err
+	}
+	timelineDescription := fmt.Sprintf("%s. Overridden by super admin: %s", windowState.Reason, overrideRequest.DeploymentWindowOverrideReason)
+	timeline := impl.pipelineStatusTimelineService.GetTimelineDbObjectByTimelineStatusAndTimelineDescription(overrideRequest.WfrId, 0, pipelineConfig.TIMELINE_STATUS_WINDOW_OVERRIDDEN, timelineDescription, overrideRequest.UserId, time.Now())
+	err = impl.pipelineStatusTimelineService.SaveTimeline(timeline, nil, false)
+	if err != nil {
+		impl.logger.Errorw("error in creating timeline status for deployment window override", "err", err, "timeline", timeline)
+	}
+	return nil
+}
+
+// queueDeploymentIfConcurrencyLimitReached holds the deployment of runner back in the deployment queue if the concurrency
+// limits of its environment or cluster are reached, the runner is then marked queued with its position in the timeline
+func (impl *WorkflowDagExecutorImpl) queueDeploymentIfConcurrencyLimitReached(overrideRequest *bean.ValuesOverrideRequest, runner *pipelineConfig.CdWorkflowRunner, triggeredBy int32) (bool, error) {
+	if overrideRequest.DeploymentAppType == util.PIPELINE_DEPLOYMENT_TYPE_MANIFEST_DOWNLOAD {
+		// nothing is applied on the cluster for manifest download
+		return false, nil
+	}
+	queuePosition, err := impl.deploymentConcurrencyService.QueueDeploymentIfLimitReached(overrideRequest, triggeredBy)
+	if err != nil {
+		impl.logger.Errorw("error in checking deployment concurrency limits", "pipelineId", overrideRequest.PipelineId, "wfrId", runner.Id, "err", err)
+		return false, err
+	}
+	if queuePosition == 0 {
+		return false, nil
+	}
+	runner.Status = pipelineConfig.WorkflowInQueue
+	runner.UpdatedOn = time.Now()
+	runner.UpdatedBy = triggeredBy
+	err = impl.cdWorkflowRepository.UpdateWorkFlowRunner(runner)
+	if err != nil {
+		impl.logger.Errorw("error in updating queued cd workflow runner", "wfrId", runner.Id, "err", err)
+		return false, err
+	}
+	statusDetail := fmt.Sprintf("Deployment queued at position %d, concurrent deployment limit of the environment is reached.", queuePosition)
+	timeline := impl.pipelineStatusTimelineService.GetTimelineDbObjectByTimelineStatusAndTimelineDescription(runner.Id, 0, pipelineConfig.TIMELINE_STATUS_DEPLOYMENT_QUEUED, statusDetail, triggeredBy, time.Now())
+	err = impl.pipelineStatusTimelineService.SaveTimeline(timeline, nil, false)
+	if err != nil {
+		impl.logger.Errorw("error in creating timeline status for queued deployment", "timeline", timeline, "err", err)
+	}
+	return true, nil
+}
+
+func (impl *WorkflowDagExecutorImpl) ReleaseQueuedDeployments() {
+	queuedDeployments, err := impl.deploymentConcurrencyService.ReleaseDeployments()
+	if err != nil {
+		impl.logger.Errorw("error in releasing deployments from queue", "err", err)
+		return
+	}
+	for _, queuedDeployment := range queuedDeployments {
+		err = impl.releaseQueuedDeployment(queuedDeployment)
+		if err != nil {
+			impl.logger.Errorw("error in releasing queued deployment", "id", queuedDeployment.Id, "wfrId", queuedDeployment.CdWorkflowRunnerId, "err", err)
+		}
+	}
+}
+
+func (impl *WorkflowDagExecutorImpl) releaseQueuedDeployment(queuedDeployment *repository6.DeploymentQueue) error {
+	runner, err := impl.cdWorkflowRepository.FindWorkflowRunnerById(queuedDeployment.CdWorkflowRunnerId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cd workflow runner of queued deployment", "wfrId", queuedDeployment.CdWorkflowRunnerId, "err", err)
+		return err
+	}
+	if slices.Contains(pipelineConfig.WfrTerminalStatusList, runner.Status) {
+		// superseded by a newer deployment of the pipeline while waiting in queue
+		return impl.deploymentConcurrencyService.MarkDropped(queuedDeployment.Id, fmt.Sprintf("deployment is already %s", runner.Status))
+	}
+	triggeredBy := queuedDeployment.TriggeredBy
+	cdPipeline, err := impl.pipelineRepository.FindById(queuedDeployment.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching pipeline of queued deployment", "pipelineId", queuedDeployment.PipelineId, "err", err)
+		return impl.MarkCurrentDeploymentFailed(runner, err, triggeredBy)
+	}
+	runner.CdWorkflow = &pipelineConfig.CdWorkflow{
+		Pipeline: cdPipeline,
+	}
+	overrideRequest := &bean.ValuesOverrideRequest{}
+	err = json.Unmarshal([]byte(queuedDeployment.ValuesOverrideRequest), overrideRequest)
+	if err != nil {
+		impl.logger.Errorw("error in unmarshalling values override request of queued deployment", "id", queuedDeployment.Id, "err", err)
+		return impl.MarkCurrentDeploymentFailed(runner, err, triggeredBy)
+	}
+	// fields not serialised in the queue are restored from the runner and pipeline
+	overrideRequest.UserId = triggeredBy
+	overrideRequest.IsAutoRollback = runner.TriggerType == pipelineConfig.CD_TRIGGER_TYPE_AUTO_ROLLBACK
+	impl.SetPipelineFieldsInOverrideRequest(overrideRequest, cdPipeline)
+
+	triggeredAt := time.Now()
+	// the deployment waited in queue, so the gates it passed on trigger are evaluated again on release
+	approvalRequestId, err := impl.validateTriggerGatesOnRelease(runner, overrideRequest, cdPipeline, triggeredAt)
+	if err != nil {
+		return err
+	}
+	runner.Status = pipelineConfig.WorkflowInitiated
+	runner.DeploymentApprovalRequestId = approvalRequestId
+	runner.UpdatedOn = triggeredAt
+	runner.UpdatedBy = triggeredBy
+	err = impl.cdWorkflowRepository.UpdateWorkFlowRunner(runner)
+	if err != nil {
+		impl.logger.Errorw("error in updating cd workflow runner released from queue", "wfrId", runner.Id, "err", err)
+		return err
+	}
+	if approvalRequestId > 0 {
+		err = impl.deploymentApprovalService.MarkApprovalRequestConsumed(approvalRequestId, triggeredBy)
+		if err != nil {
+			impl.logger.Errorw("error in consuming deployment approval of queued deployment", "approvalRequestId", approvalRequestId, "err", err)
+			return impl.MarkCurrentDeploymentFailed(runner, err, triggeredBy)
+		}
+	}
+	timeline := impl.pipelineStatusTimelineService.GetTimelineDbObjectByTimelineStatusAndTimelineDescription(runner.Id, 0, pipelineConfig.TIMELINE_STATUS_DEPLOYMENT_DEQUEUED, pipelineConfig.TIMELINE_DESCRIPTION_DEPLOYMENT_DEQUEUED, triggeredBy, triggeredAt)
+	err = impl.pipelineStatusTimelineService.SaveTimeline(timeline, nil, false)
+	if err != nil {
+		impl.logger.Errorw("error in creating timeline status for deployment released from queue", "timeline", timeline, "err", err)
+	}
+
+	ctx := context.Background()
+	if util.IsAcdApp(cdPipeline.DeploymentAppType) {
+		ctx, err = impl.buildACDContext()
+		if err != nil {
+			return impl.MarkCurrentDeploymentFailed(runner, err, triggeredBy)
+		}
+	}
+	_, _, releaseErr := impl.HandleCDTriggerRelease(overrideRequest, ctx, triggeredAt, triggeredBy)
+	//skip updatePreviousDeploymentStatus if Async Install is enabled; handled inside SubscribeDevtronAsyncHelmInstallRequest
+	if !impl.appService.IsDevtronAsyncInstallModeEnabled(cdPipeline.DeploymentAppType) {
+		return impl.updatePreviousDeploymentStatus(releaseErr, runner, cdPipeline.Id, triggeredAt, triggeredBy)
+	}
+	return releaseErr
+}
+
+// validateTriggerGatesOnRelease evaluates the deployment window, approval, promotion and image signature gates for a
+// deployment released from queue, runner is marked failed if any of them does not allow the deployment anymore. It
+// returns the approval request to be consumed by the deployment.
+func (impl *WorkflowDagExecutorImpl) validateTriggerGatesOnRelease(runner *pipelineConfig.CdWorkflowRunner, overrideRequest *bean.ValuesOverrideRequest,
+	cdPipeline *pipelineConfig.Pipeline, releasedAt time.Time) (int, error) {
+	triggeredBy := overrideRequest.UserId
+	windowState, err := impl.deploymentWindowService.GetDeploymentWindowState(cdPipeline.AppId, cdPipeline.EnvironmentId, releasedAt)
+	if err != nil {
+		impl.logger.Errorw("error in evaluating deployment windows of queued deployment", "pipelineId", cdPipeline.Id, "err", err)
+		if err1 := impl.MarkCurrentDeploymentFailed(runner, err, triggeredBy); err1 != nil {
+			impl.logger.Errorw("error while updating queued runner status to failed", "wfrId", runner.Id, "err", err1)
+		}
+		return 0, err
+	}
+	if !windowState.IsAllowed && !overrideRequest.DeploymentWindowOverride {
+		timeline := impl.pipelineStatusTimelineService.GetTimelineDbObjectByTimelineStatusAndTimelineDescription(runner.Id, 0, pipelineConfig.TIMELINE_STATUS_DEPLOYMENT_BLOCKED, windowState.Reason, triggeredBy, releasedAt)
+		err = impl.pipelineStatusTimelineService.SaveTimeline(timeline, nil, false)
+		if err != nil {
+			impl.logger.Errorw("error in creating timeline status for blocked deployment", "err", err, "timeline", timeline)
+		}
+		windowErr := &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: windowState.Reason, UserMessage: windowState.Reason}
+		if err = impl.MarkCurrentDeploymentFailed(runner, windowErr, triggeredBy); err != nil {
+			impl.logger.Errorw("error while updating queued runner status to failed", "wfrId", runner.Id, "err", err)
+		}
+		return 0, windowErr
+	}
+	approvalRequestId := 0
+	if !overrideRequest.IsAutoRollback {
+		approvalRequestId, err = impl.deploymentApprovalService.ValidateApprovalForDeployment(cdPipeline, overrideRequest.CiArtifactId)
+		if err == nil {
+			err = impl.artifactPromotionPolicyService.ValidatePromotionForDeployment(cdPipeline, overrideRequest.CiArtifactId)
+		}
+		if err != nil {
+			impl.logger.Errorw("queued deployment not allowed anymore", "pipelineId", cdPipeline.Id, "artifactId", overrideRequest.CiArtifactId, "err", err)
+			if err1 := impl.MarkCurrentDeploymentFailed(runner, err, triggeredBy); err1 != nil {
+				impl.logger.Errorw("error while updating queued runner status to failed", "wfrId", runner.Id, "err", err1)
+			}
+			return 0, err
+		}
+	}
+	artifact, err := impl.ciArtifactRepository.Get(overrideRequest.CiArtifactId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching artifact of queued deployment", "artifactId", overrideRequest.CiArtifactId, "err", err)
+		if err1 := impl.MarkCurrentDeploymentFailed(runner, err, triggeredBy); err1 != nil {
+			impl.logger.Errorw("error while updating queued runner status to failed", "wfrId", runner.Id, "err", err1)
+		}
+		return 0, err
+	}
+	// runner is marked failed by signature verification itself
+	err = impl.verifyImageSignature(runner, artifact, cdPipeline, triggeredBy)
+	if err != nil {
+		return 0, err
+	}
+	return approvalRequestId, nil
+}
+
 func (impl *WorkflowDagExecutorImpl) updatePreviousDeploymentStatus(releaseErr error, currentRunner *pipelineConfig.CdWorkflowRunner, pipelineId int, triggeredAt time.Time, triggeredBy int32) error {
 	// if releaseErr found, then the mark current deployment Failed and return
 	if releaseErr != nil {
@@ -2290,13 +2682,49 @@ func (impl *WorkflowDagExecutorImpl) ManualCdTrigger(overrideRequest *bean.Value
 			impl.logger.Errorw("error in getting CiArtifact", "CiArtifactId", overrideRequest.CiArtifactId, "err", err)
 			return 0, err
 		}
+		cdWf := &pipelineConfig.CdWorkflow{
+			CiArtifactId: artifact.Id,
+			PipelineId:   cdPipeline.Id,
+			AuditLog:     sql.AuditLog{CreatedOn: triggeredAt, CreatedBy: overrideRequest.UserId, UpdatedOn: triggeredAt, UpdatedBy: overrideRequest.UserId},
+		}
+		err = impl.cdWorkflowRepository.SaveWorkFlow(ctx, cdWf)
+		if err != nil {
+			impl.logger.Errorw("error in creating cdWorkflow, ManualCdTrigger", "PipelineId", overrideRequest.PipelineId, "err", err)
+			return 0, err
+		}
+		// pre stage is the start of the deployment, it is held to the deployment windows too
+		windowState, err := impl.deploymentWindowService.GetDeploymentWindowState(cdPipeline.AppId, cdPipeline.EnvironmentId, triggeredAt)
+		if err != nil {
+			impl.logger.Errorw("error in evaluating deployment windows, ManualCdTrigger", "pipelineId", cdPipeline.Id, "err", err)
+			return 0, err
+		}
+		if !windowState.IsAllowed && !overrideRequest.DeploymentWindowOverride {
+			err = impl.markDeploymentBlockedByWindow(cdWf.Id, bean.CD_WORKFLOW_TYPE_PRE, cdPipeline, windowState, pipelineConfig.WORKFLOW_EXECUTOR_TYPE_AWF, overrideRequest.UserId, triggeredAt)
+			if err != nil {
+				impl.logger.Errorw("error in recording blocked pre stage, ManualCdTrigger", "pipelineId", cdPipeline.Id, "err", err)
+				return 0, err
+			}
+			return 0, &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: windowState.Reason, UserMessage: windowState.Reason}
+		}
 		_, span = otel.Tracer("orchestrator").Start(ctx, "TriggerPreStage")
-		err = impl.TriggerPreStage(ctx, nil, artifact, cdPipeline, overrideRequest.UserId, 0)
+		err = impl.TriggerPreStage(ctx, cdWf, artifact, cdPipeline, overrideRequest.UserId, 0)
 		span.End()
 		if err != nil {
 			impl.logger.Errorw("error in TriggerPreStage, ManualCdTrigger", "err", err)
 			return 0, err
 		}
+		if !windowState.IsAllowed {
+			preStageRunner, err := impl.cdWorkflowRepository.FindByWorkflowIdAndRunnerType(ctx, cdWf.Id, bean.CD_WORKFLOW_TYPE_PRE)
+			if err != nil {
+				impl.logger.Errorw("error in getting pre stage runner, ManualCdTrigger", "cdWorkflowId", cdWf.Id, "err", err)
+				return 0, err
+			}
+			overrideRequest.WfrId = preStageRunner.Id
+			err = impl.auditDeploymentWindowOverride(overrideRequest, windowState)
+			if err != nil {
+				return 0, err
+			}
+		}
 	case bean.CD_WORKFLOW_TYPE_DEPLOY:
 		if overrideRequest.DeploymentType == models.DEPLOYMENTTYPE_UNKNOWN {
 			overrideRequest.DeploymentType = models.DEPLOYMENTTYPE_DEPLOY
@@ -2323,16 +2751,55 @@ func (impl *WorkflowDagExecutorImpl) ManualCdTrigger(overrideRequest *bean.Value
 			cdWorkflowId = cdWf.Id
 		}
 
+		windowState, err := impl.deploymentWindowService.GetDeploymentWindowState(cdPipeline.AppId, cdPipeline.EnvironmentId, triggeredAt)
+		if err != nil {
+			impl.logger.Errorw("error in evaluating deployment windows, ManualCdTrigger", "pipelineId", cdPipeline.Id, "err", err)
+			return 0, err
+		}
+		isWindowOverridden := false
+		if !windowState.IsAllowed {
+			// super admin access and justification for override is validated at the rest layer
+			if !overrideRequest.DeploymentWindowOverride {
+				err = impl.markDeploymentBlockedByWindow(cdWorkflowId, bean.CD_WORKFLOW_TYPE_DEPLOY, cdPipeline, windowState, pipelineConfig.WORKFLOW_EXECUTOR_TYPE_AWF, overrideRequest.UserId, triggeredAt)
+				if err != nil {
+					impl.logger.Errorw("error in recording blocked deployment, ManualCdTrigger", "pipelineId", cdPipeline.Id, "err", err)
+					return 0, err
+				}
+				return 0, &util.ApiError{HttpStatusCode: http.StatusForbidden, InternalMessage: windowState.Reason, UserMessage: windowState.Reason}
+			}
+			isWindowOverridden = true
+		}
+
+		approvalRequestId := 0
+		triggerType := ""
+		if overrideRequest.IsAutoRollback {
+			// auto rollback redeploys the last healthy release, approval is not sought again for it
+			triggerType = pipelineConfig.CD_TRIGGER_TYPE_AUTO_ROLLBACK
+		} else {
+			approvalRequestId, err = impl.deploymentApprovalService.ValidateApprovalForDeployment(cdPipeline, overrideRequest.CiArtifactId)
+			if err != nil {
+				impl.logger.Errorw("deployment approval validation failed, ManualCdTrigger", "pipelineId", cdPipeline.Id, "artifactId", overrideRequest.CiArtifactId, "err", err)
+				return 0, err
+			}
+			err = impl.artifactPromotionPolicyService.ValidatePromotionForDeployment(cdPipeline, overrideRequest.CiArtifactId)
+			if err != nil {
+				impl.logger.Errorw("artifact promotion policy validation failed, ManualCdTrigger", "pipelineId", cdPipeline.Id, "artifactId", overrideRequest.CiArtifactId, "err", err)
+				return 0, err
+			}
+		}
+
 		runner := &pipelineConfig.CdWorkflowRunner{
-			Name:         cdPipeline.Name,
-			WorkflowType: bean.CD_WORKFLOW_TYPE_DEPLOY,
-			ExecutorType: pipelineConfig.WORKFLOW_EXECUTOR_TYPE_AWF,
-			Status:       pipelineConfig.WorkflowInitiated, //deployment Initiated for manual trigger
-			TriggeredBy:  overrideRequest.UserId,
-			StartedOn:    triggeredAt,
-			Namespace:    impl.config.GetDefaultNamespace(),
-			CdWorkflowId: cdWorkflowId,
-			AuditLog:     sql.AuditLog{CreatedOn: triggeredAt, CreatedBy: overrideRequest.UserId, UpdatedOn: triggeredAt, UpdatedBy: overrideRequest.UserId},
+			Name:                        cdPipeline.Name,
+			WorkflowType:                bean.CD_WORKFLOW_TYPE_DEPLOY,
+			ExecutorType:                pipelineConfig.WORKFLOW_EXECUTOR_TYPE_AWF,
+			Status:                      pipelineConfig.WorkflowInitiated, //deployment Initiated for manual trigger
+			TriggeredBy:                 overrideRequest.UserId,
+			StartedOn:                   triggeredAt,
+			Namespace:                   impl.config.GetDefaultNamespace(),
+			CdWorkflowId:                cdWorkflowId,
+			DeploymentApprovalRequestId: approvalRequestId,
+			TriggerType:                 triggerType,
+			AuditLog:                    sql.AuditLog{CreatedOn: triggeredAt, CreatedBy: overrideRequest.UserId, UpdatedOn: triggeredAt, UpdatedBy: overrideRequest.UserId},
 		}
 		savedWfr, err := impl.cdWorkflowRepository.SaveWorkFlowRunner(runner)
 		overrideRequest.WfrId = savedWfr.Id
@@ -2353,6 +2820,15 @@ func (impl *WorkflowDagExecutorImpl) ManualCdTrigger(overrideRequest *bean.Value
 		if err != nil {
 			impl.logger.Errorw("error in creating timeline status for deployment initiation, ManualCdTrigger", "err", err, "timeline", timeline)
 		}
+		if isWindowOverridden {
+			err = impl.auditDeploymentWindowOverride(overrideRequest, windowState)
+			if err != nil {
+				if err1 := impl.MarkCurrentDeploymentFailed(runner, err, overrideRequest.UserId); err1 != nil {
+					impl.logger.Errorw("error while updating current runner status to failed, ManualCdTrigger", "wfrId", runner.Id, "err", err1)
+				}
+				return 0, err
+			}
+		}
 
 		//checking vulnerability for deploying image
 		_, span = otel.Tracer("orchestrator").Start(ctx, "ciArtifactRepository.Get")
@@ -2375,6 +2851,34 @@ func (impl *WorkflowDagExecutorImpl) ManualCdTrigger(overrideRequest *bean.Value
 			}
 			return 0, fmt.Errorf("found vulnerability for image digest %s", artifact.ImageDigest)
 		}
+		signatureErr := impl.verifyImageSignature(runner, artifact, cdPipeline, overrideRequest.UserId)
+		if signatureErr != nil {
+			if _, isApiError := signatureErr.(*util.ApiError); !isApiError {
+				impl.logger.Errorw("error in verifying image signature, ManualCdTrigger", "pipelineId", cdPipeline.Id, "artifactId", artifact.Id, "err", signatureErr)
+			}
+			return 0, signatureErr
+		}
+
+		isQueued, err := impl.queueDeploymentIfConcurrencyLimitReached(overrideRequest, runner, overrideRequest.UserId)
+		if err != nil {
+			if err1 := impl.MarkCurrentDeploymentFailed(runner, err, overrideRequest.UserId); err1 != nil {
+				impl.logger.Errorw("error while updating current runner status to failed, ManualCdTrigger", "wfrId", runner.Id, "err", err1)
+			}
+			return 0, err
+		}
+		if isQueued {
+			// deployment is released from the queue by ReleaseQueuedDeployments once the environment has capacity, the
+			// trigger gates are evaluated again and the approval is consumed then
+			return 0, nil
+		}
+
+		if approvalRequestId > 0 {
+			err = impl.deploymentApprovalService.MarkApprovalRequestConsumed(approvalRequestId, overrideRequest.UserId)
+			if err != nil {
+				impl.logger.Errorw("error in consuming deployment approval, ManualCdTrigger", "approvalRequestId", approvalRequestId, "err", err)
+				return 0, err
+			}
+		}
 
 		// Deploy the release
 		_, span = otel.Tracer("orchestrator").Start(ctx, "appService.TriggerRelease")
@@ -2463,7 +2967,13 @@ type BulkTriggerRequest struct {
 }
 
 func (impl *WorkflowDagExecutorImpl) TriggerBulkDeploymentAsync(requests []*BulkTriggerRequest, UserId int32) (interface{}, error) {
-	var cdWorkflows []*pipelineConfig.CdWorkflow
+	triggeredAt := time.Now()
+	windowStates, pipelines, err := impl.getDeploymentWindowStatesForBulk(requests, triggeredAt)
+	if err != nil {
+		impl.logger.Errorw("error in evaluating deployment windows for bulk trigger", "req", requests, "err", err)
+		return nil, err
+	}
+	var cdWorkflows, blockedCdWorkflows []*pipelineConfig.CdWorkflow
 	for _, request := range requests {
 		cdWf := &pipelineConfig.CdWorkflow{
 			CiArtifactId:   request.CiArtifactId,
@@ -2471,13 +2981,26 @@ func (impl *WorkflowDagExecutorImpl) TriggerBulkDeploymentAsync(requests []*Bulk
 			AuditLog:       sql.AuditLog{CreatedOn: time.Now(), CreatedBy: UserId, UpdatedOn: time.Now(), UpdatedBy: UserId},
 			WorkflowStatus: pipelineConfig.REQUEST_ACCEPTED,
 		}
+		if windowState, ok := windowStates[request.PipelineId]; ok && !windowState.IsAllowed {
+			cdWf.WorkflowStatus = pipelineConfig.TRIGGER_ERROR
+			blockedCdWorkflows = append(blockedCdWorkflows, cdWf)
+			continue
+		}
 		cdWorkflows = append(cdWorkflows, cdWf)
 	}
-	err := impl.cdWorkflowRepository.SaveWorkFlows(cdWorkflows...)
+	err = impl.cdWorkflowRepository.SaveWorkFlows(append(cdWorkflows, blockedCdWorkflows...)...)
 	if err != nil {
 		impl.logger.Errorw("error in saving wfs", "req", requests, "err", err)
 		return nil, err
 	}
+	for _, cdWf := range blockedCdWorkflows {
+		windowState := windowStates[cdWf.PipelineId]
+		impl.logger.Infow("deployment blocked by deployment window, skipping in bulk trigger", "pipelineId", cdWf.PipelineId, "reason", windowState.Reason)
+		err = impl.markDeploymentBlockedByWindow(cdWf.Id, bean.CD_WORKFLOW_TYPE_DEPLOY, pipelines[cdWf.PipelineId], windowState, pipelineConfig.WORKFLOW_EXECUTOR_TYPE_SYSTEM, UserId, triggeredAt)
+		if err != nil {
+			impl.logger.Errorw("error in recording blocked deployment for bulk trigger", "cdWorkflowId", cdWf.Id, "err", err)
+		}
+	}
 	impl.triggerNatsEventForBulkAction(cdWorkflows)
 	return nil, nil
 	//return
@@ -2486,6 +3009,33 @@ func (impl *WorkflowDagExecutorImpl) TriggerBulkDeploymentAsync(requests []*Bulk
 	//consume message
 }
 
+func (impl *WorkflowDagExecutorImpl) getDeploymentWindowStatesForBulk(requests []*BulkTriggerRequest, triggeredAt time.Time) (map[int]*deploymentWindow.DeploymentWindowState, map[int]*pipelineConfig.Pipeline, error) {
+	windowStates := make(map[int]*deploymentWindow.DeploymentWindowState)
+	pipelineMap := make(map[int]*pipelineConfig.Pipeline)
+	pipelineIds := make([]int, 0, len(requests))
+	for _, request := range requests {
+		pipelineIds = append(pipelineIds, request.PipelineId)
+	}
+	if len(pipelineIds) == 0 {
+		return windowStates, pipelineMap, nil
+	}
+	pipelines, err := impl.pipelineRepository.FindByIdsIn(pipelineIds)
+	if err != nil {
+		impl.logger.Errorw("error in fetching pipelines", "pipelineIds", pipelineIds, "err", err)
+		return nil, nil, err
+	}
+	for _, pipeline := range pipelines {
+		windowState, err := impl.deploymentWindowService.GetDeploymentWindowState(pipeline.AppId, pipeline.EnvironmentId, triggeredAt)
+		if err != nil {
+			impl.logger.Errorw("error in evaluating deployment windows", "pipelineId", pipeline.Id, "err", err)
+			return nil, nil, err
+		}
+		windowStates[pipeline.Id] = windowState
+		pipelineMap[pipeline.Id] = pipeline
+	}
+	return windowStates, pipelineMap, nil
+}
+
 type DeploymentGroupAppWithEnv struct {
 	EnvironmentId     int         `json:"environmentId"`
 	DeploymentGroupId int         `json:"deploymentGroupId"`
@@ -2844,17 +3394,7 @@ func (impl *WorkflowDagExecutorImpl) releasePipeline(pipeline *pipelineConfig.Pi
 		return err
 	}
 
-	request := &bean.ValuesOverrideRequest{
-		PipelineId:           pipeline.Id,
-		UserId:               artifact.CreatedBy,
-		CiArtifactId:         artifact.Id,
-		AppId:                pipeline.AppId,
-		CdWorkflowId:         cdWorkflowId,
-		ForceTrigger:         true,
-		DeploymentWithConfig: bean.DEPLOYMENT_CONFIG_TYPE_LAST_SAVED,
-		WfrId:                wfrId,
-	}
-	impl.SetPipelineFieldsInOverrideRequest(request, pipeline)
+	request := impl.buildAutoTriggerOverrideRequest(pipeline, artifact, cdWorkflowId, wfrId)
 
 	ctx, err := impl.buildACDContext()
 	if err != nil {
@@ -2872,6 +3412,21 @@ func (impl *WorkflowDagExecutorImpl) releasePipeline(pipeline *pipelineConfig.Pi
 
 }
 
+func (impl *WorkflowDagExecutorImpl) buildAutoTriggerOverrideRequest(pipeline *pipelineConfig.Pipeline, artifact *repository.CiArtifact, cdWorkflowId, wfrId int) *bean.ValuesOverrideRequest {
+	request := &bean.ValuesOverrideRequest{
+		PipelineId:           pipeline.Id,
+		UserId:               artifact.CreatedBy,
+		CiArtifactId:         artifact.Id,
+		AppId:                pipeline.AppId,
+		CdWorkflowId:         cdWorkflowId,
+		ForceTrigger:         true,
+		DeploymentWithConfig: bean.DEPLOYMENT_CONFIG_TYPE_LAST_SAVED,
+		WfrId:                wfrId,
+	}
+	impl.SetPipelineFieldsInOverrideRequest(request, pipeline)
+	return request
+}
+
 func (impl *WorkflowDagExecutorImpl) SetPipelineFieldsInOverrideRequest(overrideRequest *bean.ValuesOverrideRequest, pipeline *pipelineConfig.Pipeline) {
 	overrideRequest.PipelineId = pipeline.Id
 	overrideRequest.PipelineName = pipeline.Name
@@ -3007,6 +3562,19 @@ func (impl *WorkflowDagExecutorImpl) BuildManifestForTrigger(overrideRequest *be
 	return valuesOverrideResponse, builtChartPath, err
 }
 
+func (impl *WorkflowDagExecutorImpl) BuildManifestForDryRun(overrideRequest *bean.ValuesOverrideRequest, ctx context.Context) (*app.ValuesOverrideResponse, string, error) {
+	cdPipeline, err := impl.pipelineRepository.FindById(overrideRequest.PipelineId)
+	if err != nil {
+		impl.logger.Errorw("dry run request with invalid pipelineId, BuildManifestForDryRun", "pipelineId", overrideRequest.PipelineId, "err", err)
+		return nil, "", err
+	}
+	impl.SetPipelineFieldsInOverrideRequest(overrideRequest, cdPipeline)
+	overrideRequest.CdWorkflowType = bean.CD_WORKFLOW_TYPE_DEPLOY
+	overrideRequest.PipelineOverrideId = 0
+	overrideRequest.IsDryRun = true
+	return impl.BuildManifestForTrigger(overrideRequest, time.Now(), ctx)
+}
+
 func (impl *WorkflowDagExecutorImpl) CreateHistoriesForDeploymentTrigger(pipeline *pipelineConfig.Pipeline, strategy *chartConfig.PipelineStrategy, envOverride *chartConfig.EnvConfigOverride, deployedOn time.Time, deployedBy int32) error {
 	//creating history for deployment template
 	deploymentTemplateHistory, err := impl.deploymentTemplateHistoryService.CreateDeploymentTemplateHistoryForDeploymentTrigger(pipeline, envOverride, envOverride.Chart.ImageDescriptorTemplate, deployedOn, deployedBy)
@@ -3255,7 +3823,13 @@ func (impl *WorkflowDagExecutorImpl) GetValuesOverrideForTrigger(overrideRequest
 	)
 
 	// Conditional Block based on PipelineOverrideCreated --> start
-	if !isPipelineOverrideCreated {
+	if overrideRequest.IsDryRun {
+		// dry run renders the release which would be created, nothing is saved for it
+		pipelineOverride, err = impl.buildPipelineOverride(overrideRequest, envOverride.Id, triggeredAt)
+		if err != nil {
+			return valuesOverrideResponse, err
+		}
+	} else if !isPipelineOverrideCreated {
 		_, span = otel.Tracer("orchestrator").Start(ctx, "savePipelineOverride")
 		pipelineOverride, err = impl.savePipelineOverride(overrideRequest, envOverride.Id, triggeredAt)
 		span.End()
@@ -3314,16 +3888,22 @@ func (impl *WorkflowDagExecutorImpl) GetValuesOverrideForTrigger(overrideRequest
 		appName := fmt.Sprintf("%s-%s", overrideRequest.AppName, envOverride.Environment.Name)
 		mergedValues = impl.autoscalingCheckBeforeTrigger(ctx, appName, envOverride.Namespace, mergedValues, overrideRequest)
 
-		_, span = otel.Tracer("orchestrator").Start(ctx, "dockerRegistryIpsConfigService.HandleImagePullSecretOnApplicationDeployment")
-		// handle image pull secret if access given
-		mergedValues, err = impl.dockerRegistryIpsConfigService.HandleImagePullSecretOnApplicationDeployment(envOverride.Environment, artifact, pipeline.CiPipelineId, mergedValues)
-		span.End()
-		if err != nil {
-			return valuesOverrideResponse, err
+		// handle image pull secret if access given, it creates the secret in the target cluster so dry run renders the
+		// values without it
+		if !overrideRequest.IsDryRun {
+			_, span = otel.Tracer("orchestrator").Start(ctx, "dockerRegistryIpsConfigService.HandleImagePullSecretOnApplicationDeployment")
+			mergedValues, err = impl.dockerRegistryIpsConfigService.HandleImagePullSecretOnApplicationDeployment(envOverride.Environment, artifact, pipeline.CiPipelineId, mergedValues)
+			span.End()
+			if err != nil {
+				return valuesOverrideResponse, err
+			}
 		}
 
 		pipelineOverride.PipelineMergedValues = string(mergedValues)
 		valuesOverrideResponse.MergedValues = string(mergedValues)
+		if overrideRequest.IsDryRun {
+			return valuesOverrideResponse, nil
+		}
 		err = impl.pipelineOverrideRepository.Update(pipelineOverride)
 		if err != nil {
 			return valuesOverrideResponse, err
@@ -3741,12 +4321,14 @@ func (impl *WorkflowDagExecutorImpl) GetEnvOverrideByTriggerType(overrideRequest
 					IsBasicViewLocked: chart.IsBasicViewLocked,
 					CurrentViewEditor: chart.CurrentViewEditor,
 				}
-				_, span = otel.Tracer("orchestrator").Start(ctx, "environmentConfigRepository.Save")
-				err = impl.environmentConfigRepository.Save(envOverride)
-				span.End()
-				if err != nil {
-					impl.logger.Errorw("error in creating envconfig", "data", envOverride, "error", err)
-					return nil, err
+				if !overrideRequest.IsDryRun {
+					_, span = otel.Tracer("orchestrator").Start(ctx, "environmentConfigRepository.Save")
+					err = impl.environmentConfigRepository.Save(envOverride)
+					span.End()
+					if err != nil {
+						impl.logger.Errorw("error in creating envconfig", "data", envOverride, "error", err)
+						return nil, err
+					}
 				}
 			}
 			envOverride.Chart = chart
@@ -4009,7 +4591,7 @@ func (impl *WorkflowDagExecutorImpl) getConfigMapAndSecretJsonV2(request ConfigM
 	return merged, nil
 }
 
-func (impl *WorkflowDagExecutorImpl) savePipelineOverride(overrideRequest *bean.ValuesOverrideRequest, envOverrideId int, triggeredAt time.Time) (override *chartConfig.PipelineOverride, err error) {
+func (impl *WorkflowDagExecutorImpl) buildPipelineOverride(overrideRequest *bean.ValuesOverrideRequest, envOverrideId int, triggeredAt time.Time) (*chartConfig.PipelineOverride, error) {
 	currentReleaseNo, err := impl.pipelineOverrideRepository.GetCurrentPipelineReleaseCounter(overrideRequest.PipelineId)
 	if err != nil {
 		return nil, err
@@ -4024,7 +4606,14 @@ func (impl *WorkflowDagExecutorImpl) savePipelineOverride(overrideRequest *bean.
 		AuditLog:               sql.AuditLog{CreatedBy: overrideRequest.UserId, CreatedOn: triggeredAt, UpdatedOn: triggeredAt, UpdatedBy: overrideRequest.UserId},
 		DeploymentType:         overrideRequest.DeploymentType,
 	}
+	return po, nil
+}
 
+func (impl *WorkflowDagExecutorImpl) savePipelineOverride(overrideRequest *bean.ValuesOverrideRequest, envOverrideId int, triggeredAt time.Time) (override *chartConfig.PipelineOverride, err error) {
+	po, err := impl.buildPipelineOverride(overrideRequest, envOverrideId, triggeredAt)
+	if err != nil {
+		return nil, err
+	}
 	err = impl.pipelineOverrideRepository.Save(po)
 	if err != nil {
 		return nil, err
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	v1alpha12 "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/typed/workflow/v1alpha1"
 	"github.com/argoproj/argo-workflows/v3/workflow/util"
 	"github.com/devtron-labs/common-lib/utils/k8s"
@@ -116,15 +117,19 @@ func (impl *WorkflowServiceImpl) createWorkflowTemplate(workflowRequest *types.W
 		return bean3.WorkflowTemplate{}, err
 	}
 	workflowTemplate := workflowRequest.GetWorkflowTemplate(workflowJson, impl.ciCdConfig)
-	workflowConfigMaps, workflowSecrets, err := impl.appendGlobalCMCS(workflowRequest)
-	if err != nil {
-		impl.Logger.Errorw("error occurred while appending CmCs", "err", err)
-		return bean3.WorkflowTemplate{}, err
-	}
-	workflowConfigMaps, workflowSecrets, err = impl.addExistingCmCsInWorkflow(workflowRequest, workflowConfigMaps, workflowSecrets)
-	if err != nil {
-		impl.Logger.Errorw("error occurred while adding existing CmCs", "err", err)
-		return bean3.WorkflowTemplate{}, err
+	var workflowConfigMaps, workflowSecrets []bean.ConfigSecretMap
+	// dry runs run untrusted steps, they get no config map or secret
+	if !workflowRequest.IsDryRun {
+		workflowConfigMaps, workflowSecrets, err = impl.appendGlobalCMCS(workflowRequest)
+		if err != nil {
+			impl.Logger.Errorw("error occurred while appending CmCs", "err", err)
+			return bean3.WorkflowTemplate{}, err
+		}
+		workflowConfigMaps, workflowSecrets, err = impl.addExistingCmCsInWorkflow(workflowRequest, workflowConfigMaps, workflowSecrets)
+		if err != nil {
+			impl.Logger.Errorw("error occurred while adding existing CmCs", "err", err)
+			return bean3.WorkflowTemplate{}, err
+		}
 	}
 
 	workflowTemplate.ConfigMaps = workflowConfigMaps
@@ -141,6 +146,27 @@ func (impl *WorkflowServiceImpl) createWorkflowTemplate(workflowRequest *types.W
 
 	workflowTemplate.Containers = []v12.Container{workflowMainContainer}
 	impl.updateBlobStorageConfig(workflowRequest, &workflowTemplate)
+	if workflowRequest.Type == bean3.CI_WORKFLOW_PIPELINE_TYPE {
+		workflowTemplate.BuildMatrixContainers, err = impl.getBuildMatrixContainers(workflowRequest, workflowMainContainer)
+		if err != nil {
+			impl.Logger.Errorw("error occurred while getting build matrix containers", "err", err)
+			return bean3.WorkflowTemplate{}, err
+		}
+		if len(workflowTemplate.BuildMatrixContainers) > 0 && workflowRequest.HasPreCiStage() {
+			workflowTemplate.BuildMatrixPreCiContainer, err = impl.getBuildMatrixStageContainer(workflowRequest, workflowMainContainer, bean3.BUILD_MATRIX_STAGE_PRE_CI)
+			if err != nil {
+				impl.Logger.Errorw("error occurred while getting build matrix pre ci container", "err", err)
+				return bean3.WorkflowTemplate{}, err
+			}
+		}
+		if len(workflowTemplate.BuildMatrixContainers) > 0 && workflowRequest.HasPostCiStage() {
+			workflowTemplate.BuildMatrixPostCiContainer, err = impl.getBuildMatrixStageContainer(workflowRequest, workflowMainContainer, bean3.BUILD_MATRIX_STAGE_POST_CI)
+			if err != nil {
+				impl.Logger.Errorw("error occurred while getting build matrix post ci container", "err", err)
+				return bean3.WorkflowTemplate{}, err
+			}
+		}
+	}
 	if workflowRequest.Type == bean3.CI_WORKFLOW_PIPELINE_TYPE || workflowRequest.Type == bean3.JOB_WORKFLOW_PIPELINE_TYPE {
 		nodeSelector := impl.getAppLabelNodeSelector(workflowRequest)
 		if nodeSelector != nil {
@@ -158,6 +184,59 @@ func (impl *WorkflowServiceImpl) createWorkflowTemplate(workflowRequest *types.W
 	return workflowTemplate, nil
 }
 
+// getBuildMatrixContainers returns a copy of the main container per build matrix platform, building the image of that
+// platform only
+func (impl *WorkflowServiceImpl) getBuildMatrixContainers(workflowRequest *types.WorkflowRequest, mainContainer v12.Container) ([]*bean3.BuildMatrixContainer, error) {
+	buildMatrix := workflowRequest.CiBuildConfig.GetBuildMatrix()
+	if buildMatrix == nil {
+		return nil, nil
+	}
+	var buildMatrixContainers []*bean3.BuildMatrixContainer
+	for _, platform := range buildMatrix.Platforms {
+		platformWorkflowJson, err := workflowRequest.GetBuildMatrixWorkflowJson(platform)
+		if err != nil {
+			return nil, err
+		}
+		container := *mainContainer.DeepCopy()
+		for i := range container.Env {
+			if container.Env[i].Name == bean3.CI_CD_EVENT {
+				container.Env[i].Value = string(platformWorkflowJson)
+			}
+		}
+		platformSuffix := bean3.GetBuildMatrixPlatformSuffix(platform.Platform)
+		buildMatrixContainer := &bean3.BuildMatrixContainer{
+			Name:            fmt.Sprintf("%s-%s", bean3.CI_WORKFLOW_NAME, platformSuffix),
+			Container:       container,
+			CloudStorageKey: fmt.Sprintf("%s/%s", workflowRequest.BlobStorageLogsKey, platformSuffix),
+		}
+		if buildMatrix.UseNativeNodes {
+			buildMatrixContainer.NodeSelector = platform.GetNodeSelector()
+		}
+		buildMatrixContainers = append(buildMatrixContainers, buildMatrixContainer)
+	}
+	return buildMatrixContainers, nil
+}
+
+// getBuildMatrixStageContainer returns a copy of the main container running only the steps of the given stage of the
+// build matrix
+func (impl *WorkflowServiceImpl) getBuildMatrixStageContainer(workflowRequest *types.WorkflowRequest, mainContainer v12.Container, stage string) (*bean3.BuildMatrixContainer, error) {
+	stageWorkflowJson, err := workflowRequest.GetBuildMatrixStageWorkflowJson(stage)
+	if err != nil {
+		return nil, err
+	}
+	container := *mainContainer.DeepCopy()
+	for i := range container.Env {
+		if container.Env[i].Name == bean3.CI_CD_EVENT {
+			container.Env[i].Value = string(stageWorkflowJson)
+		}
+	}
+	return &bean3.BuildMatrixContainer{
+		Name:            fmt.Sprintf("%s-%s", bean3.CI_WORKFLOW_NAME, stage),
+		Container:       container,
+		CloudStorageKey: fmt.Sprintf("%s/%s", workflowRequest.BlobStorageLogsKey, stage),
+	}, nil
+}
+
 func (impl *WorkflowServiceImpl) getClusterConfig(workflowRequest *types.WorkflowRequest) (*rest.Config, error) {
 	env := workflowRequest.Env
 	if workflowRequest.IsExtRun {
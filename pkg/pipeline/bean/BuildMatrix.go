@@ -0,0 +1,160 @@
+package bean
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuildMatrix fans the docker build of a ci pipeline out into one parallel build per target platform, the images
+// built are then combined into a single multi-arch manifest list
+type BuildMatrix struct {
+	Platforms []*BuildMatrixPlatform `json:"platforms"`
+	// UseNativeNodes schedules the build of every platform on nodes of that platform instead of emulating it with buildx
+	UseNativeNodes bool `json:"useNativeNodes"`
+}
+
+type BuildMatrixPlatform struct {
+	// Platform is of the form os/arch[/variant], e.g. linux/arm64
+	Platform string `json:"platform"`
+	// Args are the docker build args of this platform only, they override the args of the build config
+	Args map[string]string `json:"args,omitempty"`
+}
+
+type BuildMatrixStatusType string
+
+const (
+	BUILD_MATRIX_STATUS_RUNNING   BuildMatrixStatusType = "Running"
+	BUILD_MATRIX_STATUS_SUCCEEDED BuildMatrixStatusType = "Succeeded"
+	BUILD_MATRIX_STATUS_FAILED    BuildMatrixStatusType = "Failed"
+)
+
+type BuildMatrixStatus struct {
+	Image       string                       `json:"image"`
+	ImageDigest string                       `json:"imageDigest"`
+	Status      BuildMatrixStatusType        `json:"status"`
+	Message     string                       `json:"message"`
+	Platforms   []*BuildMatrixPlatformStatus `json:"platforms"`
+}
+
+type BuildMatrixPlatformStatus struct {
+	Platform    string                `json:"platform"`
+	Image       string                `json:"image"`
+	ImageDigest string                `json:"imageDigest"`
+	Status      BuildMatrixStatusType `json:"status"`
+}
+
+const (
+	NODE_SELECTOR_OS   = "kubernetes.io/os"
+	NODE_SELECTOR_ARCH = "kubernetes.io/arch"
+)
+
+// the pre and post ci stages of a build matrix run once, before and after the builds of the platforms
+const (
+	BUILD_MATRIX_STAGE_PRE_CI  = "pre-ci"
+	BUILD_MATRIX_STAGE_POST_CI = "post-ci"
+)
+
+// GetBuildMatrix returns the build matrix of docker builds, nil if the images are not built per platform
+func (ciBuildConfig *CiBuildConfigBean) GetBuildMatrix() *BuildMatrix {
+	if ciBuildConfig == nil || ciBuildConfig.DockerBuildConfig == nil {
+		return nil
+	}
+	if ciBuildConfig.CiBuildType != SELF_DOCKERFILE_BUILD_TYPE && ciBuildConfig.CiBuildType != MANAGED_DOCKERFILE_BUILD_TYPE {
+		return nil
+	}
+	buildMatrix := ciBuildConfig.DockerBuildConfig.BuildMatrix
+	if buildMatrix == nil || len(buildMatrix.Platforms) == 0 {
+		return nil
+	}
+	return buildMatrix
+}
+
+func (ciBuildConfig *CiBuildConfigBean) ValidateBuildMatrix() error {
+	if ciBuildConfig == nil || ciBuildConfig.DockerBuildConfig == nil || ciBuildConfig.DockerBuildConfig.BuildMatrix == nil {
+		return nil
+	}
+	buildMatrix := ciBuildConfig.DockerBuildConfig.BuildMatrix
+	if len(buildMatrix.Platforms) == 0 {
+		return nil
+	}
+	if ciBuildConfig.CiBuildType != SELF_DOCKERFILE_BUILD_TYPE && ciBuildConfig.CiBuildType != MANAGED_DOCKERFILE_BUILD_TYPE {
+		return fmt.Errorf("build matrix is supported only for dockerfile builds")
+	}
+	platforms := make(map[string]bool)
+	for _, platform := range buildMatrix.Platforms {
+		if platform == nil {
+			return fmt.Errorf("build matrix platform can not be empty")
+		}
+		_, _, _, err := ParseBuildPlatform(platform.Platform)
+		if err != nil {
+			return err
+		}
+		if platforms[platform.Platform] {
+			return fmt.Errorf("platform %s is repeated in build matrix", platform.Platform)
+		}
+		platforms[platform.Platform] = true
+	}
+	return nil
+}
+
+// ForBuildMatrixPlatform returns a copy of the build config which builds the image of the given platform only
+func (ciBuildConfig *CiBuildConfigBean) ForBuildMatrixPlatform(platform *BuildMatrixPlatform) *CiBuildConfigBean {
+	buildConfig := *ciBuildConfig
+	dockerBuildConfig := *ciBuildConfig.DockerBuildConfig
+	dockerBuildConfig.TargetPlatform = platform.Platform
+	dockerBuildConfig.Args = mergeMap(dockerBuildConfig.Args, platform.Args)
+	if !ciBuildConfig.DockerBuildConfig.BuildMatrix.UseNativeNodes {
+		// platforms other than the node's are emulated by buildx
+		dockerBuildConfig.UseBuildx = true
+	}
+	dockerBuildConfig.BuildMatrix = nil
+	buildConfig.DockerBuildConfig = &dockerBuildConfig
+	return &buildConfig
+}
+
+// ForBuildMatrixStage returns a copy of the build config which skips the build, for the pre and post ci stages run
+// apart from the builds of the platforms
+func (ciBuildConfig *CiBuildConfigBean) ForBuildMatrixStage() *CiBuildConfigBean {
+	buildConfig := *ciBuildConfig
+	buildConfig.CiBuildType = SKIP_BUILD_TYPE
+	buildConfig.DockerBuildConfig = nil
+	buildConfig.BuildPackConfig = nil
+	return &buildConfig
+}
+
+// GetNodeSelector returns the node selector scheduling the build of the platform on nodes of that platform
+func (platform *BuildMatrixPlatform) GetNodeSelector() map[string]string {
+	os, arch, _, _ := ParseBuildPlatform(platform.Platform)
+	return map[string]string{
+		NODE_SELECTOR_OS:   os,
+		NODE_SELECTOR_ARCH: arch,
+	}
+}
+
+// ParseBuildPlatform splits a platform of the form os/arch[/variant]
+func ParseBuildPlatform(platform string) (os string, arch string, variant string, err error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", "", "", fmt.Errorf("invalid platform %q, expected os/arch[/variant]", platform)
+	}
+	for _, part := range parts {
+		if len(part) == 0 || strings.ContainsAny(part, " ,:") {
+			return "", "", "", fmt.Errorf("invalid platform %q, expected os/arch[/variant]", platform)
+		}
+	}
+	if len(parts) == 3 {
+		variant = parts[2]
+	}
+	return parts[0], parts[1], variant, nil
+}
+
+// GetBuildMatrixImageTag returns the tag of the image built for one platform, the manifest list is pushed with the
+// tag itself
+func GetBuildMatrixImageTag(tag string, platform string) string {
+	return fmt.Sprintf("%s-%s", tag, GetBuildMatrixPlatformSuffix(platform))
+}
+
+// GetBuildMatrixPlatformSuffix returns the platform in a form usable in tags, names and keys
+func GetBuildMatrixPlatformSuffix(platform string) string {
+	return strings.ReplaceAll(platform, "/", "-")
+}
@@ -0,0 +1,83 @@
+package bean
+
+import "testing"
+
+func getBuildMatrixConfig(buildType CiBuildType, platforms ...string) *CiBuildConfigBean {
+	buildMatrix := &BuildMatrix{}
+	for _, platform := range platforms {
+		buildMatrix.Platforms = append(buildMatrix.Platforms, &BuildMatrixPlatform{Platform: platform})
+	}
+	return &CiBuildConfigBean{
+		CiBuildType: buildType,
+		DockerBuildConfig: &DockerBuildConfig{
+			Args:        map[string]string{"GO_VERSION": "1.20", "TARGET": "app"},
+			BuildMatrix: buildMatrix,
+		},
+	}
+}
+
+func TestValidateBuildMatrix(t *testing.T) {
+	tests := []struct {
+		name          string
+		ciBuildConfig *CiBuildConfigBean
+		wantErr       bool
+	}{
+		{name: "no build matrix", ciBuildConfig: &CiBuildConfigBean{CiBuildType: SELF_DOCKERFILE_BUILD_TYPE, DockerBuildConfig: &DockerBuildConfig{}}},
+		{name: "empty build matrix", ciBuildConfig: getBuildMatrixConfig(BUILDPACK_BUILD_TYPE)},
+		{name: "valid platforms", ciBuildConfig: getBuildMatrixConfig(SELF_DOCKERFILE_BUILD_TYPE, "linux/amd64", "linux/arm/v7")},
+		{name: "buildpack build", ciBuildConfig: getBuildMatrixConfig(BUILDPACK_BUILD_TYPE, "linux/amd64"), wantErr: true},
+		{name: "invalid platform", ciBuildConfig: getBuildMatrixConfig(MANAGED_DOCKERFILE_BUILD_TYPE, "linux"), wantErr: true},
+		{name: "multiple platforms in one", ciBuildConfig: getBuildMatrixConfig(MANAGED_DOCKERFILE_BUILD_TYPE, "linux/amd64,linux/arm64"), wantErr: true},
+		{name: "repeated platform", ciBuildConfig: getBuildMatrixConfig(SELF_DOCKERFILE_BUILD_TYPE, "linux/amd64", "linux/amd64"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.ciBuildConfig.ValidateBuildMatrix(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBuildMatrix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestForBuildMatrixPlatform(t *testing.T) {
+	ciBuildConfig := getBuildMatrixConfig(SELF_DOCKERFILE_BUILD_TYPE, "linux/amd64", "linux/arm64")
+	arm64 := ciBuildConfig.DockerBuildConfig.BuildMatrix.Platforms[1]
+	arm64.Args = map[string]string{"TARGET": "arm-app"}
+	platformConfig := ciBuildConfig.ForBuildMatrixPlatform(arm64)
+	dockerBuildConfig := platformConfig.DockerBuildConfig
+	if dockerBuildConfig.TargetPlatform != "linux/arm64" || !dockerBuildConfig.UseBuildx || dockerBuildConfig.BuildMatrix != nil {
+		t.Errorf("ForBuildMatrixPlatform() = %+v", dockerBuildConfig)
+	}
+	if dockerBuildConfig.Args["TARGET"] != "arm-app" || dockerBuildConfig.Args["GO_VERSION"] != "1.20" {
+		t.Errorf("ForBuildMatrixPlatform() args = %v", dockerBuildConfig.Args)
+	}
+	if ciBuildConfig.DockerBuildConfig.Args["TARGET"] != "app" || ciBuildConfig.GetBuildMatrix() == nil {
+		t.Errorf("ForBuildMatrixPlatform() modified the build config %+v", ciBuildConfig.DockerBuildConfig)
+	}
+
+	ciBuildConfig.DockerBuildConfig.BuildMatrix.UseNativeNodes = true
+	if platformConfig = ciBuildConfig.ForBuildMatrixPlatform(arm64); platformConfig.DockerBuildConfig.UseBuildx {
+		t.Errorf("ForBuildMatrixPlatform() uses buildx on native nodes")
+	}
+	nodeSelector := arm64.GetNodeSelector()
+	if nodeSelector[NODE_SELECTOR_OS] != "linux" || nodeSelector[NODE_SELECTOR_ARCH] != "arm64" {
+		t.Errorf("GetNodeSelector() = %v", nodeSelector)
+	}
+}
+
+func TestForBuildMatrixStage(t *testing.T) {
+	ciBuildConfig := getBuildMatrixConfig(SELF_DOCKERFILE_BUILD_TYPE, "linux/amd64", "linux/arm64")
+	stageConfig := ciBuildConfig.ForBuildMatrixStage()
+	if stageConfig.CiBuildType != SKIP_BUILD_TYPE || stageConfig.DockerBuildConfig != nil || stageConfig.GetBuildMatrix() != nil {
+		t.Errorf("ForBuildMatrixStage() = %+v, want the build skipped", stageConfig)
+	}
+	if ciBuildConfig.CiBuildType != SELF_DOCKERFILE_BUILD_TYPE || ciBuildConfig.GetBuildMatrix() == nil {
+		t.Errorf("ForBuildMatrixStage() modified the build config %+v", ciBuildConfig)
+	}
+}
+
+func TestGetBuildMatrixImageTag(t *testing.T) {
+	if tag := GetBuildMatrixImageTag("abc123-12", "linux/arm/v7"); tag != "abc123-12-linux-arm-v7" {
+		t.Errorf("GetBuildMatrixImageTag() = %s", tag)
+	}
+}
@@ -43,6 +43,7 @@ type DockerBuildConfig struct {
 	UseBuildx              bool                `json:"useBuildx"`
 	BuildxProvenanceMode   string              `json:"buildxProvenanceMode"`
 	BuildxK8sDriverOptions []map[string]string `json:"buildxK8SDriverOptions,omitempty"`
+	BuildMatrix            *BuildMatrix        `json:"buildMatrix,omitempty"`
 }
 
 type BuildPackConfig struct {
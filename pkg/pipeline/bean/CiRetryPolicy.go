@@ -0,0 +1,159 @@
+package bean
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CiFailureReason string
+
+const (
+	CI_FAILURE_NODE_EVICTION         CiFailureReason = "NODE_EVICTION"
+	CI_FAILURE_OOM_KILLED            CiFailureReason = "OOM_KILLED"
+	CI_FAILURE_IMAGE_PULL            CiFailureReason = "IMAGE_PULL"
+	CI_FAILURE_REGISTRY_PUSH_TIMEOUT CiFailureReason = "REGISTRY_PUSH_TIMEOUT"
+	CI_FAILURE_USER_SCRIPT_EXIT_CODE CiFailureReason = "USER_SCRIPT_EXIT_CODE"
+	CI_FAILURE_UNKNOWN               CiFailureReason = "UNKNOWN"
+)
+
+const (
+	ciRetryMaxAttemptsLimit = 10
+	oomKilledExitCode       = 137
+)
+
+var ciFailureExitCodeRegex = regexp.MustCompile(`exit code (\d+)`)
+
+// CiRetryPolicy is the retry policy of a ci pipeline, MaxAttempts counts the first build as well so a policy with
+// MaxAttempts 3 re-triggers a failed build at most twice
+type CiRetryPolicy struct {
+	CiPipelineId      int               `json:"ciPipelineId"`
+	MaxAttempts       int               `json:"maxAttempts" validate:"min=1"`
+	BackoffSeconds    int               `json:"backoffSeconds" validate:"min=0"`
+	BackoffMultiplier float64           `json:"backoffMultiplier"`
+	MaxBackoffSeconds int               `json:"maxBackoffSeconds" validate:"min=0"`
+	RetryOn           []CiFailureReason `json:"retryOn"`
+	// RetryOnExitCodes limits the retries of USER_SCRIPT_EXIT_CODE failures to these exit codes, all are retried if empty
+	RetryOnExitCodes []int `json:"retryOnExitCodes,omitempty"`
+	Active           bool  `json:"active"`
+	UserId           int32 `json:"-"`
+}
+
+// CiRetryAttempt is one attempt of a build, attempt 1 being the build triggered by the user or the webhook
+type CiRetryAttempt struct {
+	Attempt       int             `json:"attempt"`
+	CiWorkflowId  int             `json:"ciWorkflowId"`
+	Status        string          `json:"status"`
+	FailureReason CiFailureReason `json:"failureReason,omitempty"`
+	RetryStatus   string          `json:"retryStatus,omitempty"`
+	RetryAt       *time.Time      `json:"retryAt,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
+
+func (policy *CiRetryPolicy) Validate() error {
+	if policy.MaxAttempts < 1 || policy.MaxAttempts > ciRetryMaxAttemptsLimit {
+		return fmt.Errorf("max attempts should be between 1 and %d", ciRetryMaxAttemptsLimit)
+	}
+	if policy.BackoffSeconds < 0 || policy.MaxBackoffSeconds < 0 {
+		return fmt.Errorf("backoff can not be negative")
+	}
+	if policy.BackoffMultiplier != 0 && policy.BackoffMultiplier < 1 {
+		return fmt.Errorf("backoff multiplier can not be less than 1")
+	}
+	if policy.MaxBackoffSeconds != 0 && policy.MaxBackoffSeconds < policy.BackoffSeconds {
+		return fmt.Errorf("max backoff can not be less than backoff")
+	}
+	if len(policy.RetryOn) == 0 {
+		return fmt.Errorf("at least one failure reason to retry on is required")
+	}
+	retryOnUserScript := false
+	for _, reason := range policy.RetryOn {
+		switch reason {
+		case CI_FAILURE_NODE_EVICTION, CI_FAILURE_OOM_KILLED, CI_FAILURE_IMAGE_PULL, CI_FAILURE_REGISTRY_PUSH_TIMEOUT, CI_FAILURE_UNKNOWN:
+		case CI_FAILURE_USER_SCRIPT_EXIT_CODE:
+			retryOnUserScript = true
+		default:
+			return fmt.Errorf("invalid failure reason %q", reason)
+		}
+	}
+	if len(policy.RetryOnExitCodes) > 0 && !retryOnUserScript {
+		return fmt.Errorf("exit codes can only be given when retrying on %s", CI_FAILURE_USER_SCRIPT_EXIT_CODE)
+	}
+	for _, exitCode := range policy.RetryOnExitCodes {
+		if exitCode <= 0 || exitCode > 255 {
+			return fmt.Errorf("invalid exit code %d", exitCode)
+		}
+	}
+	return nil
+}
+
+// ShouldRetry reports whether a build failed for the reason, and with the exit code, is to be retried by the policy
+func (policy *CiRetryPolicy) ShouldRetry(reason CiFailureReason, exitCode int) bool {
+	for _, retryOn := range policy.RetryOn {
+		if retryOn != reason {
+			continue
+		}
+		if reason != CI_FAILURE_USER_SCRIPT_EXIT_CODE || len(policy.RetryOnExitCodes) == 0 {
+			return true
+		}
+		for _, retryOnExitCode := range policy.RetryOnExitCodes {
+			if retryOnExitCode == exitCode {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetBackoff returns the time to wait before the given retry, retries being counted from 1
+func (policy *CiRetryPolicy) GetBackoff(retry int) time.Duration {
+	multiplier := policy.BackoffMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	backoffSeconds := float64(policy.BackoffSeconds) * math.Pow(multiplier, float64(retry-1))
+	if policy.MaxBackoffSeconds > 0 && backoffSeconds > float64(policy.MaxBackoffSeconds) {
+		backoffSeconds = float64(policy.MaxBackoffSeconds)
+	}
+	return time.Duration(backoffSeconds * float64(time.Second))
+}
+
+// ClassifyCiFailure classifies the failure of a ci workflow by the message of its failed pod, the exit code is that of
+// the ci container, 0 if it is not known
+func ClassifyCiFailure(message string) (CiFailureReason, int) {
+	exitCode := 0
+	if matches := ciFailureExitCodeRegex.FindStringSubmatch(message); len(matches) > 1 {
+		exitCode, _ = strconv.Atoi(matches[1])
+	}
+	lowerMessage := strings.ToLower(message)
+	switch {
+	case strings.Contains(lowerMessage, "errimagepull") || strings.Contains(lowerMessage, "imagepullbackoff") ||
+		strings.Contains(lowerMessage, "pulling image"):
+		return CI_FAILURE_IMAGE_PULL, exitCode
+	case strings.Contains(lowerMessage, "push") && (strings.Contains(lowerMessage, "timeout") ||
+		strings.Contains(lowerMessage, "timed out") || strings.Contains(lowerMessage, "deadline exceeded")):
+		return CI_FAILURE_REGISTRY_PUSH_TIMEOUT, exitCode
+	case strings.Contains(lowerMessage, "oomkilled") || exitCode == oomKilledExitCode:
+		return CI_FAILURE_OOM_KILLED, exitCode
+	case strings.Contains(lowerMessage, "evict") || strings.Contains(lowerMessage, "low on resource") ||
+		strings.Contains(lowerMessage, "preempt") || lowerMessage == "pod deleted":
+		// pods of ci workflows are deleted when their node is drained or reclaimed
+		return CI_FAILURE_NODE_EVICTION, exitCode
+	case exitCode > 0:
+		return CI_FAILURE_USER_SCRIPT_EXIT_CODE, exitCode
+	}
+	return CI_FAILURE_UNKNOWN, exitCode
+}
+
+// ClassifyCiWorkflowFailure classifies the failure of a ci workflow, the reason reported by the ci runner, if it is
+// known, takes over from the one of its failed pod as the pod message does not tell which step of the build failed
+func ClassifyCiWorkflowFailure(reportedReason CiFailureReason, message string) (CiFailureReason, int) {
+	reason, exitCode := ClassifyCiFailure(message)
+	if len(reportedReason) > 0 && reportedReason != CI_FAILURE_UNKNOWN {
+		return reportedReason, exitCode
+	}
+	return reason, exitCode
+}
@@ -0,0 +1,95 @@
+package bean
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassifyCiFailure(t *testing.T) {
+	tests := []struct {
+		message  string
+		reason   CiFailureReason
+		exitCode int
+	}{
+		{message: "OOMKilled (exit code 137)", reason: CI_FAILURE_OOM_KILLED, exitCode: 137},
+		{message: "Error (exit code 137)", reason: CI_FAILURE_OOM_KILLED, exitCode: 137},
+		{message: "pod deleted", reason: CI_FAILURE_NODE_EVICTION},
+		{message: "The node was low on resource: ephemeral-storage.", reason: CI_FAILURE_NODE_EVICTION},
+		{message: "Pod was evicted", reason: CI_FAILURE_NODE_EVICTION},
+		{message: "ImagePullBackOff: Back-off pulling image \"quay.io/devtron/ci-runner\"", reason: CI_FAILURE_IMAGE_PULL},
+		{message: "failed to push image: i/o timeout (exit code 1)", reason: CI_FAILURE_REGISTRY_PUSH_TIMEOUT, exitCode: 1},
+		{message: "Error (exit code 2)", reason: CI_FAILURE_USER_SCRIPT_EXIT_CODE, exitCode: 2},
+		{message: "workflow shutdown with strategy: Terminate", reason: CI_FAILURE_UNKNOWN},
+	}
+	for _, tt := range tests {
+		reason, exitCode := ClassifyCiFailure(tt.message)
+		if reason != tt.reason || exitCode != tt.exitCode {
+			t.Errorf("ClassifyCiFailure(%q) = %s, %d, want %s, %d", tt.message, reason, exitCode, tt.reason, tt.exitCode)
+		}
+	}
+}
+
+func TestClassifyCiWorkflowFailure(t *testing.T) {
+	tests := []struct {
+		reportedReason CiFailureReason
+		message        string
+		reason         CiFailureReason
+		exitCode       int
+	}{
+		{reportedReason: CI_FAILURE_REGISTRY_PUSH_TIMEOUT, message: "Error (exit code 1)", reason: CI_FAILURE_REGISTRY_PUSH_TIMEOUT, exitCode: 1},
+		{reportedReason: CI_FAILURE_UNKNOWN, message: "Error (exit code 2)", reason: CI_FAILURE_USER_SCRIPT_EXIT_CODE, exitCode: 2},
+		{message: "OOMKilled (exit code 137)", reason: CI_FAILURE_OOM_KILLED, exitCode: 137},
+	}
+	for _, tt := range tests {
+		reason, exitCode := ClassifyCiWorkflowFailure(tt.reportedReason, tt.message)
+		if reason != tt.reason || exitCode != tt.exitCode {
+			t.Errorf("ClassifyCiWorkflowFailure(%s, %q) = %s, %d, want %s, %d", tt.reportedReason, tt.message, reason, exitCode, tt.reason, tt.exitCode)
+		}
+	}
+}
+
+func TestCiRetryPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  CiRetryPolicy
+		wantErr bool
+	}{
+		{name: "valid", policy: CiRetryPolicy{MaxAttempts: 3, BackoffSeconds: 30, BackoffMultiplier: 2, MaxBackoffSeconds: 300, RetryOn: []CiFailureReason{CI_FAILURE_NODE_EVICTION, CI_FAILURE_USER_SCRIPT_EXIT_CODE}, RetryOnExitCodes: []int{3}}},
+		{name: "no attempts", policy: CiRetryPolicy{RetryOn: []CiFailureReason{CI_FAILURE_OOM_KILLED}}, wantErr: true},
+		{name: "no failure reason", policy: CiRetryPolicy{MaxAttempts: 2}, wantErr: true},
+		{name: "invalid failure reason", policy: CiRetryPolicy{MaxAttempts: 2, RetryOn: []CiFailureReason{"TIMEOUT"}}, wantErr: true},
+		{name: "multiplier less than 1", policy: CiRetryPolicy{MaxAttempts: 2, BackoffMultiplier: 0.5, RetryOn: []CiFailureReason{CI_FAILURE_OOM_KILLED}}, wantErr: true},
+		{name: "max backoff less than backoff", policy: CiRetryPolicy{MaxAttempts: 2, BackoffSeconds: 60, MaxBackoffSeconds: 30, RetryOn: []CiFailureReason{CI_FAILURE_OOM_KILLED}}, wantErr: true},
+		{name: "exit codes without user script", policy: CiRetryPolicy{MaxAttempts: 2, RetryOn: []CiFailureReason{CI_FAILURE_OOM_KILLED}, RetryOnExitCodes: []int{1}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCiRetryPolicyShouldRetry(t *testing.T) {
+	policy := &CiRetryPolicy{RetryOn: []CiFailureReason{CI_FAILURE_NODE_EVICTION, CI_FAILURE_USER_SCRIPT_EXIT_CODE}, RetryOnExitCodes: []int{3, 4}}
+	if !policy.ShouldRetry(CI_FAILURE_NODE_EVICTION, 0) || !policy.ShouldRetry(CI_FAILURE_USER_SCRIPT_EXIT_CODE, 4) {
+		t.Errorf("ShouldRetry() = false for a failure retried by the policy")
+	}
+	if policy.ShouldRetry(CI_FAILURE_USER_SCRIPT_EXIT_CODE, 2) || policy.ShouldRetry(CI_FAILURE_OOM_KILLED, 137) {
+		t.Errorf("ShouldRetry() = true for a failure not retried by the policy")
+	}
+}
+
+func TestCiRetryPolicyGetBackoff(t *testing.T) {
+	policy := &CiRetryPolicy{BackoffSeconds: 10, BackoffMultiplier: 3, MaxBackoffSeconds: 60}
+	for retry, want := range map[int]time.Duration{1: 10 * time.Second, 2: 30 * time.Second, 3: 60 * time.Second, 4: 60 * time.Second} {
+		if got := policy.GetBackoff(retry); got != want {
+			t.Errorf("GetBackoff(%d) = %v, want %v", retry, got, want)
+		}
+	}
+	policy = &CiRetryPolicy{BackoffSeconds: 10}
+	if got := policy.GetBackoff(3); got != 10*time.Second {
+		t.Errorf("GetBackoff(3) = %v without multiplier, want 10s", got)
+	}
+}
@@ -0,0 +1,133 @@
+package bean
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+)
+
+type ApprovalActionType string
+
+const (
+	APPROVAL_REQUEST_ACTION ApprovalActionType = "REQUEST"
+	APPROVAL_APPROVE_ACTION ApprovalActionType = "APPROVE"
+	APPROVAL_REJECT_ACTION  ApprovalActionType = "REJECT"
+	APPROVAL_CANCEL_ACTION  ApprovalActionType = "CANCEL"
+)
+
+type ApprovalStatus string
+
+const (
+	APPROVAL_STATUS_REQUESTED ApprovalStatus = "REQUESTED"
+	APPROVAL_STATUS_APPROVED  ApprovalStatus = "APPROVED"
+	APPROVAL_STATUS_REJECTED  ApprovalStatus = "REJECTED"
+	APPROVAL_STATUS_CONSUMED  ApprovalStatus = "CONSUMED"
+	APPROVAL_STATUS_CANCELLED ApprovalStatus = "CANCELLED"
+)
+
+type DeploymentApprovalActionRequest struct {
+	AppId             int                `json:"appId" validate:"required"`
+	PipelineId        int                `json:"pipelineId" validate:"required"`
+	ArtifactId        int                `json:"artifactId" validate:"required"`
+	ApprovalRequestId int                `json:"approvalRequestId"`
+	ActionType        ApprovalActionType `json:"actionType" validate:"oneof=REQUEST APPROVE REJECT CANCEL"`
+	Comments          string             `json:"comments" validate:"max=1000"`
+	UserId            int32              `json:"-"`
+}
+
+type DeploymentApprovalData struct {
+	ApprovalRequestId int                           `json:"approvalRequestId"`
+	PipelineId        int                           `json:"pipelineId"`
+	ArtifactId        int                           `json:"artifactId"`
+	Status            ApprovalStatus                `json:"status"`
+	RequiredCount     int                           `json:"requiredCount"`
+	ApprovedCount     int                           `json:"approvedCount"`
+	ExpiryInMinutes   int                           `json:"expiryInMinutes"`
+	RequestedBy       string                        `json:"requestedBy"`
+	RequestedOn       time.Time                     `json:"requestedOn"`
+	ApprovalUserData  []*DeploymentApprovalUserData `json:"approvalUserData"`
+}
+
+type DeploymentApprovalUserData struct {
+	UserId       int32     `json:"userId"`
+	UserEmail    string    `json:"userEmail"`
+	UserResponse string    `json:"userResponse"`
+	Comments     string    `json:"comments"`
+	ResponseTime time.Time `json:"responseTime"`
+	Expired      bool      `json:"expired"`
+}
+
+// IsApprovalExpired reports whether the approval of the user is older than the expiry of the approval config, approvals
+// never expire when no expiry is set
+func IsApprovalExpired(userData *pipelineConfig.DeploymentApprovalUserData, expiryInMinutes int, now time.Time) bool {
+	if expiryInMinutes <= 0 {
+		return false
+	}
+	return now.After(userData.UpdatedOn.Add(time.Duration(expiryInMinutes) * time.Minute))
+}
+
+// CountValidApprovals counts the approvals of the users which have not expired
+func CountValidApprovals(userDataList []*pipelineConfig.DeploymentApprovalUserData, expiryInMinutes int, now time.Time) int {
+	approvedCount := 0
+	for _, userData := range userDataList {
+		if userData.UserResponse == pipelineConfig.APPROVED && !IsApprovalExpired(userData, expiryInMinutes, now) {
+			approvedCount++
+		}
+	}
+	return approvedCount
+}
+
+// IsApprovalRejected reports whether any user rejected the request, a rejection vetoes the request irrespective of
+// the approvals received and never expires
+func IsApprovalRejected(userDataList []*pipelineConfig.DeploymentApprovalUserData) bool {
+	for _, userData := range userDataList {
+		if userData.UserResponse == pipelineConfig.REJECTED {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildApprovalData returns the approval data of the request, its status is derived from the request state and the
+// approvals which have not expired
+func BuildApprovalData(approvalRequest *pipelineConfig.DeploymentApprovalRequest, approvalConfig *pipelineConfig.UserApprovalConfig,
+	userDataList []*pipelineConfig.DeploymentApprovalUserData, userEmails map[int32]string, now time.Time) *DeploymentApprovalData {
+	approvalData := &DeploymentApprovalData{
+		ApprovalRequestId: approvalRequest.Id,
+		PipelineId:        approvalRequest.PipelineId,
+		ArtifactId:        approvalRequest.ArtifactId,
+		RequiredCount:     approvalConfig.RequiredCount,
+		ExpiryInMinutes:   approvalConfig.ExpiryInMinutes,
+		RequestedBy:       userEmails[approvalRequest.CreatedBy],
+		RequestedOn:       approvalRequest.CreatedOn,
+		ApprovedCount:     CountValidApprovals(userDataList, approvalConfig.ExpiryInMinutes, now),
+		ApprovalUserData:  make([]*DeploymentApprovalUserData, 0, len(userDataList)),
+	}
+	for _, userData := range userDataList {
+		userResponse := string(APPROVAL_APPROVE_ACTION)
+		if userData.UserResponse == pipelineConfig.REJECTED {
+			userResponse = string(APPROVAL_REJECT_ACTION)
+		}
+		approvalData.ApprovalUserData = append(approvalData.ApprovalUserData, &DeploymentApprovalUserData{
+			UserId:       userData.UserId,
+			UserEmail:    userEmails[userData.UserId],
+			UserResponse: userResponse,
+			Comments:     userData.Comments,
+			ResponseTime: userData.UpdatedOn,
+			Expired:      IsApprovalExpired(userData, approvalConfig.ExpiryInMinutes, now),
+		})
+	}
+	switch {
+	case approvalRequest.ArtifactDeploymentTriggered:
+		approvalData.Status = APPROVAL_STATUS_CONSUMED
+	case !approvalRequest.Active:
+		approvalData.Status = APPROVAL_STATUS_CANCELLED
+	case IsApprovalRejected(userDataList):
+		approvalData.Status = APPROVAL_STATUS_REJECTED
+	case approvalData.ApprovedCount >= approvalData.RequiredCount:
+		approvalData.Status = APPROVAL_STATUS_APPROVED
+	default:
+		approvalData.Status = APPROVAL_STATUS_REQUESTED
+	}
+	return approvalData
+}
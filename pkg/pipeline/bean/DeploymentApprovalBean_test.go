@@ -0,0 +1,68 @@
+package bean
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/sql"
+)
+
+func TestCountValidApprovals(t *testing.T) {
+	now := time.Date(2023, time.November, 15, 12, 0, 0, 0, time.UTC)
+	approvalAt := func(response pipelineConfig.ApprovalUserResponse, respondedAt time.Time) *pipelineConfig.DeploymentApprovalUserData {
+		return &pipelineConfig.DeploymentApprovalUserData{UserResponse: response, AuditLog: sql.AuditLog{UpdatedOn: respondedAt}}
+	}
+	userData := []*pipelineConfig.DeploymentApprovalUserData{
+		approvalAt(pipelineConfig.APPROVED, now.Add(-10*time.Minute)),
+		approvalAt(pipelineConfig.APPROVED, now.Add(-2*time.Hour)),
+		approvalAt(pipelineConfig.REJECTED, now.Add(-5*time.Minute)),
+	}
+	tests := []struct {
+		name            string
+		expiryInMinutes int
+		want            int
+	}{
+		{name: "no expiry counts all approvals", expiryInMinutes: 0, want: 2},
+		{name: "expired approvals are not counted", expiryInMinutes: 60, want: 1},
+		{name: "all approvals expired", expiryInMinutes: 5, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CountValidApprovals(userData, tt.expiryInMinutes, now); got != tt.want {
+				t.Errorf("CountValidApprovals() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildApprovalDataStatus(t *testing.T) {
+	now := time.Date(2023, time.November, 15, 12, 0, 0, 0, time.UTC)
+	approvalConfig := &pipelineConfig.UserApprovalConfig{RequiredCount: 1, ExpiryInMinutes: 60}
+	approved := []*pipelineConfig.DeploymentApprovalUserData{
+		{UserId: 2, UserResponse: pipelineConfig.APPROVED, AuditLog: sql.AuditLog{UpdatedOn: now.Add(-time.Minute)}},
+	}
+	rejected := append([]*pipelineConfig.DeploymentApprovalUserData{
+		{UserId: 3, UserResponse: pipelineConfig.REJECTED, AuditLog: sql.AuditLog{UpdatedOn: now.Add(-2 * time.Hour)}},
+	}, approved...)
+	tests := []struct {
+		name     string
+		request  *pipelineConfig.DeploymentApprovalRequest
+		userData []*pipelineConfig.DeploymentApprovalUserData
+		want     ApprovalStatus
+	}{
+		{name: "pending approvals", request: &pipelineConfig.DeploymentApprovalRequest{Active: true}, want: APPROVAL_STATUS_REQUESTED},
+		{name: "enough approvals", request: &pipelineConfig.DeploymentApprovalRequest{Active: true}, userData: approved, want: APPROVAL_STATUS_APPROVED},
+		{name: "rejection vetoes approvals", request: &pipelineConfig.DeploymentApprovalRequest{Active: true}, userData: rejected, want: APPROVAL_STATUS_REJECTED},
+		{name: "cancelled request", request: &pipelineConfig.DeploymentApprovalRequest{}, userData: approved, want: APPROVAL_STATUS_CANCELLED},
+		{name: "consumed by deployment", request: &pipelineConfig.DeploymentApprovalRequest{ArtifactDeploymentTriggered: true}, userData: approved, want: APPROVAL_STATUS_CONSUMED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildApprovalData(tt.request, approvalConfig, tt.userData, map[int32]string{}, now)
+			if got.Status != tt.want {
+				t.Errorf("BuildApprovalData() status = %v, want %v", got.Status, tt.want)
+			}
+		})
+	}
+}
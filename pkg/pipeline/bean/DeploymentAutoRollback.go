@@ -0,0 +1,52 @@
+package bean
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/devtron-labs/common-lib/utils/k8s/health"
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"k8s.io/utils/strings/slices"
+)
+
+// default observation window used when auto rollback is enabled without configuring one
+const defaultAutoRollbackObservationWindowInMinutes = 30
+
+// runner statuses which are considered as a degraded release for auto rollback
+var autoRollbackEligibleStatuses = []string{pipelineConfig.WorkflowFailed, pipelineConfig.WorkflowTimedOut, string(health.HealthStatusDegraded)}
+
+// IsEligibleForAutoRollback reports whether the deploy runner has degraded within the observation window of the auto
+// rollback config, rollbacks and superseded deployments are never rolled back
+func IsEligibleForAutoRollback(wfr *pipelineConfig.CdWorkflowRunner, autoRollbackConfig *pipelineConfig.AutoRollbackConfig, now time.Time) bool {
+	if autoRollbackConfig == nil || !autoRollbackConfig.Enabled {
+		return false
+	}
+	// a degraded rollback is not rolled back again, this avoids rollback loops
+	if wfr.WorkflowType != bean.CD_WORKFLOW_TYPE_DEPLOY || wfr.TriggerType == pipelineConfig.CD_TRIGGER_TYPE_AUTO_ROLLBACK {
+		return false
+	}
+	if !slices.Contains(autoRollbackEligibleStatuses, wfr.Status) || wfr.Message == pipelineConfig.NEW_DEPLOYMENT_INITIATED {
+		return false
+	}
+	observationWindow := autoRollbackConfig.ObservationWindowInMinutes
+	if observationWindow == 0 {
+		observationWindow = defaultAutoRollbackObservationWindowInMinutes
+	}
+	return now.Sub(wfr.StartedOn) <= time.Duration(observationWindow)*time.Minute
+}
+
+// GetAutoRollbackReason returns the reason of the rollback of the degraded runner, shown in the timeline and the
+// notification
+func GetAutoRollbackReason(wfr *pipelineConfig.CdWorkflowRunner) string {
+	switch wfr.Status {
+	case pipelineConfig.WorkflowTimedOut:
+		return "timed out before becoming healthy"
+	case string(health.HealthStatusDegraded):
+		return "degraded after release"
+	}
+	if len(wfr.Message) > 0 {
+		return fmt.Sprintf("failed: %s", wfr.Message)
+	}
+	return "failed"
+}
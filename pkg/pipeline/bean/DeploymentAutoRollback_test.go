@@ -0,0 +1,44 @@
+package bean
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+)
+
+func TestIsEligibleForAutoRollback(t *testing.T) {
+	now := time.Date(2023, time.November, 15, 12, 0, 0, 0, time.UTC)
+	enabledConfig := &pipelineConfig.AutoRollbackConfig{Enabled: true, ObservationWindowInMinutes: 15}
+	runner := func(status string, startedBefore time.Duration) *pipelineConfig.CdWorkflowRunner {
+		return &pipelineConfig.CdWorkflowRunner{WorkflowType: bean.CD_WORKFLOW_TYPE_DEPLOY, Status: status, StartedOn: now.Add(-startedBefore)}
+	}
+	rollbackRunner := runner(pipelineConfig.WorkflowFailed, 5*time.Minute)
+	rollbackRunner.TriggerType = pipelineConfig.CD_TRIGGER_TYPE_AUTO_ROLLBACK
+	supersededRunner := runner(pipelineConfig.WorkflowFailed, 5*time.Minute)
+	supersededRunner.Message = pipelineConfig.NEW_DEPLOYMENT_INITIATED
+	tests := []struct {
+		name   string
+		wfr    *pipelineConfig.CdWorkflowRunner
+		config *pipelineConfig.AutoRollbackConfig
+		want   bool
+	}{
+		{name: "auto rollback not configured", wfr: runner(pipelineConfig.WorkflowFailed, 5*time.Minute), config: nil, want: false},
+		{name: "auto rollback disabled", wfr: runner(pipelineConfig.WorkflowFailed, 5*time.Minute), config: &pipelineConfig.AutoRollbackConfig{ObservationWindowInMinutes: 15}, want: false},
+		{name: "failed within observation window", wfr: runner(pipelineConfig.WorkflowFailed, 5*time.Minute), config: enabledConfig, want: true},
+		{name: "timed out within observation window", wfr: runner(pipelineConfig.WorkflowTimedOut, 14*time.Minute), config: enabledConfig, want: true},
+		{name: "failed after observation window", wfr: runner(pipelineConfig.WorkflowFailed, 20*time.Minute), config: enabledConfig, want: false},
+		{name: "default observation window", wfr: runner(pipelineConfig.WorkflowFailed, 20*time.Minute), config: &pipelineConfig.AutoRollbackConfig{Enabled: true}, want: true},
+		{name: "healthy deployment", wfr: runner(pipelineConfig.WorkflowSucceeded, 5*time.Minute), config: enabledConfig, want: false},
+		{name: "superseded deployment", wfr: supersededRunner, config: enabledConfig, want: false},
+		{name: "failed auto rollback", wfr: rollbackRunner, config: enabledConfig, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsEligibleForAutoRollback(tt.wfr, tt.config, now); got != tt.want {
+				t.Errorf("IsEligibleForAutoRollback() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
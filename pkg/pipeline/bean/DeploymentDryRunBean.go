@@ -0,0 +1,34 @@
+package bean
+
+type DryRunChangeType string
+
+const (
+	DRY_RUN_CHANGE_TYPE_CREATE    DryRunChangeType = "CREATE"
+	DRY_RUN_CHANGE_TYPE_UPDATE    DryRunChangeType = "UPDATE"
+	DRY_RUN_CHANGE_TYPE_UNCHANGED DryRunChangeType = "UNCHANGED"
+)
+
+type DeploymentDryRunResponse struct {
+	AppId        int                   `json:"appId"`
+	PipelineId   int                   `json:"pipelineId"`
+	CiArtifactId int                   `json:"ciArtifactId"`
+	Image        string                `json:"image"`
+	ReleaseName  string                `json:"releaseName"`
+	Namespace    string                `json:"namespace"`
+	Resources    []*DryRunResourceDiff `json:"resources"`
+}
+
+// DryRunResourceDiff is the change a deployment would make to one resource of the release. LiveManifest only has the
+// fields which are rendered by the chart, so that fields defaulted by the cluster do not show up as changes
+type DryRunResourceDiff struct {
+	Group            string           `json:"group"`
+	Version          string           `json:"version"`
+	Kind             string           `json:"kind"`
+	Name             string           `json:"name"`
+	Namespace        string           `json:"namespace"`
+	ChangeType       DryRunChangeType `json:"changeType"`
+	RenderedManifest string           `json:"renderedManifest"`
+	LiveManifest     string           `json:"liveManifest,omitempty"`
+	Diff             string           `json:"diff,omitempty"`
+	Error            string           `json:"error,omitempty"`
+}
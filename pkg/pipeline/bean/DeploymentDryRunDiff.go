@@ -0,0 +1,139 @@
+package bean
+
+import (
+	"reflect"
+
+	"github.com/devtron-labs/devtron/pkg/variables/models"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	secretKind = "Secret"
+	// marks a masked secret value which will be changed by the deployment
+	hiddenUpdatedValue = models.HiddenValue + "(updated)"
+)
+
+// BuildDryRunResourceDiff compares rendered with live, live is nil when the resource does not exist in the cluster. If live
+// could not be fetched liveErr is reported on the resource and it is marked as updated
+func BuildDryRunResourceDiff(rendered unstructured.Unstructured, live map[string]interface{}, liveErr error, isSuperAdmin bool) *DryRunResourceDiff {
+	gvk := rendered.GroupVersionKind()
+	resourceDiff := &DryRunResourceDiff{
+		Group:     gvk.Group,
+		Version:   gvk.Version,
+		Kind:      gvk.Kind,
+		Name:      rendered.GetName(),
+		Namespace: rendered.GetNamespace(),
+	}
+	renderedObject := rendered.Object
+	if liveErr != nil {
+		resourceDiff.ChangeType = DRY_RUN_CHANGE_TYPE_UPDATE
+		resourceDiff.Error = liveErr.Error()
+	} else if live == nil {
+		resourceDiff.ChangeType = DRY_RUN_CHANGE_TYPE_CREATE
+	} else {
+		prunedLive, _ := pruneToTemplate(live, renderedObject).(map[string]interface{})
+		if reflect.DeepEqual(renderedObject, prunedLive) {
+			resourceDiff.ChangeType = DRY_RUN_CHANGE_TYPE_UNCHANGED
+		} else {
+			resourceDiff.ChangeType = DRY_RUN_CHANGE_TYPE_UPDATE
+		}
+		live = prunedLive
+	}
+	if gvk.Kind == secretKind && !isSuperAdmin {
+		renderedObject, live = maskSecretData(renderedObject, live)
+	}
+	renderedManifest, _ := yaml.Marshal(renderedObject)
+	resourceDiff.RenderedManifest = string(renderedManifest)
+	if live != nil {
+		liveManifest, _ := yaml.Marshal(live)
+		resourceDiff.LiveManifest = string(liveManifest)
+	}
+	if resourceDiff.ChangeType != DRY_RUN_CHANGE_TYPE_UNCHANGED {
+		resourceDiff.Diff, _ = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(resourceDiff.LiveManifest),
+			B:        difflib.SplitLines(resourceDiff.RenderedManifest),
+			FromFile: "live",
+			ToFile:   "rendered",
+			Context:  3,
+		})
+	}
+	return resourceDiff
+}
+
+// pruneToTemplate keeps only the fields of live which are also present in template, maps are pruned by key and lists
+// by index. Fields defaulted or managed by the cluster (status, uid, etc.) are dropped this way
+func pruneToTemplate(live interface{}, template interface{}) interface{} {
+	switch templateValue := template.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		pruned := make(map[string]interface{}, len(templateValue))
+		for key, value := range templateValue {
+			if liveValue, found := liveMap[key]; found {
+				pruned[key] = pruneToTemplate(liveValue, value)
+			}
+		}
+		return pruned
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok {
+			return live
+		}
+		pruned := make([]interface{}, 0, len(liveList))
+		for i, liveValue := range liveList {
+			if i < len(templateValue) {
+				liveValue = pruneToTemplate(liveValue, templateValue[i])
+			}
+			pruned = append(pruned, liveValue)
+		}
+		return pruned
+	}
+	return live
+}
+
+// maskSecretData hides values of data and stringData of secret objects, values which differ between rendered and live
+// are hidden with a different marker so that the change is still visible in the diff
+func maskSecretData(rendered map[string]interface{}, live map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	maskedRendered := copyTopLevel(rendered)
+	maskedLive := copyTopLevel(live)
+	for _, dataKey := range []string{"data", "stringData"} {
+		renderedData, _ := rendered[dataKey].(map[string]interface{})
+		var liveData map[string]interface{}
+		if live != nil {
+			liveData, _ = live[dataKey].(map[string]interface{})
+		}
+		if renderedData != nil {
+			masked := make(map[string]interface{}, len(renderedData))
+			for key, value := range renderedData {
+				masked[key] = models.HiddenValue
+				if liveValue, found := liveData[key]; live != nil && (!found || !reflect.DeepEqual(value, liveValue)) {
+					masked[key] = hiddenUpdatedValue
+				}
+			}
+			maskedRendered[dataKey] = masked
+		}
+		if liveData != nil {
+			masked := make(map[string]interface{}, len(liveData))
+			for key := range liveData {
+				masked[key] = models.HiddenValue
+			}
+			maskedLive[dataKey] = masked
+		}
+	}
+	return maskedRendered, maskedLive
+}
+
+func copyTopLevel(object map[string]interface{}) map[string]interface{} {
+	if object == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		copied[key] = value
+	}
+	return copied
+}
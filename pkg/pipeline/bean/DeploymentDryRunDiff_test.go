@@ -0,0 +1,108 @@
+package bean
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/devtron-labs/devtron/pkg/variables/models"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPruneToTemplate(t *testing.T) {
+	live := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "app", "uid": "1234", "resourceVersion": "10"},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:v1", "imagePullPolicy": "IfNotPresent"},
+				map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+			},
+		},
+		"status": map[string]interface{}{"readyReplicas": int64(2)},
+	}
+	template := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "app"},
+		"spec": map[string]interface{}{
+			"replicas":   int64(3),
+			"containers": []interface{}{map[string]interface{}{"name": "app", "image": "app:v2"}},
+		},
+	}
+	want := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "app"},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:v1"},
+				map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+			},
+		},
+	}
+	if got := pruneToTemplate(live, template); !reflect.DeepEqual(got, want) {
+		t.Errorf("pruneToTemplate() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildResourceDiff(t *testing.T) {
+	configMap := func(value string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "app-cm", "namespace": "demo"},
+			"data":       map[string]interface{}{"key": value},
+		}
+	}
+	liveConfigMap := configMap("v1")
+	liveConfigMap["metadata"].(map[string]interface{})["uid"] = "1234"
+	tests := []struct {
+		name     string
+		rendered map[string]interface{}
+		live     map[string]interface{}
+		liveErr  error
+		want     DryRunChangeType
+		wantDiff bool
+	}{
+		{name: "resource not in cluster", rendered: configMap("v1"), want: DRY_RUN_CHANGE_TYPE_CREATE, wantDiff: true},
+		{name: "only server side fields differ", rendered: configMap("v1"), live: liveConfigMap, want: DRY_RUN_CHANGE_TYPE_UNCHANGED},
+		{name: "data changed", rendered: configMap("v2"), live: liveConfigMap, want: DRY_RUN_CHANGE_TYPE_UPDATE, wantDiff: true},
+		{name: "live fetch failed", rendered: configMap("v1"), liveErr: errors.New("forbidden"), want: DRY_RUN_CHANGE_TYPE_UPDATE, wantDiff: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildDryRunResourceDiff(unstructured.Unstructured{Object: tt.rendered}, tt.live, tt.liveErr, false)
+			if got.ChangeType != tt.want {
+				t.Errorf("BuildDryRunResourceDiff() changeType = %v, want %v", got.ChangeType, tt.want)
+			}
+			if (len(got.Diff) > 0) != tt.wantDiff {
+				t.Errorf("BuildDryRunResourceDiff() diff = %q, wantDiff %v", got.Diff, tt.wantDiff)
+			}
+		})
+	}
+}
+
+func TestMaskSecretData(t *testing.T) {
+	rendered := map[string]interface{}{
+		"kind": "Secret",
+		"data": map[string]interface{}{"same": "YQ==", "changed": "Yg==", "added": "Yw=="},
+	}
+	live := map[string]interface{}{
+		"kind": "Secret",
+		"data": map[string]interface{}{"same": "YQ==", "changed": "ZA=="},
+	}
+	maskedRendered, maskedLive := maskSecretData(rendered, live)
+	wantRendered := map[string]interface{}{"same": models.HiddenValue, "changed": hiddenUpdatedValue, "added": hiddenUpdatedValue}
+	wantLive := map[string]interface{}{"same": models.HiddenValue, "changed": models.HiddenValue}
+	if !reflect.DeepEqual(maskedRendered["data"], wantRendered) {
+		t.Errorf("maskSecretData() rendered = %v, want %v", maskedRendered["data"], wantRendered)
+	}
+	if !reflect.DeepEqual(maskedLive["data"], wantLive) {
+		t.Errorf("maskSecretData() live = %v, want %v", maskedLive["data"], wantLive)
+	}
+	if rendered["data"].(map[string]interface{})["changed"] != "Yg==" {
+		t.Errorf("maskSecretData() modified the rendered object")
+	}
+	maskedRendered, _ = maskSecretData(rendered, nil)
+	if maskedRendered["data"].(map[string]interface{})["added"] != models.HiddenValue {
+		t.Errorf("maskSecretData() marked a new secret as updated")
+	}
+}
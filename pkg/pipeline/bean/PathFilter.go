@@ -0,0 +1,143 @@
+package bean
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+)
+
+const pathGlobAnyDirectories = "**"
+
+// ValidatePathFilters validates include or exclude path globs of a ci material
+func ValidatePathFilters(globs []string) error {
+	for _, glob := range globs {
+		globSegments := getPathGlobSegments(glob)
+		if len(globSegments) == 0 {
+			return fmt.Errorf("path filter can not be empty")
+		}
+		for _, segment := range globSegments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid path filter %q: %v", glob, err)
+			}
+		}
+	}
+	return nil
+}
+
+// GetChangedFilesSince returns the files changed by headCommit and the commits before it down to, but not including,
+// sinceCommit, commits are ordered newest first as listed by git sensor. It returns false if either commit is not in
+// the list or the changes of a commit in between are not known, the files changed since then are not known then.
+func GetChangedFilesSince(commits []pipelineConfig.GitCommit, headCommit string, sinceCommit string) ([]string, bool) {
+	var changedFiles []string
+	isChangedFile := make(map[string]bool)
+	isHeadFound := false
+	for _, commit := range commits {
+		if !isHeadFound {
+			if commit.Commit != headCommit {
+				continue
+			}
+			isHeadFound = true
+		}
+		if commit.Commit == sinceCommit {
+			return changedFiles, true
+		}
+		if len(commit.Changes) == 0 {
+			return nil, false
+		}
+		for _, file := range commit.Changes {
+			if !isChangedFile[file] {
+				isChangedFile[file] = true
+				changedFiles = append(changedFiles, file)
+			}
+		}
+	}
+	return nil, false
+}
+
+// GetPathFilterResult matches the changes of the commit, the files changed since the last build up to it, against the
+// path filters of the material, it returns nil if the material has no path filters
+func GetPathFilterResult(material *pipelineConfig.CiPipelineMaterial, commit pipelineConfig.GitCommit) *pipelineConfig.PathFilterResult {
+	if material == nil || (len(material.IncludePaths) == 0 && len(material.ExcludePaths) == 0) {
+		return nil
+	}
+	result := &pipelineConfig.PathFilterResult{
+		CiPipelineMaterialId: material.Id,
+		Commit:               commit.Commit,
+		IncludePaths:         material.IncludePaths,
+		ExcludePaths:         material.ExcludePaths,
+		ChangedFileCount:     len(commit.Changes),
+	}
+	if len(commit.Changes) == 0 {
+		// changed files are not known for every source type, such commits are built rather than risk missing a change
+		return result
+	}
+	result.MatchedFiles = MatchPathFilters(material.IncludePaths, material.ExcludePaths, commit.Changes)
+	result.Skipped = len(result.MatchedFiles) == 0
+	return result
+}
+
+// MatchPathFilters returns the files matching any of the include globs, or all files if there are none, and none of
+// the exclude globs
+func MatchPathFilters(includePaths []string, excludePaths []string, files []string) []string {
+	var matchedFiles []string
+	for _, file := range files {
+		if len(includePaths) > 0 && !matchAnyPathGlob(includePaths, file) {
+			continue
+		}
+		if matchAnyPathGlob(excludePaths, file) {
+			continue
+		}
+		matchedFiles = append(matchedFiles, file)
+	}
+	return matchedFiles
+}
+
+// MatchPathGlob reports whether the file matches the glob, ** matches any number of directories and a glob ending
+// with / matches everything under that directory
+func MatchPathGlob(glob string, file string) bool {
+	return matchPathSegments(getPathGlobSegments(glob), strings.Split(strings.Trim(path.Clean("/"+file), "/"), "/"))
+}
+
+func matchAnyPathGlob(globs []string, file string) bool {
+	for _, glob := range globs {
+		if MatchPathGlob(glob, file) {
+			return true
+		}
+	}
+	return false
+}
+
+func getPathGlobSegments(glob string) []string {
+	glob = strings.TrimPrefix(strings.TrimSpace(glob), "./")
+	if strings.HasSuffix(glob, "/") {
+		glob += pathGlobAnyDirectories
+	}
+	glob = strings.Trim(glob, "/")
+	if len(glob) == 0 {
+		return nil
+	}
+	return strings.Split(glob, "/")
+}
+
+func matchPathSegments(globSegments []string, fileSegments []string) bool {
+	if len(globSegments) == 0 {
+		return len(fileSegments) == 0
+	}
+	if globSegments[0] == pathGlobAnyDirectories {
+		for i := 0; i <= len(fileSegments); i++ {
+			if matchPathSegments(globSegments[1:], fileSegments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(fileSegments) == 0 {
+		return false
+	}
+	if matched, err := path.Match(globSegments[0], fileSegments[0]); err != nil || !matched {
+		return false
+	}
+	return matchPathSegments(globSegments[1:], fileSegments[1:])
+}
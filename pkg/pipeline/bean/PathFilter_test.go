@@ -0,0 +1,100 @@
+package bean
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+)
+
+func TestMatchPathGlob(t *testing.T) {
+	tests := []struct {
+		glob  string
+		file  string
+		match bool
+	}{
+		{glob: "services/api/**", file: "services/api/main.go", match: true},
+		{glob: "services/api/**", file: "services/api/handler/user.go", match: true},
+		{glob: "services/api/", file: "services/api/handler/user.go", match: true},
+		{glob: "services/api/**", file: "services/web/main.go", match: false},
+		{glob: "**/*.md", file: "README.md", match: true},
+		{glob: "**/*.md", file: "docs/setup/install.md", match: true},
+		{glob: "*.md", file: "docs/install.md", match: false},
+		{glob: "./libs/*/go.mod", file: "/libs/common/go.mod", match: true},
+		{glob: "services/**/Dockerfile", file: "services/Dockerfile", match: true},
+		{glob: "services/**/Dockerfile", file: "services/api/build/Dockerfile", match: true},
+		{glob: "services/**/Dockerfile", file: "services/api/Dockerfile.dev", match: false},
+	}
+	for _, tt := range tests {
+		if got := MatchPathGlob(tt.glob, tt.file); got != tt.match {
+			t.Errorf("MatchPathGlob(%q, %q) = %v, want %v", tt.glob, tt.file, got, tt.match)
+		}
+	}
+}
+
+func TestValidatePathFilters(t *testing.T) {
+	if err := ValidatePathFilters([]string{"services/api/**", "**/*.go"}); err != nil {
+		t.Errorf("ValidatePathFilters() error = %v", err)
+	}
+	if err := ValidatePathFilters([]string{"services/[api"}); err == nil {
+		t.Errorf("ValidatePathFilters() expected error for malformed glob")
+	}
+	if err := ValidatePathFilters([]string{" "}); err == nil {
+		t.Errorf("ValidatePathFilters() expected error for empty glob")
+	}
+}
+
+func TestGetPathFilterResult(t *testing.T) {
+	material := &pipelineConfig.CiPipelineMaterial{
+		Id:           3,
+		IncludePaths: []string{"services/api/**", "libs/**"},
+		ExcludePaths: []string{"**/*.md"},
+	}
+	commit := pipelineConfig.GitCommit{Commit: "abc", Changes: []string{"services/api/README.md", "services/web/main.go"}}
+	result := GetPathFilterResult(material, commit)
+	if result == nil || !result.Skipped || len(result.MatchedFiles) != 0 || result.ChangedFileCount != 2 || result.Commit != "abc" {
+		t.Errorf("GetPathFilterResult() = %+v, want skipped", result)
+	}
+
+	commit.Changes = append(commit.Changes, "libs/log/log.go")
+	result = GetPathFilterResult(material, commit)
+	if result.Skipped || !reflect.DeepEqual(result.MatchedFiles, []string{"libs/log/log.go"}) {
+		t.Errorf("GetPathFilterResult() = %+v, want libs/log/log.go matched", result)
+	}
+
+	// commits whose changes are not known are built
+	commit.Changes = nil
+	if result = GetPathFilterResult(material, commit); result.Skipped {
+		t.Errorf("GetPathFilterResult() skipped commit without changes")
+	}
+	if result = GetPathFilterResult(&pipelineConfig.CiPipelineMaterial{Id: 4}, commit); result != nil {
+		t.Errorf("GetPathFilterResult() = %+v for material without path filters", result)
+	}
+}
+
+func TestGetChangedFilesSince(t *testing.T) {
+	commits := []pipelineConfig.GitCommit{
+		{Commit: "d", Changes: []string{"docs/readme.md"}},
+		{Commit: "c", Changes: []string{"services/api/main.go", "docs/readme.md"}},
+		{Commit: "b", Changes: []string{"libs/log/log.go"}},
+		{Commit: "a", Changes: []string{"services/web/main.go"}},
+	}
+	files, ok := GetChangedFilesSince(commits, "d", "b")
+	if !ok || !reflect.DeepEqual(files, []string{"docs/readme.md", "services/api/main.go"}) {
+		t.Errorf("GetChangedFilesSince() = %v, %v", files, ok)
+	}
+	files, ok = GetChangedFilesSince(commits, "c", "a")
+	if !ok || !reflect.DeepEqual(files, []string{"services/api/main.go", "docs/readme.md", "libs/log/log.go"}) {
+		t.Errorf("GetChangedFilesSince() = %v, %v", files, ok)
+	}
+	if _, ok = GetChangedFilesSince(commits, "d", "z"); ok {
+		t.Errorf("GetChangedFilesSince() known for last built commit not listed")
+	}
+	if _, ok = GetChangedFilesSince(commits, "z", "a"); ok {
+		t.Errorf("GetChangedFilesSince() known for head commit not listed")
+	}
+	commits[1].Changes = nil
+	if _, ok = GetChangedFilesSince(commits, "d", "a"); ok {
+		t.Errorf("GetChangedFilesSince() known with a commit of unknown changes in between")
+	}
+}
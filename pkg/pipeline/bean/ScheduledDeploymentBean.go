@@ -0,0 +1,78 @@
+package bean
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+)
+
+type ScheduledDeploymentRequest struct {
+	AppId                  int        `json:"appId" validate:"required"`
+	PipelineId             int        `json:"pipelineId" validate:"required"`
+	CiArtifactId           int        `json:"ciArtifactId" validate:"required"`
+	ScheduledOn            *time.Time `json:"scheduledOn"`
+	InNextDeploymentWindow bool       `json:"inNextDeploymentWindow"`
+	UserId                 int32      `json:"-"`
+}
+
+type ScheduledDeploymentDto struct {
+	Id                     int        `json:"id"`
+	PipelineId             int        `json:"pipelineId"`
+	CiArtifactId           int        `json:"ciArtifactId"`
+	ScheduledOn            time.Time  `json:"scheduledOn"`
+	InNextDeploymentWindow bool       `json:"inNextDeploymentWindow"`
+	Status                 string     `json:"status"`
+	CdWorkflowRunnerId     int        `json:"cdWorkflowRunnerId,omitempty"`
+	Message                string     `json:"message,omitempty"`
+	ExecutedOn             *time.Time `json:"executedOn,omitempty"`
+	ScheduledBy            string     `json:"scheduledBy"`
+}
+
+// ValidateScheduleRequest validates the time of the schedule, a time found from the next deployment window may be now
+func ValidateScheduleRequest(request *ScheduledDeploymentRequest, now time.Time) error {
+	var validationErr string
+	if request.ScheduledOn == nil {
+		validationErr = "either scheduledOn or inNextDeploymentWindow is required"
+	} else if !request.InNextDeploymentWindow && !request.ScheduledOn.After(now) {
+		validationErr = "scheduledOn must be in the future"
+	}
+	if len(validationErr) > 0 {
+		return &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			InternalMessage: validationErr,
+			UserMessage:     validationErr,
+		}
+	}
+	return nil
+}
+
+func AdaptScheduledDeployment(scheduledDeployment *pipelineConfig.ScheduledDeployment, scheduledBy string) *ScheduledDeploymentDto {
+	dto := &ScheduledDeploymentDto{
+		Id:                     scheduledDeployment.Id,
+		PipelineId:             scheduledDeployment.PipelineId,
+		CiArtifactId:           scheduledDeployment.CiArtifactId,
+		ScheduledOn:            scheduledDeployment.ScheduledOn,
+		InNextDeploymentWindow: scheduledDeployment.InNextDeploymentWindow,
+		Status:                 string(scheduledDeployment.Status),
+		CdWorkflowRunnerId:     scheduledDeployment.CdWorkflowRunnerId,
+		Message:                scheduledDeployment.Message,
+		ScheduledBy:            scheduledBy,
+	}
+	if !scheduledDeployment.ExecutedOn.IsZero() {
+		executedOn := scheduledDeployment.ExecutedOn
+		dto.ExecutedOn = &executedOn
+	}
+	return dto
+}
+
+// IsArtifactOfCiSource reports whether the artifact was built by the ci pipeline, or received by the external ci, the
+// other id being 0
+func IsArtifactOfCiSource(artifact *repository.CiArtifact, ciPipelineId int, externalCiPipelineId int) bool {
+	if ciPipelineId > 0 {
+		return artifact.PipelineId == ciPipelineId
+	}
+	return externalCiPipelineId > 0 && artifact.ExternalCiPipelineId == externalCiPipelineId
+}
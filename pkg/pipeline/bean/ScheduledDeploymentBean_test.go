@@ -0,0 +1,74 @@
+package bean
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+)
+
+func TestValidateScheduleRequest(t *testing.T) {
+	now := time.Date(2023, time.November, 15, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		scheduledOn := now.Add(d)
+		return &scheduledOn
+	}
+	tests := []struct {
+		name    string
+		request *ScheduledDeploymentRequest
+		wantErr bool
+	}{
+		{name: "future time", request: &ScheduledDeploymentRequest{ScheduledOn: at(time.Hour)}, wantErr: false},
+		{name: "past time", request: &ScheduledDeploymentRequest{ScheduledOn: at(-time.Hour)}, wantErr: true},
+		{name: "time missing", request: &ScheduledDeploymentRequest{}, wantErr: true},
+		{name: "next deployment window already open", request: &ScheduledDeploymentRequest{ScheduledOn: at(0), InNextDeploymentWindow: true}, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateScheduleRequest(tt.request, now); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateScheduleRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdaptScheduledDeployment(t *testing.T) {
+	scheduledOn := time.Date(2023, time.November, 16, 2, 0, 0, 0, time.UTC)
+	pending := &pipelineConfig.ScheduledDeployment{Id: 1, PipelineId: 2, CiArtifactId: 3, ScheduledOn: scheduledOn, Status: pipelineConfig.SCHEDULED_DEPLOYMENT_STATUS_SCHEDULED}
+	got := AdaptScheduledDeployment(pending, "admin")
+	if got.Status != string(pipelineConfig.SCHEDULED_DEPLOYMENT_STATUS_SCHEDULED) || got.ScheduledBy != "admin" || got.ExecutedOn != nil {
+		t.Errorf("AdaptScheduledDeployment() = %+v for pending deployment", got)
+	}
+	executed := &pipelineConfig.ScheduledDeployment{Id: 1, ScheduledOn: scheduledOn, ExecutedOn: scheduledOn.Add(time.Minute), Status: pipelineConfig.SCHEDULED_DEPLOYMENT_STATUS_TRIGGERED, CdWorkflowRunnerId: 10}
+	got = AdaptScheduledDeployment(executed, "admin")
+	if got.ExecutedOn == nil || !got.ExecutedOn.Equal(executed.ExecutedOn) || got.CdWorkflowRunnerId != 10 {
+		t.Errorf("AdaptScheduledDeployment() = %+v for executed deployment", got)
+	}
+}
+
+func TestIsArtifactOfCiSource(t *testing.T) {
+	ciArtifact := &repository.CiArtifact{PipelineId: 4}
+	webhookArtifact := &repository.CiArtifact{ExternalCiPipelineId: 7}
+	tests := []struct {
+		name                 string
+		artifact             *repository.CiArtifact
+		ciPipelineId         int
+		externalCiPipelineId int
+		want                 bool
+	}{
+		{name: "built by the ci pipeline", artifact: ciArtifact, ciPipelineId: 4, want: true},
+		{name: "built by another ci pipeline", artifact: ciArtifact, ciPipelineId: 5, want: false},
+		{name: "received by the external ci", artifact: webhookArtifact, externalCiPipelineId: 7, want: true},
+		{name: "received by another external ci", artifact: webhookArtifact, externalCiPipelineId: 8, want: false},
+		{name: "external ci artifact on ci pipeline", artifact: webhookArtifact, ciPipelineId: 4, want: false},
+		{name: "no ci source", artifact: &repository.CiArtifact{}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsArtifactOfCiSource(tt.artifact, tt.ciPipelineId, tt.externalCiPipelineId); got != tt.want {
+				t.Errorf("IsArtifactOfCiSource() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
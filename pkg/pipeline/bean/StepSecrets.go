@@ -0,0 +1,35 @@
+package bean
+
+import (
+	"sort"
+
+	"github.com/devtron-labs/devtron/pkg/plugin/repository"
+	"golang.org/x/exp/slices"
+)
+
+// AddStepSecrets adds the secrets as input variables of the steps using one of the ref plugins, usually all the versions
+// of a plugin, so that they are exposed to these steps only and not to the whole ci workflow. It returns false if none of
+// the steps uses the plugin.
+func AddStepSecrets(steps []*StepObject, refPluginIds []int, secrets map[string]string) bool {
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	added := false
+	for _, step := range steps {
+		if !slices.Contains(refPluginIds, step.RefPluginId) {
+			continue
+		}
+		for _, name := range names {
+			step.InputVars = append(step.InputVars, &VariableObject{
+				Name:         name,
+				Format:       string(repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING),
+				Value:        secrets[name],
+				VariableType: VARIABLE_TYPE_VALUE,
+			})
+		}
+		added = true
+	}
+	return added
+}
@@ -0,0 +1,25 @@
+package bean
+
+import "testing"
+
+func TestAddStepSecrets(t *testing.T) {
+	secrets := map[string]string{"COSIGN_PRIVATE_KEY": "key", "COSIGN_PASSWORD": "password"}
+	build := &StepObject{Name: "build", Index: 1}
+	signer := &StepObject{Name: "sign", Index: 2, RefPluginId: 5, InputVars: []*VariableObject{{Name: "IMAGE"}}}
+	other := &StepObject{Name: "copy", Index: 3, RefPluginId: 8}
+	if !AddStepSecrets([]*StepObject{build, signer, other}, []int{3, 5}, secrets) {
+		t.Fatalf("AddStepSecrets() = false, want true")
+	}
+	if len(build.InputVars) != 0 || len(other.InputVars) != 0 {
+		t.Errorf("secrets added to steps not using the plugin")
+	}
+	if len(signer.InputVars) != 3 || signer.InputVars[1].Name != "COSIGN_PASSWORD" || signer.InputVars[2].Name != "COSIGN_PRIVATE_KEY" || signer.InputVars[2].Value != "key" {
+		t.Errorf("unexpected input vars of signer step %v", signer.InputVars)
+	}
+	if AddStepSecrets([]*StepObject{build, other}, []int{3, 5}, secrets) {
+		t.Errorf("AddStepSecrets() = true without a step of the plugin")
+	}
+	if AddStepSecrets([]*StepObject{signer}, nil, secrets) {
+		t.Errorf("AddStepSecrets() = true for unknown plugin")
+	}
+}
@@ -31,6 +31,19 @@ type WorkflowTemplate struct {
 	RefPlugins             []*RefPluginObject
 	TerminationGracePeriod int
 	WorkflowType           string
+	// BuildMatrixContainers build the image of every build matrix platform in parallel in place of the main container
+	BuildMatrixContainers []*BuildMatrixContainer
+	// BuildMatrixPreCiContainer and BuildMatrixPostCiContainer run the steps of the pre and post ci stages once, before
+	// and after the builds of the platforms, they are nil for stages without steps
+	BuildMatrixPreCiContainer  *BuildMatrixContainer
+	BuildMatrixPostCiContainer *BuildMatrixContainer
+}
+
+type BuildMatrixContainer struct {
+	Name            string
+	Container       v1.Container
+	NodeSelector    map[string]string
+	CloudStorageKey string
 }
 
 const (
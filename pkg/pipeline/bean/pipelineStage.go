@@ -25,6 +25,10 @@ type PipelineStageStepDto struct {
 	InlineStepDetail         *InlineStepDetailDto        `json:"inlineStepDetail"`
 	RefPluginStepDetail      *RefPluginStepDetailDto     `json:"pluginRefStepDetail"`
 	TriggerIfParentStageFail bool                        `json:"triggerIfParentStageFail"`
+	// DependsOn are the indexes of the steps of the stage this step runs after. Steps are sent to the ci runner in
+	// groups of steps not depending on each other, the runner runs the steps of a group concurrently and the groups one
+	// after the other. Steps run in index order if no step of the stage declares dependencies.
+	DependsOn []int `json:"dependsOn,omitempty"`
 }
 
 type InlineStepDetailDto struct {
@@ -52,6 +56,8 @@ type RefPluginStepDetailDto struct {
 	InputVariables   []*StepVariableDto    `json:"inputVariables"`
 	OutputVariables  []*StepVariableDto    `json:"outputVariables"`
 	ConditionDetails []*ConditionDetailDto `json:"conditionDetails"`
+	// PluginVersion is the semver constraint on the version of the plugin, the step runs the newest version matching it
+	PluginVersion string `json:"pluginVersion,omitempty"`
 }
 
 type StepVariableDto struct {
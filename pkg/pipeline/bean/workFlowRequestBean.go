@@ -14,6 +14,7 @@ const (
 	VARIABLE_TYPE_REF_GLOBAL  = "REF_GLOBAL"
 	VARIABLE_TYPE_REF_PLUGIN  = "REF_PLUGIN"
 	IMAGE_SCANNER_ENDPOINT    = "IMAGE_SCANNER_ENDPOINT"
+	CI_CD_EVENT               = "CI_CD_EVENT"
 )
 
 const CI_JOB string = "CI_JOB"
@@ -58,6 +59,8 @@ type StepObject struct {
 	ExtraVolumeMounts        []*MountPath       `json:"extraVolumeMounts"` // filePathMapping
 	ArtifactPaths            []string           `json:"artifactPaths"`
 	TriggerIfParentStageFail bool               `json:"triggerIfParentStageFail"`
+	DependsOn                []int              `json:"dependsOn,omitempty"` //indexes of the steps to run before this step
+	ParallelGroup            int                `json:"parallelGroup"`       //steps of the same group run concurrently, groups run one after the other
 }
 
 type VariableObject struct {
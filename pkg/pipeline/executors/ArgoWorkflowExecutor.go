@@ -95,6 +95,11 @@ func (impl *ArgoWorkflowExecutorImpl) ExecuteWorkflow(workflowTemplate bean.Work
 		},
 	}
 	impl.updateBlobStorageConfig(workflowTemplate, &ciCdTemplate)
+	if len(workflowTemplate.BuildMatrixContainers) > 0 {
+		var buildMatrixTemplates []v1alpha1.Template
+		ciCdTemplate, buildMatrixTemplates = impl.getBuildMatrixTemplates(workflowTemplate)
+		templates = append(templates, buildMatrixTemplates...)
+	}
 	templates = append(templates, ciCdTemplate)
 
 	objectMeta := workflowTemplate.CreateObjectMetadata()
@@ -138,6 +143,55 @@ func (impl *ArgoWorkflowExecutorImpl) ExecuteWorkflow(workflowTemplate bean.Work
 	return impl.convertToUnstructured(createdWf), nil
 }
 
+// getBuildMatrixTemplates returns the steps template running the pre ci stage, then the build of every build matrix
+// platform in parallel and then the post ci stage, it takes the place of the template of the main container, along
+// with the templates of the stages and the platforms
+func (impl *ArgoWorkflowExecutorImpl) getBuildMatrixTemplates(workflowTemplate bean.WorkflowTemplate) (v1alpha1.Template, []v1alpha1.Template) {
+	var steps []v1alpha1.ParallelSteps
+	var templates []v1alpha1.Template
+	if workflowTemplate.BuildMatrixPreCiContainer != nil {
+		preCiTemplate := impl.getBuildMatrixContainerTemplate(workflowTemplate, workflowTemplate.BuildMatrixPreCiContainer)
+		templates = append(templates, preCiTemplate)
+		steps = append(steps, v1alpha1.ParallelSteps{Steps: []v1alpha1.WorkflowStep{{Name: preCiTemplate.Name, Template: preCiTemplate.Name}}})
+	}
+	var platformSteps []v1alpha1.WorkflowStep
+	for _, buildMatrixContainer := range workflowTemplate.BuildMatrixContainers {
+		platformTemplate := impl.getBuildMatrixContainerTemplate(workflowTemplate, buildMatrixContainer)
+		templates = append(templates, platformTemplate)
+		platformSteps = append(platformSteps, v1alpha1.WorkflowStep{
+			Name:     platformTemplate.Name,
+			Template: platformTemplate.Name,
+		})
+	}
+	steps = append(steps, v1alpha1.ParallelSteps{Steps: platformSteps})
+	if workflowTemplate.BuildMatrixPostCiContainer != nil {
+		postCiTemplate := impl.getBuildMatrixContainerTemplate(workflowTemplate, workflowTemplate.BuildMatrixPostCiContainer)
+		templates = append(templates, postCiTemplate)
+		steps = append(steps, v1alpha1.ParallelSteps{Steps: []v1alpha1.WorkflowStep{{Name: postCiTemplate.Name, Template: postCiTemplate.Name}}})
+	}
+	ciCdTemplate := v1alpha1.Template{
+		Name:  workflowTemplate.WorkflowType,
+		Steps: steps,
+	}
+	return ciCdTemplate, templates
+}
+
+func (impl *ArgoWorkflowExecutorImpl) getBuildMatrixContainerTemplate(workflowTemplate bean.WorkflowTemplate, buildMatrixContainer *bean.BuildMatrixContainer) v1alpha1.Template {
+	container := buildMatrixContainer.Container
+	template := v1alpha1.Template{
+		Name:         buildMatrixContainer.Name,
+		Container:    &container,
+		NodeSelector: buildMatrixContainer.NodeSelector,
+		ActiveDeadlineSeconds: &intstr.IntOrString{
+			IntVal: int32(*workflowTemplate.ActiveDeadlineSeconds),
+		},
+	}
+	containerWorkflowTemplate := workflowTemplate
+	containerWorkflowTemplate.CloudStorageKey = buildMatrixContainer.CloudStorageKey
+	impl.updateBlobStorageConfig(containerWorkflowTemplate, &template)
+	return template
+}
+
 func (impl *ArgoWorkflowExecutorImpl) GetWorkflow(workflowName string, namespace string, clusterConfig *rest.Config) (*unstructured.UnstructuredList, error) {
 
 	wf, err := impl.getWorkflow(workflowName, namespace, clusterConfig)
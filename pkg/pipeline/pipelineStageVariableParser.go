@@ -18,6 +18,7 @@ type RefPluginName = string
 
 const (
 	COPY_CONTAINER_IMAGE RefPluginName = "Copy container image"
+	COSIGN_SIGNER        RefPluginName = "Cosign"
 	EMPTY_STRING                       = " "
 )
 
@@ -63,9 +63,25 @@ type PipelineStageStep struct {
 	DependentOnStep          string           `sql:"dependent_on_step"`
 	Deleted                  bool             `sql:"deleted,notnull"`
 	TriggerIfParentStageFail bool             `sql:"trigger_if_parent_stage_fail"`
+	// RefPluginVersionConstraint is the semver constraint of the plugin version to run, RefPluginId is the version
+	// resolved when the step was saved
+	RefPluginVersionConstraint string `sql:"ref_plugin_version_constraint"`
+	// DependsOnStepIndexes are the indexes of the steps of the stage this step runs after
+	DependsOnStepIndexes []int `sql:"depends_on_step_indexes" pg:",array"`
 	sql.AuditLog
 }
 
+// PluginStepUsage is an active step of a pipeline stage using a plugin
+type PluginStepUsage struct {
+	StepId                     int               `sql:"step_id"`
+	StepName                   string            `sql:"step_name"`
+	RefPluginId                int               `sql:"ref_plugin_id"`
+	RefPluginVersionConstraint string            `sql:"ref_plugin_version_constraint"`
+	StageType                  PipelineStageType `sql:"stage_type"`
+	CiPipelineId               int               `sql:"ci_pipeline_id"`
+	CdPipelineId               int               `sql:"cd_pipeline_id"`
+}
+
 // Below two tables are used at plugin-steps level too
 
 type PluginPipelineScript struct {
@@ -151,14 +167,18 @@ type PipelineStageRepository interface {
 	GetStepIdsByStageId(stageId int) ([]int, error)
 	CreatePipelineStageStep(step *PipelineStageStep, tx *pg.Tx) (*PipelineStageStep, error)
 	UpdatePipelineStageStep(step *PipelineStageStep) (*PipelineStageStep, error)
+	UpdatePipelineStageStepWithTx(step *PipelineStageStep, tx *pg.Tx) (*PipelineStageStep, error)
 	MarkPipelineStageStepsDeletedByStageId(stageId int, updatedBy int32, tx *pg.Tx) error
 	GetAllStepsByStageId(stageId int) ([]*PipelineStageStep, error)
-	GetAllCiPipelineIdsByPluginIdAndStageType(pluginId int, stageType string) ([]int, error)
-	CheckPluginExistsInCiPipeline(pipelineId int, stageType string, pluginId int) (bool, error)
+	// GetAllCiPipelineIdsByPluginIdsAndStageType returns the ci pipelines having a step of the stage using one of the
+	// plugins, pluginIds are usually all the versions of a plugin
+	GetAllCiPipelineIdsByPluginIdsAndStageType(pluginIds []int, stageType string) ([]int, error)
+	CheckPluginExistsInCiPipeline(pipelineId int, stageType string, pluginIds []int) (bool, error)
 	GetStepById(stepId int) (*PipelineStageStep, error)
 	MarkStepsDeletedByStageId(stageId int) error
 	MarkStepsDeletedExcludingActiveStepsInUpdateReq(activeStepIdsPresentInReq []int, stageId int) error
 	GetActiveStepsByRefPluginId(refPluginId int) ([]*PipelineStageStep, error)
+	GetActiveStepUsagesByRefPluginIds(refPluginIds []int) ([]*PluginStepUsage, error)
 
 	CreatePipelineScript(pipelineScript *PluginPipelineScript, tx *pg.Tx) (*PluginPipelineScript, error)
 	UpdatePipelineScript(pipelineScript *PluginPipelineScript) (*PluginPipelineScript, error)
@@ -348,6 +368,15 @@ func (impl *PipelineStageRepositoryImpl) UpdatePipelineStageStep(step *PipelineS
 	return step, nil
 }
 
+func (impl *PipelineStageRepositoryImpl) UpdatePipelineStageStepWithTx(step *PipelineStageStep, tx *pg.Tx) (*PipelineStageStep, error) {
+	err := tx.Update(step)
+	if err != nil {
+		impl.logger.Errorw("error in updating pipeline stage step", "err", err, "step", step)
+		return nil, err
+	}
+	return step, nil
+}
+
 func (impl *PipelineStageRepositoryImpl) MarkPipelineStageStepsDeletedByStageId(stageId int, updatedBy int32, tx *pg.Tx) error {
 	var step PipelineStageStep
 	_, err := tx.Model(&step).Set("deleted = ?", true).Set("updated_on = ?", time.Now()).
@@ -384,27 +413,33 @@ func (impl *PipelineStageRepositoryImpl) GetStepById(stepId int) (*PipelineStage
 	return &step, nil
 }
 
-func (impl *PipelineStageRepositoryImpl) GetAllCiPipelineIdsByPluginIdAndStageType(pluginId int, stageType string) ([]int, error) {
+func (impl *PipelineStageRepositoryImpl) GetAllCiPipelineIdsByPluginIdsAndStageType(pluginIds []int, stageType string) ([]int, error) {
 	var ciPipelineIds []int
-	query := "Select ps.ci_pipeline_id from pipeline_stage ps " +
+	if len(pluginIds) == 0 {
+		return ciPipelineIds, nil
+	}
+	query := "Select DISTINCT ps.ci_pipeline_id from pipeline_stage ps " +
 		"INNER JOIN pipeline_stage_step pss ON pss.pipeline_stage_id = ps.id " +
-		"where pss.ref_plugin_id = ? and ps.type = ? and pss.deleted = false and ps.deleted = false"
-	_, err := impl.dbConnection.Query(&ciPipelineIds, query, pluginId, stageType)
+		"where pss.ref_plugin_id IN (?) and ps.type = ? and pss.deleted = false and ps.deleted = false"
+	_, err := impl.dbConnection.Query(&ciPipelineIds, query, pg.In(pluginIds), stageType)
 	if err != nil {
-		impl.logger.Errorw("err in getting ciPipelineIds by PluginId and StepType", "err", err, "pluginId", pluginId, "stageType", stageType)
+		impl.logger.Errorw("err in getting ciPipelineIds by PluginIds and StepType", "err", err, "pluginIds", pluginIds, "stageType", stageType)
 		return nil, err
 	}
 	return ciPipelineIds, nil
 }
 
-func (impl *PipelineStageRepositoryImpl) CheckPluginExistsInCiPipeline(pipelineId int, stageType string, pluginId int) (bool, error) {
+func (impl *PipelineStageRepositoryImpl) CheckPluginExistsInCiPipeline(pipelineId int, stageType string, pluginIds []int) (bool, error) {
+	if len(pluginIds) == 0 {
+		return false, nil
+	}
 	var step PipelineStageStep
-	query := `Select * from pipeline_stage_step pss  
+	query := `Select pss.* from pipeline_stage_step pss  
 		INNER JOIN pipeline_stage ps ON ps.id = pss.pipeline_stage_id  
-		where pss.ref_plugin_id = ? and ps.type = ? and pss.deleted = false and ps.deleted = false and ps.ci_pipeline_id= ?;`
-	_, err := impl.dbConnection.Query(&step, query, pluginId, stageType, pipelineId)
+		where pss.ref_plugin_id IN (?) and ps.type = ? and pss.deleted = false and ps.deleted = false and ps.ci_pipeline_id= ? LIMIT 1;`
+	_, err := impl.dbConnection.Query(&step, query, pg.In(pluginIds), stageType, pipelineId)
 	if err != nil {
-		impl.logger.Errorw("err in getting pipelineStageStep", "err", err, "pluginId", pluginId, "pipelineId", pipelineId, "stageType", stageType)
+		impl.logger.Errorw("err in getting pipelineStageStep", "err", err, "pluginIds", pluginIds, "pipelineId", pipelineId, "stageType", stageType)
 		return false, err
 	}
 	return step.Id != 0, nil
@@ -445,6 +480,24 @@ func (impl *PipelineStageRepositoryImpl) GetActiveStepsByRefPluginId(refPluginId
 	return steps, nil
 }
 
+func (impl *PipelineStageRepositoryImpl) GetActiveStepUsagesByRefPluginIds(refPluginIds []int) ([]*PluginStepUsage, error) {
+	var usages []*PluginStepUsage
+	if len(refPluginIds) == 0 {
+		return usages, nil
+	}
+	query := "SELECT pss.id AS step_id, pss.name AS step_name, pss.ref_plugin_id, pss.ref_plugin_version_constraint," +
+		" ps.type AS stage_type, ps.ci_pipeline_id, ps.cd_pipeline_id" +
+		" FROM pipeline_stage_step pss INNER JOIN pipeline_stage ps ON ps.id = pss.pipeline_stage_id" +
+		" WHERE pss.ref_plugin_id IN (?) AND pss.deleted = false AND ps.deleted = false" +
+		" ORDER BY pss.id;"
+	_, err := impl.dbConnection.Query(&usages, query, pg.In(refPluginIds))
+	if err != nil {
+		impl.logger.Errorw("err in getting step usages by refPluginIds", "err", err, "refPluginIds", refPluginIds)
+		return nil, err
+	}
+	return usages, nil
+}
+
 func (impl *PipelineStageRepositoryImpl) CreatePipelineScript(pipelineScript *PluginPipelineScript, tx *pg.Tx) (*PluginPipelineScript, error) {
 	var err error
 	if tx != nil {
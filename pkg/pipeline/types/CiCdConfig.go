@@ -123,6 +123,9 @@ type CiCdConfig struct {
 	ExtBlobStorageSecretName                   string                       `env:"EXTERNAL_BLOB_STORAGE_SECRET_NAME" envDefault:"blob-storage-secret"`
 	UseArtifactListingQueryV2                  bool                         `env:"USE_ARTIFACT_LISTING_QUERY_V2" envDefault:"true"`
 	UseImageTagFromGitProviderForTagBasedBuild bool                         `env:"USE_IMAGE_TAG_FROM_GIT_PROVIDER_FOR_TAG_BASED_BUILD" envDefault:"false"` // this is being done for https://github.com/devtron-labs/devtron/issues/4263
+	SbomGenerationEnabled                      bool                         `env:"SBOM_GENERATION_ENABLED" envDefault:"true"`
+	SbomFormat                                 string                       `env:"SBOM_FORMAT" envDefault:"spdx-json"`
+	SbomToolImage                              string                       `env:"SBOM_TOOL_IMAGE" envDefault:"anchore/syft:v0.98.0"`
 }
 
 type CiConfig struct {
@@ -508,9 +511,11 @@ type Trigger struct {
 	PipelineType              string
 	CiArtifactLastFetch       time.Time
 	ReferenceCiWorkflowId     int
+	PathFilterResult          *pipelineConfig.PathFilterResult
+	PriorityClass             string
 }
 
-func (obj Trigger) BuildTriggerObject(refCiWorkflow *pipelineConfig.CiWorkflow,
+func (obj *Trigger) BuildTriggerObject(refCiWorkflow *pipelineConfig.CiWorkflow,
 	ciMaterials []*pipelineConfig.CiPipelineMaterial, triggeredBy int32,
 	invalidateCache bool, extraEnvironmentVariables map[string]string,
 	pipelineType string) {
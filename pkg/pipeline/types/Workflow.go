@@ -126,11 +126,19 @@ type WorkflowRequest struct {
 	RegistryCredentialMap       map[string]plugin.RegistryCredentials `json:"registryCredentialMap"`
 	PluginArtifactStage         string                                `json:"pluginArtifactStage"`
 	PushImageBeforePostCI       bool                                  `json:"pushImageBeforePostCI"`
+	SbomFilePath                string                                `json:"sbomFilePath,omitempty"` // the runner sends this file, written by the sbom step, in the ci complete event
 	Type                        bean.WorkflowPipelineType
 	Pipeline                    *pipelineConfig.Pipeline
 	Env                         *repository.Environment
 	AppLabels                   map[string]string
 	Scope                       resourceQualifiers.Scope
+	// IsDryRun is set for the sandbox runs testing plugins, the runner is to push no artifact, send no event and post
+	// the results of the steps to DryRunResultUrl with DryRunResultToken as bearer token. Dry runs are submitted with
+	// a workflow id matching no ci_workflow, without the orchestrator token and without config maps, secrets or the
+	// service account of ci workflows.
+	IsDryRun          bool   `json:"isDryRun,omitempty"`
+	DryRunResultUrl   string `json:"dryRunResultUrl,omitempty"`
+	DryRunResultToken string `json:"dryRunResultToken,omitempty"`
 }
 
 func (workflowRequest *WorkflowRequest) updateExternalRunMetadata() {
@@ -205,6 +213,55 @@ func (workflowRequest *WorkflowRequest) GetWorkflowJson(config *CiCdConfig) ([]b
 	return workflowJson, err
 }
 
+// GetBuildMatrixWorkflowJson returns the workflow json building the image of one build matrix platform, it is to be
+// called after GetWorkflowJson so that the logs key of the platform is derived from the workflow's. The pre and post ci
+// steps are left out as they run once for all the platforms, see GetBuildMatrixStageWorkflowJson
+func (workflowRequest *WorkflowRequest) GetBuildMatrixWorkflowJson(platform *bean.BuildMatrixPlatform) ([]byte, error) {
+	platformRequest := *workflowRequest
+	platformSuffix := bean.GetBuildMatrixPlatformSuffix(platform.Platform)
+	platformRequest.CiBuildConfig = workflowRequest.CiBuildConfig.ForBuildMatrixPlatform(platform)
+	platformRequest.PreCiSteps = nil
+	platformRequest.PostCiSteps = nil
+	platformRequest.BeforeDockerBuildScripts = nil
+	platformRequest.AfterDockerBuildScripts = nil
+	platformRequest.DockerImageTag = bean.GetBuildMatrixImageTag(workflowRequest.DockerImageTag, platform.Platform)
+	platformRequest.BlobStorageLogsKey = fmt.Sprintf("%s/%s", workflowRequest.BlobStorageLogsKey, platformSuffix)
+	if len(workflowRequest.CiCacheFileName) > 0 {
+		// every platform has a build cache of its own
+		platformRequest.CiCacheFileName = fmt.Sprintf("%s-%s", platformSuffix, workflowRequest.CiCacheFileName)
+	}
+	return platformRequest.getWorkflowJson()
+}
+
+// HasPreCiStage tells if the workflow runs any step before the build
+func (workflowRequest *WorkflowRequest) HasPreCiStage() bool {
+	return len(workflowRequest.PreCiSteps) > 0 || len(workflowRequest.BeforeDockerBuildScripts) > 0
+}
+
+// HasPostCiStage tells if the workflow runs any step after the build
+func (workflowRequest *WorkflowRequest) HasPostCiStage() bool {
+	return len(workflowRequest.PostCiSteps) > 0 || len(workflowRequest.AfterDockerBuildScripts) > 0
+}
+
+// GetBuildMatrixStageWorkflowJson returns the workflow json running only the steps of the given stage of a build matrix,
+// bean.BUILD_MATRIX_STAGE_PRE_CI or bean.BUILD_MATRIX_STAGE_POST_CI, with the build skipped. The post ci stage runs
+// with the tag of the manifest list of the platforms
+func (workflowRequest *WorkflowRequest) GetBuildMatrixStageWorkflowJson(stage string) ([]byte, error) {
+	stageRequest := *workflowRequest
+	stageRequest.CiBuildConfig = workflowRequest.CiBuildConfig.ForBuildMatrixStage()
+	if stage == bean.BUILD_MATRIX_STAGE_PRE_CI {
+		stageRequest.PostCiSteps = nil
+		stageRequest.AfterDockerBuildScripts = nil
+	} else {
+		stageRequest.PreCiSteps = nil
+		stageRequest.BeforeDockerBuildScripts = nil
+	}
+	stageRequest.BlobStorageLogsKey = fmt.Sprintf("%s/%s", workflowRequest.BlobStorageLogsKey, stage)
+	stageRequest.IgnoreDockerCachePush = true
+	stageRequest.IgnoreDockerCachePull = true
+	return stageRequest.getWorkflowJson()
+}
+
 func (workflowRequest *WorkflowRequest) GetEventTypeForWorkflowRequest() string {
 	switch workflowRequest.Type {
 	case bean.CI_WORKFLOW_PIPELINE_TYPE, bean.JOB_WORKFLOW_PIPELINE_TYPE:
@@ -229,7 +286,7 @@ func (workflowRequest *WorkflowRequest) GetWorkflowTypeForWorkflowRequest() stri
 
 func (workflowRequest *WorkflowRequest) getContainerEnvVariables(config *CiCdConfig, workflowJson []byte) (containerEnvVariables []v1.EnvVar) {
 	containerEnvVariables = []v1.EnvVar{{Name: bean.IMAGE_SCANNER_ENDPOINT, Value: config.ImageScannerEndpoint}, {Name: "NATS_SERVER_HOST", Value: config.NatsServerHost}}
-	eventEnv := v1.EnvVar{Name: "CI_CD_EVENT", Value: string(workflowJson)}
+	eventEnv := v1.EnvVar{Name: bean.CI_CD_EVENT, Value: string(workflowJson)}
 	inAppLoggingEnv := v1.EnvVar{Name: "IN_APP_LOGGING", Value: strconv.FormatBool(workflowRequest.InAppLoggingEnabled)}
 	containerEnvVariables = append(containerEnvVariables, eventEnv, inAppLoggingEnv)
 	return containerEnvVariables
@@ -301,6 +358,10 @@ func (workflowRequest *WorkflowRequest) AddNodeConstraintsFromConfig(workflowTem
 		workflowTemplate.NodeSelector = map[string]string{nodeConstraints.TaintKey: nodeConstraints.TaintValue}
 	}
 	workflowTemplate.ServiceAccountName = nodeConstraints.ServiceAccount
+	if workflowRequest.IsDryRun {
+		// dry runs use the default service account of their namespace
+		workflowTemplate.ServiceAccountName = ""
+	}
 	if nodeConstraints.TaintKey != "" || nodeConstraints.TaintValue != "" {
 		workflowTemplate.Tolerations = []v1.Toleration{{Key: nodeConstraints.TaintKey, Value: nodeConstraints.TaintValue, Operator: v1.TolerationOpEqual, Effect: v1.TaintEffectNoSchedule}}
 	}
@@ -314,6 +375,10 @@ func (workflowRequest *WorkflowRequest) AddNodeConstraintsFromConfig(workflowTem
 }
 
 func (workflowRequest *WorkflowRequest) GetGlobalCmCsNamePrefix() string {
+	if workflowRequest.IsDryRun {
+		// dry runs have no ci workflow, their config maps and secrets are named after the run
+		return workflowRequest.WorkflowNamePrefix
+	}
 	switch workflowRequest.Type {
 	case bean.CI_WORKFLOW_PIPELINE_TYPE, bean.JOB_WORKFLOW_PIPELINE_TYPE:
 		return strconv.Itoa(workflowRequest.WorkflowId) + "-" + bean.CI_WORKFLOW_NAME
@@ -361,6 +426,9 @@ func (workflowRequest *WorkflowRequest) GetConfiguredCmCs() (map[string]bool, ma
 }
 
 func (workflowRequest *WorkflowRequest) GetExistingCmCsNamePrefix() string {
+	if workflowRequest.IsDryRun {
+		return workflowRequest.WorkflowNamePrefix
+	}
 	switch workflowRequest.Type {
 	case bean.CI_WORKFLOW_PIPELINE_TYPE:
 		return strconv.Itoa(workflowRequest.WorkflowId) + "-" + bean.CI_WORKFLOW_NAME
@@ -592,35 +660,41 @@ type ChildCdMetadata struct {
 }
 
 type WorkflowResponse struct {
-	Id                   int                                         `json:"id"`
-	Name                 string                                      `json:"name"`
-	Status               string                                      `json:"status"`
-	PodStatus            string                                      `json:"podStatus"`
-	Message              string                                      `json:"message"`
-	StartedOn            time.Time                                   `json:"startedOn"`
-	FinishedOn           time.Time                                   `json:"finishedOn"`
-	CiPipelineId         int                                         `json:"ciPipelineId"`
-	Namespace            string                                      `json:"namespace"`
-	LogLocation          string                                      `json:"logLocation"`
-	BlobStorageEnabled   bool                                        `json:"blobStorageEnabled"`
-	GitTriggers          map[int]pipelineConfig.GitCommit            `json:"gitTriggers"`
-	CiMaterials          []pipelineConfig.CiPipelineMaterialResponse `json:"ciMaterials"`
-	TriggeredBy          int32                                       `json:"triggeredBy"`
-	Artifact             string                                      `json:"artifact"`
-	TriggeredByEmail     string                                      `json:"triggeredByEmail"`
-	Stage                string                                      `json:"stage"`
-	ArtifactId           int                                         `json:"artifactId"`
-	IsArtifactUploaded   bool                                        `json:"isArtifactUploaded"`
-	IsVirtualEnvironment bool                                        `json:"isVirtualEnvironment"`
-	PodName              string                                      `json:"podName"`
-	EnvironmentId        int                                         `json:"environmentId"`
-	EnvironmentName      string                                      `json:"environmentName"`
-	ImageReleaseTags     []*repository3.ImageTag                     `json:"imageReleaseTags"`
-	ImageComment         *repository3.ImageComment                   `json:"imageComment"`
-	AppWorkflowId        int                                         `json:"appWorkflowId"`
-	CustomTag            *bean3.CustomTagErrorResponse               `json:"customTag,omitempty"`
-	PipelineType         string                                      `json:"pipelineType"`
-	ReferenceWorkflowId  int                                         `json:"referenceWorkflowId"`
+	Id                     int                                         `json:"id"`
+	Name                   string                                      `json:"name"`
+	Status                 string                                      `json:"status"`
+	PodStatus              string                                      `json:"podStatus"`
+	Message                string                                      `json:"message"`
+	StartedOn              time.Time                                   `json:"startedOn"`
+	FinishedOn             time.Time                                   `json:"finishedOn"`
+	CiPipelineId           int                                         `json:"ciPipelineId"`
+	Namespace              string                                      `json:"namespace"`
+	LogLocation            string                                      `json:"logLocation"`
+	BlobStorageEnabled     bool                                        `json:"blobStorageEnabled"`
+	GitTriggers            map[int]pipelineConfig.GitCommit            `json:"gitTriggers"`
+	CiMaterials            []pipelineConfig.CiPipelineMaterialResponse `json:"ciMaterials"`
+	TriggeredBy            int32                                       `json:"triggeredBy"`
+	Artifact               string                                      `json:"artifact"`
+	TriggeredByEmail       string                                      `json:"triggeredByEmail"`
+	Stage                  string                                      `json:"stage"`
+	ArtifactId             int                                         `json:"artifactId"`
+	IsArtifactUploaded     bool                                        `json:"isArtifactUploaded"`
+	IsVirtualEnvironment   bool                                        `json:"isVirtualEnvironment"`
+	PodName                string                                      `json:"podName"`
+	EnvironmentId          int                                         `json:"environmentId"`
+	EnvironmentName        string                                      `json:"environmentName"`
+	ImageReleaseTags       []*repository3.ImageTag                     `json:"imageReleaseTags"`
+	ImageComment           *repository3.ImageComment                   `json:"imageComment"`
+	AppWorkflowId          int                                         `json:"appWorkflowId"`
+	CustomTag              *bean3.CustomTagErrorResponse               `json:"customTag,omitempty"`
+	PipelineType           string                                      `json:"pipelineType"`
+	ReferenceWorkflowId    int                                         `json:"referenceWorkflowId"`
+	DeploymentApprovalData *bean.DeploymentApprovalData                `json:"deploymentApprovalData,omitempty"`
+	TriggerType            string                                      `json:"triggerType,omitempty"`
+	BuildMatrix            *bean.BuildMatrixStatus                     `json:"buildMatrix,omitempty"`
+	PathFilterResult       *pipelineConfig.PathFilterResult            `json:"pathFilterResult,omitempty"`
+	FailureReason          string                                      `json:"failureReason,omitempty"`
+	RetryAttempts          []*bean.CiRetryAttempt                      `json:"retryAttempts,omitempty"`
 }
 
 type ConfigMapSecretDto struct {
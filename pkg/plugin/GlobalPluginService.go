@@ -3,6 +3,7 @@ package plugin
 import (
 	"errors"
 	"fmt"
+	"github.com/Masterminds/semver/v3"
 	repository2 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
 	"github.com/devtron-labs/devtron/pkg/plugin/repository"
 	"github.com/devtron-labs/devtron/pkg/sql"
@@ -40,10 +41,22 @@ type GlobalPluginService interface {
 	GetAllGlobalVariables() ([]*GlobalVariable, error)
 	ListAllPlugins(stageTypeReq string) ([]*PluginListComponentDto, error)
 	GetPluginDetailById(pluginId int) (*PluginDetailDto, error)
-	GetRefPluginIdByRefPluginName(pluginName string) (refPluginId int, err error)
+	// GetRefPluginIdsByRefPluginName returns the ids of all the versions of the plugin, steps may use any of them
+	GetRefPluginIdsByRefPluginName(pluginName string) (refPluginIds []int, err error)
 	PatchPlugin(pluginDto *PluginMetadataDto, userId int32) (*PluginMetadataDto, error)
 	GetDetailedPluginInfoByPluginId(pluginId int) (*PluginMetadataDto, error)
 	GetAllDetailedPluginInfo() ([]*PluginMetadataDto, error)
+
+	// CreatePluginWithTx creates the plugin in the transaction of the caller, the request is expected to be validated
+	CreatePluginWithTx(pluginDto *PluginMetadataDto, userId int32, tx *pg.Tx) (*PluginMetadataDto, error)
+	PublishPluginVersion(pluginDto *PluginMetadataDto, userId int32) (*PluginMetadataDto, error)
+	// PublishPluginVersionWithTx creates the new version of the plugin in the transaction of the caller
+	PublishPluginVersionWithTx(pluginDto *PluginMetadataDto, userId int32, tx *pg.Tx) (*PluginMetadataDto, error)
+	ValidatePluginVersion(pluginDto *PluginMetadataDto) error
+	GetPluginVersions(pluginId int) ([]*PluginVersionDto, error)
+	DeprecatePluginVersion(request *PluginDeprecationRequest) error
+	GetOutdatedPluginUsages(pluginId int) (*OutdatedPluginUsageDto, error)
+	UpgradePluginUsages(request *PluginUpgradeRequest) (*PluginUpgradeResponse, error)
 }
 
 func NewGlobalPluginService(logger *zap.SugaredLogger, globalPluginRepository repository.GlobalPluginRepository,
@@ -211,11 +224,16 @@ func (impl *GlobalPluginServiceImpl) ListAllPlugins(stageTypeReq string) ([]*Plu
 	}
 	for _, pluginMetadata := range pluginsMetadata {
 		pluginMetadataDto := &PluginMetadataDto{
-			Id:          pluginMetadata.Id,
-			Name:        pluginMetadata.Name,
-			Type:        string(pluginMetadata.Type),
-			Description: pluginMetadata.Description,
-			Icon:        pluginMetadata.Icon,
+			Id:                 pluginMetadata.Id,
+			Name:               pluginMetadata.Name,
+			Type:               string(pluginMetadata.Type),
+			Description:        pluginMetadata.Description,
+			Icon:               pluginMetadata.Icon,
+			PluginParentId:     pluginMetadata.GetParentId(),
+			PluginVersion:      pluginMetadata.PluginVersion,
+			IsLatest:           pluginMetadata.IsLatest,
+			Deprecated:         pluginMetadata.Deprecated,
+			DeprecationMessage: pluginMetadata.DeprecationMessage,
 		}
 		tags, ok := pluginIdTagsMap[pluginMetadata.Id]
 		if ok {
@@ -241,11 +259,16 @@ func (impl *GlobalPluginServiceImpl) GetPluginDetailById(pluginId int) (*PluginD
 		return nil, err
 	}
 	metadataDto := &PluginMetadataDto{
-		Id:          pluginMetadata.Id,
-		Name:        pluginMetadata.Name,
-		Type:        string(pluginMetadata.Type),
-		Description: pluginMetadata.Description,
-		Icon:        pluginMetadata.Icon,
+		Id:                 pluginMetadata.Id,
+		Name:               pluginMetadata.Name,
+		Type:               string(pluginMetadata.Type),
+		Description:        pluginMetadata.Description,
+		Icon:               pluginMetadata.Icon,
+		PluginParentId:     pluginMetadata.GetParentId(),
+		PluginVersion:      pluginMetadata.PluginVersion,
+		IsLatest:           pluginMetadata.IsLatest,
+		Deprecated:         pluginMetadata.Deprecated,
+		DeprecationMessage: pluginMetadata.DeprecationMessage,
 	}
 	pluginDetail := &PluginDetailDto{
 		Metadata: metadataDto,
@@ -335,19 +358,17 @@ func getVariableDto(pluginVariable *repository.PluginStepVariable) *PluginVariab
 		PreviousStepIndex:     pluginVariable.PreviousStepIndex,
 		VariableStepIndex:     pluginVariable.VariableStepIndex,
 		ReferenceVariableName: pluginVariable.ReferenceVariableName,
+		ValueConstraint:       pluginVariable.ValueConstraint,
 	}
 }
 
-func (impl *GlobalPluginServiceImpl) GetRefPluginIdByRefPluginName(pluginName string) (refPluginId int, err error) {
-	pluginMetadata, err := impl.globalPluginRepository.GetPluginByName(pluginName)
+func (impl *GlobalPluginServiceImpl) GetRefPluginIdsByRefPluginName(pluginName string) (refPluginIds []int, err error) {
+	refPluginIds, err = impl.globalPluginRepository.GetPluginVersionIdsByName(pluginName)
 	if err != nil {
-		impl.logger.Errorw("error in fetching plugin metadata by name", "err", err)
-		return 0, err
-	}
-	if pluginMetadata == nil {
-		return 0, nil
+		impl.logger.Errorw("error in fetching plugin version ids by name", "pluginName", pluginName, "err", err)
+		return nil, err
 	}
-	return pluginMetadata[0].Id, nil
+	return refPluginIds, nil
 }
 
 func (impl *GlobalPluginServiceImpl) PatchPlugin(pluginDto *PluginMetadataDto, userId int32) (*PluginMetadataDto, error) {
@@ -386,6 +407,14 @@ func (impl *GlobalPluginServiceImpl) validatePluginRequest(pluginReq *PluginMeta
 	if len(pluginReq.Type) == 0 {
 		return errors.New("invalid plugin type, should be of the type PRESET or SHARED")
 	}
+	if len(pluginReq.PluginVersion) > 0 {
+		if _, err := semver.NewVersion(pluginReq.PluginVersion); err != nil {
+			return errors.New("invalid plugin version, versions must follow semver")
+		}
+	}
+	if err := validatePluginVariableConstraints(pluginReq.PluginSteps); err != nil {
+		return err
+	}
 
 	plugins, err := impl.globalPluginRepository.GetMetaDataForAllPlugins()
 	if err != nil {
@@ -414,17 +443,40 @@ func (impl *GlobalPluginServiceImpl) createPlugin(pluginReq *PluginMetadataDto,
 	// Rollback tx on error.
 	defer tx.Rollback()
 
+	pluginReq, err = impl.CreatePluginWithTx(pluginReq, userId, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		impl.logger.Errorw("createPlugin, error in committing db transaction", "err", err)
+		return nil, err
+	}
+	return pluginReq, nil
+}
+
+func (impl *GlobalPluginServiceImpl) CreatePluginWithTx(pluginReq *PluginMetadataDto, userId int32, tx *pg.Tx) (*PluginMetadataDto, error) {
 	//create entry in plugin_metadata
 	pluginMetadata := &repository.PluginMetadata{}
 	if pluginReq != nil {
 		pluginMetadata = pluginReq.getPluginMetadataSqlObj(userId)
 	}
-	pluginMetadata, err = impl.globalPluginRepository.SavePluginMetadata(pluginMetadata, tx)
+	pluginMetadata, err := impl.globalPluginRepository.SavePluginMetadata(pluginMetadata, tx)
 	if err != nil {
 		impl.logger.Errorw("createPlugin, error in saving plugin", "pluginDto", pluginReq, "err", err)
 		return nil, err
 	}
+	//first version of a plugin is the parent of its later versions
+	pluginMetadata.PluginParentId = pluginMetadata.Id
+	err = impl.globalPluginRepository.UpdatePluginMetadata(pluginMetadata, tx)
+	if err != nil {
+		impl.logger.Errorw("createPlugin, error in updating plugin parent id", "pluginId", pluginMetadata.Id, "err", err)
+		return nil, err
+	}
 	pluginReq.Id = pluginMetadata.Id
+	pluginReq.PluginParentId = pluginMetadata.PluginParentId
+	pluginReq.PluginVersion = pluginMetadata.PluginVersion
+	pluginReq.IsLatest = pluginMetadata.IsLatest
 	pluginStage := repository.CI_CD
 	if pluginReq.PluginStage == CI_TYPE_PLUGIN {
 		pluginStage = repository.CI
@@ -453,17 +505,11 @@ func (impl *GlobalPluginServiceImpl) createPlugin(pluginReq *PluginMetadataDto,
 		impl.logger.Errorw("createPlugin, error in CreateNewPluginTagsAndRelationsIfRequired", "err", err)
 		return nil, err
 	}
-
-	err = tx.Commit()
-	if err != nil {
-		impl.logger.Errorw("createPlugin, error in committing db transaction", "err", err)
-		return nil, err
-	}
 	return pluginReq, nil
 }
 
 func (impl *GlobalPluginServiceImpl) CreateNewPluginTagsAndRelationsIfRequired(pluginReq *PluginMetadataDto, isUpdateReq bool, userId int32, tx *pg.Tx) error {
-	allPluginTags, err := impl.globalPluginRepository.GetAllPluginTags()
+	allPluginTags, err := impl.globalPluginRepository.GetAllPluginTagsWithTx(tx)
 	if err != nil {
 		impl.logger.Errorw("error in getting all plugin tags", "err", err)
 		return err
@@ -692,6 +738,7 @@ func (impl *GlobalPluginServiceImpl) saveDeepPluginStepData(pluginMetadataId int
 				VariableStepIndex:         pluginStepVariable.VariableStepIndex,
 				VariableStepIndexInPlugin: pluginStepVariable.VariableStepIndexInPlugin,
 				ReferenceVariableName:     pluginStepVariable.ReferenceVariableName,
+				ValueConstraint:           pluginStepVariable.ValueConstraint,
 				AuditLog:                  sql.NewDefaultAuditLog(userId),
 			}
 			pluginStepVariableData, err = impl.globalPluginRepository.SavePluginStepVariables(pluginStepVariableData, tx)
@@ -726,6 +773,9 @@ func (impl *GlobalPluginServiceImpl) updatePlugin(pluginUpdateReq *PluginMetadat
 	if len(pluginUpdateReq.Type) == 0 {
 		return nil, errors.New("invalid plugin type, should be of the type PRESET or SHARED")
 	}
+	if err := validatePluginVariableConstraints(pluginUpdateReq.PluginSteps); err != nil {
+		return nil, err
+	}
 
 	dbConnection := impl.globalPluginRepository.GetConnection()
 	tx, err := dbConnection.Begin()
@@ -739,6 +789,14 @@ func (impl *GlobalPluginServiceImpl) updatePlugin(pluginUpdateReq *PluginMetadat
 		impl.logger.Errorw("updatePlugin, error in getting pluginMetadata, pluginId does not exist", "pluginId", pluginUpdateReq.Id, "err", err)
 		return nil, err
 	}
+	//versions used by pipelines are immutable, changes to them go in a new version
+	inUse, err := impl.isPluginVersionInUse(pluginMetaData)
+	if err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, badRequest(fmt.Sprintf("version %s of the plugin is used by pipelines, publish the changes as a new version", pluginMetaData.PluginVersion))
+	}
 	//update entry in plugin_ metadata
 	pluginMetaData.Name = pluginUpdateReq.Name
 	pluginMetaData.Description = pluginUpdateReq.Description
@@ -932,6 +990,7 @@ func (impl *GlobalPluginServiceImpl) updateDeepPluginStepVariableData(pluginStep
 			dbStepVariable.VariableStepIndex = stepVariableIdsToStepVariableMapping[dbStepVariable.Id].VariableStepIndex
 			dbStepVariable.VariableStepIndexInPlugin = stepVariableIdsToStepVariableMapping[dbStepVariable.Id].VariableStepIndexInPlugin
 			dbStepVariable.ReferenceVariableName = stepVariableIdsToStepVariableMapping[dbStepVariable.Id].ReferenceVariableName
+			dbStepVariable.ValueConstraint = stepVariableIdsToStepVariableMapping[dbStepVariable.Id].ValueConstraint
 			dbStepVariable.UpdatedBy = userId
 			dbStepVariable.UpdatedOn = time.Now()
 
@@ -1167,6 +1226,7 @@ func (impl *GlobalPluginServiceImpl) saveDeepPluginStepVariableData(pluginStepId
 			VariableStepIndex:         pluginStepVariable.VariableStepIndex,
 			VariableStepIndexInPlugin: pluginStepVariable.VariableStepIndexInPlugin,
 			ReferenceVariableName:     pluginStepVariable.ReferenceVariableName,
+			ValueConstraint:           pluginStepVariable.ValueConstraint,
 			AuditLog:                  sql.NewDefaultAuditLog(userId),
 		}
 		pluginStepVariableData, err := impl.globalPluginRepository.SavePluginStepVariables(pluginStepVariableData, tx)
@@ -1415,6 +1475,12 @@ func (impl *GlobalPluginServiceImpl) GetDetailedPluginInfoByPluginId(pluginId in
 		Icon:        pluginMetaData.Icon,
 		Tags:        pluginIdTagsMap[pluginMetaData.Id],
 		PluginStage: pluginStage,
+
+		PluginParentId:     pluginMetaData.GetParentId(),
+		PluginVersion:      pluginMetaData.PluginVersion,
+		IsLatest:           pluginMetaData.IsLatest,
+		Deprecated:         pluginMetaData.Deprecated,
+		DeprecationMessage: pluginMetaData.DeprecationMessage,
 	}
 
 	pluginStepsResp := make([]*PluginStepsDto, 0)
@@ -1505,6 +1571,7 @@ func (impl *GlobalPluginServiceImpl) GetDetailedPluginInfoByPluginId(pluginId in
 					VariableStepIndex:         pluginStepVariable.VariableStepIndex,
 					VariableStepIndexInPlugin: pluginStepVariable.VariableStepIndexInPlugin,
 					ReferenceVariableName:     pluginStepVariable.ReferenceVariableName,
+					ValueConstraint:           pluginStepVariable.ValueConstraint,
 					PluginStepCondition:       pluginStepConditionDto,
 				})
 			}
@@ -1547,6 +1614,13 @@ func (impl *GlobalPluginServiceImpl) deletePlugin(pluginDeleteReq *PluginMetadat
 		impl.logger.Errorw("deletePlugin, error in deleting pluginMetadata", "pluginId", pluginDeleteReq.Id, "err", err)
 		return nil, err
 	}
+	if pluginMetaData.IsLatest {
+		err = impl.markLatestPluginVersion(pluginMetaData, userId, tx)
+		if err != nil {
+			impl.logger.Errorw("deletePlugin, error in marking latest plugin version", "pluginId", pluginDeleteReq.Id, "err", err)
+			return nil, err
+		}
+	}
 	pluginSteps, err := impl.globalPluginRepository.GetPluginStepsByPluginId(pluginDeleteReq.Id)
 	if err != nil {
 		impl.logger.Errorw("deletePlugin, error in getting pluginSteps", "pluginId", pluginDeleteReq.Id, "err", err)
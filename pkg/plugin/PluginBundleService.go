@@ -0,0 +1,431 @@
+package plugin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	dockerRegistryRepository "github.com/devtron-labs/devtron/internal/sql/repository/dockerRegistry"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/dockerRegistry"
+	"github.com/devtron-labs/devtron/pkg/plugin/repository"
+	"github.com/devtron-labs/devtron/util/registry"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+)
+
+const insecureRegistryConnection = "insecure"
+
+// PluginBundleService moves plugins between devtron installations as yaml bundles, uploaded as files or stored as oci
+// artifacts in the docker registries configured in devtron
+type PluginBundleService interface {
+	// ExportPluginBundle returns the yaml bundle of the plugins, plugins used as reference by their steps are included
+	ExportPluginBundle(pluginIds []int) ([]byte, error)
+	PushPluginBundle(request *PluginBundlePushRequest) (*PluginBundlePushResponse, error)
+	// ImportPluginBundle creates the plugins of the bundle, or new versions of the ones which exist. Plugins are imported
+	// in the order of the bundle in a single transaction once none of them conflicts.
+	ImportPluginBundle(content []byte, request *PluginBundleImportRequest) (*PluginBundleImportResponse, error)
+	ImportPluginBundleFromRegistry(request *PluginBundleImportRequest) (*PluginBundleImportResponse, error)
+}
+
+type PluginBundleServiceImpl struct {
+	logger                        *zap.SugaredLogger
+	globalPluginService           GlobalPluginService
+	globalPluginRepository        repository.GlobalPluginRepository
+	dockerArtifactStoreRepository dockerRegistryRepository.DockerArtifactStoreRepository
+}
+
+func NewPluginBundleServiceImpl(logger *zap.SugaredLogger, globalPluginService GlobalPluginService,
+	globalPluginRepository repository.GlobalPluginRepository,
+	dockerArtifactStoreRepository dockerRegistryRepository.DockerArtifactStoreRepository) *PluginBundleServiceImpl {
+	return &PluginBundleServiceImpl{
+		logger:                        logger,
+		globalPluginService:           globalPluginService,
+		globalPluginRepository:        globalPluginRepository,
+		dockerArtifactStoreRepository: dockerArtifactStoreRepository,
+	}
+}
+
+func (impl *PluginBundleServiceImpl) ExportPluginBundle(pluginIds []int) ([]byte, error) {
+	bundle, err := impl.buildPluginBundle(pluginIds)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(bundle)
+}
+
+func (impl *PluginBundleServiceImpl) PushPluginBundle(request *PluginBundlePushRequest) (*PluginBundlePushResponse, error) {
+	bundle, err := impl.buildPluginBundle(request.PluginIds)
+	if err != nil {
+		return nil, err
+	}
+	content, err := yaml.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+	client, err := impl.getRegistryClient(request.Registry.DockerRegistryId)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := client.PushArtifact(request.Registry.Repository, getBundleTag(request.Registry), PLUGIN_BUNDLE_ARTIFACT_TYPE,
+		PLUGIN_BUNDLE_LAYER_MEDIA_TYPE, PLUGIN_BUNDLE_FILE_NAME, content)
+	if err != nil {
+		impl.logger.Errorw("error in pushing plugin bundle", "registry", request.Registry, "err", err)
+		return nil, err
+	}
+	response := &PluginBundlePushResponse{Digest: digest}
+	for _, plugin := range bundle.Plugins {
+		response.Plugins = append(response.Plugins, plugin.Name+"@"+plugin.PluginVersion)
+	}
+	return response, nil
+}
+
+func (impl *PluginBundleServiceImpl) ImportPluginBundleFromRegistry(request *PluginBundleImportRequest) (*PluginBundleImportResponse, error) {
+	client, err := impl.getRegistryClient(request.Registry.DockerRegistryId)
+	if err != nil {
+		return nil, err
+	}
+	content, err := client.PullArtifact(request.Registry.Repository, getBundleTag(request.Registry), PLUGIN_BUNDLE_LAYER_MEDIA_TYPE)
+	if err != nil {
+		impl.logger.Errorw("error in pulling plugin bundle", "registry", request.Registry, "err", err)
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: err.Error(), UserMessage: "error in pulling plugin bundle: " + err.Error()}
+	}
+	return impl.ImportPluginBundle(content, request)
+}
+
+func (impl *PluginBundleServiceImpl) ImportPluginBundle(content []byte, request *PluginBundleImportRequest) (*PluginBundleImportResponse, error) {
+	bundle, err := parsePluginBundle(content)
+	if err != nil {
+		return nil, err
+	}
+	results, err := impl.planPluginImport(bundle, request.SkipConflicts)
+	if err != nil {
+		return nil, err
+	}
+	response := &PluginBundleImportResponse{DryRun: request.DryRun, Plugins: results}
+	if request.DryRun {
+		return response, nil
+	}
+	var conflicts []string
+	for _, result := range results {
+		if result.Action == PLUGIN_IMPORT_ACTION_CONFLICT {
+			conflicts = append(conflicts, fmt.Sprintf("%s@%s: %s", result.Name, result.PluginVersion, result.Message))
+		}
+	}
+	if len(conflicts) > 0 {
+		message := "plugin bundle conflicts with existing plugins, " + strings.Join(conflicts, "; ")
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, InternalMessage: message, UserMessage: message}
+	}
+	dbConnection := impl.globalPluginRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	importedIds := make(map[string]int, len(bundle.Plugins))
+	for i, plugin := range bundle.Plugins {
+		result := results[i]
+		err = impl.setRefPluginIds(plugin, importedIds)
+		if err != nil {
+			return nil, err
+		}
+		var imported *PluginMetadataDto
+		switch result.Action {
+		case PLUGIN_IMPORT_ACTION_CREATE:
+			imported, err = impl.globalPluginService.CreatePluginWithTx(plugin, request.UserId, tx)
+		case PLUGIN_IMPORT_ACTION_NEW_VERSION:
+			imported, err = impl.globalPluginService.PublishPluginVersionWithTx(plugin, request.UserId, tx)
+		}
+		if err != nil {
+			impl.logger.Errorw("error in importing plugin", "name", plugin.Name, "pluginVersion", plugin.PluginVersion, "err", err)
+			return nil, err
+		}
+		if imported != nil {
+			result.PluginId = imported.Id
+		}
+		importedIds[plugin.Name] = result.PluginId
+	}
+	err = tx.Commit()
+	if err != nil {
+		impl.logger.Errorw("error in committing db transaction", "err", err)
+		return nil, err
+	}
+	response.Imported = true
+	return response, nil
+}
+
+// planPluginImport decides how each plugin of the bundle is imported, plugins are conflicts if their version exists
+// or is not a compatible new version or if a plugin they use as reference is missing
+func (impl *PluginBundleServiceImpl) planPluginImport(bundle *PluginBundle, skipConflicts bool) ([]*PluginImportResultDto, error) {
+	bundleNames := make(map[string]bool, len(bundle.Plugins))
+	results := make([]*PluginImportResultDto, 0, len(bundle.Plugins))
+	for _, plugin := range bundle.Plugins {
+		result := &PluginImportResultDto{Name: plugin.Name, PluginVersion: plugin.PluginVersion}
+		results = append(results, result)
+		missingRef, err := impl.findMissingRefPlugin(plugin, bundleNames)
+		if err != nil {
+			return nil, err
+		}
+		bundleNames[plugin.Name] = true
+		if len(missingRef) > 0 {
+			result.Action = PLUGIN_IMPORT_ACTION_CONFLICT
+			result.Message = fmt.Sprintf("plugin %s used as reference is neither in the bundle nor installed", missingRef)
+			continue
+		}
+		versions, err := impl.globalPluginRepository.GetPluginByName(plugin.Name)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting plugin by name", "name", plugin.Name, "err", err)
+			return nil, err
+		}
+		if len(versions) == 0 {
+			if err = validatePluginVariableConstraints(plugin.PluginSteps); err != nil {
+				result.Action = PLUGIN_IMPORT_ACTION_CONFLICT
+				result.Message = err.Error()
+				continue
+			}
+			result.Action = PLUGIN_IMPORT_ACTION_CREATE
+			continue
+		}
+		if existing := findPluginVersion(versions, plugin.PluginVersion); existing != nil {
+			result.PluginId = existing.Id
+			result.Message = fmt.Sprintf("version %s is already installed", plugin.PluginVersion)
+			result.Action = PLUGIN_IMPORT_ACTION_CONFLICT
+			if skipConflicts {
+				result.Action = PLUGIN_IMPORT_ACTION_SKIP
+			}
+			continue
+		}
+		plugin.Id = versions[len(versions)-1].Id
+		err = impl.globalPluginService.ValidatePluginVersion(plugin)
+		if apiErr, ok := err.(*util.ApiError); ok {
+			result.Action = PLUGIN_IMPORT_ACTION_CONFLICT
+			result.Message = apiErr.InternalMessage
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		result.Action = PLUGIN_IMPORT_ACTION_NEW_VERSION
+	}
+	return results, nil
+}
+
+// findMissingRefPlugin returns the name of a plugin used as reference by the steps of the plugin which is neither
+// earlier in the bundle nor installed
+func (impl *PluginBundleServiceImpl) findMissingRefPlugin(plugin *PluginMetadataDto, bundleNames map[string]bool) (string, error) {
+	for _, step := range plugin.PluginSteps {
+		if step.StepType != repository.PLUGIN_STEP_TYPE_REF_PLUGIN || bundleNames[step.RefPluginName] {
+			continue
+		}
+		refPlugins, err := impl.globalPluginRepository.GetPluginByName(step.RefPluginName)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting plugin by name", "name", step.RefPluginName, "err", err)
+			return "", err
+		}
+		if len(refPlugins) == 0 {
+			return step.RefPluginName, nil
+		}
+	}
+	return "", nil
+}
+
+// setRefPluginIds points the steps of the plugin to the plugins they use as reference, the imported version of
+// plugins in the bundle and the latest version of the installed ones
+func (impl *PluginBundleServiceImpl) setRefPluginIds(plugin *PluginMetadataDto, importedIds map[string]int) error {
+	for _, step := range plugin.PluginSteps {
+		if step.StepType != repository.PLUGIN_STEP_TYPE_REF_PLUGIN {
+			continue
+		}
+		if id, ok := importedIds[step.RefPluginName]; ok {
+			step.RefPluginId = id
+			continue
+		}
+		refPlugins, err := impl.globalPluginRepository.GetPluginByName(step.RefPluginName)
+		if err != nil {
+			impl.logger.Errorw("error in getting plugin by name", "name", step.RefPluginName, "err", err)
+			return err
+		}
+		if len(refPlugins) == 0 {
+			return notFound(fmt.Sprintf("plugin %s used as reference by %s not found", step.RefPluginName, plugin.Name))
+		}
+		step.RefPluginId = refPlugins[len(refPlugins)-1].Id
+		for _, refPlugin := range refPlugins {
+			if refPlugin.IsLatest {
+				step.RefPluginId = refPlugin.Id
+			}
+		}
+	}
+	return nil
+}
+
+func (impl *PluginBundleServiceImpl) buildPluginBundle(pluginIds []int) (*PluginBundle, error) {
+	bundle := &PluginBundle{ApiVersion: PLUGIN_BUNDLE_API_VERSION, Kind: PLUGIN_BUNDLE_KIND}
+	names := make(map[int]string)
+	for _, pluginId := range pluginIds {
+		err := impl.addToPluginBundle(bundle, pluginId, names, make(map[int]bool))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bundle, nil
+}
+
+// addToPluginBundle adds the plugin after the plugins its steps use as reference, names are the names of the plugins
+// already added by id
+func (impl *PluginBundleServiceImpl) addToPluginBundle(bundle *PluginBundle, pluginId int, names map[int]string, visiting map[int]bool) error {
+	if _, ok := names[pluginId]; ok {
+		return nil
+	}
+	if visiting[pluginId] {
+		return badRequest(fmt.Sprintf("plugin %d uses itself as reference", pluginId))
+	}
+	visiting[pluginId] = true
+	plugin, err := impl.globalPluginService.GetDetailedPluginInfoByPluginId(pluginId)
+	if err != nil {
+		impl.logger.Errorw("error in getting plugin detail", "pluginId", pluginId, "err", err)
+		return err
+	}
+	for _, step := range plugin.PluginSteps {
+		if step.StepType == repository.PLUGIN_STEP_TYPE_REF_PLUGIN && step.RefPluginId > 0 {
+			err = impl.addToPluginBundle(bundle, step.RefPluginId, names, visiting)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	bundle.Plugins = append(bundle.Plugins, getBundlePlugin(plugin, names))
+	names[pluginId] = plugin.Name
+	return nil
+}
+
+func (impl *PluginBundleServiceImpl) getRegistryClient(dockerRegistryId string) (*registry.Client, error) {
+	store, err := impl.dockerArtifactStoreRepository.FindOne(dockerRegistryId)
+	if err == pg.ErrNoRows {
+		return nil, notFound(fmt.Sprintf("docker registry %s not found", dockerRegistryId))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching docker registry", "dockerRegistryId", dockerRegistryId, "err", err)
+		return nil, err
+	}
+	credential := &registry.Credential{
+		RegistryURL: store.RegistryURL,
+		Username:    store.Username,
+		Password:    store.Password,
+		Insecure:    store.Connection == insecureRegistryConnection,
+		Cert:        store.Cert,
+	}
+	if store.RegistryType == dockerRegistryRepository.REGISTRYTYPE_ECR {
+		credential.Username, credential.Password, err = dockerRegistry.CreateCredentialForEcr(store.AWSRegion, store.AWSAccessKeyId, store.AWSSecretAccessKey)
+		if err != nil {
+			impl.logger.Errorw("error in creating ecr credential", "dockerRegistryId", dockerRegistryId, "err", err)
+			return nil, err
+		}
+	}
+	return registry.NewClient(credential)
+}
+
+// parsePluginBundle reads and validates a yaml bundle, plugins without a version are on the default version
+func parsePluginBundle(content []byte) (*PluginBundle, error) {
+	bundle := &PluginBundle{}
+	err := yaml.Unmarshal(content, bundle)
+	if err != nil {
+		return nil, badRequest("invalid plugin bundle: " + err.Error())
+	}
+	if bundle.ApiVersion != PLUGIN_BUNDLE_API_VERSION || bundle.Kind != PLUGIN_BUNDLE_KIND {
+		return nil, badRequest(fmt.Sprintf("invalid plugin bundle, apiVersion should be %s and kind %s", PLUGIN_BUNDLE_API_VERSION, PLUGIN_BUNDLE_KIND))
+	}
+	if len(bundle.Plugins) == 0 {
+		return nil, badRequest("plugin bundle has no plugins")
+	}
+	names := make(map[string]bool, len(bundle.Plugins))
+	for _, plugin := range bundle.Plugins {
+		if len(plugin.Name) == 0 {
+			return nil, badRequest("plugin bundle has a plugin without name")
+		}
+		if names[plugin.Name] {
+			return nil, badRequest(fmt.Sprintf("plugin %s is in the bundle more than once", plugin.Name))
+		}
+		names[plugin.Name] = true
+		if plugin.Type != string(repository.PLUGIN_TYPE_SHARED) && plugin.Type != string(repository.PLUGIN_TYPE_PRESET) {
+			return nil, badRequest(fmt.Sprintf("invalid type of plugin %s, should be SHARED or PRESET", plugin.Name))
+		}
+		if len(plugin.PluginVersion) == 0 {
+			plugin.PluginVersion = DEFAULT_PLUGIN_VERSION
+		}
+		if _, err = semver.NewVersion(plugin.PluginVersion); err != nil {
+			return nil, badRequest(fmt.Sprintf("invalid version %q of plugin %s, versions must follow semver", plugin.PluginVersion, plugin.Name))
+		}
+		for _, step := range plugin.PluginSteps {
+			if step.StepType == repository.PLUGIN_STEP_TYPE_REF_PLUGIN && len(step.RefPluginName) == 0 {
+				return nil, badRequest(fmt.Sprintf("step %s of plugin %s has no refPluginName", step.Name, plugin.Name))
+			}
+			if step.StepType == repository.PLUGIN_STEP_TYPE_INLINE && step.PluginPipelineScript == nil {
+				return nil, badRequest(fmt.Sprintf("inline step %s of plugin %s has no script", step.Name, plugin.Name))
+			}
+		}
+	}
+	return bundle, nil
+}
+
+// getBundlePlugin drops the ids of the plugin, its steps, variables, conditions and scripts and names the plugins used
+// as reference
+func getBundlePlugin(plugin *PluginMetadataDto, names map[int]string) *PluginMetadataDto {
+	plugin.Id = 0
+	plugin.Action = 0
+	plugin.PluginParentId = 0
+	plugin.IsLatest = false
+	plugin.Deprecated = false
+	plugin.DeprecationMessage = ""
+	for _, step := range plugin.PluginSteps {
+		step.Id = 0
+		if step.StepType == repository.PLUGIN_STEP_TYPE_REF_PLUGIN {
+			step.RefPluginName = names[step.RefPluginId]
+			step.PluginPipelineScript = nil
+		}
+		step.RefPluginId = 0
+		if script := step.PluginPipelineScript; script != nil {
+			mappings := make([]*ScriptPathArgPortMapping, 0, len(script.PathArgPortMapping))
+			for _, mapping := range script.PathArgPortMapping {
+				if mapping.ScriptId == script.Id {
+					mapping.Id = 0
+					mapping.ScriptId = 0
+					mappings = append(mappings, mapping)
+				}
+			}
+			script.PathArgPortMapping = mappings
+			script.Id = 0
+			script.Deleted = false
+		}
+		for _, variable := range step.PluginStepVariable {
+			variable.Id = 0
+			for _, condition := range variable.PluginStepCondition {
+				condition.Id = 0
+				condition.PluginStepId = 0
+				condition.ConditionVariableId = 0
+				condition.Deleted = false
+			}
+		}
+	}
+	return plugin
+}
+
+func findPluginVersion(versions []*repository.PluginMetadata, pluginVersion string) *repository.PluginMetadata {
+	wanted, err := semver.NewVersion(pluginVersion)
+	if err != nil {
+		return nil
+	}
+	for _, version := range versions {
+		if v, err := semver.NewVersion(version.PluginVersion); err == nil && v.Equal(wanted) {
+			return version
+		}
+	}
+	return nil
+}
+
+func getBundleTag(reference *PluginBundleRegistryReference) string {
+	if len(reference.Tag) == 0 {
+		return PLUGIN_BUNDLE_DEFAULT_TAG
+	}
+	return reference.Tag
+}
@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/pkg/plugin/repository"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGetBundlePlugin(t *testing.T) {
+	plugin := &PluginMetadataDto{
+		Id: 12, Name: "Sonar", Type: "SHARED", PluginVersion: "1.2.0", PluginParentId: 3, IsLatest: true,
+		PluginSteps: []*PluginStepsDto{
+			{Id: 40, Name: "scan", StepType: repository.PLUGIN_STEP_TYPE_INLINE, PluginPipelineScript: &PluginPipelineScript{
+				Id: 7, Script: "sonar-scanner",
+				PathArgPortMapping: []*ScriptPathArgPortMapping{{Id: 1, ScriptId: 7, PortOnLocal: 80}, {Id: 2, ScriptId: 6}},
+			}, PluginStepVariable: []*PluginVariableDto{{Id: 90, Name: "URL", PluginStepCondition: []*PluginStepCondition{
+				{Id: 5, PluginStepId: 40, ConditionVariableId: 90, ConditionalValue: "x"},
+			}}}},
+			{Id: 41, Name: "notify", StepType: repository.PLUGIN_STEP_TYPE_REF_PLUGIN, RefPluginId: 8,
+				PluginPipelineScript: &PluginPipelineScript{Id: 0}},
+		},
+	}
+	bundlePlugin := getBundlePlugin(plugin, map[int]string{8: "Notifier"})
+	if bundlePlugin.Id != 0 || bundlePlugin.PluginParentId != 0 || bundlePlugin.IsLatest || bundlePlugin.PluginVersion != "1.2.0" {
+		t.Errorf("getBundlePlugin() metadata = %+v", bundlePlugin)
+	}
+	inline, ref := bundlePlugin.PluginSteps[0], bundlePlugin.PluginSteps[1]
+	if inline.Id != 0 || inline.PluginPipelineScript.Id != 0 || len(inline.PluginPipelineScript.PathArgPortMapping) != 1 ||
+		inline.PluginPipelineScript.PathArgPortMapping[0].ScriptId != 0 || inline.PluginPipelineScript.PathArgPortMapping[0].PortOnLocal != 80 {
+		t.Errorf("getBundlePlugin() inline step = %+v, script %+v", inline, inline.PluginPipelineScript)
+	}
+	if condition := inline.PluginStepVariable[0].PluginStepCondition[0]; inline.PluginStepVariable[0].Id != 0 || condition.Id != 0 ||
+		condition.ConditionVariableId != 0 || condition.ConditionalValue != "x" {
+		t.Errorf("getBundlePlugin() variable = %+v, condition %+v", inline.PluginStepVariable[0], condition)
+	}
+	if ref.RefPluginId != 0 || ref.RefPluginName != "Notifier" || ref.PluginPipelineScript != nil {
+		t.Errorf("getBundlePlugin() ref plugin step = %+v", ref)
+	}
+
+	content, err := yaml.Marshal(&PluginBundle{ApiVersion: PLUGIN_BUNDLE_API_VERSION, Kind: PLUGIN_BUNDLE_KIND, Plugins: []*PluginMetadataDto{bundlePlugin}})
+	if err != nil {
+		t.Fatalf("yaml.Marshal() err = %v", err)
+	}
+	bundle, err := parsePluginBundle(content)
+	if err != nil || len(bundle.Plugins) != 1 || bundle.Plugins[0].PluginSteps[1].RefPluginName != "Notifier" {
+		t.Errorf("parsePluginBundle() of exported bundle = %+v, err %v", bundle, err)
+	}
+}
+
+func TestParsePluginBundle(t *testing.T) {
+	bundle, err := parsePluginBundle([]byte(`
+apiVersion: devtron.ai/v1
+kind: PluginBundle
+plugins:
+- name: Notifier
+  type: SHARED
+`))
+	if err != nil || bundle.Plugins[0].PluginVersion != DEFAULT_PLUGIN_VERSION {
+		t.Errorf("parsePluginBundle() = %+v, err %v", bundle, err)
+	}
+	invalid := map[string]string{
+		"kind":          "apiVersion: devtron.ai/v1\nkind: Plugin\nplugins:\n- name: a\n  type: SHARED\n",
+		"empty":         "apiVersion: devtron.ai/v1\nkind: PluginBundle\nplugins: []\n",
+		"duplicate":     "apiVersion: devtron.ai/v1\nkind: PluginBundle\nplugins:\n- name: a\n  type: SHARED\n- name: a\n  type: SHARED\n",
+		"type":          "apiVersion: devtron.ai/v1\nkind: PluginBundle\nplugins:\n- name: a\n  type: OTHER\n",
+		"version":       "apiVersion: devtron.ai/v1\nkind: PluginBundle\nplugins:\n- name: a\n  type: SHARED\n  pluginVersion: latest\n",
+		"ref step":      "apiVersion: devtron.ai/v1\nkind: PluginBundle\nplugins:\n- name: a\n  type: SHARED\n  pluginSteps:\n  - name: s\n    stepType: REF_PLUGIN\n",
+		"inline script": "apiVersion: devtron.ai/v1\nkind: PluginBundle\nplugins:\n- name: a\n  type: SHARED\n  pluginSteps:\n  - name: s\n    stepType: INLINE\n",
+	}
+	for name, content := range invalid {
+		if _, err := parsePluginBundle([]byte(content)); err == nil {
+			t.Errorf("parsePluginBundle() of bundle with invalid %s did not fail", name)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/devtron-labs/devtron/pkg/plugin/repository"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+var (
+	scopedVariableReferenceRegex = regexp.MustCompile(`@{{[^}]+}}`)
+	secretReferenceRegex         = regexp.MustCompile(`^@{{[^}]+}}$`)
+)
+
+// ValidatePluginInputValue checks the value saved for an input variable of a step using a plugin against the constraint
+// of the variable, the default value of the variable is checked if the value is empty. Values referring scoped variables are checked at trigger, once the
+// variables are resolved, secret references must be a scoped variable so that secrets are not saved in pipelines.
+func ValidatePluginInputValue(input *repository.PluginStepVariable, value string) error {
+	return validatePluginInputValue(input, value, false)
+}
+
+// ValidateResolvedPluginInputValue checks the value of an input variable of a step using a plugin at trigger, after
+// scoped variables are resolved
+func ValidateResolvedPluginInputValue(input *repository.PluginStepVariable, value string) error {
+	return validatePluginInputValue(input, value, true)
+}
+
+// validatePluginInputValue only checks inputs declaring a constraint, empty values of the other inputs are left to the
+// plugin as they were saved before constraints existed
+func validatePluginInputValue(input *repository.PluginStepVariable, value string, resolved bool) error {
+	if input.ValueConstraint == nil {
+		return nil
+	}
+	if len(value) == 0 {
+		value = input.DefaultValue
+	}
+	if len(value) == 0 {
+		if input.AllowEmptyValue {
+			return nil
+		}
+		return errors.New("value is required")
+	}
+	return checkConstraint(input.ValueConstraint, value, resolved)
+}
+
+// checkConstraint checks value satisfies the constraint, values are not part of the errors as they can be secrets once
+// resolved
+func checkConstraint(constraint *repository.PluginVariableConstraint, value string, resolved bool) error {
+	if constraint.Type == repository.PLUGIN_VARIABLE_CONSTRAINT_SECRET_REFERENCE {
+		if !resolved && !secretReferenceRegex.MatchString(strings.TrimSpace(value)) {
+			return errors.New("must refer the secret through a scoped variable, e.g. @{{TOKEN}}")
+		}
+		return nil
+	}
+	if !resolved && scopedVariableReferenceRegex.MatchString(value) {
+		return nil
+	}
+	switch constraint.Type {
+	case repository.PLUGIN_VARIABLE_CONSTRAINT_ENUM:
+		for _, choice := range constraint.Choices {
+			if value == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(constraint.Choices, ", "))
+	case repository.PLUGIN_VARIABLE_CONSTRAINT_REGEX:
+		pattern, err := compilePattern(constraint.Pattern)
+		if err != nil {
+			return err
+		}
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("must match %s", constraint.Pattern)
+		}
+	case repository.PLUGIN_VARIABLE_CONSTRAINT_NUMBER_RANGE:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		if constraint.Min != nil && number < *constraint.Min {
+			return fmt.Errorf("must be at least %v", *constraint.Min)
+		}
+		if constraint.Max != nil && number > *constraint.Max {
+			return fmt.Errorf("must be at most %v", *constraint.Max)
+		}
+	case repository.PLUGIN_VARIABLE_CONSTRAINT_BOOLEAN:
+		if _, err := strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+			return errors.New("must be true or false")
+		}
+	case repository.PLUGIN_VARIABLE_CONSTRAINT_JSON:
+		var document interface{}
+		if err := json.Unmarshal([]byte(value), &document); err != nil {
+			return errors.New("must be valid JSON")
+		}
+		if len(constraint.JsonSchema) == 0 {
+			return nil
+		}
+		result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(constraint.JsonSchema), gojsonschema.NewGoLoader(document))
+		if err != nil {
+			return fmt.Errorf("invalid JSON schema: %s", err.Error())
+		}
+		if !result.Valid() {
+			var schemaErrors []string
+			for _, schemaError := range result.Errors() {
+				schemaErrors = append(schemaErrors, schemaError.String())
+			}
+			return fmt.Errorf("does not match the JSON schema: %s", strings.Join(schemaErrors, ", "))
+		}
+	case repository.PLUGIN_VARIABLE_CONSTRAINT_FILE_REFERENCE:
+		filePath := path.Clean(strings.TrimSpace(value))
+		if filePath == ".." || strings.HasPrefix(filePath, "../") {
+			return errors.New("must be a file path inside the workspace")
+		}
+		if len(constraint.FileExtensions) == 0 {
+			return nil
+		}
+		for _, extension := range constraint.FileExtensions {
+			if strings.EqualFold(path.Ext(filePath), "."+strings.TrimPrefix(extension, ".")) {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be a file with one of the extensions %s", strings.Join(constraint.FileExtensions, ", "))
+	default:
+		return fmt.Errorf("unknown constraint type %q", constraint.Type)
+	}
+	return nil
+}
+
+// validateConstraintDefinition checks the constraint declared on a plugin input variable can be checked
+func validateConstraintDefinition(constraint *repository.PluginVariableConstraint) error {
+	switch constraint.Type {
+	case repository.PLUGIN_VARIABLE_CONSTRAINT_ENUM:
+		if len(constraint.Choices) == 0 {
+			return errors.New("choices are required for ENUM")
+		}
+	case repository.PLUGIN_VARIABLE_CONSTRAINT_REGEX:
+		if len(constraint.Pattern) == 0 {
+			return errors.New("pattern is required for REGEX")
+		}
+		if _, err := compilePattern(constraint.Pattern); err != nil {
+			return err
+		}
+	case repository.PLUGIN_VARIABLE_CONSTRAINT_NUMBER_RANGE:
+		if constraint.Min != nil && constraint.Max != nil && *constraint.Min > *constraint.Max {
+			return errors.New("min is greater than max")
+		}
+	case repository.PLUGIN_VARIABLE_CONSTRAINT_JSON:
+		if len(constraint.JsonSchema) > 0 {
+			if _, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(constraint.JsonSchema)); err != nil {
+				return fmt.Errorf("invalid JSON schema: %s", err.Error())
+			}
+		}
+	case repository.PLUGIN_VARIABLE_CONSTRAINT_BOOLEAN, repository.PLUGIN_VARIABLE_CONSTRAINT_FILE_REFERENCE,
+		repository.PLUGIN_VARIABLE_CONSTRAINT_SECRET_REFERENCE:
+	default:
+		return fmt.Errorf("unknown constraint type %q", constraint.Type)
+	}
+	return nil
+}
+
+// validatePluginVariableConstraints checks the constraints of the exposed inputs of the plugin steps and that the
+// default values of the inputs satisfy them
+func validatePluginVariableConstraints(pluginSteps []*PluginStepsDto) error {
+	for _, input := range getExposedInputVariables(pluginSteps) {
+		if input.ValueConstraint == nil {
+			continue
+		}
+		if err := validateConstraintDefinition(input.ValueConstraint); err != nil {
+			return badRequest(fmt.Sprintf("invalid constraint of input variable %s: %s", input.Name, err.Error()))
+		}
+		if len(input.DefaultValue) == 0 {
+			continue
+		}
+		if err := checkConstraint(input.ValueConstraint, input.DefaultValue, false); err != nil {
+			return badRequest(fmt.Sprintf("invalid default value of input variable %s: %s", input.Name, err.Error()))
+		}
+	}
+	return nil
+}
+
+// compilePattern compiles the pattern of a REGEX constraint, the pattern must match the whole value
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	compiled, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %s", pattern, err.Error())
+	}
+	return compiled, nil
+}
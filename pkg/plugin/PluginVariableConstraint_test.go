@@ -0,0 +1,93 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/pkg/plugin/repository"
+)
+
+func TestValidatePluginInputValue(t *testing.T) {
+	min, max := 1.0, 10.0
+	schema := map[string]interface{}{
+		"type":       "object",
+		"required":   []interface{}{"host"},
+		"properties": map[string]interface{}{"host": map[string]interface{}{"type": "string"}},
+	}
+	inputs := map[string]*repository.PluginStepVariable{
+		"enum":     {ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_ENUM, Choices: []string{"low", "high"}}},
+		"regex":    {ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_REGEX, Pattern: "v[0-9]+"}},
+		"range":    {ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_NUMBER_RANGE, Min: &min, Max: &max}},
+		"bool":     {ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_BOOLEAN}},
+		"json":     {ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_JSON, JsonSchema: schema}},
+		"file":     {ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_FILE_REFERENCE, FileExtensions: []string{"yaml", ".yml"}}},
+		"secret":   {ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_SECRET_REFERENCE}},
+		"plain":    {DefaultValue: "x"},
+		"empty":    {AllowEmptyValue: true},
+		"legacy":   {},
+		"optional": {AllowEmptyValue: true, ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_BOOLEAN}},
+	}
+	tests := []struct {
+		input   string
+		value   string
+		wantErr bool
+	}{
+		{"enum", "high", false},
+		{"enum", "medium", true},
+		{"regex", "v12", false},
+		{"regex", "av12", true},
+		{"range", "10", false},
+		{"range", "0.5", true},
+		{"range", "ten", true},
+		{"bool", "true", false},
+		{"bool", "yes", true},
+		{"json", `{"host":"sonar"}`, false},
+		{"json", `{"port":80}`, true},
+		{"json", `{"host":`, true},
+		{"file", "config/app.YAML", false},
+		{"file", "../app.yaml", true},
+		{"file", "app.json", true},
+		{"secret", "@{{SONAR_TOKEN}}", false},
+		{"secret", "plain-token", true},
+		{"range", "@{{RETRIES}}", false},
+		{"plain", "", false},
+		{"empty", "", false},
+		{"enum", "", true},
+		{"legacy", "", false},
+		{"optional", "", false},
+	}
+	for _, tt := range tests {
+		err := ValidatePluginInputValue(inputs[tt.input], tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidatePluginInputValue(%s, %q) err = %v, want error %v", tt.input, tt.value, err, tt.wantErr)
+		}
+	}
+	if err := ValidateResolvedPluginInputValue(inputs["secret"], "plain-token"); err != nil {
+		t.Errorf("ValidateResolvedPluginInputValue() of resolved secret err = %v", err)
+	}
+	if err := ValidateResolvedPluginInputValue(inputs["range"], "@{{RETRIES}}"); err == nil {
+		t.Errorf("ValidateResolvedPluginInputValue() of unresolved number did not fail")
+	}
+}
+
+func TestValidatePluginVariableConstraints(t *testing.T) {
+	min, max := 5.0, 1.0
+	invalid := map[string]*PluginVariableDto{
+		"enum":    {ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_ENUM}},
+		"regex":   {ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_REGEX, Pattern: "("}},
+		"range":   {ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_NUMBER_RANGE, Min: &min, Max: &max}},
+		"schema":  {ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_JSON, JsonSchema: map[string]interface{}{"type": 12}}},
+		"type":    {ValueConstraint: &repository.PluginVariableConstraint{Type: "DATE"}},
+		"default": {DefaultValue: "maybe", ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_BOOLEAN}},
+	}
+	for name, input := range invalid {
+		input.Name, input.IsExposed, input.VariableType = name, true, repository.PLUGIN_VARIABLE_TYPE_INPUT
+		if err := validatePluginVariableConstraints([]*PluginStepsDto{{PluginStepVariable: []*PluginVariableDto{input}}}); err == nil {
+			t.Errorf("validatePluginVariableConstraints() of invalid %s did not fail", name)
+		}
+	}
+	valid := &PluginVariableDto{Name: "LEVEL", IsExposed: true, VariableType: repository.PLUGIN_VARIABLE_TYPE_INPUT, DefaultValue: "low",
+		ValueConstraint: &repository.PluginVariableConstraint{Type: repository.PLUGIN_VARIABLE_CONSTRAINT_ENUM, Choices: []string{"low"}}}
+	if err := validatePluginVariableConstraints([]*PluginStepsDto{{PluginStepVariable: []*PluginVariableDto{valid}}}); err != nil {
+		t.Errorf("validatePluginVariableConstraints() err = %v", err)
+	}
+}
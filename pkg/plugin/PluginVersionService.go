@@ -0,0 +1,611 @@
+package plugin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/devtron-labs/devtron/internal/util"
+	repository2 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	"github.com/devtron-labs/devtron/pkg/plugin/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// Versions of a plugin are immutable plugin_metadata rows sharing the id of the first version as their parent id.
+// Pipeline steps reference the version they were saved with and optionally a semver constraint, steps with a
+// constraint run the newest version matching it.
+
+// PublishPluginVersion creates a new version of the plugin, changes breaking the pipelines on the previous version of
+// the same major version are rejected
+func (impl *GlobalPluginServiceImpl) PublishPluginVersion(pluginReq *PluginMetadataDto, userId int32) (*PluginMetadataDto, error) {
+	dbConnection := impl.globalPluginRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	pluginReq, err = impl.PublishPluginVersionWithTx(pluginReq, userId, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		impl.logger.Errorw("error in committing db transaction", "err", err)
+		return nil, err
+	}
+	return pluginReq, nil
+}
+
+func (impl *GlobalPluginServiceImpl) PublishPluginVersionWithTx(pluginReq *PluginMetadataDto, userId int32, tx *pg.Tx) (*PluginMetadataDto, error) {
+	basePlugin, err := impl.getPlugin(pluginReq.Id)
+	if err != nil {
+		return nil, err
+	}
+	newVersion, isLatest, err := impl.validateNewPluginVersion(basePlugin, pluginReq)
+	if err != nil {
+		return nil, err
+	}
+	parentId := basePlugin.GetParentId()
+	if len(pluginReq.Tags) == 0 {
+		pluginReq.Tags, err = impl.globalPluginRepository.GetTagsByPluginId(basePlugin.Id)
+		if err != nil {
+			impl.logger.Errorw("error in getting tags of plugin", "pluginId", basePlugin.Id, "err", err)
+			return nil, err
+		}
+	}
+	pluginStage := repository.CI_CD
+	if pluginReq.PluginStage == CI_TYPE_PLUGIN {
+		pluginStage = repository.CI
+	} else if pluginReq.PluginStage == CD_TYPE_PLUGIN {
+		pluginStage = repository.CD
+	} else if len(pluginReq.PluginStage) == 0 {
+		baseStageMapping, err := impl.globalPluginRepository.GetPluginStageMappingByPluginId(basePlugin.Id)
+		if err != nil {
+			impl.logger.Errorw("error in getting plugin stage mapping", "pluginId", basePlugin.Id, "err", err)
+			return nil, err
+		}
+		pluginStage = baseStageMapping.StageType
+	}
+
+	if isLatest {
+		err = impl.globalPluginRepository.MarkPluginVersionsNotLatest(parentId, userId, tx)
+		if err != nil {
+			impl.logger.Errorw("error in unmarking latest plugin version", "parentId", parentId, "err", err)
+			return nil, err
+		}
+	}
+	pluginMetadata := pluginReq.getPluginMetadataSqlObj(userId)
+	pluginMetadata.Name = basePlugin.Name
+	if len(pluginMetadata.Type) == 0 {
+		pluginMetadata.Type = basePlugin.Type
+	}
+	if len(pluginMetadata.Icon) == 0 {
+		pluginMetadata.Icon = basePlugin.Icon
+	}
+	pluginMetadata.PluginParentId = parentId
+	pluginMetadata.PluginVersion = newVersion.String()
+	pluginMetadata.IsLatest = isLatest
+	pluginMetadata, err = impl.globalPluginRepository.SavePluginMetadata(pluginMetadata, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving plugin version", "pluginDto", pluginReq, "err", err)
+		return nil, err
+	}
+	_, err = impl.globalPluginRepository.SavePluginStageMapping(&repository.PluginStageMapping{
+		PluginId:  pluginMetadata.Id,
+		StageType: pluginStage,
+		AuditLog:  sql.NewDefaultAuditLog(userId),
+	}, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving plugin stage mapping", "pluginId", pluginMetadata.Id, "err", err)
+		return nil, err
+	}
+	err = impl.saveDeepPluginStepData(pluginMetadata.Id, pluginReq.PluginSteps, userId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving plugin step data", "pluginId", pluginMetadata.Id, "err", err)
+		return nil, err
+	}
+	pluginReq.Id = pluginMetadata.Id
+	err = impl.CreateNewPluginTagsAndRelationsIfRequired(pluginReq, false, userId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving plugin tags", "pluginId", pluginMetadata.Id, "err", err)
+		return nil, err
+	}
+	pluginReq.Name = pluginMetadata.Name
+	pluginReq.Type = string(pluginMetadata.Type)
+	pluginReq.PluginParentId = parentId
+	pluginReq.PluginVersion = pluginMetadata.PluginVersion
+	pluginReq.IsLatest = isLatest
+	return pluginReq, nil
+}
+
+// ValidatePluginVersion checks the version of the request can be published as a new version of the plugin of its id
+func (impl *GlobalPluginServiceImpl) ValidatePluginVersion(pluginReq *PluginMetadataDto) error {
+	basePlugin, err := impl.getPlugin(pluginReq.Id)
+	if err != nil {
+		return err
+	}
+	_, _, err = impl.validateNewPluginVersion(basePlugin, pluginReq)
+	return err
+}
+
+// validateNewPluginVersion checks the version of the request can be published as a new version of the plugin, it
+// returns the version and whether it is the newest one
+func (impl *GlobalPluginServiceImpl) validateNewPluginVersion(basePlugin *repository.PluginMetadata, pluginReq *PluginMetadataDto) (*semver.Version, bool, error) {
+	newVersion, err := semver.NewVersion(pluginReq.PluginVersion)
+	if err != nil {
+		return nil, false, badRequest(fmt.Sprintf("invalid plugin version %q, versions must follow semver", pluginReq.PluginVersion))
+	}
+	if err = validatePluginVariableConstraints(pluginReq.PluginSteps); err != nil {
+		return nil, false, err
+	}
+	versions, err := impl.globalPluginRepository.GetPluginVersionsByParentId(basePlugin.GetParentId())
+	if err != nil {
+		impl.logger.Errorw("error in getting plugin versions", "pluginId", basePlugin.Id, "err", err)
+		return nil, false, err
+	}
+	isLatest := true
+	var previous *repository.PluginMetadata
+	var previousVersion *semver.Version
+	for _, version := range versions {
+		v, err := semver.NewVersion(version.PluginVersion)
+		if err != nil {
+			continue
+		}
+		if v.Equal(newVersion) {
+			return nil, false, badRequest(fmt.Sprintf("version %s of plugin %s already exists", newVersion.String(), basePlugin.Name))
+		}
+		if v.GreaterThan(newVersion) {
+			isLatest = false
+		} else if v.Major() == newVersion.Major() && (previousVersion == nil || v.GreaterThan(previousVersion)) {
+			previous, previousVersion = version, v
+		}
+	}
+	if previous != nil {
+		previousInputs, err := impl.globalPluginRepository.GetExposedVariablesByPluginIdAndVariableType(previous.Id, repository.PLUGIN_VARIABLE_TYPE_INPUT)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting input variables of plugin", "pluginId", previous.Id, "err", err)
+			return nil, false, err
+		}
+		if changes := findBreakingChanges(previousInputs, getExposedInputVariables(pluginReq.PluginSteps)); len(changes) > 0 {
+			return nil, false, badRequest(fmt.Sprintf("version %s breaks pipelines using version %s, publish it as a new major version: %s",
+				newVersion.String(), previous.PluginVersion, strings.Join(changes, ", ")))
+		}
+	}
+	return newVersion, isLatest, nil
+}
+
+func (impl *GlobalPluginServiceImpl) GetPluginVersions(pluginId int) ([]*PluginVersionDto, error) {
+	basePlugin, err := impl.getPlugin(pluginId)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := impl.globalPluginRepository.GetPluginVersionsByParentId(basePlugin.GetParentId())
+	if err != nil {
+		impl.logger.Errorw("error in getting plugin versions", "pluginId", pluginId, "err", err)
+		return nil, err
+	}
+	usages, err := impl.getPluginStepUsages(versions)
+	if err != nil {
+		return nil, err
+	}
+	usageCounts := make(map[int]int)
+	for _, usage := range usages {
+		if runs := resolveStepUsage(usage, versions); runs != nil {
+			usageCounts[runs.Id]++
+		}
+	}
+	sortPluginVersions(versions)
+	versionDtos := make([]*PluginVersionDto, 0, len(versions))
+	for _, version := range versions {
+		versionDtos = append(versionDtos, &PluginVersionDto{
+			Id:                 version.Id,
+			PluginParentId:     version.GetParentId(),
+			Name:               version.Name,
+			PluginVersion:      version.PluginVersion,
+			IsLatest:           version.IsLatest,
+			Deprecated:         version.Deprecated,
+			DeprecationMessage: version.DeprecationMessage,
+			StepUsageCount:     usageCounts[version.Id],
+			CreatedOn:          version.CreatedOn,
+		})
+	}
+	return versionDtos, nil
+}
+
+// DeprecatePluginVersion marks the version as deprecated or not, steps with a version constraint stop resolving to
+// deprecated versions if another version matches
+func (impl *GlobalPluginServiceImpl) DeprecatePluginVersion(request *PluginDeprecationRequest) error {
+	pluginMetadata, err := impl.getPlugin(request.PluginId)
+	if err != nil {
+		return err
+	}
+	pluginMetadata.Deprecated = request.Deprecated
+	pluginMetadata.DeprecationMessage = ""
+	if request.Deprecated {
+		pluginMetadata.DeprecationMessage = request.Message
+	}
+	pluginMetadata.UpdatedOn = time.Now()
+	pluginMetadata.UpdatedBy = request.UserId
+	dbConnection := impl.globalPluginRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = impl.globalPluginRepository.UpdatePluginMetadata(pluginMetadata, tx)
+	if err != nil {
+		impl.logger.Errorw("error in updating plugin deprecation", "pluginId", request.PluginId, "err", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetOutdatedPluginUsages returns the pipeline steps which run a version of the plugin older than its latest version
+func (impl *GlobalPluginServiceImpl) GetOutdatedPluginUsages(pluginId int) (*OutdatedPluginUsageDto, error) {
+	basePlugin, err := impl.getPlugin(pluginId)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := impl.globalPluginRepository.GetPluginVersionsByParentId(basePlugin.GetParentId())
+	if err != nil {
+		impl.logger.Errorw("error in getting plugin versions", "pluginId", pluginId, "err", err)
+		return nil, err
+	}
+	sortPluginVersions(versions)
+	latest := versions[len(versions)-1]
+	for _, version := range versions {
+		if version.IsLatest {
+			latest = version
+		}
+	}
+	usages, err := impl.getPluginStepUsages(versions)
+	if err != nil {
+		return nil, err
+	}
+	outdated := &OutdatedPluginUsageDto{
+		PluginParentId: latest.GetParentId(),
+		Name:           latest.Name,
+		LatestVersion:  latest.PluginVersion,
+		Steps:          make([]*PluginStepUsageDto, 0),
+	}
+	versionsById := getVersionsById(versions)
+	for _, usage := range usages {
+		runs := resolveStepUsage(usage, versions)
+		if runs == nil || runs.Id == latest.Id {
+			continue
+		}
+		outdated.Steps = append(outdated.Steps, getStepUsageDto(usage, versionsById[usage.RefPluginId], runs))
+	}
+	return outdated, nil
+}
+
+// UpgradePluginUsages moves pipeline steps to the target version of the plugin and migrates their input variables,
+// steps with a version constraint the target does not match are constrained to the target major version
+func (impl *GlobalPluginServiceImpl) UpgradePluginUsages(request *PluginUpgradeRequest) (*PluginUpgradeResponse, error) {
+	basePlugin, err := impl.getPlugin(request.PluginId)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := impl.globalPluginRepository.GetPluginVersionsByParentId(basePlugin.GetParentId())
+	if err != nil {
+		impl.logger.Errorw("error in getting plugin versions", "pluginId", request.PluginId, "err", err)
+		return nil, err
+	}
+	target, targetVersion, err := getTargetVersion(versions, request.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+	usages, err := impl.getPluginStepUsages(versions)
+	if err != nil {
+		return nil, err
+	}
+	usagesToUpgrade, err := selectUsagesToUpgrade(usages, getVersionsById(versions), targetVersion, request.StepIds)
+	if err != nil {
+		return nil, err
+	}
+	targetInputs, err := impl.globalPluginRepository.GetExposedVariablesByPluginIdAndVariableType(target.Id, repository.PLUGIN_VARIABLE_TYPE_INPUT)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting input variables of plugin", "pluginId", target.Id, "err", err)
+		return nil, err
+	}
+
+	dbConnection := impl.globalPluginRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	response := &PluginUpgradeResponse{
+		TargetPluginId: target.Id,
+		TargetVersion:  target.PluginVersion,
+		DryRun:         request.DryRun,
+		Steps:          make([]*PluginStepUpgradeDto, 0, len(usagesToUpgrade)),
+	}
+	versionsById := getVersionsById(versions)
+	for _, usage := range usagesToUpgrade {
+		stepUpgrade, err := impl.upgradePluginStep(usage, target, targetInputs, request, tx)
+		if err != nil {
+			impl.logger.Errorw("error in upgrading plugin step", "stepId", usage.StepId, "targetPluginId", target.Id, "err", err)
+			return nil, err
+		}
+		stepUpgrade.FromVersion = versionsById[usage.RefPluginId].PluginVersion
+		response.Steps = append(response.Steps, stepUpgrade)
+	}
+	if request.DryRun {
+		return response, nil
+	}
+	err = tx.Commit()
+	if err != nil {
+		impl.logger.Errorw("error in committing db transaction", "err", err)
+		return nil, err
+	}
+	return response, nil
+}
+
+func (impl *GlobalPluginServiceImpl) upgradePluginStep(usage *repository2.PluginStepUsage, target *repository.PluginMetadata,
+	targetInputs []*repository.PluginStepVariable, request *PluginUpgradeRequest, tx *pg.Tx) (*PluginStepUpgradeDto, error) {
+	variables, err := impl.pipelineStageRepository.GetVariablesByStepId(usage.StepId)
+	if err != nil && err != pg.ErrNoRows {
+		return nil, err
+	}
+	var inputs []*repository2.PipelineStageStepVariable
+	for _, variable := range variables {
+		if variable.VariableType == repository2.PIPELINE_STAGE_STEP_VARIABLE_TYPE_INPUT {
+			inputs = append(inputs, variable)
+		}
+	}
+	migration := migrateStepInputVariables(usage.StepId, inputs, targetInputs, request.VariableRenames)
+	stepUpgrade := &PluginStepUpgradeDto{
+		StepId:           usage.StepId,
+		StepName:         usage.StepName,
+		StageType:        string(usage.StageType),
+		CiPipelineId:     usage.CiPipelineId,
+		CdPipelineId:     usage.CdPipelineId,
+		ToVersion:        target.PluginVersion,
+		AddedVariables:   migration.added,
+		RemovedVariables: migration.removed,
+		RenamedVariables: migration.renamed,
+		MissingValues:    migration.missingValues,
+		Upgraded:         len(migration.missingValues) == 0,
+	}
+	if !stepUpgrade.Upgraded || request.DryRun {
+		return stepUpgrade, nil
+	}
+	now := time.Now()
+	if len(migration.toUpdate) > 0 {
+		variablesToUpdate := make([]repository2.PipelineStageStepVariable, 0, len(migration.toUpdate))
+		for _, variable := range migration.toUpdate {
+			variable.UpdatedOn = now
+			variable.UpdatedBy = request.UserId
+			variablesToUpdate = append(variablesToUpdate, *variable)
+		}
+		if _, err = impl.pipelineStageRepository.UpdatePipelineStageStepVariables(variablesToUpdate, tx); err != nil {
+			return nil, err
+		}
+	}
+	if len(migration.toCreate) > 0 {
+		variablesToCreate := make([]repository2.PipelineStageStepVariable, 0, len(migration.toCreate))
+		for _, variable := range migration.toCreate {
+			variable.AuditLog = sql.NewDefaultAuditLog(request.UserId)
+			variablesToCreate = append(variablesToCreate, *variable)
+		}
+		if _, err = impl.pipelineStageRepository.CreatePipelineStageStepVariables(variablesToCreate, tx); err != nil {
+			return nil, err
+		}
+	}
+	if len(migration.toDelete) > 0 {
+		var variableIds, conditionIds []int
+		for _, variable := range migration.toDelete {
+			variableIds = append(variableIds, variable.Id)
+			conditions, err := impl.pipelineStageRepository.GetConditionsByVariableId(variable.Id)
+			if err != nil && err != pg.ErrNoRows {
+				return nil, err
+			}
+			for _, condition := range conditions {
+				conditionIds = append(conditionIds, condition.Id)
+			}
+		}
+		if err = impl.pipelineStageRepository.MarkPipelineStageStepVariablesDeletedByIds(variableIds, request.UserId, tx); err != nil {
+			return nil, err
+		}
+		if len(conditionIds) > 0 {
+			if err = impl.pipelineStageRepository.MarkPipelineStageStepConditionDeletedByIds(conditionIds, request.UserId, tx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	step, err := impl.pipelineStageRepository.GetStepById(usage.StepId)
+	if err != nil {
+		return nil, err
+	}
+	step.RefPluginId = target.Id
+	if len(step.RefPluginVersionConstraint) > 0 {
+		if _, err := ResolvePluginVersion([]*repository.PluginMetadata{target}, step.RefPluginVersionConstraint); err != nil {
+			step.RefPluginVersionConstraint = "^" + target.PluginVersion
+		}
+	}
+	step.UpdatedOn = now
+	step.UpdatedBy = request.UserId
+	_, err = impl.pipelineStageRepository.UpdatePipelineStageStepWithTx(step, tx)
+	if err != nil {
+		return nil, err
+	}
+	return stepUpgrade, nil
+}
+
+// isPluginVersionInUse returns whether a pipeline step runs the version of the plugin
+func (impl *GlobalPluginServiceImpl) isPluginVersionInUse(pluginMetadata *repository.PluginMetadata) (bool, error) {
+	versions, err := impl.globalPluginRepository.GetPluginVersionsByParentId(pluginMetadata.GetParentId())
+	if err != nil {
+		impl.logger.Errorw("error in getting plugin versions", "pluginId", pluginMetadata.Id, "err", err)
+		return false, err
+	}
+	usages, err := impl.getPluginStepUsages(versions)
+	if err != nil {
+		return false, err
+	}
+	for _, usage := range usages {
+		if usage.RefPluginId == pluginMetadata.Id {
+			return true, nil
+		}
+		if runs := resolveStepUsage(usage, versions); runs != nil && runs.Id == pluginMetadata.Id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// markLatestPluginVersion marks the newest remaining version of the plugin as latest once its latest version is deleted
+func (impl *GlobalPluginServiceImpl) markLatestPluginVersion(deletedPlugin *repository.PluginMetadata, userId int32, tx *pg.Tx) error {
+	versions, err := impl.globalPluginRepository.GetPluginVersionsByParentId(deletedPlugin.GetParentId())
+	if err != nil {
+		impl.logger.Errorw("error in getting plugin versions", "pluginId", deletedPlugin.Id, "err", err)
+		return err
+	}
+	remaining := make([]*repository.PluginMetadata, 0, len(versions))
+	for _, version := range versions {
+		if version.Id != deletedPlugin.Id {
+			remaining = append(remaining, version)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil
+	}
+	sortPluginVersions(remaining)
+	latest := remaining[len(remaining)-1]
+	latest.IsLatest = true
+	latest.UpdatedOn = time.Now()
+	latest.UpdatedBy = userId
+	return impl.globalPluginRepository.UpdatePluginMetadata(latest, tx)
+}
+
+func (impl *GlobalPluginServiceImpl) getPlugin(pluginId int) (*repository.PluginMetadata, error) {
+	pluginMetadata, err := impl.globalPluginRepository.GetMetaDataByPluginId(pluginId)
+	if err == pg.ErrNoRows {
+		return nil, notFound(fmt.Sprintf("plugin %d not found", pluginId))
+	} else if err != nil {
+		impl.logger.Errorw("error in getting plugin", "pluginId", pluginId, "err", err)
+		return nil, err
+	}
+	return pluginMetadata, nil
+}
+
+func (impl *GlobalPluginServiceImpl) getPluginStepUsages(versions []*repository.PluginMetadata) ([]*repository2.PluginStepUsage, error) {
+	versionIds := make([]int, 0, len(versions))
+	for _, version := range versions {
+		versionIds = append(versionIds, version.Id)
+	}
+	usages, err := impl.pipelineStageRepository.GetActiveStepUsagesByRefPluginIds(versionIds)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting plugin step usages", "pluginIds", versionIds, "err", err)
+		return nil, err
+	}
+	return usages, nil
+}
+
+// resolveStepUsage returns the version the step runs, nil if its version constraint matches no version
+func resolveStepUsage(usage *repository2.PluginStepUsage, versions []*repository.PluginMetadata) *repository.PluginMetadata {
+	if len(usage.RefPluginVersionConstraint) > 0 {
+		resolved, err := ResolvePluginVersion(versions, usage.RefPluginVersionConstraint)
+		if err != nil {
+			return nil
+		}
+		return resolved
+	}
+	for _, version := range versions {
+		if version.Id == usage.RefPluginId {
+			return version
+		}
+	}
+	return nil
+}
+
+// selectUsagesToUpgrade returns the steps with the given ids, all the steps saved with a version older than the
+// target if no ids are given
+func selectUsagesToUpgrade(usages []*repository2.PluginStepUsage, versionsById map[int]*repository.PluginMetadata,
+	targetVersion *semver.Version, stepIds []int) ([]*repository2.PluginStepUsage, error) {
+	var selected []*repository2.PluginStepUsage
+	if len(stepIds) > 0 {
+		usagesByStepId := make(map[int]*repository2.PluginStepUsage, len(usages))
+		for _, usage := range usages {
+			usagesByStepId[usage.StepId] = usage
+		}
+		for _, stepId := range stepIds {
+			usage, ok := usagesByStepId[stepId]
+			if !ok {
+				return nil, badRequest(fmt.Sprintf("step %d does not use the plugin", stepId))
+			}
+			selected = append(selected, usage)
+		}
+		return selected, nil
+	}
+	for _, usage := range usages {
+		v, err := semver.NewVersion(versionsById[usage.RefPluginId].PluginVersion)
+		if err != nil || v.LessThan(targetVersion) {
+			selected = append(selected, usage)
+		}
+	}
+	return selected, nil
+}
+
+// getTargetVersion returns the version to upgrade to, the latest version if none is given
+func getTargetVersion(versions []*repository.PluginMetadata, targetVersion string) (*repository.PluginMetadata, *semver.Version, error) {
+	var wanted *semver.Version
+	if len(targetVersion) > 0 {
+		var err error
+		wanted, err = semver.NewVersion(targetVersion)
+		if err != nil {
+			return nil, nil, badRequest(fmt.Sprintf("invalid target version %q, versions must follow semver", targetVersion))
+		}
+	}
+	for _, version := range versions {
+		v, err := semver.NewVersion(version.PluginVersion)
+		if err != nil {
+			continue
+		}
+		if (wanted == nil && version.IsLatest) || (wanted != nil && v.Equal(wanted)) {
+			if version.Deprecated {
+				return nil, nil, badRequest(fmt.Sprintf("version %s of the plugin is deprecated", version.PluginVersion))
+			}
+			return version, v, nil
+		}
+	}
+	return nil, nil, notFound(fmt.Sprintf("version %s of the plugin not found", targetVersion))
+}
+
+func getVersionsById(versions []*repository.PluginMetadata) map[int]*repository.PluginMetadata {
+	versionsById := make(map[int]*repository.PluginMetadata, len(versions))
+	for _, version := range versions {
+		versionsById[version.Id] = version
+	}
+	return versionsById
+}
+
+func getStepUsageDto(usage *repository2.PluginStepUsage, saved *repository.PluginMetadata, runs *repository.PluginMetadata) *PluginStepUsageDto {
+	return &PluginStepUsageDto{
+		StepId:            usage.StepId,
+		StepName:          usage.StepName,
+		StageType:         string(usage.StageType),
+		CiPipelineId:      usage.CiPipelineId,
+		CdPipelineId:      usage.CdPipelineId,
+		PluginId:          usage.RefPluginId,
+		PluginVersion:     saved.PluginVersion,
+		VersionConstraint: usage.RefPluginVersionConstraint,
+		RunsVersion:       runs.PluginVersion,
+		Deprecated:        runs.Deprecated,
+	}
+}
+
+func badRequest(message string) *util.ApiError {
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: message, UserMessage: message}
+}
+
+func notFound(message string) *util.ApiError {
+	return &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: message, UserMessage: message}
+}
@@ -1,6 +1,9 @@
 package plugin
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/devtron-labs/devtron/pkg/plugin/repository"
 	"github.com/devtron-labs/devtron/pkg/sql"
 )
@@ -14,6 +17,9 @@ const (
 	CI_CD_TYPE_PLUGIN = "CI_CD"
 )
 
+// DEFAULT_PLUGIN_VERSION is the version of plugins created without one, plugins existing before versioning are on it
+const DEFAULT_PLUGIN_VERSION = "1.0.0"
+
 type PluginDetailDto struct {
 	Metadata        *PluginMetadataDto   `json:"metadata"`
 	InputVariables  []*PluginVariableDto `json:"inputVariables"`
@@ -36,15 +42,27 @@ type PluginMetadataDto struct {
 	Action      int               `json:"action"`
 	PluginStage string            `json:"pluginStage,omitempty"`
 	PluginSteps []*PluginStepsDto `json:"pluginSteps,omitempty"`
+
+	PluginParentId     int    `json:"pluginParentId,omitempty"`
+	PluginVersion      string `json:"pluginVersion,omitempty"`
+	IsLatest           bool   `json:"isLatest"`
+	Deprecated         bool   `json:"deprecated"`
+	DeprecationMessage string `json:"deprecationMessage,omitempty"`
 }
 
 func (r *PluginMetadataDto) getPluginMetadataSqlObj(userId int32) *repository.PluginMetadata {
+	pluginVersion := r.PluginVersion
+	if len(pluginVersion) == 0 {
+		pluginVersion = DEFAULT_PLUGIN_VERSION
+	}
 	return &repository.PluginMetadata{
-		Name:        r.Name,
-		Description: r.Description,
-		Type:        repository.PluginType(r.Type),
-		Icon:        r.Icon,
-		AuditLog:    sql.NewDefaultAuditLog(userId),
+		Name:          r.Name,
+		Description:   r.Description,
+		Type:          repository.PluginType(r.Type),
+		Icon:          r.Icon,
+		PluginVersion: pluginVersion,
+		IsLatest:      true,
+		AuditLog:      sql.NewDefaultAuditLog(userId),
 	}
 }
 
@@ -54,7 +72,8 @@ type PluginStepsDto struct {
 	Description          string                    `json:"description"`
 	Index                int                       `json:"index"`
 	StepType             repository.PluginStepType `json:"stepType"`
-	RefPluginId          int                       `json:"refPluginId"` //id of plugin used as reference
+	RefPluginId          int                       `json:"refPluginId"`             //id of plugin used as reference
+	RefPluginName        string                    `json:"refPluginName,omitempty"` //name of plugin used as reference, set in plugin bundles
 	OutputDirectoryPath  []string                  `json:"outputDirectoryPath"`
 	DependentOnStep      string                    `json:"dependentOnStep"`
 	PluginStepVariable   []*PluginVariableDto      `json:"pluginStepVariable,omitempty"`
@@ -76,6 +95,7 @@ type PluginVariableDto struct {
 	VariableStepIndex         int                                     `json:"variableStepIndex"`
 	VariableStepIndexInPlugin int                                     `json:"variableStepIndexInPlugin"`
 	ReferenceVariableName     string                                  `json:"referenceVariableName,omitempty"`
+	ValueConstraint           *repository.PluginVariableConstraint    `json:"valueConstraint,omitempty"`
 	PluginStepCondition       []*PluginStepCondition                  `json:"pluginStepCondition,omitempty"`
 }
 
@@ -127,3 +147,156 @@ type RegistryCredentials struct {
 	AWSSecretAccessKey string `json:"awsSecretAccessKey,omitempty"`
 	AWSRegion          string `json:"awsRegion,omitempty"`
 }
+
+type PluginVersionDto struct {
+	Id                 int       `json:"id"`
+	PluginParentId     int       `json:"pluginParentId"`
+	Name               string    `json:"name"`
+	PluginVersion      string    `json:"pluginVersion"`
+	IsLatest           bool      `json:"isLatest"`
+	Deprecated         bool      `json:"deprecated"`
+	DeprecationMessage string    `json:"deprecationMessage,omitempty"`
+	StepUsageCount     int       `json:"stepUsageCount"`
+	CreatedOn          time.Time `json:"createdOn"`
+}
+
+type PluginDeprecationRequest struct {
+	PluginId   int    `json:"pluginId"`
+	Deprecated bool   `json:"deprecated"`
+	Message    string `json:"message"`
+	UserId     int32  `json:"-"`
+}
+
+// PluginStepUsageDto is a pipeline step using a version of the plugin, RunsVersion is the version the step runs, the
+// newest version matching its constraint if it has one
+type PluginStepUsageDto struct {
+	StepId            int    `json:"stepId"`
+	StepName          string `json:"stepName"`
+	StageType         string `json:"stageType"`
+	CiPipelineId      int    `json:"ciPipelineId,omitempty"`
+	CdPipelineId      int    `json:"cdPipelineId,omitempty"`
+	PluginId          int    `json:"pluginId"`
+	PluginVersion     string `json:"pluginVersion"`
+	VersionConstraint string `json:"versionConstraint,omitempty"`
+	RunsVersion       string `json:"runsVersion"`
+	Deprecated        bool   `json:"deprecated"`
+}
+
+type OutdatedPluginUsageDto struct {
+	PluginParentId int                   `json:"pluginParentId"`
+	Name           string                `json:"name"`
+	LatestVersion  string                `json:"latestVersion"`
+	Steps          []*PluginStepUsageDto `json:"steps"`
+}
+
+// PluginUpgradeRequest moves pipeline steps to the target version of the plugin, TargetVersion defaults to the latest
+// version and StepIds to all the steps on outdated versions. VariableRenames maps the names of input variables in
+// the current versions to their names in the target version
+type PluginUpgradeRequest struct {
+	PluginId        int               `json:"pluginId"`
+	TargetVersion   string            `json:"targetVersion"`
+	StepIds         []int             `json:"stepIds"`
+	VariableRenames map[string]string `json:"variableRenames"`
+	DryRun          bool              `json:"dryRun"`
+	UserId          int32             `json:"-"`
+}
+
+// PluginStepUpgradeDto is the migration of a pipeline step to the target version, steps with MissingValues are not
+// upgraded as the target version has required input variables they have no value for
+type PluginStepUpgradeDto struct {
+	StepId           int               `json:"stepId"`
+	StepName         string            `json:"stepName"`
+	StageType        string            `json:"stageType"`
+	CiPipelineId     int               `json:"ciPipelineId,omitempty"`
+	CdPipelineId     int               `json:"cdPipelineId,omitempty"`
+	FromVersion      string            `json:"fromVersion"`
+	ToVersion        string            `json:"toVersion"`
+	AddedVariables   []string          `json:"addedVariables"`
+	RemovedVariables []string          `json:"removedVariables"`
+	RenamedVariables map[string]string `json:"renamedVariables"`
+	MissingValues    []string          `json:"missingValues"`
+	Upgraded         bool              `json:"upgraded"`
+}
+
+type PluginUpgradeResponse struct {
+	TargetPluginId int                     `json:"targetPluginId"`
+	TargetVersion  string                  `json:"targetVersion"`
+	DryRun         bool                    `json:"dryRun"`
+	Steps          []*PluginStepUpgradeDto `json:"steps"`
+}
+
+// PluginInputError is an invalid value of an input variable of a pipeline step using a plugin
+type PluginInputError struct {
+	StepName     string `json:"stepName"`
+	VariableName string `json:"variableName"`
+	Message      string `json:"message"`
+}
+
+func (e *PluginInputError) Error() string {
+	return fmt.Sprintf("invalid value of input %s of step %s: %s", e.VariableName, e.StepName, e.Message)
+}
+
+const (
+	PLUGIN_BUNDLE_API_VERSION      = "devtron.ai/v1"
+	PLUGIN_BUNDLE_KIND             = "PluginBundle"
+	PLUGIN_BUNDLE_ARTIFACT_TYPE    = "application/vnd.devtron.plugin.bundle.v1"
+	PLUGIN_BUNDLE_LAYER_MEDIA_TYPE = "application/vnd.devtron.plugin.bundle.layer.v1+yaml"
+	PLUGIN_BUNDLE_FILE_NAME        = "plugins.yaml"
+	PLUGIN_BUNDLE_DEFAULT_TAG      = "latest"
+)
+
+const (
+	PLUGIN_IMPORT_ACTION_CREATE      = "CREATE"
+	PLUGIN_IMPORT_ACTION_NEW_VERSION = "NEW_VERSION"
+	PLUGIN_IMPORT_ACTION_SKIP        = "SKIP"
+	PLUGIN_IMPORT_ACTION_CONFLICT    = "CONFLICT"
+)
+
+// PluginBundle is the portable form of plugins, ids are dropped and plugins used as reference by steps are named by
+// RefPluginName. Plugins are ordered so that the ones used as reference come first.
+type PluginBundle struct {
+	ApiVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Plugins    []*PluginMetadataDto `json:"plugins"`
+}
+
+// PluginBundleRegistryReference is the oci artifact of a plugin bundle in a docker registry configured in devtron
+type PluginBundleRegistryReference struct {
+	DockerRegistryId string `json:"dockerRegistryId" validate:"required"`
+	Repository       string `json:"repository" validate:"required"`
+	Tag              string `json:"tag"`
+}
+
+type PluginBundlePushRequest struct {
+	PluginIds []int                          `json:"pluginIds" validate:"required,min=1"`
+	Registry  *PluginBundleRegistryReference `json:"registry" validate:"required"`
+}
+
+type PluginBundlePushResponse struct {
+	Digest  string   `json:"digest"`
+	Plugins []string `json:"plugins"`
+}
+
+// PluginBundleImportRequest imports the bundle pulled from Registry, or the uploaded one if Registry is not set.
+// Plugins already having the version of the bundle are conflicts and fail the import unless SkipConflicts is set.
+type PluginBundleImportRequest struct {
+	Registry      *PluginBundleRegistryReference `json:"registry,omitempty"`
+	DryRun        bool                           `json:"dryRun"`
+	SkipConflicts bool                           `json:"skipConflicts"`
+	UserId        int32                          `json:"-"`
+}
+
+type PluginImportResultDto struct {
+	Name          string `json:"name"`
+	PluginVersion string `json:"pluginVersion"`
+	// Action is CREATE for new plugins, NEW_VERSION for new versions of existing plugins, SKIP or CONFLICT
+	Action   string `json:"action"`
+	Message  string `json:"message,omitempty"`
+	PluginId int    `json:"pluginId,omitempty"`
+}
+
+type PluginBundleImportResponse struct {
+	DryRun   bool                     `json:"dryRun"`
+	Imported bool                     `json:"imported"`
+	Plugins  []*PluginImportResultDto `json:"plugins"`
+}
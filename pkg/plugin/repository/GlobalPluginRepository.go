@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
 	"go.uber.org/zap"
 )
 
@@ -15,6 +18,7 @@ type PluginStepVariableType string
 type PluginStepVariableValueType string
 type PluginStepConditionType string
 type PluginStepVariableFormatType string
+type PluginVariableConstraintType string
 
 const (
 	PLUGIN_TYPE_SHARED                  PluginType                   = "SHARED"
@@ -44,6 +48,16 @@ const (
 	PLUGIN_VARIABLE_FORMAT_TYPE_DATE    PluginStepVariableFormatType = "DATE"
 )
 
+const (
+	PLUGIN_VARIABLE_CONSTRAINT_ENUM             PluginVariableConstraintType = "ENUM"
+	PLUGIN_VARIABLE_CONSTRAINT_REGEX            PluginVariableConstraintType = "REGEX"
+	PLUGIN_VARIABLE_CONSTRAINT_NUMBER_RANGE     PluginVariableConstraintType = "NUMBER_RANGE"
+	PLUGIN_VARIABLE_CONSTRAINT_BOOLEAN          PluginVariableConstraintType = "BOOLEAN"
+	PLUGIN_VARIABLE_CONSTRAINT_JSON             PluginVariableConstraintType = "JSON"
+	PLUGIN_VARIABLE_CONSTRAINT_FILE_REFERENCE   PluginVariableConstraintType = "FILE_REFERENCE"
+	PLUGIN_VARIABLE_CONSTRAINT_SECRET_REFERENCE PluginVariableConstraintType = "SECRET_REFERENCE"
+)
+
 const (
 	CI                = 1
 	CD                = 2
@@ -56,16 +70,30 @@ const (
 )
 
 type PluginMetadata struct {
-	tableName   struct{}   `sql:"plugin_metadata" pg:",discard_unknown_columns"`
-	Id          int        `sql:"id,pk"`
-	Name        string     `sql:"name"`
-	Description string     `sql:"description"`
-	Type        PluginType `sql:"type"`
-	Icon        string     `sql:"icon"`
-	Deleted     bool       `sql:"deleted, notnull"`
+	tableName          struct{}   `sql:"plugin_metadata" pg:",discard_unknown_columns"`
+	Id                 int        `sql:"id,pk"`
+	Name               string     `sql:"name"`
+	Description        string     `sql:"description"`
+	Type               PluginType `sql:"type"`
+	Icon               string     `sql:"icon"`
+	Deleted            bool       `sql:"deleted, notnull"`
+	PluginParentId     int        `sql:"plugin_parent_id"` //id of the first version of the plugin, shared by all its versions
+	PluginVersion      string     `sql:"plugin_version"`
+	IsLatest           bool       `sql:"is_latest,notnull"`
+	Deprecated         bool       `sql:"deprecated,notnull"`
+	DeprecationMessage string     `sql:"deprecation_message"`
 	sql.AuditLog
 }
 
+// GetParentId returns the id shared by all the versions of the plugin, plugins added by migrations before versioning
+// may not have the parent id set
+func (m *PluginMetadata) GetParentId() int {
+	if m.PluginParentId == 0 {
+		return m.Id
+	}
+	return m.PluginParentId
+}
+
 type PluginTag struct {
 	tableName struct{} `sql:"plugin_tag" pg:",discard_unknown_columns"`
 	Id        int      `sql:"id,pk"`
@@ -149,11 +177,28 @@ type PluginStepVariable struct {
 	VariableStepIndex         int                          `sql:"variable_step_index,notnull"`
 	VariableStepIndexInPlugin int                          `sql:"variable_step_index_in_plugin,notnull"` // will contain stepIndex of variable in case of refPlugin
 	ReferenceVariableName     string                       `sql:"reference_variable_name"`
+	ValueConstraint           *PluginVariableConstraint    `sql:"value_constraint"`
 	Deleted                   bool                         `sql:"deleted,notnull"`
 	sql.AuditLog
 	PluginMetadataId int `sql:"-"`
 }
 
+// PluginVariableConstraint is the type of the values accepted by an input variable, the fields used depend on Type
+type PluginVariableConstraint struct {
+	Type PluginVariableConstraintType `json:"type"`
+	// Choices are the values allowed for ENUM
+	Choices []string `json:"choices,omitempty"`
+	// Pattern is the regular expression matched by REGEX values
+	Pattern string `json:"pattern,omitempty"`
+	// Min and Max are the inclusive bounds of NUMBER_RANGE values, a nil bound is open
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// JsonSchema is the schema JSON values are validated against, any JSON object is allowed when empty
+	JsonSchema map[string]interface{} `json:"jsonSchema,omitempty"`
+	// FileExtensions are the extensions allowed for FILE_REFERENCE paths, any file is allowed when empty
+	FileExtensions []string `json:"fileExtensions,omitempty"`
+}
+
 type PluginStepCondition struct {
 	tableName           struct{}                `sql:"plugin_step_condition" pg:",discard_unknown_columns"`
 	Id                  int                     `sql:"id,pk"`
@@ -179,6 +224,8 @@ type GlobalPluginRepository interface {
 	GetMetaDataForPluginWithStageType(stageType int) ([]*PluginMetadata, error)
 	GetMetaDataByPluginId(pluginId int) (*PluginMetadata, error)
 	GetAllPluginTags() ([]*PluginTag, error)
+	// GetAllPluginTagsWithTx returns the tags including the ones created in the transaction
+	GetAllPluginTagsWithTx(tx *pg.Tx) ([]*PluginTag, error)
 	GetAllPluginTagRelations() ([]*PluginTagRelation, error)
 	GetTagsByPluginId(pluginId int) ([]string, error)
 	GetScriptDetailById(id int) (*PluginPipelineScript, error)
@@ -190,10 +237,15 @@ type GlobalPluginRepository interface {
 	GetExposedVariablesForAllPlugins() ([]*PluginStepVariable, error)
 	GetConditionsByStepId(stepId int) ([]*PluginStepCondition, error)
 	GetPluginByName(pluginName string) ([]*PluginMetadata, error)
+	// GetPluginVersionIdsByName returns the ids of all the versions of the plugin named pluginName, versions are matched
+	// on their parent so that steps using any version of the plugin can be found
+	GetPluginVersionIdsByName(pluginName string) ([]int, error)
 	GetAllPluginMetaData() ([]*PluginMetadata, error)
 	GetPluginStepsByPluginId(pluginId int) ([]*PluginStep, error)
 	GetConditionsByPluginId(pluginId int) ([]*PluginStepCondition, error)
 	GetPluginStageMappingByPluginId(pluginId int) (*PluginStageMapping, error)
+	// GetPluginVersionsByParentId returns all the versions of the plugin, ordered by id
+	GetPluginVersionsByParentId(parentId int) ([]*PluginMetadata, error)
 	GetConnection() (dbConnection *pg.DB)
 
 	SavePluginMetadata(pluginMetadata *PluginMetadata, tx *pg.Tx) (*PluginMetadata, error)
@@ -208,6 +260,7 @@ type GlobalPluginRepository interface {
 	SavePluginTagRelationInBulk(pluginTagRelation []*PluginTagRelation, tx *pg.Tx) error
 
 	UpdatePluginMetadata(pluginMetadata *PluginMetadata, tx *pg.Tx) error
+	MarkPluginVersionsNotLatest(parentId int, userId int32, tx *pg.Tx) error
 	UpdatePluginStageMapping(pluginStageMapping *PluginStageMapping, tx *pg.Tx) error
 	UpdatePluginSteps(pluginStep *PluginStep, tx *pg.Tx) error
 	UpdatePluginPipelineScript(pluginPipelineScript *PluginPipelineScript, tx *pg.Tx) error
@@ -240,7 +293,8 @@ func (impl *GlobalPluginRepositoryImpl) GetConnection() (dbConnection *pg.DB) {
 func (impl *GlobalPluginRepositoryImpl) GetMetaDataForAllPlugins() ([]*PluginMetadata, error) {
 	var plugins []*PluginMetadata
 	err := impl.dbConnection.Model(&plugins).
-		Where("deleted = ?", false).Select()
+		Where("deleted = ?", false).
+		Where("is_latest = ?", true).Select()
 	if err != nil {
 		impl.logger.Errorw("err in getting all plugins", "err", err)
 		return nil, err
@@ -253,6 +307,7 @@ func (impl *GlobalPluginRepositoryImpl) GetMetaDataForPluginWithStageType(stageT
 	err := impl.dbConnection.Model(&plugins).
 		Join("INNER JOIN plugin_stage_mapping psm on psm.plugin_id=plugin_metadata.id").
 		Where("plugin_metadata.deleted = ?", false).
+		Where("plugin_metadata.is_latest = ?", true).
 		Where("psm.stage_type= 2 or psm.stage_type= ?", stageType).
 		Select()
 	if err != nil {
@@ -273,6 +328,17 @@ func (impl *GlobalPluginRepositoryImpl) GetAllPluginTags() ([]*PluginTag, error)
 	return tags, nil
 }
 
+func (impl *GlobalPluginRepositoryImpl) GetAllPluginTagsWithTx(tx *pg.Tx) ([]*PluginTag, error) {
+	var tags []*PluginTag
+	err := tx.Model(&tags).
+		Where("deleted = ?", false).Select()
+	if err != nil {
+		impl.logger.Errorw("err in getting all tags", "err", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
 func (impl *GlobalPluginRepositoryImpl) GetAllPluginTagRelations() ([]*PluginTagRelation, error) {
 	var rel []*PluginTagRelation
 	err := impl.dbConnection.Model(&rel).
@@ -424,6 +490,7 @@ func (impl *GlobalPluginRepositoryImpl) GetPluginByName(pluginName string) ([]*P
 	err := impl.dbConnection.Model(&plugin).
 		Where("name = ?", pluginName).
 		Where("deleted = ?", false).
+		Order("id").
 		Select()
 	if err != nil {
 		impl.logger.Errorw("err in getting pluginMetadata by pluginName", "err", err, "pluginName", pluginName)
@@ -433,6 +500,20 @@ func (impl *GlobalPluginRepositoryImpl) GetPluginByName(pluginName string) ([]*P
 
 }
 
+func (impl *GlobalPluginRepositoryImpl) GetPluginVersionIdsByName(pluginName string) ([]int, error) {
+	var ids []int
+	query := "SELECT DISTINCT pm.id FROM plugin_metadata pm" +
+		" INNER JOIN plugin_metadata named ON COALESCE(NULLIF(named.plugin_parent_id, 0), named.id) = COALESCE(NULLIF(pm.plugin_parent_id, 0), pm.id)" +
+		" WHERE named.name = ? AND named.deleted = false AND pm.deleted = false" +
+		" ORDER BY pm.id;"
+	_, err := impl.dbConnection.Query(&ids, query, pluginName)
+	if err != nil {
+		impl.logger.Errorw("err in getting plugin version ids by pluginName", "err", err, "pluginName", pluginName)
+		return nil, err
+	}
+	return ids, nil
+}
+
 func (impl *GlobalPluginRepositoryImpl) GetAllPluginMetaData() ([]*PluginMetadata, error) {
 	var plugins []*PluginMetadata
 	err := impl.dbConnection.Model(&plugins).Where("deleted = ?", false).Select()
@@ -443,6 +524,24 @@ func (impl *GlobalPluginRepositoryImpl) GetAllPluginMetaData() ([]*PluginMetadat
 	return plugins, nil
 }
 
+func (impl *GlobalPluginRepositoryImpl) GetPluginVersionsByParentId(parentId int) ([]*PluginMetadata, error) {
+	var plugins []*PluginMetadata
+	err := impl.dbConnection.Model(&plugins).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("plugin_parent_id = ?", parentId).
+				WhereOr("id = ?", parentId)
+			return q, nil
+		}).
+		Where("deleted = ?", false).
+		Order("id").
+		Select()
+	if err != nil {
+		impl.logger.Errorw("err in getting plugin versions by parentId", "err", err, "parentId", parentId)
+		return nil, err
+	}
+	return plugins, nil
+}
+
 func (impl *GlobalPluginRepositoryImpl) GetPluginStepsByPluginId(pluginId int) ([]*PluginStep, error) {
 	var pluginSteps []*PluginStep
 	err := impl.dbConnection.Model(&pluginSteps).
@@ -571,6 +670,21 @@ func (impl *GlobalPluginRepositoryImpl) UpdatePluginMetadata(pluginMetadata *Plu
 	return tx.Update(pluginMetadata)
 }
 
+func (impl *GlobalPluginRepositoryImpl) MarkPluginVersionsNotLatest(parentId int, userId int32, tx *pg.Tx) error {
+	_, err := tx.Model(&PluginMetadata{}).
+		Set("is_latest = ?", false).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", userId).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("plugin_parent_id = ?", parentId).
+				WhereOr("id = ?", parentId)
+			return q, nil
+		}).
+		Where("is_latest = ?", true).
+		Update()
+	return err
+}
+
 func (impl *GlobalPluginRepositoryImpl) UpdatePluginStageMapping(pluginStageMapping *PluginStageMapping, tx *pg.Tx) error {
 	return tx.Update(pluginStageMapping)
 }
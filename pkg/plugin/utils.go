@@ -2,6 +2,11 @@ package plugin
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+	repository2 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
 	"github.com/devtron-labs/devtron/pkg/plugin/repository"
 )
 
@@ -19,3 +24,172 @@ func getStageType(stageTypeReq string) (int, error) {
 	}
 	return stageType, nil
 }
+
+// ResolvePluginVersion returns the newest version of the plugin matching the semver constraint, deprecated versions
+// are resolved to only if no other version matches
+func ResolvePluginVersion(versions []*repository.PluginMetadata, constraint string) (*repository.PluginMetadata, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin version constraint %q: %s", constraint, err.Error())
+	}
+	var resolved *repository.PluginMetadata
+	var resolvedVersion *semver.Version
+	for _, version := range versions {
+		v, err := semver.NewVersion(version.PluginVersion)
+		if err != nil || !c.Check(v) {
+			continue
+		}
+		if resolved == nil || (resolved.Deprecated && !version.Deprecated) ||
+			(resolved.Deprecated == version.Deprecated && v.GreaterThan(resolvedVersion)) {
+			resolved, resolvedVersion = version, v
+		}
+	}
+	if resolved == nil {
+		return nil, fmt.Errorf("no version of the plugin matches the version constraint %q", constraint)
+	}
+	return resolved, nil
+}
+
+// sortPluginVersions sorts the versions of a plugin from the oldest to the newest, versions which are not semver are
+// ordered first by id
+func sortPluginVersions(versions []*repository.PluginMetadata) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, erri := semver.NewVersion(versions[i].PluginVersion)
+		vj, errj := semver.NewVersion(versions[j].PluginVersion)
+		if erri != nil || errj != nil {
+			return erri != nil && (errj == nil || versions[i].Id < versions[j].Id)
+		}
+		return vi.LessThan(vj)
+	})
+}
+
+// findBreakingChanges compares the exposed input variables of a new plugin version with the ones of the previous
+// version, removing or changing the format of a variable or adding a variable which needs a value breaks the pipelines
+// using the previous version
+func findBreakingChanges(previousInputs []*repository.PluginStepVariable, newInputs []*PluginVariableDto) []string {
+	newInputsByName := make(map[string]*PluginVariableDto, len(newInputs))
+	for _, input := range newInputs {
+		newInputsByName[input.Name] = input
+	}
+	previousInputNames := make(map[string]bool, len(previousInputs))
+	var changes []string
+	for _, previous := range previousInputs {
+		previousInputNames[previous.Name] = true
+		input, ok := newInputsByName[previous.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("input variable %s is removed", previous.Name))
+		} else if input.Format != previous.Format {
+			changes = append(changes, fmt.Sprintf("format of input variable %s is changed from %s to %s", previous.Name, previous.Format, input.Format))
+		} else if getConstraintType(input.ValueConstraint) != getConstraintType(previous.ValueConstraint) {
+			changes = append(changes, fmt.Sprintf("type of input variable %s is changed", previous.Name))
+		}
+	}
+	for _, input := range newInputs {
+		if !previousInputNames[input.Name] && !input.AllowEmptyValue && len(input.DefaultValue) == 0 {
+			changes = append(changes, fmt.Sprintf("input variable %s is added without a default value", input.Name))
+		}
+	}
+	return changes
+}
+
+func getConstraintType(constraint *repository.PluginVariableConstraint) repository.PluginVariableConstraintType {
+	if constraint == nil {
+		return ""
+	}
+	return constraint.Type
+}
+
+// getExposedInputVariables returns the input variables of the plugin steps which are set by the pipelines using it
+func getExposedInputVariables(pluginSteps []*PluginStepsDto) []*PluginVariableDto {
+	var inputs []*PluginVariableDto
+	for _, step := range pluginSteps {
+		for _, variable := range step.PluginStepVariable {
+			if variable.IsExposed && variable.VariableType == repository.PLUGIN_VARIABLE_TYPE_INPUT {
+				inputs = append(inputs, variable)
+			}
+		}
+	}
+	return inputs
+}
+
+// stepVariableMigration is the change of the input variables of a pipeline step moved to another plugin version
+type stepVariableMigration struct {
+	toUpdate      []*repository2.PipelineStageStepVariable
+	toCreate      []*repository2.PipelineStageStepVariable
+	toDelete      []*repository2.PipelineStageStepVariable
+	added         []string
+	removed       []string
+	renamed       map[string]string
+	missingValues []string
+}
+
+// migrateStepInputVariables maps the input variables of a pipeline step to the exposed input variables of the target
+// plugin version. Values are kept for variables found by name or by their renamed name, variables new in the target
+// get its default value and the ones not in the target are removed
+func migrateStepInputVariables(stepId int, current []*repository2.PipelineStageStepVariable, target []*repository.PluginStepVariable,
+	renames map[string]string) *stepVariableMigration {
+	migration := &stepVariableMigration{renamed: make(map[string]string)}
+	currentByName := make(map[string]*repository2.PipelineStageStepVariable, len(current))
+	currentNames := make([]string, 0, len(current))
+	for _, variable := range current {
+		currentByName[variable.Name] = variable
+		currentNames = append(currentNames, variable.Name)
+	}
+	// renamed variables are looked up by their name in the target version
+	renamedFrom := make(map[string]string, len(renames))
+	for from, to := range renames {
+		if _, ok := currentByName[from]; ok {
+			renamedFrom[to] = from
+		}
+	}
+	used := make(map[string]bool, len(current))
+	for _, variable := range target {
+		sourceName := variable.Name
+		if from, ok := renamedFrom[variable.Name]; ok {
+			sourceName = from
+		}
+		existing, ok := currentByName[sourceName]
+		if ok && !used[sourceName] {
+			used[sourceName] = true
+			if sourceName != variable.Name {
+				migration.renamed[sourceName] = variable.Name
+			}
+			existing.Name = variable.Name
+			existing.Format = repository2.PipelineStageStepVariableFormatType(variable.Format)
+			existing.Description = variable.Description
+			existing.AllowEmptyValue = variable.AllowEmptyValue
+			existing.DefaultValue = variable.DefaultValue
+			existing.VariableStepIndexInPlugin = variable.VariableStepIndex
+			migration.toUpdate = append(migration.toUpdate, existing)
+			if existing.ValueType == repository2.PIPELINE_STAGE_STEP_VARIABLE_VALUE_TYPE_NEW && len(existing.Value) == 0 &&
+				len(existing.DefaultValue) == 0 && !existing.AllowEmptyValue {
+				migration.missingValues = append(migration.missingValues, variable.Name)
+			}
+			continue
+		}
+		migration.toCreate = append(migration.toCreate, &repository2.PipelineStageStepVariable{
+			PipelineStageStepId:       stepId,
+			Name:                      variable.Name,
+			Format:                    repository2.PipelineStageStepVariableFormatType(variable.Format),
+			Description:               variable.Description,
+			IsExposed:                 true,
+			AllowEmptyValue:           variable.AllowEmptyValue,
+			DefaultValue:              variable.DefaultValue,
+			Value:                     variable.DefaultValue,
+			VariableType:              repository2.PIPELINE_STAGE_STEP_VARIABLE_TYPE_INPUT,
+			ValueType:                 repository2.PIPELINE_STAGE_STEP_VARIABLE_VALUE_TYPE_NEW,
+			VariableStepIndexInPlugin: variable.VariableStepIndex,
+		})
+		migration.added = append(migration.added, variable.Name)
+		if len(variable.DefaultValue) == 0 && !variable.AllowEmptyValue {
+			migration.missingValues = append(migration.missingValues, variable.Name)
+		}
+	}
+	for i, name := range currentNames {
+		if !used[name] {
+			migration.toDelete = append(migration.toDelete, current[i])
+			migration.removed = append(migration.removed, name)
+		}
+	}
+	return migration
+}
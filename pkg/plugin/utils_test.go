@@ -0,0 +1,93 @@
+package plugin
+
+import (
+	"testing"
+
+	repository2 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	"github.com/devtron-labs/devtron/pkg/plugin/repository"
+)
+
+func TestResolvePluginVersion(t *testing.T) {
+	versions := []*repository.PluginMetadata{
+		{Id: 1, PluginVersion: "1.0.0"},
+		{Id: 2, PluginVersion: "1.2.0"},
+		{Id: 3, PluginVersion: "1.3.0", Deprecated: true},
+		{Id: 4, PluginVersion: "2.0.0"},
+		{Id: 5, PluginVersion: "not-semver"},
+	}
+	tests := []struct {
+		constraint string
+		wantId     int
+	}{
+		{"^1.0.0", 2},
+		{"~1.0", 1},
+		{"1.3.0", 3},
+		{">=1.0.0", 4},
+	}
+	for _, tt := range tests {
+		resolved, err := ResolvePluginVersion(versions, tt.constraint)
+		if err != nil || resolved.Id != tt.wantId {
+			t.Errorf("ResolvePluginVersion(%q) = %+v, err %v, want id %d", tt.constraint, resolved, err, tt.wantId)
+		}
+	}
+	if _, err := ResolvePluginVersion(versions, "^3.0.0"); err == nil {
+		t.Errorf("ResolvePluginVersion() without a matching version did not fail")
+	}
+	if _, err := ResolvePluginVersion(versions, "not a constraint"); err == nil {
+		t.Errorf("ResolvePluginVersion() of invalid constraint did not fail")
+	}
+}
+
+func TestFindBreakingChanges(t *testing.T) {
+	previous := []*repository.PluginStepVariable{
+		{Name: "IMAGE", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING},
+		{Name: "TIMEOUT", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING},
+		{Name: "DEBUG", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING},
+	}
+	compatible := []*PluginVariableDto{
+		{Name: "IMAGE", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING},
+		{Name: "TIMEOUT", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING},
+		{Name: "DEBUG", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING},
+		{Name: "RETRIES", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_NUMBER, DefaultValue: "3"},
+		{Name: "LABELS", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING, AllowEmptyValue: true},
+	}
+	if changes := findBreakingChanges(previous, compatible); len(changes) != 0 {
+		t.Errorf("findBreakingChanges() of compatible inputs = %v", changes)
+	}
+	breaking := []*PluginVariableDto{
+		{Name: "IMAGE", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING},
+		{Name: "TIMEOUT", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_NUMBER},
+		{Name: "TOKEN", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING},
+	}
+	if changes := findBreakingChanges(previous, breaking); len(changes) != 3 {
+		t.Errorf("findBreakingChanges() = %v, want the changed format, the removed and the required input", changes)
+	}
+}
+
+func TestMigrateStepInputVariables(t *testing.T) {
+	current := []*repository2.PipelineStageStepVariable{
+		{Id: 1, Name: "IMAGE", Value: "nginx", ValueType: repository2.PIPELINE_STAGE_STEP_VARIABLE_VALUE_TYPE_NEW},
+		{Id: 2, Name: "TIME_OUT", Value: "30", ValueType: repository2.PIPELINE_STAGE_STEP_VARIABLE_VALUE_TYPE_NEW},
+		{Id: 3, Name: "DEBUG", Value: "true", ValueType: repository2.PIPELINE_STAGE_STEP_VARIABLE_VALUE_TYPE_NEW},
+	}
+	target := []*repository.PluginStepVariable{
+		{Name: "IMAGE", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING, VariableStepIndex: 1},
+		{Name: "TIMEOUT", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_NUMBER, VariableStepIndex: 2},
+		{Name: "RETRIES", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_NUMBER, DefaultValue: "3", VariableStepIndex: 3},
+		{Name: "TOKEN", Format: repository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING, VariableStepIndex: 4},
+	}
+	migration := migrateStepInputVariables(7, current, target, map[string]string{"TIME_OUT": "TIMEOUT"})
+	if len(migration.toUpdate) != 2 || migration.toUpdate[1].Id != 2 || migration.toUpdate[1].Name != "TIMEOUT" ||
+		migration.toUpdate[1].Value != "30" || migration.toUpdate[1].VariableStepIndexInPlugin != 2 {
+		t.Errorf("migrateStepInputVariables() updated = %+v", migration.toUpdate)
+	}
+	if len(migration.toCreate) != 2 || migration.toCreate[0].Value != "3" || migration.toCreate[0].PipelineStageStepId != 7 {
+		t.Errorf("migrateStepInputVariables() created = %+v", migration.toCreate)
+	}
+	if len(migration.toDelete) != 1 || migration.toDelete[0].Id != 3 || migration.removed[0] != "DEBUG" {
+		t.Errorf("migrateStepInputVariables() deleted = %+v", migration.toDelete)
+	}
+	if migration.renamed["TIME_OUT"] != "TIMEOUT" || len(migration.missingValues) != 1 || migration.missingValues[0] != "TOKEN" {
+		t.Errorf("migrateStepInputVariables() renamed = %v, missing values = %v", migration.renamed, migration.missingValues)
+	}
+}
@@ -0,0 +1,598 @@
+package pluginTest
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	pipelineStageRepository "github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	"github.com/devtron-labs/devtron/pkg/pipeline/types"
+	"github.com/devtron-labs/devtron/pkg/plugin"
+	pluginRepository "github.com/devtron-labs/devtron/pkg/plugin/repository"
+	"github.com/devtron-labs/devtron/pkg/pluginTest/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type PluginTestConfig struct {
+	// Enabled turns on the plugin test runs, which need a ci runner version supporting dry runs
+	Enabled bool `env:"PLUGIN_TEST_ENABLED" envDefault:"false"`
+	// Namespace is the namespace the runs are submitted in, apart from the ci namespace so that the steps get none of
+	// its secrets or service accounts. It has to exist and be watched for workflow status updates.
+	Namespace string `env:"PLUGIN_TEST_NAMESPACE" envDefault:"devtron-plugin-test"`
+	// ResultHost is the base url of the webhooks the runner posts the results of the steps to, the webhook url of
+	// ORCH_HOST is used if empty
+	ResultHost     string `env:"PLUGIN_TEST_RESULT_HOST" envDefault:""`
+	TimeoutSeconds int64  `env:"PLUGIN_TEST_TIMEOUT_SECONDS" envDefault:"900"`
+	// CheckoutPath is the directory of the runner the synthetic git checkout is created in
+	CheckoutPath      string `env:"PLUGIN_TEST_CHECKOUT_PATH" envDefault:"/devtroncd"`
+	MaxCheckoutFiles  int    `env:"PLUGIN_TEST_MAX_CHECKOUT_FILES" envDefault:"50"`
+	MaxCheckoutSizeKb int    `env:"PLUGIN_TEST_MAX_CHECKOUT_SIZE_KB" envDefault:"256"`
+}
+
+type PluginTestService interface {
+	// TestPlugin runs the plugin with the input values in a sandbox workflow, no ci workflow or artifact is created
+	TestPlugin(request *PluginTestRequest) (*PluginTestRunDto, error)
+	// TestStage runs the steps of the pre/post stage of the pipeline in a sandbox workflow, with the input values of
+	// the steps overridden by the ones of the request
+	TestStage(request *StageTestRequest) (*PluginTestRunDto, error)
+	GetRun(id int) (*PluginTestRunDto, error)
+	// SaveResult saves the step results posted by the runner of the run, token is the bearer token of the request
+	SaveResult(id int, token string, result *PluginTestResultRequest) error
+	// UpdateWorkflowStatus updates the run of the workflow, it returns false if the workflow is not the one of a run
+	UpdateWorkflowStatus(workflowStatus v1alpha1.WorkflowStatus) (bool, error)
+}
+
+type PluginTestServiceImpl struct {
+	logger                  *zap.SugaredLogger
+	pluginTestRunRepository repository.PluginTestRunRepository
+	globalPluginRepository  pluginRepository.GlobalPluginRepository
+	ciPipelineRepository    pipelineConfig.CiPipelineRepository
+	pipelineRepository      pipelineConfig.PipelineRepository
+	pipelineStageService    pipeline.PipelineStageService
+	workflowService         pipeline.WorkflowService
+	config                  *PluginTestConfig
+	ciConfig                *types.CiConfig
+}
+
+func NewPluginTestServiceImpl(logger *zap.SugaredLogger,
+	pluginTestRunRepository repository.PluginTestRunRepository,
+	globalPluginRepository pluginRepository.GlobalPluginRepository,
+	ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	pipelineStageService pipeline.PipelineStageService,
+	workflowService pipeline.WorkflowService) *PluginTestServiceImpl {
+	cfg := &PluginTestConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Infow("error occurred while parsing PluginTestConfig, so setting plugin test config to default values", "err", err)
+		cfg = &PluginTestConfig{Namespace: "devtron-plugin-test", TimeoutSeconds: 900, CheckoutPath: "/devtroncd", MaxCheckoutFiles: 50, MaxCheckoutSizeKb: 256}
+	}
+	ciConfig, err := types.GetCiConfig()
+	if err != nil {
+		logger.Errorw("error in parsing ci config", "err", err)
+		return nil
+	}
+	return &PluginTestServiceImpl{
+		logger:                  logger,
+		pluginTestRunRepository: pluginTestRunRepository,
+		globalPluginRepository:  globalPluginRepository,
+		ciPipelineRepository:    ciPipelineRepository,
+		pipelineRepository:      pipelineRepository,
+		pipelineStageService:    pipelineStageService,
+		workflowService:         workflowService,
+		config:                  cfg,
+		ciConfig:                ciConfig,
+	}
+}
+
+func (impl *PluginTestServiceImpl) TestPlugin(request *PluginTestRequest) (*PluginTestRunDto, error) {
+	if !impl.config.Enabled {
+		return nil, disabled()
+	}
+	pluginMetadata, err := impl.resolvePlugin(request.PluginId, request.PluginVersion)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := impl.globalPluginRepository.GetExposedVariablesByPluginIdAndVariableType(pluginMetadata.Id, pluginRepository.PLUGIN_VARIABLE_TYPE_INPUT)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting plugin input variables", "err", err, "pluginId", pluginMetadata.Id)
+		return nil, err
+	}
+	outputs, err := impl.globalPluginRepository.GetExposedVariablesByPluginIdAndVariableType(pluginMetadata.Id, pluginRepository.PLUGIN_VARIABLE_TYPE_OUTPUT)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting plugin output variables", "err", err, "pluginId", pluginMetadata.Id)
+		return nil, err
+	}
+	step, err := getPluginStep(pluginMetadata, inputs, outputs, request.InputVariables)
+	if err != nil {
+		return nil, err
+	}
+	refPlugins, err := impl.pipelineStageService.BuildRefPluginStepDataForWfRequest([]int{pluginMetadata.Id})
+	if err != nil {
+		impl.logger.Errorw("error in building plugin steps", "err", err, "pluginId", pluginMetadata.Id)
+		return nil, err
+	}
+	run := &repository.PluginTestRun{RunType: RUN_TYPE_PLUGIN, PluginId: pluginMetadata.Id}
+	return impl.submitRun(run, []*bean.StepObject{step}, nil, refPlugins, request.Checkout, request.UserId)
+}
+
+func (impl *PluginTestServiceImpl) TestStage(request *StageTestRequest) (*PluginTestRunDto, error) {
+	if !impl.config.Enabled {
+		return nil, disabled()
+	}
+	err := impl.checkPipelineExists(request.PipelineId, request.StageType)
+	if err != nil {
+		return nil, err
+	}
+	// scoped variables are not resolved, they may hold the secrets of the pipeline, values using them are to be
+	// overridden by the request
+	steps, refPlugins, err := impl.pipelineStageService.BuildStageStepsDataForWfRequest(request.PipelineId, request.StageType)
+	if err != nil {
+		impl.logger.Errorw("error in building stage steps", "err", err, "pipelineId", request.PipelineId, "stageType", request.StageType)
+		return nil, err
+	}
+	if len(steps) == 0 {
+		return nil, badRequest(fmt.Sprintf("%s stage of pipeline %d has no steps", request.StageType, request.PipelineId))
+	}
+	err = applyInputOverrides(steps, request.InputVariables)
+	if err != nil {
+		return nil, badRequest(err.Error())
+	}
+	err = impl.validateOverriddenPluginInputs(steps, request.InputVariables)
+	if err != nil {
+		return nil, err
+	}
+	run := &repository.PluginTestRun{RunType: RUN_TYPE_STAGE, PipelineId: request.PipelineId, StageType: string(request.StageType)}
+	if request.StageType == pipelineStageRepository.PIPELINE_STAGE_TYPE_PRE_CI || request.StageType == pipelineStageRepository.PIPELINE_STAGE_TYPE_PRE_CD {
+		return impl.submitRun(run, steps, nil, refPlugins, request.Checkout, request.UserId)
+	}
+	return impl.submitRun(run, nil, steps, refPlugins, request.Checkout, request.UserId)
+}
+
+func (impl *PluginTestServiceImpl) GetRun(id int) (*PluginTestRunDto, error) {
+	run, err := impl.pluginTestRunRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return nil, notFound(fmt.Sprintf("plugin test run %d not found", id))
+	} else if err != nil {
+		impl.logger.Errorw("error in getting plugin test run", "err", err, "id", id)
+		return nil, err
+	}
+	return getRunDto(run), nil
+}
+
+func (impl *PluginTestServiceImpl) SaveResult(id int, token string, result *PluginTestResultRequest) error {
+	run, err := impl.pluginTestRunRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return notFound(fmt.Sprintf("plugin test run %d not found", id))
+	} else if err != nil {
+		impl.logger.Errorw("error in getting plugin test run", "err", err, "id", id)
+		return err
+	}
+	if len(token) == 0 || subtle.ConstantTimeCompare([]byte(run.ResultToken), []byte(token)) != 1 {
+		return &util.ApiError{HttpStatusCode: http.StatusUnauthorized, InternalMessage: "invalid result token", UserMessage: "Unauthorized req"}
+	}
+	run.StepResults = result.Steps
+	run.UpdatedOn = time.Now()
+	err = impl.pluginTestRunRepository.UpdateStepResults(run)
+	if err != nil {
+		impl.logger.Errorw("error in saving plugin test step results", "err", err, "id", id)
+		return err
+	}
+	return nil
+}
+
+func (impl *PluginTestServiceImpl) UpdateWorkflowStatus(workflowStatus v1alpha1.WorkflowStatus) (bool, error) {
+	workflowName, message := "", workflowStatus.Message
+	for name, node := range workflowStatus.Nodes {
+		if node.TemplateName == bean.CI_WORKFLOW_NAME {
+			workflowName = name
+			if node.BoundaryID != "" {
+				workflowName = node.BoundaryID
+			}
+			if len(node.Message) > 0 {
+				message = node.Message
+			}
+			break
+		}
+	}
+	runId, ok := getRunId(workflowName)
+	if !ok {
+		return false, nil
+	}
+	run, err := impl.pluginTestRunRepository.FindById(runId)
+	if err == pg.ErrNoRows {
+		impl.logger.Warnw("plugin test run of workflow not found", "workflowName", workflowName)
+		return true, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting plugin test run", "err", err, "id", runId)
+		return true, err
+	}
+	if isFinished(run.Status) || len(workflowStatus.Phase) == 0 {
+		return true, nil
+	}
+	run.WorkflowName = workflowName
+	run.Status = string(workflowStatus.Phase)
+	run.Message = message
+	if isFinished(run.Status) {
+		run.FinishedOn = workflowStatus.FinishedAt.Time
+		if run.FinishedOn.IsZero() {
+			run.FinishedOn = time.Now()
+		}
+	}
+	run.UpdatedOn = time.Now()
+	err = impl.pluginTestRunRepository.UpdateStatus(run)
+	if err != nil {
+		impl.logger.Errorw("error in updating plugin test run status", "err", err, "id", runId)
+		return true, err
+	}
+	return true, nil
+}
+
+// resolvePlugin returns the version of the plugin matching the version constraint, the plugin itself if there is no
+// constraint
+func (impl *PluginTestServiceImpl) resolvePlugin(pluginId int, versionConstraint string) (*pluginRepository.PluginMetadata, error) {
+	pluginMetadata, err := impl.globalPluginRepository.GetMetaDataByPluginId(pluginId)
+	if err == pg.ErrNoRows {
+		return nil, notFound(fmt.Sprintf("plugin %d not found", pluginId))
+	} else if err != nil {
+		return nil, err
+	}
+	if len(versionConstraint) == 0 {
+		return pluginMetadata, nil
+	}
+	versions, err := impl.globalPluginRepository.GetPluginVersionsByParentId(pluginMetadata.GetParentId())
+	if err != nil {
+		impl.logger.Errorw("error in getting plugin versions", "err", err, "pluginId", pluginId)
+		return nil, err
+	}
+	resolved, err := plugin.ResolvePluginVersion(versions, versionConstraint)
+	if err != nil {
+		return nil, badRequest(err.Error())
+	}
+	return resolved, nil
+}
+
+// checkPipelineExists returns a not found error if the ci or cd pipeline of the stage does not exist
+func (impl *PluginTestServiceImpl) checkPipelineExists(pipelineId int, stageType pipelineStageRepository.PipelineStageType) error {
+	switch stageType {
+	case pipelineStageRepository.PIPELINE_STAGE_TYPE_PRE_CI, pipelineStageRepository.PIPELINE_STAGE_TYPE_POST_CI:
+		_, err := impl.ciPipelineRepository.FindById(pipelineId)
+		if err == pg.ErrNoRows {
+			return notFound(fmt.Sprintf("ci pipeline %d not found", pipelineId))
+		} else if err != nil {
+			impl.logger.Errorw("error in fetching ci pipeline", "err", err, "ciPipelineId", pipelineId)
+			return err
+		}
+		return nil
+	case pipelineStageRepository.PIPELINE_STAGE_TYPE_PRE_CD, pipelineStageRepository.PIPELINE_STAGE_TYPE_POST_CD:
+		_, err := impl.pipelineRepository.FindById(pipelineId)
+		if err == pg.ErrNoRows {
+			return notFound(fmt.Sprintf("cd pipeline %d not found", pipelineId))
+		} else if err != nil {
+			impl.logger.Errorw("error in fetching cd pipeline", "err", err, "cdPipelineId", pipelineId)
+			return err
+		}
+		return nil
+	default:
+		return badRequest(fmt.Sprintf("invalid stage type %s", stageType))
+	}
+}
+
+// validateOverriddenPluginInputs checks the overridden input values of the steps using plugins against the types of
+// the inputs declared by the plugins, the other values are checked when the steps are built
+func (impl *PluginTestServiceImpl) validateOverriddenPluginInputs(steps []*bean.StepObject, overrides map[string]map[string]string) error {
+	var inputErrors []*plugin.PluginInputError
+	for _, step := range steps {
+		values, ok := overrides[step.Name]
+		if !ok || step.StepType != string(pipelineStageRepository.PIPELINE_STEP_TYPE_REF_PLUGIN) {
+			continue
+		}
+		inputs, err := impl.globalPluginRepository.GetExposedVariablesByPluginIdAndVariableType(step.RefPluginId, pluginRepository.PLUGIN_VARIABLE_TYPE_INPUT)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting plugin input variables", "err", err, "pluginId", step.RefPluginId)
+			return err
+		}
+		for _, input := range inputs {
+			value, ok := values[input.Name]
+			if !ok {
+				continue
+			}
+			if err = plugin.ValidateResolvedPluginInputValue(input, value); err != nil {
+				inputErrors = append(inputErrors, &plugin.PluginInputError{StepName: step.Name, VariableName: input.Name, Message: err.Error()})
+			}
+		}
+	}
+	return getInputValidationError(inputErrors)
+}
+
+// submitRun saves the run and submits its workflow in the namespace of the runs, the synthetic checkout, if any, is
+// created by a step run before the steps of the stage
+func (impl *PluginTestServiceImpl) submitRun(run *repository.PluginTestRun, preSteps []*bean.StepObject, postSteps []*bean.StepObject,
+	refPlugins []*bean.RefPluginObject, checkout *SyntheticCheckout, userId int32) (*PluginTestRunDto, error) {
+	checkoutStep, err := getCheckoutStep(checkout, impl.config)
+	if err != nil {
+		return nil, badRequest(err.Error())
+	}
+	if checkoutStep != nil {
+		if len(preSteps) > 0 {
+			preSteps = withCheckoutStep(checkoutStep, preSteps)
+		} else {
+			postSteps = withCheckoutStep(checkoutStep, postSteps)
+		}
+	}
+	resultToken, err := generateResultToken()
+	if err != nil {
+		impl.logger.Errorw("error in generating plugin test result token", "err", err)
+		return nil, err
+	}
+	run.Status = RUN_STATUS_STARTING
+	run.ResultToken = resultToken
+	run.Namespace = impl.config.Namespace
+	run.StartedOn = time.Now()
+	run.AuditLog = sql.NewDefaultAuditLog(userId)
+	err = impl.pluginTestRunRepository.Save(run)
+	if err != nil {
+		impl.logger.Errorw("error in saving plugin test run", "err", err, "run", run)
+		return nil, err
+	}
+	workflowNamePrefix := WORKFLOW_NAME_PREFIX + strconv.Itoa(run.Id)
+	// the steps run untrusted input values, the orchestrator token, config maps and secrets are not given to the sandbox
+	workflowRequest := &types.WorkflowRequest{
+		WorkflowNamePrefix:         workflowNamePrefix,
+		PipelineName:               workflowNamePrefix,
+		WorkflowId:                 getWorkflowId(run.Id),
+		Namespace:                  run.Namespace,
+		CiImage:                    impl.ciConfig.GetDefaultImage(),
+		ActiveDeadlineSeconds:      impl.config.TimeoutSeconds,
+		TriggeredBy:                userId,
+		CloudProvider:              impl.ciConfig.CloudProvider,
+		DefaultAddressPoolBaseCidr: impl.ciConfig.GetDefaultAddressPoolBaseCidr(),
+		DefaultAddressPoolSize:     impl.ciConfig.GetDefaultAddressPoolSize(),
+		PreCiSteps:                 preSteps,
+		PostCiSteps:                postSteps,
+		RefPlugins:                 refPlugins,
+		CiBuildConfig:              &bean.CiBuildConfigBean{CiBuildType: bean.SKIP_BUILD_TYPE},
+		IgnoreDockerCachePush:      true,
+		IgnoreDockerCachePull:      true,
+		OrchestratorHost:           impl.ciConfig.OrchestratorHost,
+		WorkflowExecutor:           impl.ciConfig.GetWorkflowExecutorType(),
+		Type:                       bean.JOB_WORKFLOW_PIPELINE_TYPE,
+		IsDryRun:                   true,
+		DryRunResultUrl:            getResultUrl(impl.config.ResultHost, impl.ciConfig.OrchestratorHost, run.Id),
+		DryRunResultToken:          resultToken,
+	}
+	createdWorkflows, err := impl.workflowService.SubmitWorkflow(workflowRequest)
+	if err != nil {
+		impl.logger.Errorw("error in submitting plugin test workflow", "err", err, "id", run.Id)
+		run.Status = RUN_STATUS_FAILED
+		run.Message = err.Error()
+		run.FinishedOn = time.Now()
+		run.UpdatedOn = time.Now()
+		if updateErr := impl.pluginTestRunRepository.UpdateStatus(run); updateErr != nil {
+			impl.logger.Errorw("error in updating plugin test run status", "err", updateErr, "id", run.Id)
+		}
+		return nil, err
+	}
+	if createdWorkflows != nil && len(createdWorkflows.Items) > 0 {
+		run.WorkflowName = createdWorkflows.Items[0].GetName()
+	}
+	run.Status = RUN_STATUS_RUNNING
+	run.UpdatedOn = time.Now()
+	err = impl.pluginTestRunRepository.UpdateStatus(run)
+	if err != nil {
+		impl.logger.Errorw("error in updating plugin test run status", "err", err, "id", run.Id)
+		return nil, err
+	}
+	return getRunDto(run), nil
+}
+
+// getPluginStep returns the step running the plugin with the input values, the default values of the inputs are
+// used for the values not given
+func getPluginStep(pluginMetadata *pluginRepository.PluginMetadata, inputs []*pluginRepository.PluginStepVariable,
+	outputs []*pluginRepository.PluginStepVariable, values map[string]string) (*bean.StepObject, error) {
+	inputNames := make(map[string]bool, len(inputs))
+	for _, input := range inputs {
+		inputNames[input.Name] = true
+	}
+	var unknownInputs []string
+	for name := range values {
+		if !inputNames[name] {
+			unknownInputs = append(unknownInputs, name)
+		}
+	}
+	if len(unknownInputs) > 0 {
+		sort.Strings(unknownInputs)
+		return nil, badRequest(fmt.Sprintf("unknown input variables of plugin %s: %s", pluginMetadata.Name, strings.Join(unknownInputs, ", ")))
+	}
+	step := &bean.StepObject{
+		Name:         pluginMetadata.Name,
+		Index:        1,
+		StepType:     string(pipelineStageRepository.PIPELINE_STEP_TYPE_REF_PLUGIN),
+		ExecutorType: "PLUGIN",
+		RefPluginId:  pluginMetadata.Id,
+	}
+	var inputErrors []*plugin.PluginInputError
+	for _, input := range inputs {
+		value := values[input.Name]
+		if err := plugin.ValidateResolvedPluginInputValue(input, value); err != nil {
+			inputErrors = append(inputErrors, &plugin.PluginInputError{StepName: step.Name, VariableName: input.Name, Message: err.Error()})
+		}
+		if len(value) == 0 {
+			value = input.DefaultValue
+		}
+		step.InputVars = append(step.InputVars, &bean.VariableObject{
+			Name:                      input.Name,
+			Format:                    string(input.Format),
+			Value:                     value,
+			VariableType:              bean.VARIABLE_TYPE_VALUE,
+			VariableStepIndexInPlugin: input.VariableStepIndexInPlugin,
+		})
+	}
+	if err := getInputValidationError(inputErrors); err != nil {
+		return nil, err
+	}
+	for _, output := range outputs {
+		step.OutputVars = append(step.OutputVars, &bean.VariableObject{
+			Name:                      output.Name,
+			Format:                    string(output.Format),
+			VariableStepIndexInPlugin: output.VariableStepIndexInPlugin,
+		})
+	}
+	return step, nil
+}
+
+// applyInputOverrides sets the values of the input variables of the steps, keyed by step name and variable name,
+// overridden inputs take the value as is in place of referring other variables
+func applyInputOverrides(steps []*bean.StepObject, overrides map[string]map[string]string) error {
+	stepsByName := make(map[string]*bean.StepObject, len(steps))
+	for _, step := range steps {
+		stepsByName[step.Name] = step
+	}
+	stepNames := make([]string, 0, len(overrides))
+	for stepName := range overrides {
+		stepNames = append(stepNames, stepName)
+	}
+	sort.Strings(stepNames)
+	for _, stepName := range stepNames {
+		step, ok := stepsByName[stepName]
+		if !ok {
+			return fmt.Errorf("unknown step %s", stepName)
+		}
+		inputs := make(map[string]*bean.VariableObject, len(step.InputVars))
+		for _, input := range step.InputVars {
+			inputs[input.Name] = input
+		}
+		values := overrides[stepName]
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			input, ok := inputs[name]
+			if !ok {
+				return fmt.Errorf("unknown input variable %s of step %s", name, stepName)
+			}
+			input.Value = values[name]
+			input.VariableType = bean.VARIABLE_TYPE_VALUE
+			input.ReferenceVariableName = ""
+			input.ReferenceVariableStepIndex = 0
+		}
+	}
+	return nil
+}
+
+// withCheckoutStep puts the checkout step first, the steps depending on no other step are made to depend on it
+func withCheckoutStep(checkoutStep *bean.StepObject, steps []*bean.StepObject) []*bean.StepObject {
+	for _, step := range steps {
+		if len(step.DependsOn) == 0 {
+			step.DependsOn = []int{checkoutStep.Index}
+		}
+	}
+	return append([]*bean.StepObject{checkoutStep}, steps...)
+}
+
+// getWorkflowId returns the workflow id the run is submitted with. Runs use the negative ids so that the events the
+// runner sends for the workflow, keyed by workflow id, never match a ci_workflow.
+func getWorkflowId(runId int) int {
+	return -runId
+}
+
+// getRunId returns the id of the run from the name of its workflow
+func getRunId(workflowName string) (int, bool) {
+	if !strings.HasPrefix(workflowName, WORKFLOW_NAME_PREFIX) {
+		return 0, false
+	}
+	runId := strings.TrimPrefix(workflowName, WORKFLOW_NAME_PREFIX)
+	if i := strings.Index(runId, "-"); i >= 0 {
+		runId = runId[:i]
+	}
+	id, err := strconv.Atoi(runId)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// getResultUrl returns the url the runner posts the step results of the run to, the webhook url of the orchestrator
+// host is used if no result host is configured
+func getResultUrl(resultHost string, orchestratorHost string, runId int) string {
+	if len(resultHost) == 0 {
+		resultHost = strings.TrimSuffix(orchestratorHost, "/msg/nats")
+	}
+	return fmt.Sprintf("%s/plugin-test/%d/result", strings.TrimSuffix(resultHost, "/"), runId)
+}
+
+func isFinished(status string) bool {
+	return status == RUN_STATUS_SUCCEEDED || status == RUN_STATUS_FAILED || status == RUN_STATUS_ERROR
+}
+
+func generateResultToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+func getRunDto(run *repository.PluginTestRun) *PluginTestRunDto {
+	runDto := &PluginTestRunDto{
+		Id:           run.Id,
+		RunType:      run.RunType,
+		PluginId:     run.PluginId,
+		PipelineId:   run.PipelineId,
+		StageType:    run.StageType,
+		WorkflowName: run.WorkflowName,
+		Namespace:    run.Namespace,
+		Status:       run.Status,
+		Message:      run.Message,
+		Steps:        run.StepResults,
+		StartedOn:    run.StartedOn,
+	}
+	if !run.FinishedOn.IsZero() {
+		finishedOn := run.FinishedOn
+		runDto.FinishedOn = &finishedOn
+	}
+	if runDto.Steps == nil {
+		runDto.Steps = []*repository.StepResult{}
+	}
+	return runDto
+}
+
+func getInputValidationError(inputErrors []*plugin.PluginInputError) error {
+	if len(inputErrors) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(inputErrors))
+	for _, inputError := range inputErrors {
+		messages = append(messages, inputError.Error())
+	}
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: strings.Join(messages, ", "), UserMessage: inputErrors}
+}
+
+func badRequest(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: message, UserMessage: message}
+}
+
+func notFound(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: message, UserMessage: message}
+}
+
+func disabled() error {
+	message := "plugin test runs are disabled, they need a ci runner supporting dry runs"
+	return &util.ApiError{HttpStatusCode: http.StatusNotImplemented, InternalMessage: message, UserMessage: message}
+}
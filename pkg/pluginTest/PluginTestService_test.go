@@ -0,0 +1,158 @@
+package pluginTest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	pluginRepository "github.com/devtron-labs/devtron/pkg/plugin/repository"
+)
+
+func TestGetCheckoutStep(t *testing.T) {
+	config := &PluginTestConfig{CheckoutPath: "/devtroncd", MaxCheckoutFiles: 2, MaxCheckoutSizeKb: 1}
+	step, err := getCheckoutStep(&SyntheticCheckout{Files: map[string]string{"src/app's.yaml": "a: b\n", "README.md": "x"}}, config)
+	if err != nil {
+		t.Fatalf("getCheckoutStep() err = %v", err)
+	}
+	if step.Name != CHECKOUT_STEP_NAME || step.Index != CHECKOUT_STEP_INDEX || step.StepType != "INLINE" || step.ExecutorType != "SHELL" {
+		t.Errorf("getCheckoutStep() step = %+v", step)
+	}
+	for _, line := range []string{
+		"mkdir -p '/devtroncd' && cd '/devtroncd'\n",
+		"printf '%s' 'eA==' | base64 -d > 'README.md'\n",
+		"mkdir -p 'src'\nprintf '%s' 'YTogYgo=' | base64 -d > 'src/app'\\''s.yaml'\n",
+		"git checkout -q -b 'main'\n",
+		"commit -q --allow-empty -m 'plugin test checkout'\n",
+	} {
+		if !strings.Contains(step.Script, line) {
+			t.Errorf("getCheckoutStep() script does not contain %q:\n%s", line, step.Script)
+		}
+	}
+	if strings.Index(step.Script, "README.md") > strings.Index(step.Script, "src/") {
+		t.Errorf("getCheckoutStep() files are not sorted:\n%s", step.Script)
+	}
+	if step, err = getCheckoutStep(nil, config); step != nil || err != nil {
+		t.Errorf("getCheckoutStep() of no checkout = %+v, err %v", step, err)
+	}
+	invalid := map[string]*SyntheticCheckout{
+		"absolute path": {Files: map[string]string{"/etc/passwd": ""}},
+		"parent path":   {Files: map[string]string{"a/../../b": ""}},
+		"git directory": {Files: map[string]string{".git/config": ""}},
+		"empty path":    {Files: map[string]string{"": ""}},
+		"branch":        {Branch: "-x"},
+		"branch dots":   {Branch: "a..b"},
+		"file count":    {Files: map[string]string{"a": "", "b": "", "c": ""}},
+		"size":          {Files: map[string]string{"a": strings.Repeat("x", 1025)}},
+	}
+	for name, checkout := range invalid {
+		if _, err = getCheckoutStep(checkout, config); err == nil {
+			t.Errorf("getCheckoutStep() of invalid %s did not fail", name)
+		}
+	}
+}
+
+func TestApplyInputOverrides(t *testing.T) {
+	steps := []*bean.StepObject{
+		{Name: "build", Index: 1, InputVars: []*bean.VariableObject{{Name: "TAG", Value: "v1", VariableType: bean.VARIABLE_TYPE_VALUE}}},
+		{Name: "scan", Index: 2, DependsOn: []int{1}, InputVars: []*bean.VariableObject{
+			{Name: "IMAGE", VariableType: bean.VARIABLE_TYPE_REF_PRE_CI, ReferenceVariableName: "IMAGE", ReferenceVariableStepIndex: 1},
+			{Name: "LEVEL", Value: "low", VariableType: bean.VARIABLE_TYPE_VALUE},
+		}},
+	}
+	err := applyInputOverrides(steps, map[string]map[string]string{"scan": {"IMAGE": "nginx:1"}})
+	if err != nil {
+		t.Fatalf("applyInputOverrides() err = %v", err)
+	}
+	image, level := steps[1].InputVars[0], steps[1].InputVars[1]
+	if image.Value != "nginx:1" || image.VariableType != bean.VARIABLE_TYPE_VALUE || image.ReferenceVariableName != "" || image.ReferenceVariableStepIndex != 0 {
+		t.Errorf("applyInputOverrides() overridden input = %+v", image)
+	}
+	if level.Value != "low" || steps[0].InputVars[0].Value != "v1" {
+		t.Errorf("applyInputOverrides() changed inputs not overridden")
+	}
+	if err = applyInputOverrides(steps, map[string]map[string]string{"deploy": {"A": "b"}}); err == nil {
+		t.Errorf("applyInputOverrides() of unknown step did not fail")
+	}
+	if err = applyInputOverrides(steps, map[string]map[string]string{"build": {"IMAGE": "b"}}); err == nil {
+		t.Errorf("applyInputOverrides() of unknown input did not fail")
+	}
+
+	checkoutStep := &bean.StepObject{Name: CHECKOUT_STEP_NAME, Index: CHECKOUT_STEP_INDEX}
+	withCheckout := withCheckoutStep(checkoutStep, steps)
+	if len(withCheckout) != 3 || withCheckout[0] != checkoutStep || len(steps[0].DependsOn) != 1 || steps[0].DependsOn[0] != CHECKOUT_STEP_INDEX ||
+		len(steps[1].DependsOn) != 1 || steps[1].DependsOn[0] != 1 {
+		t.Errorf("withCheckoutStep() = %+v", withCheckout)
+	}
+}
+
+func TestGetPluginStep(t *testing.T) {
+	plugin := &pluginRepository.PluginMetadata{Id: 7, Name: "Sonar"}
+	inputs := []*pluginRepository.PluginStepVariable{
+		{Name: "URL", Format: pluginRepository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING,
+			ValueConstraint: &pluginRepository.PluginVariableConstraint{Type: pluginRepository.PLUGIN_VARIABLE_CONSTRAINT_REGEX, Pattern: `\S+`}},
+		{Name: "RETRIES", Format: pluginRepository.PLUGIN_VARIABLE_FORMAT_TYPE_NUMBER, DefaultValue: "3", VariableStepIndexInPlugin: 2,
+			ValueConstraint: &pluginRepository.PluginVariableConstraint{Type: pluginRepository.PLUGIN_VARIABLE_CONSTRAINT_NUMBER_RANGE}},
+	}
+	outputs := []*pluginRepository.PluginStepVariable{{Name: "REPORT", Format: pluginRepository.PLUGIN_VARIABLE_FORMAT_TYPE_STRING}}
+	step, err := getPluginStep(plugin, inputs, outputs, map[string]string{"URL": "http://sonar"})
+	if err != nil {
+		t.Fatalf("getPluginStep() err = %v", err)
+	}
+	if step.RefPluginId != 7 || step.StepType != "REF_PLUGIN" || len(step.InputVars) != 2 || len(step.OutputVars) != 1 {
+		t.Fatalf("getPluginStep() step = %+v", step)
+	}
+	if retries := step.InputVars[1]; retries.Value != "3" || retries.VariableType != bean.VARIABLE_TYPE_VALUE || retries.VariableStepIndexInPlugin != 2 {
+		t.Errorf("getPluginStep() defaulted input = %+v", retries)
+	}
+	if _, err = getPluginStep(plugin, inputs, outputs, map[string]string{"URL": "x", "TOKEN": "y"}); err == nil {
+		t.Errorf("getPluginStep() of unknown input did not fail")
+	}
+	if _, err = getPluginStep(plugin, inputs, outputs, map[string]string{"URL": "x", "RETRIES": "many"}); err == nil {
+		t.Errorf("getPluginStep() of invalid input did not fail")
+	}
+	if _, err = getPluginStep(plugin, inputs, outputs, nil); err == nil {
+		t.Errorf("getPluginStep() of missing required input did not fail")
+	}
+}
+
+func TestGetRunId(t *testing.T) {
+	tests := []struct {
+		workflowName string
+		id           int
+		ok           bool
+	}{
+		{"plugin-test-42-x7k2p", 42, true},
+		{"plugin-test-42", 42, true},
+		{"42-ci-build-x7k2p", 0, false},
+		{"plugin-test-abc-x7k2p", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if id, ok := getRunId(tt.workflowName); id != tt.id || ok != tt.ok {
+			t.Errorf("getRunId(%q) = %d, %v, want %d, %v", tt.workflowName, id, ok, tt.id, tt.ok)
+		}
+	}
+	if workflowId := getWorkflowId(42); workflowId > 0 {
+		t.Errorf("getWorkflowId() = %d, workflow ids of runs must not match a ci workflow", workflowId)
+	}
+	if url := getResultUrl("", "http://orchestrator.devtroncd/webhook/msg/nats", 5); url != "http://orchestrator.devtroncd/webhook/plugin-test/5/result" {
+		t.Errorf("getResultUrl() of orchestrator host = %s", url)
+	}
+	if url := getResultUrl("https://devtron.example.com/orchestrator/webhook/", "", 5); url != "https://devtron.example.com/orchestrator/webhook/plugin-test/5/result" {
+		t.Errorf("getResultUrl() of result host = %s", url)
+	}
+}
+
+func TestDisabledRuns(t *testing.T) {
+	impl := &PluginTestServiceImpl{config: &PluginTestConfig{}}
+	_, err := impl.TestPlugin(&PluginTestRequest{PluginId: 1})
+	if apiErr, ok := err.(*util.ApiError); !ok || apiErr.HttpStatusCode != http.StatusNotImplemented {
+		t.Errorf("TestPlugin() of disabled runs err = %v", err)
+	}
+	_, err = impl.TestStage(&StageTestRequest{PipelineId: 1})
+	if apiErr, ok := err.(*util.ApiError); !ok || apiErr.HttpStatusCode != http.StatusNotImplemented {
+		t.Errorf("TestStage() of disabled runs err = %v", err)
+	}
+}
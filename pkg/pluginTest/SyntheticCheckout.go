@@ -0,0 +1,100 @@
+package pluginTest
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	pipelineStageRepository "github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	pluginRepository "github.com/devtron-labs/devtron/pkg/plugin/repository"
+)
+
+const (
+	CHECKOUT_STEP_NAME = "synthetic-git-checkout"
+	// CHECKOUT_STEP_INDEX is below the indexes of the steps of stages, which start at 1
+	CHECKOUT_STEP_INDEX     = 0
+	DEFAULT_CHECKOUT_BRANCH = "main"
+	DEFAULT_COMMIT_MESSAGE  = "plugin test checkout"
+)
+
+var branchNameRegex = regexp.MustCompile(`^[A-Za-z0-9._][A-Za-z0-9._/-]*$`)
+
+// getCheckoutStep returns the shell step creating the git repository of the synthetic checkout, with its files
+// committed to the branch, nil if there is no checkout
+func getCheckoutStep(checkout *SyntheticCheckout, config *PluginTestConfig) (*bean.StepObject, error) {
+	if checkout == nil {
+		return nil, nil
+	}
+	if len(checkout.Files) > config.MaxCheckoutFiles {
+		return nil, fmt.Errorf("checkout has %d files, at most %d are allowed", len(checkout.Files), config.MaxCheckoutFiles)
+	}
+	branch := checkout.Branch
+	if len(branch) == 0 {
+		branch = DEFAULT_CHECKOUT_BRANCH
+	}
+	if !branchNameRegex.MatchString(branch) || strings.Contains(branch, "..") {
+		return nil, fmt.Errorf("invalid checkout branch %s", branch)
+	}
+	commitMessage := checkout.CommitMessage
+	if len(commitMessage) == 0 {
+		commitMessage = DEFAULT_COMMIT_MESSAGE
+	}
+	filePaths := make([]string, 0, len(checkout.Files))
+	size := 0
+	for filePath, content := range checkout.Files {
+		filePaths = append(filePaths, filePath)
+		size += len(content)
+	}
+	if size > config.MaxCheckoutSizeKb*1024 {
+		return nil, fmt.Errorf("checkout files exceed %d KB", config.MaxCheckoutSizeKb)
+	}
+	sort.Strings(filePaths)
+
+	var script strings.Builder
+	script.WriteString("set -e\n")
+	script.WriteString(fmt.Sprintf("mkdir -p %s && cd %s\n", shellQuote(config.CheckoutPath), shellQuote(config.CheckoutPath)))
+	for _, filePath := range filePaths {
+		cleanPath, err := getCheckoutFilePath(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if dir := path.Dir(cleanPath); dir != "." {
+			script.WriteString(fmt.Sprintf("mkdir -p %s\n", shellQuote(dir)))
+		}
+		content := base64.StdEncoding.EncodeToString([]byte(checkout.Files[filePath]))
+		script.WriteString(fmt.Sprintf("printf '%%s' %s | base64 -d > %s\n", shellQuote(content), shellQuote(cleanPath)))
+	}
+	script.WriteString("git init -q .\n")
+	script.WriteString(fmt.Sprintf("git checkout -q -b %s\n", shellQuote(branch)))
+	script.WriteString("git add -A\n")
+	script.WriteString(fmt.Sprintf("git -c user.name=devtron -c user.email=plugin-test@devtron.ai commit -q --allow-empty -m %s\n", shellQuote(commitMessage)))
+	return &bean.StepObject{
+		Name:         CHECKOUT_STEP_NAME,
+		Index:        CHECKOUT_STEP_INDEX,
+		StepType:     string(pipelineStageRepository.PIPELINE_STEP_TYPE_INLINE),
+		ExecutorType: string(pluginRepository.SCRIPT_TYPE_SHELL),
+		Script:       script.String(),
+	}, nil
+}
+
+// getCheckoutFilePath returns the cleaned path of the file of the checkout, it must stay inside the checkout and out
+// of the .git directory
+func getCheckoutFilePath(filePath string) (string, error) {
+	cleanPath := path.Clean(filePath)
+	if len(filePath) == 0 || path.IsAbs(filePath) || cleanPath == "." || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+		return "", fmt.Errorf("invalid checkout file path %q, must be a relative path inside the checkout", filePath)
+	}
+	if cleanPath == ".git" || strings.HasPrefix(cleanPath, ".git/") {
+		return "", errors.New("checkout files cannot be in the .git directory")
+	}
+	return cleanPath, nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
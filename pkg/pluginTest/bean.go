@@ -0,0 +1,75 @@
+package pluginTest
+
+import (
+	"time"
+
+	pipelineStageRepository "github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	"github.com/devtron-labs/devtron/pkg/pluginTest/repository"
+)
+
+const (
+	RUN_TYPE_PLUGIN = "PLUGIN"
+	RUN_TYPE_STAGE  = "STAGE"
+)
+
+const (
+	// RUN_STATUS_STARTING is the status of runs whose workflow is not submitted yet, the other statuses are the
+	// phases of the workflow
+	RUN_STATUS_STARTING  = "Starting"
+	RUN_STATUS_RUNNING   = "Running"
+	RUN_STATUS_SUCCEEDED = "Succeeded"
+	RUN_STATUS_FAILED    = "Failed"
+	RUN_STATUS_ERROR     = "Error"
+)
+
+// WORKFLOW_NAME_PREFIX prefixes the names of the workflows of the runs, it keeps them apart from the ci workflows
+// whose names start with the id of the ci_workflow
+const WORKFLOW_NAME_PREFIX = "plugin-test-"
+
+// SyntheticCheckout is the git checkout the steps of the run see in place of cloning the git materials of a pipeline
+type SyntheticCheckout struct {
+	// Files are the contents of the files of the checkout keyed by their path relative to the checkout
+	Files         map[string]string `json:"files"`
+	Branch        string            `json:"branch,omitempty"`
+	CommitMessage string            `json:"commitMessage,omitempty"`
+}
+
+type PluginTestRequest struct {
+	PluginId int `json:"pluginId"`
+	// PluginVersion is a semver constraint resolved among the versions of the plugin, the plugin itself is run if empty
+	PluginVersion  string             `json:"pluginVersion,omitempty"`
+	InputVariables map[string]string  `json:"inputVariables"`
+	Checkout       *SyntheticCheckout `json:"checkout,omitempty"`
+	UserId         int32              `json:"-"`
+}
+
+type StageTestRequest struct {
+	// PipelineId is the id of the ci pipeline for PRE_CI and POST_CI stages and of the cd pipeline for PRE_CD and
+	// POST_CD stages
+	PipelineId int                                       `json:"pipelineId"`
+	StageType  pipelineStageRepository.PipelineStageType `json:"stageType"`
+	// InputVariables overrides the values of the input variables of the steps, keyed by step name and variable name
+	InputVariables map[string]map[string]string `json:"inputVariables"`
+	Checkout       *SyntheticCheckout           `json:"checkout,omitempty"`
+	UserId         int32                        `json:"-"`
+}
+
+type PluginTestRunDto struct {
+	Id           int                      `json:"id"`
+	RunType      string                   `json:"runType"`
+	PluginId     int                      `json:"pluginId,omitempty"`
+	PipelineId   int                      `json:"pipelineId,omitempty"`
+	StageType    string                   `json:"stageType,omitempty"`
+	WorkflowName string                   `json:"workflowName,omitempty"`
+	Namespace    string                   `json:"namespace,omitempty"`
+	Status       string                   `json:"status"`
+	Message      string                   `json:"message,omitempty"`
+	Steps        []*repository.StepResult `json:"steps"`
+	StartedOn    time.Time                `json:"startedOn"`
+	FinishedOn   *time.Time               `json:"finishedOn,omitempty"`
+}
+
+// PluginTestResultRequest is posted by the runner once the steps of the run are done
+type PluginTestResultRequest struct {
+	Steps []*repository.StepResult `json:"steps"`
+}
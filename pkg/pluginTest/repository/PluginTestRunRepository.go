@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// PluginTestRun is a sandbox run of a plugin or of the pre/post stage of a pipeline, ResultToken authenticates the
+// step results posted by the runner
+type PluginTestRun struct {
+	tableName    struct{}      `sql:"plugin_test_run" pg:",discard_unknown_columns"`
+	Id           int           `sql:"id,pk"`
+	RunType      string        `sql:"run_type,notnull"`
+	PluginId     int           `sql:"plugin_id"`
+	PipelineId   int           `sql:"pipeline_id"`
+	StageType    string        `sql:"stage_type"`
+	WorkflowName string        `sql:"workflow_name"`
+	Namespace    string        `sql:"namespace"`
+	Status       string        `sql:"status,notnull"`
+	Message      string        `sql:"message"`
+	ResultToken  string        `sql:"result_token,notnull"`
+	StepResults  []*StepResult `sql:"step_results"`
+	StartedOn    time.Time     `sql:"started_on,notnull"`
+	FinishedOn   time.Time     `sql:"finished_on"`
+	sql.AuditLog
+}
+
+// StepResult is the outcome of a step of the run as reported by the runner
+type StepResult struct {
+	Index           int               `json:"index"`
+	Name            string            `json:"name"`
+	Status          string            `json:"status"`
+	ExitCode        int               `json:"exitCode"`
+	Message         string            `json:"message,omitempty"`
+	OutputVariables map[string]string `json:"outputVariables,omitempty"`
+	// FailedConditions are the success/failure conditions which failed the step
+	FailedConditions []string   `json:"failedConditions,omitempty"`
+	StartedOn        *time.Time `json:"startedOn,omitempty"`
+	FinishedOn       *time.Time `json:"finishedOn,omitempty"`
+}
+
+type PluginTestRunRepository interface {
+	Save(run *PluginTestRun) error
+	// UpdateStatus updates the workflow, status and finish time of the run, leaving the step results as they are
+	UpdateStatus(run *PluginTestRun) error
+	UpdateStepResults(run *PluginTestRun) error
+	FindById(id int) (*PluginTestRun, error)
+}
+
+type PluginTestRunRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewPluginTestRunRepositoryImpl(dbConnection *pg.DB) *PluginTestRunRepositoryImpl {
+	return &PluginTestRunRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *PluginTestRunRepositoryImpl) Save(run *PluginTestRun) error {
+	return impl.dbConnection.Insert(run)
+}
+
+func (impl *PluginTestRunRepositoryImpl) UpdateStatus(run *PluginTestRun) error {
+	_, err := impl.dbConnection.Model(run).
+		Column("workflow_name", "namespace", "status", "message", "finished_on", "updated_on", "updated_by").
+		WherePK().
+		Update()
+	return err
+}
+
+func (impl *PluginTestRunRepositoryImpl) UpdateStepResults(run *PluginTestRun) error {
+	_, err := impl.dbConnection.Model(run).
+		Column("step_results", "updated_on", "updated_by").
+		WherePK().
+		Update()
+	return err
+}
+
+func (impl *PluginTestRunRepositoryImpl) FindById(id int) (*PluginTestRun, error) {
+	run := &PluginTestRun{}
+	err := impl.dbConnection.Model(run).
+		Where("id = ?", id).
+		Select()
+	return run, err
+}
@@ -0,0 +1,101 @@
+package provenance
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// pae is the DSSE pre-authentication encoding of the payload, it is what the signatures of an envelope sign
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// signEnvelope wraps the payload in a DSSE envelope signed by the key
+func signEnvelope(payloadType string, payload []byte, keyId string, privateKey *ecdsa.PrivateKey) (*Envelope, error) {
+	hash := sha256.Sum256(pae(payloadType, payload))
+	sig, err := ecdsa.SignASN1(rand.Reader, privateKey, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []*Signature{{KeyId: keyId, Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+// verifyEnvelope checks that a signature of the envelope made by the key is valid and returns the decoded payload
+func verifyEnvelope(envelope *Envelope, keyId string, publicKey *ecdsa.PublicKey) ([]byte, error) {
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid envelope payload: %v", err)
+	}
+	hash := sha256.Sum256(pae(envelope.PayloadType, payload))
+	for _, signature := range envelope.Signatures {
+		if signature.KeyId != keyId {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(signature.Sig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid envelope signature: %v", err)
+		}
+		if ecdsa.VerifyASN1(publicKey, hash[:], sig) {
+			return payload, nil
+		}
+		return nil, errors.New("envelope signature does not match its payload")
+	}
+	return nil, fmt.Errorf("envelope is not signed by key %s", keyId)
+}
+
+// generateSigningKey generates an ECDSA P-256 key pair, returning the PEM encoded keys along with the key id which is
+// the hex encoded sha256 of the DER encoded public key
+func generateSigningKey() (keyId string, publicKeyPem string, privateKeyPem string, err error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", "", err
+	}
+	privateKeyDer, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return "", "", "", err
+	}
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return "", "", "", err
+	}
+	keyHash := sha256.Sum256(publicKeyDer)
+	publicKeyPem = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer}))
+	privateKeyPem = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privateKeyDer}))
+	return hex.EncodeToString(keyHash[:]), publicKeyPem, privateKeyPem, nil
+}
+
+func parsePrivateKey(privateKeyPem string) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKeyPem))
+	if block == nil {
+		return nil, errors.New("invalid signing key, PEM block not found")
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+func parsePublicKey(publicKeyPem string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPem))
+	if block == nil {
+		return nil, errors.New("invalid public key, PEM block not found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an ECDSA key")
+	}
+	return publicKey, nil
+}
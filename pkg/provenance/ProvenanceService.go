@@ -0,0 +1,520 @@
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devtron-labs/common-lib/utils/k8s"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	repository2 "github.com/devtron-labs/devtron/pkg/plugin/repository"
+	provenanceRepository "github.com/devtron-labs/devtron/pkg/provenance/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	util2 "github.com/devtron-labs/devtron/util"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// privateKeySecretNamePrefix prefixes the start of the id of the key in the name of the kubernetes secret its
+	// private key is kept in
+	privateKeySecretNamePrefix = "devtron-provenance-signing-key-"
+	privateKeySecretDataKey    = "provenance.key"
+)
+
+type ProvenanceService interface {
+	// SaveBuildDefinition records what the ci workflow is going to build from, the provenance of its image is
+	// generated from it once the image is pushed
+	SaveBuildDefinition(request *BuildDefinitionRequest) error
+	// GenerateProvenance signs the provenance of the image of the artifact built by a ci workflow and stores it
+	// alongside the artifact, artifacts not built by a ci workflow of devtron have no provenance
+	GenerateProvenance(artifact *repository.CiArtifact) error
+	GetProvenance(ciArtifactId int) (*ProvenanceDto, error)
+	// VerifyProvenance checks the signature of the provenance of the artifact and that the provenance is about the
+	// image of the artifact
+	VerifyProvenance(ciArtifactId int) (*ProvenanceVerificationResult, error)
+	GetSigningKey() (*SigningKeyDto, error)
+}
+
+type ProvenanceServiceImpl struct {
+	logger                 *zap.SugaredLogger
+	provenanceRepository   provenanceRepository.ProvenanceRepository
+	ciArtifactRepository   repository.CiArtifactRepository
+	ciWorkflowRepository   pipelineConfig.CiWorkflowRepository
+	globalPluginRepository repository2.GlobalPluginRepository
+	k8sUtil                *k8s.K8sUtil
+	devtronSecretConfig    *util2.DevtronSecretConfig
+	signingKeyLock         *sync.Mutex
+}
+
+func NewProvenanceServiceImpl(logger *zap.SugaredLogger,
+	provenanceRepository provenanceRepository.ProvenanceRepository,
+	ciArtifactRepository repository.CiArtifactRepository,
+	ciWorkflowRepository pipelineConfig.CiWorkflowRepository,
+	globalPluginRepository repository2.GlobalPluginRepository,
+	k8sUtil *k8s.K8sUtil,
+	devtronSecretConfig *util2.DevtronSecretConfig) *ProvenanceServiceImpl {
+	return &ProvenanceServiceImpl{
+		logger:                 logger,
+		provenanceRepository:   provenanceRepository,
+		ciArtifactRepository:   ciArtifactRepository,
+		ciWorkflowRepository:   ciWorkflowRepository,
+		globalPluginRepository: globalPluginRepository,
+		k8sUtil:                k8sUtil,
+		devtronSecretConfig:    devtronSecretConfig,
+		signingKeyLock:         &sync.Mutex{},
+	}
+}
+
+func (impl *ProvenanceServiceImpl) SaveBuildDefinition(request *BuildDefinitionRequest) error {
+	pluginNames, err := impl.getPluginNames(request.PreCiSteps, request.PostCiSteps)
+	if err != nil {
+		return err
+	}
+	definition := buildDefinition(request, pluginNames)
+	definitionJson, err := json.Marshal(definition)
+	if err != nil {
+		impl.logger.Errorw("error in marshalling build definition", "ciWorkflowId", request.CiWorkflowId, "err", err)
+		return err
+	}
+	provenance := &provenanceRepository.CiBuildProvenance{
+		CiWorkflowId:    request.CiWorkflowId,
+		CiPipelineId:    request.CiPipelineId,
+		BuildDefinition: string(definitionJson),
+		AuditLog:        sql.NewDefaultAuditLog(request.UserId),
+	}
+	err = impl.provenanceRepository.Save(provenance)
+	if err != nil {
+		impl.logger.Errorw("error in saving build definition", "ciWorkflowId", request.CiWorkflowId, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *ProvenanceServiceImpl) GenerateProvenance(artifact *repository.CiArtifact) error {
+	if artifact.WorkflowId == nil {
+		return nil
+	}
+	provenance, err := impl.provenanceRepository.FindByCiWorkflowId(*artifact.WorkflowId)
+	if err == pg.ErrNoRows {
+		impl.logger.Infow("build definition not recorded for ci workflow, skipping provenance", "ciWorkflowId", *artifact.WorkflowId)
+		return nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching build provenance", "ciWorkflowId", *artifact.WorkflowId, "err", err)
+		return err
+	}
+	if len(artifact.ImageDigest) == 0 {
+		impl.logger.Warnw("image digest of artifact not known, skipping provenance", "ciArtifactId", artifact.Id, "image", artifact.Image)
+		return nil
+	}
+	definition := &BuildDefinition{}
+	err = json.Unmarshal([]byte(provenance.BuildDefinition), definition)
+	if err != nil {
+		impl.logger.Errorw("error in unmarshalling build definition", "ciWorkflowId", *artifact.WorkflowId, "err", err)
+		return err
+	}
+	workflow, err := impl.ciWorkflowRepository.FindById(*artifact.WorkflowId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci workflow", "ciWorkflowId", *artifact.WorkflowId, "err", err)
+		return err
+	}
+	finishedOn := workflow.FinishedOn
+	if finishedOn.IsZero() {
+		finishedOn = time.Now()
+	}
+	statement := buildStatement(definition, artifact.Image, artifact.ImageDigest, workflow.Id, workflow.StartedOn, finishedOn)
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return err
+	}
+	signingKey, err := impl.getOrCreateSigningKey()
+	if err != nil {
+		return err
+	}
+	privateKeyPem, err := impl.getPrivateKey(signingKey)
+	if err != nil {
+		impl.logger.Errorw("error in fetching provenance signing key", "keyId", signingKey.KeyId, "secret", signingKey.PrivateKeySecretName, "err", err)
+		return err
+	}
+	privateKey, err := parsePrivateKey(privateKeyPem)
+	if err != nil {
+		impl.logger.Errorw("error in parsing provenance signing key", "keyId", signingKey.KeyId, "err", err)
+		return err
+	}
+	envelope, err := signEnvelope(IN_TOTO_PAYLOAD_TYPE, payload, signingKey.KeyId, privateKey)
+	if err != nil {
+		impl.logger.Errorw("error in signing provenance", "ciArtifactId", artifact.Id, "err", err)
+		return err
+	}
+	envelopeJson, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	provenance.CiArtifactId = artifact.Id
+	provenance.Envelope = string(envelopeJson)
+	provenance.SigningKeyId = signingKey.Id
+	provenance.UpdatedOn = time.Now()
+	err = impl.provenanceRepository.Update(provenance)
+	if err != nil {
+		impl.logger.Errorw("error in saving provenance", "ciArtifactId", artifact.Id, "err", err)
+		return err
+	}
+	impl.logger.Infow("provenance generated", "ciArtifactId", artifact.Id, "ciWorkflowId", workflow.Id, "keyId", signingKey.KeyId)
+	return nil
+}
+
+func (impl *ProvenanceServiceImpl) GetProvenance(ciArtifactId int) (*ProvenanceDto, error) {
+	artifact, provenance, err := impl.findProvenance(ciArtifactId)
+	if err != nil {
+		return nil, err
+	}
+	envelope := &Envelope{}
+	err = json.Unmarshal([]byte(provenance.Envelope), envelope)
+	if err != nil {
+		impl.logger.Errorw("error in unmarshalling provenance envelope", "ciArtifactId", ciArtifactId, "err", err)
+		return nil, err
+	}
+	statement, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		impl.logger.Errorw("error in decoding provenance statement", "ciArtifactId", ciArtifactId, "err", err)
+		return nil, err
+	}
+	keyId := ""
+	if len(envelope.Signatures) > 0 {
+		keyId = envelope.Signatures[0].KeyId
+	}
+	return &ProvenanceDto{
+		CiArtifactId: ciArtifactId,
+		CiWorkflowId: provenance.CiWorkflowId,
+		Image:        artifact.Image,
+		ImageDigest:  artifact.ImageDigest,
+		KeyId:        keyId,
+		Envelope:     json.RawMessage(provenance.Envelope),
+		Statement:    statement,
+		CreatedOn:    provenance.UpdatedOn,
+	}, nil
+}
+
+func (impl *ProvenanceServiceImpl) VerifyProvenance(ciArtifactId int) (*ProvenanceVerificationResult, error) {
+	artifact, provenance, err := impl.findProvenance(ciArtifactId)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := impl.provenanceRepository.FindSigningKeyById(provenance.SigningKeyId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching provenance signing key", "signingKeyId", provenance.SigningKeyId, "err", err)
+		return nil, err
+	}
+	result := &ProvenanceVerificationResult{CiArtifactId: ciArtifactId, KeyId: signingKey.KeyId}
+	result.Verified, result.Message = verifyProvenance(provenance.Envelope, signingKey, artifact.ImageDigest)
+	return result, nil
+}
+
+func (impl *ProvenanceServiceImpl) GetSigningKey() (*SigningKeyDto, error) {
+	signingKey, err := impl.getOrCreateSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKeyDto{KeyId: signingKey.KeyId, PublicKey: signingKey.PublicKey}, nil
+}
+
+// findProvenance returns the provenance of the artifact, artifacts of linked ci pipelines and images copied by
+// plugins share the provenance of the artifact they are derived from
+func (impl *ProvenanceServiceImpl) findProvenance(ciArtifactId int) (*repository.CiArtifact, *provenanceRepository.CiBuildProvenance, error) {
+	artifact, err := impl.ciArtifactRepository.Get(ciArtifactId)
+	if err == pg.ErrNoRows {
+		return nil, nil, notFound("ci artifact not found")
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching ci artifact", "ciArtifactId", ciArtifactId, "err", err)
+		return nil, nil, err
+	}
+	builtArtifactId := artifact.Id
+	if artifact.ParentCiArtifact > 0 {
+		builtArtifactId = artifact.ParentCiArtifact
+	}
+	provenance, err := impl.provenanceRepository.FindByCiArtifactId(builtArtifactId)
+	if err == pg.ErrNoRows {
+		return nil, nil, notFound("provenance not found for the ci artifact")
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching provenance", "ciArtifactId", builtArtifactId, "err", err)
+		return nil, nil, err
+	}
+	return artifact, provenance, nil
+}
+
+// getOrCreateSigningKey returns the active signing key, generating it the first time provenance is signed
+func (impl *ProvenanceServiceImpl) getOrCreateSigningKey() (*provenanceRepository.ProvenanceSigningKey, error) {
+	impl.signingKeyLock.Lock()
+	defer impl.signingKeyLock.Unlock()
+	signingKey, err := impl.provenanceRepository.FindActiveSigningKey()
+	if err == nil {
+		return signingKey, nil
+	} else if err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching provenance signing key", "err", err)
+		return nil, err
+	}
+	keyId, publicKey, privateKey, err := generateSigningKey()
+	if err != nil {
+		impl.logger.Errorw("error in generating provenance signing key", "err", err)
+		return nil, err
+	}
+	signingKey = &provenanceRepository.ProvenanceSigningKey{
+		KeyId:     keyId,
+		PublicKey: publicKey,
+		Active:    true,
+		AuditLog:  sql.NewDefaultAuditLog(1),
+	}
+	err = impl.savePrivateKey(signingKey, privateKey)
+	if err != nil {
+		impl.logger.Errorw("error in saving provenance signing key secret", "keyId", keyId, "err", err)
+		return nil, err
+	}
+	err = impl.provenanceRepository.SaveSigningKey(signingKey)
+	if err != nil {
+		// another instance may have generated the key in the meantime, only one active key is allowed
+		impl.logger.Warnw("error in saving provenance signing key, fetching the active one", "err", err)
+		if err := impl.deletePrivateKey(signingKey); err != nil {
+			impl.logger.Errorw("error in deleting secret of unsaved provenance signing key", "secret", signingKey.PrivateKeySecretName, "err", err)
+		}
+		return impl.provenanceRepository.FindActiveSigningKey()
+	}
+	impl.logger.Infow("provenance signing key generated", "keyId", keyId)
+	return signingKey, nil
+}
+
+// savePrivateKey creates the kubernetes secret of the devtron namespace the private key of key is kept in
+func (impl *ProvenanceServiceImpl) savePrivateKey(key *provenanceRepository.ProvenanceSigningKey, privateKey string) error {
+	client, err := impl.k8sUtil.GetClientForInCluster()
+	if err != nil {
+		return err
+	}
+	secretName := privateKeySecretNamePrefix + key.KeyId[:16]
+	data := map[string][]byte{privateKeySecretDataKey: []byte(privateKey)}
+	_, err = impl.k8sUtil.CreateSecret(impl.devtronSecretConfig.DevtronDexSecretNamespace, data, secretName, "", client, nil, nil)
+	if err != nil {
+		return err
+	}
+	key.PrivateKeySecretName = secretName
+	return nil
+}
+
+func (impl *ProvenanceServiceImpl) getPrivateKey(key *provenanceRepository.ProvenanceSigningKey) (string, error) {
+	if len(key.PrivateKeySecretName) == 0 {
+		return "", fmt.Errorf("private key of provenance signing key %s is not stored", key.KeyId)
+	}
+	client, err := impl.k8sUtil.GetClientForInCluster()
+	if err != nil {
+		return "", err
+	}
+	secret, err := impl.k8sUtil.GetSecret(impl.devtronSecretConfig.DevtronDexSecretNamespace, key.PrivateKeySecretName, client)
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Data[privateKeySecretDataKey]), nil
+}
+
+func (impl *ProvenanceServiceImpl) deletePrivateKey(key *provenanceRepository.ProvenanceSigningKey) error {
+	client, err := impl.k8sUtil.GetClientForInCluster()
+	if err != nil {
+		return err
+	}
+	err = impl.k8sUtil.DeleteSecret(impl.devtronSecretConfig.DevtronDexSecretNamespace, key.PrivateKeySecretName, client)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// getPluginNames returns the names of the plugins referred by the steps
+func (impl *ProvenanceServiceImpl) getPluginNames(stepLists ...[]*bean.StepObject) (map[int]string, error) {
+	pluginNames := make(map[int]string)
+	for _, steps := range stepLists {
+		for _, step := range steps {
+			if step.RefPluginId == 0 {
+				continue
+			}
+			if _, ok := pluginNames[step.RefPluginId]; ok {
+				continue
+			}
+			plugin, err := impl.globalPluginRepository.GetMetaDataByPluginId(step.RefPluginId)
+			if err != nil {
+				impl.logger.Errorw("error in fetching plugin", "pluginId", step.RefPluginId, "err", err)
+				return nil, err
+			}
+			pluginNames[step.RefPluginId] = plugin.Name
+		}
+	}
+	return pluginNames, nil
+}
+
+func buildDefinition(request *BuildDefinitionRequest, pluginNames map[int]string) *BuildDefinition {
+	definition := &BuildDefinition{
+		BuildType: DEVTRON_CI_BUILD_TYPE,
+		ExternalParameters: &ExternalParameters{
+			AppName:      request.AppName,
+			CiPipelineId: request.CiPipelineId,
+			CiPipeline:   request.PipelineName,
+			BuildConfig:  request.CiBuildConfig,
+			PreCiSteps:   adaptSteps(request.PreCiSteps, pluginNames),
+			PostCiSteps:  adaptSteps(request.PostCiSteps, pluginNames),
+			TriggeredBy:  request.TriggeredBy,
+		},
+		InternalParameters: &InternalParameters{CiWorkflowId: request.CiWorkflowId},
+	}
+	for _, material := range request.Materials {
+		dependency := &ResourceDescriptor{
+			Name: material.MaterialName,
+			Uri:  "git+" + material.GitRepository,
+		}
+		if material.SourceType == pipelineConfig.SOURCE_TYPE_BRANCH_FIXED && len(material.SourceValue) > 0 {
+			dependency.Uri = fmt.Sprintf("%s@refs/heads/%s", dependency.Uri, material.SourceValue)
+		}
+		if len(material.CommitHash) > 0 {
+			dependency.Digest = map[string]string{GIT_COMMIT_DIGEST_KEY: material.CommitHash}
+		}
+		definition.ResolvedDependencies = append(definition.ResolvedDependencies, dependency)
+	}
+	if len(request.BuilderImage) > 0 {
+		definition.ResolvedDependencies = append(definition.ResolvedDependencies, imageDescriptor(request.BuilderImage))
+	}
+	return definition
+}
+
+func adaptSteps(steps []*bean.StepObject, pluginNames map[int]string) []*BuildStep {
+	var buildSteps []*BuildStep
+	for _, step := range steps {
+		buildStep := &BuildStep{
+			Index:        step.Index,
+			Name:         step.Name,
+			StepType:     step.StepType,
+			ExecutorType: step.ExecutorType,
+			PluginId:     step.RefPluginId,
+			PluginName:   pluginNames[step.RefPluginId],
+			DockerImage:  step.DockerImage,
+		}
+		if len(step.Script) > 0 {
+			buildStep.ScriptDigest = map[string]string{SHA256_DIGEST_KEY: sha256Hex([]byte(step.Script))}
+		}
+		buildSteps = append(buildSteps, buildStep)
+	}
+	return buildSteps
+}
+
+// buildStatement builds the in-toto statement attesting that the image was built from the build definition, the
+// builder image is moved from the resolved dependencies to the builder
+func buildStatement(definition *BuildDefinition, image string, imageDigest string, ciWorkflowId int, startedOn time.Time, finishedOn time.Time) *Statement {
+	runDetails := &RunDetails{
+		Builder: &Builder{Id: DEVTRON_CI_BUILDER_ID},
+		Metadata: &BuildMetadata{
+			InvocationId: fmt.Sprintf("%d", ciWorkflowId),
+			FinishedOn:   &finishedOn,
+		},
+	}
+	if !startedOn.IsZero() {
+		runDetails.Metadata.StartedOn = &startedOn
+	}
+	var resolvedDependencies []*ResourceDescriptor
+	for _, dependency := range definition.ResolvedDependencies {
+		if strings.HasPrefix(dependency.Uri, "docker://") {
+			runDetails.Builder.BuilderDependencies = append(runDetails.Builder.BuilderDependencies, dependency)
+		} else {
+			resolvedDependencies = append(resolvedDependencies, dependency)
+		}
+	}
+	definition.ResolvedDependencies = resolvedDependencies
+	return &Statement{
+		Type: IN_TOTO_STATEMENT_TYPE,
+		Subject: []*ResourceDescriptor{{
+			Name:   imageName(image),
+			Digest: digestMap(imageDigest),
+		}},
+		PredicateType: SLSA_PROVENANCE_TYPE,
+		Predicate: &Provenance{
+			BuildDefinition: definition,
+			RunDetails:      runDetails,
+		},
+	}
+}
+
+// verifyProvenance verifies the signature of the envelope and that its statement is the provenance of the image
+// with the digest, returning the reason if it is not
+func verifyProvenance(envelopeJson string, signingKey *provenanceRepository.ProvenanceSigningKey, imageDigest string) (bool, string) {
+	envelope := &Envelope{}
+	err := json.Unmarshal([]byte(envelopeJson), envelope)
+	if err != nil {
+		return false, fmt.Sprintf("invalid envelope: %v", err)
+	}
+	if envelope.PayloadType != IN_TOTO_PAYLOAD_TYPE {
+		return false, fmt.Sprintf("unexpected payload type %s", envelope.PayloadType)
+	}
+	publicKey, err := parsePublicKey(signingKey.PublicKey)
+	if err != nil {
+		return false, fmt.Sprintf("invalid signing key: %v", err)
+	}
+	payload, err := verifyEnvelope(envelope, signingKey.KeyId, publicKey)
+	if err != nil {
+		return false, err.Error()
+	}
+	statement := &Statement{}
+	err = json.Unmarshal(payload, statement)
+	if err != nil {
+		return false, fmt.Sprintf("invalid statement: %v", err)
+	}
+	if statement.Type != IN_TOTO_STATEMENT_TYPE || statement.PredicateType != SLSA_PROVENANCE_TYPE {
+		return false, fmt.Sprintf("statement of type %s is not a SLSA provenance", statement.PredicateType)
+	}
+	expectedDigest := digestMap(imageDigest)
+	for _, subject := range statement.Subject {
+		if len(expectedDigest[SHA256_DIGEST_KEY]) > 0 && subject.Digest[SHA256_DIGEST_KEY] == expectedDigest[SHA256_DIGEST_KEY] {
+			return true, "provenance signature verified"
+		}
+	}
+	return false, fmt.Sprintf("provenance is not about image digest %s", imageDigest)
+}
+
+// imageName strips the tag and the digest of an image reference
+func imageName(image string) string {
+	if index := strings.Index(image, "@"); index >= 0 {
+		image = image[:index]
+	}
+	if index := strings.LastIndex(image, ":"); index > strings.LastIndex(image, "/") {
+		image = image[:index]
+	}
+	return image
+}
+
+func imageDescriptor(image string) *ResourceDescriptor {
+	descriptor := &ResourceDescriptor{Uri: "docker://" + image}
+	if index := strings.Index(image, "@"); index >= 0 {
+		descriptor.Digest = digestMap(image[index+1:])
+	}
+	return descriptor
+}
+
+// digestMap converts an OCI digest like sha256:abc to an in-toto digest set
+func digestMap(digest string) map[string]string {
+	algorithm, value, found := strings.Cut(digest, ":")
+	if !found {
+		return map[string]string{SHA256_DIGEST_KEY: digest}
+	}
+	return map[string]string{algorithm: value}
+}
+
+func sha256Hex(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+func notFound(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: message, UserMessage: message}
+}
@@ -0,0 +1,105 @@
+package provenance
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	provenanceRepository "github.com/devtron-labs/devtron/pkg/provenance/repository"
+)
+
+const imageDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+
+func signedProvenance(t *testing.T, digest string) (string, *provenanceRepository.ProvenanceSigningKey) {
+	keyId, publicKey, privateKeyPem, err := generateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := &BuildDefinitionRequest{
+		CiWorkflowId: 7,
+		CiPipelineId: 3,
+		AppName:      "app",
+		BuilderImage: "quay.io/devtron/ci-runner:abc",
+		TriggeredBy:  "admin",
+		Materials: []bean.CiProjectDetails{{
+			GitRepository: "https://github.com/org/app.git",
+			MaterialName:  "app",
+			CommitHash:    "8a9c2f",
+			SourceType:    pipelineConfig.SOURCE_TYPE_BRANCH_FIXED,
+			SourceValue:   "main",
+		}},
+		PreCiSteps: []*bean.StepObject{{Index: 1, Name: "lint", StepType: "INLINE", Script: "make lint"}},
+	}
+	statement := buildStatement(buildDefinition(request, nil), "registry.example.com/org/app:v1", digest, 7, time.Now(), time.Now())
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey, err := parsePrivateKey(privateKeyPem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := signEnvelope(IN_TOTO_PAYLOAD_TYPE, payload, keyId, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelopeJson, _ := json.Marshal(envelope)
+	return string(envelopeJson), &provenanceRepository.ProvenanceSigningKey{KeyId: keyId, PublicKey: publicKey}
+}
+
+func TestBuildStatement(t *testing.T) {
+	request := &BuildDefinitionRequest{
+		BuilderImage: "quay.io/devtron/ci-runner@sha256:2222",
+		Materials: []bean.CiProjectDetails{{
+			GitRepository: "https://github.com/org/app.git",
+			CommitHash:    "8a9c2f",
+			SourceType:    pipelineConfig.SOURCE_TYPE_BRANCH_FIXED,
+			SourceValue:   "main",
+		}},
+		PreCiSteps: []*bean.StepObject{{Index: 1, Name: "scan", StepType: "REF_PLUGIN", RefPluginId: 4}},
+	}
+	statement := buildStatement(buildDefinition(request, map[int]string{4: "Vulnerability Scanning"}), "registry.example.com/org/app:v1", imageDigest, 7, time.Time{}, time.Now())
+	if statement.Subject[0].Name != "registry.example.com/org/app" || statement.Subject[0].Digest[SHA256_DIGEST_KEY] != imageDigest[len("sha256:"):] {
+		t.Errorf("buildStatement() subject = %+v", statement.Subject[0])
+	}
+	dependencies := statement.Predicate.BuildDefinition.ResolvedDependencies
+	if len(dependencies) != 1 || dependencies[0].Uri != "git+https://github.com/org/app.git@refs/heads/main" || dependencies[0].Digest[GIT_COMMIT_DIGEST_KEY] != "8a9c2f" {
+		t.Errorf("buildStatement() resolvedDependencies = %+v", dependencies)
+	}
+	builderDependencies := statement.Predicate.RunDetails.Builder.BuilderDependencies
+	if len(builderDependencies) != 1 || builderDependencies[0].Digest[SHA256_DIGEST_KEY] != "2222" {
+		t.Errorf("buildStatement() builderDependencies = %+v", builderDependencies)
+	}
+	if steps := statement.Predicate.BuildDefinition.ExternalParameters.PreCiSteps; len(steps) != 1 || steps[0].PluginName != "Vulnerability Scanning" {
+		t.Errorf("buildStatement() preCiSteps = %+v", steps)
+	}
+	if statement.Predicate.RunDetails.Metadata.StartedOn != nil {
+		t.Errorf("buildStatement() startedOn = %v, want unset", statement.Predicate.RunDetails.Metadata.StartedOn)
+	}
+}
+
+func TestVerifyProvenance(t *testing.T) {
+	envelopeJson, signingKey := signedProvenance(t, imageDigest)
+	if verified, message := verifyProvenance(envelopeJson, signingKey, imageDigest); !verified {
+		t.Errorf("verifyProvenance() = %v, %s", verified, message)
+	}
+	if verified, _ := verifyProvenance(envelopeJson, signingKey, "sha256:3333"); verified {
+		t.Errorf("verifyProvenance() of another image = %v", verified)
+	}
+
+	_, otherKey := signedProvenance(t, imageDigest)
+	otherKey.KeyId = signingKey.KeyId
+	if verified, _ := verifyProvenance(envelopeJson, otherKey, imageDigest); verified {
+		t.Errorf("verifyProvenance() with another key = %v", verified)
+	}
+
+	envelope := &Envelope{}
+	_ = json.Unmarshal([]byte(envelopeJson), envelope)
+	envelope.Payload = envelope.Payload[:len(envelope.Payload)-4] + "AAA="
+	tampered, _ := json.Marshal(envelope)
+	if verified, _ := verifyProvenance(string(tampered), signingKey, imageDigest); verified {
+		t.Errorf("verifyProvenance() of tampered payload = %v", verified)
+	}
+}
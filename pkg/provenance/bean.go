@@ -0,0 +1,140 @@
+package provenance
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/pipeline/bean"
+)
+
+const (
+	IN_TOTO_STATEMENT_TYPE = "https://in-toto.io/Statement/v1"
+	IN_TOTO_PAYLOAD_TYPE   = "application/vnd.in-toto+json"
+	SLSA_PROVENANCE_TYPE   = "https://slsa.dev/provenance/v1"
+	DEVTRON_CI_BUILD_TYPE  = "https://github.com/devtron-labs/devtron/ci-workflow/v1"
+	DEVTRON_CI_BUILDER_ID  = "https://github.com/devtron-labs/devtron/ci-runner"
+	GIT_COMMIT_DIGEST_KEY  = "gitCommit"
+	SHA256_DIGEST_KEY      = "sha256"
+)
+
+// BuildDefinitionRequest is what is sent to the ci runner for a ci workflow, it is recorded when the workflow is
+// triggered and attested once the workflow pushes its image
+type BuildDefinitionRequest struct {
+	CiWorkflowId  int
+	CiPipelineId  int
+	PipelineName  string
+	AppName       string
+	BuilderImage  string
+	TriggeredBy   string
+	Materials     []bean.CiProjectDetails
+	CiBuildConfig *bean.CiBuildConfigBean
+	PreCiSteps    []*bean.StepObject
+	PostCiSteps   []*bean.StepObject
+	UserId        int32
+}
+
+// Statement is an in-toto attestation statement, see https://github.com/in-toto/attestation/blob/main/spec/v1/statement.md
+type Statement struct {
+	Type          string                `json:"_type"`
+	Subject       []*ResourceDescriptor `json:"subject"`
+	PredicateType string                `json:"predicateType"`
+	Predicate     *Provenance           `json:"predicate"`
+}
+
+type ResourceDescriptor struct {
+	Name   string            `json:"name,omitempty"`
+	Uri    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// Provenance is the SLSA v1 build provenance predicate, see https://slsa.dev/spec/v1.0/provenance
+type Provenance struct {
+	BuildDefinition *BuildDefinition `json:"buildDefinition"`
+	RunDetails      *RunDetails      `json:"runDetails"`
+}
+
+type BuildDefinition struct {
+	BuildType            string                `json:"buildType"`
+	ExternalParameters   *ExternalParameters   `json:"externalParameters"`
+	InternalParameters   *InternalParameters   `json:"internalParameters,omitempty"`
+	ResolvedDependencies []*ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// ExternalParameters are the parameters of the build under the control of the users of the ci pipeline
+type ExternalParameters struct {
+	AppName      string                  `json:"appName"`
+	CiPipelineId int                     `json:"ciPipelineId"`
+	CiPipeline   string                  `json:"ciPipeline"`
+	BuildConfig  *bean.CiBuildConfigBean `json:"buildConfig,omitempty"`
+	PreCiSteps   []*BuildStep            `json:"preCiSteps,omitempty"`
+	PostCiSteps  []*BuildStep            `json:"postCiSteps,omitempty"`
+	TriggeredBy  string                  `json:"triggeredBy"`
+}
+
+// BuildStep is a pre or post ci step executed by the build, the script of inline steps is recorded by its digest
+type BuildStep struct {
+	Index        int               `json:"index"`
+	Name         string            `json:"name"`
+	StepType     string            `json:"stepType"`
+	ExecutorType string            `json:"executorType,omitempty"`
+	PluginId     int               `json:"pluginId,omitempty"`
+	PluginName   string            `json:"pluginName,omitempty"`
+	DockerImage  string            `json:"dockerImage,omitempty"`
+	ScriptDigest map[string]string `json:"scriptDigest,omitempty"`
+}
+
+type InternalParameters struct {
+	CiWorkflowId int `json:"ciWorkflowId"`
+}
+
+type RunDetails struct {
+	Builder  *Builder       `json:"builder"`
+	Metadata *BuildMetadata `json:"metadata,omitempty"`
+}
+
+type Builder struct {
+	Id                  string                `json:"id"`
+	BuilderDependencies []*ResourceDescriptor `json:"builderDependencies,omitempty"`
+}
+
+type BuildMetadata struct {
+	InvocationId string     `json:"invocationId"`
+	StartedOn    *time.Time `json:"startedOn,omitempty"`
+	FinishedOn   *time.Time `json:"finishedOn,omitempty"`
+}
+
+// Envelope is a DSSE envelope, see https://github.com/secure-systems-lab/dsse/blob/master/envelope.md
+type Envelope struct {
+	PayloadType string       `json:"payloadType"`
+	Payload     string       `json:"payload"`
+	Signatures  []*Signature `json:"signatures"`
+}
+
+type Signature struct {
+	KeyId string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+type ProvenanceDto struct {
+	CiArtifactId int             `json:"ciArtifactId"`
+	CiWorkflowId int             `json:"ciWorkflowId"`
+	Image        string          `json:"image"`
+	ImageDigest  string          `json:"imageDigest"`
+	KeyId        string          `json:"keyId"`
+	Envelope     json.RawMessage `json:"envelope"`
+	Statement    json.RawMessage `json:"statement"`
+	CreatedOn    time.Time       `json:"createdOn"`
+}
+
+type ProvenanceVerificationResult struct {
+	CiArtifactId int    `json:"ciArtifactId"`
+	Verified     bool   `json:"verified"`
+	KeyId        string `json:"keyId,omitempty"`
+	Message      string `json:"message"`
+}
+
+// SigningKeyDto is the public key provenance is signed with, for verifying provenance outside of devtron
+type SigningKeyDto struct {
+	KeyId     string `json:"keyId"`
+	PublicKey string `json:"publicKey"`
+}
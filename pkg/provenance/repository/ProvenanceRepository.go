@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// CiBuildProvenance is the provenance of the image built by a ci workflow, BuildDefinition is recorded when the
+// workflow is triggered and Envelope, the signed in-toto statement, once its artifact is saved
+type CiBuildProvenance struct {
+	tableName       struct{} `sql:"ci_build_provenance" pg:",discard_unknown_columns"`
+	Id              int      `sql:"id,pk"`
+	CiWorkflowId    int      `sql:"ci_workflow_id,notnull"`
+	CiPipelineId    int      `sql:"ci_pipeline_id,notnull"`
+	CiArtifactId    int      `sql:"ci_artifact_id"`
+	BuildDefinition string   `sql:"build_definition,notnull"`
+	Envelope        string   `sql:"envelope"`
+	SigningKeyId    int      `sql:"signing_key_id"`
+	sql.AuditLog
+}
+
+// ProvenanceSigningKey is the key pair devtron signs provenance with, only one key is active. Its private key is kept
+// in the kubernetes secret PrivateKeySecretName
+type ProvenanceSigningKey struct {
+	tableName            struct{} `sql:"provenance_signing_key" pg:",discard_unknown_columns"`
+	Id                   int      `sql:"id,pk"`
+	KeyId                string   `sql:"key_id,notnull"`
+	PublicKey            string   `sql:"public_key,notnull"`
+	PrivateKeySecretName string   `sql:"private_key_secret_name"`
+	Active               bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type ProvenanceRepository interface {
+	Save(provenance *CiBuildProvenance) error
+	Update(provenance *CiBuildProvenance) error
+	FindByCiWorkflowId(ciWorkflowId int) (*CiBuildProvenance, error)
+	FindByCiArtifactId(ciArtifactId int) (*CiBuildProvenance, error)
+
+	SaveSigningKey(key *ProvenanceSigningKey) error
+	FindActiveSigningKey() (*ProvenanceSigningKey, error)
+	FindSigningKeyById(id int) (*ProvenanceSigningKey, error)
+}
+
+type ProvenanceRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewProvenanceRepositoryImpl(dbConnection *pg.DB) *ProvenanceRepositoryImpl {
+	return &ProvenanceRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *ProvenanceRepositoryImpl) Save(provenance *CiBuildProvenance) error {
+	return impl.dbConnection.Insert(provenance)
+}
+
+func (impl *ProvenanceRepositoryImpl) Update(provenance *CiBuildProvenance) error {
+	return impl.dbConnection.Update(provenance)
+}
+
+func (impl *ProvenanceRepositoryImpl) FindByCiWorkflowId(ciWorkflowId int) (*CiBuildProvenance, error) {
+	provenance := &CiBuildProvenance{}
+	err := impl.dbConnection.Model(provenance).
+		Where("ci_workflow_id = ?", ciWorkflowId).
+		Select()
+	return provenance, err
+}
+
+func (impl *ProvenanceRepositoryImpl) FindByCiArtifactId(ciArtifactId int) (*CiBuildProvenance, error) {
+	provenance := &CiBuildProvenance{}
+	err := impl.dbConnection.Model(provenance).
+		Where("ci_artifact_id = ?", ciArtifactId).
+		Select()
+	return provenance, err
+}
+
+func (impl *ProvenanceRepositoryImpl) SaveSigningKey(key *ProvenanceSigningKey) error {
+	return impl.dbConnection.Insert(key)
+}
+
+func (impl *ProvenanceRepositoryImpl) FindActiveSigningKey() (*ProvenanceSigningKey, error) {
+	key := &ProvenanceSigningKey{}
+	err := impl.dbConnection.Model(key).
+		Where("active = ?", true).
+		Select()
+	return key, err
+}
+
+func (impl *ProvenanceRepositoryImpl) FindSigningKeyById(id int) (*ProvenanceSigningKey, error) {
+	key := &ProvenanceSigningKey{}
+	err := impl.dbConnection.Model(key).
+		Where("id = ?", id).
+		Select()
+	return key, err
+}
@@ -0,0 +1,216 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const spdxNoAssertion = "NOASSERTION"
+
+type spdxDocument struct {
+	SpdxVersion  string `json:"spdxVersion"`
+	CreationInfo struct {
+		Creators []string `json:"creators"`
+	} `json:"creationInfo"`
+	Packages []struct {
+		Name             string `json:"name"`
+		VersionInfo      string `json:"versionInfo"`
+		LicenseConcluded string `json:"licenseConcluded"`
+		LicenseDeclared  string `json:"licenseDeclared"`
+		ExternalRefs     []struct {
+			ReferenceType    string `json:"referenceType"`
+			ReferenceLocator string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+}
+
+type cycloneDxDocument struct {
+	BomFormat   string `json:"bomFormat"`
+	SpecVersion string `json:"specVersion"`
+	Metadata    struct {
+		// Tools is an array of tools till spec 1.4 and an object of components and services since 1.5
+		Tools json.RawMessage `json:"tools"`
+	} `json:"metadata"`
+	Components []*cycloneDxComponent `json:"components"`
+}
+
+type cycloneDxTool struct {
+	Vendor  string `json:"vendor"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type cycloneDxComponent struct {
+	Type     string `json:"type"`
+	Group    string `json:"group"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Purl     string `json:"purl"`
+	Licenses []struct {
+		License *struct {
+			Id   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"license"`
+		Expression string `json:"expression"`
+	} `json:"licenses"`
+	Components []*cycloneDxComponent `json:"components"`
+}
+
+// ParseSbomDocument parses a JSON encoded SPDX or CycloneDX document, the format is detected from the content
+func ParseSbomDocument(data []byte) (*SbomDocument, error) {
+	var header struct {
+		SpdxVersion string `json:"spdxVersion"`
+		BomFormat   string `json:"bomFormat"`
+	}
+	err := json.Unmarshal(data, &header)
+	if err != nil {
+		return nil, fmt.Errorf("only JSON encoded SPDX and CycloneDX documents are supported: %s", err.Error())
+	}
+	switch {
+	case strings.HasPrefix(header.SpdxVersion, "SPDX-"):
+		return parseSpdxDocument(data)
+	case header.BomFormat == "CycloneDX":
+		return parseCycloneDxDocument(data)
+	}
+	return nil, fmt.Errorf("document is neither an SPDX nor a CycloneDX document")
+}
+
+func parseSpdxDocument(data []byte) (*SbomDocument, error) {
+	doc := &spdxDocument{}
+	err := json.Unmarshal(data, doc)
+	if err != nil {
+		return nil, err
+	}
+	result := &SbomDocument{
+		Format:      SBOM_FORMAT_SPDX,
+		SpecVersion: strings.TrimPrefix(doc.SpdxVersion, "SPDX-"),
+	}
+	for _, creator := range doc.CreationInfo.Creators {
+		if strings.HasPrefix(creator, "Tool:") {
+			result.Tool = strings.TrimSpace(strings.TrimPrefix(creator, "Tool:"))
+			break
+		}
+	}
+	for _, spdxPackage := range doc.Packages {
+		pkg := &SbomPackage{
+			Name:    spdxPackage.Name,
+			Version: spdxAssertion(spdxPackage.VersionInfo),
+			License: spdxAssertion(spdxPackage.LicenseConcluded),
+		}
+		if len(pkg.License) == 0 {
+			pkg.License = spdxAssertion(spdxPackage.LicenseDeclared)
+		}
+		for _, ref := range spdxPackage.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				pkg.Purl = ref.ReferenceLocator
+				break
+			}
+		}
+		pkg.Type, pkg.Group = parsePurl(pkg.Purl)
+		result.Packages = append(result.Packages, pkg)
+	}
+	return result, nil
+}
+
+func parseCycloneDxDocument(data []byte) (*SbomDocument, error) {
+	doc := &cycloneDxDocument{}
+	err := json.Unmarshal(data, doc)
+	if err != nil {
+		return nil, err
+	}
+	result := &SbomDocument{
+		Format:      SBOM_FORMAT_CYCLONEDX,
+		SpecVersion: doc.SpecVersion,
+		Tool:        parseCycloneDxTool(doc.Metadata.Tools),
+	}
+	var addComponents func(components []*cycloneDxComponent)
+	addComponents = func(components []*cycloneDxComponent) {
+		for _, component := range components {
+			if component.Type != "file" {
+				pkg := &SbomPackage{
+					Name:    component.Name,
+					Group:   component.Group,
+					Version: component.Version,
+					Purl:    component.Purl,
+					License: cycloneDxLicense(component),
+				}
+				purlType, purlGroup := parsePurl(component.Purl)
+				pkg.Type = purlType
+				if len(pkg.Type) == 0 {
+					pkg.Type = component.Type
+				}
+				if len(pkg.Group) == 0 {
+					pkg.Group = purlGroup
+				}
+				result.Packages = append(result.Packages, pkg)
+			}
+			addComponents(component.Components)
+		}
+	}
+	addComponents(doc.Components)
+	return result, nil
+}
+
+func parseCycloneDxTool(tools json.RawMessage) string {
+	if len(tools) == 0 {
+		return ""
+	}
+	var toolList []*cycloneDxTool
+	if err := json.Unmarshal(tools, &toolList); err != nil {
+		var toolObject struct {
+			Components []*cycloneDxTool `json:"components"`
+		}
+		if err = json.Unmarshal(tools, &toolObject); err != nil {
+			return ""
+		}
+		toolList = toolObject.Components
+	}
+	if len(toolList) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s %s", toolList[0].Name, toolList[0].Version))
+}
+
+func cycloneDxLicense(component *cycloneDxComponent) string {
+	var licenses []string
+	for _, license := range component.Licenses {
+		switch {
+		case len(license.Expression) > 0:
+			licenses = append(licenses, license.Expression)
+		case license.License != nil && len(license.License.Id) > 0:
+			licenses = append(licenses, license.License.Id)
+		case license.License != nil && len(license.License.Name) > 0:
+			licenses = append(licenses, license.License.Name)
+		}
+	}
+	return strings.Join(licenses, " AND ")
+}
+
+func spdxAssertion(value string) string {
+	if value == spdxNoAssertion || value == "NONE" {
+		return ""
+	}
+	return value
+}
+
+// parsePurl returns the type and the namespace of a package url like pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1
+func parsePurl(purl string) (string, string) {
+	if !strings.HasPrefix(purl, "pkg:") {
+		return "", ""
+	}
+	path := strings.TrimPrefix(purl, "pkg:")
+	if index := strings.IndexAny(path, "@?#"); index >= 0 {
+		path = path[:index]
+	}
+	segments := strings.Split(path, "/")
+	if len(segments) < 3 {
+		return segments[0], ""
+	}
+	namespace, err := url.PathUnescape(strings.Join(segments[1:len(segments)-1], "/"))
+	if err != nil {
+		return segments[0], ""
+	}
+	return segments[0], namespace
+}
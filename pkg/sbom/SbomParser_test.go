@@ -0,0 +1,76 @@
+package sbom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSbomDocumentSpdx(t *testing.T) {
+	data := []byte(`{
+		"spdxVersion": "SPDX-2.3",
+		"creationInfo": {"creators": ["Organization: Anchore, Inc", "Tool: syft-0.98.0"]},
+		"packages": [{
+			"name": "log4j-core",
+			"versionInfo": "2.14.1",
+			"licenseConcluded": "NOASSERTION",
+			"licenseDeclared": "Apache-2.0",
+			"externalRefs": [
+				{"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:apache:log4j:2.14.1:*:*:*:*:*:*:*"},
+				{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"}
+			]
+		}]
+	}`)
+	doc, err := ParseSbomDocument(data)
+	if err != nil {
+		t.Fatalf("ParseSbomDocument() error = %v", err)
+	}
+	if doc.Format != SBOM_FORMAT_SPDX || doc.SpecVersion != "2.3" || doc.Tool != "syft-0.98.0" {
+		t.Errorf("ParseSbomDocument() = %s %s %s, want SPDX 2.3 syft-0.98.0", doc.Format, doc.SpecVersion, doc.Tool)
+	}
+	want := []*SbomPackage{{Name: "log4j-core", Group: "org.apache.logging.log4j", Version: "2.14.1", Type: "maven",
+		Purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", License: "Apache-2.0"}}
+	if !reflect.DeepEqual(doc.Packages, want) {
+		t.Errorf("ParseSbomDocument() packages = %+v, want %+v", doc.Packages[0], want[0])
+	}
+}
+
+func TestParseSbomDocumentCycloneDx(t *testing.T) {
+	data := []byte(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"metadata": {"tools": {"components": [{"type": "application", "name": "syft", "version": "0.98.0"}]}},
+		"components": [{
+			"type": "library",
+			"name": "express",
+			"version": "4.18.2",
+			"purl": "pkg:npm/express@4.18.2",
+			"licenses": [{"license": {"id": "MIT"}}],
+			"components": [{"type": "library", "group": "@babel", "name": "core", "version": "7.23.0", "purl": "pkg:npm/%40babel/core@7.23.0"}]
+		}, {
+			"type": "file",
+			"name": "/usr/lib/libc.so"
+		}]
+	}`)
+	doc, err := ParseSbomDocument(data)
+	if err != nil {
+		t.Fatalf("ParseSbomDocument() error = %v", err)
+	}
+	if doc.Format != SBOM_FORMAT_CYCLONEDX || doc.SpecVersion != "1.5" || doc.Tool != "syft 0.98.0" {
+		t.Errorf("ParseSbomDocument() = %s %s %s, want CYCLONEDX 1.5 syft 0.98.0", doc.Format, doc.SpecVersion, doc.Tool)
+	}
+	want := []*SbomPackage{
+		{Name: "express", Version: "4.18.2", Type: "npm", Purl: "pkg:npm/express@4.18.2", License: "MIT"},
+		{Name: "core", Group: "@babel", Version: "7.23.0", Type: "npm", Purl: "pkg:npm/%40babel/core@7.23.0"},
+	}
+	if !reflect.DeepEqual(doc.Packages, want) {
+		t.Errorf("ParseSbomDocument() packages = %+v %+v, want %+v %+v", doc.Packages[0], doc.Packages[1], want[0], want[1])
+	}
+}
+
+func TestParseSbomDocumentUnsupported(t *testing.T) {
+	for _, data := range []string{`<bom xmlns="http://cyclonedx.org/schema/bom/1.4"></bom>`, `{"name": "not an sbom"}`} {
+		if _, err := ParseSbomDocument([]byte(data)); err == nil {
+			t.Errorf("ParseSbomDocument(%q) error = nil, want error", data)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/pipeline/executors"
+	sbomRepository "github.com/devtron-labs/devtron/pkg/sbom/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type SbomConfig struct {
+	// BlobKeyPrefix is the prefix of the blob storage keys SBOM documents are stored at
+	BlobKeyPrefix       string `env:"SBOM_BLOB_KEY_PREFIX" envDefault:"sbom"`
+	MaxDocumentSizeInMb int    `env:"SBOM_MAX_DOCUMENT_SIZE_IN_MB" envDefault:"50"`
+}
+
+type SbomService interface {
+	// IngestSbom parses the SBOM document, stores it in blob storage and indexes its packages, replacing the SBOM
+	// previously ingested for the image
+	IngestSbom(request *IngestSbomRequest) (*SbomDto, error)
+	GetSbomByCiArtifactId(ciArtifactId int, withPackages bool) (*SbomDto, error)
+	// DownloadSbom returns the SBOM document of the ci artifact as it was ingested
+	DownloadSbom(ciArtifactId int) ([]byte, *SbomDto, error)
+	// SearchPackages finds the ci artifacts whose SBOM contains the package, along with the environments they are
+	// deployed on
+	SearchPackages(request *PackageSearchRequest) ([]*PackageSearchResultDto, error)
+	GetMaxDocumentSize() int64
+}
+
+type SbomServiceImpl struct {
+	logger                   *zap.SugaredLogger
+	sbomRepository           sbomRepository.SbomRepository
+	ciArtifactRepository     repository.CiArtifactRepository
+	ciPipelineRepository     pipelineConfig.CiPipelineRepository
+	blobStorageConfigService pipeline.BlobStorageConfigService
+	config                   *SbomConfig
+}
+
+func NewSbomServiceImpl(logger *zap.SugaredLogger,
+	sbomRepository sbomRepository.SbomRepository,
+	ciArtifactRepository repository.CiArtifactRepository,
+	ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	blobStorageConfigService pipeline.BlobStorageConfigService) *SbomServiceImpl {
+	cfg := &SbomConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Infow("error occurred while parsing SbomConfig, so setting sbom config to default values", "err", err)
+		cfg.BlobKeyPrefix = "sbom"
+		cfg.MaxDocumentSizeInMb = 50
+	}
+	return &SbomServiceImpl{
+		logger:                   logger,
+		sbomRepository:           sbomRepository,
+		ciArtifactRepository:     ciArtifactRepository,
+		ciPipelineRepository:     ciPipelineRepository,
+		blobStorageConfigService: blobStorageConfigService,
+		config:                   cfg,
+	}
+}
+
+// undeployedStatuses are the statuses of deployments which did not roll out the artifact
+var undeployedStatuses = []string{pipelineConfig.WorkflowFailed, pipelineConfig.WorkflowAborted, executors.WorkflowCancel}
+
+func (impl *SbomServiceImpl) GetMaxDocumentSize() int64 {
+	return int64(impl.config.MaxDocumentSizeInMb) * 1024 * 1024
+}
+
+func (impl *SbomServiceImpl) IngestSbom(request *IngestSbomRequest) (*SbomDto, error) {
+	if len(request.Document) == 0 {
+		return nil, badRequest("SBOM document is empty")
+	}
+	if int64(len(request.Document)) > impl.GetMaxDocumentSize() {
+		return nil, badRequest(fmt.Sprintf("SBOM document is larger than %d MB", impl.config.MaxDocumentSizeInMb))
+	}
+	err := impl.resolveArtifactImage(request)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := ParseSbomDocument(request.Document)
+	if err != nil {
+		impl.logger.Errorw("error in parsing sbom document", "image", request.Image, "err", err)
+		return nil, badRequest(err.Error())
+	}
+	sbom := &sbomRepository.CiArtifactSbom{
+		CiPipelineId: request.CiPipelineId,
+		Image:        request.Image,
+		ImageDigest:  request.ImageDigest,
+		Format:       doc.Format,
+		SpecVersion:  doc.SpecVersion,
+		Tool:         doc.Tool,
+		PackageCount: len(doc.Packages),
+		Active:       true,
+		AuditLog:     sql.NewDefaultAuditLog(request.UserId),
+	}
+	if impl.blobStorageConfigService.IsBlobStorageConfigured() {
+		sbom.BlobKey = impl.getBlobKey(request.CiPipelineId, request.Image, doc.Format)
+		err = impl.blobStorageConfigService.PutObject(sbom.BlobKey, request.Document)
+		if err != nil {
+			impl.logger.Errorw("error in storing sbom document", "image", request.Image, "key", sbom.BlobKey, "err", err)
+			return nil, err
+		}
+	} else {
+		impl.logger.Warnw("blob storage not configured, indexing sbom packages without storing the document", "image", request.Image)
+	}
+	packages := make([]*sbomRepository.CiArtifactSbomPackage, 0, len(doc.Packages))
+	for _, pkg := range doc.Packages {
+		if len(pkg.Name) == 0 {
+			continue
+		}
+		packages = append(packages, &sbomRepository.CiArtifactSbomPackage{
+			Name:    pkg.Name,
+			Group:   pkg.Group,
+			Version: pkg.Version,
+			Type:    pkg.Type,
+			Purl:    pkg.Purl,
+			License: pkg.License,
+		})
+	}
+	err = impl.sbomRepository.Save(sbom, packages)
+	if err != nil {
+		impl.logger.Errorw("error in saving sbom", "image", request.Image, "err", err)
+		return nil, err
+	}
+	impl.logger.Infow("sbom ingested", "image", request.Image, "format", sbom.Format, "packages", len(packages))
+	return adaptSbom(sbom), nil
+}
+
+func (impl *SbomServiceImpl) GetSbomByCiArtifactId(ciArtifactId int, withPackages bool) (*SbomDto, error) {
+	sbom, err := impl.getSbomByCiArtifactId(ciArtifactId)
+	if err != nil {
+		return nil, err
+	}
+	result := adaptSbom(sbom)
+	if withPackages {
+		packages, err := impl.sbomRepository.FindPackagesBySbomId(sbom.Id)
+		if err != nil {
+			impl.logger.Errorw("error in fetching sbom packages", "sbomId", sbom.Id, "err", err)
+			return nil, err
+		}
+		for _, pkg := range packages {
+			result.Packages = append(result.Packages, &SbomPackage{
+				Name:    pkg.Name,
+				Group:   pkg.Group,
+				Version: pkg.Version,
+				Type:    pkg.Type,
+				Purl:    pkg.Purl,
+				License: pkg.License,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (impl *SbomServiceImpl) DownloadSbom(ciArtifactId int) ([]byte, *SbomDto, error) {
+	sbom, err := impl.getSbomByCiArtifactId(ciArtifactId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sbom.BlobKey) == 0 {
+		return nil, nil, &util.ApiError{
+			HttpStatusCode:  http.StatusNotFound,
+			InternalMessage: "sbom document not stored as blob storage is not configured",
+			UserMessage:     "SBOM document is not stored as blob storage is not configured",
+		}
+	}
+	content, err := impl.blobStorageConfigService.GetObject(sbom.BlobKey)
+	if err != nil {
+		impl.logger.Errorw("error in fetching sbom document", "key", sbom.BlobKey, "err", err)
+		return nil, nil, err
+	}
+	return content, adaptSbom(sbom), nil
+}
+
+func (impl *SbomServiceImpl) SearchPackages(request *PackageSearchRequest) ([]*PackageSearchResultDto, error) {
+	constraint, err := ParseVersionConstraint(request.Version)
+	if err != nil {
+		return nil, badRequest(err.Error())
+	}
+	matches, err := impl.sbomRepository.FindPackagesByName(request.Name)
+	if err != nil {
+		impl.logger.Errorw("error in searching sbom packages", "name", request.Name, "err", err)
+		return nil, err
+	}
+	matchesByImage := make(map[string][]*sbomRepository.SbomPackageMatch)
+	var images []string
+	for _, match := range matches {
+		if !constraint.Matches(match.Version) {
+			continue
+		}
+		if _, ok := matchesByImage[match.Image]; !ok {
+			images = append(images, match.Image)
+		}
+		matchesByImage[match.Image] = append(matchesByImage[match.Image], match)
+	}
+	results := make([]*PackageSearchResultDto, 0)
+	if len(images) == 0 {
+		return results, nil
+	}
+	artifacts, err := impl.ciArtifactRepository.FindCiArtifactByImagePaths(images)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci artifacts by images", "err", err)
+		return nil, err
+	}
+	deployedImages, err := impl.sbomRepository.FindDeployedImages(images, undeployedStatuses)
+	if err != nil {
+		impl.logger.Errorw("error in fetching deployed images", "err", err)
+		return nil, err
+	}
+	deploymentsByArtifact := make(map[int][]*DeploymentDto)
+	for _, deployedImage := range deployedImages {
+		deploymentsByArtifact[deployedImage.CiArtifactId] = append(deploymentsByArtifact[deployedImage.CiArtifactId], &DeploymentDto{
+			CdPipelineId:    deployedImage.CdPipelineId,
+			AppId:           deployedImage.AppId,
+			AppName:         deployedImage.AppName,
+			EnvironmentId:   deployedImage.EnvironmentId,
+			EnvironmentName: deployedImage.EnvironmentName,
+			DeployedOn:      deployedImage.DeployedOn,
+		})
+	}
+	for _, artifact := range artifacts {
+		deployments := deploymentsByArtifact[artifact.Id]
+		if request.DeployedOnly && len(deployments) == 0 {
+			continue
+		}
+		if deployments == nil {
+			deployments = make([]*DeploymentDto, 0)
+		}
+		for _, match := range matchesByImage[artifact.Image] {
+			results = append(results, &PackageSearchResultDto{
+				CiArtifactId:   artifact.Id,
+				CiPipelineId:   artifact.PipelineId,
+				Image:          artifact.Image,
+				PackageName:    match.Name,
+				PackageGroup:   match.Group,
+				PackageVersion: match.Version,
+				Purl:           match.Purl,
+				Deployments:    deployments,
+			})
+		}
+	}
+	return results, nil
+}
+
+// resolveArtifactImage fills the image of the request from its ci artifact, or checks the ci pipeline building the
+// image exists if the artifact is not given
+func (impl *SbomServiceImpl) resolveArtifactImage(request *IngestSbomRequest) error {
+	if request.CiArtifactId > 0 {
+		artifact, err := impl.ciArtifactRepository.Get(request.CiArtifactId)
+		if err == pg.ErrNoRows {
+			return notFound(fmt.Sprintf("ci artifact %d not found", request.CiArtifactId))
+		} else if err != nil {
+			impl.logger.Errorw("error in fetching ci artifact", "ciArtifactId", request.CiArtifactId, "err", err)
+			return err
+		}
+		request.Image = artifact.Image
+		request.ImageDigest = artifact.ImageDigest
+		request.CiPipelineId = artifact.PipelineId
+		return nil
+	}
+	if len(request.Image) == 0 || request.CiPipelineId == 0 {
+		return badRequest("either ci artifact or ci pipeline and image are required")
+	}
+	_, err := impl.ciPipelineRepository.FindById(request.CiPipelineId)
+	if err == pg.ErrNoRows {
+		return notFound(fmt.Sprintf("ci pipeline %d not found", request.CiPipelineId))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching ci pipeline", "ciPipelineId", request.CiPipelineId, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *SbomServiceImpl) getSbomByCiArtifactId(ciArtifactId int) (*sbomRepository.CiArtifactSbom, error) {
+	artifact, err := impl.ciArtifactRepository.Get(ciArtifactId)
+	if err == pg.ErrNoRows {
+		return nil, notFound(fmt.Sprintf("ci artifact %d not found", ciArtifactId))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching ci artifact", "ciArtifactId", ciArtifactId, "err", err)
+		return nil, err
+	}
+	sbom, err := impl.sbomRepository.FindActiveByImage(artifact.Image)
+	if err == pg.ErrNoRows {
+		return nil, notFound(fmt.Sprintf("no SBOM found for ci artifact %d", ciArtifactId))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching sbom", "image", artifact.Image, "err", err)
+		return nil, err
+	}
+	return sbom, nil
+}
+
+func (impl *SbomServiceImpl) getBlobKey(ciPipelineId int, image string, format string) string {
+	imageHash := sha256.Sum256([]byte(image))
+	return fmt.Sprintf("%s/%d/%s-%d.%s.json", impl.config.BlobKeyPrefix, ciPipelineId,
+		hex.EncodeToString(imageHash[:8]), time.Now().Unix(), strings.ToLower(format))
+}
+
+func adaptSbom(sbom *sbomRepository.CiArtifactSbom) *SbomDto {
+	return &SbomDto{
+		Id:           sbom.Id,
+		CiPipelineId: sbom.CiPipelineId,
+		Image:        sbom.Image,
+		ImageDigest:  sbom.ImageDigest,
+		Format:       sbom.Format,
+		SpecVersion:  sbom.SpecVersion,
+		Tool:         sbom.Tool,
+		PackageCount: sbom.PackageCount,
+		Stored:       len(sbom.BlobKey) > 0,
+		CreatedOn:    sbom.CreatedOn,
+	}
+}
+
+func badRequest(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: message, UserMessage: message}
+}
+
+func notFound(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: message, UserMessage: message}
+}
@@ -0,0 +1,171 @@
+package sbom
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type versionCondition struct {
+	operator string
+	version  string
+}
+
+// VersionConstraint is a conjunction of version conditions like ">=2.0.0,<2.17.1"
+type VersionConstraint []*versionCondition
+
+var versionOperators = []string{"<=", ">=", "!=", "==", "<", ">", "="}
+
+func ParseVersionConstraint(constraint string) (VersionConstraint, error) {
+	var result VersionConstraint
+	for _, condition := range strings.Split(constraint, ",") {
+		condition = strings.TrimSpace(condition)
+		if len(condition) == 0 {
+			continue
+		}
+		operator := "="
+		for _, versionOperator := range versionOperators {
+			if strings.HasPrefix(condition, versionOperator) {
+				operator = versionOperator
+				condition = strings.TrimSpace(strings.TrimPrefix(condition, versionOperator))
+				break
+			}
+		}
+		if operator == "==" {
+			operator = "="
+		}
+		if len(condition) == 0 {
+			return nil, fmt.Errorf("version missing in constraint %q", constraint)
+		}
+		result = append(result, &versionCondition{operator: operator, version: condition})
+	}
+	return result, nil
+}
+
+// Matches reports whether the version satisfies every condition of the constraint, an empty version never matches a
+// non-empty constraint as nothing can be said about it
+func (constraint VersionConstraint) Matches(version string) bool {
+	if len(constraint) > 0 && len(version) == 0 {
+		return false
+	}
+	for _, condition := range constraint {
+		comparison := CompareVersions(version, condition.version)
+		var ok bool
+		switch condition.operator {
+		case "<":
+			ok = comparison < 0
+		case "<=":
+			ok = comparison <= 0
+		case ">":
+			ok = comparison > 0
+		case ">=":
+			ok = comparison >= 0
+		case "!=":
+			ok = comparison != 0
+		default:
+			ok = comparison == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareVersions compares versions of any ecosystem segment by segment, numeric segments numerically and others
+// lexically. A release is greater than its pre-releases, so 2.17.0 > 2.17.0-rc1, and missing numeric segments count
+// as 0, so 2.17 == 2.17.0.
+func CompareVersions(a, b string) int {
+	aSegments, bSegments := splitVersion(a), splitVersion(b)
+	for i := 0; i < len(aSegments) || i < len(bSegments); i++ {
+		var aSegment, bSegment string
+		if i < len(aSegments) {
+			aSegment = aSegments[i]
+		}
+		if i < len(bSegments) {
+			bSegment = bSegments[i]
+		}
+		if comparison := compareVersionSegments(aSegment, bSegment); comparison != 0 {
+			return comparison
+		}
+	}
+	return 0
+}
+
+func compareVersionSegments(a, b string) int {
+	aNumeric, bNumeric := isNumeric(a), isNumeric(b)
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		if bNumeric {
+			return compareNumeric("0", b)
+		}
+		return 1
+	case len(b) == 0:
+		if aNumeric {
+			return compareNumeric(a, "0")
+		}
+		return -1
+	case aNumeric && bNumeric:
+		return compareNumeric(a, b)
+	case aNumeric:
+		return 1
+	case bNumeric:
+		return -1
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// compareNumeric compares numeric strings of any length
+func compareNumeric(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// splitVersion splits the version on separators and on boundaries between digits and letters, 1.0.0-rc1 giving
+// [1 0 0 rc 1]
+func splitVersion(version string) []string {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	var segments []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			segments = append(segments, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range version {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if current.Len() > 0 {
+			last := []rune(current.String())
+			if unicode.IsDigit(last[len(last)-1]) != unicode.IsDigit(r) {
+				flush()
+			}
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return segments
+}
+
+func isNumeric(segment string) bool {
+	if len(segment) == 0 {
+		return false
+	}
+	for _, r := range segment {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
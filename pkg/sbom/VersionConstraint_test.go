@@ -0,0 +1,54 @@
+package sbom
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "2.14.1", b: "2.17", want: -1},
+		{a: "2.17", b: "2.17.0", want: 0},
+		{a: "2.17.1", b: "2.17", want: 1},
+		{a: "2.17.0-rc1", b: "2.17.0", want: -1},
+		{a: "2.17.0-rc2", b: "2.17.0-rc10", want: -1},
+		{a: "1.10", b: "1.9", want: 1},
+		{a: "v1.2.3", b: "1.2.3", want: 0},
+		{a: "1:2.36-9+deb12u4", b: "1:2.36-9+deb12u3", want: 1},
+		{a: "2.0.0-beta", b: "2.0.0-alpha", want: 1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestVersionConstraintMatches(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{constraint: "<2.17", version: "2.14.1", want: true},
+		{constraint: "<2.17", version: "2.17.0", want: false},
+		{constraint: ">=2.0.0,<2.17.1", version: "2.17.0", want: true},
+		{constraint: ">=2.0.0,<2.17.1", version: "1.2.17", want: false},
+		{constraint: "2.14.1", version: "2.14.1", want: true},
+		{constraint: "!=2.14.1", version: "2.14.1", want: false},
+		{constraint: "<2.17", version: "", want: false},
+		{constraint: "", version: "", want: true},
+	}
+	for _, tt := range tests {
+		constraint, err := ParseVersionConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseVersionConstraint(%q) error = %v", tt.constraint, err)
+		}
+		if got := constraint.Matches(tt.version); got != tt.want {
+			t.Errorf("%q.Matches(%q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+	if _, err := ParseVersionConstraint("<2.17,>="); err == nil {
+		t.Errorf("ParseVersionConstraint() error = nil for a condition without version")
+	}
+}
@@ -0,0 +1,78 @@
+package sbom
+
+import "time"
+
+const (
+	SBOM_FORMAT_SPDX      = "SPDX"
+	SBOM_FORMAT_CYCLONEDX = "CYCLONEDX"
+)
+
+// SbomDocument is the format agnostic content of an SBOM document
+type SbomDocument struct {
+	Format      string
+	SpecVersion string
+	Tool        string
+	Packages    []*SbomPackage
+}
+
+type SbomPackage struct {
+	Name    string `json:"name"`
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version"`
+	Type    string `json:"type,omitempty"`
+	Purl    string `json:"purl,omitempty"`
+	License string `json:"license,omitempty"`
+}
+
+// IngestSbomRequest ingests the SBOM document of a ci artifact, identified either by CiArtifactId or by the image built
+// by the ci pipeline, the artifact of the image may not exist yet when the SBOM is generated in post build stage
+type IngestSbomRequest struct {
+	CiArtifactId int
+	CiPipelineId int
+	Image        string
+	ImageDigest  string
+	Document     []byte
+	UserId       int32
+}
+
+type SbomDto struct {
+	Id           int            `json:"id"`
+	CiPipelineId int            `json:"ciPipelineId"`
+	Image        string         `json:"image"`
+	ImageDigest  string         `json:"imageDigest,omitempty"`
+	Format       string         `json:"format"`
+	SpecVersion  string         `json:"specVersion,omitempty"`
+	Tool         string         `json:"tool,omitempty"`
+	PackageCount int            `json:"packageCount"`
+	Stored       bool           `json:"stored"`
+	CreatedOn    time.Time      `json:"createdOn"`
+	Packages     []*SbomPackage `json:"packages,omitempty"`
+}
+
+// PackageSearchRequest searches the ci artifacts containing a package, Version being a constraint like "<2.17" or
+// ">=2.0.0,<2.17.1", all versions match if it is empty
+type PackageSearchRequest struct {
+	Name         string
+	Version      string
+	DeployedOnly bool
+}
+
+type PackageSearchResultDto struct {
+	CiArtifactId   int              `json:"ciArtifactId"`
+	CiPipelineId   int              `json:"ciPipelineId"`
+	Image          string           `json:"image"`
+	PackageName    string           `json:"packageName"`
+	PackageGroup   string           `json:"packageGroup,omitempty"`
+	PackageVersion string           `json:"packageVersion"`
+	Purl           string           `json:"purl,omitempty"`
+	Deployments    []*DeploymentDto `json:"deployments"`
+}
+
+type DeploymentDto struct {
+	CdPipelineId    int       `json:"cdPipelineId"`
+	AppId           int       `json:"appId"`
+	AppName         string    `json:"appName"`
+	EnvironmentId   int       `json:"environmentId"`
+	EnvironmentName string    `json:"environmentName"`
+	DeployedOn      time.Time `json:"deployedOn"`
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// CiArtifactSbom is the SBOM of an image, the ci artifacts of the image share it. Only the latest ingested SBOM of an
+// image is active.
+type CiArtifactSbom struct {
+	tableName    struct{} `sql:"ci_artifact_sbom" pg:",discard_unknown_columns"`
+	Id           int      `sql:"id,pk"`
+	CiPipelineId int      `sql:"ci_pipeline_id"`
+	Image        string   `sql:"image,notnull"`
+	ImageDigest  string   `sql:"image_digest"`
+	Format       string   `sql:"format,notnull"`
+	SpecVersion  string   `sql:"spec_version"`
+	Tool         string   `sql:"tool"`
+	BlobKey      string   `sql:"blob_key"`
+	PackageCount int      `sql:"package_count,notnull"`
+	Active       bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type CiArtifactSbomPackage struct {
+	tableName struct{} `sql:"ci_artifact_sbom_package" pg:",discard_unknown_columns"`
+	Id        int      `sql:"id,pk"`
+	SbomId    int      `sql:"sbom_id,notnull"`
+	Name      string   `sql:"name,notnull"`
+	Group     string   `sql:"package_group"`
+	Version   string   `sql:"version"`
+	Type      string   `sql:"type"`
+	Purl      string   `sql:"purl"`
+	License   string   `sql:"license"`
+}
+
+// SbomPackageMatch is a package of an active SBOM along with the image the SBOM belongs to
+type SbomPackageMatch struct {
+	SbomId       int    `sql:"sbom_id"`
+	CiPipelineId int    `sql:"ci_pipeline_id"`
+	Image        string `sql:"image"`
+	Name         string `sql:"name"`
+	Group        string `sql:"package_group"`
+	Version      string `sql:"version"`
+	Type         string `sql:"type"`
+	Purl         string `sql:"purl"`
+}
+
+// DeployedImage is the image last deployed by a cd pipeline
+type DeployedImage struct {
+	CdPipelineId    int       `sql:"cd_pipeline_id"`
+	AppId           int       `sql:"app_id"`
+	AppName         string    `sql:"app_name"`
+	EnvironmentId   int       `sql:"environment_id"`
+	EnvironmentName string    `sql:"environment_name"`
+	CiArtifactId    int       `sql:"ci_artifact_id"`
+	Image           string    `sql:"image"`
+	DeployedOn      time.Time `sql:"deployed_on"`
+}
+
+type SbomRepository interface {
+	// Save saves the SBOM with its packages, deactivating the SBOM previously active for the image
+	Save(sbom *CiArtifactSbom, packages []*CiArtifactSbomPackage) error
+	FindActiveByImage(image string) (*CiArtifactSbom, error)
+	FindPackagesBySbomId(sbomId int) ([]*CiArtifactSbomPackage, error)
+	// FindPackagesByName finds the packages of active SBOMs by case-insensitive name
+	FindPackagesByName(name string) ([]*SbomPackageMatch, error)
+	// FindDeployedImages returns, for every active cd pipeline whose last deployment is of one of the images, that image
+	FindDeployedImages(images []string, excludedStatuses []string) ([]*DeployedImage, error)
+}
+
+type SbomRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewSbomRepositoryImpl(dbConnection *pg.DB) *SbomRepositoryImpl {
+	return &SbomRepositoryImpl{dbConnection: dbConnection}
+}
+
+const sbomPackageInsertBatchSize = 1000
+
+func (impl *SbomRepositoryImpl) Save(sbom *CiArtifactSbom, packages []*CiArtifactSbomPackage) error {
+	tx, err := impl.dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	// packages of the replaced SBOM are not queried anymore, only the SBOM row is kept for audit
+	_, err = tx.Exec("DELETE FROM ci_artifact_sbom_package WHERE sbom_id IN (SELECT id FROM ci_artifact_sbom WHERE image = ? AND active = true);", sbom.Image)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Model(&CiArtifactSbom{}).
+		Set("active = ?", false).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", sbom.UpdatedBy).
+		Where("image = ?", sbom.Image).
+		Where("active = ?", true).
+		Update()
+	if err != nil {
+		return err
+	}
+	err = tx.Insert(sbom)
+	if err != nil {
+		return err
+	}
+	for _, pkg := range packages {
+		pkg.SbomId = sbom.Id
+	}
+	for start := 0; start < len(packages); start += sbomPackageInsertBatchSize {
+		end := start + sbomPackageInsertBatchSize
+		if end > len(packages) {
+			end = len(packages)
+		}
+		batch := packages[start:end]
+		_, err = tx.Model(&batch).Insert()
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (impl *SbomRepositoryImpl) FindActiveByImage(image string) (*CiArtifactSbom, error) {
+	sbom := &CiArtifactSbom{}
+	err := impl.dbConnection.Model(sbom).
+		Where("image = ?", image).
+		Where("active = ?", true).
+		Select()
+	return sbom, err
+}
+
+func (impl *SbomRepositoryImpl) FindPackagesBySbomId(sbomId int) ([]*CiArtifactSbomPackage, error) {
+	var packages []*CiArtifactSbomPackage
+	err := impl.dbConnection.Model(&packages).
+		Where("sbom_id = ?", sbomId).
+		Order("name ASC").
+		Select()
+	return packages, err
+}
+
+func (impl *SbomRepositoryImpl) FindPackagesByName(name string) ([]*SbomPackageMatch, error) {
+	var matches []*SbomPackageMatch
+	query := "SELECT s.id AS sbom_id, s.ci_pipeline_id, s.image, p.name, p.package_group, p.version, p.type, p.purl" +
+		" FROM ci_artifact_sbom_package p" +
+		" INNER JOIN ci_artifact_sbom s ON s.id = p.sbom_id" +
+		" WHERE s.active = true AND lower(p.name) = lower(?)" +
+		" ORDER BY s.id DESC;"
+	_, err := impl.dbConnection.Query(&matches, query, name)
+	return matches, err
+}
+
+func (impl *SbomRepositoryImpl) FindDeployedImages(images []string, excludedStatuses []string) ([]*DeployedImage, error) {
+	var deployedImages []*DeployedImage
+	if len(images) == 0 {
+		return deployedImages, nil
+	}
+	query := "SELECT * FROM (" +
+		" SELECT DISTINCT ON (cw.pipeline_id) cw.pipeline_id AS cd_pipeline_id, p.app_id, a.app_name, p.environment_id," +
+		" e.environment_name, ca.id AS ci_artifact_id, ca.image, cwr.started_on AS deployed_on" +
+		" FROM cd_workflow_runner cwr" +
+		" INNER JOIN cd_workflow cw ON cw.id = cwr.cd_workflow_id" +
+		" INNER JOIN pipeline p ON p.id = cw.pipeline_id AND p.deleted = false" +
+		" INNER JOIN app a ON a.id = p.app_id" +
+		" INNER JOIN environment e ON e.id = p.environment_id" +
+		" INNER JOIN ci_artifact ca ON ca.id = cw.ci_artifact_id" +
+		" WHERE cwr.workflow_type = 'DEPLOY' AND cwr.status NOT IN (?)" +
+		" ORDER BY cw.pipeline_id, cwr.id DESC" +
+		" ) deployed WHERE deployed.image IN (?);"
+	_, err := impl.dbConnection.Query(&deployedImages, query, pg.In(excludedStatuses), pg.In(images))
+	return deployedImages, err
+}
@@ -0,0 +1,127 @@
+package testAnalytics
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// maxMessageLength bounds the failure message stored for a test case, stack traces can be long
+const maxMessageLength = 2000
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:""`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Suites    []junitTestSuite `xml:"testsuite"`
+	TestCases []junitTestCase  `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+	Skipped   *junitMessage `xml:"skipped"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type testCase struct {
+	suiteName       string
+	className       string
+	name            string
+	status          string
+	durationSeconds float64
+	message         string
+}
+
+// key identifies the test across builds, suite names are left out as some frameworks put hosts or timestamps in them
+func (t *testCase) key() string {
+	if len(t.className) > 0 {
+		return t.className + "." + t.name
+	}
+	if len(t.suiteName) > 0 {
+		return t.suiteName + "." + t.name
+	}
+	return t.name
+}
+
+// parseJUnitReport returns the test cases of a junit xml report, whose root is either testsuites or a testsuite
+func parseJUnitReport(report string) ([]*testCase, error) {
+	root := &junitTestSuites{}
+	err := xml.Unmarshal([]byte(report), root)
+	if err != nil {
+		return nil, err
+	}
+	suites := root.Suites
+	if root.XMLName.Local == "testsuite" {
+		suite := junitTestSuite{}
+		err = xml.Unmarshal([]byte(report), &suite)
+		if err != nil {
+			return nil, err
+		}
+		suites = []junitTestSuite{suite}
+	}
+	var testCases []*testCase
+	for _, suite := range suites {
+		testCases = appendTestCases(testCases, suite)
+	}
+	return testCases, nil
+}
+
+func appendTestCases(testCases []*testCase, suite junitTestSuite) []*testCase {
+	for _, junitCase := range suite.TestCases {
+		if len(junitCase.Name) == 0 {
+			continue
+		}
+		t := &testCase{
+			suiteName: suite.Name,
+			className: junitCase.ClassName,
+			name:      junitCase.Name,
+			status:    TEST_STATUS_PASSED,
+		}
+		if duration, err := strconv.ParseFloat(strings.ReplaceAll(junitCase.Time, ",", ""), 64); err == nil {
+			t.durationSeconds = duration
+		}
+		if failure := firstNonNil(junitCase.Failure, junitCase.Error); failure != nil {
+			t.status = TEST_STATUS_FAILED
+			t.message = failure.text()
+		} else if junitCase.Skipped != nil {
+			t.status = TEST_STATUS_SKIPPED
+			t.message = junitCase.Skipped.text()
+		}
+		testCases = append(testCases, t)
+	}
+	for _, nested := range suite.Suites {
+		testCases = appendTestCases(testCases, nested)
+	}
+	return testCases
+}
+
+func firstNonNil(messages ...*junitMessage) *junitMessage {
+	for _, message := range messages {
+		if message != nil {
+			return message
+		}
+	}
+	return nil
+}
+
+func (m *junitMessage) text() string {
+	text := strings.TrimSpace(m.Message)
+	if len(text) == 0 {
+		text = strings.TrimSpace(m.Text)
+	}
+	if len(text) > maxMessageLength {
+		text = text[:maxMessageLength]
+	}
+	return text
+}
@@ -0,0 +1,56 @@
+package testAnalytics
+
+import "testing"
+
+func TestParseJUnitReport(t *testing.T) {
+	report := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="api-suite">
+    <testcase classname="com.example.ApiTest" name="getsUser" time="1,200.5"/>
+    <testcase classname="com.example.ApiTest" name="savesUser" time="0.3">
+      <failure message="expected 200 but was 500">stack trace</failure>
+    </testcase>
+    <testsuite name="nested">
+      <testcase name="retries" time="2">
+        <error>connection refused</error>
+      </testcase>
+      <testcase classname="com.example.SlowTest" name="runsLong">
+        <skipped/>
+      </testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+	testCases, err := parseJUnitReport(report)
+	if err != nil {
+		t.Fatalf("parseJUnitReport() err = %v", err)
+	}
+	want := []struct {
+		key      string
+		status   string
+		duration float64
+		message  string
+	}{
+		{"com.example.ApiTest.getsUser", TEST_STATUS_PASSED, 1200.5, ""},
+		{"com.example.ApiTest.savesUser", TEST_STATUS_FAILED, 0.3, "expected 200 but was 500"},
+		{"nested.retries", TEST_STATUS_FAILED, 2, "connection refused"},
+		{"com.example.SlowTest.runsLong", TEST_STATUS_SKIPPED, 0, ""},
+	}
+	if len(testCases) != len(want) {
+		t.Fatalf("parseJUnitReport() = %d test cases, want %d", len(testCases), len(want))
+	}
+	for i, w := range want {
+		got := testCases[i]
+		if got.key() != w.key || got.status != w.status || got.durationSeconds != w.duration || got.message != w.message {
+			t.Errorf("parseJUnitReport() test case %d = %s %s %v %q, want %+v", i, got.key(), got.status, got.durationSeconds, got.message, w)
+		}
+	}
+
+	testCases, err = parseJUnitReport(`<testsuite name="single"><testcase classname="pkg" name="works"/></testsuite>`)
+	if err != nil || len(testCases) != 1 || testCases[0].key() != "pkg.works" || testCases[0].suiteName != "single" {
+		t.Errorf("parseJUnitReport() single suite = %+v, err %v", testCases, err)
+	}
+
+	if _, err = parseJUnitReport("not xml"); err == nil {
+		t.Errorf("parseJUnitReport() of invalid report did not fail")
+	}
+}
@@ -0,0 +1,519 @@
+package testAnalytics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	testAnalyticsRepository "github.com/devtron-labs/devtron/pkg/testAnalytics/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type TestAnalyticsConfig struct {
+	// IngestSucceededBuilds makes the test reports of succeeded builds ingested too, flakiness cannot be detected
+	// from failed builds only
+	IngestSucceededBuilds bool `env:"TEST_ANALYTICS_INGEST_SUCCEEDED_BUILDS" envDefault:"true"`
+	DefaultWindowDays     int  `env:"TEST_ANALYTICS_WINDOW_DAYS" envDefault:"30"`
+	ResultRetentionDays   int  `env:"TEST_ANALYTICS_RESULT_RETENTION_DAYS" envDefault:"90"`
+}
+
+const (
+	maxWindowDays       = 180
+	defaultTopFailing   = 20
+	maxTopFailing       = 100
+	maxTestKeyLength    = 1000
+	maxQuarantineReason = 500
+)
+
+type TestAnalyticsService interface {
+	// IngestTestReports stores the test case results of the junit reports of the ci workflow, replacing the ones
+	// already stored for it. It returns true if tests failed and all of the failed ones are quarantined
+	IngestTestReports(request *IngestTestReportsRequest) (bool, error)
+	IngestsSucceededBuilds() bool
+	// GetQuarantineEnvVariables returns the environment variables listing the quarantined tests of the ci pipeline,
+	// none if no test is quarantined
+	GetQuarantineEnvVariables(ciPipelineId int) (map[string]string, error)
+
+	GetFlakyTests(ciPipelineId int, days int) ([]*FlakyTestDto, error)
+	GetTopFailingTests(ciPipelineId int, days int, limit int) ([]*FailingTestDto, error)
+	GetTestTrend(ciPipelineId int, testKey string, days int) (*TestTrendDto, error)
+	GetBuildTrend(ciPipelineId int, days int) ([]*BuildTestSummaryDto, error)
+
+	QuarantineTest(request *TestQuarantineDto) (*TestQuarantineDto, error)
+	RemoveQuarantine(ciPipelineId int, id int, userId int32) error
+	GetQuarantinedTests(ciPipelineId int) ([]*TestQuarantineDto, error)
+}
+
+type TestAnalyticsServiceImpl struct {
+	logger                  *zap.SugaredLogger
+	testAnalyticsRepository testAnalyticsRepository.TestAnalyticsRepository
+	ciWorkflowRepository    pipelineConfig.CiWorkflowRepository
+	ciPipelineRepository    pipelineConfig.CiPipelineRepository
+	config                  *TestAnalyticsConfig
+}
+
+func NewTestAnalyticsServiceImpl(logger *zap.SugaredLogger,
+	testAnalyticsRepository testAnalyticsRepository.TestAnalyticsRepository,
+	ciWorkflowRepository pipelineConfig.CiWorkflowRepository,
+	ciPipelineRepository pipelineConfig.CiPipelineRepository) *TestAnalyticsServiceImpl {
+	cfg := &TestAnalyticsConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Infow("error occurred while parsing TestAnalyticsConfig, so setting test analytics config to default values", "err", err)
+		cfg.IngestSucceededBuilds = true
+		cfg.DefaultWindowDays = 30
+		cfg.ResultRetentionDays = 90
+	}
+	return &TestAnalyticsServiceImpl{
+		logger:                  logger,
+		testAnalyticsRepository: testAnalyticsRepository,
+		ciWorkflowRepository:    ciWorkflowRepository,
+		ciPipelineRepository:    ciPipelineRepository,
+		config:                  cfg,
+	}
+}
+
+func (impl *TestAnalyticsServiceImpl) IngestsSucceededBuilds() bool {
+	return impl.config.IngestSucceededBuilds
+}
+
+func (impl *TestAnalyticsServiceImpl) IngestTestReports(request *IngestTestReportsRequest) (bool, error) {
+	ciWorkflow, err := impl.ciWorkflowRepository.FindById(request.CiWorkflowId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci workflow", "ciWorkflowId", request.CiWorkflowId, "err", err)
+		return false, err
+	}
+	quarantinedKeys, err := impl.getQuarantinedKeys(request.CiPipelineId)
+	if err != nil {
+		return false, err
+	}
+	commitKey := getCommitKey(ciWorkflow)
+	now := time.Now()
+	var results []*testAnalyticsRepository.TestCaseResult
+	for _, report := range request.Reports {
+		testCases, err := parseJUnitReport(report)
+		if err != nil {
+			// reports of other formats may be among the xml files of the artifacts
+			impl.logger.Warnw("skipping test report which is not junit xml", "ciWorkflowId", request.CiWorkflowId, "err", err)
+			continue
+		}
+		for _, t := range testCases {
+			testKey := t.key()
+			if len(testKey) > maxTestKeyLength {
+				testKey = testKey[:maxTestKeyLength]
+			}
+			results = append(results, &testAnalyticsRepository.TestCaseResult{
+				CiPipelineId:    request.CiPipelineId,
+				CiWorkflowId:    request.CiWorkflowId,
+				CommitKey:       commitKey,
+				TestKey:         testKey,
+				SuiteName:       t.suiteName,
+				ClassName:       t.className,
+				TestName:        t.name,
+				Status:          t.status,
+				DurationSeconds: t.durationSeconds,
+				Message:         t.message,
+				Quarantined:     quarantinedKeys[testKey],
+				CreatedOn:       now,
+			})
+		}
+	}
+	dbConnection := impl.testAnalyticsRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return false, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = impl.testAnalyticsRepository.DeleteResultsByCiWorkflowId(request.CiWorkflowId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deleting test case results", "ciWorkflowId", request.CiWorkflowId, "err", err)
+		return false, err
+	}
+	err = impl.testAnalyticsRepository.SaveResults(results, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving test case results", "ciWorkflowId", request.CiWorkflowId, "err", err)
+		return false, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	_, err = impl.testAnalyticsRepository.DeleteResultsBefore(request.CiPipelineId, now.AddDate(0, 0, -impl.config.ResultRetentionDays))
+	if err != nil {
+		impl.logger.Errorw("error in deleting test case results past retention", "ciPipelineId", request.CiPipelineId, "err", err)
+	}
+	impl.logger.Infow("ingested test reports", "ciWorkflowId", request.CiWorkflowId, "reports", len(request.Reports), "testCases", len(results))
+	return areFailuresQuarantined(results), nil
+}
+
+// areFailuresQuarantined reports whether some of the test cases failed and all of the failed ones are quarantined
+func areFailuresQuarantined(results []*testAnalyticsRepository.TestCaseResult) bool {
+	failures := 0
+	for _, result := range results {
+		if result.Status != TEST_STATUS_FAILED {
+			continue
+		}
+		if !result.Quarantined {
+			return false
+		}
+		failures++
+	}
+	return failures > 0
+}
+
+// getCommitKey joins the commits the workflow built by material, workflows without commits get a key of their own
+func getCommitKey(ciWorkflow *pipelineConfig.CiWorkflow) string {
+	materialIds := make([]int, 0, len(ciWorkflow.GitTriggers))
+	for materialId, gitCommit := range ciWorkflow.GitTriggers {
+		if len(gitCommit.Commit) > 0 {
+			materialIds = append(materialIds, materialId)
+		}
+	}
+	if len(materialIds) == 0 {
+		return "workflow-" + strconv.Itoa(ciWorkflow.Id)
+	}
+	sort.Ints(materialIds)
+	commits := make([]string, 0, len(materialIds))
+	for _, materialId := range materialIds {
+		commits = append(commits, strconv.Itoa(materialId)+":"+ciWorkflow.GitTriggers[materialId].Commit)
+	}
+	return strings.Join(commits, ",")
+}
+
+func (impl *TestAnalyticsServiceImpl) getQuarantinedKeys(ciPipelineId int) (map[string]bool, error) {
+	quarantines, err := impl.testAnalyticsRepository.FindActiveQuarantinesByCiPipelineId(ciPipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching quarantined tests", "ciPipelineId", ciPipelineId, "err", err)
+		return nil, err
+	}
+	quarantinedKeys := make(map[string]bool, len(quarantines))
+	for _, quarantine := range quarantines {
+		quarantinedKeys[quarantine.TestKey] = true
+	}
+	return quarantinedKeys, nil
+}
+
+func (impl *TestAnalyticsServiceImpl) GetQuarantineEnvVariables(ciPipelineId int) (map[string]string, error) {
+	quarantines, err := impl.testAnalyticsRepository.FindActiveQuarantinesByCiPipelineId(ciPipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching quarantined tests", "ciPipelineId", ciPipelineId, "err", err)
+		return nil, err
+	}
+	if len(quarantines) == 0 {
+		return nil, nil
+	}
+	testKeys := make([]string, 0, len(quarantines))
+	for _, quarantine := range quarantines {
+		testKeys = append(testKeys, quarantine.TestKey)
+	}
+	return map[string]string{QUARANTINED_TESTS_ENV_VARIABLE: strings.Join(testKeys, "\n")}, nil
+}
+
+func (impl *TestAnalyticsServiceImpl) getSince(days int) (time.Time, error) {
+	if days <= 0 {
+		days = impl.config.DefaultWindowDays
+	} else if days > maxWindowDays {
+		return time.Time{}, badRequest(fmt.Sprintf("at most %d days can be analysed", maxWindowDays))
+	}
+	return time.Now().AddDate(0, 0, -days), nil
+}
+
+func (impl *TestAnalyticsServiceImpl) GetFlakyTests(ciPipelineId int, days int) ([]*FlakyTestDto, error) {
+	since, err := impl.getSince(days)
+	if err != nil {
+		return nil, err
+	}
+	results, err := impl.testAnalyticsRepository.FindResultsOfFlippingTests(ciPipelineId, since, TEST_STATUS_PASSED, TEST_STATUS_FAILED)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching results of flipping tests", "ciPipelineId", ciPipelineId, "err", err)
+		return nil, err
+	}
+	quarantinedKeys, err := impl.getQuarantinedKeys(ciPipelineId)
+	if err != nil {
+		return nil, err
+	}
+	return computeFlakyTests(results, quarantinedKeys), nil
+}
+
+// computeFlakyTests scores the tests on their consecutive runs on a same commit, results are ordered by workflow and
+// skipped runs are left out
+func computeFlakyTests(results []*testAnalyticsRepository.TestCaseResult, quarantinedKeys map[string]bool) []*FlakyTestDto {
+	flakyTests := make(map[string]*FlakyTestDto)
+	pairs := make(map[string]int)
+	// lastStatuses holds the status of the last run of each test by commit
+	lastStatuses := make(map[string]map[string]string)
+	flakyCommits := make(map[string]map[string]bool)
+	var testKeys []string
+	for _, result := range results {
+		if result.Status == TEST_STATUS_SKIPPED {
+			continue
+		}
+		flakyTest, ok := flakyTests[result.TestKey]
+		if !ok {
+			flakyTest = &FlakyTestDto{
+				TestKey:     result.TestKey,
+				SuiteName:   result.SuiteName,
+				ClassName:   result.ClassName,
+				TestName:    result.TestName,
+				Quarantined: quarantinedKeys[result.TestKey],
+			}
+			flakyTests[result.TestKey] = flakyTest
+			lastStatuses[result.TestKey] = make(map[string]string)
+			flakyCommits[result.TestKey] = make(map[string]bool)
+			testKeys = append(testKeys, result.TestKey)
+		}
+		flakyTest.Runs++
+		if result.Status == TEST_STATUS_FAILED {
+			flakyTest.Failures++
+		}
+		if lastStatus, ok := lastStatuses[result.TestKey][result.CommitKey]; ok {
+			pairs[result.TestKey]++
+			if lastStatus != result.Status {
+				flakyTest.Flips++
+				flakyTest.LastFlakyWorkflowId = result.CiWorkflowId
+				flakyCommits[result.TestKey][result.CommitKey] = true
+			}
+		}
+		lastStatuses[result.TestKey][result.CommitKey] = result.Status
+	}
+	flaky := make([]*FlakyTestDto, 0)
+	for _, testKey := range testKeys {
+		flakyTest := flakyTests[testKey]
+		if flakyTest.Flips == 0 {
+			continue
+		}
+		flakyTest.FlakyCommits = len(flakyCommits[testKey])
+		flakyTest.FlakinessScore = float64(flakyTest.Flips) / float64(pairs[testKey])
+		flaky = append(flaky, flakyTest)
+	}
+	sort.SliceStable(flaky, func(i, j int) bool {
+		if flaky[i].FlakinessScore != flaky[j].FlakinessScore {
+			return flaky[i].FlakinessScore > flaky[j].FlakinessScore
+		}
+		return flaky[i].Flips > flaky[j].Flips
+	})
+	return flaky
+}
+
+func (impl *TestAnalyticsServiceImpl) GetTopFailingTests(ciPipelineId int, days int, limit int) ([]*FailingTestDto, error) {
+	since, err := impl.getSince(days)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultTopFailing
+	} else if limit > maxTopFailing {
+		return nil, badRequest(fmt.Sprintf("at most %d tests can be listed", maxTopFailing))
+	}
+	summaries, err := impl.testAnalyticsRepository.FindTopFailingTests(ciPipelineId, since, TEST_STATUS_FAILED, limit)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching top failing tests", "ciPipelineId", ciPipelineId, "err", err)
+		return nil, err
+	}
+	quarantinedKeys, err := impl.getQuarantinedKeys(ciPipelineId)
+	if err != nil {
+		return nil, err
+	}
+	failingTests := make([]*FailingTestDto, 0, len(summaries))
+	for _, summary := range summaries {
+		failingTests = append(failingTests, &FailingTestDto{
+			TestKey:              summary.TestKey,
+			SuiteName:            summary.SuiteName,
+			ClassName:            summary.ClassName,
+			TestName:             summary.TestName,
+			Runs:                 summary.Runs,
+			Failures:             summary.Failures,
+			FailureRate:          float64(summary.Failures) / float64(summary.Runs),
+			LastFailedWorkflowId: summary.LastFailedWorkflowId,
+			LastFailureMessage:   summary.LastFailureMessage,
+			Quarantined:          quarantinedKeys[summary.TestKey],
+		})
+	}
+	return failingTests, nil
+}
+
+func (impl *TestAnalyticsServiceImpl) GetTestTrend(ciPipelineId int, testKey string, days int) (*TestTrendDto, error) {
+	if len(testKey) == 0 {
+		return nil, badRequest("testKey is required")
+	}
+	since, err := impl.getSince(days)
+	if err != nil {
+		return nil, err
+	}
+	results, err := impl.testAnalyticsRepository.FindResultsByTestKey(ciPipelineId, testKey, since)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching test case results", "ciPipelineId", ciPipelineId, "testKey", testKey, "err", err)
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, notFound(fmt.Sprintf("no results of test %s in ci pipeline %d since %s", testKey, ciPipelineId, since.Format(time.RFC3339)))
+	}
+	return computeTestTrend(testKey, results), nil
+}
+
+// computeTestTrend summarises the runs of a test ordered by workflow, durations of skipped runs are left out
+func computeTestTrend(testKey string, results []*testAnalyticsRepository.TestCaseResult) *TestTrendDto {
+	trend := &TestTrendDto{TestKey: testKey, History: make([]*TestRunDto, 0, len(results))}
+	var durations []float64
+	for _, result := range results {
+		trend.History = append(trend.History, &TestRunDto{
+			CiWorkflowId:    result.CiWorkflowId,
+			CommitKey:       result.CommitKey,
+			Status:          result.Status,
+			DurationSeconds: result.DurationSeconds,
+			Message:         result.Message,
+			Quarantined:     result.Quarantined,
+			CreatedOn:       result.CreatedOn,
+		})
+		trend.Runs++
+		if result.Status == TEST_STATUS_FAILED {
+			trend.Failures++
+		}
+		if result.Status != TEST_STATUS_SKIPPED {
+			durations = append(durations, result.DurationSeconds)
+			if result.DurationSeconds > trend.MaxDurationSeconds {
+				trend.MaxDurationSeconds = result.DurationSeconds
+			}
+		}
+	}
+	trend.MeanDurationSeconds = mean(durations)
+	if len(durations) >= 2 {
+		earlierMean := mean(durations[:len(durations)/2])
+		if earlierMean > 0 {
+			trend.DurationChangePercent = (mean(durations[len(durations)/2:]) - earlierMean) / earlierMean * 100
+		}
+	}
+	return trend
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+func (impl *TestAnalyticsServiceImpl) GetBuildTrend(ciPipelineId int, days int) ([]*BuildTestSummaryDto, error) {
+	since, err := impl.getSince(days)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := impl.testAnalyticsRepository.FindWorkflowSummaries(ciPipelineId, since, TEST_STATUS_PASSED, TEST_STATUS_FAILED, TEST_STATUS_SKIPPED)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching workflow test summaries", "ciPipelineId", ciPipelineId, "err", err)
+		return nil, err
+	}
+	trend := make([]*BuildTestSummaryDto, 0, len(summaries))
+	for _, summary := range summaries {
+		trend = append(trend, &BuildTestSummaryDto{
+			CiWorkflowId:         summary.CiWorkflowId,
+			CreatedOn:            summary.CreatedOn,
+			Total:                summary.Total,
+			Passed:               summary.Passed,
+			Failed:               summary.Failed,
+			Skipped:              summary.Skipped,
+			QuarantinedFailures:  summary.QuarantinedFailures,
+			TotalDurationSeconds: summary.TotalDurationSeconds,
+		})
+	}
+	return trend, nil
+}
+
+func (impl *TestAnalyticsServiceImpl) QuarantineTest(request *TestQuarantineDto) (*TestQuarantineDto, error) {
+	if len(request.TestKey) > maxTestKeyLength {
+		return nil, badRequest(fmt.Sprintf("testKey must be at most %d characters long", maxTestKeyLength))
+	}
+	if len(request.Reason) > maxQuarantineReason {
+		return nil, badRequest(fmt.Sprintf("reason must be at most %d characters long", maxQuarantineReason))
+	}
+	_, err := impl.ciPipelineRepository.FindById(request.CiPipelineId)
+	if util.IsErrNoRows(err) {
+		return nil, notFound(fmt.Sprintf("ci pipeline %d not found", request.CiPipelineId))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching ci pipeline", "ciPipelineId", request.CiPipelineId, "err", err)
+		return nil, err
+	}
+	quarantinedKeys, err := impl.getQuarantinedKeys(request.CiPipelineId)
+	if err != nil {
+		return nil, err
+	}
+	if quarantinedKeys[request.TestKey] {
+		return nil, badRequest(fmt.Sprintf("test %s is already quarantined", request.TestKey))
+	}
+	quarantine := &testAnalyticsRepository.TestQuarantine{
+		CiPipelineId: request.CiPipelineId,
+		TestKey:      request.TestKey,
+		Reason:       request.Reason,
+		Active:       true,
+		AuditLog:     sql.NewDefaultAuditLog(request.UserId),
+	}
+	err = impl.testAnalyticsRepository.SaveQuarantine(quarantine)
+	if err != nil {
+		impl.logger.Errorw("error in saving test quarantine", "quarantine", quarantine, "err", err)
+		return nil, err
+	}
+	return adaptQuarantine(quarantine), nil
+}
+
+func (impl *TestAnalyticsServiceImpl) RemoveQuarantine(ciPipelineId int, id int, userId int32) error {
+	quarantine, err := impl.testAnalyticsRepository.FindActiveQuarantineById(id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching test quarantine", "id", id, "err", err)
+		return err
+	} else if err == pg.ErrNoRows || quarantine.CiPipelineId != ciPipelineId {
+		return notFound(fmt.Sprintf("quarantine %d not found in ci pipeline %d", id, ciPipelineId))
+	}
+	quarantine.Active = false
+	quarantine.UpdatedOn = time.Now()
+	quarantine.UpdatedBy = userId
+	err = impl.testAnalyticsRepository.UpdateQuarantine(quarantine)
+	if err != nil {
+		impl.logger.Errorw("error in updating test quarantine", "id", id, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl *TestAnalyticsServiceImpl) GetQuarantinedTests(ciPipelineId int) ([]*TestQuarantineDto, error) {
+	quarantines, err := impl.testAnalyticsRepository.FindActiveQuarantinesByCiPipelineId(ciPipelineId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching quarantined tests", "ciPipelineId", ciPipelineId, "err", err)
+		return nil, err
+	}
+	dtos := make([]*TestQuarantineDto, 0, len(quarantines))
+	for _, quarantine := range quarantines {
+		dtos = append(dtos, adaptQuarantine(quarantine))
+	}
+	return dtos, nil
+}
+
+func adaptQuarantine(quarantine *testAnalyticsRepository.TestQuarantine) *TestQuarantineDto {
+	return &TestQuarantineDto{
+		Id:           quarantine.Id,
+		CiPipelineId: quarantine.CiPipelineId,
+		TestKey:      quarantine.TestKey,
+		Reason:       quarantine.Reason,
+		CreatedOn:    quarantine.CreatedOn,
+	}
+}
+
+func badRequest(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: message, UserMessage: message}
+}
+
+func notFound(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: message, UserMessage: message}
+}
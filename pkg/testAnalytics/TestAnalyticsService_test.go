@@ -0,0 +1,98 @@
+package testAnalytics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	testAnalyticsRepository "github.com/devtron-labs/devtron/pkg/testAnalytics/repository"
+)
+
+func result(workflowId int, commitKey string, testKey string, status string, duration float64) *testAnalyticsRepository.TestCaseResult {
+	return &testAnalyticsRepository.TestCaseResult{CiWorkflowId: workflowId, CommitKey: commitKey, TestKey: testKey, TestName: testKey,
+		Status: status, DurationSeconds: duration}
+}
+
+func TestComputeFlakyTests(t *testing.T) {
+	results := []*testAnalyticsRepository.TestCaseResult{
+		result(1, "a", "flaky", TEST_STATUS_FAILED, 1),
+		result(1, "a", "broken", TEST_STATUS_FAILED, 1),
+		result(2, "a", "flaky", TEST_STATUS_PASSED, 1),
+		result(2, "a", "broken", TEST_STATUS_PASSED, 1),
+		result(3, "b", "flaky", TEST_STATUS_PASSED, 1),
+		result(3, "b", "broken", TEST_STATUS_FAILED, 1),
+		result(4, "a", "flaky", TEST_STATUS_SKIPPED, 1),
+		result(5, "a", "flaky", TEST_STATUS_FAILED, 1),
+		result(5, "b", "broken", TEST_STATUS_FAILED, 1),
+		result(6, "a", "broken", TEST_STATUS_PASSED, 1),
+	}
+	flaky := computeFlakyTests(results, map[string]bool{"broken": true})
+	if len(flaky) != 2 {
+		t.Fatalf("computeFlakyTests() = %d tests, want 2", len(flaky))
+	}
+	// flaky flipped on both of its two runs after the first on commit a
+	if f := flaky[0]; f.TestKey != "flaky" || f.Runs != 4 || f.Failures != 2 || f.Flips != 2 || f.FlakyCommits != 1 ||
+		f.FlakinessScore != 1 || f.LastFlakyWorkflowId != 5 || f.Quarantined {
+		t.Errorf("computeFlakyTests() flaky = %+v", f)
+	}
+	// broken flipped once out of its two runs after the first on commit a and kept failing on commit b
+	if f := flaky[1]; f.TestKey != "broken" || f.Flips != 1 || f.FlakyCommits != 1 || math.Abs(f.FlakinessScore-1.0/3) > 1e-9 ||
+		f.LastFlakyWorkflowId != 2 || !f.Quarantined {
+		t.Errorf("computeFlakyTests() broken = %+v", f)
+	}
+
+	stable := computeFlakyTests([]*testAnalyticsRepository.TestCaseResult{
+		result(1, "a", "stable", TEST_STATUS_FAILED, 1),
+		result(2, "b", "stable", TEST_STATUS_PASSED, 1),
+	}, nil)
+	if len(stable) != 0 {
+		t.Errorf("computeFlakyTests() across commits = %+v, want none", stable)
+	}
+}
+
+func TestComputeTestTrend(t *testing.T) {
+	trend := computeTestTrend("slow", []*testAnalyticsRepository.TestCaseResult{
+		result(1, "a", "slow", TEST_STATUS_PASSED, 2),
+		result(2, "a", "slow", TEST_STATUS_PASSED, 2),
+		result(3, "b", "slow", TEST_STATUS_SKIPPED, 0),
+		result(4, "b", "slow", TEST_STATUS_FAILED, 3),
+		result(5, "c", "slow", TEST_STATUS_PASSED, 5),
+	})
+	if trend.Runs != 5 || trend.Failures != 1 || len(trend.History) != 5 || trend.MeanDurationSeconds != 3 ||
+		trend.MaxDurationSeconds != 5 || trend.DurationChangePercent != 100 {
+		t.Errorf("computeTestTrend() = %+v", trend)
+	}
+}
+
+func TestGetCommitKey(t *testing.T) {
+	ciWorkflow := &pipelineConfig.CiWorkflow{Id: 7, GitTriggers: map[int]pipelineConfig.GitCommit{
+		12: {Commit: "def"},
+		3:  {Commit: "abc"},
+		5:  {},
+	}}
+	if key := getCommitKey(ciWorkflow); key != "3:abc,12:def" {
+		t.Errorf("getCommitKey() = %q", key)
+	}
+	if key := getCommitKey(&pipelineConfig.CiWorkflow{Id: 7}); key != "workflow-7" {
+		t.Errorf("getCommitKey() without commits = %q", key)
+	}
+}
+
+func TestAreFailuresQuarantined(t *testing.T) {
+	passed := &testAnalyticsRepository.TestCaseResult{TestKey: "a", Status: TEST_STATUS_PASSED}
+	quarantinedFailure := &testAnalyticsRepository.TestCaseResult{TestKey: "b", Status: TEST_STATUS_FAILED, Quarantined: true}
+	failure := &testAnalyticsRepository.TestCaseResult{TestKey: "c", Status: TEST_STATUS_FAILED}
+	tests := []struct {
+		results []*testAnalyticsRepository.TestCaseResult
+		want    bool
+	}{
+		{results: []*testAnalyticsRepository.TestCaseResult{passed}, want: false},
+		{results: []*testAnalyticsRepository.TestCaseResult{passed, quarantinedFailure}, want: true},
+		{results: []*testAnalyticsRepository.TestCaseResult{quarantinedFailure, failure}, want: false},
+	}
+	for i, tt := range tests {
+		if got := areFailuresQuarantined(tt.results); got != tt.want {
+			t.Errorf("case %d: areFailuresQuarantined() = %v, want %v", i, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package testAnalytics
+
+import "time"
+
+const (
+	TEST_STATUS_PASSED  = "PASSED"
+	TEST_STATUS_FAILED  = "FAILED"
+	TEST_STATUS_SKIPPED = "SKIPPED"
+)
+
+// QUARANTINED_TESTS_ENV_VARIABLE lists the quarantined tests of the ci pipeline, one test key per line, so that the
+// test steps of the build can skip them or ignore their failures
+const QUARANTINED_TESTS_ENV_VARIABLE = "QUARANTINED_TESTS"
+
+// IngestTestReportsRequest carries the junit xml reports found in the artifacts of a ci workflow
+type IngestTestReportsRequest struct {
+	CiPipelineId int
+	CiWorkflowId int
+	Reports      []string
+}
+
+// FlakyTestDto is a test which both passed and failed on the same commits, FlakinessScore is the share of its
+// consecutive runs on a same commit which flipped between passing and failing
+type FlakyTestDto struct {
+	TestKey             string  `json:"testKey"`
+	SuiteName           string  `json:"suiteName"`
+	ClassName           string  `json:"className"`
+	TestName            string  `json:"testName"`
+	Runs                int     `json:"runs"`
+	Failures            int     `json:"failures"`
+	Flips               int     `json:"flips"`
+	FlakyCommits        int     `json:"flakyCommits"`
+	FlakinessScore      float64 `json:"flakinessScore"`
+	LastFlakyWorkflowId int     `json:"lastFlakyWorkflowId"`
+	Quarantined         bool    `json:"quarantined"`
+}
+
+type FailingTestDto struct {
+	TestKey              string  `json:"testKey"`
+	SuiteName            string  `json:"suiteName"`
+	ClassName            string  `json:"className"`
+	TestName             string  `json:"testName"`
+	Runs                 int     `json:"runs"`
+	Failures             int     `json:"failures"`
+	FailureRate          float64 `json:"failureRate"`
+	LastFailedWorkflowId int     `json:"lastFailedWorkflowId"`
+	LastFailureMessage   string  `json:"lastFailureMessage"`
+	Quarantined          bool    `json:"quarantined"`
+}
+
+type TestRunDto struct {
+	CiWorkflowId    int       `json:"ciWorkflowId"`
+	CommitKey       string    `json:"commitKey"`
+	Status          string    `json:"status"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Message         string    `json:"message,omitempty"`
+	Quarantined     bool      `json:"quarantined"`
+	CreatedOn       time.Time `json:"createdOn"`
+}
+
+// TestTrendDto is the history of a test, DurationChangePercent compares the mean duration of the later half of the
+// runs with the one of the earlier half
+type TestTrendDto struct {
+	TestKey               string        `json:"testKey"`
+	Runs                  int           `json:"runs"`
+	Failures              int           `json:"failures"`
+	MeanDurationSeconds   float64       `json:"meanDurationSeconds"`
+	MaxDurationSeconds    float64       `json:"maxDurationSeconds"`
+	DurationChangePercent float64       `json:"durationChangePercent"`
+	History               []*TestRunDto `json:"history"`
+}
+
+type BuildTestSummaryDto struct {
+	CiWorkflowId         int       `json:"ciWorkflowId"`
+	CreatedOn            time.Time `json:"createdOn"`
+	Total                int       `json:"total"`
+	Passed               int       `json:"passed"`
+	Failed               int       `json:"failed"`
+	Skipped              int       `json:"skipped"`
+	QuarantinedFailures  int       `json:"quarantinedFailures"`
+	TotalDurationSeconds float64   `json:"totalDurationSeconds"`
+}
+
+type TestQuarantineDto struct {
+	Id           int       `json:"id"`
+	CiPipelineId int       `json:"ciPipelineId"`
+	TestKey      string    `json:"testKey" validate:"required"`
+	Reason       string    `json:"reason"`
+	CreatedOn    time.Time `json:"createdOn"`
+	UserId       int32     `json:"-"`
+}
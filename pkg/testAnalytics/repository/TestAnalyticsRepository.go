@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// TestCaseResult is the result of a test case in the test reports of a ci workflow, Quarantined is whether the test
+// was quarantined when the workflow ran
+type TestCaseResult struct {
+	tableName       struct{}  `sql:"test_case_result" pg:",discard_unknown_columns"`
+	Id              int       `sql:"id,pk"`
+	CiPipelineId    int       `sql:"ci_pipeline_id,notnull"`
+	CiWorkflowId    int       `sql:"ci_workflow_id,notnull"`
+	CommitKey       string    `sql:"commit_key,notnull"`
+	TestKey         string    `sql:"test_key,notnull"`
+	SuiteName       string    `sql:"suite_name"`
+	ClassName       string    `sql:"class_name"`
+	TestName        string    `sql:"test_name,notnull"`
+	Status          string    `sql:"status,notnull"`
+	DurationSeconds float64   `sql:"duration_seconds,notnull"`
+	Message         string    `sql:"message"`
+	Quarantined     bool      `sql:"quarantined,notnull"`
+	CreatedOn       time.Time `sql:"created_on,notnull"`
+}
+
+// TestQuarantine is a test of the ci pipeline known to be flaky, its failures should not fail the pipeline
+type TestQuarantine struct {
+	tableName    struct{} `sql:"test_quarantine" pg:",discard_unknown_columns"`
+	Id           int      `sql:"id,pk"`
+	CiPipelineId int      `sql:"ci_pipeline_id,notnull"`
+	TestKey      string   `sql:"test_key,notnull"`
+	Reason       string   `sql:"reason"`
+	Active       bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type TestFailureSummary struct {
+	TestKey              string `sql:"test_key"`
+	SuiteName            string `sql:"suite_name"`
+	ClassName            string `sql:"class_name"`
+	TestName             string `sql:"test_name"`
+	Runs                 int    `sql:"runs"`
+	Failures             int    `sql:"failures"`
+	LastFailedWorkflowId int    `sql:"last_failed_workflow_id"`
+	LastFailureMessage   string `sql:"last_failure_message"`
+}
+
+type WorkflowTestSummary struct {
+	CiWorkflowId         int       `sql:"ci_workflow_id"`
+	CreatedOn            time.Time `sql:"created_on"`
+	Total                int       `sql:"total"`
+	Passed               int       `sql:"passed"`
+	Failed               int       `sql:"failed"`
+	Skipped              int       `sql:"skipped"`
+	QuarantinedFailures  int       `sql:"quarantined_failures"`
+	TotalDurationSeconds float64   `sql:"total_duration_seconds"`
+}
+
+type TestAnalyticsRepository interface {
+	GetConnection() *pg.DB
+	// DeleteResultsByCiWorkflowId deletes the results of the workflow so that its reports can be ingested again
+	DeleteResultsByCiWorkflowId(ciWorkflowId int, tx *pg.Tx) error
+	SaveResults(results []*TestCaseResult, tx *pg.Tx) error
+	DeleteResultsBefore(ciPipelineId int, createdBefore time.Time) (int, error)
+	// FindResultsOfFlippingTests returns the results of the tests which both passed and failed on a commit, ordered by
+	// workflow
+	FindResultsOfFlippingTests(ciPipelineId int, since time.Time, passedStatus string, failedStatus string) ([]*TestCaseResult, error)
+	FindResultsByTestKey(ciPipelineId int, testKey string, since time.Time) ([]*TestCaseResult, error)
+	// FindTopFailingTests returns the tests which failed the most, quarantined failures included
+	FindTopFailingTests(ciPipelineId int, since time.Time, failedStatus string, limit int) ([]*TestFailureSummary, error)
+	FindWorkflowSummaries(ciPipelineId int, since time.Time, passedStatus string, failedStatus string, skippedStatus string) ([]*WorkflowTestSummary, error)
+
+	SaveQuarantine(quarantine *TestQuarantine) error
+	UpdateQuarantine(quarantine *TestQuarantine) error
+	FindActiveQuarantineById(id int) (*TestQuarantine, error)
+	FindActiveQuarantinesByCiPipelineId(ciPipelineId int) ([]*TestQuarantine, error)
+}
+
+type TestAnalyticsRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewTestAnalyticsRepositoryImpl(dbConnection *pg.DB) *TestAnalyticsRepositoryImpl {
+	return &TestAnalyticsRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *TestAnalyticsRepositoryImpl) GetConnection() *pg.DB {
+	return impl.dbConnection
+}
+
+func (impl *TestAnalyticsRepositoryImpl) DeleteResultsByCiWorkflowId(ciWorkflowId int, tx *pg.Tx) error {
+	_, err := tx.Model(&TestCaseResult{}).
+		Where("ci_workflow_id = ?", ciWorkflowId).
+		Delete()
+	return err
+}
+
+func (impl *TestAnalyticsRepositoryImpl) SaveResults(results []*TestCaseResult, tx *pg.Tx) error {
+	if len(results) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&results).Insert()
+	return err
+}
+
+func (impl *TestAnalyticsRepositoryImpl) DeleteResultsBefore(ciPipelineId int, createdBefore time.Time) (int, error) {
+	result, err := impl.dbConnection.Model(&TestCaseResult{}).
+		Where("ci_pipeline_id = ?", ciPipelineId).
+		Where("created_on < ?", createdBefore).
+		Delete()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (impl *TestAnalyticsRepositoryImpl) FindResultsOfFlippingTests(ciPipelineId int, since time.Time, passedStatus string, failedStatus string) ([]*TestCaseResult, error) {
+	var results []*TestCaseResult
+	query := "SELECT r.* FROM test_case_result r" +
+		" WHERE r.ci_pipeline_id = ? AND r.created_on > ? AND r.test_key IN (" +
+		" SELECT f.test_key FROM test_case_result f WHERE f.ci_pipeline_id = ? AND f.created_on > ? AND f.status IN (?, ?)" +
+		" GROUP BY f.test_key, f.commit_key HAVING count(DISTINCT f.status) = 2)" +
+		" ORDER BY r.ci_workflow_id, r.id;"
+	_, err := impl.dbConnection.Query(&results, query, ciPipelineId, since, ciPipelineId, since, passedStatus, failedStatus)
+	return results, err
+}
+
+func (impl *TestAnalyticsRepositoryImpl) FindResultsByTestKey(ciPipelineId int, testKey string, since time.Time) ([]*TestCaseResult, error) {
+	var results []*TestCaseResult
+	err := impl.dbConnection.Model(&results).
+		Where("ci_pipeline_id = ?", ciPipelineId).
+		Where("test_key = ?", testKey).
+		Where("created_on > ?", since).
+		Order("ci_workflow_id", "id").
+		Select()
+	return results, err
+}
+
+func (impl *TestAnalyticsRepositoryImpl) FindTopFailingTests(ciPipelineId int, since time.Time, failedStatus string, limit int) ([]*TestFailureSummary, error) {
+	var summaries []*TestFailureSummary
+	query := "SELECT test_key, max(suite_name) AS suite_name, max(class_name) AS class_name, max(test_name) AS test_name," +
+		" count(*) AS runs, count(*) FILTER (WHERE status = ?) AS failures," +
+		" max(ci_workflow_id) FILTER (WHERE status = ?) AS last_failed_workflow_id," +
+		" (array_agg(message ORDER BY ci_workflow_id DESC) FILTER (WHERE status = ?))[1] AS last_failure_message" +
+		" FROM test_case_result WHERE ci_pipeline_id = ? AND created_on > ?" +
+		" GROUP BY test_key HAVING count(*) FILTER (WHERE status = ?) > 0" +
+		" ORDER BY failures DESC, runs, test_key LIMIT ?;"
+	_, err := impl.dbConnection.Query(&summaries, query, failedStatus, failedStatus, failedStatus, ciPipelineId, since, failedStatus, limit)
+	return summaries, err
+}
+
+func (impl *TestAnalyticsRepositoryImpl) FindWorkflowSummaries(ciPipelineId int, since time.Time, passedStatus string, failedStatus string, skippedStatus string) ([]*WorkflowTestSummary, error) {
+	var summaries []*WorkflowTestSummary
+	query := "SELECT ci_workflow_id, min(created_on) AS created_on, count(*) AS total," +
+		" count(*) FILTER (WHERE status = ?) AS passed, count(*) FILTER (WHERE status = ?) AS failed," +
+		" count(*) FILTER (WHERE status = ?) AS skipped, count(*) FILTER (WHERE status = ? AND quarantined) AS quarantined_failures," +
+		" sum(duration_seconds) AS total_duration_seconds" +
+		" FROM test_case_result WHERE ci_pipeline_id = ? AND created_on > ?" +
+		" GROUP BY ci_workflow_id ORDER BY ci_workflow_id;"
+	_, err := impl.dbConnection.Query(&summaries, query, passedStatus, failedStatus, skippedStatus, failedStatus, ciPipelineId, since)
+	return summaries, err
+}
+
+func (impl *TestAnalyticsRepositoryImpl) SaveQuarantine(quarantine *TestQuarantine) error {
+	return impl.dbConnection.Insert(quarantine)
+}
+
+func (impl *TestAnalyticsRepositoryImpl) UpdateQuarantine(quarantine *TestQuarantine) error {
+	return impl.dbConnection.Update(quarantine)
+}
+
+func (impl *TestAnalyticsRepositoryImpl) FindActiveQuarantineById(id int) (*TestQuarantine, error) {
+	quarantine := &TestQuarantine{}
+	err := impl.dbConnection.Model(quarantine).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return quarantine, err
+}
+
+func (impl *TestAnalyticsRepositoryImpl) FindActiveQuarantinesByCiPipelineId(ciPipelineId int) ([]*TestQuarantine, error) {
+	var quarantines []*TestQuarantine
+	err := impl.dbConnection.Model(&quarantines).
+		Where("ci_pipeline_id = ?", ciPipelineId).
+		Where("active = ?", true).
+		Order("id").
+		Select()
+	return quarantines, err
+}
@@ -0,0 +1,150 @@
+package workflowLog
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// setupStepName is the name of the step holding the lines logged before the first step marker
+const setupStepName = "Setup"
+
+// maxStepNameLength is the length of the name column of steps, in characters
+const maxStepNameLength = 250
+
+var ansiEscapeRegex = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
+
+// lineTimestampLayouts are the layouts of the timestamps lines may start with, the first is the one of the pod logs
+// fetched with timestamps and the second the one of the go logger of the runner
+var lineTimestampLayouts = []string{time.RFC3339Nano, "2006/01/02 15:04:05"}
+
+type parsedLogStep struct {
+	name             string
+	startLine        int
+	endLine          int
+	startedOn        time.Time
+	finishedOn       time.Time
+	content          strings.Builder
+	contentTruncated bool
+}
+
+type parsedLog struct {
+	steps     []*parsedLogStep
+	lineCount int
+	sizeBytes int64
+	// truncated is set when the log is bigger than the max size read, lines after it are not indexed
+	truncated bool
+}
+
+// parseLog splits the log into steps, a step starts at a line matching the step marker, whose first capture group is
+// the step name, and ends before the next one. Step contents are cut at maxContentBytes and reading stops after
+// maxLogBytes.
+func parseLog(reader io.Reader, stepMarker *regexp.Regexp, maxLogBytes int64, maxContentBytes int) (*parsedLog, error) {
+	result := &parsedLog{}
+	var step *parsedLogStep
+	bufReader := bufio.NewReader(reader)
+	for {
+		line, err := bufReader.ReadString('\n')
+		if len(line) > 0 {
+			if result.sizeBytes+int64(len(line)) > maxLogBytes {
+				result.truncated = true
+				break
+			}
+			result.sizeBytes += int64(len(line))
+			result.lineCount++
+			text := ansiEscapeRegex.ReplaceAllString(strings.TrimRight(line, "\r\n"), "")
+			if name, ok := matchStepMarker(stepMarker, text); ok {
+				step = &parsedLogStep{name: name, startLine: result.lineCount}
+				result.steps = append(result.steps, step)
+			} else if step == nil {
+				step = &parsedLogStep{name: setupStepName, startLine: result.lineCount}
+				result.steps = append(result.steps, step)
+			}
+			step.addLine(text, result.lineCount, maxContentBytes)
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func matchStepMarker(stepMarker *regexp.Regexp, line string) (string, bool) {
+	matches := stepMarker.FindStringSubmatch(line)
+	if matches == nil {
+		return "", false
+	}
+	name := ""
+	if len(matches) > 1 {
+		name = strings.TrimSpace(matches[1])
+	}
+	if len(name) == 0 {
+		name = strings.TrimSpace(matches[0])
+	}
+	if utf8.RuneCountInString(name) > maxStepNameLength {
+		name = string([]rune(name)[:maxStepNameLength])
+	}
+	return name, true
+}
+
+func (step *parsedLogStep) addLine(line string, lineNumber int, maxContentBytes int) {
+	step.endLine = lineNumber
+	if timestamp, ok := parseLineTimestamp(line); ok {
+		if step.startedOn.IsZero() {
+			step.startedOn = timestamp
+		}
+		step.finishedOn = timestamp
+	}
+	if step.contentTruncated {
+		return
+	}
+	if step.content.Len()+len(line)+1 > maxContentBytes {
+		step.contentTruncated = true
+		return
+	}
+	step.content.WriteString(line)
+	step.content.WriteByte('\n')
+}
+
+func parseLineTimestamp(line string) (time.Time, bool) {
+	for _, layout := range lineTimestampLayouts {
+		prefix := line
+		if layout == time.RFC3339Nano {
+			prefix, _, _ = strings.Cut(line, " ")
+		} else if len(line) >= len(layout) {
+			prefix = line[:len(layout)]
+		}
+		if timestamp, err := time.Parse(layout, prefix); err == nil {
+			return timestamp, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// copyLines writes the lines from startLine to endLine of the log, lines are numbered from 1
+func copyLines(writer io.Writer, reader io.Reader, startLine int, endLine int) error {
+	bufReader := bufio.NewReader(reader)
+	lineNumber := 0
+	for lineNumber < endLine {
+		line, err := bufReader.ReadString('\n')
+		if len(line) > 0 {
+			lineNumber++
+			if lineNumber >= startLine {
+				if _, writeErr := io.WriteString(writer, line); writeErr != nil {
+					return writeErr
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package workflowLog
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLog = "2024-03-01T10:00:00Z pulling runner image\n" +
+	"2024-03-01T10:00:05Z \x1b[1mSTAGE:  Git clone\x1b[0m\n" +
+	"2024-03-01T10:00:07Z cloned repository\n" +
+	"STAGE:  Lint\n" +
+	"2024/03/01 10:00:10 WARN deprecated flag --fast\n" +
+	"2024/03/01 10:00:40 lint done\n" +
+	"STAGE:  Docker build\n" +
+	"step 1/5 : FROM golang\n" +
+	"warn: cache miss"
+
+func TestParseLog(t *testing.T) {
+	stepMarker := regexp.MustCompile(`STAGE:\s+(.+)`)
+	parsed, err := parseLog(strings.NewReader(testLog), stepMarker, 1024, 1024)
+	if err != nil {
+		t.Fatalf("parseLog() err = %v", err)
+	}
+	if parsed.lineCount != 9 || parsed.truncated || len(parsed.steps) != 4 {
+		t.Fatalf("parseLog() = %d lines, truncated %v, %d steps", parsed.lineCount, parsed.truncated, len(parsed.steps))
+	}
+	want := []struct {
+		name      string
+		startLine int
+		endLine   int
+		duration  time.Duration
+	}{
+		{setupStepName, 1, 1, 0},
+		{"Git clone", 2, 3, 2 * time.Second},
+		{"Lint", 4, 6, 30 * time.Second},
+		{"Docker build", 7, 9, 0},
+	}
+	for i, w := range want {
+		step := parsed.steps[i]
+		if step.name != w.name || step.startLine != w.startLine || step.endLine != w.endLine ||
+			step.finishedOn.Sub(step.startedOn) != w.duration {
+			t.Errorf("parseLog() step %d = %s lines %d-%d took %v, want %+v", i, step.name, step.startLine, step.endLine,
+				step.finishedOn.Sub(step.startedOn), w)
+		}
+	}
+	if content := parsed.steps[1].content.String(); strings.Contains(content, "\x1b") {
+		t.Errorf("parseLog() content kept ansi escapes: %q", content)
+	}
+
+	parsed, err = parseLog(strings.NewReader(testLog), stepMarker, 150, 45)
+	if err != nil {
+		t.Fatalf("parseLog() err = %v", err)
+	}
+	if !parsed.truncated || parsed.lineCount != 4 || !parsed.steps[1].contentTruncated || parsed.steps[0].contentTruncated {
+		t.Errorf("parseLog() with limits = %d lines, truncated %v", parsed.lineCount, parsed.truncated)
+	}
+}
+
+func TestClassifyStep(t *testing.T) {
+	configuredSteps := map[string]*configuredStep{
+		"lint":   {stage: STEP_STAGE_PRE_CI},
+		"sonar":  {stage: STEP_STAGE_POST_CI, pluginName: "Sonarqube"},
+		"notify": {stage: STEP_STAGE_POST_CD},
+	}
+	for _, tt := range []struct {
+		name         string
+		workflowType string
+		runnerType   string
+		stage        string
+		pluginName   string
+	}{
+		{setupStepName, WORKFLOW_TYPE_CI, "", STEP_STAGE_SETUP, ""},
+		{"Lint", WORKFLOW_TYPE_CI, "", STEP_STAGE_PRE_CI, ""},
+		{"Sonar", WORKFLOW_TYPE_CI, "", STEP_STAGE_POST_CI, "Sonarqube"},
+		{"Docker build", WORKFLOW_TYPE_CI, "", STEP_STAGE_BUILD, ""},
+		{"Git clone", WORKFLOW_TYPE_CI, "", STEP_STAGE_SYSTEM, ""},
+		{"Notify", WORKFLOW_TYPE_CD, "POST", STEP_STAGE_POST_CD, ""},
+		{"Migrate", WORKFLOW_TYPE_CD, "PRE", STEP_STAGE_PRE_CD, ""},
+	} {
+		stage, pluginName := classifyStep(tt.name, tt.workflowType, tt.runnerType, configuredSteps)
+		if stage != tt.stage || pluginName != tt.pluginName {
+			t.Errorf("classifyStep(%q) = %s, %s, want %s, %s", tt.name, stage, pluginName, tt.stage, tt.pluginName)
+		}
+	}
+}
+
+func TestMatchStepMarker(t *testing.T) {
+	stepMarker := regexp.MustCompile(`^STAGE:\s*(.*)$`)
+	name, ok := matchStepMarker(stepMarker, "STAGE:  "+strings.Repeat("é", maxStepNameLength+10))
+	if !ok || name != strings.Repeat("é", maxStepNameLength) {
+		t.Errorf("matchStepMarker() = %q, %v, want %d runes", name, ok, maxStepNameLength)
+	}
+}
+
+func TestFindMatchingLines(t *testing.T) {
+	matches := findMatchingLines("WARN deprecated flag\nlint done\nwarn: cache miss\n", 5, "warn")
+	if len(matches) != 2 || matches[0].lineNumber != 5 || matches[1].lineNumber != 7 || matches[1].line != "warn: cache miss" {
+		t.Errorf("findMatchingLines() = %+v", matches)
+	}
+}
+
+func TestCopyLines(t *testing.T) {
+	var buffer bytes.Buffer
+	err := copyLines(&buffer, strings.NewReader(testLog), 7, 9)
+	if err != nil {
+		t.Fatalf("copyLines() err = %v", err)
+	}
+	if want := "STAGE:  Docker build\nstep 1/5 : FROM golang\nwarn: cache miss"; buffer.String() != want {
+		t.Errorf("copyLines() = %q, want %q", buffer.String(), want)
+	}
+}
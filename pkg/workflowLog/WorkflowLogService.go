@@ -0,0 +1,558 @@
+package workflowLog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/pipeline/executors"
+	pipelineStageRepository "github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	pluginRepository "github.com/devtron-labs/devtron/pkg/plugin/repository"
+	workflowLogRepository "github.com/devtron-labs/devtron/pkg/workflowLog/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type WorkflowLogConfig struct {
+	// StepMarkerRegex matches the lines starting a step, its first capture group is the step name
+	StepMarkerRegex      string `env:"WORKFLOW_LOG_STEP_MARKER_REGEX" envDefault:"STAGE:\\s+(.+)"`
+	MaxLogSizeMb         int    `env:"WORKFLOW_LOG_INDEX_MAX_LOG_SIZE_MB" envDefault:"50"`
+	MaxStepContentKb     int    `env:"WORKFLOW_LOG_INDEX_MAX_STEP_CONTENT_KB" envDefault:"1024"`
+	IndexDelayMinutes    int    `env:"WORKFLOW_LOG_INDEX_DELAY_MINUTES" envDefault:"2"`
+	IndexLookbackHours   int    `env:"WORKFLOW_LOG_INDEX_LOOKBACK_HOURS" envDefault:"24"`
+	IndexBatchSize       int    `env:"WORKFLOW_LOG_INDEX_BATCH_SIZE" envDefault:"20"`
+	IndexRetentionDays   int    `env:"WORKFLOW_LOG_INDEX_RETENTION_DAYS" envDefault:"30"`
+	SearchRuns           int    `env:"WORKFLOW_LOG_SEARCH_RUNS" envDefault:"20"`
+	MaxSearchMatches     int    `env:"WORKFLOW_LOG_SEARCH_MAX_MATCHES" envDefault:"500"`
+	MinSearchQueryLength int    `env:"WORKFLOW_LOG_SEARCH_MIN_QUERY_LENGTH" envDefault:"3"`
+}
+
+const maxSearchRuns = 100
+
+type WorkflowLogService interface {
+	// IndexPendingWorkflows indexes the logs of the recently finished workflows and deletes the indexes past retention
+	IndexPendingWorkflows()
+	// GetSteps returns the steps of the workflow logs, the logs of finished workflows not indexed yet are indexed and
+	// the ones of running workflows are parsed without being stored
+	GetSteps(workflowType string, pipelineId int, workflowId int) (*WorkflowLogStepsDto, error)
+	// SearchLogs searches the text, ignoring case, in the indexed logs of the latest runs of the pipeline
+	SearchLogs(workflowType string, pipelineId int, query string, runs int) (*LogSearchResponse, error)
+	// DownloadStepLogs returns the full logs of the step, including the lines past the indexed content
+	DownloadStepLogs(workflowType string, pipelineId int, workflowId int, stepIndex int) ([]byte, error)
+}
+
+type WorkflowLogServiceImpl struct {
+	logger                  *zap.SugaredLogger
+	workflowLogRepository   workflowLogRepository.WorkflowLogRepository
+	ciWorkflowRepository    pipelineConfig.CiWorkflowRepository
+	cdWorkflowRepository    pipelineConfig.CdWorkflowRepository
+	pipelineRepository      pipelineConfig.PipelineRepository
+	pipelineStageRepository pipelineStageRepository.PipelineStageRepository
+	globalPluginRepository  pluginRepository.GlobalPluginRepository
+	ciHandler               pipeline.CiHandler
+	cdHandler               pipeline.CdHandler
+	config                  *WorkflowLogConfig
+	stepMarker              *regexp.Regexp
+}
+
+func NewWorkflowLogServiceImpl(logger *zap.SugaredLogger,
+	workflowLogRepository workflowLogRepository.WorkflowLogRepository,
+	ciWorkflowRepository pipelineConfig.CiWorkflowRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	pipelineStageRepository pipelineStageRepository.PipelineStageRepository,
+	globalPluginRepository pluginRepository.GlobalPluginRepository,
+	ciHandler pipeline.CiHandler, cdHandler pipeline.CdHandler) *WorkflowLogServiceImpl {
+	cfg := &WorkflowLogConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Infow("error occurred while parsing WorkflowLogConfig, so setting workflow log config to default values", "err", err)
+		cfg = &WorkflowLogConfig{StepMarkerRegex: `STAGE:\s+(.+)`, MaxLogSizeMb: 50, MaxStepContentKb: 1024, IndexDelayMinutes: 2,
+			IndexLookbackHours: 24, IndexBatchSize: 20, IndexRetentionDays: 30, SearchRuns: 20, MaxSearchMatches: 500, MinSearchQueryLength: 3}
+	}
+	stepMarker, err := regexp.Compile(cfg.StepMarkerRegex)
+	if err != nil {
+		logger.Errorw("invalid workflow log step marker regex, using the default one", "regex", cfg.StepMarkerRegex, "err", err)
+		stepMarker = regexp.MustCompile(`STAGE:\s+(.+)`)
+	}
+	return &WorkflowLogServiceImpl{
+		logger:                  logger,
+		workflowLogRepository:   workflowLogRepository,
+		ciWorkflowRepository:    ciWorkflowRepository,
+		cdWorkflowRepository:    cdWorkflowRepository,
+		pipelineRepository:      pipelineRepository,
+		pipelineStageRepository: pipelineStageRepository,
+		globalPluginRepository:  globalPluginRepository,
+		ciHandler:               ciHandler,
+		cdHandler:               cdHandler,
+		config:                  cfg,
+		stepMarker:              stepMarker,
+	}
+}
+
+// finishedStatuses are the statuses of workflows whose logs do not change anymore
+var finishedStatuses = []string{pipelineConfig.WorkflowSucceeded, pipelineConfig.WorkflowFailed, pipelineConfig.WorkflowAborted,
+	pipelineConfig.WorkflowTimedOut, string(v1alpha1.NodeError), executors.WorkflowCancel}
+
+// cdRunnerTypes are the cd workflow runners running a pod, deployments have no logs
+var cdRunnerTypes = []string{pipelineConfig.WorkflowTypePre, pipelineConfig.WorkflowTypePost}
+
+func (impl *WorkflowLogServiceImpl) IndexPendingWorkflows() {
+	now := time.Now()
+	finishedAfter := now.Add(-time.Duration(impl.config.IndexLookbackHours) * time.Hour)
+	finishedBefore := now.Add(-time.Duration(impl.config.IndexDelayMinutes) * time.Minute)
+	ciWorkflows, err := impl.workflowLogRepository.FindFinishedCiWorkflows(finishedStatuses, finishedAfter, finishedBefore, impl.config.IndexBatchSize)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci workflows to index logs of", "err", err)
+	}
+	for _, workflow := range ciWorkflows {
+		_, err = impl.indexWorkflow(WORKFLOW_TYPE_CI, workflow)
+		if err != nil {
+			impl.logger.Errorw("error in indexing ci workflow logs", "workflowId", workflow.WorkflowId, "err", err)
+		}
+	}
+	cdWorkflowRunners, err := impl.workflowLogRepository.FindFinishedCdWorkflowRunners(cdRunnerTypes, finishedStatuses, finishedAfter, finishedBefore, impl.config.IndexBatchSize)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cd workflow runners to index logs of", "err", err)
+	}
+	for _, workflow := range cdWorkflowRunners {
+		_, err = impl.indexWorkflow(WORKFLOW_TYPE_CD, workflow)
+		if err != nil {
+			impl.logger.Errorw("error in indexing cd workflow runner logs", "workflowRunnerId", workflow.WorkflowId, "err", err)
+		}
+	}
+	deleted, err := impl.workflowLogRepository.DeleteIndexedBefore(now.AddDate(0, 0, -impl.config.IndexRetentionDays))
+	if err != nil {
+		impl.logger.Errorw("error in deleting workflow log indexes past retention", "err", err)
+		return
+	}
+	impl.logger.Debugw("indexed workflow logs", "ciWorkflows", len(ciWorkflows), "cdWorkflowRunners", len(cdWorkflowRunners), "deletedIndexes", deleted)
+}
+
+// indexWorkflow parses and stores the steps of the workflow logs, workflows whose logs cannot be fetched are stored
+// as failed so that they are not fetched again
+func (impl *WorkflowLogServiceImpl) indexWorkflow(workflowType string, workflow *workflowLogRepository.FinishedWorkflow) (*workflowLogRepository.WorkflowLogIndex, error) {
+	index := &workflowLogRepository.WorkflowLogIndex{
+		WorkflowType:      workflowType,
+		WorkflowId:        workflow.WorkflowId,
+		PipelineId:        workflow.PipelineId,
+		WorkflowStartedOn: workflow.StartedOn,
+		Status:            LOG_INDEX_STATUS_INDEXED,
+		IndexedOn:         time.Now(),
+	}
+	steps, err := impl.parseWorkflowLogs(workflowType, workflow, index)
+	if err != nil {
+		impl.logger.Errorw("error in parsing workflow logs", "workflowType", workflowType, "workflowId", workflow.WorkflowId, "err", err)
+		index.Status = LOG_INDEX_STATUS_FAILED
+		index.Message = err.Error()
+	}
+	dbConnection := impl.workflowLogRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = impl.workflowLogRepository.SaveIndex(index, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving workflow log index", "index", index, "err", err)
+		return nil, err
+	}
+	for _, step := range steps {
+		step.LogIndexId = index.Id
+	}
+	err = impl.workflowLogRepository.SaveSteps(steps, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving workflow log steps", "logIndexId", index.Id, "err", err)
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// parseWorkflowLogs fetches and splits the workflow logs into steps, the size of the logs is set on the index
+func (impl *WorkflowLogServiceImpl) parseWorkflowLogs(workflowType string, workflow *workflowLogRepository.FinishedWorkflow,
+	index *workflowLogRepository.WorkflowLogIndex) ([]*workflowLogRepository.WorkflowLogStep, error) {
+	reader, cleanUp, err := impl.getLogReader(workflowType, workflow)
+	if err != nil {
+		return nil, err
+	}
+	if cleanUp != nil {
+		defer cleanUp()
+	}
+	parsed, err := parseLog(reader, impl.stepMarker, int64(impl.config.MaxLogSizeMb)*1024*1024, impl.config.MaxStepContentKb*1024)
+	if err != nil {
+		return nil, err
+	}
+	index.LineCount = parsed.lineCount
+	index.SizeBytes = parsed.sizeBytes
+	index.Truncated = parsed.truncated
+	configuredSteps := impl.getConfiguredSteps(workflowType, workflow.PipelineId)
+	steps := make([]*workflowLogRepository.WorkflowLogStep, 0, len(parsed.steps))
+	for i, parsedStep := range parsed.steps {
+		stage, pluginName := classifyStep(parsedStep.name, workflowType, workflow.RunnerType, configuredSteps)
+		steps = append(steps, &workflowLogRepository.WorkflowLogStep{
+			StepIndex:        i,
+			Name:             parsedStep.name,
+			Stage:            stage,
+			PluginName:       pluginName,
+			StartLine:        parsedStep.startLine,
+			EndLine:          parsedStep.endLine,
+			StartedOn:        parsedStep.startedOn,
+			FinishedOn:       parsedStep.finishedOn,
+			Content:          parsedStep.content.String(),
+			ContentTruncated: parsedStep.contentTruncated,
+		})
+	}
+	return steps, nil
+}
+
+func (impl *WorkflowLogServiceImpl) getLogReader(workflowType string, workflow *workflowLogRepository.FinishedWorkflow) (*bufio.Reader, func() error, error) {
+	var reader *bufio.Reader
+	var cleanUp func() error
+	var err error
+	if workflowType == WORKFLOW_TYPE_CI {
+		reader, cleanUp, err = impl.ciHandler.GetRunningWorkflowLogs(workflow.PipelineId, workflow.WorkflowId)
+	} else {
+		reader, cleanUp, err = impl.cdHandler.GetRunningWorkflowLogs(workflow.EnvironmentId, workflow.PipelineId, workflow.WorkflowId)
+	}
+	if err == nil && reader == nil {
+		err = fmt.Errorf("no logs found for workflow %d", workflow.WorkflowId)
+	}
+	return reader, cleanUp, err
+}
+
+type configuredStep struct {
+	stage      string
+	pluginName string
+}
+
+// getConfiguredSteps returns the steps configured in the pipeline stages by lower cased name, pipelines configured
+// without steps have none
+func (impl *WorkflowLogServiceImpl) getConfiguredSteps(workflowType string, pipelineId int) map[string]*configuredStep {
+	configuredSteps := make(map[string]*configuredStep)
+	var stages []*pipelineStageRepository.PipelineStage
+	var err error
+	if workflowType == WORKFLOW_TYPE_CI {
+		stages, err = impl.pipelineStageRepository.GetAllCiStagesByCiPipelineId(pipelineId)
+	} else {
+		stages, err = impl.pipelineStageRepository.GetAllCdStagesByCdPipelineId(pipelineId)
+	}
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching pipeline stages, steps are classified by name", "pipelineId", pipelineId, "err", err)
+		return configuredSteps
+	}
+	pluginNames := make(map[int]string)
+	for _, stage := range stages {
+		steps, err := impl.pipelineStageRepository.GetAllStepsByStageId(stage.Id)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching pipeline stage steps", "stageId", stage.Id, "err", err)
+			continue
+		}
+		for _, step := range steps {
+			pluginName := ""
+			if step.StepType == pipelineStageRepository.PIPELINE_STEP_TYPE_REF_PLUGIN {
+				if _, ok := pluginNames[step.RefPluginId]; !ok {
+					pluginMetadata, err := impl.globalPluginRepository.GetMetaDataByPluginId(step.RefPluginId)
+					if err != nil {
+						impl.logger.Errorw("error in fetching plugin metadata", "pluginId", step.RefPluginId, "err", err)
+					} else {
+						pluginNames[step.RefPluginId] = pluginMetadata.Name
+					}
+				}
+				pluginName = pluginNames[step.RefPluginId]
+			}
+			configuredSteps[strings.ToLower(step.Name)] = &configuredStep{stage: string(stage.Type), pluginName: pluginName}
+		}
+	}
+	return configuredSteps
+}
+
+// classifyStep returns the stage and plugin of the step, steps not configured in the pipeline are the build of ci
+// workflows, steps the ci runner adds or the tasks of cd stages configured in yaml
+func classifyStep(name string, workflowType string, runnerType string, configuredSteps map[string]*configuredStep) (string, string) {
+	if name == setupStepName {
+		return STEP_STAGE_SETUP, ""
+	}
+	if step, ok := configuredSteps[strings.ToLower(name)]; ok {
+		return step.stage, step.pluginName
+	}
+	if workflowType == WORKFLOW_TYPE_CD {
+		if runnerType == pipelineConfig.WorkflowTypePost {
+			return STEP_STAGE_POST_CD, ""
+		}
+		return STEP_STAGE_PRE_CD, ""
+	}
+	lowerName := strings.ToLower(name)
+	for _, keyword := range []string{"docker", "build", "push"} {
+		if strings.Contains(lowerName, keyword) {
+			return STEP_STAGE_BUILD, ""
+		}
+	}
+	return STEP_STAGE_SYSTEM, ""
+}
+
+// getWorkflow returns the workflow if it belongs to the pipeline, with whether it finished
+func (impl *WorkflowLogServiceImpl) getWorkflow(workflowType string, pipelineId int, workflowId int) (*workflowLogRepository.FinishedWorkflow, bool, error) {
+	workflow := &workflowLogRepository.FinishedWorkflow{WorkflowId: workflowId, PipelineId: pipelineId}
+	var status string
+	if workflowType == WORKFLOW_TYPE_CI {
+		ciWorkflow, err := impl.ciWorkflowRepository.FindById(workflowId)
+		if err != nil && !util.IsErrNoRows(err) {
+			impl.logger.Errorw("error in fetching ci workflow", "id", workflowId, "err", err)
+			return nil, false, err
+		} else if err != nil || ciWorkflow.CiPipelineId != pipelineId {
+			return nil, false, notFound(fmt.Sprintf("workflow %d not found in ci pipeline %d", workflowId, pipelineId))
+		}
+		workflow.StartedOn = ciWorkflow.StartedOn
+		status = ciWorkflow.Status
+	} else {
+		wfr, err := impl.cdWorkflowRepository.FindWorkflowRunnerById(workflowId)
+		if err != nil && !util.IsErrNoRows(err) {
+			impl.logger.Errorw("error in fetching cd workflow runner", "id", workflowId, "err", err)
+			return nil, false, err
+		} else if err != nil || wfr.CdWorkflow == nil || wfr.CdWorkflow.PipelineId != pipelineId {
+			return nil, false, notFound(fmt.Sprintf("workflow %d not found in cd pipeline %d", workflowId, pipelineId))
+		}
+		if wfr.WorkflowType != pipelineConfig.WorkflowTypePre && wfr.WorkflowType != pipelineConfig.WorkflowTypePost {
+			return nil, false, badRequest(fmt.Sprintf("workflow %d is a deployment, only pre and post deployment stages have logs", workflowId))
+		}
+		cdPipeline, err := impl.pipelineRepository.FindById(pipelineId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching cd pipeline", "id", pipelineId, "err", err)
+			return nil, false, err
+		}
+		workflow.EnvironmentId = cdPipeline.EnvironmentId
+		workflow.RunnerType = string(wfr.WorkflowType)
+		workflow.StartedOn = wfr.StartedOn
+		status = wfr.Status
+	}
+	finished := false
+	for _, finishedStatus := range finishedStatuses {
+		if status == finishedStatus {
+			finished = true
+		}
+	}
+	return workflow, finished, nil
+}
+
+func (impl *WorkflowLogServiceImpl) GetSteps(workflowType string, pipelineId int, workflowId int) (*WorkflowLogStepsDto, error) {
+	workflow, finished, err := impl.getWorkflow(workflowType, pipelineId, workflowId)
+	if err != nil {
+		return nil, err
+	}
+	return impl.getSteps(workflowType, workflow, finished)
+}
+
+func (impl *WorkflowLogServiceImpl) getSteps(workflowType string, workflow *workflowLogRepository.FinishedWorkflow, finished bool) (*WorkflowLogStepsDto, error) {
+	workflowId := workflow.WorkflowId
+	index, err := impl.workflowLogRepository.FindIndex(workflowType, workflowId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching workflow log index", "workflowType", workflowType, "workflowId", workflowId, "err", err)
+		return nil, err
+	}
+	var steps []*workflowLogRepository.WorkflowLogStep
+	if err == pg.ErrNoRows && !finished {
+		index = &workflowLogRepository.WorkflowLogIndex{
+			WorkflowType: workflowType,
+			WorkflowId:   workflowId,
+			PipelineId:   workflow.PipelineId,
+			Status:       LOG_INDEX_STATUS_INDEXED,
+			IndexedOn:    time.Now(),
+		}
+		steps, err = impl.parseWorkflowLogs(workflowType, workflow, index)
+		if err != nil {
+			impl.logger.Errorw("error in parsing running workflow logs", "workflowType", workflowType, "workflowId", workflowId, "err", err)
+			return nil, err
+		}
+	} else {
+		if err == pg.ErrNoRows {
+			index, err = impl.indexWorkflow(workflowType, workflow)
+			if err != nil {
+				return nil, err
+			}
+		}
+		steps, err = impl.workflowLogRepository.FindStepsByIndexId(index.Id)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching workflow log steps", "logIndexId", index.Id, "err", err)
+			return nil, err
+		}
+	}
+	return adaptSteps(index, steps), nil
+}
+
+func adaptSteps(index *workflowLogRepository.WorkflowLogIndex, steps []*workflowLogRepository.WorkflowLogStep) *WorkflowLogStepsDto {
+	dto := &WorkflowLogStepsDto{
+		WorkflowType: index.WorkflowType,
+		WorkflowId:   index.WorkflowId,
+		PipelineId:   index.PipelineId,
+		Status:       index.Status,
+		Message:      index.Message,
+		LineCount:    index.LineCount,
+		SizeBytes:    index.SizeBytes,
+		Truncated:    index.Truncated,
+		IndexedOn:    index.IndexedOn,
+		Steps:        make([]*LogStepDto, 0, len(steps)),
+	}
+	for _, step := range steps {
+		stepDto := &LogStepDto{
+			StepIndex:        step.StepIndex,
+			Name:             step.Name,
+			Stage:            step.Stage,
+			PluginName:       step.PluginName,
+			StartLine:        step.StartLine,
+			EndLine:          step.EndLine,
+			ContentTruncated: step.ContentTruncated,
+		}
+		if !step.StartedOn.IsZero() {
+			startedOn := step.StartedOn
+			stepDto.StartedOn = &startedOn
+		}
+		if !step.FinishedOn.IsZero() {
+			finishedOn := step.FinishedOn
+			stepDto.FinishedOn = &finishedOn
+		}
+		if stepDto.StartedOn != nil && stepDto.FinishedOn != nil {
+			stepDto.DurationSeconds = step.FinishedOn.Sub(step.StartedOn).Seconds()
+		}
+		dto.Steps = append(dto.Steps, stepDto)
+	}
+	return dto
+}
+
+func (impl *WorkflowLogServiceImpl) SearchLogs(workflowType string, pipelineId int, query string, runs int) (*LogSearchResponse, error) {
+	if len(strings.TrimSpace(query)) < impl.config.MinSearchQueryLength {
+		return nil, badRequest(fmt.Sprintf("query must be at least %d characters long", impl.config.MinSearchQueryLength))
+	}
+	if runs <= 0 {
+		runs = impl.config.SearchRuns
+	} else if runs > maxSearchRuns {
+		return nil, badRequest(fmt.Sprintf("at most %d runs can be searched", maxSearchRuns))
+	}
+	indexes, err := impl.workflowLogRepository.FindRecentIndexes(workflowType, pipelineId, runs)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching workflow log indexes", "workflowType", workflowType, "pipelineId", pipelineId, "err", err)
+		return nil, err
+	}
+	response := &LogSearchResponse{Query: query, Matches: make([]*LogMatchDto, 0)}
+	indexById := make(map[int]*workflowLogRepository.WorkflowLogIndex)
+	var indexIds []int
+	for _, index := range indexes {
+		if index.Status == LOG_INDEX_STATUS_INDEXED {
+			indexById[index.Id] = index
+			indexIds = append(indexIds, index.Id)
+		}
+	}
+	response.RunsSearched = len(indexIds)
+	steps, err := impl.workflowLogRepository.FindStepsContaining(indexIds, query)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in searching workflow log steps", "pipelineId", pipelineId, "query", query, "err", err)
+		return nil, err
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		workflowI, workflowJ := indexById[steps[i].LogIndexId].WorkflowId, indexById[steps[j].LogIndexId].WorkflowId
+		if workflowI != workflowJ {
+			return workflowI < workflowJ
+		}
+		return steps[i].StepIndex < steps[j].StepIndex
+	})
+	for _, step := range steps {
+		index := indexById[step.LogIndexId]
+		for _, match := range findMatchingLines(step.Content, step.StartLine, query) {
+			if len(response.Matches) == impl.config.MaxSearchMatches {
+				response.MatchesTruncated = true
+				return response, nil
+			}
+			if response.FirstSeenWorkflowId == 0 {
+				response.FirstSeenWorkflowId = index.WorkflowId
+			}
+			response.Matches = append(response.Matches, &LogMatchDto{
+				WorkflowId:        index.WorkflowId,
+				WorkflowStartedOn: index.WorkflowStartedOn,
+				StepIndex:         step.StepIndex,
+				StepName:          step.Name,
+				Stage:             step.Stage,
+				LineNumber:        match.lineNumber,
+				Line:              match.line,
+			})
+		}
+	}
+	return response, nil
+}
+
+type lineMatch struct {
+	lineNumber int
+	line       string
+}
+
+// findMatchingLines returns the lines of the step content containing the query ignoring case, numbered from the
+// first line of the step
+func findMatchingLines(content string, startLine int, query string) []*lineMatch {
+	var matches []*lineMatch
+	lowerQuery := strings.ToLower(query)
+	for i, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		if strings.Contains(strings.ToLower(line), lowerQuery) {
+			matches = append(matches, &lineMatch{lineNumber: startLine + i, line: line})
+		}
+	}
+	return matches
+}
+
+func (impl *WorkflowLogServiceImpl) DownloadStepLogs(workflowType string, pipelineId int, workflowId int, stepIndex int) ([]byte, error) {
+	workflow, finished, err := impl.getWorkflow(workflowType, pipelineId, workflowId)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := impl.getSteps(workflowType, workflow, finished)
+	if err != nil {
+		return nil, err
+	}
+	if steps.Status == LOG_INDEX_STATUS_FAILED {
+		return nil, notFound(fmt.Sprintf("logs of workflow %d could not be fetched: %s", workflowId, steps.Message))
+	}
+	var step *LogStepDto
+	for _, s := range steps.Steps {
+		if s.StepIndex == stepIndex {
+			step = s
+		}
+	}
+	if step == nil {
+		return nil, notFound(fmt.Sprintf("step %d not found in logs of workflow %d", stepIndex, workflowId))
+	}
+	reader, cleanUp, err := impl.getLogReader(workflowType, workflow)
+	if err != nil {
+		impl.logger.Errorw("error in fetching workflow logs", "workflowType", workflowType, "workflowId", workflowId, "err", err)
+		return nil, err
+	}
+	if cleanUp != nil {
+		defer cleanUp()
+	}
+	var buffer bytes.Buffer
+	err = copyLines(&buffer, reader, step.StartLine, step.EndLine)
+	if err != nil {
+		impl.logger.Errorw("error in reading workflow logs", "workflowType", workflowType, "workflowId", workflowId, "err", err)
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func badRequest(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, InternalMessage: message, UserMessage: message}
+}
+
+func notFound(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusNotFound, InternalMessage: message, UserMessage: message}
+}
@@ -0,0 +1,74 @@
+package workflowLog
+
+import "time"
+
+const (
+	WORKFLOW_TYPE_CI = "CI"
+	// WORKFLOW_TYPE_CD covers the pre and post cd workflow runners, deployments run no pod and have no logs
+	WORKFLOW_TYPE_CD = "CD"
+)
+
+const (
+	LOG_INDEX_STATUS_INDEXED = "Indexed"
+	// LOG_INDEX_STATUS_FAILED is the status of workflows whose logs could not be fetched, those are not retried
+	LOG_INDEX_STATUS_FAILED = "Failed"
+)
+
+const (
+	// STEP_STAGE_SETUP is the stage of the lines logged before the first step
+	STEP_STAGE_SETUP   = "SETUP"
+	STEP_STAGE_PRE_CI  = "PRE_CI"
+	STEP_STAGE_BUILD   = "BUILD"
+	STEP_STAGE_POST_CI = "POST_CI"
+	STEP_STAGE_PRE_CD  = "PRE_CD"
+	STEP_STAGE_POST_CD = "POST_CD"
+	// STEP_STAGE_SYSTEM is the stage of the steps the runner adds around the configured ones, like cloning or
+	// uploading artifacts
+	STEP_STAGE_SYSTEM = "SYSTEM"
+)
+
+type LogStepDto struct {
+	StepIndex        int        `json:"stepIndex"`
+	Name             string     `json:"name"`
+	Stage            string     `json:"stage"`
+	PluginName       string     `json:"pluginName,omitempty"`
+	StartLine        int        `json:"startLine"`
+	EndLine          int        `json:"endLine"`
+	StartedOn        *time.Time `json:"startedOn,omitempty"`
+	FinishedOn       *time.Time `json:"finishedOn,omitempty"`
+	DurationSeconds  float64    `json:"durationSeconds"`
+	ContentTruncated bool       `json:"contentTruncated"`
+}
+
+type WorkflowLogStepsDto struct {
+	WorkflowType string        `json:"workflowType"`
+	WorkflowId   int           `json:"workflowId"`
+	PipelineId   int           `json:"pipelineId"`
+	Status       string        `json:"status"`
+	Message      string        `json:"message,omitempty"`
+	LineCount    int           `json:"lineCount"`
+	SizeBytes    int64         `json:"sizeBytes"`
+	Truncated    bool          `json:"truncated"`
+	IndexedOn    time.Time     `json:"indexedOn"`
+	Steps        []*LogStepDto `json:"steps"`
+}
+
+type LogMatchDto struct {
+	WorkflowId        int       `json:"workflowId"`
+	WorkflowStartedOn time.Time `json:"workflowStartedOn"`
+	StepIndex         int       `json:"stepIndex"`
+	StepName          string    `json:"stepName"`
+	Stage             string    `json:"stage"`
+	LineNumber        int       `json:"lineNumber"`
+	Line              string    `json:"line"`
+}
+
+// LogSearchResponse lists the matches oldest run first, FirstSeenWorkflowId is the oldest searched run the text
+// appears in
+type LogSearchResponse struct {
+	Query               string         `json:"query"`
+	RunsSearched        int            `json:"runsSearched"`
+	FirstSeenWorkflowId int            `json:"firstSeenWorkflowId,omitempty"`
+	MatchesTruncated    bool           `json:"matchesTruncated"`
+	Matches             []*LogMatchDto `json:"matches"`
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg"
+)
+
+// WorkflowLogIndex is the index of the logs of a finished ci workflow or pre/post cd workflow runner, the logs are
+// split into the steps the workflow ran
+type WorkflowLogIndex struct {
+	tableName         struct{}  `sql:"workflow_log_index" pg:",discard_unknown_columns"`
+	Id                int       `sql:"id,pk"`
+	WorkflowType      string    `sql:"workflow_type,notnull"`
+	WorkflowId        int       `sql:"workflow_id,notnull"`
+	PipelineId        int       `sql:"pipeline_id,notnull"`
+	WorkflowStartedOn time.Time `sql:"workflow_started_on"`
+	Status            string    `sql:"status,notnull"`
+	Message           string    `sql:"message"`
+	LineCount         int       `sql:"line_count,notnull"`
+	SizeBytes         int64     `sql:"size_bytes,notnull"`
+	Truncated         bool      `sql:"truncated,notnull"`
+	IndexedOn         time.Time `sql:"indexed_on,notnull"`
+}
+
+// WorkflowLogStep is a step of the logs, lines are numbered from 1 over the whole log and Content is cut at the max
+// content size searched
+type WorkflowLogStep struct {
+	tableName        struct{}  `sql:"workflow_log_step" pg:",discard_unknown_columns"`
+	Id               int       `sql:"id,pk"`
+	LogIndexId       int       `sql:"log_index_id,notnull"`
+	StepIndex        int       `sql:"step_index,notnull"`
+	Name             string    `sql:"name,notnull"`
+	Stage            string    `sql:"stage,notnull"`
+	PluginName       string    `sql:"plugin_name"`
+	StartLine        int       `sql:"start_line,notnull"`
+	EndLine          int       `sql:"end_line,notnull"`
+	StartedOn        time.Time `sql:"started_on"`
+	FinishedOn       time.Time `sql:"finished_on"`
+	Content          string    `sql:"content,notnull"`
+	ContentTruncated bool      `sql:"content_truncated,notnull"`
+}
+
+// FinishedWorkflow is a workflow whose logs are not indexed yet, EnvironmentId and RunnerType are set for cd
+// workflow runners only
+type FinishedWorkflow struct {
+	WorkflowId    int       `sql:"workflow_id"`
+	PipelineId    int       `sql:"pipeline_id"`
+	EnvironmentId int       `sql:"environment_id"`
+	RunnerType    string    `sql:"runner_type"`
+	StartedOn     time.Time `sql:"started_on"`
+}
+
+type WorkflowLogRepository interface {
+	GetConnection() *pg.DB
+	SaveIndex(index *WorkflowLogIndex, tx *pg.Tx) error
+	SaveSteps(steps []*WorkflowLogStep, tx *pg.Tx) error
+	FindIndex(workflowType string, workflowId int) (*WorkflowLogIndex, error)
+	// FindStepsByIndexId returns the steps of the index without their content
+	FindStepsByIndexId(logIndexId int) ([]*WorkflowLogStep, error)
+	// FindRecentIndexes returns the indexed logs of the latest runs of the pipeline, latest first
+	FindRecentIndexes(workflowType string, pipelineId int, limit int) ([]*WorkflowLogIndex, error)
+	// FindStepsContaining returns the steps of the indexes whose content contains the text, ignoring case
+	FindStepsContaining(logIndexIds []int, text string) ([]*WorkflowLogStep, error)
+	FindFinishedCiWorkflows(statuses []string, finishedAfter time.Time, finishedBefore time.Time, limit int) ([]*FinishedWorkflow, error)
+	FindFinishedCdWorkflowRunners(runnerTypes []string, statuses []string, finishedAfter time.Time, finishedBefore time.Time, limit int) ([]*FinishedWorkflow, error)
+	DeleteIndexedBefore(indexedBefore time.Time) (int, error)
+}
+
+type WorkflowLogRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewWorkflowLogRepositoryImpl(dbConnection *pg.DB) *WorkflowLogRepositoryImpl {
+	return &WorkflowLogRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl *WorkflowLogRepositoryImpl) GetConnection() *pg.DB {
+	return impl.dbConnection
+}
+
+func (impl *WorkflowLogRepositoryImpl) SaveIndex(index *WorkflowLogIndex, tx *pg.Tx) error {
+	return tx.Insert(index)
+}
+
+func (impl *WorkflowLogRepositoryImpl) SaveSteps(steps []*WorkflowLogStep, tx *pg.Tx) error {
+	if len(steps) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&steps).Insert()
+	return err
+}
+
+func (impl *WorkflowLogRepositoryImpl) FindIndex(workflowType string, workflowId int) (*WorkflowLogIndex, error) {
+	index := &WorkflowLogIndex{}
+	err := impl.dbConnection.Model(index).
+		Where("workflow_type = ?", workflowType).
+		Where("workflow_id = ?", workflowId).
+		Select()
+	return index, err
+}
+
+func (impl *WorkflowLogRepositoryImpl) FindStepsByIndexId(logIndexId int) ([]*WorkflowLogStep, error) {
+	var steps []*WorkflowLogStep
+	err := impl.dbConnection.Model(&steps).
+		Column("id", "log_index_id", "step_index", "name", "stage", "plugin_name", "start_line", "end_line", "started_on", "finished_on", "content_truncated").
+		Where("log_index_id = ?", logIndexId).
+		Order("step_index").
+		Select()
+	return steps, err
+}
+
+func (impl *WorkflowLogRepositoryImpl) FindRecentIndexes(workflowType string, pipelineId int, limit int) ([]*WorkflowLogIndex, error) {
+	var indexes []*WorkflowLogIndex
+	err := impl.dbConnection.Model(&indexes).
+		Where("workflow_type = ?", workflowType).
+		Where("pipeline_id = ?", pipelineId).
+		Order("workflow_id DESC").
+		Limit(limit).
+		Select()
+	return indexes, err
+}
+
+func (impl *WorkflowLogRepositoryImpl) FindStepsContaining(logIndexIds []int, text string) ([]*WorkflowLogStep, error) {
+	var steps []*WorkflowLogStep
+	if len(logIndexIds) == 0 {
+		return steps, nil
+	}
+	err := impl.dbConnection.Model(&steps).
+		Where("log_index_id IN (?)", pg.In(logIndexIds)).
+		Where("content ILIKE ? ESCAPE '\\'", "%"+escapeLikePattern(text)+"%").
+		Order("log_index_id", "step_index").
+		Select()
+	return steps, err
+}
+
+func (impl *WorkflowLogRepositoryImpl) FindFinishedCiWorkflows(statuses []string, finishedAfter time.Time, finishedBefore time.Time, limit int) ([]*FinishedWorkflow, error) {
+	var workflows []*FinishedWorkflow
+	query := "SELECT wf.id AS workflow_id, wf.ci_pipeline_id AS pipeline_id, wf.started_on FROM ci_workflow wf" +
+		" WHERE wf.status IN (?) AND wf.finished_on > ? AND wf.finished_on < ?" +
+		" AND NOT EXISTS (SELECT 1 FROM workflow_log_index i WHERE i.workflow_type = 'CI' AND i.workflow_id = wf.id)" +
+		" ORDER BY wf.id LIMIT ?;"
+	_, err := impl.dbConnection.Query(&workflows, query, pg.In(statuses), finishedAfter, finishedBefore, limit)
+	return workflows, err
+}
+
+func (impl *WorkflowLogRepositoryImpl) FindFinishedCdWorkflowRunners(runnerTypes []string, statuses []string, finishedAfter time.Time, finishedBefore time.Time, limit int) ([]*FinishedWorkflow, error) {
+	var workflows []*FinishedWorkflow
+	query := "SELECT wfr.id AS workflow_id, cw.pipeline_id, p.environment_id, wfr.workflow_type AS runner_type, wfr.started_on FROM cd_workflow_runner wfr" +
+		" INNER JOIN cd_workflow cw ON cw.id = wfr.cd_workflow_id" +
+		" INNER JOIN pipeline p ON p.id = cw.pipeline_id" +
+		" WHERE wfr.workflow_type IN (?) AND wfr.status IN (?) AND wfr.finished_on > ? AND wfr.finished_on < ?" +
+		" AND NOT EXISTS (SELECT 1 FROM workflow_log_index i WHERE i.workflow_type = 'CD' AND i.workflow_id = wfr.id)" +
+		" ORDER BY wfr.id LIMIT ?;"
+	_, err := impl.dbConnection.Query(&workflows, query, pg.In(runnerTypes), pg.In(statuses), finishedAfter, finishedBefore, limit)
+	return workflows, err
+}
+
+func (impl *WorkflowLogRepositoryImpl) DeleteIndexedBefore(indexedBefore time.Time) (int, error) {
+	result, err := impl.dbConnection.Model(&WorkflowLogIndex{}).
+		Where("indexed_on < ?", indexedBefore).
+		Delete()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// escapeLikePattern escapes the wildcards of like patterns so that the text is matched as is
+func escapeLikePattern(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(text)
+}
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "public"."deployment_window_override_audit";
+
+DROP SEQUENCE IF EXISTS id_seq_deployment_window_override_audit;
+
+DROP INDEX IF EXISTS deployment_window_env_team_idx;
+
+DROP TABLE IF EXISTS "public"."deployment_window";
+
+DROP SEQUENCE IF EXISTS id_seq_deployment_window;
@@ -0,0 +1,44 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_deployment_window;
+
+CREATE TABLE IF NOT EXISTS "public"."deployment_window"
+(
+    "id"               integer NOT NULL DEFAULT nextval('id_seq_deployment_window'::regclass),
+    "name"             varchar(250) NOT NULL,
+    "description"      text,
+    "window_type"      varchar(50)  NOT NULL,
+    "environment_id"   integer,
+    "team_id"          integer,
+    "cron_expression"  varchar(100),
+    "duration_minutes" integer,
+    "start_time"       timestamptz,
+    "end_time"         timestamptz,
+    "timezone"         varchar(100),
+    "active"           bool         NOT NULL,
+    "created_on"       timestamptz  NOT NULL,
+    "created_by"       integer      NOT NULL,
+    "updated_on"       timestamptz  NOT NULL,
+    "updated_by"       integer      NOT NULL,
+    CONSTRAINT "deployment_window_environment_id_fkey" FOREIGN KEY ("environment_id") REFERENCES "public"."environment" ("id"),
+    CONSTRAINT "deployment_window_team_id_fkey" FOREIGN KEY ("team_id") REFERENCES "public"."team" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS deployment_window_env_team_idx ON deployment_window (environment_id, team_id) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_deployment_window_override_audit;
+
+CREATE TABLE IF NOT EXISTS "public"."deployment_window_override_audit"
+(
+    "id"                    integer NOT NULL DEFAULT nextval('id_seq_deployment_window_override_audit'::regclass),
+    "pipeline_id"           integer NOT NULL,
+    "ci_artifact_id"        integer NOT NULL,
+    "cd_workflow_runner_id" integer,
+    "window_ids"            integer[],
+    "justification"         text    NOT NULL,
+    "created_on"            timestamptz NOT NULL,
+    "created_by"            integer NOT NULL,
+    "updated_on"            timestamptz NOT NULL,
+    "updated_by"            integer NOT NULL,
+    CONSTRAINT "deployment_window_override_audit_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
@@ -0,0 +1,9 @@
+DELETE FROM "public"."notification_templates" WHERE event_type_id IN (SELECT id FROM public.event WHERE event_type = 'AUTO ROLLBACK');
+DELETE FROM notifier_event_log WHERE event_type_id IN (SELECT id FROM public.event WHERE event_type = 'AUTO ROLLBACK');
+DELETE FROM public.event WHERE event_type = 'AUTO ROLLBACK';
+
+ALTER TABLE "public"."cd_workflow_runner" DROP COLUMN IF EXISTS "auto_rollback_attempted";
+
+ALTER TABLE "public"."cd_workflow_runner" DROP COLUMN IF EXISTS "trigger_type";
+
+ALTER TABLE "public"."pipeline" DROP COLUMN IF EXISTS "auto_rollback_config";
@@ -0,0 +1,74 @@
+ALTER TABLE "public"."pipeline" ADD COLUMN IF NOT EXISTS "auto_rollback_config" text;
+
+ALTER TABLE "public"."cd_workflow_runner" ADD COLUMN IF NOT EXISTS "trigger_type" varchar(50);
+
+-- set once the auto rollback of a degraded runner is attempted, so that it is attempted only once per runner
+ALTER TABLE "public"."cd_workflow_runner" ADD COLUMN IF NOT EXISTS "auto_rollback_attempted" bool NOT NULL DEFAULT false;
+
+INSERT INTO public.event (id, event_type, description) VALUES (6, 'AUTO ROLLBACK', '') ON CONFLICT (id) DO NOTHING;
+INSERT INTO "public"."notification_templates" (channel_type, node_type, event_type_id, template_name, template_payload)
+SELECT 'slack', 'CD', id, 'CD auto rollback template', '{
+    "text": ":rewind: Deployment rolled back automatically | Application > {{appName}} | Environment > {{envName}}",
+    "blocks": [{
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": ":rewind: *Deployment rolled back automatically on {{envName}}*\n{{eventTime}}"
+            }
+        },
+        {
+            "type": "divider"
+        },
+        {
+            "type": "section",
+            "fields": [{
+                    "type": "mrkdwn",
+                    "text": "*Application*\n{{appName}}\n*Pipeline*\n{{pipelineName}}"
+                },
+                {
+                    "type": "mrkdwn",
+                    "text": "*Environment*\n{{envName}}\n*Reason*\n{{failureReason}}"
+                }
+            ]
+        },
+        {
+            "type": "section",
+            "text": {
+                "type": "mrkdwn",
+                "text": "*Rolled back to image*\n`{{dockerImageUrl}}`"
+            }
+        },
+        {
+            "type": "actions",
+            "elements": [{
+                    "type": "button",
+                    "text": {
+                        "type": "plain_text",
+                        "text": "View Pipeline",
+                        "emoji": true
+                    }
+                    {{#deploymentHistoryLink}}
+                    ,
+                    "url": "{{& deploymentHistoryLink}}"
+                    {{/deploymentHistoryLink}}
+                },
+                {
+                    "type": "button",
+                    "text": {
+                        "type": "plain_text",
+                        "text": "App details",
+                        "emoji": true
+                    }
+                    {{#appDetailsLink}}
+                    ,
+                    "url": "{{& appDetailsLink}}"
+                    {{/appDetailsLink}}
+                }
+            ]
+        }
+    ]
+}' FROM public.event WHERE event_type = 'AUTO ROLLBACK';
+INSERT INTO "public"."notification_templates" (channel_type, node_type, event_type_id, template_name, template_payload)
+SELECT 'ses', 'CD', id, 'CD auto rollback ses template', '{"from": "{{fromEmail}}", "to": "{{toEmail}}","subject": "Deployment rolled back automatically | Application > {{appName}} | Environment > {{envName}}","html": "<table style=\"width: 600px; border-collapse: collapse; padding: 20px;\"><tr style=\"background-color:#FDE7E7;\"><td colspan=\"2\" style=\"padding-left:16px;\"><h2 style=\"color:#000A14;\">Deployment rolled back automatically</h2><span>{{eventTime}}</span></td></tr><tr><td style=\"padding-left:16px;\"><p style=\"color:#767D84;\">Application</p><p>{{appName}}</p></td><td><p style=\"color:#767D84;\">Environment</p><p>{{envName}}</p></td></tr><tr><td style=\"padding-left:16px;\"><p style=\"color:#767D84;\">Pipeline</p><p>{{pipelineName}}</p></td><td><p style=\"color:#767D84;\">Reason</p><p>{{failureReason}}</p></td></tr><tr><td colspan=\"2\" style=\"padding-left:16px;\"><p style=\"color:#767D84;\">Rolled back to image</p><p>{{dockerImageUrl}}</p></td></tr><tr><td colspan=\"2\" style=\"padding-left:16px;\">{{#deploymentHistoryLink}}<a href=\"{{& deploymentHistoryLink}}\">View Pipeline</a>{{/deploymentHistoryLink}}</td></tr></table>"}' FROM public.event WHERE event_type = 'AUTO ROLLBACK';
+INSERT INTO "public"."notification_templates" (channel_type, node_type, event_type_id, template_name, template_payload)
+SELECT 'smtp', 'CD', id, 'CD auto rollback smtp template', '{"from": "{{fromEmail}}", "to": "{{toEmail}}","subject": "Deployment rolled back automatically | Application > {{appName}} | Environment > {{envName}}","html": "<table style=\"width: 600px; border-collapse: collapse; padding: 20px;\"><tr style=\"background-color:#FDE7E7;\"><td colspan=\"2\" style=\"padding-left:16px;\"><h2 style=\"color:#000A14;\">Deployment rolled back automatically</h2><span>{{eventTime}}</span></td></tr><tr><td style=\"padding-left:16px;\"><p style=\"color:#767D84;\">Application</p><p>{{appName}}</p></td><td><p style=\"color:#767D84;\">Environment</p><p>{{envName}}</p></td></tr><tr><td style=\"padding-left:16px;\"><p style=\"color:#767D84;\">Pipeline</p><p>{{pipelineName}}</p></td><td><p style=\"color:#767D84;\">Reason</p><p>{{failureReason}}</p></td></tr><tr><td colspan=\"2\" style=\"padding-left:16px;\"><p style=\"color:#767D84;\">Rolled back to image</p><p>{{dockerImageUrl}}</p></td></tr><tr><td colspan=\"2\" style=\"padding-left:16px;\">{{#deploymentHistoryLink}}<a href=\"{{& deploymentHistoryLink}}\">View Pipeline</a>{{/deploymentHistoryLink}}</td></tr></table>"}' FROM public.event WHERE event_type = 'AUTO ROLLBACK';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS artifact_promotion_policy_target_env_idx;
+
+DROP TABLE IF EXISTS "public"."artifact_promotion_policy";
+
+DROP SEQUENCE IF EXISTS id_seq_artifact_promotion_policy;
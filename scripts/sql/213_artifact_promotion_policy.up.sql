@@ -0,0 +1,22 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_artifact_promotion_policy;
+
+CREATE TABLE IF NOT EXISTS "public"."artifact_promotion_policy"
+(
+    "id"                       integer NOT NULL DEFAULT nextval('id_seq_artifact_promotion_policy'::regclass),
+    "name"                     varchar(250) NOT NULL,
+    "description"              text,
+    "target_environment_id"    integer      NOT NULL,
+    "source_environment_id"    integer      NOT NULL,
+    "min_soak_time_in_minutes" integer      NOT NULL DEFAULT 0,
+    "require_post_cd_success"  bool         NOT NULL DEFAULT false,
+    "active"                   bool         NOT NULL,
+    "created_on"               timestamptz  NOT NULL,
+    "created_by"               integer      NOT NULL,
+    "updated_on"               timestamptz  NOT NULL,
+    "updated_by"               integer      NOT NULL,
+    CONSTRAINT "artifact_promotion_policy_target_environment_id_fkey" FOREIGN KEY ("target_environment_id") REFERENCES "public"."environment" ("id"),
+    CONSTRAINT "artifact_promotion_policy_source_environment_id_fkey" FOREIGN KEY ("source_environment_id") REFERENCES "public"."environment" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS artifact_promotion_policy_target_env_idx ON artifact_promotion_policy (target_environment_id) WHERE active = true;
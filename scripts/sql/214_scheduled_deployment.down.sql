@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS scheduled_deployment_pipeline_id_idx;
+
+DROP INDEX IF EXISTS scheduled_deployment_pending_idx;
+
+DROP TABLE IF EXISTS "public"."scheduled_deployment";
+
+DROP SEQUENCE IF EXISTS id_seq_scheduled_deployment;
@@ -0,0 +1,25 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_scheduled_deployment;
+
+CREATE TABLE IF NOT EXISTS "public"."scheduled_deployment"
+(
+    "id"                        integer NOT NULL DEFAULT nextval('id_seq_scheduled_deployment'::regclass),
+    "pipeline_id"               integer      NOT NULL,
+    "ci_artifact_id"            integer      NOT NULL,
+    "scheduled_on"              timestamptz  NOT NULL,
+    "in_next_deployment_window" bool         NOT NULL DEFAULT false,
+    "status"                    varchar(50)  NOT NULL,
+    "cd_workflow_runner_id"     integer,
+    "message"                   text,
+    "executed_on"               timestamptz,
+    "created_on"                timestamptz  NOT NULL,
+    "created_by"                integer      NOT NULL,
+    "updated_on"                timestamptz  NOT NULL,
+    "updated_by"                integer      NOT NULL,
+    CONSTRAINT "scheduled_deployment_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    CONSTRAINT "scheduled_deployment_ci_artifact_id_fkey" FOREIGN KEY ("ci_artifact_id") REFERENCES "public"."ci_artifact" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_deployment_pending_idx ON scheduled_deployment (scheduled_on) WHERE status = 'SCHEDULED';
+
+CREATE INDEX IF NOT EXISTS scheduled_deployment_pipeline_id_idx ON scheduled_deployment (pipeline_id);
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS deployment_queue_cd_workflow_runner_id_uq;
+
+DROP INDEX IF EXISTS deployment_queue_queued_idx;
+
+DROP TABLE IF EXISTS "public"."deployment_queue";
+
+DROP SEQUENCE IF EXISTS id_seq_deployment_queue;
+
+DROP INDEX IF EXISTS deployment_concurrency_limit_cluster_uq;
+
+DROP INDEX IF EXISTS deployment_concurrency_limit_env_uq;
+
+DROP TABLE IF EXISTS "public"."deployment_concurrency_limit";
+
+DROP SEQUENCE IF EXISTS id_seq_deployment_concurrency_limit;
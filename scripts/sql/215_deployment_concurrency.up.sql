@@ -0,0 +1,49 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_deployment_concurrency_limit;
+
+CREATE TABLE IF NOT EXISTS "public"."deployment_concurrency_limit"
+(
+    "id"                         integer NOT NULL DEFAULT nextval('id_seq_deployment_concurrency_limit'::regclass),
+    "environment_id"             integer,
+    "cluster_id"                 integer,
+    "max_concurrent_deployments" integer      NOT NULL,
+    "active"                     bool         NOT NULL,
+    "created_on"                 timestamptz  NOT NULL,
+    "created_by"                 integer      NOT NULL,
+    "updated_on"                 timestamptz  NOT NULL,
+    "updated_by"                 integer      NOT NULL,
+    CONSTRAINT "deployment_concurrency_limit_environment_id_fkey" FOREIGN KEY ("environment_id") REFERENCES "public"."environment" ("id"),
+    CONSTRAINT "deployment_concurrency_limit_cluster_id_fkey" FOREIGN KEY ("cluster_id") REFERENCES "public"."cluster" ("id"),
+    CONSTRAINT "deployment_concurrency_limit_scope_check" CHECK (("environment_id" IS NULL) <> ("cluster_id" IS NULL)),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS deployment_concurrency_limit_env_uq ON deployment_concurrency_limit (environment_id) WHERE active = true;
+
+CREATE UNIQUE INDEX IF NOT EXISTS deployment_concurrency_limit_cluster_uq ON deployment_concurrency_limit (cluster_id) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_deployment_queue;
+
+CREATE TABLE IF NOT EXISTS "public"."deployment_queue"
+(
+    "id"                      integer NOT NULL DEFAULT nextval('id_seq_deployment_queue'::regclass),
+    "cd_workflow_runner_id"   integer      NOT NULL,
+    "pipeline_id"             integer      NOT NULL,
+    "environment_id"          integer      NOT NULL,
+    "cluster_id"              integer      NOT NULL,
+    "status"                  varchar(50)  NOT NULL,
+    "values_override_request" text         NOT NULL,
+    "triggered_by"            integer      NOT NULL,
+    "message"                 text,
+    "released_on"             timestamptz,
+    "created_on"              timestamptz  NOT NULL,
+    "created_by"              integer      NOT NULL,
+    "updated_on"              timestamptz  NOT NULL,
+    "updated_by"              integer      NOT NULL,
+    CONSTRAINT "deployment_queue_cd_workflow_runner_id_fkey" FOREIGN KEY ("cd_workflow_runner_id") REFERENCES "public"."cd_workflow_runner" ("id"),
+    CONSTRAINT "deployment_queue_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS deployment_queue_queued_idx ON deployment_queue (id) WHERE status = 'QUEUED';
+
+CREATE UNIQUE INDEX IF NOT EXISTS deployment_queue_cd_workflow_runner_id_uq ON deployment_queue (cd_workflow_runner_id);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS canary_analysis_result_wfr_id_idx;
+DROP TABLE IF EXISTS "public"."canary_analysis_result";
+DROP SEQUENCE IF EXISTS id_seq_canary_analysis_result;
+
+DROP INDEX IF EXISTS canary_analysis_metric_config_id_idx;
+DROP TABLE IF EXISTS "public"."canary_analysis_metric";
+DROP SEQUENCE IF EXISTS id_seq_canary_analysis_metric;
+
+DROP INDEX IF EXISTS canary_analysis_config_pipeline_id_unique_idx;
+DROP TABLE IF EXISTS "public"."canary_analysis_config";
+DROP SEQUENCE IF EXISTS id_seq_canary_analysis_config;
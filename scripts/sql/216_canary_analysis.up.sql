@@ -0,0 +1,63 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_canary_analysis_config;
+
+CREATE TABLE IF NOT EXISTS "public"."canary_analysis_config"
+(
+    "id"                       integer     NOT NULL DEFAULT nextval('id_seq_canary_analysis_config'::regclass),
+    "pipeline_id"              integer     NOT NULL,
+    "lookback_window_in_secs"  integer     NOT NULL,
+    "active"                   bool        NOT NULL,
+    "created_on"               timestamptz NOT NULL,
+    "created_by"               integer     NOT NULL,
+    "updated_on"               timestamptz NOT NULL,
+    "updated_by"               integer     NOT NULL,
+    CONSTRAINT "canary_analysis_config_pipeline_id_fkey" FOREIGN KEY ("pipeline_id") REFERENCES "public"."pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS canary_analysis_config_pipeline_id_unique_idx ON canary_analysis_config (pipeline_id) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_canary_analysis_metric;
+
+CREATE TABLE IF NOT EXISTS "public"."canary_analysis_metric"
+(
+    "id"                        integer      NOT NULL DEFAULT nextval('id_seq_canary_analysis_metric'::regclass),
+    "canary_analysis_config_id" integer      NOT NULL,
+    "name"                      varchar(250) NOT NULL,
+    "query"                     text         NOT NULL,
+    "condition"                 varchar(50)  NOT NULL,
+    "threshold"                 float8       NOT NULL,
+    "active"                    bool         NOT NULL,
+    "created_on"                timestamptz  NOT NULL,
+    "created_by"                integer      NOT NULL,
+    "updated_on"                timestamptz  NOT NULL,
+    "updated_by"                integer      NOT NULL,
+    CONSTRAINT "canary_analysis_metric_canary_analysis_config_id_fkey" FOREIGN KEY ("canary_analysis_config_id") REFERENCES "public"."canary_analysis_config" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS canary_analysis_metric_config_id_idx ON canary_analysis_metric (canary_analysis_config_id) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_canary_analysis_result;
+
+CREATE TABLE IF NOT EXISTS "public"."canary_analysis_result"
+(
+    "id"                    integer      NOT NULL DEFAULT nextval('id_seq_canary_analysis_result'::regclass),
+    "cd_workflow_runner_id" integer      NOT NULL,
+    "step_index"            integer      NOT NULL,
+    "metric_name"           varchar(250) NOT NULL,
+    "query"                 text         NOT NULL,
+    "condition"             varchar(50)  NOT NULL,
+    "threshold"             float8       NOT NULL,
+    "value"                 float8,
+    "baseline_value"        float8,
+    "passed"                bool         NOT NULL,
+    "message"               text,
+    "created_on"            timestamptz  NOT NULL,
+    "created_by"            integer      NOT NULL,
+    "updated_on"            timestamptz  NOT NULL,
+    "updated_by"            integer      NOT NULL,
+    CONSTRAINT "canary_analysis_result_cd_workflow_runner_id_fkey" FOREIGN KEY ("cd_workflow_runner_id") REFERENCES "public"."cd_workflow_runner" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS canary_analysis_result_wfr_id_idx ON canary_analysis_result (cd_workflow_runner_id, step_index);
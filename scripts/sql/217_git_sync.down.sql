@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS git_sync_resource_status_result_id_idx;
+DROP TABLE IF EXISTS "public"."git_sync_resource_status";
+DROP SEQUENCE IF EXISTS id_seq_git_sync_resource_status;
+
+DROP INDEX IF EXISTS git_sync_result_config_id_idx;
+DROP TABLE IF EXISTS "public"."git_sync_result";
+DROP SEQUENCE IF EXISTS id_seq_git_sync_result;
+
+DROP INDEX IF EXISTS git_sync_config_name_unique_idx;
+DROP TABLE IF EXISTS "public"."git_sync_config";
+DROP SEQUENCE IF EXISTS id_seq_git_sync_config;
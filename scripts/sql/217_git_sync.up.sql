@@ -0,0 +1,64 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_git_sync_config;
+
+CREATE TABLE IF NOT EXISTS "public"."git_sync_config"
+(
+    "id"                      integer      NOT NULL DEFAULT nextval('id_seq_git_sync_config'::regclass),
+    "name"                    varchar(250) NOT NULL,
+    "ci_pipeline_material_id" integer      NOT NULL,
+    "path"                    varchar(500),
+    "auto_sync"               bool         NOT NULL,
+    "active"                  bool         NOT NULL,
+    "created_on"              timestamptz  NOT NULL,
+    "created_by"              integer      NOT NULL,
+    "updated_on"              timestamptz  NOT NULL,
+    "updated_by"              integer      NOT NULL,
+    CONSTRAINT "git_sync_config_ci_pipeline_material_id_fkey" FOREIGN KEY ("ci_pipeline_material_id") REFERENCES "public"."ci_pipeline_material" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS git_sync_config_name_unique_idx ON git_sync_config (name) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_git_sync_result;
+
+CREATE TABLE IF NOT EXISTS "public"."git_sync_result"
+(
+    "id"                 integer      NOT NULL DEFAULT nextval('id_seq_git_sync_result'::regclass),
+    "git_sync_config_id" integer      NOT NULL,
+    "commit_hash"        varchar(250),
+    "status"             varchar(50)  NOT NULL,
+    "dry_run"            bool         NOT NULL,
+    "drift_detected"     bool         NOT NULL,
+    "message"            text,
+    "started_on"         timestamptz  NOT NULL,
+    "finished_on"        timestamptz,
+    "created_on"         timestamptz  NOT NULL,
+    "created_by"         integer      NOT NULL,
+    "updated_on"         timestamptz  NOT NULL,
+    "updated_by"         integer      NOT NULL,
+    CONSTRAINT "git_sync_result_git_sync_config_id_fkey" FOREIGN KEY ("git_sync_config_id") REFERENCES "public"."git_sync_config" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS git_sync_result_config_id_idx ON git_sync_result (git_sync_config_id, id);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_git_sync_resource_status;
+
+CREATE TABLE IF NOT EXISTS "public"."git_sync_resource_status"
+(
+    "id"                 integer      NOT NULL DEFAULT nextval('id_seq_git_sync_resource_status'::regclass),
+    "git_sync_result_id" integer      NOT NULL,
+    "file_path"          varchar(500) NOT NULL,
+    "app_name"           varchar(250),
+    "component"          varchar(100) NOT NULL,
+    "name"               varchar(500),
+    "status"             varchar(50)  NOT NULL,
+    "message"            text,
+    "created_on"         timestamptz  NOT NULL,
+    "created_by"         integer      NOT NULL,
+    "updated_on"         timestamptz  NOT NULL,
+    "updated_by"         integer      NOT NULL,
+    CONSTRAINT "git_sync_resource_status_git_sync_result_id_fkey" FOREIGN KEY ("git_sync_result_id") REFERENCES "public"."git_sync_result" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS git_sync_resource_status_result_id_idx ON git_sync_resource_status (git_sync_result_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS ci_build_matrix_platform_matrix_id_idx;
+DROP TABLE IF EXISTS "public"."ci_build_matrix_platform";
+DROP SEQUENCE IF EXISTS id_seq_ci_build_matrix_platform;
+
+DROP INDEX IF EXISTS ci_build_matrix_ci_workflow_id_unique_idx;
+DROP TABLE IF EXISTS "public"."ci_build_matrix";
+DROP SEQUENCE IF EXISTS id_seq_ci_build_matrix;
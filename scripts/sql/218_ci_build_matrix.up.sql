@@ -0,0 +1,42 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_build_matrix;
+
+CREATE TABLE IF NOT EXISTS "public"."ci_build_matrix"
+(
+    "id"                 integer      NOT NULL DEFAULT nextval('id_seq_ci_build_matrix'::regclass),
+    "ci_workflow_id"     integer      NOT NULL,
+    "docker_registry_id" varchar(250) NOT NULL,
+    "docker_repository"  varchar(250) NOT NULL,
+    "image_tag"          varchar(250) NOT NULL,
+    "image"              text         NOT NULL,
+    "image_digest"       varchar(250),
+    "status"             varchar(50)  NOT NULL,
+    "message"            text,
+    "created_on"         timestamptz  NOT NULL,
+    "created_by"         integer      NOT NULL,
+    "updated_on"         timestamptz  NOT NULL,
+    "updated_by"         integer      NOT NULL,
+    CONSTRAINT "ci_build_matrix_ci_workflow_id_fkey" FOREIGN KEY ("ci_workflow_id") REFERENCES "public"."ci_workflow" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ci_build_matrix_ci_workflow_id_unique_idx ON ci_build_matrix (ci_workflow_id);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_build_matrix_platform;
+
+CREATE TABLE IF NOT EXISTS "public"."ci_build_matrix_platform"
+(
+    "id"                 integer      NOT NULL DEFAULT nextval('id_seq_ci_build_matrix_platform'::regclass),
+    "ci_build_matrix_id" integer      NOT NULL,
+    "platform"           varchar(100) NOT NULL,
+    "image_tag"          varchar(250) NOT NULL,
+    "image_digest"       varchar(250),
+    "status"             varchar(50)  NOT NULL,
+    "created_on"         timestamptz  NOT NULL,
+    "created_by"         integer      NOT NULL,
+    "updated_on"         timestamptz  NOT NULL,
+    "updated_by"         integer      NOT NULL,
+    CONSTRAINT "ci_build_matrix_platform_ci_build_matrix_id_fkey" FOREIGN KEY ("ci_build_matrix_id") REFERENCES "public"."ci_build_matrix" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS ci_build_matrix_platform_matrix_id_idx ON ci_build_matrix_platform (ci_build_matrix_id);
@@ -0,0 +1,6 @@
+ALTER TABLE ci_workflow
+    DROP COLUMN IF EXISTS path_filter_result;
+
+ALTER TABLE ci_pipeline_material
+    DROP COLUMN IF EXISTS include_paths,
+    DROP COLUMN IF EXISTS exclude_paths;
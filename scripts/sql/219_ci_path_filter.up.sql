@@ -0,0 +1,6 @@
+ALTER TABLE ci_pipeline_material
+    ADD COLUMN IF NOT EXISTS include_paths json DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS exclude_paths json DEFAULT '[]';
+
+ALTER TABLE ci_workflow
+    ADD COLUMN IF NOT EXISTS path_filter_result json;
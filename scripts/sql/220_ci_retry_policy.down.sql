@@ -0,0 +1,11 @@
+ALTER TABLE ci_workflow
+    DROP COLUMN IF EXISTS failure_reason;
+
+DROP INDEX IF EXISTS ci_workflow_retry_status_retry_at_idx;
+DROP INDEX IF EXISTS ci_workflow_retry_ci_workflow_id_unique_idx;
+DROP TABLE IF EXISTS "public"."ci_workflow_retry";
+DROP SEQUENCE IF EXISTS id_seq_ci_workflow_retry;
+
+DROP INDEX IF EXISTS ci_retry_policy_ci_pipeline_id_unique_idx;
+DROP TABLE IF EXISTS "public"."ci_retry_policy";
+DROP SEQUENCE IF EXISTS id_seq_ci_retry_policy;
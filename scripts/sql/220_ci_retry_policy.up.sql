@@ -0,0 +1,51 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_retry_policy;
+
+CREATE TABLE IF NOT EXISTS "public"."ci_retry_policy"
+(
+    "id"                  integer     NOT NULL DEFAULT nextval('id_seq_ci_retry_policy'::regclass),
+    "ci_pipeline_id"      integer     NOT NULL,
+    "max_attempts"        integer     NOT NULL,
+    "backoff_seconds"     integer     NOT NULL,
+    "backoff_multiplier"  float8      NOT NULL,
+    "max_backoff_seconds" integer     NOT NULL,
+    "retry_on"            json        NOT NULL DEFAULT '[]',
+    "retry_on_exit_codes" integer[],
+    "active"              bool        NOT NULL,
+    "created_on"          timestamptz NOT NULL,
+    "created_by"          integer     NOT NULL,
+    "updated_on"          timestamptz NOT NULL,
+    "updated_by"          integer     NOT NULL,
+    CONSTRAINT "ci_retry_policy_ci_pipeline_id_fkey" FOREIGN KEY ("ci_pipeline_id") REFERENCES "public"."ci_pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ci_retry_policy_ci_pipeline_id_unique_idx ON ci_retry_policy (ci_pipeline_id);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_workflow_retry;
+
+CREATE TABLE IF NOT EXISTS "public"."ci_workflow_retry"
+(
+    "id"                       integer     NOT NULL DEFAULT nextval('id_seq_ci_workflow_retry'::regclass),
+    "ci_workflow_id"           integer     NOT NULL,
+    "ref_ci_workflow_id"       integer     NOT NULL,
+    "attempt"                  integer     NOT NULL,
+    "failure_reason"           varchar(50) NOT NULL,
+    "retry_at"                 timestamptz NOT NULL,
+    "status"                   varchar(50) NOT NULL,
+    "triggered_ci_workflow_id" integer,
+    "message"                  text,
+    "created_on"               timestamptz NOT NULL,
+    "created_by"               integer     NOT NULL,
+    "updated_on"               timestamptz NOT NULL,
+    "updated_by"               integer     NOT NULL,
+    CONSTRAINT "ci_workflow_retry_ci_workflow_id_fkey" FOREIGN KEY ("ci_workflow_id") REFERENCES "public"."ci_workflow" ("id"),
+    CONSTRAINT "ci_workflow_retry_ref_ci_workflow_id_fkey" FOREIGN KEY ("ref_ci_workflow_id") REFERENCES "public"."ci_workflow" ("id"),
+    PRIMARY KEY ("id")
+);
+
+-- a failed attempt is retried at most once, status updates of a workflow are received more than once
+CREATE UNIQUE INDEX IF NOT EXISTS ci_workflow_retry_ci_workflow_id_unique_idx ON ci_workflow_retry (ci_workflow_id);
+CREATE INDEX IF NOT EXISTS ci_workflow_retry_status_retry_at_idx ON ci_workflow_retry (status, retry_at);
+
+ALTER TABLE ci_workflow
+    ADD COLUMN IF NOT EXISTS failure_reason varchar(50);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS ci_build_queue_ci_workflow_id_uq;
+DROP INDEX IF EXISTS ci_build_queue_queued_idx;
+DROP TABLE IF EXISTS "public"."ci_build_queue";
+DROP SEQUENCE IF EXISTS id_seq_ci_build_queue;
+
+DROP INDEX IF EXISTS ci_pipeline_build_priority_ci_pipeline_id_uq;
+DROP TABLE IF EXISTS "public"."ci_pipeline_build_priority";
+DROP SEQUENCE IF EXISTS id_seq_ci_pipeline_build_priority;
+
+DROP INDEX IF EXISTS ci_build_quota_team_uq;
+DROP TABLE IF EXISTS "public"."ci_build_quota";
+DROP SEQUENCE IF EXISTS id_seq_ci_build_quota;
@@ -0,0 +1,61 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_build_quota;
+
+CREATE TABLE IF NOT EXISTS "public"."ci_build_quota"
+(
+    "id"                    integer     NOT NULL DEFAULT nextval('id_seq_ci_build_quota'::regclass),
+    "team_id"               integer     NOT NULL,
+    "max_concurrent_builds" integer     NOT NULL,
+    "active"                bool        NOT NULL,
+    "created_on"            timestamptz NOT NULL,
+    "created_by"            integer     NOT NULL,
+    "updated_on"            timestamptz NOT NULL,
+    "updated_by"            integer     NOT NULL,
+    CONSTRAINT "ci_build_quota_team_id_fkey" FOREIGN KEY ("team_id") REFERENCES "public"."team" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ci_build_quota_team_uq ON ci_build_quota (team_id) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_pipeline_build_priority;
+
+CREATE TABLE IF NOT EXISTS "public"."ci_pipeline_build_priority"
+(
+    "id"             integer     NOT NULL DEFAULT nextval('id_seq_ci_pipeline_build_priority'::regclass),
+    "ci_pipeline_id" integer     NOT NULL,
+    "priority_class" varchar(50) NOT NULL,
+    "created_on"     timestamptz NOT NULL,
+    "created_by"     integer     NOT NULL,
+    "updated_on"     timestamptz NOT NULL,
+    "updated_by"     integer     NOT NULL,
+    CONSTRAINT "ci_pipeline_build_priority_ci_pipeline_id_fkey" FOREIGN KEY ("ci_pipeline_id") REFERENCES "public"."ci_pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ci_pipeline_build_priority_ci_pipeline_id_uq ON ci_pipeline_build_priority (ci_pipeline_id);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_build_queue;
+
+CREATE TABLE IF NOT EXISTS "public"."ci_build_queue"
+(
+    "id"               integer     NOT NULL DEFAULT nextval('id_seq_ci_build_queue'::regclass),
+    "ci_workflow_id"   integer     NOT NULL,
+    "ci_pipeline_id"   integer     NOT NULL,
+    "team_id"          integer     NOT NULL,
+    "priority_class"   varchar(50) NOT NULL,
+    "status"           varchar(50) NOT NULL,
+    "workflow_request" text        NOT NULL,
+    "triggered_by"     integer     NOT NULL,
+    "message"          text,
+    "released_on"      timestamptz,
+    "created_on"       timestamptz NOT NULL,
+    "created_by"       integer     NOT NULL,
+    "updated_on"       timestamptz NOT NULL,
+    "updated_by"       integer     NOT NULL,
+    CONSTRAINT "ci_build_queue_ci_workflow_id_fkey" FOREIGN KEY ("ci_workflow_id") REFERENCES "public"."ci_workflow" ("id"),
+    CONSTRAINT "ci_build_queue_ci_pipeline_id_fkey" FOREIGN KEY ("ci_pipeline_id") REFERENCES "public"."ci_pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS ci_build_queue_queued_idx ON ci_build_queue (id) WHERE status = 'QUEUED';
+
+CREATE UNIQUE INDEX IF NOT EXISTS ci_build_queue_ci_workflow_id_uq ON ci_build_queue (ci_workflow_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "public"."ci_artifact_sbom_package";
+DROP SEQUENCE IF EXISTS id_seq_ci_artifact_sbom_package;
+DROP TABLE IF EXISTS "public"."ci_artifact_sbom";
+DROP SEQUENCE IF EXISTS id_seq_ci_artifact_sbom;
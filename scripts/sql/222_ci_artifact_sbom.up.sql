@@ -0,0 +1,41 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_artifact_sbom;
+
+CREATE TABLE IF NOT EXISTS "public"."ci_artifact_sbom"
+(
+    "id"             integer      NOT NULL DEFAULT nextval('id_seq_ci_artifact_sbom'::regclass),
+    "ci_pipeline_id" integer,
+    "image"          varchar(500) NOT NULL,
+    "image_digest"   varchar(250),
+    "format"         varchar(50)  NOT NULL,
+    "spec_version"   varchar(50),
+    "tool"           varchar(250),
+    "blob_key"       text,
+    "package_count"  integer      NOT NULL,
+    "active"         bool         NOT NULL,
+    "created_on"     timestamptz  NOT NULL,
+    "created_by"     integer      NOT NULL,
+    "updated_on"     timestamptz  NOT NULL,
+    "updated_by"     integer      NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ci_artifact_sbom_image_uq ON ci_artifact_sbom (image) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_artifact_sbom_package;
+
+CREATE TABLE IF NOT EXISTS "public"."ci_artifact_sbom_package"
+(
+    "id"            integer      NOT NULL DEFAULT nextval('id_seq_ci_artifact_sbom_package'::regclass),
+    "sbom_id"       integer      NOT NULL,
+    "name"          varchar(500) NOT NULL,
+    "package_group" varchar(500),
+    "version"       varchar(250),
+    "type"          varchar(50),
+    "purl"          text,
+    "license"       text,
+    CONSTRAINT "ci_artifact_sbom_package_sbom_id_fkey" FOREIGN KEY ("sbom_id") REFERENCES "public"."ci_artifact_sbom" ("id") ON DELETE CASCADE,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS ci_artifact_sbom_package_sbom_id_idx ON ci_artifact_sbom_package (sbom_id);
+CREATE INDEX IF NOT EXISTS ci_artifact_sbom_package_name_idx ON ci_artifact_sbom_package (lower(name));
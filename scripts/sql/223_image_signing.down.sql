@@ -0,0 +1,16 @@
+DELETE FROM plugin_step_variable WHERE plugin_step_id =(SELECT ps.id FROM plugin_metadata p inner JOIN plugin_step ps on ps.plugin_id=p.id WHERE p.name='Cosign Image Signer v1.0.0' and ps."index"=1 and ps.deleted=false);
+DELETE FROM plugin_step WHERE plugin_id=(SELECT id FROM plugin_metadata WHERE name='Cosign Image Signer v1.0.0');
+DELETE FROM plugin_stage_mapping WHERE plugin_id =(SELECT id FROM plugin_metadata WHERE name='Cosign Image Signer v1.0.0');
+DELETE FROM pipeline_stage_step_variable WHERE pipeline_stage_step_id in (SELECT id FROM pipeline_stage_step where ref_plugin_id =(SELECT id from plugin_metadata WHERE name ='Cosign Image Signer v1.0.0'));
+DELETE FROM pipeline_stage_step where ref_plugin_id in (SELECT id from plugin_metadata WHERE name ='Cosign Image Signer v1.0.0');
+DELETE FROM plugin_tag_relation WHERE plugin_id=(SELECT id FROM plugin_metadata WHERE name='Cosign Image Signer v1.0.0');
+DELETE FROM plugin_metadata WHERE name ='Cosign Image Signer v1.0.0';
+
+DROP TABLE IF EXISTS "public"."image_signature_verification";
+DROP SEQUENCE IF EXISTS id_seq_image_signature_verification;
+DROP TABLE IF EXISTS "public"."image_signature_policy";
+DROP SEQUENCE IF EXISTS id_seq_image_signature_policy;
+DROP TABLE IF EXISTS "public"."ci_pipeline_signing_config";
+DROP SEQUENCE IF EXISTS id_seq_ci_pipeline_signing_config;
+DROP TABLE IF EXISTS "public"."image_signing_key";
+DROP SEQUENCE IF EXISTS id_seq_image_signing_key;
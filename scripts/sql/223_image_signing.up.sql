@@ -0,0 +1,140 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_image_signing_key;
+
+CREATE TABLE IF NOT EXISTS "public"."image_signing_key"
+(
+    "id"          integer      NOT NULL DEFAULT nextval('id_seq_image_signing_key'::regclass),
+    "name"        varchar(250) NOT NULL,
+    "description" text,
+    "public_key"  text         NOT NULL,
+    "private_key" text,
+    "password"    text,
+    "active"      bool         NOT NULL,
+    "created_on"  timestamptz  NOT NULL,
+    "created_by"  integer      NOT NULL,
+    "updated_on"  timestamptz  NOT NULL,
+    "updated_by"  integer      NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS image_signing_key_name_uq ON image_signing_key (name) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_pipeline_signing_config;
+
+CREATE TABLE IF NOT EXISTS "public"."ci_pipeline_signing_config"
+(
+    "id"             integer     NOT NULL DEFAULT nextval('id_seq_ci_pipeline_signing_config'::regclass),
+    "ci_pipeline_id" integer     NOT NULL,
+    "signing_key_id" integer     NOT NULL,
+    "active"         bool        NOT NULL,
+    "created_on"     timestamptz NOT NULL,
+    "created_by"     integer     NOT NULL,
+    "updated_on"     timestamptz NOT NULL,
+    "updated_by"     integer     NOT NULL,
+    CONSTRAINT "ci_pipeline_signing_config_ci_pipeline_id_fkey" FOREIGN KEY ("ci_pipeline_id") REFERENCES "public"."ci_pipeline" ("id"),
+    CONSTRAINT "ci_pipeline_signing_config_signing_key_id_fkey" FOREIGN KEY ("signing_key_id") REFERENCES "public"."image_signing_key" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ci_pipeline_signing_config_ci_pipeline_id_uq ON ci_pipeline_signing_config (ci_pipeline_id) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_image_signature_policy;
+
+CREATE TABLE IF NOT EXISTS "public"."image_signature_policy"
+(
+    "id"              integer     NOT NULL DEFAULT nextval('id_seq_image_signature_policy'::regclass),
+    "environment_id"  integer     NOT NULL,
+    "trusted_key_ids" integer[],
+    "active"          bool        NOT NULL,
+    "created_on"      timestamptz NOT NULL,
+    "created_by"      integer     NOT NULL,
+    "updated_on"      timestamptz NOT NULL,
+    "updated_by"      integer     NOT NULL,
+    CONSTRAINT "image_signature_policy_environment_id_fkey" FOREIGN KEY ("environment_id") REFERENCES "public"."environment" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS image_signature_policy_environment_id_uq ON image_signature_policy (environment_id) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_image_signature_verification;
+
+CREATE TABLE IF NOT EXISTS "public"."image_signature_verification"
+(
+    "id"             integer      NOT NULL DEFAULT nextval('id_seq_image_signature_verification'::regclass),
+    "ci_artifact_id" integer      NOT NULL,
+    "image_digest"   varchar(250),
+    "environment_id" integer      NOT NULL,
+    "policy_id"      integer      NOT NULL,
+    "verified"       bool         NOT NULL,
+    "signing_key_id" integer,
+    "message"        varchar(250),
+    "verified_on"    timestamptz  NOT NULL,
+    "created_on"     timestamptz  NOT NULL,
+    "created_by"     integer      NOT NULL,
+    "updated_on"     timestamptz  NOT NULL,
+    "updated_by"     integer      NOT NULL,
+    CONSTRAINT "image_signature_verification_ci_artifact_id_fkey" FOREIGN KEY ("ci_artifact_id") REFERENCES "public"."ci_artifact" ("id"),
+    CONSTRAINT "image_signature_verification_policy_id_fkey" FOREIGN KEY ("policy_id") REFERENCES "public"."image_signature_policy" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS image_signature_verification_ci_artifact_id_idx ON image_signature_verification (ci_artifact_id);
+
+INSERT INTO plugin_metadata (id,name,description,type,icon,deleted,created_on,created_by,updated_on,updated_by)
+VALUES (nextval('id_seq_plugin_metadata'),'Cosign Image Signer v1.0.0','Sign the built image with cosign using the signing key configured for the ci pipeline in Devtron','PRESET','https://raw.githubusercontent.com/devtron-labs/devtron/main/assets/ic-plugin-vulnerability-scan.png',false,'now()',1,'now()',1);
+
+INSERT INTO plugin_tag (id, name, deleted, created_on, created_by, updated_on, updated_by)
+SELECT
+    nextval('id_seq_plugin_tag'),
+    'Security',
+    false,
+    'now()',
+    1,
+    'now()',
+    1
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM plugin_tag
+    WHERE name = 'Security'
+);
+
+INSERT INTO "plugin_tag_relation" ("id", "tag_id", "plugin_id", "created_on", "created_by", "updated_on", "updated_by") VALUES (nextval('id_seq_plugin_tag_relation'), (SELECT id FROM plugin_tag WHERE name='Security'), (SELECT id FROM plugin_metadata WHERE name='Cosign Image Signer v1.0.0'),'now()', 1, 'now()', 1);
+
+INSERT INTO "plugin_stage_mapping" ("plugin_id","stage_type","created_on", "created_by", "updated_on", "updated_by")
+VALUES ((SELECT id FROM plugin_metadata WHERE name='Cosign Image Signer v1.0.0'),0,'now()', 1, 'now()', 1);
+
+INSERT INTO "plugin_pipeline_script" ("id", "script","type","deleted","created_on", "created_by", "updated_on", "updated_by")
+VALUES (
+    nextval('id_seq_plugin_pipeline_script'),
+    E'#!/bin/sh
+    if [ -z "$COSIGN_PRIVATE_KEY" ]
+    then
+        echo -e "\\n======== No signing key is configured for the ci pipeline ========"
+        exit 1
+    fi
+    if [ -z $CosignImage ]
+    then
+        CosignImage=gcr.io/projectsigstore/cosign:v2.2.2
+    fi
+    echo -e "\\n======== Signing $DEST@$DIGEST ========"
+    docker run --rm -e COSIGN_PRIVATE_KEY -e COSIGN_PASSWORD -e DOCKER_CONFIG=/docker -v $HOME/.docker:/docker $CosignImage sign --yes --tlog-upload=false --key env://COSIGN_PRIVATE_KEY "$DEST@$DIGEST"
+    if [ $? != 0 ]
+    then
+        echo -e "\\n======== Image signing failed ========"
+        exit 1
+    fi',
+    'SHELL',
+    'f',
+    'now()',
+    1,
+    'now()',
+    1
+);
+
+INSERT INTO "plugin_step" ("id", "plugin_id","name","description","index","step_type","script_id","deleted", "created_on", "created_by", "updated_on", "updated_by") VALUES (nextval('id_seq_plugin_step'), (SELECT id FROM plugin_metadata WHERE name='Cosign Image Signer v1.0.0'),'Step 1','Step 1 - Cosign Image Signer','1','INLINE',(SELECT last_value FROM id_seq_plugin_pipeline_script),'f','now()', 1, 'now()', 1);
+
+INSERT INTO plugin_step_variable (id,plugin_step_id,name,format,description,is_exposed,allow_empty_value,default_value,value,variable_type,value_type,previous_step_index,variable_step_index,variable_step_index_in_plugin,reference_variable_name,deleted,created_on,created_by,updated_on,updated_by)
+VALUES (nextval('id_seq_plugin_step_variable'),(SELECT ps.id FROM plugin_metadata p inner JOIN plugin_step ps on ps.plugin_id=p.id WHERE p.name='Cosign Image Signer v1.0.0' and ps."index"=1 and ps.deleted=false),'CosignImage','STRING','Image of cosign used to sign, default is gcr.io/projectsigstore/cosign:v2.2.2','t','t',null,null,'INPUT','NEW',null,1,null,null,'f','now()',1,'now()',1);
+
+INSERT INTO "plugin_step_variable" ("id", "plugin_step_id", "name", "format", "description", "is_exposed", "allow_empty_value","variable_type", "value_type", "variable_step_index",reference_variable_name, "deleted", "created_on", "created_by", "updated_on", "updated_by") VALUES
+    (nextval('id_seq_plugin_step_variable'), (SELECT ps.id FROM plugin_metadata p inner JOIN plugin_step ps on ps.plugin_id=p.id WHERE p.name='Cosign Image Signer v1.0.0' and ps."index"=1 and ps.deleted=false), 'DEST','STRING','image dest',false,true,'INPUT','GLOBAL',1 ,'DEST','f','now()', 1, 'now()', 1),
+    (nextval('id_seq_plugin_step_variable'), (SELECT ps.id FROM plugin_metadata p inner JOIN plugin_step ps on ps.plugin_id=p.id WHERE p.name='Cosign Image Signer v1.0.0' and ps."index"=1 and ps.deleted=false), 'DIGEST','STRING','Image Digest',false,true,'INPUT','GLOBAL',1 ,'DIGEST','f','now()', 1, 'now()', 1);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "public"."ci_build_provenance";
+DROP SEQUENCE IF EXISTS id_seq_ci_build_provenance;
+DROP TABLE IF EXISTS "public"."provenance_signing_key";
+DROP SEQUENCE IF EXISTS id_seq_provenance_signing_key;
@@ -0,0 +1,40 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_provenance_signing_key;
+
+CREATE TABLE IF NOT EXISTS "public"."provenance_signing_key"
+(
+    "id"          integer      NOT NULL DEFAULT nextval('id_seq_provenance_signing_key'::regclass),
+    "key_id"      varchar(250) NOT NULL,
+    "public_key"  text         NOT NULL,
+    "private_key" text         NOT NULL,
+    "active"      bool         NOT NULL,
+    "created_on"  timestamptz  NOT NULL,
+    "created_by"  integer      NOT NULL,
+    "updated_on"  timestamptz  NOT NULL,
+    "updated_by"  integer      NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS provenance_signing_key_active_uq ON provenance_signing_key (active) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_ci_build_provenance;
+
+CREATE TABLE IF NOT EXISTS "public"."ci_build_provenance"
+(
+    "id"               integer     NOT NULL DEFAULT nextval('id_seq_ci_build_provenance'::regclass),
+    "ci_workflow_id"   integer     NOT NULL,
+    "ci_pipeline_id"   integer     NOT NULL,
+    "ci_artifact_id"   integer,
+    "build_definition" text        NOT NULL,
+    "envelope"         text,
+    "signing_key_id"   integer,
+    "created_on"       timestamptz NOT NULL,
+    "created_by"       integer     NOT NULL,
+    "updated_on"       timestamptz NOT NULL,
+    "updated_by"       integer     NOT NULL,
+    CONSTRAINT "ci_build_provenance_ci_workflow_id_fkey" FOREIGN KEY ("ci_workflow_id") REFERENCES "public"."ci_workflow" ("id"),
+    CONSTRAINT "ci_build_provenance_signing_key_id_fkey" FOREIGN KEY ("signing_key_id") REFERENCES "public"."provenance_signing_key" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ci_build_provenance_ci_workflow_id_uq ON ci_build_provenance (ci_workflow_id);
+CREATE INDEX IF NOT EXISTS ci_build_provenance_ci_artifact_id_idx ON ci_build_provenance (ci_artifact_id);
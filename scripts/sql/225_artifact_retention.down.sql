@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "public"."artifact_gc_record";
+DROP SEQUENCE IF EXISTS id_seq_artifact_gc_record;
+DROP TABLE IF EXISTS "public"."artifact_gc_run";
+DROP SEQUENCE IF EXISTS id_seq_artifact_gc_run;
+DROP TABLE IF EXISTS "public"."artifact_retention_policy";
+DROP SEQUENCE IF EXISTS id_seq_artifact_retention_policy;
@@ -0,0 +1,76 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_artifact_retention_policy;
+
+CREATE TABLE IF NOT EXISTS "public"."artifact_retention_policy"
+(
+    "id"                        integer      NOT NULL DEFAULT nextval('id_seq_artifact_retention_policy'::regclass),
+    "name"                      varchar(250) NOT NULL,
+    "ci_pipeline_id"            integer,
+    "docker_registry_id"        varchar(250),
+    "keep_last"                 integer      NOT NULL,
+    "keep_deployed_within_days" integer      NOT NULL,
+    "keep_tagged_releases"      bool         NOT NULL,
+    "delete_registry_images"    bool         NOT NULL,
+    "delete_blob_storage_logs"  bool         NOT NULL,
+    "dry_run"                   bool         NOT NULL,
+    "active"                    bool         NOT NULL,
+    "created_on"                timestamptz  NOT NULL,
+    "created_by"                integer      NOT NULL,
+    "updated_on"                timestamptz  NOT NULL,
+    "updated_by"                integer      NOT NULL,
+    CONSTRAINT "artifact_retention_policy_ci_pipeline_id_fkey" FOREIGN KEY ("ci_pipeline_id") REFERENCES "public"."ci_pipeline" ("id"),
+    CONSTRAINT "artifact_retention_policy_docker_registry_id_fkey" FOREIGN KEY ("docker_registry_id") REFERENCES "public"."docker_artifact_store" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS artifact_retention_policy_ci_pipeline_uq ON artifact_retention_policy (ci_pipeline_id) WHERE active = true AND ci_pipeline_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS artifact_retention_policy_docker_registry_uq ON artifact_retention_policy (docker_registry_id) WHERE active = true AND docker_registry_id IS NOT NULL;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_artifact_gc_run;
+
+CREATE TABLE IF NOT EXISTS "public"."artifact_gc_run"
+(
+    "id"                integer     NOT NULL DEFAULT nextval('id_seq_artifact_gc_run'::regclass),
+    "policy_id"         integer     NOT NULL,
+    "dry_run"           bool        NOT NULL,
+    "status"            varchar(50) NOT NULL,
+    "message"           text,
+    "artifacts_scanned" integer     NOT NULL,
+    "artifacts_deleted" integer     NOT NULL,
+    "images_deleted"    integer     NOT NULL,
+    "logs_deleted"      integer     NOT NULL,
+    "started_on"        timestamptz NOT NULL,
+    "finished_on"       timestamptz,
+    "created_on"        timestamptz NOT NULL,
+    "created_by"        integer     NOT NULL,
+    "updated_on"        timestamptz NOT NULL,
+    "updated_by"        integer     NOT NULL,
+    CONSTRAINT "artifact_gc_run_policy_id_fkey" FOREIGN KEY ("policy_id") REFERENCES "public"."artifact_retention_policy" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS artifact_gc_run_policy_id_idx ON artifact_gc_run (policy_id);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_artifact_gc_record;
+
+-- ci_artifact_id has no foreign key as the artifact row may be deleted by the run recording it
+CREATE TABLE IF NOT EXISTS "public"."artifact_gc_record"
+(
+    "id"             integer      NOT NULL DEFAULT nextval('id_seq_artifact_gc_record'::regclass),
+    "gc_run_id"      integer      NOT NULL,
+    "ci_artifact_id" integer      NOT NULL,
+    "ci_pipeline_id" integer      NOT NULL,
+    "image"          varchar(500),
+    "image_digest"   varchar(250),
+    "row_deleted"    bool         NOT NULL,
+    "image_deleted"  bool         NOT NULL,
+    "logs_deleted"   bool         NOT NULL,
+    "dry_run"        bool         NOT NULL,
+    "failed"         bool         NOT NULL,
+    "message"        text,
+    "created_on"     timestamptz  NOT NULL,
+    CONSTRAINT "artifact_gc_record_gc_run_id_fkey" FOREIGN KEY ("gc_run_id") REFERENCES "public"."artifact_gc_run" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS artifact_gc_record_gc_run_id_idx ON artifact_gc_record (gc_run_id);
+CREATE INDEX IF NOT EXISTS artifact_gc_record_ci_artifact_id_idx ON artifact_gc_record (ci_artifact_id) WHERE dry_run = false AND failed = false;
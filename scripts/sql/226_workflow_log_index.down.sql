@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "public"."workflow_log_step";
+DROP SEQUENCE IF EXISTS id_seq_workflow_log_step;
+DROP TABLE IF EXISTS "public"."workflow_log_index";
+DROP SEQUENCE IF EXISTS id_seq_workflow_log_index;
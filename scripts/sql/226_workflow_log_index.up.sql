@@ -0,0 +1,43 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_workflow_log_index;
+
+-- workflow_id is the id of the ci_workflow for CI logs and of the cd_workflow_runner for CD logs
+CREATE TABLE IF NOT EXISTS "public"."workflow_log_index"
+(
+    "id"                  integer     NOT NULL DEFAULT nextval('id_seq_workflow_log_index'::regclass),
+    "workflow_type"       varchar(10) NOT NULL,
+    "workflow_id"         integer     NOT NULL,
+    "pipeline_id"         integer     NOT NULL,
+    "workflow_started_on" timestamptz,
+    "status"              varchar(50) NOT NULL,
+    "message"             text,
+    "line_count"          integer     NOT NULL,
+    "size_bytes"          bigint      NOT NULL,
+    "truncated"           bool        NOT NULL,
+    "indexed_on"          timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS workflow_log_index_workflow_uq ON workflow_log_index (workflow_type, workflow_id);
+CREATE INDEX IF NOT EXISTS workflow_log_index_pipeline_idx ON workflow_log_index (workflow_type, pipeline_id, workflow_id);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_workflow_log_step;
+
+CREATE TABLE IF NOT EXISTS "public"."workflow_log_step"
+(
+    "id"                integer      NOT NULL DEFAULT nextval('id_seq_workflow_log_step'::regclass),
+    "log_index_id"      integer      NOT NULL,
+    "step_index"        integer      NOT NULL,
+    "name"              varchar(250) NOT NULL,
+    "stage"             varchar(50)  NOT NULL,
+    "plugin_name"       varchar(250),
+    "start_line"        integer      NOT NULL,
+    "end_line"          integer      NOT NULL,
+    "started_on"        timestamptz,
+    "finished_on"       timestamptz,
+    "content"           text         NOT NULL,
+    "content_truncated" bool         NOT NULL,
+    CONSTRAINT "workflow_log_step_log_index_id_fkey" FOREIGN KEY ("log_index_id") REFERENCES "public"."workflow_log_index" ("id") ON DELETE CASCADE,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS workflow_log_step_log_index_id_idx ON workflow_log_step (log_index_id);
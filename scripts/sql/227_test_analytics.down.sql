@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "public"."test_quarantine";
+DROP SEQUENCE IF EXISTS id_seq_test_quarantine;
+DROP TABLE IF EXISTS "public"."test_case_result";
+DROP SEQUENCE IF EXISTS id_seq_test_case_result;
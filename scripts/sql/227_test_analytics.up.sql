@@ -0,0 +1,44 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_test_case_result;
+
+-- commit_key identifies the commits the ci workflow built, a test passing and failing on the same key is flaky
+CREATE TABLE IF NOT EXISTS "public"."test_case_result"
+(
+    "id"               integer          NOT NULL DEFAULT nextval('id_seq_test_case_result'::regclass),
+    "ci_pipeline_id"   integer          NOT NULL,
+    "ci_workflow_id"   integer          NOT NULL,
+    "commit_key"       text             NOT NULL,
+    "test_key"         text             NOT NULL,
+    "suite_name"       text,
+    "class_name"       text,
+    "test_name"        text             NOT NULL,
+    "status"           varchar(20)      NOT NULL,
+    "duration_seconds" double precision NOT NULL,
+    "message"          text,
+    "quarantined"      bool             NOT NULL,
+    "created_on"       timestamptz      NOT NULL,
+    CONSTRAINT "test_case_result_ci_pipeline_id_fkey" FOREIGN KEY ("ci_pipeline_id") REFERENCES "public"."ci_pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS test_case_result_pipeline_test_idx ON test_case_result (ci_pipeline_id, test_key, ci_workflow_id);
+CREATE INDEX IF NOT EXISTS test_case_result_pipeline_created_on_idx ON test_case_result (ci_pipeline_id, created_on);
+CREATE INDEX IF NOT EXISTS test_case_result_workflow_idx ON test_case_result (ci_workflow_id);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_test_quarantine;
+
+CREATE TABLE IF NOT EXISTS "public"."test_quarantine"
+(
+    "id"             integer     NOT NULL DEFAULT nextval('id_seq_test_quarantine'::regclass),
+    "ci_pipeline_id" integer     NOT NULL,
+    "test_key"       text        NOT NULL,
+    "reason"         text,
+    "active"         bool        NOT NULL,
+    "created_on"     timestamptz NOT NULL,
+    "created_by"     integer     NOT NULL,
+    "updated_on"     timestamptz NOT NULL,
+    "updated_by"     integer     NOT NULL,
+    CONSTRAINT "test_quarantine_ci_pipeline_id_fkey" FOREIGN KEY ("ci_pipeline_id") REFERENCES "public"."ci_pipeline" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS test_quarantine_pipeline_test_uq ON test_quarantine (ci_pipeline_id, test_key) WHERE active = true;
@@ -0,0 +1,11 @@
+ALTER TABLE pipeline_stage_step
+    DROP COLUMN IF EXISTS ref_plugin_version_constraint;
+
+DROP INDEX IF EXISTS plugin_metadata_parent_id_version_unique_idx;
+
+ALTER TABLE plugin_metadata
+    DROP COLUMN IF EXISTS plugin_parent_id,
+    DROP COLUMN IF EXISTS plugin_version,
+    DROP COLUMN IF EXISTS is_latest,
+    DROP COLUMN IF EXISTS deprecated,
+    DROP COLUMN IF EXISTS deprecation_message;
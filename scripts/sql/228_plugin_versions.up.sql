@@ -0,0 +1,17 @@
+-- every version of a plugin is a plugin_metadata row of its own, the versions of a plugin share the id of its first
+-- version as plugin_parent_id
+ALTER TABLE plugin_metadata
+    ADD COLUMN IF NOT EXISTS plugin_parent_id    integer,
+    ADD COLUMN IF NOT EXISTS plugin_version      varchar(50) NOT NULL DEFAULT '1.0.0',
+    ADD COLUMN IF NOT EXISTS is_latest           bool        NOT NULL DEFAULT true,
+    ADD COLUMN IF NOT EXISTS deprecated          bool        NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS deprecation_message text;
+
+UPDATE plugin_metadata SET plugin_parent_id = id WHERE plugin_parent_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS plugin_metadata_parent_id_version_unique_idx
+    ON plugin_metadata (plugin_parent_id, plugin_version) WHERE deleted = false;
+
+-- semver constraint of the plugin version used by the step, ref_plugin_id is the version resolved when the step was saved
+ALTER TABLE pipeline_stage_step
+    ADD COLUMN IF NOT EXISTS ref_plugin_version_constraint varchar(100);
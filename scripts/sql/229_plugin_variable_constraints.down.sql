@@ -0,0 +1,2 @@
+ALTER TABLE plugin_step_variable
+    DROP COLUMN IF EXISTS value_constraint;
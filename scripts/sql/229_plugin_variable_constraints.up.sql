@@ -0,0 +1,4 @@
+-- type of the values accepted by a plugin input variable, validated when pipeline stages using the plugin are saved and
+-- triggered
+ALTER TABLE plugin_step_variable
+    ADD COLUMN IF NOT EXISTS value_constraint jsonb;
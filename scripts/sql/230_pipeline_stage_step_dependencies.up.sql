@@ -0,0 +1,3 @@
+-- indexes of the steps of the stage a step runs after, steps of a stage where no step declares them run in index order
+ALTER TABLE pipeline_stage_step
+    ADD COLUMN IF NOT EXISTS depends_on_step_indexes integer[];
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "public"."plugin_test_run";
+DROP SEQUENCE IF EXISTS id_seq_plugin_test_run;
@@ -0,0 +1,24 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_plugin_test_run;
+
+-- sandbox runs testing a plugin or the pre/post stage of a pipeline, no ci_workflow is created for them
+CREATE TABLE IF NOT EXISTS "public"."plugin_test_run"
+(
+    "id"            integer      NOT NULL DEFAULT nextval('id_seq_plugin_test_run'::regclass),
+    "run_type"      varchar(10)  NOT NULL,
+    "plugin_id"     integer,
+    "pipeline_id"   integer,
+    "stage_type"    varchar(50),
+    "workflow_name" varchar(250),
+    "namespace"     varchar(250),
+    "status"        varchar(50)  NOT NULL,
+    "message"       text,
+    "result_token"  varchar(100) NOT NULL,
+    "step_results"  jsonb,
+    "started_on"    timestamptz  NOT NULL,
+    "finished_on"   timestamptz,
+    "created_on"    timestamptz  NOT NULL,
+    "created_by"    integer      NOT NULL,
+    "updated_on"    timestamptz  NOT NULL,
+    "updated_by"    integer      NOT NULL,
+    PRIMARY KEY ("id")
+);
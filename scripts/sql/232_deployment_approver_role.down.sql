@@ -0,0 +1,7 @@
+DELETE FROM default_rbac_role_data WHERE role = 'deploymentApprover';
+DELETE FROM default_auth_role WHERE role_type = 'approver' AND access_type = 'devtron-app' AND entity = 'apps';
+DELETE FROM default_auth_policy WHERE role_type = 'approver' AND access_type = 'devtron-app' AND entity = 'apps';
+
+UPDATE rbac_policy_resource_detail
+SET allowed_actions = array_remove(allowed_actions, 'approve'), updated_on = now()
+WHERE resource IN ('applications', 'environment');
@@ -0,0 +1,90 @@
+-- deployment approval needs the approve action on the application and its environment
+UPDATE rbac_policy_resource_detail
+SET allowed_actions = array_append(allowed_actions, 'approve'), updated_on = now()
+WHERE resource IN ('applications', 'environment')
+  AND NOT ('approve' = ANY (allowed_actions));
+
+INSERT INTO "public"."default_auth_policy" ( "role_type", "policy", "created_on", "created_by", "updated_on", "updated_by","access_type","entity")
+SELECT 'approver', '{
+    "data": [
+        {
+            "type": "p",
+            "sub": "role:approver_{{.Team}}_{{.Env}}_{{.App}}",
+            "res": "applications",
+            "act": "get",
+            "obj": "{{.TeamObj}}/{{.AppObj}}"
+        },
+        {
+            "type": "p",
+            "sub": "role:approver_{{.Team}}_{{.Env}}_{{.App}}",
+            "res": "applications",
+            "act": "approve",
+            "obj": "{{.TeamObj}}/{{.AppObj}}"
+        },
+        {
+            "type": "p",
+            "sub": "role:approver_{{.Team}}_{{.Env}}_{{.App}}",
+            "res": "environment",
+            "act": "get",
+            "obj": "{{.EnvObj}}/{{.AppObj}}"
+        },
+        {
+            "type": "p",
+            "sub": "role:approver_{{.Team}}_{{.Env}}_{{.App}}",
+            "res": "environment",
+            "act": "approve",
+            "obj": "{{.EnvObj}}/{{.AppObj}}"
+        },
+        {
+            "type": "p",
+            "sub": "role:approver_{{.Team}}_{{.Env}}_{{.App}}",
+            "res": "global-environment",
+            "act": "get",
+            "obj": "{{.EnvObj}}"
+        },
+        {
+            "type": "p",
+            "sub": "role:approver_{{.Team}}_{{.Env}}_{{.App}}",
+            "res": "team",
+            "act": "get",
+            "obj": "{{.TeamObj}}"
+        }
+    ]
+}', now(), 1, now(), 1, 'devtron-app', 'apps'
+WHERE NOT EXISTS (SELECT 1 FROM default_auth_policy WHERE role_type = 'approver' AND access_type = 'devtron-app' AND entity = 'apps');
+
+INSERT INTO "public"."default_auth_role" ( "role_type", "role", "created_on", "created_by", "updated_on", "updated_by","access_type","entity")
+SELECT 'approver', '{
+    "role": "role:approver_{{.Team}}_{{.Env}}_{{.App}}",
+    "casbinSubjects": [
+        "role:approver_{{.Team}}_{{.Env}}_{{.App}}"
+    ],
+    "team": "{{.Team}}",
+    "entityName": "{{.App}}",
+    "environment": "{{.Env}}",
+    "action": "approver",
+    "entity": "{{.Entity}}",
+    "accessType": "devtron-app"
+}', now(), 1, now(), 1, 'devtron-app', 'apps'
+WHERE NOT EXISTS (SELECT 1 FROM default_auth_role WHERE role_type = 'approver' AND access_type = 'devtron-app' AND entity = 'apps');
+
+INSERT INTO "public"."default_rbac_role_data" ( "role","default_role_data", "created_on", "created_by", "updated_on", "updated_by","enabled")
+SELECT 'deploymentApprover', '{
+    "roleName" : "deploymentApprover",
+    "roleDisplayName" : "Deployment Approver",
+    "roleDescription": "Can Approve Or Reject Deployment Requests",
+    "updatePoliciesForExistingProvidedRoles" : false,
+    "entity" : "apps",
+    "accessType": "devtron-app",
+    "policyResourceList" : [
+        {
+            "resource": "applications",
+            "actions" : ["get", "approve"]
+        },
+        {
+            "resource": "environment",
+            "actions" : ["get", "approve"]
+        }
+    ]
+  }', now(), 1, now(), 1, true
+WHERE NOT EXISTS (SELECT 1 FROM default_rbac_role_data WHERE role = 'deploymentApprover');
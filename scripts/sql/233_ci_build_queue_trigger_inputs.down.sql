@@ -0,0 +1,8 @@
+UPDATE ci_workflow SET status = 'Failed', message = 'build dropped from queue on downgrade, trigger it again', finished_on = now()
+WHERE id IN (SELECT ci_workflow_id FROM ci_build_queue WHERE status = 'QUEUED');
+
+DELETE FROM ci_build_queue WHERE status = 'QUEUED';
+
+ALTER TABLE ci_build_queue DROP COLUMN IF EXISTS trigger_inputs;
+ALTER TABLE ci_build_queue ADD COLUMN IF NOT EXISTS released_on timestamptz;
+ALTER TABLE ci_build_queue ADD COLUMN IF NOT EXISTS workflow_request text NOT NULL DEFAULT '';
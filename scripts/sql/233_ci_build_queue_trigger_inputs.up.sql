@@ -0,0 +1,9 @@
+-- builds waiting in queue kept the whole workflow request, they are failed and have to be triggered again
+UPDATE ci_workflow SET status = 'Failed', message = 'build dropped from queue on upgrade, trigger it again', finished_on = now()
+WHERE id IN (SELECT ci_workflow_id FROM ci_build_queue WHERE status = 'QUEUED');
+
+DELETE FROM ci_build_queue WHERE status IN ('QUEUED', 'RELEASED');
+
+ALTER TABLE ci_build_queue DROP COLUMN IF EXISTS workflow_request;
+ALTER TABLE ci_build_queue DROP COLUMN IF EXISTS released_on;
+ALTER TABLE ci_build_queue ADD COLUMN IF NOT EXISTS trigger_inputs text NOT NULL DEFAULT '{}';
@@ -0,0 +1,4 @@
+-- private keys are kept in kubernetes secrets rather than next to their password, keys stored in the database have to
+-- be uploaded again
+ALTER TABLE image_signing_key DROP COLUMN IF EXISTS private_key;
+ALTER TABLE image_signing_key ADD COLUMN IF NOT EXISTS private_key_secret_name varchar(250);
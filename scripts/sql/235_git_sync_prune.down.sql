@@ -0,0 +1 @@
+ALTER TABLE git_sync_config DROP COLUMN IF EXISTS prune;
@@ -0,0 +1,2 @@
+-- workflows and cd pipelines removed from the documents of a config are deleted on apply only when prune is set
+ALTER TABLE git_sync_config ADD COLUMN IF NOT EXISTS prune bool NOT NULL DEFAULT false;
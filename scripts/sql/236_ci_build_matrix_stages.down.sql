@@ -0,0 +1 @@
+ALTER TABLE ci_build_matrix DROP COLUMN IF EXISTS pending_stages;
@@ -0,0 +1,2 @@
+-- completions of the pre and post ci stages, run once apart from the builds of the platforms, awaited by a build matrix
+ALTER TABLE ci_build_matrix ADD COLUMN IF NOT EXISTS pending_stages integer NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE provenance_signing_key DROP COLUMN IF EXISTS private_key_secret_name;
+ALTER TABLE provenance_signing_key ADD COLUMN IF NOT EXISTS private_key text;
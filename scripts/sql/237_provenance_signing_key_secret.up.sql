@@ -0,0 +1,5 @@
+-- private keys are kept in kubernetes secrets rather than in the database, keys stored in the database are retired and
+-- a new key is generated, provenance they signed is still verified with their public key
+UPDATE provenance_signing_key SET active = false WHERE active = true;
+ALTER TABLE provenance_signing_key DROP COLUMN IF EXISTS private_key;
+ALTER TABLE provenance_signing_key ADD COLUMN IF NOT EXISTS private_key_secret_name varchar(250);
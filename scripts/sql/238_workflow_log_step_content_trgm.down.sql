@@ -0,0 +1 @@
+DROP INDEX IF EXISTS workflow_log_step_content_trgm_idx;
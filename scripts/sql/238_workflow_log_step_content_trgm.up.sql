@@ -0,0 +1,3 @@
+-- trigram index serving the case insensitive substring search of log contents
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS workflow_log_step_content_trgm_idx ON workflow_log_step USING gin (content gin_trgm_ops);
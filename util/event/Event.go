@@ -22,6 +22,7 @@ type EventType int
 const Trigger EventType = 1
 const Success EventType = 2
 const Fail EventType = 3
+const AutoRollback EventType = 6
 
 type PipelineType string
 
@@ -74,6 +74,9 @@ type EnforcerUtil interface {
 	GetAllWorkflowRBACObjectsByAppId(appId int, workflowNames []string, workflowIds []int) map[int]string
 	GetEnvRBACArrayByAppIdForJobs(appId int) []string
 	CheckAppRbacForAppOrJob(token, resourceName, action string) bool
+	// CheckCiPipelineRbac enforces the app or job rbac of the app of the ci pipeline, the error is that of fetching the
+	// ci pipeline, pg.ErrNoRows if it does not exist
+	CheckCiPipelineRbac(token string, ciPipelineId int, action string) (bool, error)
 	CheckAppRbacForAppOrJobInBulk(token, action string, rbacObjects []string, appType helper.AppType) map[string]bool
 }
 
@@ -709,6 +712,15 @@ func (impl EnforcerUtilImpl) CheckAppRbacForAppOrJob(token, resourceName, action
 	return ok
 }
 
+func (impl EnforcerUtilImpl) CheckCiPipelineRbac(token string, ciPipelineId int, action string) (bool, error) {
+	ciPipeline, err := impl.ciPipelineRepository.FindById(ciPipelineId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching ci pipeline", "ciPipelineId", ciPipelineId, "err", err)
+		return false, err
+	}
+	return impl.CheckAppRbacForAppOrJob(token, impl.GetAppRBACNameByAppId(ciPipeline.AppId), action), nil
+}
+
 func (impl EnforcerUtilImpl) CheckAppRbacForAppOrJobInBulk(token, action string, rbacObjects []string, appType helper.AppType) map[string]bool {
 	var enforcedMap map[string]bool
 	if appType == helper.Job {
@@ -0,0 +1,124 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const (
+	MEDIA_TYPE_OCI_EMPTY = "application/vnd.oci.empty.v1+json"
+	ociTitleAnnotation   = "org.opencontainers.image.title"
+)
+
+// ociEmptyConfig is the config of artifacts which are not images, as recommended by the oci image spec
+var ociEmptyConfig = []byte("{}")
+
+type artifactManifest struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	MediaType     string        `json:"mediaType"`
+	ArtifactType  string        `json:"artifactType,omitempty"`
+	Config        *descriptor   `json:"config"`
+	Layers        []*descriptor `json:"layers"`
+}
+
+// PushArtifact pushes content as the only layer of an oci artifact of artifactType tagged tag, fileName is the title
+// of the layer. The manifest digest is returned.
+func (client *Client) PushArtifact(repository string, tag string, artifactType string, mediaType string, fileName string, content []byte) (string, error) {
+	repository = client.getRepository(repository)
+	err := client.pushBlob(repository, ociEmptyConfig)
+	if err != nil {
+		return "", err
+	}
+	err = client.pushBlob(repository, content)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(&artifactManifest{
+		SchemaVersion: 2,
+		MediaType:     MEDIA_TYPE_OCI_MANIFEST,
+		ArtifactType:  artifactType,
+		Config:        &descriptor{MediaType: MEDIA_TYPE_OCI_EMPTY, Digest: getDigest(ociEmptyConfig), Size: int64(len(ociEmptyConfig))},
+		Layers: []*descriptor{{
+			MediaType:   mediaType,
+			Digest:      getDigest(content),
+			Size:        int64(len(content)),
+			Annotations: map[string]string{ociTitleAnnotation: fileName},
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+	err = client.putManifest(repository, tag, MEDIA_TYPE_OCI_MANIFEST, body)
+	if err != nil {
+		return "", err
+	}
+	return getDigest(body), nil
+}
+
+// PullArtifact returns the content of the first layer of mediaType of the artifact of reference, a tag or a digest
+func (client *Client) PullArtifact(repository string, reference string, mediaType string) ([]byte, error) {
+	repository = client.getRepository(repository)
+	manifestMediaType, body, _, err := client.getManifest(repository, reference)
+	if err != nil {
+		return nil, err
+	}
+	if manifestMediaType != MEDIA_TYPE_OCI_MANIFEST {
+		return nil, fmt.Errorf("%s:%s is not an oci artifact, media type %q", repository, reference, manifestMediaType)
+	}
+	manifest := &artifactManifest{}
+	err = json.Unmarshal(body, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest %s:%s: %v", repository, reference, err)
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == mediaType {
+			return client.getBlob(repository, layer.Digest)
+		}
+	}
+	return nil, fmt.Errorf("%w: layer of media type %s in %s:%s", ErrNotFound, mediaType, repository, reference)
+}
+
+// pushBlob uploads content in a single request unless the registry already has it
+func (client *Client) pushBlob(repository string, content []byte) error {
+	digest := getDigest(content)
+	resp, err := client.do(http.MethodHead, fmt.Sprintf("/v2/%s/blobs/%s", repository, digest), nil, nil, repository)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	resp, err = client.do(http.MethodPost, fmt.Sprintf("/v2/%s/blobs/uploads/", repository), nil, nil, repository)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("error in starting blob upload to %s, status %d", repository, resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || len(location.Path) == 0 {
+		return fmt.Errorf("invalid blob upload location %q of %s", resp.Header.Get("Location"), repository)
+	}
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+	uploadPath := location.String()
+	if !location.IsAbs() {
+		uploadPath = location.RequestURI()
+	}
+	resp, err = client.do(http.MethodPut, uploadPath, map[string]string{"Content-Type": "application/octet-stream"}, content, repository)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error in uploading blob %s@%s, status %d: %s", repository, digest, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
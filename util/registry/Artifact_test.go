@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPushAndPullArtifact(t *testing.T) {
+	blobs := make(map[string][]byte)
+	manifests := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v2/org/plugins/")
+		switch {
+		case r.Method == http.MethodPost && path == "blobs/uploads/":
+			w.Header().Set("Location", "/v2/org/plugins/blobs/uploads/session-1?state=abc")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && path == "blobs/uploads/session-1":
+			if r.URL.Query().Get("state") != "abc" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			blobs[r.URL.Query().Get("digest")] = body
+			w.WriteHeader(http.StatusCreated)
+		case strings.HasPrefix(path, "blobs/"):
+			blob, ok := blobs[strings.TrimPrefix(path, "blobs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(blob)
+		case r.Method == http.MethodPut && strings.HasPrefix(path, "manifests/"):
+			body, _ := io.ReadAll(r.Body)
+			manifests[strings.TrimPrefix(path, "manifests/")] = body
+			w.WriteHeader(http.StatusCreated)
+		case strings.HasPrefix(path, "manifests/"):
+			manifest, ok := manifests[strings.TrimPrefix(path, "manifests/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", MEDIA_TYPE_OCI_MANIFEST)
+			_, _ = w.Write(manifest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(&Credential{RegistryURL: server.URL})
+	content := []byte("plugins: []\n")
+	digest, err := client.PushArtifact("org/plugins", "v1", "application/vnd.example.bundle", "application/vnd.example.bundle.layer.v1+yaml", "bundle.yaml", content)
+	if err != nil || digest != getDigest(manifests["v1"]) {
+		t.Fatalf("PushArtifact() = %s, err %v", digest, err)
+	}
+	if len(blobs) != 2 || string(blobs[getDigest(ociEmptyConfig)]) != "{}" {
+		t.Errorf("PushArtifact() pushed blobs = %v", blobs)
+	}
+	pulled, err := client.PullArtifact("org/plugins", "v1", "application/vnd.example.bundle.layer.v1+yaml")
+	if err != nil || string(pulled) != string(content) {
+		t.Errorf("PullArtifact() = %q, err %v", pulled, err)
+	}
+	if _, err = client.PullArtifact("org/plugins", "v1", "application/json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("PullArtifact() of missing layer err = %v", err)
+	}
+	if _, err = client.PullArtifact("org/plugins", "v2", "application/vnd.example.bundle.layer.v1+yaml"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("PullArtifact() of missing tag err = %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package registry
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	MEDIA_TYPE_COSIGN_SIMPLE_SIGNING = "application/vnd.dev.cosign.simplesigning.v1+json"
+	cosignSignatureAnnotation        = "dev.cosignproject.cosign/signature"
+	cosignSignatureTagSuffix         = ".sig"
+)
+
+// CosignSignature is a signature pushed by cosign, Signature is the base64 encoded signature of Payload
+type CosignSignature struct {
+	Payload   []byte
+	Signature string
+}
+
+type imageManifest struct {
+	Layers []*descriptor `json:"layers"`
+}
+
+// simpleSigningPayload is the payload signed by cosign, it binds the signature to the manifest digest of the image
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// GetCosignSignatures returns the cosign signatures of the image of digest, stored by cosign in the same repository
+// under the tag sha256-<hex>.sig. No signature is returned if the image was never signed.
+func (client *Client) GetCosignSignatures(repository string, digest string) ([]*CosignSignature, error) {
+	repository = client.getRepository(repository)
+	_, body, _, err := client.getManifest(repository, strings.Replace(digest, ":", "-", 1)+cosignSignatureTagSuffix)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	manifest := &imageManifest{}
+	err = json.Unmarshal(body, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature manifest of %s@%s: %v", repository, digest, err)
+	}
+	var signatures []*CosignSignature
+	for _, layer := range manifest.Layers {
+		signature, ok := layer.Annotations[cosignSignatureAnnotation]
+		if layer.MediaType != MEDIA_TYPE_COSIGN_SIMPLE_SIGNING || !ok {
+			continue
+		}
+		payload, err := client.getBlob(repository, layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, &CosignSignature{Payload: payload, Signature: signature})
+	}
+	return signatures, nil
+}
+
+func (client *Client) getBlob(repository string, digest string) ([]byte, error) {
+	resp, err := client.do(http.MethodGet, fmt.Sprintf("/v2/%s/blobs/%s", repository, digest), nil, nil, repository)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: blob %s@%s", ErrNotFound, repository, digest)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error in fetching blob %s@%s, status %d", repository, digest, resp.StatusCode)
+	}
+	if getDigest(body) != digest {
+		return nil, fmt.Errorf("digest mismatch of blob %s@%s", repository, digest)
+	}
+	return body, nil
+}
+
+// ParsePublicKey parses a PEM encoded public key as generated by cosign generate-key-pair
+func ParsePublicKey(publicKeyPem string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(publicKeyPem)))
+	if block == nil {
+		return nil, fmt.Errorf("public key is not PEM encoded")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return publicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// VerifyCosignSignature checks the signature was made by the private key of publicKey for the image of digest
+func VerifyCosignSignature(publicKey crypto.PublicKey, signature *CosignSignature, digest string) error {
+	rawSignature, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return fmt.Errorf("signature is not base64 encoded: %v", err)
+	}
+	payloadHash := sha256.Sum256(signature.Payload)
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, payloadHash[:], rawSignature) {
+			return fmt.Errorf("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err = rsa.VerifyPKCS1v15(key, crypto.SHA256, payloadHash[:], rawSignature); err != nil {
+			return fmt.Errorf("invalid signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, signature.Payload, rawSignature) {
+			return fmt.Errorf("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	payload := &simpleSigningPayload{}
+	err = json.Unmarshal(signature.Payload, payload)
+	if err != nil {
+		return fmt.Errorf("invalid signature payload: %v", err)
+	}
+	if payload.Critical.Image.DockerManifestDigest != digest {
+		return fmt.Errorf("signature is of image %s", payload.Critical.Image.DockerManifestDigest)
+	}
+	return nil
+}
+
+// GetRepositoryOfImage returns the repository path of an image reference like registry.example.com/org/app:v1,
+// without the registry host, the tag and the digest
+func GetRepositoryOfImage(image string) string {
+	if index := strings.Index(image, "@"); index >= 0 {
+		image = image[:index]
+	}
+	if index := strings.LastIndex(image, ":"); index > strings.LastIndex(image, "/") {
+		image = image[:index]
+	}
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[1]
+	}
+	return image
+}
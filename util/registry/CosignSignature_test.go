@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const signedDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+
+func signPayload(t *testing.T, key *ecdsa.PrivateKey, digest string) *CosignSignature {
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"registry.example.com/org/app"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, digest))
+	hash := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &CosignSignature{Payload: payload, Signature: base64.StdEncoding.EncodeToString(signature)}
+}
+
+func encodePublicKey(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestVerifyCosignSignature(t *testing.T) {
+	signingKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	publicKey, err := ParsePublicKey(encodePublicKey(t, signingKey))
+	if err != nil {
+		t.Fatalf("ParsePublicKey() error = %v", err)
+	}
+	otherPublicKey, _ := ParsePublicKey(encodePublicKey(t, otherKey))
+	signature := signPayload(t, signingKey, signedDigest)
+	if err = VerifyCosignSignature(publicKey, signature, signedDigest); err != nil {
+		t.Errorf("VerifyCosignSignature() error = %v", err)
+	}
+	if err = VerifyCosignSignature(otherPublicKey, signature, signedDigest); err == nil {
+		t.Errorf("VerifyCosignSignature() expected error for signature of another key")
+	}
+	if err = VerifyCosignSignature(publicKey, signature, "sha256:2222"); err == nil {
+		t.Errorf("VerifyCosignSignature() expected error for signature of another image")
+	}
+	tampered := &CosignSignature{Payload: append([]byte(" "), signature.Payload...), Signature: signature.Signature}
+	if err = VerifyCosignSignature(publicKey, tampered, signedDigest); err == nil {
+		t.Errorf("VerifyCosignSignature() expected error for tampered payload")
+	}
+	if _, err = ParsePublicKey("not a key"); err == nil {
+		t.Errorf("ParsePublicKey() expected error for invalid key")
+	}
+}
+
+func TestGetCosignSignatures(t *testing.T) {
+	signingKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signature := signPayload(t, signingKey, signedDigest)
+	payloadDigest := getDigest(signature.Payload)
+	manifest := fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[
+		{"mediaType":"application/vnd.dev.cosign.simplesigning.v1+json","digest":%q,"size":%d,"annotations":{"dev.cosignproject.cosign/signature":%q}}]}`,
+		payloadDigest, len(signature.Payload), signature.Signature)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/sha256-1111111111111111111111111111111111111111111111111111111111111111.sig":
+			w.Header().Set("Content-Type", MEDIA_TYPE_OCI_MANIFEST)
+			_, _ = w.Write([]byte(manifest))
+		case "/v2/org/app/blobs/" + payloadDigest:
+			_, _ = w.Write(signature.Payload)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(&Credential{RegistryURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatures, err := client.GetCosignSignatures("org/app", signedDigest)
+	if err != nil || len(signatures) != 1 || signatures[0].Signature != signature.Signature {
+		t.Fatalf("GetCosignSignatures() = %v, %v", signatures, err)
+	}
+	signatures, err = client.GetCosignSignatures("org/unsigned", signedDigest)
+	if err != nil || len(signatures) != 0 {
+		t.Errorf("GetCosignSignatures() unsigned image = %v, %v", signatures, err)
+	}
+}
+
+func TestGetRepositoryOfImage(t *testing.T) {
+	tests := map[string]string{
+		"registry.example.com/org/app:v1":              "org/app",
+		"registry.example.com:5000/org/app@sha256:abc": "org/app",
+		"localhost/app:v1":                             "app",
+		"org/app:v1":                                   "org/app",
+		"app":                                          "app",
+	}
+	for image, want := range tests {
+		if got := GetRepositoryOfImage(image); got != want {
+			t.Errorf("GetRepositoryOfImage(%s) = %s, want %s", image, got, want)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	MEDIA_TYPE_OCI_INDEX            = "application/vnd.oci.image.index.v1+json"
+	MEDIA_TYPE_OCI_MANIFEST         = "application/vnd.oci.image.manifest.v1+json"
+	MEDIA_TYPE_DOCKER_MANIFEST_LIST = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MEDIA_TYPE_DOCKER_MANIFEST      = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+const (
+	dockerHubHost         = "docker.io"
+	dockerHubRegistryHost = "registry-1.docker.io"
+	registryClientTimeout = 60 * time.Second
+)
+
+// ErrNotFound is returned when the manifest or blob asked for does not exist in the registry
+var ErrNotFound = errors.New("not found in registry")
+
+// Credential of the registry, Insecure skips tls verification and Cert is the ca certificate of the registry
+type Credential struct {
+	RegistryURL string
+	Username    string
+	Password    string
+	Insecure    bool
+	Cert        string
+}
+
+type PlatformImage struct {
+	// Platform is of the form os/arch[/variant]
+	Platform string
+	Tag      string
+}
+
+type ManifestList struct {
+	Digest string
+	// PlatformDigests are the digests of the image manifests listed, by platform
+	PlatformDigests map[string]string
+}
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Platform    *platform         `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+type index struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	MediaType     string        `json:"mediaType"`
+	Manifests     []*descriptor `json:"manifests"`
+}
+
+// Client talks to the distribution api of a registry, it is not safe for concurrent use
+type Client struct {
+	httpClient  *http.Client
+	credential  *Credential
+	baseUrl     string
+	pathPrefix  string
+	isDockerHub bool
+	// authorization is the value of the authorization header of the last successful challenge
+	authorization string
+}
+
+func NewClient(credential *Credential) (*Client, error) {
+	registryUrl := credential.RegistryURL
+	if !strings.HasPrefix(registryUrl, "http://") && !strings.HasPrefix(registryUrl, "https://") {
+		registryUrl = "https://" + registryUrl
+	}
+	parsedUrl, err := url.Parse(registryUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry url %s: %v", credential.RegistryURL, err)
+	}
+	host := parsedUrl.Host
+	isDockerHub := host == dockerHubHost || host == "index."+dockerHubHost || host == dockerHubRegistryHost
+	if isDockerHub {
+		host = dockerHubRegistryHost
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: credential.Insecure}
+	if len(credential.Cert) > 0 {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM([]byte(credential.Cert)) {
+			return nil, fmt.Errorf("invalid certificate of registry %s", credential.RegistryURL)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   registryClientTimeout,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig},
+		},
+		credential:  credential,
+		baseUrl:     fmt.Sprintf("%s://%s", parsedUrl.Scheme, host),
+		pathPrefix:  strings.Trim(parsedUrl.Path, "/"),
+		isDockerHub: isDockerHub,
+	}, nil
+}
+
+// CreateManifestList pushes a manifest list with tag listing the image of every platform, images which are indexes
+// themselves (as pushed by buildx with attestations) contribute the manifest of their platform
+func (client *Client) CreateManifestList(repository string, tag string, images []*PlatformImage) (*ManifestList, error) {
+	repository = client.getRepository(repository)
+	manifestList := &ManifestList{PlatformDigests: make(map[string]string)}
+	var manifests []*descriptor
+	for _, image := range images {
+		mediaType, body, digest, err := client.getManifest(repository, image.Tag)
+		if err != nil {
+			return nil, err
+		}
+		manifest, err := getPlatformManifest(image.Platform, mediaType, body, digest)
+		if err != nil {
+			return nil, fmt.Errorf("image %s:%s: %v", repository, image.Tag, err)
+		}
+		manifests = append(manifests, manifest)
+		manifestList.PlatformDigests[image.Platform] = manifest.Digest
+	}
+	mediaType, body, err := buildIndex(manifests)
+	if err != nil {
+		return nil, err
+	}
+	err = client.putManifest(repository, tag, mediaType, body)
+	if err != nil {
+		return nil, err
+	}
+	manifestList.Digest = getDigest(body)
+	return manifestList, nil
+}
+
+// DeleteManifest deletes the manifest of the reference along with the tags pointing to it, registries do not allow
+// deleting a manifest by its tag so a tag is resolved to its digest first
+func (client *Client) DeleteManifest(repository string, reference string) error {
+	repository = client.getRepository(repository)
+	digest := reference
+	if !strings.HasPrefix(reference, "sha256:") {
+		_, _, resolved, err := client.getManifest(repository, reference)
+		if err != nil {
+			return err
+		}
+		digest = resolved
+	}
+	resp, err := client.do(http.MethodDelete, fmt.Sprintf("/v2/%s/manifests/%s", repository, digest), nil, nil, repository)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: manifest %s@%s", ErrNotFound, repository, digest)
+	}
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error in deleting manifest %s@%s, status %d: %s", repository, digest, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+func (client *Client) getRepository(repository string) string {
+	repository = strings.Trim(repository, "/")
+	if len(client.pathPrefix) > 0 && !strings.HasPrefix(repository, client.pathPrefix+"/") {
+		repository = client.pathPrefix + "/" + repository
+	}
+	if client.isDockerHub && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return repository
+}
+
+func (client *Client) getManifest(repository string, reference string) (mediaType string, body []byte, digest string, err error) {
+	headers := map[string]string{
+		"Accept": strings.Join([]string{MEDIA_TYPE_OCI_INDEX, MEDIA_TYPE_OCI_MANIFEST, MEDIA_TYPE_DOCKER_MANIFEST_LIST, MEDIA_TYPE_DOCKER_MANIFEST}, ", "),
+	}
+	resp, err := client.do(http.MethodGet, fmt.Sprintf("/v2/%s/manifests/%s", repository, reference), headers, nil, repository)
+	if err != nil {
+		return "", nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, "", err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil, "", fmt.Errorf("%w: manifest %s:%s", ErrNotFound, repository, reference)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, "", fmt.Errorf("error in fetching manifest %s:%s, status %d: %s", repository, reference, resp.StatusCode, string(body))
+	}
+	mediaType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != MEDIA_TYPE_OCI_INDEX && mediaType != MEDIA_TYPE_OCI_MANIFEST && mediaType != MEDIA_TYPE_DOCKER_MANIFEST_LIST && mediaType != MEDIA_TYPE_DOCKER_MANIFEST {
+		// some registries serve manifests as json, the media type is then read from the manifest
+		manifest := &struct {
+			MediaType string `json:"mediaType"`
+		}{}
+		if err = json.Unmarshal(body, manifest); err != nil {
+			return "", nil, "", fmt.Errorf("invalid manifest %s:%s: %v", repository, reference, err)
+		}
+		mediaType = manifest.MediaType
+	}
+	return mediaType, body, getDigest(body), nil
+}
+
+func (client *Client) putManifest(repository string, reference string, mediaType string, body []byte) error {
+	resp, err := client.do(http.MethodPut, fmt.Sprintf("/v2/%s/manifests/%s", repository, reference), map[string]string{"Content-Type": mediaType}, body, repository)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error in pushing manifest list %s:%s, status %d: %s", repository, reference, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// do sends the request and, on an auth challenge of the registry, sends it once more with the authorization asked for.
+// path is either relative to the registry or an absolute url as returned in the location of a blob upload
+func (client *Client) do(method string, path string, headers map[string]string, body []byte, repository string) (*http.Response, error) {
+	requestUrl := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		requestUrl = client.baseUrl + path
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, requestUrl, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		if len(client.authorization) > 0 {
+			req.Header.Set("Authorization", client.authorization)
+		}
+		resp, err := client.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			return resp, nil
+		}
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		client.authorization, err = client.authorize(challenge, repository, getScopeActions(method))
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (client *Client) authorize(challenge string, repository string, actions string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		return "Basic " + client.getBasicAuth(), nil
+	case "bearer":
+		return client.getBearerToken(params, repository, actions)
+	default:
+		return "", fmt.Errorf("unsupported auth challenge %q of registry %s", challenge, client.credential.RegistryURL)
+	}
+}
+
+func (client *Client) getBearerToken(params map[string]string, repository string, actions string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || len(params["realm"]) == 0 {
+		return "", fmt.Errorf("invalid auth realm %q of registry %s", params["realm"], client.credential.RegistryURL)
+	}
+	query := realm.Query()
+	if service := params["service"]; len(service) > 0 {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:%s", repository, actions))
+	realm.RawQuery = query.Encode()
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if len(client.credential.Username) > 0 || len(client.credential.Password) > 0 {
+		req.Header.Set("Authorization", "Basic "+client.getBasicAuth())
+	}
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error in authenticating with registry %s, status %d", client.credential.RegistryURL, resp.StatusCode)
+	}
+	tokenResponse := &struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(tokenResponse)
+	if err != nil {
+		return "", err
+	}
+	token := tokenResponse.Token
+	if len(token) == 0 {
+		token = tokenResponse.AccessToken
+	}
+	return "Bearer " + token, nil
+}
+
+func (client *Client) getBasicAuth() string {
+	return base64.StdEncoding.EncodeToString([]byte(client.credential.Username + ":" + client.credential.Password))
+}
+
+// getScopeActions returns the actions on the repository a token is asked for to send a request of the method
+func getScopeActions(method string) string {
+	if method == http.MethodDelete {
+		return "delete"
+	}
+	return "pull,push"
+}
+
+// parseChallenge parses a WWW-Authenticate header of the form scheme key="value",key="value"
+func parseChallenge(challenge string) (string, map[string]string) {
+	challenge = strings.TrimSpace(challenge)
+	scheme, rest, _ := strings.Cut(challenge, " ")
+	params := make(map[string]string)
+	for len(rest) > 0 {
+		rest = strings.TrimLeft(rest, " ,")
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key] = value[1 : end+1]
+			rest = value[end+2:]
+		} else {
+			params[key], rest, _ = strings.Cut(value, ",")
+		}
+	}
+	return scheme, params
+}
+
+// getPlatformManifest returns the descriptor of the image manifest of the platform
+func getPlatformManifest(platformName string, mediaType string, body []byte, digest string) (*descriptor, error) {
+	targetPlatform, err := parsePlatform(platformName)
+	if err != nil {
+		return nil, err
+	}
+	switch mediaType {
+	case MEDIA_TYPE_OCI_MANIFEST, MEDIA_TYPE_DOCKER_MANIFEST:
+		return &descriptor{
+			MediaType: mediaType,
+			Digest:    digest,
+			Size:      int64(len(body)),
+			Platform:  targetPlatform,
+		}, nil
+	case MEDIA_TYPE_OCI_INDEX, MEDIA_TYPE_DOCKER_MANIFEST_LIST:
+		imageIndex := &index{}
+		err = json.Unmarshal(body, imageIndex)
+		if err != nil {
+			return nil, err
+		}
+		for _, manifest := range imageIndex.Manifests {
+			if manifest.Platform != nil && manifest.Platform.OS == targetPlatform.OS && manifest.Platform.Architecture == targetPlatform.Architecture &&
+				(len(targetPlatform.Variant) == 0 || manifest.Platform.Variant == targetPlatform.Variant) {
+				return manifest, nil
+			}
+		}
+		return nil, fmt.Errorf("no manifest found for platform %s", platformName)
+	default:
+		return nil, fmt.Errorf("unsupported manifest media type %q", mediaType)
+	}
+}
+
+// buildIndex returns a docker manifest list if all the manifests are docker manifests and an oci index otherwise
+func buildIndex(manifests []*descriptor) (string, []byte, error) {
+	mediaType := MEDIA_TYPE_DOCKER_MANIFEST_LIST
+	for _, manifest := range manifests {
+		if manifest.MediaType != MEDIA_TYPE_DOCKER_MANIFEST {
+			mediaType = MEDIA_TYPE_OCI_INDEX
+		}
+	}
+	body, err := json.Marshal(&index{
+		SchemaVersion: 2,
+		MediaType:     mediaType,
+		Manifests:     manifests,
+	})
+	return mediaType, body, err
+}
+
+func parsePlatform(platformName string) (*platform, error) {
+	parts := strings.Split(platformName, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid platform %q", platformName)
+	}
+	targetPlatform := &platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		targetPlatform.Variant = parts[2]
+	}
+	return targetPlatform, nil
+}
+
+func getDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
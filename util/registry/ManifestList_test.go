@@ -0,0 +1,150 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:org/app:pull,push"`)
+	if scheme != "Bearer" || params["realm"] != "https://auth.docker.io/token" || params["service"] != "registry.docker.io" ||
+		params["scope"] != "repository:org/app:pull,push" {
+		t.Errorf("parseChallenge() = %s, %v", scheme, params)
+	}
+	scheme, params = parseChallenge(`Basic realm=registry`)
+	if scheme != "Basic" || params["realm"] != "registry" {
+		t.Errorf("parseChallenge() = %s, %v", scheme, params)
+	}
+}
+
+func TestGetPlatformManifest(t *testing.T) {
+	amd64Index := `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[
+		{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:aaa","size":10,"platform":{"architecture":"amd64","os":"linux"}},
+		{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:att","size":5,"platform":{"architecture":"unknown","os":"unknown"}}]}`
+	manifest, err := getPlatformManifest("linux/amd64", MEDIA_TYPE_OCI_INDEX, []byte(amd64Index), "sha256:index")
+	if err != nil || manifest.Digest != "sha256:aaa" {
+		t.Errorf("getPlatformManifest() index = %+v, %v", manifest, err)
+	}
+	_, err = getPlatformManifest("linux/arm64", MEDIA_TYPE_OCI_INDEX, []byte(amd64Index), "sha256:index")
+	if err == nil {
+		t.Errorf("getPlatformManifest() expected error for platform missing in index")
+	}
+	manifest, err = getPlatformManifest("linux/arm/v7", MEDIA_TYPE_DOCKER_MANIFEST, []byte(`{}`), "sha256:bbb")
+	if err != nil || manifest.Digest != "sha256:bbb" || manifest.Size != 2 || manifest.Platform.Variant != "v7" {
+		t.Errorf("getPlatformManifest() manifest = %+v, %v", manifest, err)
+	}
+}
+
+func TestCreateManifestList(t *testing.T) {
+	manifests := map[string]string{
+		"v1-linux-amd64": `{"schemaVersion":2,"mediaType":"application/vnd.docker.distribution.manifest.v2+json"}`,
+		"v1-linux-arm64": `{"schemaVersion":2,"mediaType":"application/vnd.docker.distribution.manifest.v2+json","config":{}}`,
+	}
+	var pushedIndex *index
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			username, password, _ := r.BasicAuth()
+			if username != "user" || password != "pass" || r.URL.Query().Get("scope") != "repository:org/app:pull,push" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token":"abc"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		tag := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		switch r.Method {
+		case http.MethodGet:
+			manifest, ok := manifests[tag]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", MEDIA_TYPE_DOCKER_MANIFEST)
+			_, _ = w.Write([]byte(manifest))
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			pushedIndex = &index{}
+			_ = json.Unmarshal(body, pushedIndex)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Credential{RegistryURL: server.URL, Username: "user", Password: "pass"})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	manifestList, err := client.CreateManifestList("org/app", "v1", []*PlatformImage{
+		{Platform: "linux/amd64", Tag: "v1-linux-amd64"},
+		{Platform: "linux/arm64", Tag: "v1-linux-arm64"},
+	})
+	if err != nil {
+		t.Fatalf("CreateManifestList() error = %v", err)
+	}
+	if pushedIndex == nil || pushedIndex.MediaType != MEDIA_TYPE_DOCKER_MANIFEST_LIST || len(pushedIndex.Manifests) != 2 ||
+		pushedIndex.Manifests[1].Platform.Architecture != "arm64" {
+		t.Errorf("CreateManifestList() pushed index = %+v", pushedIndex)
+	}
+	if manifestList.PlatformDigests["linux/arm64"] != getDigest([]byte(manifests["v1-linux-arm64"])) || !strings.HasPrefix(manifestList.Digest, "sha256:") {
+		t.Errorf("CreateManifestList() = %+v", manifestList)
+	}
+}
+
+func TestDeleteManifest(t *testing.T) {
+	digest := getDigest([]byte("manifest"))
+	var deletedPath string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:org/app:delete" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token":"abc"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodDelete || !strings.HasSuffix(r.URL.Path, digest) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		deletedPath = r.URL.Path
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(&Credential{RegistryURL: server.URL})
+	if err := client.DeleteManifest("org/app", digest); err != nil || deletedPath != "/v2/org/app/manifests/"+digest {
+		t.Errorf("DeleteManifest() error = %v, deleted %s", err, deletedPath)
+	}
+	if err := client.DeleteManifest("org/app", getDigest([]byte("other"))); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteManifest() of missing manifest error = %v", err)
+	}
+}
+
+func TestGetRepository(t *testing.T) {
+	client, _ := NewClient(&Credential{RegistryURL: "docker.io"})
+	if client.baseUrl != "https://registry-1.docker.io" || client.getRepository("nginx") != "library/nginx" {
+		t.Errorf("NewClient() docker hub = %s, %s", client.baseUrl, client.getRepository("nginx"))
+	}
+	client, _ = NewClient(&Credential{RegistryURL: "harbor.example.com/team"})
+	if client.getRepository("app") != "team/app" || client.getRepository("team/app") != "team/app" {
+		t.Errorf("getRepository() = %s", client.getRepository("app"))
+	}
+}